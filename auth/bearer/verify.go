@@ -0,0 +1,109 @@
+package bearer
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// Verifier provides an interface for server implementations to authenticate
+// a request message signed with a bearer token. The verifier is responsible
+// for validating the message type is compatible with the verifier.
+type Verifier interface {
+	VerifyBearerToken(context.Context, Message) error
+}
+
+// VerificationError is returned by a Verifier when a request message is not
+// authenticated by a valid bearer token.
+type VerificationError struct {
+	Reason string
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("bearer token verification failed, %s", e.Reason)
+}
+
+// VerifyHTTPMessage provides a bearer token verification implementation for
+// HTTP requests signed by SignHTTPSMessage, comparing the token carried in the
+// "Authorization" header against the one retrieved from TokenProvider.
+type VerifyHTTPMessage struct {
+	// The source of the expected token. Required.
+	TokenProvider TokenProvider
+
+	// The amount of time past the expected token's expiration that it will
+	// still be accepted for, to account for clock skew between client and
+	// server.
+	ClockSkew time.Duration
+
+	// Overrides the current time, used for testing.
+	now func() time.Time
+}
+
+// NewVerifyHTTPMessage returns an initialized verifier for HTTP messages.
+func NewVerifyHTTPMessage(tokenProvider TokenProvider, optFns ...func(*VerifyHTTPMessage)) *VerifyHTTPMessage {
+	v := &VerifyHTTPMessage{
+		TokenProvider: tokenProvider,
+	}
+	for _, fn := range optFns {
+		fn(v)
+	}
+	return v
+}
+
+// VerifyBearerToken verifies that the message carries the expected bearer
+// token per RFC 6750, https://datatracker.ietf.org/doc/html/rfc6750. The
+// message may be either a smithy-go HTTP Request pointer or a net/http Request
+// pointer.
+//
+// Tokens are compared in constant time. A VerificationError is returned if
+// the token is missing, does not match, or has expired.
+func (v *VerifyHTTPMessage) VerifyBearerToken(ctx context.Context, message Message) error {
+	var header http.Header
+	switch r := message.(type) {
+	case *smithyhttp.Request:
+		header = r.Header
+	case *http.Request:
+		header = r.Header
+	default:
+		return fmt.Errorf("expect HTTP Request, got %T", message)
+	}
+
+	actual, ok := parseAuthorization(header.Get("Authorization"))
+	if !ok {
+		return &VerificationError{Reason: "missing bearer token"}
+	}
+
+	expect, err := v.TokenProvider.RetrieveBearerToken(ctx)
+	if err != nil {
+		return fmt.Errorf("retrieve expected bearer token, %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(expect.Value), []byte(actual)) != 1 {
+		return &VerificationError{Reason: "token mismatch"}
+	}
+
+	now := time.Now
+	if v.now != nil {
+		now = v.now
+	}
+	if expect.Expired(now().Add(-v.ClockSkew)) {
+		return &VerificationError{Reason: "token expired"}
+	}
+
+	return nil
+}
+
+func parseAuthorization(v string) (string, bool) {
+	const scheme = "Bearer "
+
+	// auth-scheme is case-insensitive, RFC 9110 section 11.1
+	if len(v) <= len(scheme) || !strings.EqualFold(v[:len(scheme)], scheme) {
+		return "", false
+	}
+	return strings.TrimSpace(v[len(scheme):]), true
+}
@@ -0,0 +1,109 @@
+package bearer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+func TestVerifyHTTPMessage(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newRequest := func(authorization string) *http.Request {
+		r, _ := http.NewRequest("GET", "https://example.aws", nil)
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		return r
+	}
+
+	cases := map[string]struct {
+		message     Message
+		token       Token
+		clockSkew   time.Duration
+		expectErr   string
+		expectVerif bool
+	}{
+		"not http request": {
+			message:   struct{}{},
+			expectErr: "expect HTTP Request",
+		},
+		"missing header": {
+			message:     newRequest(""),
+			token:       Token{Value: "abc123"},
+			expectErr:   "missing bearer token",
+			expectVerif: true,
+		},
+		"wrong scheme": {
+			message:     newRequest("Basic abc123"),
+			token:       Token{Value: "abc123"},
+			expectErr:   "missing bearer token",
+			expectVerif: true,
+		},
+		"mismatch": {
+			message:     newRequest("Bearer abc124"),
+			token:       Token{Value: "abc123"},
+			expectErr:   "token mismatch",
+			expectVerif: true,
+		},
+		"success": {
+			message: newRequest("Bearer abc123"),
+			token:   Token{Value: "abc123"},
+		},
+		"case-insensitive scheme": {
+			message: newRequest("bearer abc123"),
+			token:   Token{Value: "abc123"},
+		},
+		"smithy request": {
+			message: func() Message {
+				r := smithyhttp.NewStackRequest().(*smithyhttp.Request)
+				r.Header.Set("Authorization", "Bearer abc123")
+				return r
+			}(),
+			token: Token{Value: "abc123"},
+		},
+		"expired": {
+			message:     newRequest("Bearer abc123"),
+			token:       Token{Value: "abc123", CanExpire: true, Expires: now.Add(-time.Minute)},
+			expectErr:   "token expired",
+			expectVerif: true,
+		},
+		"expired within clock skew": {
+			message:   newRequest("Bearer abc123"),
+			token:     Token{Value: "abc123", CanExpire: true, Expires: now.Add(-time.Minute)},
+			clockSkew: 5 * time.Minute,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := NewVerifyHTTPMessage(StaticTokenProvider{Token: c.token}, func(v *VerifyHTTPMessage) {
+				v.ClockSkew = c.clockSkew
+				v.now = func() time.Time { return now }
+			})
+
+			err := v.VerifyBearerToken(context.Background(), c.message)
+			if len(c.expectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error %q", c.expectErr)
+				}
+				if e, a := c.expectErr, err.Error(); !strings.Contains(a, e) {
+					t.Fatalf("expect error %q, got %q", e, a)
+				}
+				var verr *VerificationError
+				if e, a := c.expectVerif, errors.As(err, &verr); e != a {
+					t.Errorf("expect verification error %v, got %v", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
@@ -81,7 +81,13 @@ public class SignRequestMiddlewareGenerator {
                     return out, metadata, $errorf:T("no signer")
                 }
 
-                if err := signer.SignRequest(ctx, req, identity, rscheme.SignerProperties); err != nil {
+                // signing overrides apply to this request only, not to the
+                // properties of the resolved auth scheme
+                var signerProps $properties:T
+                signerProps.SetAll(&rscheme.SignerProperties)
+                $applySigningOverrides:T(ctx, &signerProps)
+
+                if err := signer.SignRequest(ctx, req, identity, signerProps); err != nil {
                     return out, metadata, $errorf:T("sign request: %w", err)
                 }
 
@@ -90,7 +96,9 @@ public class SignRequestMiddlewareGenerator {
                 MapUtils.of(
                         // FUTURE(#458) protocol generator should specify the transport type
                         "request", SmithyGoTypes.Transport.Http.Request,
-                        "errorf", GoStdlibTypes.Fmt.Errorf
+                        "errorf", GoStdlibTypes.Fmt.Errorf,
+                        "properties", SmithyGoTypes.Smithy.Properties,
+                        "applySigningOverrides", SmithyGoTypes.Transport.Http.ApplySigningOverrides
                 ));
     }
 }
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ *  http://aws.amazon.com/apache2.0
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+package software.amazon.smithy.go.codegen.integration;
+
+import software.amazon.smithy.codegen.core.SymbolProvider;
+import software.amazon.smithy.go.codegen.GoDelegator;
+import software.amazon.smithy.go.codegen.GoSettings;
+import software.amazon.smithy.go.codegen.SmithyGoDependency;
+import software.amazon.smithy.model.Model;
+
+/**
+ * Adds the error registry of the client, which the error dispatchers of its
+ * deserializers consult for error codes the client does not model.
+ */
+public class ClientErrorRegistry implements GoIntegration {
+    public static final String ERROR_REGISTRY = "ErrorRegistry";
+
+    @Override
+    public void writeAdditionalFiles(
+            GoSettings settings,
+            Model model,
+            SymbolProvider symbolProvider,
+            GoDelegator goDelegator
+    ) {
+        goDelegator.useShapeWriter(settings.getService(model), writer -> {
+            writer.writeDocs(String.format("%s is consulted by the deserializers of the client for the codes of "
+                    + "error responses the client does not model, such that errors added to the API can be "
+                    + "returned as typed errors without regenerating the client. See smithy.ErrorRegistry.",
+                    ERROR_REGISTRY));
+            writer.write("var $L $T", ERROR_REGISTRY, SmithyGoDependency.SMITHY.struct("ErrorRegistry"));
+            writer.write("");
+        });
+    }
+}
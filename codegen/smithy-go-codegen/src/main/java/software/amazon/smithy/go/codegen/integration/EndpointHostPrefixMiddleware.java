@@ -22,12 +22,9 @@ import java.util.List;
 import software.amazon.smithy.codegen.core.SymbolProvider;
 import software.amazon.smithy.go.codegen.GoDelegator;
 import software.amazon.smithy.go.codegen.GoSettings;
-import software.amazon.smithy.go.codegen.GoStackStepMiddlewareGenerator;
 import software.amazon.smithy.go.codegen.GoWriter;
-import software.amazon.smithy.go.codegen.MiddlewareIdentifier;
 import software.amazon.smithy.go.codegen.SmithyGoDependency;
 import software.amazon.smithy.go.codegen.SymbolUtils;
-import software.amazon.smithy.go.codegen.endpoints.EndpointMiddlewareGenerator;
 import software.amazon.smithy.model.Model;
 import software.amazon.smithy.model.knowledge.TopDownIndex;
 import software.amazon.smithy.model.pattern.SmithyPattern;
@@ -38,13 +35,11 @@ import software.amazon.smithy.model.shapes.StructureShape;
 import software.amazon.smithy.model.traits.EndpointTrait;
 
 /**
- * EndpointHostPrefixMiddleware adds middlewares to identify
- * a host prefix and mutate the request URL host if permitted.
+ * EndpointHostPrefixMiddleware adds the smithyhttp host prefix middleware to
+ * operations with a host prefix, to mutate the request URL host if permitted.
 **/
 public class EndpointHostPrefixMiddleware implements GoIntegration {
 
-    private static final MiddlewareIdentifier MIDDLEWARE_ID = MiddlewareIdentifier.string("EndpointHostPrefix");
-
     final List<RuntimeClientPlugin> runtimeClientPlugins = new ArrayList<>();
     final List<OperationShape> endpointPrefixOperations = new ArrayList<>();
 
@@ -81,92 +76,45 @@ public class EndpointHostPrefixMiddleware implements GoIntegration {
             delegator.useShapeWriter(operation, (writer) -> {
                 SmithyPattern pattern = operation.expectTrait(EndpointTrait.class).getHostPrefix();
 
-                writeMiddleware(writer, model, symbolProvider, operation, pattern);
-
-                String middlewareName = getMiddlewareName(operation);
-                String middlewareHelperName = getMiddlewareHelperName(operation);
                 writer.addUseImports(SmithyGoDependency.SMITHY_MIDDLEWARE);
+                writer.addUseImports(SmithyGoDependency.SMITHY_HTTP_TRANSPORT);
                 writer.openBlock("func $L(stack *middleware.Stack) error {", "}",
-                        middlewareHelperName,
-                        () -> {
-                            writer.write(
-                                    "return stack.Finalize.Insert(&$L{}, $S, middleware.After)",
-                                    middlewareName, EndpointMiddlewareGenerator.MIDDLEWARE_ID);
-                        });
+                        getMiddlewareHelperName(operation),
+                        () -> writeAddMiddleware(writer, model, symbolProvider, operation, pattern));
             });
         });
     }
 
-    private static void writeMiddleware(
+    private static void writeAddMiddleware(
             GoWriter writer,
             Model model,
             SymbolProvider symbolProvider,
             OperationShape operation,
             SmithyPattern pattern
     ) {
-        GoStackStepMiddlewareGenerator middlewareGenerator =
-                GoStackStepMiddlewareGenerator.createFinalizeStepMiddleware(
-                        getMiddlewareName(operation),
-                        MIDDLEWARE_ID
-                );
-
-        middlewareGenerator.writeMiddleware(writer, (generator, w) -> {
-            writer.addUseImports(SmithyGoDependency.SMITHY_HTTP_TRANSPORT);
-            writer.addUseImports(SmithyGoDependency.FMT);
-
-            w.openBlock("if smithyhttp.GetHostnameImmutable(ctx) || "
-                    + "smithyhttp.IsEndpointHostPrefixDisabled(ctx) {", "}", () -> {
-                w.write("return next.$L(ctx, in)", generator.getHandleMethodName());
+        if (pattern.getLabels().isEmpty()) {
+            writer.write("return smithyhttp.AddHostPrefixMiddleware(stack, $S, nil)", pattern.toString());
+            return;
+        }
+
+        // the values of the labels are the members of the input bound to them
+        writer.addUseImports(SmithyGoDependency.CONTEXT);
+        writer.addUseImports(SmithyGoDependency.FMT);
+        StructureShape input = ProtocolUtils.expectInput(model, operation);
+        writer.openBlock("return smithyhttp.AddHostPrefixMiddleware(stack, $S, "
+                + "func(ctx context.Context) (map[string]*string, error) {", "})", pattern.toString(), () -> {
+            writer.write("opaqueInput := getOperationInput(ctx)");
+            writer.write("input, ok := opaqueInput.($P)", symbolProvider.toSymbol(input));
+            writer.openBlock("if !ok {", "}", () -> {
+                writer.write("return nil, fmt.Errorf(\"unknown input type %T\", opaqueInput)");
             }).write("");
 
-            w.write("req, ok := in.Request.(*smithyhttp.Request)");
-            w.openBlock("if !ok {", "}", () -> {
-                writer.write("return out, metadata, fmt.Errorf(\"unknown transport type %T\", in.Request)");
-            }).write("");
-
-            if (pattern.getLabels().isEmpty()) {
-                w.write("req.URL.Host = $S + req.URL.Host", pattern.toString());
-            } else {
-                // If the pattern has labels, we need to build up the host prefix using a string builder.
-                writer.addUseImports(SmithyGoDependency.STRINGS);
-                writer.addUseImports(SmithyGoDependency.SMITHY);
-                StructureShape input = ProtocolUtils.expectInput(model, operation);
-                writer.write("opaqueInput := getOperationInput(ctx)");
-                writer.write("input, ok := opaqueInput.($P)", symbolProvider.toSymbol(input));
-                w.openBlock("if !ok {", "}", () -> {
-                    writer.write("return out, metadata, fmt.Errorf(\"unknown input type %T\", opaqueInput)");
-                }).write("");
-
-                w.write("var prefix strings.Builder");
-                for (SmithyPattern.Segment segment : pattern.getSegments()) {
-                    if (!segment.isLabel()) {
-                        w.write("prefix.WriteString($S)", segment.toString());
-                    } else {
-                        MemberShape member = input.getMember(segment.getContent()).get();
-                        String memberName = symbolProvider.toMemberName(member);
-                        String memberReference = "input." + memberName;
-
-                        // Theoretically this should never be nil or empty by this point unless validation has
-                        // been disabled.
-                        w.write("if $L == nil {", memberReference).indent();
-                        w.write("return out, metadata, &smithy.SerializationError{Err: "
-                                        + "fmt.Errorf(\"$L forms part of the endpoint host and so may not be nil\")}",
-                                memberName);
-                        w.dedent().write("} else if !smithyhttp.ValidHostLabel(*$L) {", memberReference).indent();
-                        w.write("return out, metadata, &smithy.SerializationError{Err: "
-                                + "fmt.Errorf(\"$L forms part of the endpoint host and so must match "
-                                + "\\\"[a-zA-Z0-9-]{1,63}\\\""
-                                + ", but was \\\"%s\\\"\", *$L)}", memberName, memberReference);
-                        w.dedent().openBlock("} else {", "}", () -> {
-                            w.write("prefix.WriteString(*$L)", memberReference);
-                        });
-                    }
+            writer.openBlock("return map[string]*string{", "}, nil", () -> {
+                for (SmithyPattern.Segment label : pattern.getLabels()) {
+                    MemberShape member = input.getMember(label.getContent()).get();
+                    writer.write("$S: input.$L,", label.getContent(), symbolProvider.toMemberName(member));
                 }
-                w.write("req.URL.Host = prefix.String() + req.URL.Host");
-            }
-            w.write("");
-
-            w.write("return next.$L(ctx, in)", generator.getHandleMethodName());
+            });
         });
     }
 
@@ -189,10 +137,6 @@ public class EndpointHostPrefixMiddleware implements GoIntegration {
         return operations;
     }
 
-    private static String getMiddlewareName(OperationShape operation) {
-        return String.format("endpointPrefix_op%sMiddleware", operation.getId().getName());
-    }
-
     private static String getMiddlewareHelperName(OperationShape operation) {
         return String.format("addEndpointPrefix_op%sMiddleware", operation.getId().getName());
     }
@@ -47,8 +47,9 @@ public final class HttpProtocolGeneratorUtils {
      * Generates a function that handles error deserialization by getting the error code then
      * dispatching to the error-specific deserializer.
      * <p>
-     * If the error code does not map to a known error, a generic error will be returned using
-     * the error code and error message discovered in the response.
+     * If the error code does not map to a known error, the error is built by the constructor
+     * registered for the code with the error registry of the client, or a generic error will be
+     * returned using the error code and error message discovered in the response.
      * <p>
      * The default error message and code are both "UnknownError".
      *
@@ -73,11 +74,17 @@ public final class HttpProtocolGeneratorUtils {
 
     private static void defaultBlock(GoWriter writer) {
         writer.openBlock("default:", "", () -> {
-            writer.openBlock("genericError := &smithy.GenericAPIError{", "}", () -> {
-                writer.write("Code: errorCode,");
-                writer.write("Message: errorMessage,");
+            // errors the client does not model are built by the constructor registered for their code, if any
+            writer.openBlock("apiErr, err := $L.NewError(smithy.ErrorResponse{", "})",
+                    ClientErrorRegistry.ERROR_REGISTRY, () -> {
+                        writer.write("Code: errorCode,");
+                        writer.write("Message: errorMessage,");
+                        writer.write("Body: errorBuffer.Bytes(),");
+                    });
+            writer.openBlock("if err != nil {", "}", () -> {
+                writer.write("return err");
             });
-            writer.write("return genericError");
+            writer.write("return apiErr");
         });
     }
 
@@ -191,6 +191,27 @@ public class Waiters implements GoIntegration {
                     writer.writeDocs("LogWaitAttempts is used to enable logging for waiter retry attempts");
                     writer.write("LogWaitAttempts bool");
 
+                    Symbol stateSymbol = SymbolUtils.createPointableSymbolBuilder(
+                            "State", SmithyGoDependency.SMITHY_WAITERS
+                    ).build();
+                    writer.write("");
+                    writer.writeDocs(
+                            "ResumeState, if set, resumes a wait from the state checkpointed by an earlier wait, "
+                                    + "see Checkpoint, rather than starting a new one. The wait continues from the "
+                                    + "attempt count of the state, within the remaining wait time of the state, "
+                                    + "rather than maxWaitDur, and makes its next attempt without delay."
+                    );
+                    writer.write("ResumeState $P", stateSymbol);
+
+                    writer.write("");
+                    writer.writeDocs(
+                            "Checkpoint, if set, is called with the state of the wait after each attempt that "
+                                    + "matched the retry state, before the delay to the next attempt. The state may "
+                                    + "be saved, e.g. with its MarshalJSON method, to resume the wait later with "
+                                    + "ResumeState, potentially in another process."
+                    );
+                    writer.write("Checkpoint func($T)", stateSymbol);
+
                     writer.write("");
                     writer.writeDocs(
                             "Retryable is function that can be used to override the "
@@ -199,7 +220,8 @@ public class Waiters implements GoIntegration {
                                     + "or a terminal state.\n\nBy default service-modeled logic "
                                     + "will populate this option. This option can thus be used to define a custom "
                                     + "waiter state with fall-back to service-modeled waiter state mutators."
-                                    + "The function returns an error in case of a failure state. "
+                                    + "The function returns a *smithywaiter.FailureStateError in case of a failure "
+                                    + "state, and the operation error in case of an error no acceptor matched. "
                                     + "In case of retry state, this function returns a bool value of true and "
                                     + "nil error, while in case of success it returns a bool value of false and "
                                     + "nil error."
@@ -412,18 +434,38 @@ public class Waiters implements GoIntegration {
                                 + "maximum waiter delay of %v.\", options.MinDelay, options.MaxDelay)");
                     }).write("");
 
+                    Symbol stateSymbol = SymbolUtils.createValueSymbolBuilder(
+                            "State", SmithyGoDependency.SMITHY_WAITERS
+                    ).build();
+                    Symbol budgetExhaustedErrorSymbol = SymbolUtils.createValueSymbolBuilder(
+                            "RetryBudgetExhaustedError", SmithyGoDependency.SMITHY_WAITERS
+                    ).build();
+                    writer.write("state := $T{MaxWaitTime: maxWaitDur}", stateSymbol);
+                    writer.openBlock("if options.ResumeState != nil {", "}", () -> {
+                        writer.write("state = *options.ResumeState");
+                    });
+                    writer.write("remainingTime := state.Remaining()");
+                    writer.openBlock("if remainingTime <= 0 {", "}", () -> {
+                        writer.write("return nil, &$T{WaiterName: $S, MaxWaitTime: state.MaxWaitTime}",
+                                budgetExhaustedErrorSymbol, waiterName);
+                    }).write("");
+
                     writer.addUseImports(SmithyGoDependency.CONTEXT);
-                    writer.write("ctx, cancelFn := context.WithTimeout(ctx, maxWaitDur)");
+                    writer.write("ctx, cancelFn := context.WithTimeout(ctx, remainingTime)");
                     writer.write("defer cancelFn()");
                     writer.write("");
 
                     Symbol loggerMiddleware = SymbolUtils.createValueSymbolBuilder(
                             "Logger", SmithyGoDependency.SMITHY_WAITERS
                     ).build();
-                    writer.write("logger := $T{}", loggerMiddleware);
-                    writer.write("remainingTime := maxWaitDur").write("");
+                    writer.write("logger := $T{}", loggerMiddleware).write("");
 
-                    writer.write("var attempt int64");
+                    Symbol attemptSymbol = SymbolUtils.createValueSymbolBuilder(
+                            "Attempt", SmithyGoDependency.SMITHY_WAITERS
+                    ).build();
+                    writer.write("attempt := state.Attempt");
+                    writer.write("var attempts []$T", attemptSymbol);
+                    writer.write("var delay time.Duration");
                     writer.openBlock("for {", "}", () -> {
                         writer.write("");
                         writer.write("attempt++");
@@ -450,10 +492,49 @@ public class Waiters implements GoIntegration {
                                 });
                         writer.write("");
 
-                        // handle response and identify waiter state
-                        writer.write("retryable, err := options.Retryable(ctx, params, out, err)");
-                        writer.write("if err != nil { return nil, err }");
-                        writer.write("if !retryable { return out, nil }").write("");
+                        // handle response and identify waiter state, returning the attempts made with the
+                        // error of a terminal state
+                        Symbol failureStateErrorSymbol = SymbolUtils.createPointableSymbolBuilder(
+                                "FailureStateError", SmithyGoDependency.SMITHY_WAITERS
+                        ).build();
+                        Symbol nonRetryableErrorSymbol = SymbolUtils.createValueSymbolBuilder(
+                                "NonRetryableError", SmithyGoDependency.SMITHY_WAITERS
+                        ).build();
+                        writer.addUseImports(SmithyGoDependency.ERRORS);
+                        writer.write("retryable, retryErr := options.Retryable(ctx, params, out, err)");
+                        writer.write("record := $T{Number: attempt, Delay: delay, Err: err}", attemptSymbol);
+                        writer.write("var failureErr $P", failureStateErrorSymbol);
+                        writer.openBlock("switch {", "}", () -> {
+                            writer.openBlock("case errors.As(retryErr, &failureErr):", "", () -> {
+                                writer.write("record.Match = \"failure\"");
+                                writer.write("failureErr.WaiterName = $S", waiterName);
+                                writer.write("failureErr.Attempts = append(attempts, record)");
+                                writer.write("return nil, failureErr");
+                            });
+                            writer.openBlock("case retryErr != nil && err != nil && errors.Is(retryErr, err):", "",
+                                    () -> {
+                                        writer.openBlock("return nil, &$T{", "}", nonRetryableErrorSymbol, () -> {
+                                            writer.write("WaiterName: $S,", waiterName);
+                                            writer.write("Err: err,");
+                                            writer.write("Attempts: append(attempts, record),");
+                                        });
+                                    });
+                            writer.openBlock("case retryErr != nil:", "", () -> {
+                                writer.write("return nil, retryErr");
+                            });
+                            writer.openBlock("case !retryable:", "", () -> {
+                                writer.write("return out, nil");
+                            });
+                        });
+                        writer.write("record.Match = \"retry\"");
+                        writer.write("attempts = append(attempts, record)").write("");
+
+                        // checkpoint the state of the wait
+                        writer.write("state = state.Record(delay+time.Since(start), record.Match)");
+                        writer.openBlock("if options.Checkpoint != nil {", "}", () -> {
+                            writer.write("options.Checkpoint(state)");
+                        });
+                        writer.write("");
 
                         // update remaining time
                         writer.write("remainingTime -= time.Since(start)");
@@ -469,7 +550,7 @@ public class Waiters implements GoIntegration {
                                 "ComputeDelay", SmithyGoDependency.SMITHY_WAITERS
                         ).build();
                         writer.writeDocs("compute exponential backoff between waiter retries");
-                        writer.openBlock("delay, err := $T(", ")", computeDelaySymbol, () -> {
+                        writer.openBlock("delay, err = $T(", ")", computeDelaySymbol, () -> {
                             writer.write("attempt, options.MinDelay, options.MaxDelay, remainingTime,");
                         });
 
@@ -492,7 +573,11 @@ public class Waiters implements GoIntegration {
                                             "return nil, fmt.Errorf(\"request cancelled while waiting, %w\", err)");
                                 });
                     });
-                    writer.write("return nil, fmt.Errorf(\"exceeded max wait time for $L waiter\")", waiterName);
+                    writer.openBlock("return nil, &$T{", "}", budgetExhaustedErrorSymbol, () -> {
+                        writer.write("WaiterName: $S,", waiterName);
+                        writer.write("MaxWaitTime: state.MaxWaitTime,");
+                        writer.write("Attempts: attempts,");
+                    });
                 });
     }
 
@@ -553,8 +638,8 @@ public class Waiters implements GoIntegration {
                                     writer.write("expectedValue := $S", expectedValue);
 
                                     if (comparator == PathComparator.BOOLEAN_EQUALS) {
-                                        writeWaiterComparator(writer, acceptor, comparator, null, "pathValue",
-                                                "expectedValue");
+                                        writeWaiterComparator(writer, waiterName, acceptor, comparator, null,
+                                                "pathValue", "expectedValue");
                                     } else {
                                         String[] pathMembers = path.split("\\.");
                                         Shape targetShape = outputShape;
@@ -568,13 +653,12 @@ public class Waiters implements GoIntegration {
                                         }
 
                                         if (targetShape == null) {
-                                            writeWaiterComparator(writer, acceptor, comparator, null, "pathValue",
-                                                    "expectedValue");
+                                            writeWaiterComparator(writer, waiterName, acceptor, comparator, null,
+                                                    "pathValue", "expectedValue");
                                         } else {
                                             Symbol targetSymbol = symbolProvider.toSymbol(targetShape);
-                                            writeWaiterComparator(writer, acceptor, comparator, targetSymbol,
-                                                    "pathValue",
-                                                    "expectedValue");
+                                            writeWaiterComparator(writer, waiterName, acceptor, comparator,
+                                                    targetSymbol, "pathValue", "expectedValue");
                                         }
                                     }
                                 });
@@ -602,8 +686,8 @@ public class Waiters implements GoIntegration {
                                     });
                                     writer.write("");
                                     writer.write("expectedValue := $S", expectedValue);
-                                    writeWaiterComparator(writer, acceptor, comparator, outputSymbol, "pathValue",
-                                            "expectedValue");
+                                    writeWaiterComparator(writer, waiterName, acceptor, comparator, outputSymbol,
+                                            "pathValue", "expectedValue");
                                 });
                                 break;
 
@@ -611,7 +695,7 @@ public class Waiters implements GoIntegration {
                                 Matcher.SuccessMember successMember = (Matcher.SuccessMember) matcher;
                                 writer.openBlock("if err == nil {", "}",
                                         () -> {
-                                            writeMatchedAcceptorReturn(writer, acceptor);
+                                            writeMatchedAcceptorReturn(writer, waiterName, acceptor);
                                         });
                                 break;
 
@@ -634,7 +718,7 @@ public class Waiters implements GoIntegration {
                                         writer.addUseImports(SmithyGoDependency.ERRORS);
                                         writer.write("var errorType *$T", modeledErrorSymbol);
                                         writer.openBlock("if errors.As(err, &errorType) {", "}", () -> {
-                                            writeMatchedAcceptorReturn(writer, acceptor);
+                                            writeMatchedAcceptorReturn(writer, waiterName, acceptor);
                                         });
                                     } else {
                                         // fall back to un-modeled error shape matching
@@ -653,7 +737,7 @@ public class Waiters implements GoIntegration {
 
                                         writer.openBlock("if $S == apiErr.ErrorCode() {", "}",
                                                 errorType, () -> {
-                                                    writeMatchedAcceptorReturn(writer, acceptor);
+                                                    writeMatchedAcceptorReturn(writer, waiterName, acceptor);
                                                 });
                                     }
                                 });
@@ -667,6 +751,7 @@ public class Waiters implements GoIntegration {
                     });
 
                     writer.write("");
+                    writer.write("if err != nil { return false, err }");
                     writer.write("return true, nil");
                 });
     }
@@ -675,6 +760,7 @@ public class Waiters implements GoIntegration {
      * writes comparators for a given waiter. The comparators are defined within the waiter acceptor.
      *
      * @param writer       the Gowriter
+     * @param waiterName   the waiter name
      * @param acceptor     the waiter acceptor that defines the comparator and acceptor states
      * @param comparator   the comparator
      * @param targetSymbol the shape symbol of the compared type.
@@ -684,6 +770,7 @@ public class Waiters implements GoIntegration {
      */
     private void writeWaiterComparator(
             GoWriter writer,
+            String waiterName,
             Acceptor acceptor,
             PathComparator comparator,
             Symbol targetSymbol,
@@ -709,7 +796,7 @@ public class Waiters implements GoIntegration {
                 writer.write("");
 
                 writer.openBlock("if $L == $L {", "}", valueAccessor, expected, () -> {
-                    writeMatchedAcceptorReturn(writer, acceptor);
+                    writeMatchedAcceptorReturn(writer, waiterName, acceptor);
                 });
                 break;
 
@@ -728,7 +815,7 @@ public class Waiters implements GoIntegration {
                 writer.write("");
 
                 writer.openBlock("if value == bv {", "}", () -> {
-                    writeMatchedAcceptorReturn(writer, acceptor);
+                    writeMatchedAcceptorReturn(writer, waiterName, acceptor);
                 });
                 break;
 
@@ -756,7 +843,7 @@ public class Waiters implements GoIntegration {
                 writer.write("");
 
                 writer.openBlock("if match {", "}", () -> {
-                    writeMatchedAcceptorReturn(writer, acceptor);
+                    writeMatchedAcceptorReturn(writer, waiterName, acceptor);
                 });
                 break;
 
@@ -777,7 +864,7 @@ public class Waiters implements GoIntegration {
                             anyStringTargetSymbol, actual);
                     writer.write("");
                     writer.openBlock("if $L == $L {", "}", anyStringValueAccessor, expected, () -> {
-                        writeMatchedAcceptorReturn(writer, acceptor);
+                        writeMatchedAcceptorReturn(writer, waiterName, acceptor);
                     });
                 });
                 break;
@@ -792,18 +879,21 @@ public class Waiters implements GoIntegration {
     /**
      * Writes return statement for state where a waiter's acceptor state is a match.
      *
-     * @param writer   the Go writer
-     * @param acceptor the waiter acceptor who's state is used to write an appropriate return statement.
+     * @param writer     the Go writer
+     * @param waiterName the waiter name
+     * @param acceptor   the waiter acceptor who's state is used to write an appropriate return statement.
      */
-    private void writeMatchedAcceptorReturn(GoWriter writer, Acceptor acceptor) {
+    private void writeMatchedAcceptorReturn(GoWriter writer, String waiterName, Acceptor acceptor) {
         switch (acceptor.getState()) {
             case SUCCESS:
                 writer.write("return false, nil");
                 break;
 
             case FAILURE:
-                writer.addUseImports(SmithyGoDependency.FMT);
-                writer.write("return false, fmt.Errorf(\"waiter state transitioned to Failure\")");
+                Symbol failureStateErrorSymbol = SymbolUtils.createValueSymbolBuilder(
+                        "FailureStateError", SmithyGoDependency.SMITHY_WAITERS
+                ).build();
+                writer.write("return false, &$T{WaiterName: $S}", failureStateErrorSymbol, waiterName);
                 break;
 
             case RETRY:
@@ -30,6 +30,7 @@ import software.amazon.smithy.go.codegen.GoStdlibTypes;
 import software.amazon.smithy.go.codegen.GoWriter;
 import software.amazon.smithy.go.codegen.SmithyGoDependency;
 import software.amazon.smithy.go.codegen.SmithyGoTypes;
+import software.amazon.smithy.go.codegen.integration.ClientErrorRegistry;
 import software.amazon.smithy.go.codegen.integration.ProtocolGenerator;
 import software.amazon.smithy.go.codegen.protocol.DeserializeResponseMiddleware;
 import software.amazon.smithy.go.codegen.protocol.rpc2.Rpc2ProtocolGenerator;
@@ -140,7 +141,15 @@ public class Rpc2CborProtocolGenerator extends Rpc2ProtocolGenerator {
                     $errors:W
                     default:
                         $awsQueryCompatible:W
-                        return &$genericAPIError:T{Code: typ, Message: msg}
+                        apiErr, err := $errorRegistry:L.NewError($errorResponse:T{
+                            Code: typ,
+                            Message: msg,
+                            Body: payload,
+                        })
+                        if err != nil {
+                            return err
+                        }
+                        return apiErr
                     }
                 }
                 """,
@@ -153,7 +162,8 @@ public class Rpc2CborProtocolGenerator extends Rpc2ProtocolGenerator {
                         "deserError", SmithyGoDependency.SMITHY.pointableSymbol("DeserializationError"),
                         "fmtErrorf", GoStdlibTypes.Fmt.Errorf,
                         "func", ProtocolGenerator.getOperationErrorDeserFunctionName(operation, service, "rpc2"),
-                        "genericAPIError", SmithyGoDependency.SMITHY.pointableSymbol("GenericAPIError"),
+                        "errorRegistry", ClientErrorRegistry.ERROR_REGISTRY,
+                        "errorResponse", SmithyGoDependency.SMITHY.struct("ErrorResponse"),
                         "readAll", SmithyGoDependency.IO.func("ReadAll"),
                         "smithyhttpResponse", SmithyGoTypes.Transport.Http.Response,
                         "awsQueryCompatible", ctx.getService().hasTrait(AwsQueryCompatibleTrait.class)
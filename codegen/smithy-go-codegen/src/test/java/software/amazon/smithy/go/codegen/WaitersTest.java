@@ -0,0 +1,94 @@
+/*
+ * Copyright 2024 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License").
+ * You may not use this file except in compliance with the License.
+ * A copy of the License is located at
+ *
+ *  http://aws.amazon.com/apache2.0
+ *
+ * or in the "license" file accompanying this file. This file is distributed
+ * on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+ * express or implied. See the License for the specific language governing
+ * permissions and limitations under the License.
+ */
+
+package software.amazon.smithy.go.codegen;
+
+import static org.hamcrest.MatcherAssert.assertThat;
+import static org.hamcrest.Matchers.containsString;
+import static software.amazon.smithy.go.codegen.TestUtils.buildMockPluginContext;
+
+import org.junit.jupiter.api.Test;
+import software.amazon.smithy.build.MockManifest;
+import software.amazon.smithy.build.PluginContext;
+import software.amazon.smithy.model.Model;
+
+public class WaitersTest {
+    private static final String MODEL = """
+            $version: "2.0"
+            namespace smithy.example
+
+            use smithy.waiters#waitable
+
+            service Example {
+                version: "1.0.0"
+                operations: [GetThing]
+            }
+
+            @waitable(
+                ThingExists: {
+                    acceptors: [
+                        {
+                            state: "success"
+                            matcher: { success: true }
+                        }
+                        {
+                            state: "retry"
+                            matcher: { errorType: "NotFound" }
+                        }
+                    ]
+                }
+            )
+            operation GetThing {
+                input := {}
+                output := {}
+                errors: [NotFound]
+            }
+
+            @error("client")
+            structure NotFound {}
+            """;
+
+    private static String generateOperation() {
+        Model model = Model.assembler()
+                .addUnparsedModel("example.smithy", MODEL)
+                .discoverModels()
+                .assemble()
+                .unwrap();
+        MockManifest manifest = new MockManifest();
+        PluginContext context = buildMockPluginContext(model, manifest, "smithy.example#Example");
+
+        (new GoCodegenPlugin()).execute(context);
+
+        return manifest.getFileString("api_op_GetThing.go").get();
+    }
+
+    @Test
+    public void testRetryableReturnsUnmatchedOperationError() {
+        String actual = generateOperation();
+
+        assertThat("unmatched operation errors are returned by the retryable",
+                actual, containsString("\tif err != nil { return false, err }\n\treturn true, nil\n}"));
+    }
+
+    @Test
+    public void testWaiterStopsOnUnmatchedOperationError() {
+        String actual = generateOperation();
+
+        assertThat("operation errors returned by the retryable stop the waiter",
+                actual, containsString("case retryErr != nil && err != nil && errors.Is(retryErr, err):"));
+        assertThat("operation errors returned by the retryable stop the waiter",
+                actual, containsString("NonRetryableError{"));
+    }
+}
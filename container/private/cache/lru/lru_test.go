@@ -52,6 +52,24 @@ func TestCache(t *testing.T) {
 	assertEntry(t, cache, 9, 0)
 }
 
+func TestCache_PutExisting(t *testing.T) {
+	cache := New(2).(*lru)
+
+	cache.Put(1, 2)
+	cache.Put(2, 3)
+	cache.Put(1, 4)
+	assertEntry(t, cache, 1, 4)
+	if e, a := 2, cache.mru.Len(); e != a {
+		t.Errorf("expected %v elements, got %v", e, a)
+	}
+
+	// 2 is the oldest, since 1 was replaced
+	cache.Put(3, 4)
+	assertNoEntry(t, cache, 2)
+	assertEntry(t, cache, 1, 4)
+	assertEntry(t, cache, 3, 4)
+}
+
 func assertEntry(t *testing.T, c *lru, k interface{}, v interface{}) {
 	e, ok := c.entries[k]
 	if !ok {
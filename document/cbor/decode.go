@@ -2,6 +2,7 @@ package cbor
 
 import (
 	"fmt"
+	"math"
 	"math/big"
 	"reflect"
 
@@ -14,7 +15,17 @@ import (
 //
 // FUTURE(rpc2cbor): document support is currently disabled. This API is
 // unexported until that changes.
-type decoderOptions struct{}
+type decoderOptions struct {
+	// Controls whether numbers may be unmarshaled into Go integer types from
+	// floating-point values and vice versa. Defaults to
+	// document.NumberCoercionDefault.
+	NumberCoercion document.NumberCoercion
+
+	// The custom types decoded from tagged CBOR text strings by their
+	// document.TypeCodec. Should be shared with the encoder so that values
+	// round-trip.
+	TypeRegistry *document.TypeRegistry
+}
 
 // decoder is a Smithy document decoder for CBOR-based protocols.
 //
@@ -60,6 +71,22 @@ func (d *decoder) decode(cv cbor.Value, rv reflect.Value, tag serde.Tag) error {
 	}
 
 	rv = serde.Indirect(rv, false)
+	if c, ok := d.options.TypeRegistry.CodecForType(rv.Type()); ok {
+		return d.decodeTypeCodec(c, cv, rv)
+	}
+	if tv, ok := cv.(*cbor.Tag); ok && rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		if c, ok := d.options.TypeRegistry.CodecForCBORTag(tv.ID); ok {
+			return d.decodeTypeCodec(c, cv, rv)
+		}
+	}
+
+	if u, ok := asUnmarshaler(rv); ok {
+		if err := u.UnmarshalCBOR(cbor.Encode(cv)); err != nil {
+			return &document.UnmarshalError{Err: err, Value: "cbor", Type: rv.Type()}
+		}
+		return nil
+	}
+
 	if err := d.unsupportedType(rv); err != nil {
 		return err
 	}
@@ -84,6 +111,27 @@ func (d *decoder) decode(cv cbor.Value, rv reflect.Value, tag serde.Tag) error {
 	}
 }
 
+func (d *decoder) decodeTypeCodec(c document.TypeCodec, cv cbor.Value, rv reflect.Value) error {
+	tv, ok := cv.(*cbor.Tag)
+	if !ok || tv.ID != c.CBORTag {
+		return &document.UnmarshalTypeError{Value: fmt.Sprintf("%T", cv), Type: c.Type}
+	}
+	str, ok := tv.Value.(cbor.String)
+	if !ok {
+		return &document.UnmarshalTypeError{Value: fmt.Sprintf("tagged %T", tv.Value), Type: c.Type}
+	}
+	return c.ParseValue(string(str), rv)
+}
+
+func asUnmarshaler(rv reflect.Value) (cbor.Unmarshaler, bool) {
+	if !rv.CanAddr() || !rv.Addr().CanInterface() {
+		return nil, false
+	}
+
+	u, ok := rv.Addr().Interface().(cbor.Unmarshaler)
+	return u, ok
+}
+
 func (d *decoder) decodeInt(v cbor.Value, rv reflect.Value) error {
 	switch rv.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -110,6 +158,27 @@ func (d *decoder) decodeInt(v cbor.Value, rv reflect.Value) error {
 			}
 		}
 		rv.SetUint(uint64(u))
+	case reflect.Float32, reflect.Float64:
+		policy := d.options.NumberCoercion
+		if policy == document.NumberCoercionDefault {
+			return &document.UnmarshalTypeError{Value: "number", Type: rv.Type()}
+		}
+
+		i, err := cbor.AsBigInt(v)
+		if err != nil {
+			return err
+		}
+		f, ok := serde.IntegerToFloat(i, rv.Type().Bits(), policy)
+		if !ok {
+			return &document.NumberCoercionError{Value: i.String(), Type: rv.Type(), Policy: policy}
+		}
+		if math.IsInf(f, 0) {
+			return &document.UnmarshalTypeError{
+				Value: fmt.Sprintf("float overflow, %s", i.String()),
+				Type:  rv.Type(),
+			}
+		}
+		rv.SetFloat(f)
 	default:
 		return &document.UnmarshalTypeError{Value: "number", Type: rv.Type()}
 	}
@@ -134,6 +203,26 @@ func (d *decoder) decodeBool(v bool, rv reflect.Value) error {
 }
 
 func (d *decoder) decodeFloat(v float64, rv reflect.Value) error {
+	if policy := d.options.NumberCoercion; policy != document.NumberCoercionDefault && serde.IsIntegerKind(rv.Kind()) {
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return &document.UnmarshalTypeError{
+				Value: fmt.Sprintf("int overflow, %e", v),
+				Type:  rv.Type(),
+			}
+		}
+		i, ok := serde.FloatToInteger(big.NewFloat(v), policy)
+		if !ok {
+			return &document.NumberCoercionError{Value: fmt.Sprintf("%g", v), Type: rv.Type(), Policy: policy}
+		}
+		if !serde.SetInteger(rv, i) {
+			return &document.UnmarshalTypeError{
+				Value: fmt.Sprintf("int overflow, %e", v),
+				Type:  rv.Type(),
+			}
+		}
+		return nil
+	}
+
 	switch rv.Kind() {
 	case reflect.Interface:
 		rv.Set(reflect.ValueOf(v))
@@ -185,7 +274,7 @@ func (d *decoder) decodeList(v cbor.List, rv reflect.Value) error {
 		s := make([]interface{}, len(v))
 		for i, av := range v {
 			if err := d.decode(av, reflect.ValueOf(&s[i]).Elem(), serde.Tag{}); err != nil {
-				return err
+				return serde.PrependErrorPath(err, fmt.Sprintf("[%d]", i))
 			}
 		}
 		rv.Set(reflect.ValueOf(s))
@@ -200,7 +289,7 @@ func (d *decoder) decodeList(v cbor.List, rv reflect.Value) error {
 			rv.SetLen(i + 1)
 		}
 		if err := d.decode(v[i], rv.Index(i), serde.Tag{}); err != nil {
-			return err
+			return serde.PrependErrorPath(err, fmt.Sprintf("[%d]", i))
 		}
 	}
 
@@ -249,7 +338,7 @@ func (d *decoder) decodeMap(tv cbor.Map, rv reflect.Value) error {
 			key.SetString(k)
 			elem := reflect.New(rv.Type().Elem()).Elem()
 			if err := d.decode(kv, elem, serde.Tag{}); err != nil {
-				return err
+				return serde.PrependErrorPath(err, k)
 			}
 			rv.SetMapIndex(key, elem)
 		}
@@ -259,7 +348,7 @@ func (d *decoder) decodeMap(tv cbor.Map, rv reflect.Value) error {
 			if f, ok := fields.FieldByName(k); ok {
 				fv := serde.DecoderFieldByIndex(rv, f.Index)
 				if err := d.decode(kv, fv, f.Tag); err != nil {
-					return err
+					return serde.PrependErrorPath(err, k)
 				}
 			}
 		}
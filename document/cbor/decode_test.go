@@ -1,11 +1,13 @@
 package cbor
 
 import (
+	"errors"
 	"math"
 	"math/big"
 	"reflect"
 	"testing"
 
+	"github.com/aws/smithy-go/document"
 	"github.com/aws/smithy-go/encoding/cbor"
 	"github.com/aws/smithy-go/ptr"
 )
@@ -128,3 +130,146 @@ func TestDecode_KitchenSink(t *testing.T) {
 		t.Errorf("%v != %v", expect, actual)
 	}
 }
+
+func TestDecode_Unmarshaler(t *testing.T) {
+	type target struct {
+		Value    cents
+		ValuePtr *cents
+		List     []cents
+	}
+
+	in := cbor.Map{
+		"Value":    cbor.String("12.34"),
+		"ValuePtr": cbor.String("2.50"),
+		"List":     cbor.List{cbor.String("0.01"), cbor.String("1.00")},
+	}
+
+	v := cents(250)
+	expect := target{
+		Value:    1234,
+		ValuePtr: &v,
+		List:     []cents{1, 100},
+	}
+
+	var actual target
+	if err := (&decoder{}).Decode(in, &actual); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expect, actual) {
+		t.Errorf("%v != %v", expect, actual)
+	}
+}
+
+func TestDecode_UnmarshalerError(t *testing.T) {
+	var actual cents
+	err := (&decoder{}).Decode(cbor.Uint(1), &actual)
+	if err == nil {
+		t.Fatal("expect error")
+	}
+
+	var uerr *document.UnmarshalError
+	if !errors.As(err, &uerr) {
+		t.Errorf("expect %T, got %v", uerr, err)
+	}
+}
+
+func TestDecode_NumberCoercion(t *testing.T) {
+	type target struct {
+		Int   int32
+		Uint  uint64
+		Float float64
+		List  []float32
+	}
+
+	cases := map[string]struct {
+		in          cbor.Map
+		policy      document.NumberCoercion
+		want        target
+		wantErrPath string
+		wantErr     bool
+	}{
+		"default int into float": {
+			in:      cbor.Map{"Float": cbor.Uint(1)},
+			wantErr: true,
+		},
+		"default integral float into int": {
+			in:   cbor.Map{"Int": cbor.Float64(2)},
+			want: target{Int: 2},
+		},
+		"strict": {
+			in:          cbor.Map{"Int": cbor.Float64(2)},
+			policy:      document.NumberCoercionStrict,
+			wantErrPath: "Int",
+		},
+		"lossless": {
+			in: cbor.Map{
+				"Int":   cbor.Float64(-2),
+				"Uint":  cbor.Float64(1 << 60),
+				"Float": cbor.NegInt(1 << 53),
+				"List":  cbor.List{cbor.Uint(1 << 24)},
+			},
+			policy: document.NumberCoercionLossless,
+			want:   target{Int: -2, Uint: 1 << 60, Float: -(1 << 53), List: []float32{1 << 24}},
+		},
+		"lossless inexact": {
+			in:          cbor.Map{"List": cbor.List{cbor.Uint(1), cbor.Uint(1<<24 + 1)}},
+			policy:      document.NumberCoercionLossless,
+			wantErrPath: "List[1]",
+		},
+		"lossless fraction": {
+			in:          cbor.Map{"Int": cbor.Float64(0.5)},
+			policy:      document.NumberCoercionLossless,
+			wantErrPath: "Int",
+		},
+		"permissive": {
+			in: cbor.Map{
+				"Int":   cbor.Float64(-2.5),
+				"Float": cbor.Uint(1<<53 + 1),
+			},
+			policy: document.NumberCoercionPermissive,
+			want:   target{Int: -2, Float: 1 << 53},
+		},
+		"permissive negative into uint": {
+			in:      cbor.Map{"Uint": cbor.Float64(-0.5e1)},
+			policy:  document.NumberCoercionPermissive,
+			wantErr: true,
+		},
+		"permissive nan": {
+			in:      cbor.Map{"Int": cbor.Float64(math.NaN())},
+			policy:  document.NumberCoercionPermissive,
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := newDecoder(func(o *decoderOptions) {
+				o.NumberCoercion = tt.policy
+			})
+
+			var actual target
+			err := d.Decode(tt.in, &actual)
+
+			var cerr *document.NumberCoercionError
+			if tt.wantErrPath != "" {
+				if !errors.As(err, &cerr) {
+					t.Fatalf("expect %T, got %v", cerr, err)
+				}
+				if e, a := tt.wantErrPath, cerr.Path; e != a {
+					t.Errorf("expect path %q, got %q", e, a)
+				}
+				return
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expect err %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(tt.want, actual) {
+				t.Errorf("%v != %v", tt.want, actual)
+			}
+		})
+	}
+}
@@ -14,7 +14,12 @@ import (
 //
 // FUTURE(rpc2cbor): document support is currently disabled. This API is
 // unexported until that changes.
-type encoderOptions struct{}
+type encoderOptions struct {
+	// The custom types encoded as tagged CBOR text strings by their
+	// document.TypeCodec. Should be shared with the decoder so that values
+	// round-trip.
+	TypeRegistry *document.TypeRegistry
+}
 
 // encoder is a Smithy document encoder for CBOR-based protocols.
 //
@@ -51,10 +56,32 @@ func (e *encoder) Encode(v interface{}) ([]byte, error) {
 }
 
 func (e *encoder) encode(rv reflect.Value, tag serde.Tag) (cbor.Value, error) {
-	if serde.IsZeroValue(rv) {
-		if tag.OmitEmpty {
-			return nil, nil
+	isZero := serde.IsZeroValue(rv)
+	if isZero && tag.OmitEmpty {
+		return nil, nil
+	}
+	if !rv.IsValid() {
+		return e.encodeZeroValue(rv)
+	}
+
+	if c, cv, ok := serde.TypeCodecElem(e.options.TypeRegistry, rv); ok {
+		str, err := c.FormatValue(cv)
+		if err != nil {
+			return nil, err
 		}
+		return &cbor.Tag{ID: c.CBORTag, Value: cbor.String(str)}, nil
+	}
+
+	// a zero value of a Marshaler type, e.g. cents(0), is still encoded by its
+	// MarshalCBOR, such that it round-trips through UnmarshalCBOR, a nil
+	// pointer or interface has no value to marshal
+	if !isNilPointer(rv) {
+		if m, ok := asMarshaler(rv); ok {
+			return e.encodeMarshaler(m, rv)
+		}
+	}
+
+	if isZero {
 		return e.encodeZeroValue(rv)
 	}
 
@@ -94,6 +121,56 @@ func (e *encoder) encodeZeroValue(rv reflect.Value) (cbor.Value, error) {
 	}
 }
 
+func (e *encoder) encodeMarshaler(m cbor.Marshaler, rv reflect.Value) (cbor.Value, error) {
+	p, err := m.MarshalCBOR()
+	if err != nil {
+		return nil, &document.InvalidMarshalError{
+			Message: fmt.Sprintf("marshal %s: %v", rv.Type().String(), err),
+		}
+	}
+
+	// the result is embedded as-is, make sure it's at least well-formed
+	if _, err := cbor.Decode(p); err != nil {
+		return nil, &document.InvalidMarshalError{
+			Message: fmt.Sprintf("invalid CBOR from %s.MarshalCBOR", rv.Type().String()),
+		}
+	}
+
+	return cbor.EncodeRaw(p), nil
+}
+
+func isNilPointer(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+// asMarshaler walks interface and pointer types of rv and returns the first
+// value that implements cbor.Marshaler, including the address of the
+// underlying element when that is available.
+func asMarshaler(rv reflect.Value) (cbor.Marshaler, bool) {
+	for {
+		if rv.CanInterface() {
+			if m, ok := rv.Interface().(cbor.Marshaler); ok {
+				return m, true
+			}
+		}
+		if rv.Kind() != reflect.Interface && rv.Kind() != reflect.Ptr || rv.IsNil() {
+			break
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.CanAddr() && rv.Addr().CanInterface() {
+		m, ok := rv.Addr().Interface().(cbor.Marshaler)
+		return m, ok
+	}
+	return nil, false
+}
+
 func (e *encoder) encodeStruct(rv reflect.Value) (cbor.Value, error) {
 	if rv.CanInterface() && document.IsNoSerde(rv.Interface()) {
 		return nil, &document.UnmarshalTypeError{
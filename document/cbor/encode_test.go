@@ -1,11 +1,15 @@
 package cbor
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/aws/smithy-go/document"
 	"github.com/aws/smithy-go/encoding/cbor"
 	"github.com/aws/smithy-go/ptr"
 )
@@ -113,3 +117,129 @@ func TestEncode_KitchenSink(t *testing.T) {
 		t.Errorf("%v != %v", expect, actual)
 	}
 }
+
+// cents is a domain type that controls its own wire form, a string
+// representation of its dollar amount.
+type cents int64
+
+func (c cents) MarshalCBOR() ([]byte, error) {
+	return cbor.Encode(cbor.String(fmt.Sprintf("%d.%02d", c/100, c%100))), nil
+}
+
+func (c *cents) UnmarshalCBOR(p []byte) error {
+	v, err := cbor.Decode(p)
+	if err != nil {
+		return err
+	}
+	s, ok := v.(cbor.String)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", v)
+	}
+
+	var dollars, rem int64
+	if _, err := fmt.Sscanf(string(s), "%d.%02d", &dollars, &rem); err != nil {
+		return err
+	}
+	*c = cents(dollars*100 + rem)
+	return nil
+}
+
+type invalidMarshaler struct{}
+
+func (invalidMarshaler) MarshalCBOR() ([]byte, error) {
+	return []byte{3<<5 | 24}, nil
+}
+
+func TestEncode_Marshaler(t *testing.T) {
+	type target struct {
+		Value    cents
+		ValuePtr *cents
+		List     []cents
+	}
+
+	v := cents(250)
+	in := target{
+		Value:    1234,
+		ValuePtr: &v,
+		List:     []cents{1, 100},
+	}
+
+	expect := cbor.Map{
+		"Value":    cbor.String("12.34"),
+		"ValuePtr": cbor.String("2.50"),
+		"List":     cbor.List{cbor.String("0.01"), cbor.String("1.00")},
+	}
+
+	enc := &encoder{}
+	encoded, err := enc.Encode(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := cbor.Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expect, actual) {
+		t.Errorf("%v != %v", expect, actual)
+	}
+}
+
+func TestEncode_MarshalerZeroValue(t *testing.T) {
+	type target struct {
+		Value    cents
+		ValuePtr *cents
+	}
+
+	in := target{}
+	expect := cbor.Map{
+		"Value":    cbor.String("0.00"),
+		"ValuePtr": &cbor.Nil{},
+	}
+
+	encoded, err := (&encoder{}).Encode(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := cbor.Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expect, actual) {
+		t.Errorf("%v != %v", expect, actual)
+	}
+
+	var out target
+	out.ValuePtr = new(cents)
+	if err := (&decoder{}).Decode(actual, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("%v != %v", in, out)
+	}
+}
+
+func TestEncode_InvalidMarshaler(t *testing.T) {
+	enc := &encoder{}
+	_, err := enc.Encode(struct{ V invalidMarshaler }{})
+	if err == nil {
+		t.Fatal("expect error")
+	}
+	if e, a := "invalid CBOR", err.Error(); !strings.Contains(a, e) {
+		t.Errorf("expect error to contain %q, got %q", e, a)
+	}
+}
+
+func TestEncode_Nil(t *testing.T) {
+	enc := &encoder{}
+	_, err := enc.Encode(nil)
+	if err == nil {
+		t.Fatal("expect error")
+	}
+	var mErr *document.InvalidMarshalError
+	if !errors.As(err, &mErr) {
+		t.Errorf("expect InvalidMarshalError, got %T", err)
+	}
+}
@@ -0,0 +1,98 @@
+package cbor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/document"
+	"github.com/aws/smithy-go/encoding/cbor"
+)
+
+func newTestTypeRegistry(t *testing.T) *document.TypeRegistry {
+	t.Helper()
+
+	r, err := document.NewTypeRegistry(document.TypeCodec{
+		Type:    reflect.TypeOf(time.Time{}),
+		CBORTag: 0, // standard date/time string
+		Format: func(v interface{}) (string, error) {
+			return v.(time.Time).Format(time.RFC3339Nano), nil
+		},
+		Parse: func(s string) (interface{}, error) {
+			return time.Parse(time.RFC3339Nano, s)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestTypeRegistry_RoundTrip(t *testing.T) {
+	type target struct {
+		Time   time.Time
+		Ptr    *time.Time
+		NilPtr *time.Time
+		Any    interface{}
+	}
+
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	in := target{
+		Time: ts,
+		Ptr:  &ts,
+		Any:  ts,
+	}
+
+	registry := newTestTypeRegistry(t)
+	p, err := newEncoder(func(o *encoderOptions) {
+		o.TypeRegistry = registry
+	}).Encode(in)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	cv, err := cbor.Decode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &cbor.Tag{ID: 0, Value: cbor.String("2020-01-02T03:04:05.000000006Z")}
+	if e, a := expect, cv.(cbor.Map)["Time"]; !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %#v, got %#v", e, a)
+	}
+
+	var out target
+	err = newDecoder(func(o *decoderOptions) {
+		o.TypeRegistry = registry
+	}).Decode(cv, &out)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("expect %v, got %v", in, out)
+	}
+}
+
+func TestTypeRegistry_DecodeErrors(t *testing.T) {
+	registry := newTestTypeRegistry(t)
+	decoder := newDecoder(func(o *decoderOptions) {
+		o.TypeRegistry = registry
+	})
+
+	cases := map[string]struct {
+		value cbor.Value
+	}{
+		"untagged":    {value: cbor.String("2020-01-02T03:04:05Z")},
+		"wrong tag":   {value: &cbor.Tag{ID: 1, Value: cbor.String("2020-01-02T03:04:05Z")}},
+		"not a text":  {value: &cbor.Tag{ID: 0, Value: cbor.Uint(1)}},
+		"parse error": {value: &cbor.Tag{ID: 0, Value: cbor.String("yesterday")}},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var out time.Time
+			if err := decoder.Decode(c.value, &out); err == nil {
+				t.Errorf("expect error")
+			}
+		})
+	}
+}
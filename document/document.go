@@ -44,7 +44,10 @@ import (
 // when walking the value to be marshaled.
 //
 // time.Time is not supported and will cause the Marshaler to return an error. These values should be represented
-// by your application as a string or numerical representation.
+// by your application as a string or numerical representation, or registered with a TypeRegistry.
+//
+// Custom types, such as UUIDs, decimals, and timestamps, can be registered with a TypeCodec in a TypeRegistry configured
+// on the Marshaler and Unmarshaler, such that they are marshaled by their string form consistently across protocols.
 //
 // Errors that occur when marshaling will stop the marshaler, and return the error.
 //
@@ -151,3 +154,49 @@ func (n Number) BigInt() (*big.Int, error) {
 	}
 	return f, nil
 }
+
+// NumberCoercion controls whether a document number may be unmarshaled into a
+// Go numeric type on the other side of the integer/floating-point boundary,
+// e.g. the JSON number 1.0 into an int, or 9007199254740993 into a float64.
+//
+// A number is considered an integer if it is encoded as one by the protocol.
+// For JSON, that is a literal without a fraction or exponent. JSON numbers
+// already decoded to float64, which carry no literal, are considered integers
+// if they are integral.
+//
+// Violations of the policy are returned as a NumberCoercionError.
+type NumberCoercion int
+
+// Enumeration values for NumberCoercion.
+const (
+	// Retain the historical behavior of each protocol decoder.
+	NumberCoercionDefault NumberCoercion = iota
+
+	// Integers may only be unmarshaled into integer types, and floating-point
+	// numbers into floating-point types.
+	NumberCoercionStrict
+
+	// Numbers may cross the boundary only if their exact value is preserved.
+	NumberCoercionLossless
+
+	// Numbers may always cross the boundary. Floating-point numbers are
+	// truncated toward zero, and integers rounded to the nearest
+	// floating-point value. Values out of range of the target type are still
+	// an error.
+	NumberCoercionPermissive
+)
+
+func (c NumberCoercion) String() string {
+	switch c {
+	case NumberCoercionDefault:
+		return "default"
+	case NumberCoercionStrict:
+		return "strict"
+	case NumberCoercionLossless:
+		return "lossless"
+	case NumberCoercionPermissive:
+		return "permissive"
+	default:
+		return fmt.Sprintf("NumberCoercion(%d)", int(c))
+	}
+}
@@ -73,3 +73,27 @@ type InvalidMarshalError struct {
 func (e *InvalidMarshalError) Error() string {
 	return fmt.Sprintf("marshal failed, %s", e.Message)
 }
+
+// A NumberCoercionError is an error type representing a document number that
+// could not be unmarshaled into a Go numeric type under the configured
+// NumberCoercion policy.
+type NumberCoercionError struct {
+	// The path to the number from the root of the document in the form
+	// "Items[2].Count", or empty if the document is the number itself.
+	Path string
+
+	Value  string
+	Type   reflect.Type
+	Policy NumberCoercion
+}
+
+// Error returns the string representation of the error.
+// Satisfying the error interface.
+func (e *NumberCoercionError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "document root"
+	}
+	return fmt.Sprintf("unmarshal failed, cannot coerce number %s at %s into Go value type %s under %s policy",
+		e.Value, path, e.Type.String(), e.Policy)
+}
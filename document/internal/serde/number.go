@@ -0,0 +1,102 @@
+package serde
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/aws/smithy-go/document"
+)
+
+// FloatToInteger converts a floating-point document number to an integer
+// under the given coercion policy, returning false if the policy does not
+// allow it. f must be finite.
+func FloatToInteger(f *big.Float, policy document.NumberCoercion) (*big.Int, bool) {
+	switch policy {
+	case document.NumberCoercionStrict:
+		return nil, false
+	case document.NumberCoercionPermissive:
+	default:
+		if !f.IsInt() {
+			return nil, false
+		}
+	}
+
+	i, _ := f.Int(nil) // truncates toward zero
+	return i, true
+}
+
+// IntegerToFloat converts an integer document number to a float of the given
+// bit size (32 or 64) under the given coercion policy, returning false if the
+// policy does not allow it. The result is infinite if i is out of range.
+func IntegerToFloat(i *big.Int, bitSize int, policy document.NumberCoercion) (float64, bool) {
+	if policy == document.NumberCoercionStrict {
+		return 0, false
+	}
+
+	bf := new(big.Float).SetInt(i)
+	var f float64
+	var accuracy big.Accuracy
+	if bitSize == 32 {
+		var f32 float32
+		f32, accuracy = bf.Float32()
+		f = float64(f32)
+	} else {
+		f, accuracy = bf.Float64()
+	}
+
+	if policy == document.NumberCoercionLossless && accuracy != big.Exact {
+		return 0, false
+	}
+	return f, true
+}
+
+// SetInteger stores i into rv, which must be of an integer kind, returning
+// false if i overflows it.
+func SetInteger(rv reflect.Value, i *big.Int) bool {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !i.IsInt64() || rv.OverflowInt(i.Int64()) {
+			return false
+		}
+		rv.SetInt(i.Int64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !i.IsUint64() || rv.OverflowUint(i.Uint64()) {
+			return false
+		}
+		rv.SetUint(i.Uint64())
+	default:
+		return false
+	}
+	return true
+}
+
+// IsIntegerKind returns whether k is one of the signed or unsigned integer
+// kinds.
+func IsIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// PrependErrorPath prefixes the path of a document.NumberCoercionError with
+// the name of a member, or an index in the form "[i]", as the error is
+// returned up through the value being unmarshaled. Other errors are returned
+// unchanged.
+func PrependErrorPath(err error, elem string) error {
+	var cerr *document.NumberCoercionError
+	if !errors.As(err, &cerr) {
+		return err
+	}
+
+	if cerr.Path == "" || strings.HasPrefix(cerr.Path, "[") {
+		cerr.Path = elem + cerr.Path
+	} else {
+		cerr.Path = elem + "." + cerr.Path
+	}
+	return err
+}
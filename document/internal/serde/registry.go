@@ -0,0 +1,26 @@
+package serde
+
+import (
+	"reflect"
+
+	"github.com/aws/smithy-go/document"
+)
+
+// TypeCodecElem walks the non-nil interface and pointer values of rv,
+// returning the first value whose type has a codec in the registry.
+func TypeCodecElem(r *document.TypeRegistry, rv reflect.Value) (document.TypeCodec, reflect.Value, bool) {
+	if r == nil {
+		return document.TypeCodec{}, rv, false
+	}
+
+	for rv.IsValid() {
+		if c, ok := r.CodecForType(rv.Type()); ok {
+			return c, rv, true
+		}
+		if rv.Kind() != reflect.Interface && rv.Kind() != reflect.Ptr || rv.IsNil() {
+			break
+		}
+		rv = rv.Elem()
+	}
+	return document.TypeCodec{}, rv, false
+}
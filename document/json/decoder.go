@@ -3,15 +3,28 @@ package json
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/aws/smithy-go/document"
 	"github.com/aws/smithy-go/document/internal/serde"
 )
 
 // DecoderOptions is the set of options that can be configured for a Decoder.
-type DecoderOptions struct{}
+type DecoderOptions struct {
+	// Controls whether numbers may be unmarshaled into Go integer types from
+	// floating-point values and vice versa. Defaults to
+	// document.NumberCoercionDefault.
+	NumberCoercion document.NumberCoercion
+
+	// The custom types decoded from JSON strings by their
+	// document.TypeCodec. Should be shared with the Encoder so that values
+	// round-trip.
+	TypeRegistry *document.TypeRegistry
+}
 
 // Decoder is a Smithy document decoder for JSON based protocols.
 type Decoder struct {
@@ -53,6 +66,14 @@ func (d *Decoder) decode(jv interface{}, rv reflect.Value, tag serde.Tag) error
 
 	rv = serde.Indirect(rv, false)
 
+	if c, ok := d.options.TypeRegistry.CodecForType(rv.Type()); ok {
+		str, ok := jv.(string)
+		if !ok {
+			return &document.UnmarshalTypeError{Value: fmt.Sprintf("%T", jv), Type: rv.Type()}
+		}
+		return c.ParseValue(str, rv)
+	}
+
 	if err := d.unsupportedType(jv, rv); err != nil {
 		return err
 	}
@@ -95,6 +116,12 @@ func (d *Decoder) decodeJSONBoolean(tv bool, rv reflect.Value) error {
 }
 
 func (d *Decoder) decodeJSONNumber(tv json.Number, rv reflect.Value) error {
+	if d.options.NumberCoercion != document.NumberCoercionDefault {
+		if ok, err := d.coerceJSONNumber(tv, rv); ok {
+			return err
+		}
+	}
+
 	switch rv.Kind() {
 	case reflect.Interface:
 		rv.Set(reflect.ValueOf(document.Number(tv)))
@@ -180,7 +207,73 @@ func (d *Decoder) decodeJSONNumber(tv json.Number, rv reflect.Value) error {
 	return nil
 }
 
+// coerceJSONNumber decodes a number crossing the integer/floating-point
+// boundary under the configured NumberCoercion policy, returning false if tv
+// and rv are on the same side of it.
+func (d *Decoder) coerceJSONNumber(tv json.Number, rv reflect.Value) (bool, error) {
+	policy := d.options.NumberCoercion
+	sv := tv.String()
+	isFloat := strings.ContainsAny(sv, ".eE")
+
+	switch {
+	case serde.IsIntegerKind(rv.Kind()) && isFloat:
+		f, ok := new(big.Float).SetPrec(uint(len(sv))*4 + 64).SetString(sv)
+		if !ok || f.IsInf() {
+			return true, &document.UnmarshalTypeError{
+				Value: fmt.Sprintf("invalid number format, %s", sv),
+				Type:  rv.Type(),
+			}
+		}
+		i, ok := serde.FloatToInteger(f, policy)
+		if !ok {
+			return true, &document.NumberCoercionError{Value: sv, Type: rv.Type(), Policy: policy}
+		}
+		if !serde.SetInteger(rv, i) {
+			return true, &document.UnmarshalTypeError{
+				Value: fmt.Sprintf("number overflow, %s", sv),
+				Type:  rv.Type(),
+			}
+		}
+		return true, nil
+	case (rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64) && !isFloat:
+		i, ok := new(big.Int).SetString(sv, 10)
+		if !ok {
+			return true, &document.UnmarshalTypeError{
+				Value: fmt.Sprintf("invalid number format, %s", sv),
+				Type:  rv.Type(),
+			}
+		}
+		f, ok := serde.IntegerToFloat(i, rv.Type().Bits(), policy)
+		if !ok {
+			return true, &document.NumberCoercionError{Value: sv, Type: rv.Type(), Policy: policy}
+		}
+		if math.IsInf(f, 0) {
+			return true, &document.UnmarshalTypeError{
+				Value: fmt.Sprintf("float overflow, %s", sv),
+				Type:  rv.Type(),
+			}
+		}
+		rv.SetFloat(f)
+		return true, nil
+	}
+	return false, nil
+}
+
 func (d *Decoder) decodeJSONFloat64(tv float64, rv reflect.Value) error {
+	if serde.IsIntegerKind(rv.Kind()) && tv != math.Trunc(tv) {
+		switch policy := d.options.NumberCoercion; policy {
+		case document.NumberCoercionDefault:
+		case document.NumberCoercionPermissive:
+			tv = math.Trunc(tv)
+		default:
+			return &document.NumberCoercionError{
+				Value:  strconv.FormatFloat(tv, 'g', -1, 64),
+				Type:   rv.Type(),
+				Policy: policy,
+			}
+		}
+	}
+
 	switch rv.Kind() {
 	case reflect.Interface:
 		rv.Set(reflect.ValueOf(tv))
@@ -249,7 +342,7 @@ func (d *Decoder) decodeJSONArray(tv []interface{}, rv reflect.Value) error {
 		s := make([]interface{}, len(tv))
 		for i, av := range tv {
 			if err := d.decode(av, reflect.ValueOf(&s[i]).Elem(), serde.Tag{}); err != nil {
-				return err
+				return serde.PrependErrorPath(err, fmt.Sprintf("[%d]", i))
 			}
 		}
 		rv.Set(reflect.ValueOf(s))
@@ -264,7 +357,7 @@ func (d *Decoder) decodeJSONArray(tv []interface{}, rv reflect.Value) error {
 			rv.SetLen(i + 1)
 		}
 		if err := d.decode(tv[i], rv.Index(i), serde.Tag{}); err != nil {
-			return err
+			return serde.PrependErrorPath(err, fmt.Sprintf("[%d]", i))
 		}
 	}
 
@@ -315,7 +408,7 @@ func (d *Decoder) decodeJSONObject(tv map[string]interface{}, rv reflect.Value)
 			key.SetString(k)
 			elem := reflect.New(rv.Type().Elem()).Elem()
 			if err := d.decode(kv, elem, serde.Tag{}); err != nil {
-				return err
+				return serde.PrependErrorPath(err, k)
 			}
 			rv.SetMapIndex(key, elem)
 		}
@@ -325,7 +418,7 @@ func (d *Decoder) decodeJSONObject(tv map[string]interface{}, rv reflect.Value)
 			if f, ok := fields.FieldByName(k); ok {
 				fv := serde.DecoderFieldByIndex(rv, f.Index)
 				if err := d.decode(kv, fv, f.Tag); err != nil {
-					return err
+					return serde.PrependErrorPath(err, k)
 				}
 			}
 		}
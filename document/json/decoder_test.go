@@ -1,8 +1,10 @@
 package json_test
 
 import (
+	"errors"
 	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -144,6 +146,137 @@ func TestNewDecoderUnsupportedTypes(t *testing.T) {
 	}
 }
 
+func TestDecoder_NumberCoercion(t *testing.T) {
+	type target struct {
+		Int   int64
+		Uint  uint8
+		Float float64
+		F32   float32
+		List  []struct{ Int int32 }
+		Map   map[string]int
+	}
+
+	cases := map[string]struct {
+		json              string
+		disableJSONNumber bool
+		policy            document.NumberCoercion
+		want              target
+		wantErrPath       string
+		wantErr           bool
+	}{
+		"default integral float literal": {
+			json:    `{"Int": 1.0}`,
+			wantErr: true,
+		},
+		"default lossy integer": {
+			json: `{"Float": 9007199254740993}`,
+			want: target{Float: 9007199254740992},
+		},
+		"strict integer": {
+			json:   `{"Int": 1, "Float": 1.5}`,
+			policy: document.NumberCoercionStrict,
+			want:   target{Int: 1, Float: 1.5},
+		},
+		"strict integral float literal": {
+			json:        `{"Int": 1.0}`,
+			policy:      document.NumberCoercionStrict,
+			wantErrPath: "Int",
+		},
+		"strict integer into float": {
+			json:        `{"Float": 1}`,
+			policy:      document.NumberCoercionStrict,
+			wantErrPath: "Float",
+		},
+		"strict integral float64": {
+			json:              `{"Int": 1}`,
+			disableJSONNumber: true,
+			policy:            document.NumberCoercionStrict,
+			want:              target{Int: 1},
+		},
+		"lossless integral float literal": {
+			json:   `{"Int": 1.5e3, "Uint": 2.00, "Float": 9007199254740992, "F32": 16777216}`,
+			policy: document.NumberCoercionLossless,
+			want:   target{Int: 1500, Uint: 2, Float: 9007199254740992, F32: 16777216},
+		},
+		"lossless fraction": {
+			json:        `{"List": [{"Int": 1}, {"Int": 2.5}]}`,
+			policy:      document.NumberCoercionLossless,
+			wantErrPath: "List[1].Int",
+		},
+		"lossless fraction float64": {
+			json:              `{"Map": {"a": 2.5}}`,
+			disableJSONNumber: true,
+			policy:            document.NumberCoercionLossless,
+			wantErrPath:       "Map.a",
+		},
+		"lossless inexact float64": {
+			json:        `{"Float": 9007199254740993}`,
+			policy:      document.NumberCoercionLossless,
+			wantErrPath: "Float",
+		},
+		"lossless inexact float32": {
+			json:        `{"F32": 16777217}`,
+			policy:      document.NumberCoercionLossless,
+			wantErrPath: "F32",
+		},
+		"permissive": {
+			json:   `{"Int": -2.9, "Uint": 3.5e0, "Float": 9007199254740993}`,
+			policy: document.NumberCoercionPermissive,
+			want:   target{Int: -2, Uint: 3, Float: 9007199254740992},
+		},
+		"permissive float64": {
+			json:              `{"Int": 2.9}`,
+			disableJSONNumber: true,
+			policy:            document.NumberCoercionPermissive,
+			want:              target{Int: 2},
+		},
+		"permissive overflow": {
+			json:    `{"Uint": 256.5}`,
+			policy:  document.NumberCoercionPermissive,
+			wantErr: true,
+		},
+		"permissive float32 overflow": {
+			json:    `{"F32": 1` + strings.Repeat("0", 39) + `}`,
+			policy:  document.NumberCoercionPermissive,
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := json.NewDecoder(func(o *json.DecoderOptions) {
+				o.NumberCoercion = tt.policy
+			})
+
+			var actual target
+			err := d.DecodeJSONInterface(MustJSONUnmarshal([]byte(tt.json), !tt.disableJSONNumber), &actual)
+
+			var cerr *document.NumberCoercionError
+			if tt.wantErrPath != "" {
+				if !errors.As(err, &cerr) {
+					t.Fatalf("expect %T, got %v", cerr, err)
+				}
+				if e, a := tt.wantErrPath, cerr.Path; e != a {
+					t.Errorf("expect path %q, got %q", e, a)
+				}
+				return
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expect err %v, got %v", tt.wantErr, err)
+			}
+			if errors.As(err, &cerr) {
+				t.Errorf("expect non-coercion error, got %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(tt.want, actual) {
+				t.Errorf("%v != %v", tt.want, actual)
+			}
+		})
+	}
+}
+
 func testDecodeJSONInterface(t *testing.T, tt testCase) {
 	t.Helper()
 
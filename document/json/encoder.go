@@ -1,6 +1,7 @@
 package json
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -11,7 +12,11 @@ import (
 )
 
 // EncoderOptions is the set of options that can be configured for an Encoder.
-type EncoderOptions struct{}
+type EncoderOptions struct {
+	// The custom types encoded as JSON strings by their document.TypeCodec.
+	// Should be shared with the Decoder so that values round-trip.
+	TypeRegistry *document.TypeRegistry
+}
 
 // Encoder is a Smithy document decoder for JSON based protocols.
 type Encoder struct {
@@ -22,7 +27,14 @@ type Encoder struct {
 func (e *Encoder) Encode(v interface{}) ([]byte, error) {
 	encoder := smithyjson.NewEncoder()
 
-	if err := e.encode(jsonValueProvider(encoder.Value), reflect.ValueOf(v), serde.Tag{}); err != nil {
+	// values composed of the Go native document types are written directly,
+	// anything else falls back to walking the value by reflection
+	err := encoder.Value.Document(v)
+	var unsupported *smithyjson.UnsupportedDocumentTypeError
+	if errors.As(err, &unsupported) {
+		err = e.encode(jsonValueProvider(encoder.Value), reflect.ValueOf(v), serde.Tag{})
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -68,11 +80,23 @@ func (p jsonArrayProvider) GetValue() smithyjson.Value {
 }
 
 func (e *Encoder) encode(vp valueProvider, rv reflect.Value, tag serde.Tag) error {
-	// Zero values are serialized as null, or skipped if omitEmpty.
-	if serde.IsZeroValue(rv) {
-		if tag.OmitEmpty {
-			return nil
+	// Zero values are serialized as null, or skipped if omitEmpty. Custom
+	// types are always serialized by their codec unless omitted.
+	isZero := serde.IsZeroValue(rv)
+	if isZero && tag.OmitEmpty {
+		return nil
+	}
+
+	if c, cv, ok := serde.TypeCodecElem(e.options.TypeRegistry, rv); ok {
+		str, err := c.FormatValue(cv)
+		if err != nil {
+			return err
 		}
+		vp.GetValue().String(str)
+		return nil
+	}
+
+	if isZero {
 		return e.encodeZeroValue(vp, rv)
 	}
 
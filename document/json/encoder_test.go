@@ -1,6 +1,8 @@
 package json_test
 
 import (
+	"errors"
+	"math"
 	"reflect"
 	"testing"
 	"time"
@@ -67,6 +69,15 @@ func TestNewEncoderUnsupportedTypes(t *testing.T) {
 	}
 }
 
+func TestEncoder_EncodeNonFiniteFloat(t *testing.T) {
+	encoder := json.NewEncoder()
+	_, err := encoder.Encode(map[string]interface{}{"a": math.NaN()})
+	var invalid *document.InvalidMarshalError
+	if !errors.As(err, &invalid) {
+		t.Errorf("expect invalid marshal error, got %v", err)
+	}
+}
+
 func testEncode(t *testing.T, tt testCase) {
 	t.Helper()
 
@@ -0,0 +1,103 @@
+package json_test
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/aws/smithy-go/document"
+	"github.com/aws/smithy-go/document/json"
+)
+
+type testUUID [16]byte
+
+func newTestTypeRegistry(t *testing.T) *document.TypeRegistry {
+	t.Helper()
+
+	r, err := document.NewTypeRegistry(document.TypeCodec{
+		Type:    reflect.TypeOf(testUUID{}),
+		CBORTag: 37,
+		Format: func(v interface{}) (string, error) {
+			u := v.(testUUID)
+			return hex.EncodeToString(u[:]), nil
+		},
+		Parse: func(s string) (interface{}, error) {
+			var u testUUID
+			p, err := hex.DecodeString(s)
+			if err != nil {
+				return nil, err
+			}
+			copy(u[:], p)
+			return u, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestTypeRegistry_RoundTrip(t *testing.T) {
+	type target struct {
+		ID     testUUID
+		Ptr    *testUUID
+		NilPtr *testUUID
+		Zero   testUUID
+		List   []testUUID
+	}
+
+	id := testUUID{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+	in := target{
+		ID:   id,
+		Ptr:  &id,
+		List: []testUUID{{}, id},
+	}
+
+	registry := newTestTypeRegistry(t)
+	p, err := json.NewEncoder(func(o *json.EncoderOptions) {
+		o.TypeRegistry = registry
+	}).Encode(in)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	expect := `{"ID":"0123456789abcdef0000000000000000","Ptr":"0123456789abcdef0000000000000000","NilPtr":null,` +
+		`"Zero":"00000000000000000000000000000000","List":["00000000000000000000000000000000","0123456789abcdef0000000000000000"]}`
+	if e, a := expect, string(p); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	var out target
+	err = json.NewDecoder(func(o *json.DecoderOptions) {
+		o.TypeRegistry = registry
+	}).DecodeJSONInterface(MustJSONUnmarshal(p, true), &out)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("expect %v, got %v", in, out)
+	}
+}
+
+func TestTypeRegistry_DecodeErrors(t *testing.T) {
+	registry := newTestTypeRegistry(t)
+	decoder := json.NewDecoder(func(o *json.DecoderOptions) {
+		o.TypeRegistry = registry
+	})
+
+	cases := map[string]struct {
+		json string
+	}{
+		"not a string": {json: `{"ID":1}`},
+		"parse error":  {json: `{"ID":"xyz"}`},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var out struct{ ID testUUID }
+			if err := decoder.DecodeJSONInterface(MustJSONUnmarshal([]byte(c.json), true), &out); err == nil {
+				t.Errorf("expect error")
+			}
+		})
+	}
+}
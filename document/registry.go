@@ -0,0 +1,148 @@
+package document
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// reservedCBORTags are the CBOR tag numbers used by the document encoders for
+// big numbers, which cannot be registered for custom types.
+var reservedCBORTags = map[uint64]struct{}{
+	2: {}, // unsigned bignum
+	3: {}, // negative bignum
+	4: {}, // decimal fraction
+}
+
+// TypeCodec describes how values of a custom Go type, e.g. a UUID, decimal,
+// or timestamp, are represented in documents.
+//
+// A value is represented by its string form: as a JSON string for JSON based
+// protocols, and as a text string wrapped in CBORTag for CBOR based
+// protocols. Since the tag identifies the type, a tagged CBOR value is
+// decoded as the custom type even into an empty interface, whereas a JSON
+// string is only decoded as the custom type into a value of that type.
+type TypeCodec struct {
+	// The custom type. Pointers to the type are also encoded and decoded with
+	// the codec.
+	Type reflect.Type
+
+	// The CBOR tag number wrapping the string form, e.g. 37 for a UUID. Tags
+	// 2, 3, and 4 are reserved for big numbers.
+	CBORTag uint64
+
+	// Format returns the string form of v, which is a value of Type.
+	Format func(v interface{}) (string, error)
+
+	// Parse returns the value of Type with the string form s.
+	Parse func(s string) (interface{}, error)
+}
+
+// TypeRegistry is the set of custom types with a TypeCodec, shared by the
+// encoders and decoders of each protocol so that the types round-trip
+// consistently. The zero value is an empty registry, and a nil
+// *TypeRegistry has no types.
+//
+// A TypeRegistry is safe for concurrent use.
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	types map[reflect.Type]TypeCodec
+	tags  map[uint64]TypeCodec
+}
+
+// NewTypeRegistry returns a TypeRegistry with the codecs registered.
+func NewTypeRegistry(codecs ...TypeCodec) (*TypeRegistry, error) {
+	r := &TypeRegistry{}
+	for _, c := range codecs {
+		if err := r.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds the codec to the registry. Returns an error if the codec is
+// incomplete, or its type or CBOR tag is already registered.
+func (r *TypeRegistry) Register(c TypeCodec) error {
+	if c.Type == nil || c.Format == nil || c.Parse == nil {
+		return fmt.Errorf("type codec must have Type, Format, and Parse")
+	}
+	if c.Type.Kind() == reflect.Ptr || c.Type.Kind() == reflect.Interface {
+		return fmt.Errorf("type codec for %v must not be a pointer or interface type", c.Type)
+	}
+	if _, ok := reservedCBORTags[c.CBORTag]; ok {
+		return fmt.Errorf("CBOR tag %d is reserved", c.CBORTag)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.types[c.Type]; ok {
+		return fmt.Errorf("type %v is already registered", c.Type)
+	}
+	if existing, ok := r.tags[c.CBORTag]; ok {
+		return fmt.Errorf("CBOR tag %d is already registered for %v", c.CBORTag, existing.Type)
+	}
+
+	if r.types == nil {
+		r.types = map[reflect.Type]TypeCodec{}
+		r.tags = map[uint64]TypeCodec{}
+	}
+	r.types[c.Type] = c
+	r.tags[c.CBORTag] = c
+	return nil
+}
+
+// CodecForType returns the codec registered for t, if any.
+func (r *TypeRegistry) CodecForType(t reflect.Type) (TypeCodec, bool) {
+	if r == nil {
+		return TypeCodec{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.types[t]
+	return c, ok
+}
+
+// CodecForCBORTag returns the codec registered for the CBOR tag number, if
+// any.
+func (r *TypeRegistry) CodecForCBORTag(tag uint64) (TypeCodec, bool) {
+	if r == nil {
+		return TypeCodec{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.tags[tag]
+	return c, ok
+}
+
+// FormatValue returns the string form of rv with the codec.
+func (c TypeCodec) FormatValue(rv reflect.Value) (string, error) {
+	s, err := c.Format(rv.Interface())
+	if err != nil {
+		return "", &InvalidMarshalError{Message: fmt.Sprintf("format %v: %v", c.Type, err)}
+	}
+	return s, nil
+}
+
+// ParseValue parses s with the codec and stores the result in rv, which must
+// be settable and either of the codec's type or an empty interface.
+func (c TypeCodec) ParseValue(s string, rv reflect.Value) error {
+	v, err := c.Parse(s)
+	if err != nil {
+		return &UnmarshalError{Err: err, Value: "string", Type: c.Type}
+	}
+
+	pv := reflect.ValueOf(v)
+	if !pv.IsValid() || pv.Type() != c.Type {
+		return &UnmarshalError{
+			Err:   fmt.Errorf("parse returned %T", v),
+			Value: "string",
+			Type:  c.Type,
+		}
+	}
+	rv.Set(pv)
+	return nil
+}
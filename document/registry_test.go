@@ -0,0 +1,107 @@
+package document
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testID string
+
+func testIDCodec(tag uint64) TypeCodec {
+	return TypeCodec{
+		Type:    reflect.TypeOf(testID("")),
+		CBORTag: tag,
+		Format:  func(v interface{}) (string, error) { return string(v.(testID)), nil },
+		Parse:   func(s string) (interface{}, error) { return testID(s), nil },
+	}
+}
+
+func TestTypeRegistry_Register(t *testing.T) {
+	cases := map[string]struct {
+		codecs []TypeCodec
+		err    string
+	}{
+		"valid": {
+			codecs: []TypeCodec{testIDCodec(37)},
+		},
+		"incomplete": {
+			codecs: []TypeCodec{{Type: reflect.TypeOf(testID("")), CBORTag: 37}},
+			err:    "must have Type, Format, and Parse",
+		},
+		"pointer type": {
+			codecs: []TypeCodec{func() TypeCodec {
+				c := testIDCodec(37)
+				c.Type = reflect.PtrTo(c.Type)
+				return c
+			}()},
+			err: "must not be a pointer",
+		},
+		"reserved tag": {
+			codecs: []TypeCodec{testIDCodec(2)},
+			err:    "CBOR tag 2 is reserved",
+		},
+		"duplicate type": {
+			codecs: []TypeCodec{testIDCodec(37), testIDCodec(38)},
+			err:    "already registered",
+		},
+		"duplicate tag": {
+			codecs: []TypeCodec{testIDCodec(37), func() TypeCodec {
+				c := testIDCodec(37)
+				c.Type = reflect.TypeOf(0)
+				return c
+			}()},
+			err: "CBOR tag 37 is already registered for document.testID",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewTypeRegistry(c.codecs...)
+			if len(c.err) != 0 {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expect error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if _, ok := r.CodecForType(reflect.TypeOf(testID(""))); !ok {
+				t.Errorf("expect codec for type")
+			}
+			if _, ok := r.CodecForCBORTag(37); !ok {
+				t.Errorf("expect codec for tag")
+			}
+		})
+	}
+}
+
+func TestTypeRegistry_Nil(t *testing.T) {
+	var r *TypeRegistry
+	if _, ok := r.CodecForType(reflect.TypeOf(testID(""))); ok {
+		t.Errorf("expect no codec for type")
+	}
+	if _, ok := r.CodecForCBORTag(37); ok {
+		t.Errorf("expect no codec for tag")
+	}
+}
+
+func TestTypeCodec_ParseValue(t *testing.T) {
+	c := testIDCodec(37)
+
+	var id testID
+	if err := c.ParseValue("abc", reflect.ValueOf(&id).Elem()); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := testID("abc"), id; e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	c.Parse = func(s string) (interface{}, error) { return s, nil }
+	err := c.ParseValue("abc", reflect.ValueOf(&id).Elem())
+	if err == nil || !strings.Contains(err.Error(), "parse returned string") {
+		t.Errorf("expect parse type error, got %v", err)
+	}
+}
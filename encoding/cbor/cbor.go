@@ -123,17 +123,61 @@ type Float32 float32
 type Float64 float64
 
 // Encode returns a byte slice that encodes the given Value.
-func Encode(v Value) []byte {
-	p := make([]byte, v.len())
-	v.encode(p)
-	return p
+//
+// Encode is equivalent to calling Encode on an Encoder constructed with the
+// given options. Callers encoding many values with the same options may share
+// a single Encoder instead.
+func Encode(v Value, optFns ...func(*EncodeOptions)) []byte {
+	return NewEncoder(optFns...).Encode(v)
+}
+
+// DecodeOptions is the set of options that can be configured for Decode.
+type DecodeOptions struct {
+	// By default, decoded Slice values alias p while String values and Map
+	// keys are copied from it. If NoCopy is set, String values and Map keys
+	// alias p as well, such that decoding a payload allocates memory only for
+	// the structure of the decoded Value.
+	//
+	// p must not be modified while any Value decoded from it with NoCopy is
+	// in use, since doing so would mutate the aliasing strings.
+	NoCopy bool
 }
 
 // Decode returns the Value encoded in the given byte slice.
-func Decode(p []byte) (Value, error) {
-	v, _, err := decode(p)
+//
+// Decoded Slice values alias p. See DecodeOptions.NoCopy to alias String
+// values as well.
+//
+// A NeedMoreDataError is returned if p ends before the encoded data item.
+func Decode(p []byte, optFns ...func(*DecodeOptions)) (Value, error) {
+	var o DecodeOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	v, _, err := (&decoder{aliasStrings: o.NoCopy}).decode(p)
 	if err != nil {
 		return nil, err
 	}
 	return v, nil
 }
+
+// Marshaler is implemented by types that can encode themselves to CBOR.
+//
+// The reflective document encoder calls MarshalCBOR in place of its default
+// behavior for any value that implements this interface. The returned bytes
+// must contain exactly one well-formed CBOR data item.
+type Marshaler interface {
+	MarshalCBOR() ([]byte, error)
+}
+
+// Unmarshaler is implemented by types that can decode themselves from CBOR.
+//
+// The reflective document decoder calls UnmarshalCBOR in place of its default
+// behavior for any value whose pointer implements this interface. The input is
+// a single CBOR data item, re-encoded from the decoded Value, so it will never
+// contain indefinite-length items. UnmarshalCBOR must copy the data if it
+// wishes to retain it after returning.
+type Unmarshaler interface {
+	UnmarshalCBOR([]byte) error
+}
@@ -4,11 +4,46 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"unsafe"
 )
 
+// NeedMoreDataError is returned when the input to decode ends before the
+// encoded data item, such that the item may be decoded once more data is
+// available, e.g. a partially received message. Callers receiving the item
+// incrementally should decode it again once at least Minimum more bytes are
+// available, since fewer cannot complete it.
+type NeedMoreDataError struct {
+	// The minimum number of additional bytes required for decode to make
+	// progress. The complete item may require more.
+	Minimum uint64
+
+	reason string
+}
+
+func needMoreData(minimum uint64, format string, args ...interface{}) error {
+	return &NeedMoreDataError{Minimum: minimum, reason: fmt.Sprintf(format, args...)}
+}
+
+func (e *NeedMoreDataError) Error() string {
+	return fmt.Sprintf("%s, need at least %d more bytes", e.reason, e.Minimum)
+}
+
+// decoder holds the copy semantics of a single decode.
+type decoder struct {
+	// String values and map keys alias the input
+	aliasStrings bool
+
+	// Slice values are copied from the input
+	copySlices bool
+}
+
 func decode(p []byte) (Value, int, error) {
+	return (&decoder{}).decode(p)
+}
+
+func (d *decoder) decode(p []byte) (Value, int, error) {
 	if len(p) == 0 {
-		return nil, 0, fmt.Errorf("unexpected end of payload")
+		return nil, 0, needMoreData(1, "unexpected end of payload")
 	}
 
 	switch peekMajor(p) {
@@ -17,21 +52,32 @@ func decode(p []byte) (Value, int, error) {
 	case majorTypeNegInt:
 		return decodeNegInt(p)
 	case majorTypeSlice:
-		return decodeSlice(p, majorTypeSlice)
+		s, n, err := decodeSlice(p, majorTypeSlice)
+		if err == nil && d.copySlices {
+			s = append(Slice{}, s...)
+		}
+		return s, n, err
 	case majorTypeString:
 		s, n, err := decodeSlice(p, majorTypeString)
-		return String(s), n, err
+		return String(d.string(s)), n, err
 	case majorTypeList:
-		return decodeList(p)
+		return d.decodeList(p)
 	case majorTypeMap:
-		return decodeMap(p)
+		return d.decodeMap(p)
 	case majorTypeTag:
-		return decodeTag(p)
+		return d.decodeTag(p)
 	default: // majorType7
 		return decodeMajor7(p)
 	}
 }
 
+func (d *decoder) string(p []byte) string {
+	if d.aliasStrings && len(p) > 0 {
+		return unsafe.String(&p[0], len(p))
+	}
+	return string(p)
+}
+
 func decodeUint(p []byte) (Uint, int, error) {
 	i, off, err := decodeArgument(p)
 	if err != nil {
@@ -66,7 +112,7 @@ func decodeSlice(p []byte, inner majorType) (Slice, int, error) {
 
 	p = p[off:]
 	if uint64(len(p)) < slen {
-		return nil, 0, fmt.Errorf("slice len %d greater than remaining buf len", slen)
+		return nil, 0, needMoreData(slen-uint64(len(p)), "slice len %d greater than remaining buf len", slen)
 	}
 
 	return Slice(p[:slen]), off + int(slen), nil
@@ -97,13 +143,13 @@ func decodeSliceIndefinite(p []byte, inner majorType) (Slice, int, error) {
 		s = append(s, ss...)
 		off += n
 	}
-	return nil, 0, fmt.Errorf("expected break marker")
+	return nil, 0, needMoreData(1, "expected break marker")
 }
 
-func decodeList(p []byte) (List, int, error) {
+func (d *decoder) decodeList(p []byte) (List, int, error) {
 	minor := peekMinor(p)
 	if minor == minorIndefinite {
-		return decodeListIndefinite(p)
+		return d.decodeListIndefinite(p)
 	}
 
 	alen, off, err := decodeArgument(p)
@@ -113,8 +159,8 @@ func decodeList(p []byte) (List, int, error) {
 	p = p[off:]
 
 	l := List{}
-	for i := 0; i < int(alen); i++ {
-		item, n, err := decode(p)
+	for i := uint64(0); i < alen; i++ {
+		item, n, err := d.decode(p)
 		if err != nil {
 			return nil, 0, fmt.Errorf("decode item: %w", err)
 		}
@@ -127,7 +173,7 @@ func decodeList(p []byte) (List, int, error) {
 	return l, off, nil
 }
 
-func decodeListIndefinite(p []byte) (List, int, error) {
+func (d *decoder) decodeListIndefinite(p []byte) (List, int, error) {
 	p = p[1:]
 
 	l := List{}
@@ -136,7 +182,7 @@ func decodeListIndefinite(p []byte) (List, int, error) {
 			return l, off + 2, nil
 		}
 
-		item, n, err := decode(p)
+		item, n, err := d.decode(p)
 		if err != nil {
 			return nil, 0, fmt.Errorf("decode item: %w", err)
 		}
@@ -145,13 +191,13 @@ func decodeListIndefinite(p []byte) (List, int, error) {
 		l = append(l, item)
 		off += n
 	}
-	return nil, 0, fmt.Errorf("expected break marker")
+	return nil, 0, needMoreData(1, "expected break marker")
 }
 
-func decodeMap(p []byte) (Map, int, error) {
+func (d *decoder) decodeMap(p []byte) (Map, int, error) {
 	minor := peekMinor(p)
 	if minor == minorIndefinite {
-		return decodeMapIndefinite(p)
+		return d.decodeMapIndefinite(p)
 	}
 
 	maplen, off, err := decodeArgument(p)
@@ -161,9 +207,9 @@ func decodeMap(p []byte) (Map, int, error) {
 	p = p[off:]
 
 	mp := Map{}
-	for i := 0; i < int(maplen); i++ {
+	for i := uint64(0); i < maplen; i++ {
 		if len(p) == 0 {
-			return nil, 0, fmt.Errorf("unexpected end of payload")
+			return nil, 0, needMoreData(1, "unexpected end of payload")
 		}
 
 		if major := peekMajor(p); major != majorTypeString {
@@ -176,20 +222,20 @@ func decodeMap(p []byte) (Map, int, error) {
 		}
 		p = p[kn:]
 
-		value, vn, err := decode(p)
+		value, vn, err := d.decode(p)
 		if err != nil {
 			return nil, 0, fmt.Errorf("decode value: %w", err)
 		}
 		p = p[vn:]
 
-		mp[string(key)] = value
+		mp[d.string(key)] = value
 		off += kn + vn
 	}
 
 	return mp, off, nil
 }
 
-func decodeMapIndefinite(p []byte) (Map, int, error) {
+func (d *decoder) decodeMapIndefinite(p []byte) (Map, int, error) {
 	p = p[1:]
 
 	mp := Map{}
@@ -208,26 +254,26 @@ func decodeMapIndefinite(p []byte) (Map, int, error) {
 		}
 		p = p[kn:]
 
-		value, vn, err := decode(p)
+		value, vn, err := d.decode(p)
 		if err != nil {
 			return nil, 0, fmt.Errorf("decode value: %w", err)
 		}
 		p = p[vn:]
 
-		mp[string(key)] = value
+		mp[d.string(key)] = value
 		off += kn + vn
 	}
-	return nil, 0, fmt.Errorf("expected break marker")
+	return nil, 0, needMoreData(1, "expected break marker")
 }
 
-func decodeTag(p []byte) (*Tag, int, error) {
+func (d *decoder) decodeTag(p []byte) (*Tag, int, error) {
 	id, off, err := decodeArgument(p)
 	if err != nil {
 		return nil, 0, fmt.Errorf("decode argument: %w", err)
 	}
 	p = p[off:]
 
-	v, n, err := decode(p)
+	v, n, err := d.decode(p)
 	if err != nil {
 		return nil, 0, fmt.Errorf("decode value: %w", err)
 	}
@@ -245,19 +291,19 @@ func decodeMajor7(p []byte) (Value, int, error) {
 		return &Undefined{}, 1, nil
 	case major7Float16:
 		if len(p) < 3 {
-			return nil, 0, fmt.Errorf("incomplete float16 at end of buf")
+			return nil, 0, needMoreData(uint64(3-len(p)), "incomplete float16 at end of buf")
 		}
 		b := binary.BigEndian.Uint16(p[1:])
 		return Float32(math.Float32frombits(float16to32(b))), 3, nil
 	case major7Float32:
 		if len(p) < 5 {
-			return nil, 0, fmt.Errorf("incomplete float32 at end of buf")
+			return nil, 0, needMoreData(uint64(5-len(p)), "incomplete float32 at end of buf")
 		}
 		b := binary.BigEndian.Uint32(p[1:])
 		return Float32(math.Float32frombits(b)), 5, nil
 	case major7Float64:
 		if len(p) < 9 {
-			return nil, 0, fmt.Errorf("incomplete float64 at end of buf")
+			return nil, 0, needMoreData(uint64(9-len(p)), "incomplete float64 at end of buf")
 		}
 		b := binary.BigEndian.Uint64(p[1:])
 		return Float64(math.Float64frombits(b)), 9, nil
@@ -288,7 +334,7 @@ func decodeArgument(p []byte) (uint64, int, error) {
 	case minorArg1, minorArg2, minorArg4, minorArg8:
 		argLen := mtol(minor)
 		if len(p) < argLen+1 {
-			return 0, 0, fmt.Errorf("arg len %d greater than remaining buf len", argLen)
+			return 0, 0, needMoreData(uint64(argLen+1-len(p)), "arg len %d greater than remaining buf len", argLen)
 		}
 		return readArgument(p[1:], argLen), argLen + 1, nil
 	default:
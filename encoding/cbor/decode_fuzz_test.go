@@ -0,0 +1,178 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/aws/smithy-go/internal/testutil"
+)
+
+// testVector is a single entry in testdata/vectors.json, which is derived
+// from the examples in RFC 8949 Appendix A (restricted to the subset of CBOR
+// supported by this package).
+//
+// Roundtrip indicates whether re-encoding the decoded value is expected to
+// reproduce the input byte-for-byte. It is false for inputs that use
+// encodings this package never generates (float16, indefinite-length items)
+// or whose output is order-dependent (maps with more than one key).
+type testVector struct {
+	Hex       string `json:"hex"`
+	Roundtrip bool   `json:"roundtrip"`
+}
+
+func loadTestVectors(tb testing.TB) []testVector {
+	tb.Helper()
+
+	corpus := testutil.LoadCorpus(tb, "testdata/vectors.json", func(o *testutil.CorpusOptions) {
+		o.KeyField = "hex"
+	})
+
+	var vectors []testVector
+	corpus.Decode(tb, &vectors)
+	return vectors
+}
+
+func seedFuzzCorpus(f *testing.F) {
+	for _, v := range loadTestVectors(f) {
+		p, err := hex.DecodeString(v.Hex)
+		if err != nil {
+			f.Fatalf("decode test vector %q: %v", v.Hex, err)
+		}
+		f.Add(p)
+	}
+}
+
+func TestDecode_TestVectors(t *testing.T) {
+	for _, v := range loadTestVectors(t) {
+		t.Run(v.Hex, func(t *testing.T) {
+			p, err := hex.DecodeString(v.Hex)
+			if err != nil {
+				t.Fatalf("decode hex: %v", err)
+			}
+
+			dv, err := Decode(p)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			if !v.Roundtrip {
+				return
+			}
+			if actual := Encode(dv); !bytes.Equal(p, actual) {
+				t.Errorf("roundtrip mismatch: %x != %x", p, actual)
+			}
+		})
+	}
+}
+
+// FuzzDecode asserts that Decode never panics on arbitrary input.
+func FuzzDecode(f *testing.F) {
+	seedFuzzCorpus(f)
+
+	f.Fuzz(func(t *testing.T, p []byte) {
+		Decode(p)
+	})
+}
+
+// FuzzRoundTrip asserts that any successfully decoded Value survives a
+// subsequent encode and decode unchanged.
+func FuzzRoundTrip(f *testing.F) {
+	seedFuzzCorpus(f)
+
+	f.Fuzz(func(t *testing.T, p []byte) {
+		v, err := Decode(p)
+		if err != nil {
+			return
+		}
+
+		encoded := Encode(v)
+		vv, err := Decode(encoded)
+		if err != nil {
+			t.Fatalf("decode re-encoded value %x: %v", encoded, err)
+		}
+
+		if err := equalValue(v, vv); err != nil {
+			t.Errorf("roundtrip mismatch for %x: %v", p, err)
+		}
+	})
+}
+
+// equalValue compares two decoded values, treating floats by their bit
+// pattern so that NaN payloads compare equal to themselves.
+func equalValue(a, b Value) error {
+	switch av := a.(type) {
+	case List:
+		bv, ok := b.(List)
+		if !ok {
+			return fmt.Errorf("%T != %T", a, b)
+		}
+		if len(av) != len(bv) {
+			return fmt.Errorf("list len %d != %d", len(av), len(bv))
+		}
+		for i := range av {
+			if err := equalValue(av[i], bv[i]); err != nil {
+				return fmt.Errorf("list[%d]: %w", i, err)
+			}
+		}
+		return nil
+	case Map:
+		bv, ok := b.(Map)
+		if !ok {
+			return fmt.Errorf("%T != %T", a, b)
+		}
+		if len(av) != len(bv) {
+			return fmt.Errorf("map len %d != %d", len(av), len(bv))
+		}
+		for k, v := range av {
+			if err := equalValue(v, bv[k]); err != nil {
+				return fmt.Errorf("map[%q]: %w", k, err)
+			}
+		}
+		return nil
+	case *Tag:
+		bv, ok := b.(*Tag)
+		if !ok {
+			return fmt.Errorf("%T != %T", a, b)
+		}
+		if av.ID != bv.ID {
+			return fmt.Errorf("tag id %d != %d", av.ID, bv.ID)
+		}
+		return equalValue(av.Value, bv.Value)
+	case Slice:
+		bv, ok := b.(Slice)
+		if !ok || !bytes.Equal(av, bv) {
+			return fmt.Errorf("%v != %v", a, b)
+		}
+		return nil
+	case Float32:
+		bv, ok := b.(Float32)
+		if !ok || math.Float32bits(float32(av)) != math.Float32bits(float32(bv)) {
+			return fmt.Errorf("%v != %v", a, b)
+		}
+		return nil
+	case Float64:
+		bv, ok := b.(Float64)
+		if !ok || math.Float64bits(float64(av)) != math.Float64bits(float64(bv)) {
+			return fmt.Errorf("%v != %v", a, b)
+		}
+		return nil
+	case *Nil:
+		if _, ok := b.(*Nil); !ok {
+			return fmt.Errorf("%T != %T", a, b)
+		}
+		return nil
+	case *Undefined:
+		if _, ok := b.(*Undefined); !ok {
+			return fmt.Errorf("%T != %T", a, b)
+		}
+		return nil
+	default:
+		if a != b {
+			return fmt.Errorf("%v != %v", a, b)
+		}
+		return nil
+	}
+}
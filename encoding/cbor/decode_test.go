@@ -1,6 +1,7 @@
 package cbor
 
 import (
+	"errors"
 	"math"
 	"reflect"
 	"strings"
@@ -236,6 +237,10 @@ func TestDecode_InvalidList(t *testing.T) {
 			[]byte{4<<5 | 1, 0<<5 | 24},
 			"arg len 1 greater than remaining buf len",
 		},
+		"[] / len overflows int": {
+			[]byte{4<<5 | 27, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			"unexpected end of payload",
+		},
 		"[_ ] / no break": {
 			[]byte{4<<5 | 31},
 			"expected break marker",
@@ -270,6 +275,10 @@ func TestDecode_InvalidMap(t *testing.T) {
 			[]byte{5<<5 | 1, 0},
 			"unexpected major type 0 for map key",
 		},
+		"{} / len overflows int": {
+			[]byte{5<<5 | 27, 0xbb, 0xbb, 0x30, 0x30, 0x30, 0x30, 0x30, 0x30},
+			"unexpected end of payload",
+		},
 		"{} / invalid key": {
 			[]byte{5<<5 | 1, 3<<5 | 24, 1},
 			"slice len 1 greater than remaining buf len",
@@ -1332,3 +1341,50 @@ func withIndefiniteMap(p []byte) []byte {
 	p = append(head, p...)
 	return append(p, 0xff)
 }
+
+func TestDecode_NeedMoreData(t *testing.T) {
+	for name, c := range map[string]struct {
+		In      []byte
+		Minimum uint64
+	}{
+		"empty": {
+			[]byte{},
+			1,
+		},
+		"arg": {
+			[]byte{0<<5 | 26, 0},
+			3,
+		},
+		"slice": {
+			[]byte{2<<5 | 5, 'a', 'b'},
+			3,
+		},
+		"float64": {
+			[]byte{7<<5 | major7Float64, 0, 0},
+			6,
+		},
+		"list item": {
+			[]byte{4<<5 | 2, 0},
+			1,
+		},
+		"map value string": {
+			[]byte{5<<5 | 1, 3<<5 | 1, 'a', 3<<5 | 4, 'b'},
+			3,
+		},
+		"indefinite list": {
+			[]byte{4<<5 | minorIndefinite, 0},
+			1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Decode(c.In)
+			var needErr *NeedMoreDataError
+			if !errors.As(err, &needErr) {
+				t.Fatalf("expect NeedMoreDataError, got %v", err)
+			}
+			if e, a := c.Minimum, needErr.Minimum; e != a {
+				t.Errorf("expect minimum %d, got %d", e, a)
+			}
+		})
+	}
+}
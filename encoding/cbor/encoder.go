@@ -0,0 +1,52 @@
+package cbor
+
+// Encoder encodes Values with a fixed set of EncodeOptions.
+//
+// An Encoder is safe for concurrent use by multiple goroutines, such that one
+// can be configured once and shared, e.g. by every request of a client. The
+// scratch space needed to encode with a MapKeyOrder is drawn from an internal
+// pool for the duration of each call rather than held by the Encoder.
+type Encoder struct {
+	options EncodeOptions
+}
+
+// NewEncoder returns an Encoder configured with the given options.
+func NewEncoder(optFns ...func(*EncodeOptions)) *Encoder {
+	var o EncodeOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	return &Encoder{options: o}
+}
+
+// Encode returns a byte slice that encodes the given Value.
+func (e *Encoder) Encode(v Value) []byte {
+	return e.Append(nil, v)
+}
+
+// Append appends the encoding of the given Value to dst and returns the
+// extended buffer.
+func (e *Encoder) Append(dst []byte, v Value) []byte {
+	if e.options.MapKeyOrder != MapKeyOrderNone {
+		s := getSortState()
+		defer putSortState(s)
+
+		v = s.sortMaps(v, e.options.MapKeyOrder)
+	}
+
+	n := v.len()
+	dst = grow(dst, n)
+	v.encode(dst[len(dst) : len(dst)+n])
+	return dst[:len(dst)+n]
+}
+
+// grow returns p with capacity for at least n more bytes. Capacity is grown
+// amortized as with append, since the sort key arena grows once per map key.
+func grow(p []byte, n int) []byte {
+	if cap(p)-len(p) >= n {
+		return p
+	}
+
+	return append(p[:cap(p)], make([]byte, n)...)[:len(p)]
+}
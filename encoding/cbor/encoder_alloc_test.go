@@ -0,0 +1,26 @@
+//go:build !race
+// +build !race
+
+// The race detector randomly drops items put into a sync.Pool, so allocation
+// counts are only meaningful without it.
+
+package cbor
+
+import "testing"
+
+func TestEncoder_SortAllocations(t *testing.T) {
+	e := NewEncoder(func(o *EncodeOptions) {
+		o.MapKeyOrder = MapKeyOrderBytewise
+	})
+	in := newTestMap(100)
+	buf := make([]byte, 0, len(e.Encode(in)))
+
+	// warm the pool
+	e.Append(buf, in)
+
+	// the sorted copy is built from pooled scratch space, so the number of
+	// allocations does not grow with the size of the input
+	if n := testing.AllocsPerRun(10, func() { e.Append(buf, in) }); n > 10 {
+		t.Errorf("expect at most 10 allocations, got %v", n)
+	}
+}
@@ -0,0 +1,129 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func newTestMap(n int) Map {
+	m := Map{}
+	for i := 0; i < n; i++ {
+		m[fmt.Sprintf("key%03d", i)] = List{
+			String("value"),
+			Map{"nested": Uint(i), "a": Bool(true)},
+		}
+	}
+	return m
+}
+
+func TestEncoder_Concurrent(t *testing.T) {
+	e := NewEncoder(func(o *EncodeOptions) {
+		o.MapKeyOrder = MapKeyOrderBytewise
+	})
+
+	inputs := make([]Value, 8)
+	expect := make([][]byte, len(inputs))
+	for i := range inputs {
+		inputs[i] = newTestMap(i * 10)
+		expect[i] = e.Encode(inputs[i])
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(inputs))
+	for i := range inputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if actual := e.Encode(inputs[i]); !bytes.Equal(expect[i], actual) {
+					errs <- fmt.Errorf("input %d: %s != %s", i, hex.EncodeToString(expect[i]), hex.EncodeToString(actual))
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestEncoder_Append(t *testing.T) {
+	e := NewEncoder()
+
+	p := e.Append([]byte{0xff}, Uint(1))
+	p = e.Append(p, String("a"))
+	if expect := []byte{0xff, 0x01, 3<<5 | 1, 'a'}; !bytes.Equal(expect, p) {
+		t.Errorf("bytes not equal (%s != %s)", hex.EncodeToString(expect), hex.EncodeToString(p))
+	}
+
+	// appending within capacity reuses the buffer
+	buf := make([]byte, 0, 16)
+	if p := e.Append(buf, Uint(1)); &p[0] != &buf[:1][0] {
+		t.Errorf("expect buffer to be reused")
+	}
+}
+
+func TestGrow_Amortized(t *testing.T) {
+	var p []byte
+	var reallocs int
+	for i := 0; i < 40000; i++ {
+		before := cap(p)
+		p = grow(p, 8)
+		if cap(p) != before {
+			reallocs++
+		}
+		p = p[:len(p)+8]
+	}
+
+	// growing one key at a time must not copy the whole arena per key
+	if reallocs > 64 {
+		t.Errorf("expect amortized growth, got %d reallocations", reallocs)
+	}
+}
+
+func TestEncoder_MapKeyOrderLargeMap(t *testing.T) {
+	e := NewEncoder(func(o *EncodeOptions) {
+		o.MapKeyOrder = MapKeyOrderBytewise
+	})
+	in := Map{}
+	for i := 0; i < 40000; i++ {
+		in[fmt.Sprintf("key%05d", i)] = Uint(i)
+	}
+
+	out, err := Decode(e.Encode(in))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if actual := len(out.(Map)); actual != len(in) {
+		t.Errorf("expect %d entries, got %d", len(in), actual)
+	}
+}
+
+func BenchmarkEncoder_MapKeyOrder(b *testing.B) {
+	for _, n := range []int{100, 10000} {
+		b.Run(fmt.Sprintf("keys=%d", n), func(b *testing.B) {
+			benchmarkEncoderMapKeyOrder(b, n)
+		})
+	}
+}
+
+func benchmarkEncoderMapKeyOrder(b *testing.B, n int) {
+	e := NewEncoder(func(o *EncodeOptions) {
+		o.MapKeyOrder = MapKeyOrderBytewise
+	})
+	in := newTestMap(n)
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		var buf []byte
+		for pb.Next() {
+			buf = e.Append(buf[:0], in)
+		}
+	})
+}
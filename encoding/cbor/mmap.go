@@ -0,0 +1,77 @@
+package cbor
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MappedFile is a read-only view of the contents of a file containing CBOR,
+// memory-mapped where the platform supports it.
+//
+// Decoding a mapped file avoids reading its contents onto the heap. A file
+// must be explicitly released with Release once it and any Value decoded from
+// it with NoCopy are no longer in use.
+type MappedFile struct {
+	mu      sync.Mutex
+	data    []byte
+	release func([]byte) error
+}
+
+// OpenMappedFile maps the contents of the named file into memory. On
+// platforms without memory mapping support the contents are read instead.
+func OpenMappedFile(name string) (*MappedFile, error) {
+	data, release, err := mmapFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("map %s: %w", name, err)
+	}
+	return &MappedFile{data: data, release: release}, nil
+}
+
+// Bytes returns the mapped contents of the file. The slice must not be
+// modified, nor used after Release.
+func (f *MappedFile) Bytes() []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.data
+}
+
+// Decode returns the Value encoded in the mapped file.
+//
+// Unlike Decode, decoded Slice values are copied out of the mapping by
+// default, such that the Value remains valid after Release. If NoCopy is set,
+// Slice values, String values, and Map keys all alias the mapping, and
+// accessing them after Release is a fatal error.
+func (f *MappedFile) Decode(optFns ...func(*DecodeOptions)) (Value, error) {
+	var o DecodeOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.release == nil {
+		return nil, fmt.Errorf("mapped file is released")
+	}
+
+	v, _, err := (&decoder{aliasStrings: o.NoCopy, copySlices: !o.NoCopy}).decode(f.data)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Release unmaps the file. Release is idempotent.
+func (f *MappedFile) Release() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.release == nil {
+		return nil
+	}
+
+	err := f.release(f.data)
+	f.data, f.release = nil, nil
+	return err
+}
@@ -0,0 +1,124 @@
+package cbor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func writeTempFile(t *testing.T, p []byte) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "payload.cbor")
+	if err := os.WriteFile(name, p, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func within(p []byte, ptr *byte) bool {
+	start := uintptr(unsafe.Pointer(&p[0]))
+	at := uintptr(unsafe.Pointer(ptr))
+	return at >= start && at < start+uintptr(len(p))
+}
+
+func TestMappedFile_Decode(t *testing.T) {
+	expect := Map{
+		"foo": Slice("bar"),
+		"baz": List{String("qux"), Uint(1)},
+	}
+	f, err := OpenMappedFile(writeTempFile(t, Encode(expect)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := f.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if within(f.Bytes(), &v.(Map)["foo"].(Slice)[0]) {
+		t.Error("expect slice to be copied out of the mapping")
+	}
+
+	if err := f.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expect, v) {
+		t.Errorf("%v != %v", expect, v)
+	}
+
+	if err := f.Release(); err != nil {
+		t.Errorf("expect release to be idempotent, got %v", err)
+	}
+	if _, err := f.Decode(); err == nil {
+		t.Error("expect decode after release to fail")
+	}
+}
+
+func TestMappedFile_DecodeNoCopy(t *testing.T) {
+	f, err := OpenMappedFile(writeTempFile(t, Encode(Map{
+		"foo": Slice("bar"),
+		"baz": String("qux"),
+	})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Release()
+
+	v, err := f.Decode(func(o *DecodeOptions) {
+		o.NoCopy = true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := v.(Map)
+	if !within(f.Bytes(), &m["foo"].(Slice)[0]) {
+		t.Error("expect slice to alias the mapping")
+	}
+	if !within(f.Bytes(), unsafe.StringData(string(m["baz"].(String)))) {
+		t.Error("expect string to alias the mapping")
+	}
+	for k := range m {
+		if !within(f.Bytes(), unsafe.StringData(k)) {
+			t.Errorf("expect key %q to alias the mapping", k)
+		}
+	}
+}
+
+func TestMappedFile_Empty(t *testing.T) {
+	f, err := OpenMappedFile(writeTempFile(t, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Release()
+
+	if _, err := f.Decode(); err == nil {
+		t.Error("expect decode of empty file to fail")
+	}
+}
+
+func TestDecode_NoCopy(t *testing.T) {
+	p := Encode(String("foo"))
+
+	copied, err := Decode(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliased, err := Decode(p, func(o *DecodeOptions) {
+		o.NoCopy = true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p[1] = 'g'
+	if e, a := String("foo"), copied; e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+	if e, a := String("goo"), aliased; e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+}
@@ -0,0 +1,37 @@
+//go:build unix
+
+package cbor
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"syscall"
+)
+
+func mmapFile(name string) ([]byte, func([]byte) error, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close() // the mapping remains valid once the file is closed
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	size := fi.Size()
+	if size == 0 {
+		return []byte{}, func([]byte) error { return nil }, nil
+	}
+	if size > math.MaxInt {
+		return nil, nil, fmt.Errorf("file size %d exceeds addressable memory", size)
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, syscall.Munmap, nil
+}
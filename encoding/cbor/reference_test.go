@@ -0,0 +1,357 @@
+//go:build reference
+// +build reference
+
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// The tests in this file cross-check Decode and Encode against refDecode, a
+// second CBOR decoder written independently of the one in this package. It
+// is deliberately naive: it reads from a bytes.Reader one item at a time and
+// shares no code with decode.go, such that an error in the interpretation of
+// the spec in one is unlikely to be mirrored in the other.
+//
+// Run with:
+//
+//	go test -tags reference ./encoding/cbor
+//	go test -tags reference -run XXX -fuzz FuzzReference ./encoding/cbor
+
+// reference representations of decoded items, independent of Value
+type (
+	refNegInt struct{ Arg uint64 } // encoded argument, value is -1-Arg
+	refTag    struct {
+		ID    uint64
+		Value interface{}
+	}
+	refUndefined struct{}
+	refFloat     struct{ Bits uint64 } // float64 bits after widening
+)
+
+// all NaNs compare equal, payload handling on widening is platform-specific
+func refFloatOf(f float64) refFloat {
+	if math.IsNaN(f) {
+		return refFloat{0x7ff8000000000000}
+	}
+	return refFloat{math.Float64bits(f)}
+}
+
+var errRefBreak = errors.New("break")
+
+func refDecode(p []byte) (interface{}, error) {
+	r := bytes.NewReader(p)
+	v, err := refDecodeValue(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing bytes", r.Len())
+	}
+	return v, nil
+}
+
+func refReadArg(r *bytes.Reader, info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		b, err := r.ReadByte()
+		return uint64(b), err
+	case info == 25:
+		var v uint16
+		err := binary.Read(r, binary.BigEndian, &v)
+		return uint64(v), err
+	case info == 26:
+		var v uint32
+		err := binary.Read(r, binary.BigEndian, &v)
+		return uint64(v), err
+	case info == 27:
+		var v uint64
+		err := binary.Read(r, binary.BigEndian, &v)
+		return v, err
+	}
+	return 0, fmt.Errorf("invalid additional info %d", info)
+}
+
+func refReadBytes(r *bytes.Reader, major, info byte) ([]byte, error) {
+	if info != 31 {
+		n, err := refReadArg(r, info)
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		p := make([]byte, n)
+		_, err = io.ReadFull(r, p)
+		return p, err
+	}
+
+	out := []byte{}
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == 0xff {
+			return out, nil
+		}
+		if b>>5 != major || b&0x1f == 31 {
+			return nil, fmt.Errorf("invalid chunk 0x%x in indefinite string", b)
+		}
+		chunk, err := refReadBytes(r, major, b&0x1f)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chunk...)
+	}
+}
+
+// refDecodeValue decodes an item in a position where a break is not allowed.
+func refDecodeValue(r *bytes.Reader) (interface{}, error) {
+	v, err := refDecodeItem(r)
+	if errors.Is(err, errRefBreak) {
+		return nil, fmt.Errorf("unexpected break")
+	}
+	return v, err
+}
+
+func refDecodeItem(r *bytes.Reader) (interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	major, info := b>>5, b&0x1f
+
+	switch major {
+	case 0:
+		return refReadArg(r, info)
+	case 1:
+		arg, err := refReadArg(r, info)
+		return refNegInt{arg}, err
+	case 2:
+		return refReadBytes(r, major, info)
+	case 3:
+		p, err := refReadBytes(r, major, info)
+		return string(p), err
+	case 4:
+		list := []interface{}{}
+		if info == 31 {
+			for {
+				v, err := refDecodeItem(r)
+				if errors.Is(err, errRefBreak) {
+					return list, nil
+				} else if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+		}
+		n, err := refReadArg(r, info)
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < n; i++ {
+			v, err := refDecodeValue(r)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case 5:
+		m := map[string]interface{}{}
+		n := uint64(math.MaxUint64)
+		if info != 31 {
+			if n, err = refReadArg(r, info); err != nil {
+				return nil, err
+			}
+		}
+		for i := uint64(0); i < n; i++ {
+			k, err := refDecodeItem(r)
+			if errors.Is(err, errRefBreak) && info == 31 {
+				return m, nil
+			} else if errors.Is(err, errRefBreak) {
+				return nil, fmt.Errorf("unexpected break")
+			} else if err != nil {
+				return nil, err
+			}
+			ks, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("non-string map key %T", k)
+			}
+			v, err := refDecodeValue(r)
+			if err != nil {
+				return nil, err
+			}
+			m[ks] = v
+		}
+		return m, nil
+	case 6:
+		id, err := refReadArg(r, info)
+		if err != nil {
+			return nil, err
+		}
+		v, err := refDecodeValue(r)
+		if err != nil {
+			return nil, err
+		}
+		return refTag{id, v}, nil
+	}
+
+	switch info {
+	case 20, 21:
+		return info == 21, nil
+	case 22:
+		return nil, nil
+	case 23:
+		return refUndefined{}, nil
+	case 25:
+		var h uint16
+		if err := binary.Read(r, binary.BigEndian, &h); err != nil {
+			return nil, err
+		}
+		return refFloatOf(refHalf(h)), nil
+	case 26:
+		var f uint32
+		if err := binary.Read(r, binary.BigEndian, &f); err != nil {
+			return nil, err
+		}
+		return refFloatOf(float64(math.Float32frombits(f))), nil
+	case 27:
+		var f uint64
+		if err := binary.Read(r, binary.BigEndian, &f); err != nil {
+			return nil, err
+		}
+		return refFloatOf(math.Float64frombits(f)), nil
+	case 31:
+		return nil, errRefBreak
+	}
+	return nil, fmt.Errorf("invalid simple value %d", info)
+}
+
+// refHalf is the reference half-precision conversion from RFC 8949 Appendix D.
+func refHalf(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var v float64
+	switch exp {
+	case 0:
+		v = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			v = math.Inf(1)
+		} else {
+			v = math.NaN()
+		}
+	default:
+		v = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -v
+	}
+	return v
+}
+
+// toRef converts a Value to the reference representation.
+func toRef(v Value) interface{} {
+	switch vv := v.(type) {
+	case Uint:
+		return uint64(vv)
+	case NegInt:
+		return refNegInt{uint64(vv) - 1}
+	case Slice:
+		return []byte(vv)
+	case String:
+		return string(vv)
+	case List:
+		list := []interface{}{}
+		for _, item := range vv {
+			list = append(list, toRef(item))
+		}
+		return list
+	case Map:
+		m := map[string]interface{}{}
+		for k, item := range vv {
+			m[k] = toRef(item)
+		}
+		return m
+	case *Tag:
+		return refTag{vv.ID, toRef(vv.Value)}
+	case Bool:
+		return bool(vv)
+	case *Nil:
+		return nil
+	case *Undefined:
+		return refUndefined{}
+	case Float32:
+		return refFloatOf(float64(vv))
+	case Float64:
+		return refFloatOf(float64(vv))
+	}
+	panic(fmt.Sprintf("unrecognized variant %T", v))
+}
+
+// crossCheck reports any divergence between this package and the reference
+// decoder for the given input.
+func crossCheck(p []byte) error {
+	expect, referr := refDecode(p)
+
+	v, n, err := decode(p)
+	if err == nil && n != len(p) {
+		err = fmt.Errorf("%d trailing bytes", len(p)-n)
+	}
+	if (err == nil) != (referr == nil) {
+		return fmt.Errorf("decode error divergence: %v != reference %v", err, referr)
+	}
+	if err != nil {
+		return nil
+	}
+
+	if actual := toRef(v); !reflect.DeepEqual(expect, actual) {
+		return fmt.Errorf("decode divergence: %#v != reference %#v", actual, expect)
+	}
+
+	encoded, err := refDecode(Encode(v))
+	if err != nil {
+		return fmt.Errorf("reference decode of encoded value: %v", err)
+	}
+	if !reflect.DeepEqual(expect, encoded) {
+		return fmt.Errorf("encode divergence: %#v != reference %#v", encoded, expect)
+	}
+	return nil
+}
+
+func TestReference_TestVectors(t *testing.T) {
+	for _, v := range loadTestVectors(t) {
+		t.Run(v.Hex, func(t *testing.T) {
+			p, err := hex.DecodeString(v.Hex)
+			if err != nil {
+				t.Fatalf("decode hex: %v", err)
+			}
+			if err := crossCheck(p); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func FuzzReference(f *testing.F) {
+	seedFuzzCorpus(f)
+
+	f.Fuzz(func(t *testing.T, p []byte) {
+		if err := crossCheck(p); err != nil {
+			t.Errorf("%x: %v", p, err)
+		}
+	})
+}
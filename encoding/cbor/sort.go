@@ -0,0 +1,183 @@
+package cbor
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+)
+
+// MapKeyOrder specifies the order in which Encode writes the entries of a
+// Map.
+type MapKeyOrder int
+
+// Enumeration values for MapKeyOrder.
+const (
+	// MapKeyOrderNone writes map entries in Go map iteration order, which is
+	// unspecified and will vary between calls. This is the default.
+	MapKeyOrderNone MapKeyOrder = iota
+
+	// MapKeyOrderBytewise sorts map entries by the bytewise lexicographic
+	// order of their encoded keys, as specified by the core deterministic
+	// encoding requirements in RFC 8949 section 4.2.1.
+	MapKeyOrderBytewise
+
+	// MapKeyOrderLengthFirst sorts map entries by the length of their encoded
+	// keys, then by bytewise lexicographic order of keys of equal length, as
+	// specified by the "length-first" ordering in RFC 8949 section 4.2.3.
+	//
+	// This is the canonical form required by COSE (RFC 9052) and CTAP2. Since
+	// Map keys are restricted to text strings, whose encoded head sorts by
+	// length, the resulting order is the same as MapKeyOrderBytewise for all
+	// Values this package can represent.
+	MapKeyOrderLengthFirst
+)
+
+// EncodeOptions is the set of options that can be configured for Encode.
+type EncodeOptions struct {
+	// The order in which Map entries are written, including those of Maps
+	// nested within List, Map, or Tag values.
+	MapKeyOrder MapKeyOrder
+}
+
+// sortedMap is a Map whose entries have been ordered ahead of encode.
+type sortedMap []sortedMapEntry
+
+type sortedMapEntry struct {
+	key   []byte // pre-encoded
+	value Value
+}
+
+func (m sortedMap) len() int {
+	total := itoarglen(len(m))
+	for _, e := range m {
+		total += len(e.key) + e.value.len()
+	}
+	return total
+}
+
+func (m sortedMap) encode(p []byte) int {
+	off := encodeArg(majorTypeMap, len(m), p)
+	for _, e := range m {
+		off += copy(p[off:], e.key)
+		off += e.value.encode(p[off:])
+	}
+	return off
+}
+
+// sortState is the scratch space used to sort the maps of a Value ahead of
+// encode. The sorted copy of the Value is built out of its arenas, such that
+// the number of allocations does not grow with the number of map keys.
+//
+// The arenas may be reallocated as they grow, leaving earlier subslices to
+// reference the previous backing array. This is safe since nothing is written
+// through the arenas themselves once a subslice has been handed out.
+type sortState struct {
+	keys    []byte
+	entries []sortedMapEntry
+	values  []Value
+
+	// the headers of the sorted copies, which are returned by pointer to
+	// avoid allocating for their conversion to Value
+	maps  []sortedMap
+	lists []List
+
+	sorter mapSorter
+}
+
+var sortStatePool = sync.Pool{
+	New: func() interface{} { return &sortState{} },
+}
+
+func getSortState() *sortState {
+	return sortStatePool.Get().(*sortState)
+}
+
+// putSortState returns s to the pool. Nothing sorted with s may be used
+// afterwards.
+func putSortState(s *sortState) {
+	// references to the sorted values are cleared, so the pool does not keep
+	// the caller's data alive
+	for i := range s.entries {
+		s.entries[i] = sortedMapEntry{}
+	}
+	for i := range s.values {
+		s.values[i] = nil
+	}
+	for i := range s.maps {
+		s.maps[i] = nil
+	}
+	for i := range s.lists {
+		s.lists[i] = nil
+	}
+
+	s.keys = s.keys[:0]
+	s.entries = s.entries[:0]
+	s.values = s.values[:0]
+	s.maps = s.maps[:0]
+	s.lists = s.lists[:0]
+	s.sorter = mapSorter{}
+	sortStatePool.Put(s)
+}
+
+// sortMaps returns a copy of v where every Map has been replaced with a
+// sortedMap in the given order.
+func (s *sortState) sortMaps(v Value, order MapKeyOrder) Value {
+	switch vv := v.(type) {
+	case List:
+		start := len(s.values)
+		s.values = append(s.values, vv...)
+		l := List(s.values[start:len(s.values):len(s.values)])
+		for i, item := range l {
+			l[i] = s.sortMaps(item, order)
+		}
+		s.lists = append(s.lists, l)
+		return &s.lists[len(s.lists)-1]
+	case Map:
+		start := len(s.entries)
+		for k, item := range vv {
+			s.entries = append(s.entries, sortedMapEntry{
+				key:   s.encodeKey(k),
+				value: item,
+			})
+		}
+		m := sortedMap(s.entries[start:len(s.entries):len(s.entries)])
+		for i := range m {
+			m[i].value = s.sortMaps(m[i].value, order)
+		}
+		s.sorter = mapSorter{m: m, order: order}
+		sort.Sort(&s.sorter)
+
+		s.maps = append(s.maps, m)
+		return &s.maps[len(s.maps)-1]
+	case *Tag:
+		return &Tag{ID: vv.ID, Value: s.sortMaps(vv.Value, order)}
+	default:
+		return v
+	}
+}
+
+func (s *sortState) encodeKey(k string) []byte {
+	start := len(s.keys)
+	s.keys = grow(s.keys, String(k).len())
+	end := start + String(k).encode(s.keys[start:cap(s.keys)])
+	s.keys = s.keys[:end]
+	return s.keys[start:end:end]
+}
+
+// mapSorter sorts the entries of a sortedMap, held by the sortState so that
+// sort.Sort can be passed a pointer without allocating.
+type mapSorter struct {
+	m     sortedMap
+	order MapKeyOrder
+}
+
+func (s *mapSorter) Len() int           { return len(s.m) }
+func (s *mapSorter) Less(i, j int) bool { return lessKey(s.m[i].key, s.m[j].key, s.order) }
+func (s *mapSorter) Swap(i, j int)      { s.m[i], s.m[j] = s.m[j], s.m[i] }
+
+func lessKey(a, b []byte, order MapKeyOrder) bool {
+	if order == MapKeyOrderLengthFirst && len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return bytes.Compare(a, b) < 0
+}
@@ -0,0 +1,73 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestEncode_MapKeyOrder(t *testing.T) {
+	in := Map{
+		"bb": Uint(2),
+		"a":  Uint(1),
+		"b":  List{Map{"z": Uint(0), "y": Uint(1)}},
+		"aaa": &Tag{ID: 1, Value: Map{
+			"long": Bool(true),
+			"x":    Bool(false),
+		}},
+	}
+	expect := []byte{
+		5<<5 | 4,
+		3<<5 | 1, 'a', 1,
+		3<<5 | 1, 'b',
+		4<<5 | 1, 5<<5 | 2,
+		3<<5 | 1, 'y', 1,
+		3<<5 | 1, 'z', 0,
+		3<<5 | 2, 'b', 'b', 2,
+		3<<5 | 3, 'a', 'a', 'a',
+		6<<5 | 1, 5<<5 | 2,
+		3<<5 | 1, 'x', 7<<5 | major7False,
+		3<<5 | 4, 'l', 'o', 'n', 'g', 7<<5 | major7True,
+	}
+
+	for name, order := range map[string]MapKeyOrder{
+		"bytewise":     MapKeyOrderBytewise,
+		"length-first": MapKeyOrderLengthFirst,
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual := Encode(in, func(o *EncodeOptions) {
+				o.MapKeyOrder = order
+			})
+			if !bytes.Equal(expect, actual) {
+				t.Errorf("bytes not equal (%s != %s)", hex.EncodeToString(expect), hex.EncodeToString(actual))
+			}
+		})
+	}
+}
+
+func TestLessKey(t *testing.T) {
+	for name, c := range map[string]struct {
+		A, B   []byte
+		Order  MapKeyOrder
+		Expect bool
+	}{
+		"bytewise/shorter prefix": {
+			[]byte{0x01}, []byte{0x01, 0x00}, MapKeyOrderBytewise, true,
+		},
+		"bytewise/longer smaller": {
+			[]byte{0x01, 0x00}, []byte{0x02}, MapKeyOrderBytewise, true,
+		},
+		"length-first/longer smaller": {
+			[]byte{0x01, 0x00}, []byte{0x02}, MapKeyOrderLengthFirst, false,
+		},
+		"length-first/equal length": {
+			[]byte{0x01, 0x00}, []byte{0x01, 0x01}, MapKeyOrderLengthFirst, true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if actual := lessKey(c.A, c.B, c.Order); c.Expect != actual {
+				t.Errorf("expect %v, got %v", c.Expect, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+// Package eventstream implements the framing of messages of the
+// application/vnd.amazon.eventstream content type, used by streaming
+// operations to send a sequence of events over the body of an HTTP request or
+// response.
+//
+// Each message is framed by a prelude, of the total length of the message and
+// the length of its headers, a CRC32 checksum of the prelude, the headers and
+// payload of the message, and a CRC32 checksum of the whole message.
+//
+// A Reader reads messages from a response body, and a Writer writes messages
+// to a request body. An EventReader reads the events of a stream with an
+// EventDeserializer of the operation, such that each event is deserialized by
+// the protocol of the operation, while errors in the stream are handled the
+// same for every operation.
+//
+// This package is intended for use only by the smithy client runtime. The
+// exported API therein is not considered stable and is subject to breaking
+// changes without notice.
+package eventstream
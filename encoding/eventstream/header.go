@@ -0,0 +1,238 @@
+package eventstream
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Names of the headers of messages that describe their content.
+const (
+	MessageTypeHeader   = ":message-type"
+	EventTypeHeader     = ":event-type"
+	ExceptionTypeHeader = ":exception-type"
+	ErrorCodeHeader     = ":error-code"
+	ErrorMessageHeader  = ":error-message"
+	ContentTypeHeader   = ":content-type"
+)
+
+// Values of the MessageTypeHeader of messages.
+const (
+	EventMessageType     = "event"
+	ExceptionMessageType = "exception"
+	ErrorMessageType     = "error"
+)
+
+// HeaderValueType is the type of the value of a header, encoded before the
+// value.
+type HeaderValueType uint8
+
+// Types of the values of headers.
+const (
+	TrueValueType HeaderValueType = iota
+	FalseValueType
+	ByteValueType
+	Int16ValueType
+	Int32ValueType
+	Int64ValueType
+	BytesValueType
+	StringValueType
+	TimestampValueType
+	UUIDValueType
+)
+
+func (t HeaderValueType) String() string {
+	switch t {
+	case TrueValueType, FalseValueType:
+		return "bool"
+	case ByteValueType:
+		return "byte"
+	case Int16ValueType:
+		return "int16"
+	case Int32ValueType:
+		return "int32"
+	case Int64ValueType:
+		return "int64"
+	case BytesValueType:
+		return "bytes"
+	case StringValueType:
+		return "string"
+	case TimestampValueType:
+		return "timestamp"
+	case UUIDValueType:
+		return "uuid"
+	default:
+		return "unknown(" + strconv.Itoa(int(t)) + ")"
+	}
+}
+
+// Value is the value of a header.
+type Value interface {
+	// Type returns the type of the value.
+	Type() HeaderValueType
+
+	// String returns the value in a human readable form.
+	String() string
+
+	// encodedLen returns the length of the encoded value, excluding its type.
+	encodedLen() int
+
+	// encode appends the encoded value, excluding its type, to p.
+	encode(p []byte) []byte
+}
+
+// BoolValue is a header value of a bool.
+type BoolValue bool
+
+// Type returns TrueValueType or FalseValueType.
+func (v BoolValue) Type() HeaderValueType {
+	if v {
+		return TrueValueType
+	}
+	return FalseValueType
+}
+
+func (v BoolValue) String() string       { return strconv.FormatBool(bool(v)) }
+func (BoolValue) encodedLen() int        { return 0 }
+func (BoolValue) encode(p []byte) []byte { return p }
+
+// ByteValue is a header value of a byte.
+type ByteValue int8
+
+// Type returns ByteValueType.
+func (ByteValue) Type() HeaderValueType    { return ByteValueType }
+func (v ByteValue) String() string         { return strconv.Itoa(int(v)) }
+func (ByteValue) encodedLen() int          { return 1 }
+func (v ByteValue) encode(p []byte) []byte { return append(p, byte(v)) }
+
+// Int16Value is a header value of an int16.
+type Int16Value int16
+
+// Type returns Int16ValueType.
+func (Int16Value) Type() HeaderValueType { return Int16ValueType }
+func (v Int16Value) String() string      { return strconv.Itoa(int(v)) }
+func (Int16Value) encodedLen() int       { return 2 }
+func (v Int16Value) encode(p []byte) []byte {
+	return binary.BigEndian.AppendUint16(p, uint16(v))
+}
+
+// Int32Value is a header value of an int32.
+type Int32Value int32
+
+// Type returns Int32ValueType.
+func (Int32Value) Type() HeaderValueType { return Int32ValueType }
+func (v Int32Value) String() string      { return strconv.Itoa(int(v)) }
+func (Int32Value) encodedLen() int       { return 4 }
+func (v Int32Value) encode(p []byte) []byte {
+	return binary.BigEndian.AppendUint32(p, uint32(v))
+}
+
+// Int64Value is a header value of an int64.
+type Int64Value int64
+
+// Type returns Int64ValueType.
+func (Int64Value) Type() HeaderValueType { return Int64ValueType }
+func (v Int64Value) String() string      { return strconv.FormatInt(int64(v), 10) }
+func (Int64Value) encodedLen() int       { return 8 }
+func (v Int64Value) encode(p []byte) []byte {
+	return binary.BigEndian.AppendUint64(p, uint64(v))
+}
+
+// BytesValue is a header value of a byte slice, of at most
+// MaxHeaderValueLen bytes.
+type BytesValue []byte
+
+// Type returns BytesValueType.
+func (BytesValue) Type() HeaderValueType { return BytesValueType }
+func (v BytesValue) String() string      { return hex.EncodeToString(v) }
+func (v BytesValue) encodedLen() int     { return 2 + len(v) }
+func (v BytesValue) encode(p []byte) []byte {
+	p = binary.BigEndian.AppendUint16(p, uint16(len(v)))
+	return append(p, v...)
+}
+
+// StringValue is a header value of a string, of at most MaxHeaderValueLen
+// bytes.
+type StringValue string
+
+// Type returns StringValueType.
+func (StringValue) Type() HeaderValueType { return StringValueType }
+func (v StringValue) String() string      { return string(v) }
+func (v StringValue) encodedLen() int     { return 2 + len(v) }
+func (v StringValue) encode(p []byte) []byte {
+	p = binary.BigEndian.AppendUint16(p, uint16(len(v)))
+	return append(p, v...)
+}
+
+// TimestampValue is a header value of a time, encoded with millisecond
+// precision.
+type TimestampValue time.Time
+
+// Type returns TimestampValueType.
+func (TimestampValue) Type() HeaderValueType { return TimestampValueType }
+func (v TimestampValue) String() string {
+	return time.Time(v).UTC().Format(time.RFC3339Nano)
+}
+func (TimestampValue) encodedLen() int { return 8 }
+func (v TimestampValue) encode(p []byte) []byte {
+	return binary.BigEndian.AppendUint64(p, uint64(time.Time(v).UnixMilli()))
+}
+
+// UUIDValue is a header value of a UUID.
+type UUIDValue [16]byte
+
+// Type returns UUIDValueType.
+func (UUIDValue) Type() HeaderValueType { return UUIDValueType }
+func (v UUIDValue) String() string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", v[0:4], v[4:6], v[6:8], v[8:10], v[10:])
+}
+func (UUIDValue) encodedLen() int          { return 16 }
+func (v UUIDValue) encode(p []byte) []byte { return append(p, v[:]...) }
+
+// Header is a header of a message.
+type Header struct {
+	// The name of the header, of at most MaxHeaderNameLen bytes.
+	Name string
+
+	Value Value
+}
+
+// Headers are the headers of a message, in the order they are encoded.
+type Headers []Header
+
+// Get returns the value of the first header with the name, or nil if there
+// is none.
+func (hs Headers) Get(name string) Value {
+	for _, h := range hs {
+		if h.Name == name {
+			return h.Value
+		}
+	}
+	return nil
+}
+
+// GetString returns the value of the first header with the name if it has a
+// string value.
+func (hs Headers) GetString(name string) (string, bool) {
+	v, ok := hs.Get(name).(StringValue)
+	return string(v), ok
+}
+
+// Set replaces the headers with the name by a header with the value.
+func (hs *Headers) Set(name string, value Value) {
+	hs.Del(name)
+	*hs = append(*hs, Header{Name: name, Value: value})
+}
+
+// Del removes the headers with the name.
+func (hs *Headers) Del(name string) {
+	kept := (*hs)[:0]
+	for _, h := range *hs {
+		if h.Name != name {
+			kept = append(kept, h)
+		}
+	}
+	*hs = kept
+}
@@ -0,0 +1,266 @@
+package eventstream
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"time"
+)
+
+// Limits of the sizes of the parts of a message.
+const (
+	// MaxPayloadLen is the maximum length in bytes of the payload of a
+	// message.
+	MaxPayloadLen = 16 * 1024 * 1024
+
+	// MaxHeadersLen is the maximum length in bytes of the encoded headers
+	// of a message.
+	MaxHeadersLen = 128 * 1024
+
+	// MaxHeaderNameLen is the maximum length in bytes of the name of a
+	// header.
+	MaxHeaderNameLen = 255
+
+	// MaxHeaderValueLen is the maximum length in bytes of a bytes or string
+	// header value.
+	MaxHeaderValueLen = 32767
+)
+
+const (
+	// the total length, headers length, and prelude CRC
+	preludeLen = 12
+
+	messageCRCLen = 4
+
+	minMessageLen = preludeLen + messageCRCLen
+	maxMessageLen = minMessageLen + MaxHeadersLen + MaxPayloadLen
+)
+
+// Message is a message of an event stream.
+type Message struct {
+	Headers Headers
+	Payload []byte
+}
+
+// ChecksumError is returned when decoding a message whose prelude or message
+// CRC does not match its content, e.g. as it was corrupted.
+type ChecksumError struct {
+	// The part of the message, "prelude" or "message".
+	Part string
+
+	Expect uint32
+	Actual uint32
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("event stream %s checksum mismatch, expect %08x, got %08x", e.Part, e.Expect, e.Actual)
+}
+
+// LengthError is returned when encoding or decoding a message if a part of
+// the message exceeds its maximum length.
+type LengthError struct {
+	// The part of the message, e.g. "payload".
+	Part string
+
+	Limit  int
+	Length int
+}
+
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("event stream %s length %d exceeds limit of %d", e.Part, e.Length, e.Limit)
+}
+
+// AppendMessage appends the encoded message to p, returning an error if a
+// part of the message exceeds its maximum length.
+func AppendMessage(p []byte, msg Message) ([]byte, error) {
+	headersLen, err := encodedHeadersLen(msg.Headers)
+	if err != nil {
+		return p, err
+	}
+	if len(msg.Payload) > MaxPayloadLen {
+		return p, &LengthError{Part: "payload", Limit: MaxPayloadLen, Length: len(msg.Payload)}
+	}
+	totalLen := minMessageLen + headersLen + len(msg.Payload)
+
+	start := len(p)
+	p = binary.BigEndian.AppendUint32(p, uint32(totalLen))
+	p = binary.BigEndian.AppendUint32(p, uint32(headersLen))
+	p = binary.BigEndian.AppendUint32(p, crc32.ChecksumIEEE(p[start:]))
+
+	for _, h := range msg.Headers {
+		p = append(p, byte(len(h.Name)))
+		p = append(p, h.Name...)
+		p = append(p, byte(h.Value.Type()))
+		p = h.Value.encode(p)
+	}
+	p = append(p, msg.Payload...)
+
+	return binary.BigEndian.AppendUint32(p, crc32.ChecksumIEEE(p[start:])), nil
+}
+
+func encodedHeadersLen(hs Headers) (int, error) {
+	var n int
+	for _, h := range hs {
+		if len(h.Name) == 0 || len(h.Name) > MaxHeaderNameLen {
+			return 0, &LengthError{Part: "header name", Limit: MaxHeaderNameLen, Length: len(h.Name)}
+		}
+		if h.Value == nil {
+			return 0, fmt.Errorf("event stream header %s has no value", h.Name)
+		}
+		switch v := h.Value.(type) {
+		case BytesValue:
+			if len(v) > MaxHeaderValueLen {
+				return 0, &LengthError{Part: "header " + h.Name + " value", Limit: MaxHeaderValueLen, Length: len(v)}
+			}
+		case StringValue:
+			if len(v) > MaxHeaderValueLen {
+				return 0, &LengthError{Part: "header " + h.Name + " value", Limit: MaxHeaderValueLen, Length: len(v)}
+			}
+		}
+		n += 1 + len(h.Name) + 1 + h.Value.encodedLen()
+	}
+	if n > MaxHeadersLen {
+		return 0, &LengthError{Part: "headers", Limit: MaxHeadersLen, Length: n}
+	}
+	return n, nil
+}
+
+// DecodeMessage decodes the message encoded at the start of p, returning the
+// message and the number of bytes of p it was encoded in. The payload, and
+// bytes header values, of the message alias p.
+//
+// Returns an error if p does not start with a whole message, or the message
+// is malformed.
+func DecodeMessage(p []byte) (Message, int, error) {
+	if len(p) < preludeLen {
+		return Message{}, 0, fmt.Errorf("event stream message prelude truncated, %d bytes", len(p))
+	}
+	totalLen, headersLen, err := decodePrelude(p[:preludeLen])
+	if err != nil {
+		return Message{}, 0, err
+	}
+	if len(p) < totalLen {
+		return Message{}, 0, fmt.Errorf("event stream message truncated, expect %d bytes, got %d", totalLen, len(p))
+	}
+
+	msg, err := decodeMessage(p[:totalLen], headersLen)
+	if err != nil {
+		return Message{}, 0, err
+	}
+	return msg, totalLen, nil
+}
+
+// decodePrelude validates the prelude of a message, returning the total
+// length of the message, and the length of its headers.
+func decodePrelude(p []byte) (totalLen, headersLen int, err error) {
+	expect := binary.BigEndian.Uint32(p[8:12])
+	if actual := crc32.ChecksumIEEE(p[:8]); expect != actual {
+		return 0, 0, &ChecksumError{Part: "prelude", Expect: expect, Actual: actual}
+	}
+
+	total := binary.BigEndian.Uint32(p[0:4])
+	headers := binary.BigEndian.Uint32(p[4:8])
+	if total < minMessageLen || total > maxMessageLen {
+		return 0, 0, fmt.Errorf("event stream message length %d is invalid", total)
+	}
+	if headers > MaxHeadersLen {
+		return 0, 0, &LengthError{Part: "headers", Limit: MaxHeadersLen, Length: int(headers)}
+	}
+	if headers > total-minMessageLen {
+		return 0, 0, fmt.Errorf("event stream headers length %d exceeds message length %d", headers, total)
+	}
+	if payload := total - minMessageLen - headers; payload > MaxPayloadLen {
+		return 0, 0, &LengthError{Part: "payload", Limit: MaxPayloadLen, Length: int(payload)}
+	}
+	return int(total), int(headers), nil
+}
+
+// decodeMessage decodes a whole message, of a validated prelude.
+func decodeMessage(p []byte, headersLen int) (Message, error) {
+	crcOffset := len(p) - messageCRCLen
+	expect := binary.BigEndian.Uint32(p[crcOffset:])
+	if actual := crc32.ChecksumIEEE(p[:crcOffset]); expect != actual {
+		return Message{}, &ChecksumError{Part: "message", Expect: expect, Actual: actual}
+	}
+
+	headers, err := decodeHeaders(p[preludeLen : preludeLen+headersLen])
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Headers: headers,
+		Payload: p[preludeLen+headersLen : crcOffset],
+	}, nil
+}
+
+func decodeHeaders(p []byte) (Headers, error) {
+	var hs Headers
+	for len(p) != 0 {
+		nameLen := int(p[0])
+		if nameLen == 0 {
+			return nil, fmt.Errorf("event stream header name is empty")
+		}
+		if len(p) < 1+nameLen+1 {
+			return nil, fmt.Errorf("event stream header truncated")
+		}
+		name := string(p[1 : 1+nameLen])
+		typ := HeaderValueType(p[1+nameLen])
+		p = p[1+nameLen+1:]
+
+		v, n, err := decodeValue(typ, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode event stream header %s, %w", name, err)
+		}
+		hs = append(hs, Header{Name: name, Value: v})
+		p = p[n:]
+	}
+	return hs, nil
+}
+
+// fixedValueLens are the encoded lengths of the header value types of a fixed
+// length.
+var fixedValueLens = map[HeaderValueType]int{
+	ByteValueType: 1, Int16ValueType: 2, Int32ValueType: 4, Int64ValueType: 8,
+	TimestampValueType: 8, UUIDValueType: 16,
+}
+
+func decodeValue(typ HeaderValueType, p []byte) (Value, int, error) {
+	if n, ok := fixedValueLens[typ]; ok && len(p) < n {
+		return nil, 0, fmt.Errorf("%s value truncated", typ)
+	}
+
+	switch typ {
+	case TrueValueType:
+		return BoolValue(true), 0, nil
+	case FalseValueType:
+		return BoolValue(false), 0, nil
+	case ByteValueType:
+		return ByteValue(p[0]), 1, nil
+	case Int16ValueType:
+		return Int16Value(binary.BigEndian.Uint16(p)), 2, nil
+	case Int32ValueType:
+		return Int32Value(binary.BigEndian.Uint32(p)), 4, nil
+	case Int64ValueType:
+		return Int64Value(binary.BigEndian.Uint64(p)), 8, nil
+	case TimestampValueType:
+		return TimestampValue(time.UnixMilli(int64(binary.BigEndian.Uint64(p)))), 8, nil
+	case UUIDValueType:
+		var v UUIDValue
+		copy(v[:], p)
+		return v, 16, nil
+	case BytesValueType, StringValueType:
+		if len(p) < 2 {
+			return nil, 0, fmt.Errorf("%s value length truncated", typ)
+		}
+		n := int(binary.BigEndian.Uint16(p))
+		if len(p) < 2+n {
+			return nil, 0, fmt.Errorf("%s value truncated", typ)
+		}
+		if typ == StringValueType {
+			return StringValue(p[2 : 2+n]), 2 + n, nil
+		}
+		return BytesValue(p[2 : 2+n]), 2 + n, nil
+	default:
+		return nil, 0, fmt.Errorf("unknown header value type %d", uint8(typ))
+	}
+}
@@ -0,0 +1,190 @@
+package eventstream
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendMessage_Empty(t *testing.T) {
+	p, err := AppendMessage(nil, Message{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	expect := []byte{
+		0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00,
+		0x05, 0xc2, 0x48, 0xeb, 0x7d, 0x98, 0xc8, 0xff,
+	}
+	if !bytes.Equal(expect, p) {
+		t.Errorf("expect %x, got %x", expect, p)
+	}
+}
+
+func TestMessage_RoundTrip(t *testing.T) {
+	cases := map[string]Message{
+		"empty": {},
+		"payload": {
+			Payload: []byte(`{"foo":"bar"}`),
+		},
+		"headers": {
+			Headers: Headers{
+				{Name: "true", Value: BoolValue(true)},
+				{Name: "false", Value: BoolValue(false)},
+				{Name: "byte", Value: ByteValue(-1)},
+				{Name: "int16", Value: Int16Value(-2)},
+				{Name: "int32", Value: Int32Value(-3)},
+				{Name: "int64", Value: Int64Value(-4)},
+				{Name: "bytes", Value: BytesValue{0xde, 0xad}},
+				{Name: "string", Value: StringValue("hello")},
+				{Name: "timestamp", Value: TimestampValue(time.UnixMilli(1700000000123))},
+				{Name: "uuid", Value: UUIDValue{0: 1, 15: 2}},
+			},
+			Payload: []byte("payload"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			p, err := AppendMessage([]byte("prefix"), c)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			p = append(p, "suffix"...)
+
+			msg, n, err := DecodeMessage(p[len("prefix"):])
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := len(p)-len("prefix")-len("suffix"), n; e != a {
+				t.Errorf("expect %v bytes decoded, got %v", e, a)
+			}
+			if len(c.Payload) == 0 {
+				c.Payload = []byte{}
+			}
+			if e, a := c, msg; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestDecodeMessage_Errors(t *testing.T) {
+	valid, err := AppendMessage(nil, NewEventMessage("Event", "text/plain", []byte("hello")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupt := func(i int) []byte {
+		p := append([]byte{}, valid...)
+		p[i] ^= 0xff
+		return p
+	}
+
+	cases := map[string]struct {
+		input          []byte
+		expectChecksum string
+		expectErr      string
+	}{
+		"truncated prelude": {
+			input:     valid[:8],
+			expectErr: "prelude truncated",
+		},
+		"truncated message": {
+			input:     valid[:len(valid)-1],
+			expectErr: "message truncated",
+		},
+		"prelude checksum": {
+			input:          corrupt(2),
+			expectChecksum: "prelude",
+		},
+		"message checksum": {
+			input:          corrupt(len(valid) - 6),
+			expectChecksum: "message",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := DecodeMessage(c.input)
+			if err == nil {
+				t.Fatalf("expect error")
+			}
+
+			var cerr *ChecksumError
+			if e, a := len(c.expectChecksum) != 0, errors.As(err, &cerr); e != a {
+				t.Fatalf("expect checksum error %v, got %v", e, err)
+			}
+			if cerr != nil && c.expectChecksum != cerr.Part {
+				t.Errorf("expect %q checksum error, got %q", c.expectChecksum, cerr.Part)
+			}
+			if !strings.Contains(err.Error(), c.expectErr) {
+				t.Errorf("expect error to contain %q, got %v", c.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestAppendMessage_Limits(t *testing.T) {
+	cases := map[string]struct {
+		msg        Message
+		expectPart string
+	}{
+		"payload": {
+			msg:        Message{Payload: make([]byte, MaxPayloadLen+1)},
+			expectPart: "payload",
+		},
+		"header name": {
+			msg:        Message{Headers: Headers{{Name: strings.Repeat("a", 256), Value: BoolValue(true)}}},
+			expectPart: "header name",
+		},
+		"header value": {
+			msg:        Message{Headers: Headers{{Name: "a", Value: StringValue(strings.Repeat("a", 32768))}}},
+			expectPart: "header a value",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := AppendMessage(nil, c.msg)
+
+			var lerr *LengthError
+			if !errors.As(err, &lerr) {
+				t.Fatalf("expect length error, got %v", err)
+			}
+			if e, a := c.expectPart, lerr.Part; e != a {
+				t.Errorf("expect part %q, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	var hs Headers
+	hs.Set("a", StringValue("1"))
+	hs.Set("b", Int32Value(2))
+	hs = append(hs, Header{Name: "a", Value: StringValue("3")})
+
+	if v, ok := hs.GetString("a"); !ok || v != "1" {
+		t.Errorf("expect a of 1, got %v, %v", v, ok)
+	}
+	if _, ok := hs.GetString("b"); ok {
+		t.Errorf("expect b not to be a string")
+	}
+
+	hs.Set("a", StringValue("4"))
+	expect := Headers{
+		{Name: "b", Value: Int32Value(2)},
+		{Name: "a", Value: StringValue("4")},
+	}
+	if !reflect.DeepEqual(expect, hs) {
+		t.Errorf("expect %v, got %v", expect, hs)
+	}
+
+	hs.Del("b")
+	if v := hs.Get("b"); v != nil {
+		t.Errorf("expect b removed, got %v", v)
+	}
+}
@@ -0,0 +1,201 @@
+package eventstream
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/smithy-go"
+)
+
+// ContentType is the media type of the body of an event stream.
+const ContentType = "application/vnd.amazon.eventstream"
+
+// Reader reads the messages of an event stream, e.g. from the body of an HTTP
+// response.
+type Reader struct {
+	r   io.Reader
+	buf []byte
+}
+
+// NewReader returns a Reader of the messages of r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+// ReadMessage reads the next message of the stream. The payload, and bytes
+// header values, of the message are only valid until the next call to
+// ReadMessage.
+//
+// Returns io.EOF if the stream ends before the next message, or
+// io.ErrUnexpectedEOF if the stream ends within a message.
+func (r *Reader) ReadMessage() (Message, error) {
+	r.buf = r.grow(preludeLen)
+	if _, err := io.ReadFull(r.r, r.buf); err != nil {
+		return Message{}, err
+	}
+	totalLen, headersLen, err := decodePrelude(r.buf)
+	if err != nil {
+		return Message{}, err
+	}
+
+	r.buf = r.grow(totalLen)
+	if _, err := io.ReadFull(r.r, r.buf[preludeLen:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return Message{}, err
+	}
+	return decodeMessage(r.buf, headersLen)
+}
+
+// Close closes the underlying reader if it is an io.Closer, e.g. the body of
+// the response the stream is received in.
+func (r *Reader) Close() error {
+	if c, ok := r.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// grow returns the buffer of the reader grown to n bytes, keeping its content.
+func (r *Reader) grow(n int) []byte {
+	if cap(r.buf) >= n {
+		return r.buf[:n]
+	}
+	buf := make([]byte, n)
+	copy(buf, r.buf)
+	return buf
+}
+
+// Writer writes the messages of an event stream, e.g. to the body of an HTTP
+// request. It is safe for concurrent use.
+type Writer struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf []byte
+}
+
+// NewWriter returns a Writer of messages to w. Each message is written to w
+// with a single call to Write.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteMessage writes the message to the stream.
+func (w *Writer) WriteMessage(msg Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buf, err := AppendMessage(w.buf[:0], msg)
+	if err != nil {
+		return err
+	}
+	w.buf = buf
+
+	if _, err := w.w.Write(buf); err != nil {
+		return fmt.Errorf("failed to write event stream message, %w", err)
+	}
+	return nil
+}
+
+// Close closes the underlying writer if it is an io.Closer, e.g. to end the
+// body of the request the stream is sent in.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if c, ok := w.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// EventDeserializer deserializes the messages of an event stream of an
+// operation, see EventReader.
+type EventDeserializer interface {
+	// DeserializeEvent deserializes a message with the event message type,
+	// of the event type.
+	DeserializeEvent(eventType string, msg *Message) (interface{}, error)
+
+	// DeserializeException deserializes a message with the exception message
+	// type, of the exception type, returning the modeled error of the
+	// exception.
+	DeserializeException(exceptionType string, msg *Message) error
+}
+
+// EventReader reads the events of an event stream, deserializing each event
+// with an EventDeserializer.
+type EventReader struct {
+	r            *Reader
+	deserializer EventDeserializer
+}
+
+// NewEventReader returns an EventReader of the events of r, deserialized with
+// d.
+func NewEventReader(r *Reader, d EventDeserializer) *EventReader {
+	return &EventReader{r: r, deserializer: d}
+}
+
+// ReadEvent reads the next event of the stream.
+//
+// Returns the error of a message with the exception type, deserialized with
+// the EventDeserializer, or a *smithy.GenericAPIError of a message with the
+// error type, of an error not modeled by the operation.
+// Returns io.EOF once the stream ends.
+func (r *EventReader) ReadEvent() (interface{}, error) {
+	msg, err := r.r.ReadMessage()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read event stream message, %w", err)
+	}
+
+	messageType, _ := msg.Headers.GetString(MessageTypeHeader)
+	switch messageType {
+	case EventMessageType:
+		eventType, ok := msg.Headers.GetString(EventTypeHeader)
+		if !ok {
+			return nil, fmt.Errorf("event stream event message has no %s header", EventTypeHeader)
+		}
+		return r.deserializer.DeserializeEvent(eventType, &msg)
+
+	case ExceptionMessageType:
+		exceptionType, ok := msg.Headers.GetString(ExceptionTypeHeader)
+		if !ok {
+			return nil, fmt.Errorf("event stream exception message has no %s header", ExceptionTypeHeader)
+		}
+		return nil, r.deserializer.DeserializeException(exceptionType, &msg)
+
+	case ErrorMessageType:
+		code, _ := msg.Headers.GetString(ErrorCodeHeader)
+		message, _ := msg.Headers.GetString(ErrorMessageHeader)
+		return nil, &smithy.GenericAPIError{Code: code, Message: message}
+
+	default:
+		return nil, fmt.Errorf("unknown event stream message type %q", messageType)
+	}
+}
+
+// Close closes the underlying Reader.
+func (r *EventReader) Close() error {
+	return r.r.Close()
+}
+
+// NewEventMessage returns a message of an event of the event type, with the
+// payload of the content type.
+func NewEventMessage(eventType, contentType string, payload []byte) Message {
+	msg := Message{
+		Headers: Headers{
+			{Name: MessageTypeHeader, Value: StringValue(EventMessageType)},
+			{Name: EventTypeHeader, Value: StringValue(eventType)},
+		},
+		Payload: payload,
+	}
+	if len(contentType) != 0 {
+		msg.Headers.Set(ContentTypeHeader, StringValue(contentType))
+	}
+	return msg
+}
@@ -0,0 +1,162 @@
+package eventstream
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestReaderWriter(t *testing.T) {
+	messages := []Message{
+		NewEventMessage("First", "text/plain", []byte("hello")),
+		NewEventMessage("Second", "", bytes.Repeat([]byte("a"), 1024)),
+		NewEventMessage("Third", "", nil),
+	}
+
+	pr, pw := io.Pipe()
+	w := NewWriter(pw)
+	go func() {
+		for _, msg := range messages {
+			if err := w.WriteMessage(msg); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		w.Close()
+	}()
+
+	r := NewReader(pr)
+	defer r.Close()
+	for i, expect := range messages {
+		msg, err := r.ReadMessage()
+		if err != nil {
+			t.Fatalf("%d, expect no error, got %v", i, err)
+		}
+		if e, a := expect.Headers.Get(EventTypeHeader), msg.Headers.Get(EventTypeHeader); e != a {
+			t.Errorf("%d, expect event type %v, got %v", i, e, a)
+		}
+		if e, a := expect.Payload, msg.Payload; !bytes.Equal(e, a) {
+			t.Errorf("%d, expect payload %q, got %q", i, e, a)
+		}
+	}
+
+	if _, err := r.ReadMessage(); err != io.EOF {
+		t.Errorf("expect EOF, got %v", err)
+	}
+}
+
+func TestReader_UnexpectedEOF(t *testing.T) {
+	p, err := AppendMessage(nil, NewEventMessage("Event", "", []byte("hello")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReader(bytes.NewReader(p[:len(p)-1]))
+	if _, err := r.ReadMessage(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expect unexpected EOF, got %v", err)
+	}
+}
+
+type testEventDeserializer struct{}
+
+func (testEventDeserializer) DeserializeEvent(eventType string, msg *Message) (interface{}, error) {
+	return eventType + ":" + string(msg.Payload), nil
+}
+
+func (testEventDeserializer) DeserializeException(exceptionType string, msg *Message) error {
+	return fmt.Errorf("%s: %s", exceptionType, msg.Payload)
+}
+
+func TestEventReader(t *testing.T) {
+	cases := map[string]struct {
+		msg         Message
+		expectEvent interface{}
+		expectErr   string
+		expectAPI   *smithy.GenericAPIError
+	}{
+		"event": {
+			msg:         NewEventMessage("Greeting", "text/plain", []byte("hello")),
+			expectEvent: "Greeting:hello",
+		},
+		"exception": {
+			msg: Message{
+				Headers: Headers{
+					{Name: MessageTypeHeader, Value: StringValue(ExceptionMessageType)},
+					{Name: ExceptionTypeHeader, Value: StringValue("ThrottlingException")},
+				},
+				Payload: []byte("slow down"),
+			},
+			expectErr: "ThrottlingException: slow down",
+		},
+		"error": {
+			msg: Message{
+				Headers: Headers{
+					{Name: MessageTypeHeader, Value: StringValue(ErrorMessageType)},
+					{Name: ErrorCodeHeader, Value: StringValue("InternalError")},
+					{Name: ErrorMessageHeader, Value: StringValue("failed")},
+				},
+			},
+			expectAPI: &smithy.GenericAPIError{Code: "InternalError", Message: "failed"},
+		},
+		"no event type": {
+			msg: Message{
+				Headers: Headers{
+					{Name: MessageTypeHeader, Value: StringValue(EventMessageType)},
+				},
+			},
+			expectErr: "has no :event-type header",
+		},
+		"unknown message type": {
+			msg: Message{
+				Headers: Headers{
+					{Name: MessageTypeHeader, Value: StringValue("other")},
+				},
+			},
+			expectErr: `unknown event stream message type "other"`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			p, err := AppendMessage(nil, c.msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := NewEventReader(NewReader(bytes.NewReader(p)), testEventDeserializer{})
+			event, err := r.ReadEvent()
+
+			if c.expectAPI != nil {
+				var apiErr *smithy.GenericAPIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("expect API error, got %v", err)
+				}
+				if e, a := *c.expectAPI, *apiErr; e != a {
+					t.Errorf("expect %v, got %v", e, a)
+				}
+				return
+			}
+			if len(c.expectErr) != 0 {
+				if err == nil || !strings.Contains(err.Error(), c.expectErr) {
+					t.Fatalf("expect error %q, got %v", c.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.expectEvent, event; e != a {
+				t.Errorf("expect event %v, got %v", e, a)
+			}
+
+			if _, err := r.ReadEvent(); err != io.EOF {
+				t.Errorf("expect EOF, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package httpbinding
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// A Decoder provides decoding of REST URI path, query, and header components
+// of an incoming HTTP request, per the same binding rules as Encoder. It is
+// the server side counterpart of Encoder.
+type Decoder struct {
+	labels map[string]string
+
+	query  url.Values
+	header http.Header
+}
+
+// NewDecoder creates a new decoder of the request, bound by the Smithy HTTP
+// binding trait URI, e.g. "/{Bucket}/{Key+}?x-id=GetObject". Returns an error
+// if the request's path or query does not match the URI.
+//
+// Each label of the URI occupies an entire path segment, and is decoded from
+// the escaped path segment it matches. A greedy label, e.g. {Key+}, matches
+// one or more segments, which are decoded with their separating slashes. A
+// literal query of the URI must be present in the request's query. Returns a
+// URITemplateError if the URI is malformed.
+func NewDecoder(req *http.Request, uri string) (*Decoder, error) {
+	pattern, literalQuery := SplitURI(uri)
+	if _, err := parseURITemplate(pattern); err != nil {
+		return nil, err
+	}
+
+	query, err := url.ParseQuery(req.URL.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query string: %w", err)
+	}
+	if err := matchQuery(literalQuery, query); err != nil {
+		return nil, err
+	}
+
+	labels, err := matchPath(pattern, req.URL.EscapedPath())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Decoder{
+		labels: labels,
+		query:  query,
+		header: req.Header,
+	}, nil
+}
+
+// GetURI returns the value of the given path label, and whether the label is
+// bound by the URI of the decoder.
+func (d *Decoder) GetURI(key string) (DecodedValue, bool) {
+	v, ok := d.labels[key]
+	return DecodedValue(v), ok
+}
+
+// GetQuery returns the first value of the given query key, and whether the
+// key is present.
+func (d *Decoder) GetQuery(key string) (DecodedValue, bool) {
+	vs, ok := d.query[key]
+	if !ok || len(vs) == 0 {
+		return "", false
+	}
+	return DecodedValue(vs[0]), true
+}
+
+// GetQueryValues returns the values of the given query key, e.g. of a list
+// member bound to the key, in order.
+func (d *Decoder) GetQueryValues(key string) []DecodedValue {
+	return decodedValues(d.query[key], false)
+}
+
+// Query returns the parsed query of the request, e.g. to decode a map member
+// bound to all query params. The returned url.Values must not be modified.
+func (d *Decoder) Query() url.Values {
+	return d.query
+}
+
+// HasQuery returns if a query with the key specified exists with one or
+// more values.
+func (d *Decoder) HasQuery(key string) bool {
+	return len(d.query[key]) != 0
+}
+
+// GetHeader returns the first value of the given header name, with
+// surrounding whitespace trimmed, and whether the header is present.
+func (d *Decoder) GetHeader(key string) (DecodedValue, bool) {
+	vs := d.header.Values(strings.TrimSpace(key))
+	if len(vs) == 0 {
+		return "", false
+	}
+	return DecodedValue(strings.TrimSpace(vs[0])), true
+}
+
+// GetHeaderValues returns the values of the given header name, with
+// surrounding whitespace trimmed. Values are not split by comma, such that a
+// list member bound to the header should be split as by the client, e.g. with
+// transport/http.SplitHeaderListValues.
+func (d *Decoder) GetHeaderValues(key string) []DecodedValue {
+	return decodedValues(d.header.Values(strings.TrimSpace(key)), true)
+}
+
+// HasHeader returns if a header with the key specified exists with one or
+// more value.
+func (d *Decoder) HasHeader(key string) bool {
+	return len(d.header.Values(strings.TrimSpace(key))) != 0
+}
+
+// GetPrefixHeaders returns the headers whose names start with the given
+// prefix, compared case-insensitively, keyed by the remainder of their
+// canonical names, e.g. "Foo" for "X-Amz-Meta-Foo" with prefix
+// "X-Amz-Meta-", see Encoder.Headers. The first value of each header is
+// returned, with surrounding whitespace trimmed. Returns nil if there are
+// none. See PrefixHeaders for other key cases and duplicate handling.
+func (d *Decoder) GetPrefixHeaders(prefix string) map[string]string {
+	return PrefixHeaders(d.header, prefix)
+}
+
+func decodedValues(vs []string, trim bool) []DecodedValue {
+	if len(vs) == 0 {
+		return nil
+	}
+
+	values := make([]DecodedValue, len(vs))
+	for i, v := range vs {
+		if trim {
+			v = strings.TrimSpace(v)
+		}
+		values[i] = DecodedValue(v)
+	}
+	return values
+}
+
+// matchPath returns the values of the labels of the pattern, decoded from
+// the escaped path. Returns an error if the path does not match the pattern.
+func matchPath(pattern, path string) (map[string]string, error) {
+	patternSegments := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	greedy := -1
+	for i, s := range patternSegments {
+		if _, isGreedy, ok := parseLabel(s); ok && isGreedy {
+			greedy = i
+			break
+		}
+	}
+
+	if greedy < 0 && len(pathSegments) != len(patternSegments) ||
+		greedy >= 0 && len(pathSegments) < len(patternSegments) {
+		return nil, fmt.Errorf("request path %s does not match %s", path, pattern)
+	}
+
+	labels := map[string]string{}
+	for i, s := range patternSegments {
+		segment := pathSegments[i]
+		if greedy >= 0 && i >= greedy {
+			// segments after the greedy label match from the end of the path
+			segment = pathSegments[len(pathSegments)-len(patternSegments)+i]
+		}
+		if i == greedy {
+			segment = strings.Join(pathSegments[greedy:len(pathSegments)-len(patternSegments)+greedy+1], "/")
+		}
+
+		value, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unescape request path %s: %w", path, err)
+		}
+
+		key, _, isLabel := parseLabel(s)
+		switch {
+		case !isLabel && value != s:
+			return nil, fmt.Errorf("request path %s does not match %s", path, pattern)
+		case isLabel && len(value) == 0:
+			return nil, fmt.Errorf("request path %s has empty label %s", path, key)
+		case isLabel:
+			labels[key] = value
+		}
+	}
+
+	return labels, nil
+}
+
+// parseLabel returns the name of the label of the pattern segment, e.g.
+// "Key" for "{Key+}", whether the label is greedy, and whether the segment is
+// a label.
+func parseLabel(segment string) (key string, greedy bool, ok bool) {
+	if len(segment) < 2 || segment[0] != uriTokenStart || segment[len(segment)-1] != uriTokenStop {
+		return "", false, false
+	}
+
+	key = segment[1 : len(segment)-1]
+	if strings.HasSuffix(key, string(uriTokenSkip)) {
+		return key[:len(key)-1], true, true
+	}
+	return key, false, true
+}
+
+// matchQuery returns an error if a param of the literal query is not present
+// in the query, with the same value if the literal has one.
+func matchQuery(literal string, query url.Values) error {
+	if len(literal) == 0 {
+		return nil
+	}
+
+	for _, param := range strings.Split(literal, "&") {
+		key, value, hasValue := strings.Cut(param, "=")
+		vs, ok := query[key]
+		if !ok {
+			return fmt.Errorf("request query does not have %s", key)
+		}
+		if !hasValue {
+			continue
+		}
+
+		found := false
+		for _, v := range vs {
+			found = found || v == value
+		}
+		if !found {
+			return fmt.Errorf("request query does not have %s=%s", key, value)
+		}
+	}
+	return nil
+}
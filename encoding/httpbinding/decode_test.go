@@ -0,0 +1,363 @@
+package httpbinding
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"math/big"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewDecoder_Path(t *testing.T) {
+	cases := map[string]struct {
+		uri       string
+		path      string
+		expected  map[string]string
+		expectErr string
+	}{
+		"root": {
+			uri:      "/",
+			path:     "/",
+			expected: map[string]string{},
+		},
+		"literal": {
+			uri:      "/some/path",
+			path:     "/some/path",
+			expected: map[string]string{},
+		},
+		"labels": {
+			uri:      "/some/{pathKeyOne}/{pathKeyTwo}",
+			path:     "/some/a%2Fb/c%20d",
+			expected: map[string]string{"pathKeyOne": "a/b", "pathKeyTwo": "c d"},
+		},
+		"greedy label": {
+			uri:      "/{Bucket}/{Key+}",
+			path:     "/bucket/a/b%3F/c",
+			expected: map[string]string{"Bucket": "bucket", "Key": "a/b?/c"},
+		},
+		"greedy label with suffix": {
+			uri:      "/{Prefix+}/object/{Name}",
+			path:     "/a/b/object/c",
+			expected: map[string]string{"Prefix": "a/b", "Name": "c"},
+		},
+		"literal mismatch": {
+			uri:       "/some/{pathKey}",
+			path:      "/other/value",
+			expectErr: "does not match",
+		},
+		"too many segments": {
+			uri:       "/some/{pathKey}",
+			path:      "/some/a/b",
+			expectErr: "does not match",
+		},
+		"greedy label without segments": {
+			uri:       "/{Bucket}/{Key+}",
+			path:      "/bucket",
+			expectErr: "does not match",
+		},
+		"empty label": {
+			uri:       "/some/{pathKey}",
+			path:      "/some/",
+			expectErr: "empty label pathKey",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			u, err := url.Parse("https://example.com" + c.path)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			d, err := NewDecoder(&http.Request{URL: u, Header: http.Header{}}, c.uri)
+			if len(c.expectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				if e, a := c.expectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expected %v in error, got %v", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if e, a := c.expected, d.labels; !reflect.DeepEqual(e, a) {
+				t.Errorf("expected %v, got %v", e, a)
+			}
+			for k, e := range c.expected {
+				if a, ok := d.GetURI(k); !ok || e != a.String() {
+					t.Errorf("expected %v %v, got %v, %v", k, e, a, ok)
+				}
+			}
+		})
+	}
+}
+
+func TestNewDecoder_LiteralQuery(t *testing.T) {
+	cases := map[string]struct {
+		query     string
+		expectErr string
+	}{
+		"match": {
+			query: "x-id=GetObject&list&other=1",
+		},
+		"missing key": {
+			query:     "x-id=GetObject",
+			expectErr: "request query does not have list",
+		},
+		"mismatched value": {
+			query:     "x-id=PutObject&list",
+			expectErr: "request query does not have x-id=GetObject",
+		},
+		"malformed": {
+			query:     "x-id=%zz",
+			expectErr: "failed to parse query string",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := &http.Request{URL: &url.URL{Path: "/", RawQuery: c.query}}
+			_, err := NewDecoder(req, "/?x-id=GetObject&list")
+			if len(c.expectErr) == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			if e, a := c.expectErr, err.Error(); !strings.Contains(a, e) {
+				t.Errorf("expected %v in error, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestDecoder_RoundTrip(t *testing.T) {
+	const uri = "/some/{pathKeyOne}/{pathKeyTwo+}"
+
+	path, query := SplitURI(uri)
+	encoder, err := NewEncoder(path, query, http.Header{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	encoder.SetHeader("x-amzn-header-foo").String(" someValue ")
+	encoder.AddHeader("x-amzn-list").Integer(1)
+	encoder.AddHeader("x-amzn-list").Integer(2)
+	encoder.Headers("x-amzn-meta-").SetHeader("foo").String("a")
+	encoder.Headers("x-amzn-meta-").SetHeader("bar").Boolean(true)
+	encoder.SetQuery("someKey").Double(math.Inf(-1))
+	encoder.AddQuery("list").String("a b")
+	encoder.AddQuery("list").String("c&d")
+	if err := encoder.SetURI("pathKeyOne").String("a/b"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := encoder.SetURI("pathKeyTwo").String("c d/e+f"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if req, err = encoder.Encode(req); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// send the request over the wire, as received by a server
+	var buf bytes.Buffer
+	if err := req.Write(&buf); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	req, err = http.ReadRequest(bufio.NewReader(&buf))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	d, err := NewDecoder(req, uri)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if v, _ := d.GetURI("pathKeyOne"); v != "a/b" {
+		t.Errorf("expected pathKeyOne a/b, got %q", v)
+	}
+	if v, _ := d.GetURI("pathKeyTwo"); v != "c d/e+f" {
+		t.Errorf("expected pathKeyTwo c d/e+f, got %q", v)
+	}
+
+	if v, ok := d.GetHeader("x-amzn-header-foo"); !ok || v != "someValue" {
+		t.Errorf("expected header someValue, got %q, %v", v, ok)
+	}
+	var list []int32
+	for _, v := range d.GetHeaderValues("X-Amzn-List") {
+		i, err := v.Integer()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		list = append(list, i)
+	}
+	if e, a := []int32{1, 2}, list; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if !d.HasHeader("x-amzn-list") || d.HasHeader("x-amzn-missing") {
+		t.Errorf("expected only x-amzn-list header")
+	}
+	if e, a := map[string]string{"Foo": "a", "Bar": "true"}, d.GetPrefixHeaders("x-amzn-meta-"); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	v, ok := d.GetQuery("someKey")
+	if !ok {
+		t.Fatalf("expected someKey query")
+	}
+	if f, err := v.Double(); err != nil || !math.IsInf(f, -1) {
+		t.Errorf("expected -Infinity, got %v, %v", f, err)
+	}
+	if e, a := []DecodedValue{"a b", "c&d"}, d.GetQueryValues("list"); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if !d.HasQuery("list") || d.HasQuery("missing") {
+		t.Errorf("expected only list query")
+	}
+	if _, ok := d.GetQuery("missing"); ok {
+		t.Errorf("expected missing query not to be present")
+	}
+}
+
+func TestDecodedValue(t *testing.T) {
+	cases := map[string]struct {
+		decode    func() (interface{}, error)
+		expected  interface{}
+		expectErr bool
+	}{
+		"boolean": {
+			decode:   func() (interface{}, error) { return DecodedValue("false").Boolean() },
+			expected: false,
+		},
+		"boolean strict": {
+			decode:    func() (interface{}, error) { return DecodedValue("1").Boolean() },
+			expectErr: true,
+		},
+		"byte": {
+			decode:   func() (interface{}, error) { return DecodedValue("-128").Byte() },
+			expected: int8(-128),
+		},
+		"byte overflow": {
+			decode:    func() (interface{}, error) { return DecodedValue("128").Byte() },
+			expectErr: true,
+		},
+		"short": {
+			decode:   func() (interface{}, error) { return DecodedValue("1024").Short() },
+			expected: int16(1024),
+		},
+		"integer": {
+			decode:   func() (interface{}, error) { return DecodedValue("-1").Integer() },
+			expected: int32(-1),
+		},
+		"long": {
+			decode:   func() (interface{}, error) { return DecodedValue("9223372036854775807").Long() },
+			expected: int64(math.MaxInt64),
+		},
+		"long invalid": {
+			decode:    func() (interface{}, error) { return DecodedValue("1.5").Long() },
+			expectErr: true,
+		},
+		"float": {
+			decode:   func() (interface{}, error) { return DecodedValue("1.5").Float() },
+			expected: float32(1.5),
+		},
+		"double infinity": {
+			decode:   func() (interface{}, error) { return DecodedValue("Infinity").Double() },
+			expected: math.Inf(1),
+		},
+		"double invalid": {
+			decode:    func() (interface{}, error) { return DecodedValue("inf").Double() },
+			expectErr: true,
+		},
+		"big integer": {
+			decode:   func() (interface{}, error) { return DecodedValue("18446744073709551616").BigInteger() },
+			expected: new(big.Int).Lsh(big.NewInt(1), 64),
+		},
+		"big decimal": {
+			decode:   func() (interface{}, error) { return DecodedValue("1.5e3").BigDecimal() },
+			expected: big.NewFloat(1500),
+		},
+		"blob": {
+			decode:   func() (interface{}, error) { return DecodedValue("Zm9vIGJhcg==").Blob() },
+			expected: []byte("foo bar"),
+		},
+		"timestamp http-date": {
+			decode: func() (interface{}, error) {
+				return DecodedValue("Tue, 29 Apr 2014 18:30:38 GMT").Timestamp(TimestampHTTPDate)
+			},
+			expected: time.Date(2014, 4, 29, 18, 30, 38, 0, time.UTC),
+		},
+		"timestamp date-time": {
+			decode: func() (interface{}, error) {
+				return DecodedValue("2014-04-29T18:30:38.123Z").Timestamp(TimestampDateTime)
+			},
+			expected: time.Date(2014, 4, 29, 18, 30, 38, 123e6, time.UTC),
+		},
+		"timestamp epoch-seconds": {
+			decode: func() (interface{}, error) {
+				return DecodedValue("1398796238.123").Timestamp(TimestampEpochSeconds)
+			},
+			expected: time.Date(2014, 4, 29, 18, 30, 38, 123e6, time.UTC),
+		},
+		"timestamp invalid": {
+			decode: func() (interface{}, error) {
+				return DecodedValue("2014-04-29T18:30:38.123Z").Timestamp(TimestampHTTPDate)
+			},
+			expectErr: true,
+		},
+		"blob invalid": {
+			decode:    func() (interface{}, error) { return DecodedValue("Zm9v!").Blob() },
+			expectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, err := c.decode()
+			if c.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			switch e := c.expected.(type) {
+			case *big.Int:
+				if e.Cmp(v.(*big.Int)) != 0 {
+					t.Errorf("expected %v, got %v", e, v)
+				}
+			case *big.Float:
+				if e.Cmp(v.(*big.Float)) != 0 {
+					t.Errorf("expected %v, got %v", e, v)
+				}
+			default:
+				if !reflect.DeepEqual(e, v) {
+					t.Errorf("expected %v, got %v", e, v)
+				}
+			}
+		})
+	}
+
+	if v, err := DecodedValue("NaN").Double(); err != nil || !math.IsNaN(v) {
+		t.Errorf("expected NaN, got %v, %v", v, err)
+	}
+}
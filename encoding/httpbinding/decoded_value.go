@@ -0,0 +1,131 @@
+package httpbinding
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// DecodedValue is a value of a REST URI path label, query, or header of an
+// HTTP request, as decoded by a Decoder. Its methods parse the value as the
+// type of the member bound to it, per the same formats as Encoder.
+type DecodedValue string
+
+// String returns v as a string value
+func (v DecodedValue) String() string {
+	return string(v)
+}
+
+// Boolean parses v as a boolean value, which is either "true" or "false"
+func (v DecodedValue) Boolean() (bool, error) {
+	switch v {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to parse %q as boolean", string(v))
+	}
+}
+
+// Byte parses v as an int8 value
+func (v DecodedValue) Byte() (int8, error) {
+	i, err := v.integer(8)
+	return int8(i), err
+}
+
+// Short parses v as an int16 value
+func (v DecodedValue) Short() (int16, error) {
+	i, err := v.integer(16)
+	return int16(i), err
+}
+
+// Integer parses v as an int32 value
+func (v DecodedValue) Integer() (int32, error) {
+	i, err := v.integer(32)
+	return int32(i), err
+}
+
+// Long parses v as an int64 value
+func (v DecodedValue) Long() (int64, error) {
+	return v.integer(64)
+}
+
+func (v DecodedValue) integer(bitSize int) (int64, error) {
+	i, err := strconv.ParseInt(string(v), 10, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %q as integer, %w", string(v), err)
+	}
+	return i, nil
+}
+
+// Float parses v as a float32 value
+func (v DecodedValue) Float() (float32, error) {
+	f, err := v.float(32)
+	return float32(f), err
+}
+
+// Double parses v as a float64 value
+func (v DecodedValue) Double() (float64, error) {
+	return v.float(64)
+}
+
+func (v DecodedValue) float(bitSize int) (float64, error) {
+	switch v {
+	case floatNaN:
+		return math.NaN(), nil
+	case floatInfinity:
+		return math.Inf(1), nil
+	case floatNegInfinity:
+		return math.Inf(-1), nil
+	}
+
+	f, err := strconv.ParseFloat(string(v), bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %q as float, %w", string(v), err)
+	}
+	// only the spellings above are accepted for non-finite values
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("failed to parse %q as float", string(v))
+	}
+	return f, nil
+}
+
+// BigInteger parses v as a big.Int value
+func (v DecodedValue) BigInteger() (*big.Int, error) {
+	i, ok := new(big.Int).SetString(string(v), 10)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse %q as big integer", string(v))
+	}
+	return i, nil
+}
+
+// BigDecimal parses v as a big.Float value
+func (v DecodedValue) BigDecimal() (*big.Float, error) {
+	f, _, err := big.ParseFloat(string(v), 10, 0, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q as big decimal, %w", string(v), err)
+	}
+	return f, nil
+}
+
+// Blob parses v as a base64 encoded value
+func (v DecodedValue) Blob() ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(string(v))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q as base64, %w", string(v), err)
+	}
+	return b, nil
+}
+
+// Timestamp parses v as a timestamp in the format
+func (v DecodedValue) Timestamp(format TimestampFormat) (time.Time, error) {
+	t, err := parseTimestamp(string(v), format)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse %q as %v timestamp, %w", string(v), format, err)
+	}
+	return t, nil
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 const (
@@ -15,6 +17,84 @@ const (
 	floatNegInfinity    = "-Infinity"
 )
 
+// maxPooledBufferSize is the largest path buffer capacity that PutEncoder
+// will retain, so that a single long path does not pin memory indefinitely.
+const maxPooledBufferSize = 8 * 1024
+
+var encoderPool = sync.Pool{
+	New: func() interface{} {
+		return &Encoder{
+			template: &uriTemplate{},
+			query:    url.Values{},
+		}
+	},
+}
+
+// QuerySpaceEncoding is how spaces in query keys and values are encoded, see
+// EncoderOptions.QuerySpaceEncoding.
+type QuerySpaceEncoding int
+
+// Enumeration values for QuerySpaceEncoding
+const (
+	// Spaces are encoded as "+", as by url.Values.Encode.
+	QuerySpacePlus QuerySpaceEncoding = iota
+
+	// Spaces are encoded as "%20", per RFC 3986.
+	QuerySpacePercent
+)
+
+// QueryKeyOrder is the order in which query keys are encoded, see
+// EncoderOptions.QueryKeyOrder.
+type QueryKeyOrder int
+
+// Enumeration values for QueryKeyOrder
+const (
+	// Keys are sorted, as by url.Values.Encode, such that the encoded query
+	// is deterministic, e.g. for signing.
+	QueryKeyOrderSorted QueryKeyOrder = iota
+
+	// Keys are in the order they are first added, those of the encoder's
+	// existing query first.
+	QueryKeyOrderInsertion
+)
+
+// QueryListStyle is how the values of a query key with more than one value
+// are encoded, see EncoderOptions.QueryListStyle.
+type QueryListStyle int
+
+// Enumeration values for QueryListStyle
+const (
+	// The key is repeated for each value, e.g. "key=a&key=b".
+	QueryListRepeated QueryListStyle = iota
+
+	// The key is encoded once with its values joined by commas, e.g.
+	// "key=a,b". Commas within values are escaped.
+	QueryListCommaJoined
+)
+
+// EncoderOptions is the set of options that can be configured for an
+// Encoder.
+type EncoderOptions struct {
+	// How spaces in query keys and values are encoded. Defaults to
+	// QuerySpacePlus.
+	QuerySpaceEncoding QuerySpaceEncoding
+
+	// The order in which query keys are encoded. Defaults to
+	// QueryKeyOrderSorted.
+	QueryKeyOrder QueryKeyOrder
+
+	// How the values of a query key with more than one value are encoded.
+	// Defaults to QueryListRepeated.
+	QueryListStyle QueryListStyle
+
+	// Validates the request when it is encoded, such that header values
+	// containing CR, LF, or other control characters, invalid header names,
+	// query keys with reserved characters, empty label values, and greedy
+	// label values with empty or dot path segments are rejected by Encode
+	// with a descriptive error, rather than by the server. Defaults to false.
+	StrictValidation bool
+}
+
 // An Encoder provides encoding of REST URI path, query, and header components
 // of an HTTP request. Can also encode a stream as the payload.
 //
@@ -22,45 +102,168 @@ const (
 type Encoder struct {
 	path, rawPath, pathBuffer []byte
 
+	// template of the path, with the values of its labels
+	template *uriTemplate
+
 	query  url.Values
 	header http.Header
+
+	// query keys in the order they were first added
+	queryKeys []string
+
+	// query params of a map bound with httpQueryParams, which are added to
+	// the query when encoded unless their keys are bound explicitly
+	queryParams url.Values
+
+	// the header is the header of an encoded request, so is not reused
+	headerEncoded bool
+
+	options EncoderOptions
 }
 
 // NewEncoder creates a new encoder from the passed in request. It assumes that
 // raw path contains no valuable information at this point, so it passes in path
 // as path and raw path for subsequent trans
-func NewEncoder(path, query string, headers http.Header) (*Encoder, error) {
-	return NewEncoderWithRawPath(path, path, query, headers)
+func NewEncoder(path, query string, headers http.Header, optFns ...func(*EncoderOptions)) (*Encoder, error) {
+	return NewEncoderWithRawPath(path, path, query, headers, optFns...)
+}
+
+// NewEncoderFromURI creates a new encoder from a Smithy HTTP binding trait
+// URI, e.g. "/{Bucket}?uploads" or "/{Bucket}/{Key+}?x-id=GetObject", whose
+// path is the URI path template, and whose query literals are encoded in the
+// query of the request.
+func NewEncoderFromURI(uri string, headers http.Header, optFns ...func(*EncoderOptions)) (*Encoder, error) {
+	path, query := SplitURI(uri)
+	return NewEncoderWithRawPath(path, path, query, headers, optFns...)
 }
 
 // NewHTTPBindingEncoder creates a new encoder from the passed in request. All query and
 // header values will be added on top of the request's existing values. Overwriting
 // duplicate values.
-func NewEncoderWithRawPath(path, rawPath, query string, headers http.Header) (*Encoder, error) {
-	parseQuery, err := url.ParseQuery(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse query string: %w", err)
+//
+// The path is a URI path template, e.g. "/{Bucket}/{Key+}", whose labels are
+// validated, returning a URITemplateError if the template is malformed.
+func NewEncoderWithRawPath(path, rawPath, query string, headers http.Header, optFns ...func(*EncoderOptions)) (*Encoder, error) {
+	var o EncoderOptions
+	for _, fn := range optFns {
+		fn(&o)
 	}
 
 	e := &Encoder{
-		path:    []byte(path),
-		rawPath: []byte(rawPath),
-		query:   parseQuery,
-		header:  headers.Clone(),
+		template: &uriTemplate{},
+		options:  o,
+	}
+	if err := e.Reset(path, rawPath, query, headers); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Reset resets the encoder to encode a request with the path, raw path,
+// query, and headers, as NewEncoderWithRawPath does, keeping the encoder's
+// options. The encoder's buffers are reused, other than the header of a
+// request previously encoded, which the request retains.
+//
+// Returns an error if the query or path template is malformed, in which case
+// the encoder must be reset again before it is used.
+func (e *Encoder) Reset(path, rawPath, query string, headers http.Header) error {
+	if len(query) == 0 && e.query != nil {
+		for k := range e.query {
+			delete(e.query, k)
+		}
+	} else {
+		parseQuery, err := url.ParseQuery(query)
+		if err != nil {
+			return fmt.Errorf("failed to parse query string: %w", err)
+		}
+		e.query = parseQuery
+	}
+
+	if err := e.template.parse(path); err != nil {
+		return err
 	}
 
+	e.path = append(e.path[:0], path...)
+	e.rawPath = append(e.rawPath[:0], rawPath...)
+
+	if e.header == nil || e.headerEncoded {
+		if e.header = headers.Clone(); e.header == nil {
+			e.header = http.Header{}
+		}
+	} else {
+		for k := range e.header {
+			delete(e.header, k)
+		}
+		for k, vs := range headers {
+			e.header[k] = append([]string(nil), vs...)
+		}
+	}
+	e.headerEncoded = false
+
+	for k := range e.queryParams {
+		delete(e.queryParams, k)
+	}
+	e.queryKeys = e.queryKeys[:0]
+	if e.options.QueryKeyOrder == QueryKeyOrderInsertion {
+		e.queryKeys = append(e.queryKeys, queryKeyOrder(query)...)
+	}
+
+	return nil
+}
+
+// GetEncoder returns an Encoder from a pool, reset to encode a request with
+// the path, raw path, query, and headers, as if by NewEncoderWithRawPath with
+// the default options. The encoder should be returned to the pool with
+// PutEncoder once the request is encoded.
+func GetEncoder(path, rawPath, query string, headers http.Header) (*Encoder, error) {
+	e := encoderPool.Get().(*Encoder)
+	if err := e.Reset(path, rawPath, query, headers); err != nil {
+		encoderPool.Put(e)
+		return nil, err
+	}
 	return e, nil
 }
 
+// PutEncoder returns e to the pool used by GetEncoder. Neither e nor the
+// values returned by its methods may be used after calling PutEncoder. The
+// request encoded by e is not affected. Encoders configured with options are
+// not pooled.
+func PutEncoder(e *Encoder) {
+	if e.options != (EncoderOptions{}) || cap(e.pathBuffer) > maxPooledBufferSize {
+		return
+	}
+	encoderPool.Put(e)
+}
+
 // Encode returns a REST protocol encoder for encoding HTTP bindings.
 //
 // Due net/http requiring `Content-Length` to be specified on the http.Request#ContentLength directly. Encode
 // will look for whether the header is present, and if so will remove it and set the respective value on http.Request.
 //
-// Returns any error occurring during encoding.
+// Returns any error occurring during encoding, including a URITemplateError if
+// the value of a label of the path was not set. With
+// EncoderOptions.StrictValidation, returns a ValidationError or
+// URITemplateError if a header, query key, or label value is invalid.
 func (e *Encoder) Encode(req *http.Request) (*http.Request, error) {
+	e.addQueryParams()
+
+	if e.options.StrictValidation {
+		if err := e.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	path, rawPath, buffer, err := e.template.expand(e.path, e.rawPath, e.pathBuffer)
+	if err != nil {
+		return nil, err
+	}
+	e.path, e.rawPath, e.pathBuffer = path, rawPath, buffer
+	// the labels are replaced, such that encoding again does not replace them
+	e.template.labels = e.template.labels[:0]
+
 	req.URL.Path, req.URL.RawPath = string(e.path), string(e.rawPath)
-	req.URL.RawQuery = e.query.Encode()
+	req.URL.RawQuery = e.encodeQuery()
 
 	// net/http ignores Content-Length header and requires it to be set on http.Request
 	if v := e.header.Get(contentLengthHeader); len(v) > 0 {
@@ -73,6 +276,7 @@ func (e *Encoder) Encode(req *http.Request) (*http.Request, error) {
 	}
 
 	req.Header = e.header
+	e.headerEncoded = true
 
 	return req, nil
 }
@@ -87,6 +291,12 @@ func (e *Encoder) SetHeader(key string) HeaderValue {
 	return newHeaderValue(e.header, key, false)
 }
 
+// HeaderList returns a HeaderList for appending the members of a list to the
+// given header name, in the given style
+func (e *Encoder) HeaderList(key string, style HeaderListStyle) HeaderList {
+	return HeaderList{header: e.header, key: strings.TrimSpace(key), style: style}
+}
+
 // Headers returns a Header used for encoding headers with the given prefix
 func (e *Encoder) Headers(prefix string) Headers {
 	return Headers{
@@ -101,21 +311,84 @@ func (e Encoder) HasHeader(key string) bool {
 	return len(e.header[key]) != 0
 }
 
-// SetURI returns a URIValue used for setting the given path key
+// SetURI returns a URIValue used for setting the given path key. The value
+// is written to the path by Encode. Setting a key that is not a label of the
+// path returns a URITemplateError.
 func (e *Encoder) SetURI(key string) URIValue {
-	return newURIValue(&e.path, &e.rawPath, &e.pathBuffer, key)
+	v := newURIValue(&e.path, &e.rawPath, &e.pathBuffer, key)
+	v.template = e.template
+	return v
 }
 
 // SetQuery returns a QueryValue used for setting the given query key
 func (e *Encoder) SetQuery(key string) QueryValue {
+	e.addQueryKey(key)
 	return NewQueryValue(e.query, key, false)
 }
 
 // AddQuery returns a QueryValue used for appending the given query key
 func (e *Encoder) AddQuery(key string) QueryValue {
+	e.addQueryKey(key)
 	return NewQueryValue(e.query, key, true)
 }
 
+// AddQueryParam returns a QueryValue used for appending the given query key
+// as a member of a map bound to the query with httpQueryParams. The key is
+// encoded only if it is not bound explicitly, with SetQuery or AddQuery, or
+// by a query literal, whether before or after the param is added, since
+// explicit bindings take precedence.
+func (e *Encoder) AddQueryParam(key string) QueryValue {
+	if e.queryParams == nil {
+		e.queryParams = url.Values{}
+	}
+	return NewQueryValue(e.queryParams, key, true)
+}
+
+// AddQueryParams appends the values of the map bound to the query with
+// httpQueryParams, as by AddQueryParam. Keys are added in sorted order, such
+// that their order is deterministic with QueryKeyOrderInsertion.
+func (e *Encoder) AddQueryParams(params map[string][]string) {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range params[k] {
+			e.AddQueryParam(k).String(v)
+		}
+	}
+}
+
+// addQueryParams adds the query params whose keys are not bound explicitly
+// to the query.
+func (e *Encoder) addQueryParams() {
+	if len(e.queryParams) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(e.queryParams))
+	for k := range e.queryParams {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, ok := e.query[k]; !ok {
+			e.query[k] = e.queryParams[k]
+			e.addQueryKey(k)
+		}
+		delete(e.queryParams, k)
+	}
+}
+
+func (e *Encoder) addQueryKey(key string) {
+	if e.options.QueryKeyOrder == QueryKeyOrderInsertion {
+		e.queryKeys = appendKey(e.queryKeys, key)
+	}
+}
+
 // HasQuery returns if a query with the key specified exists with one or
 // more values.
 func (e *Encoder) HasQuery(key string) bool {
@@ -0,0 +1,51 @@
+//go:build !race
+// +build !race
+
+// The race detector randomly drops items put into a sync.Pool, so allocation
+// counts are only meaningful without it.
+
+package httpbinding
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestEncoder_ResetAllocations(t *testing.T) {
+	req := &http.Request{URL: &url.URL{}}
+	encode := func(e *Encoder) {
+		e.SetURI("Bucket").String("bucket")
+		e.SetURI("Key").String("a/b c")
+		e.SetQuery("versionId").String("1")
+		e.SetHeader("X-Amz-Meta").String("v")
+		if _, err := e.Encode(req); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	created := testing.AllocsPerRun(10, func() {
+		e, err := NewEncoder("/{Bucket}/{Key+}", "", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		encode(e)
+	})
+
+	// warm the pool
+	if e, err := GetEncoder("/", "/", "", nil); err == nil {
+		PutEncoder(e)
+	}
+	pooled := testing.AllocsPerRun(10, func() {
+		e, err := GetEncoder("/{Bucket}/{Key+}", "/{Bucket}/{Key+}", "", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		encode(e)
+		PutEncoder(e)
+	})
+
+	if pooled >= created {
+		t.Errorf("expected fewer allocations than %v with a pooled encoder, got %v", created, pooled)
+	}
+}
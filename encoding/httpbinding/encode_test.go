@@ -1,6 +1,7 @@
 package httpbinding
 
 import (
+	"errors"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -144,3 +145,244 @@ func TestEncodeContentLength(t *testing.T) {
 		})
 	}
 }
+
+func TestEncoder_QueryOptions(t *testing.T) {
+	cases := map[string]struct {
+		optFns   []func(*EncoderOptions)
+		expected string
+	}{
+		"default": {
+			expected: "a+key=x+y&b=1&b=2&existing=e&list=a%2Cb&list=c",
+		},
+		"percent spaces": {
+			optFns: []func(*EncoderOptions){func(o *EncoderOptions) {
+				o.QuerySpaceEncoding = QuerySpacePercent
+			}},
+			expected: "a%20key=x%20y&b=1&b=2&existing=e&list=a%2Cb&list=c",
+		},
+		"insertion order": {
+			optFns: []func(*EncoderOptions){func(o *EncoderOptions) {
+				o.QueryKeyOrder = QueryKeyOrderInsertion
+			}},
+			expected: "existing=e&list=a%2Cb&list=c&b=1&b=2&a+key=x+y",
+		},
+		"comma joined lists": {
+			optFns: []func(*EncoderOptions){func(o *EncoderOptions) {
+				o.QueryListStyle = QueryListCommaJoined
+			}},
+			expected: "a+key=x+y&b=1,2&existing=e&list=a%2Cb,c",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder, err := NewEncoder("/", "existing=e", http.Header{}, c.optFns...)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			encoder.AddQuery("list").String("a,b")
+			encoder.AddQuery("list").String("c")
+			encoder.AddQuery("b").Integer(1)
+			encoder.AddQuery("b").Integer(2)
+			encoder.SetQuery("a key").String("x y")
+			encoder.SetQuery("unset")
+
+			req, err := encoder.Encode(&http.Request{URL: &url.URL{}})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if e, a := c.expected, req.URL.RawQuery; e != a {
+				t.Errorf("expected %v, got %v", e, a)
+			}
+
+			// the encoded query decodes as the values set
+			q, err := url.ParseQuery(req.URL.RawQuery)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if e, a := "x y", q.Get("a key"); e != a {
+				t.Errorf("expected %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestEncoder_Reset(t *testing.T) {
+	encode := func(e *Encoder, bucket string) *http.Request {
+		if err := e.SetURI("Bucket").String(bucket); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		e.SetQuery("list-type").Integer(2)
+		e.SetHeader("x-amz-bucket").String(bucket)
+
+		req, err := e.Encode(&http.Request{URL: &url.URL{}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return req
+	}
+
+	encoder, err := NewEncoder("/{Bucket}", "a=1", http.Header{"X-Existing": {"first"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	first := encode(encoder, "first")
+
+	if err := encoder.Reset("/{Bucket}/list", "/{Bucket}/list", "", http.Header{"X-Existing": {"second"}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	second := encode(encoder, "second bucket")
+
+	// the first request is not modified by encoding the second
+	if e, a := "/first", first.URL.Path; e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := "a=1&list-type=2", first.URL.RawQuery; e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := (http.Header{"X-Existing": {"first"}, "x-amz-bucket": {"first"}}), first.Header; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	expected, err := NewEncoderWithRawPath("/{Bucket}/list", "/{Bucket}/list", "", http.Header{"X-Existing": {"second"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if e, a := encode(expected, "second bucket"), second; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	// the header is reused if no request was encoded
+	if err := encoder.Reset("/{Bucket}", "/{Bucket}", "", http.Header{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := encoder.Reset("/{Key}", "/{Key}", "b=2", http.Header{"X-Existing": {"third"}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if encoder.HasHeader("x-amz-bucket") || !encoder.HasQuery("b") || encoder.HasQuery("list-type") {
+		t.Errorf("expected only the reset header and query, got %v, %v", encoder.header, encoder.query)
+	}
+	if err := encoder.SetURI("Bucket").String("b"); !errors.Is(err, ErrUnknownLabel) {
+		t.Errorf("expected %v, got %v", ErrUnknownLabel, err)
+	}
+
+	if err := encoder.Reset("/{Bucket", "/{Bucket", "", nil); !errors.Is(err, ErrMalformedLabel) {
+		t.Errorf("expected %v, got %v", ErrMalformedLabel, err)
+	}
+}
+
+func TestGetEncoder(t *testing.T) {
+	encoder, err := GetEncoder("/{Key+}", "/{Key+}", "", http.Header{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := encoder.SetURI("Key").String("a/b c"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	encoder.SetHeader("x-amz-key").String("v")
+	req, err := encoder.Encode(&http.Request{URL: &url.URL{}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	PutEncoder(encoder)
+
+	// the encoded request is not modified by reusing the encoder
+	next, err := GetEncoder("/other", "/other", "", http.Header{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	next.SetHeader("x-amz-key").String("other")
+	if _, err := next.Encode(&http.Request{URL: &url.URL{}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	PutEncoder(next)
+
+	if e, a := "/a/b%20c", req.URL.RawPath; e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := "v", req.Header["x-amz-key"][0]; e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	if _, err := GetEncoder("/{Key", "/{Key", "", http.Header{}); !errors.Is(err, ErrMalformedLabel) {
+		t.Errorf("expected %v, got %v", ErrMalformedLabel, err)
+	}
+}
+
+func TestNewEncoderFromURI(t *testing.T) {
+	encoder, err := NewEncoderFromURI("/{Bucket}?uploads&x-id=CreateMultipartUpload", http.Header{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := encoder.SetURI("Bucket").String("bucket"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	encoder.SetQuery("prefix").String("a b")
+
+	req, err := encoder.Encode(&http.Request{URL: &url.URL{}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if e, a := "/bucket", req.URL.Path; e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := "prefix=a+b&uploads=&x-id=CreateMultipartUpload", req.URL.RawQuery; e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	if _, err := NewEncoderFromURI("/{Bucket?x-id=1", http.Header{}); !errors.Is(err, ErrMalformedLabel) {
+		t.Errorf("expected %v, got %v", ErrMalformedLabel, err)
+	}
+}
+
+func TestEncoder_QueryParams(t *testing.T) {
+	cases := map[string]struct {
+		optFns   []func(*EncoderOptions)
+		expected string
+	}{
+		"default": {
+			expected: "a=1&b=2&b=3&explicit=e&literal=l&unset=u&z=4",
+		},
+		"insertion order": {
+			optFns: []func(*EncoderOptions){func(o *EncoderOptions) {
+				o.QueryKeyOrder = QueryKeyOrderInsertion
+			}},
+			expected: "literal=l&explicit=e&unset=u&a=1&b=2&b=3&z=4",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder, err := NewEncoderFromURI("/?literal=l", http.Header{}, c.optFns...)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			encoder.AddQueryParams(map[string][]string{
+				"literal":  {"ignored"},
+				"explicit": {"ignored"},
+				"b":        {"2", "3"},
+				"a":        {"1"},
+			})
+			encoder.AddQueryParam("z").Integer(4)
+			// explicit bindings take precedence, even when set after the params
+			encoder.SetQuery("explicit").String("e")
+			// a key bound explicitly without a value is not a collision
+			encoder.SetQuery("unset")
+			encoder.AddQueryParam("unset").String("u")
+
+			if encoder.HasQuery("a") {
+				t.Errorf("expected params not in the query until encoded")
+			}
+
+			req, err := encoder.Encode(&http.Request{URL: &url.URL{}})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if e, a := c.expected, req.URL.RawQuery; e != a {
+				t.Errorf("expected %v, got %v", e, a)
+			}
+		})
+	}
+}
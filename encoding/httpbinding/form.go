@@ -0,0 +1,70 @@
+package httpbinding
+
+import (
+	"net/url"
+)
+
+// FormContentType is the media type of a form body, see FormEncoder.
+const FormContentType = "application/x-www-form-urlencoded"
+
+// A FormEncoder provides encoding of an application/x-www-form-urlencoded
+// request body, e.g. of query protocol services or OAuth token endpoints,
+// from a set of keys and values. Values are encoded as by a QueryValue.
+//
+// The body is encoded per the query options of EncoderOptions: by default
+// keys are sorted, spaces are encoded as "+", and other characters as by
+// url.QueryEscape, and each value of a key is a key=value pair of its own.
+type FormEncoder struct {
+	values url.Values
+
+	// keys in the order they were first added
+	keys []string
+
+	options EncoderOptions
+}
+
+// NewFormEncoder returns an empty FormEncoder.
+func NewFormEncoder(optFns ...func(*EncoderOptions)) *FormEncoder {
+	var o EncoderOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	return &FormEncoder{
+		values:  url.Values{},
+		options: o,
+	}
+}
+
+// SetValue returns a QueryValue used for setting the given key
+func (e *FormEncoder) SetValue(key string) QueryValue {
+	e.addKey(key)
+	return NewQueryValue(e.values, key, false)
+}
+
+// AddValue returns a QueryValue used for appending the given key
+func (e *FormEncoder) AddValue(key string) QueryValue {
+	e.addKey(key)
+	return NewQueryValue(e.values, key, true)
+}
+
+// HasValue returns if a value with the key specified exists.
+func (e *FormEncoder) HasValue(key string) bool {
+	return len(e.values[key]) != 0
+}
+
+func (e *FormEncoder) addKey(key string) {
+	if e.options.QueryKeyOrder == QueryKeyOrderInsertion {
+		e.keys = appendKey(e.keys, key)
+	}
+}
+
+// String returns the encoded form body, e.g. "a=1&b=x+y"
+func (e *FormEncoder) String() string {
+	return encodeValues(e.values, e.keys, e.options)
+}
+
+// Bytes returns the encoded form body
+func (e *FormEncoder) Bytes() []byte {
+	return []byte(e.String())
+}
@@ -0,0 +1,56 @@
+package httpbinding
+
+import (
+	"math"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestFormEncoder(t *testing.T) {
+	cases := map[string]struct {
+		optFns   []func(*EncoderOptions)
+		expected string
+	}{
+		"default": {
+			expected: "Action=GetToken&grant_type=client_credentials&nan=NaN&scope=a+b%2Fc&scope=%26d%3D",
+		},
+		"insertion order with percent spaces": {
+			optFns: []func(*EncoderOptions){func(o *EncoderOptions) {
+				o.QueryKeyOrder = QueryKeyOrderInsertion
+				o.QuerySpaceEncoding = QuerySpacePercent
+			}},
+			expected: "grant_type=client_credentials&scope=a%20b%2Fc&scope=%26d%3D&Action=GetToken&nan=NaN",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := NewFormEncoder(c.optFns...)
+			e.SetValue("grant_type").String("client_credentials")
+			e.AddValue("scope").String("a b/c")
+			e.AddValue("scope").String("&d=")
+			e.SetValue("Action").String("GetToken")
+			e.SetValue("nan").Double(math.NaN())
+			e.SetValue("unset")
+
+			if e, a := c.expected, e.String(); e != a {
+				t.Errorf("expected %v, got %v", e, a)
+			}
+			if e, a := c.expected, string(e.Bytes()); e != a {
+				t.Errorf("expected %v, got %v", e, a)
+			}
+			if !e.HasValue("scope") || e.HasValue("unset") {
+				t.Errorf("expected only scope to have a value")
+			}
+
+			values, err := url.ParseQuery(e.String())
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if e, a := []string{"a b/c", "&d="}, values["scope"]; !reflect.DeepEqual(e, a) {
+				t.Errorf("expected %v, got %v", e, a)
+			}
+		})
+	}
+}
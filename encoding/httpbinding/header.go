@@ -7,6 +7,9 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+
+	smithytime "github.com/aws/smithy-go/time"
 )
 
 // Headers is used to encode header keys using a provided prefix
@@ -29,11 +32,45 @@ func (h Headers) newHeaderValue(key string, append bool) HeaderValue {
 	return newHeaderValue(h.header, h.prefix+strings.TrimSpace(key), append)
 }
 
+// HeaderListStyle is how the members of a list bound to a header are
+// encoded, see Encoder.HeaderList.
+type HeaderListStyle int
+
+// Enumeration values for HeaderListStyle
+const (
+	// Each member is encoded as a header line of its own, e.g.
+	// "Key: a" and "Key: b".
+	HeaderListRepeated HeaderListStyle = iota
+
+	// The members are encoded as a single header line, joined by commas,
+	// e.g. "Key: a, b". A string member containing a comma or double quote,
+	// or with surrounding whitespace, is double quoted, with double quotes
+	// and backslashes within it escaped by backslashes, e.g. `"a,b"`.
+	HeaderListCommaJoined
+)
+
+// HeaderList is used to encode the members of a list to an HTTP header
+type HeaderList struct {
+	header http.Header
+	key    string
+	style  HeaderListStyle
+}
+
+// Member returns a HeaderValue used to append a member to the list
+func (l HeaderList) Member() HeaderValue {
+	v := newHeaderValue(l.header, l.key, true)
+	v.commaJoined = l.style == HeaderListCommaJoined
+	return v
+}
+
 // HeaderValue is used to encode values to an HTTP header
 type HeaderValue struct {
 	header http.Header
 	key    string
 	append bool
+
+	// append to the first header line, as a member of a comma joined list
+	commaJoined bool
 }
 
 func newHeaderValue(header http.Header, key string, append bool) HeaderValue {
@@ -41,6 +78,24 @@ func newHeaderValue(header http.Header, key string, append bool) HeaderValue {
 }
 
 func (h HeaderValue) modifyHeader(value string) {
+	h.modifyHeaderMember(value, true)
+}
+
+// modifyHeaderMember modifies the header with the value, quoting it as a
+// member of a comma joined list if it needs quoting and quote is set.
+func (h HeaderValue) modifyHeaderMember(value string, quote bool) {
+	if h.commaJoined {
+		if quote {
+			value = quoteHeaderListMember(value)
+		}
+		if vs := h.header[h.key]; len(vs) != 0 {
+			vs[0] += ", " + value
+			return
+		}
+		h.header[h.key] = []string{value}
+		return
+	}
+
 	if h.append {
 		h.header[h.key] = append(h.header[h.key], value)
 	} else {
@@ -53,6 +108,19 @@ func (h HeaderValue) String(v string) {
 	h.modifyHeader(v)
 }
 
+// HTTPDate encodes the value v as an HTTP-date header string value, e.g.
+// "Tue, 29 Apr 2014 18:30:38 GMT". The value is not quoted as a member of a
+// comma joined list, since its commas are part of the format.
+func (h HeaderValue) HTTPDate(v time.Time) {
+	h.modifyHeaderMember(smithytime.FormatHTTPDate(v), false)
+}
+
+// Timestamp encodes the value v as a header string value in the format. As
+// with HTTPDate, the value is not quoted as a member of a comma joined list.
+func (h HeaderValue) Timestamp(v time.Time, format TimestampFormat) {
+	h.modifyHeaderMember(formatTimestamp(v, format), false)
+}
+
 // Byte encodes the value v as a query string value
 func (h HeaderValue) Byte(v int8) {
 	h.Long(int64(v))
@@ -101,12 +169,13 @@ func (h HeaderValue) float(v float64, bitSize int) {
 	}
 }
 
-// BigInteger encodes the value v as a query string value
+// BigInteger encodes the value v as a header string value
 func (h HeaderValue) BigInteger(v *big.Int) {
 	h.modifyHeader(v.String())
 }
 
-// BigDecimal encodes the value v as a query string value
+// BigDecimal encodes the value v as a header string value, in exponent
+// notation unless it is an integer, e.g. "1.5e+00"
 func (h HeaderValue) BigDecimal(v *big.Float) {
 	if i, accuracy := v.Int64(); accuracy == big.Exact {
 		h.Long(i)
@@ -115,8 +184,30 @@ func (h HeaderValue) BigDecimal(v *big.Float) {
 	h.modifyHeader(v.Text('e', -1))
 }
 
-// Blob encodes the value v as a base64 header string value
+// Blob encodes the value v as a base64 header string value. The base64
+// alphabet has no characters that need quoting as a member of a comma joined
+// list.
 func (h HeaderValue) Blob(v []byte) {
-	encodeToString := base64.StdEncoding.EncodeToString(v)
-	h.modifyHeader(encodeToString)
+	h.modifyHeaderMember(base64.StdEncoding.EncodeToString(v), false)
+}
+
+// quoteHeaderListMember returns the value double quoted if it contains a
+// comma or double quote, or has surrounding whitespace, such that it is split
+// as a single member of a comma joined list.
+func quoteHeaderListMember(v string) string {
+	if !strings.ContainsAny(v, `,"`) && strings.TrimSpace(v) == v {
+		return v
+	}
+
+	var b strings.Builder
+	b.Grow(len(v) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(v); i++ {
+		if v[i] == '"' || v[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(v[i])
+	}
+	b.WriteByte('"')
+	return b.String()
 }
@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
+
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
 func TestHeaderValue(t *testing.T) {
@@ -293,3 +296,97 @@ func setHeader(hv HeaderValue, args []interface{}) error {
 		return fmt.Errorf("unhandled header value type")
 	}
 }
+
+func TestHeaderList(t *testing.T) {
+	date := time.Date(2014, 4, 29, 18, 30, 38, 0, time.UTC)
+	members := []string{"a", "b,c", `say "hi"`, ` padded `, `back\slash`, ""}
+
+	cases := map[string]struct {
+		style    HeaderListStyle
+		encode   func(HeaderList)
+		expected []string
+		split    []string
+	}{
+		"repeated": {
+			style: HeaderListRepeated,
+			encode: func(l HeaderList) {
+				for _, m := range members {
+					l.Member().String(m)
+				}
+			},
+			expected: members,
+		},
+		"comma joined": {
+			style: HeaderListCommaJoined,
+			encode: func(l HeaderList) {
+				for _, m := range members {
+					l.Member().String(m)
+				}
+			},
+			expected: []string{`a, "b,c", "say \"hi\"", " padded ", back\slash, `},
+			split:    members,
+		},
+		"comma joined typed": {
+			style: HeaderListCommaJoined,
+			encode: func(l HeaderList) {
+				l.Member().Integer(1)
+				l.Member().Boolean(true)
+				l.Member().Double(1.5)
+			},
+			expected: []string{"1, true, 1.5"},
+			split:    []string{"1", "true", "1.5"},
+		},
+		"comma joined http dates": {
+			style: HeaderListCommaJoined,
+			encode: func(l HeaderList) {
+				l.Member().HTTPDate(date)
+				l.Member().HTTPDate(date)
+			},
+			expected: []string{"Tue, 29 Apr 2014 18:30:38 GMT, Tue, 29 Apr 2014 18:30:38 GMT"},
+		},
+		"comma joined timestamps": {
+			style: HeaderListCommaJoined,
+			encode: func(l HeaderList) {
+				l.Member().Timestamp(date, TimestampHTTPDate)
+				l.Member().Timestamp(date.Add(123*time.Millisecond), TimestampDateTime)
+				l.Member().Timestamp(date.Add(123*time.Millisecond), TimestampEpochSeconds)
+			},
+			expected: []string{"Tue, 29 Apr 2014 18:30:38 GMT, 2014-04-29T18:30:38.123Z, 1398796238.123"},
+		},
+		"comma joined big numbers and blobs": {
+			style: HeaderListCommaJoined,
+			encode: func(l HeaderList) {
+				l.Member().BigDecimal(big.NewFloat(1.5))
+				l.Member().BigInteger(new(big.Int).Lsh(big.NewInt(1), 64))
+				l.Member().Blob([]byte("foo bar?"))
+			},
+			expected: []string{"1.5e+00, 18446744073709551616, Zm9vIGJhcj8="},
+			split:    []string{"1.5e+00", "18446744073709551616", "Zm9vIGJhcj8="},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder, err := NewEncoder("/", "", http.Header{})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			c.encode(encoder.HeaderList(" X-List ", c.style))
+
+			if e, a := c.expected, encoder.header["X-List"]; !reflect.DeepEqual(e, a) {
+				t.Errorf("expected %q, got %q", e, a)
+			}
+
+			if c.split == nil {
+				return
+			}
+			split, err := smithyhttp.SplitHeaderListValues(encoder.header["X-List"])
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if e, a := c.split, split; !reflect.DeepEqual(e, a) {
+				t.Errorf("expected %q, got %q", e, a)
+			}
+		})
+	}
+}
@@ -26,7 +26,7 @@ func replacePathElement(path, fieldBuf []byte, key, val string, escape bool) ([]
 	fieldBuf = append(fieldBuf, uriTokenStart)
 	fieldBuf = append(fieldBuf, key...)
 
-	start := bytes.Index(path, fieldBuf)
+	start := indexPathElement(path, fieldBuf)
 	end := start + len(fieldBuf)
 	if start < 0 || len(path[end:]) == 0 {
 		// TODO what to do about error?
@@ -78,6 +78,31 @@ func replacePathElement(path, fieldBuf []byte, key, val string, escape bool) ([]
 	return path, fieldBuf, nil
 }
 
+// indexPathElement returns the index of the first element of the path that
+// starts with the field, e.g. "{key", and ends the label with a token stop,
+// such that "{key}" is not matched by the field of "{ke". Returns the index of
+// the first occurrence of field if there is no such element, or -1.
+func indexPathElement(path, field []byte) int {
+	first := bytes.Index(path, field)
+	for i := first; i >= 0; {
+		end := i + len(field)
+		rest := path[end:]
+		if len(rest) != 0 && rest[0] == uriTokenSkip {
+			rest = rest[1:]
+		}
+		if len(rest) != 0 && rest[0] == uriTokenStop {
+			return i
+		}
+
+		next := bytes.Index(path[end:], field)
+		if next < 0 {
+			break
+		}
+		i = end + next
+	}
+	return first
+}
+
 // EscapePath escapes part of a URL path in Amazon style.
 func EscapePath(path string, encodeSep bool) string {
 	var buf bytes.Buffer
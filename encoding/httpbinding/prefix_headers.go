@@ -0,0 +1,120 @@
+package httpbinding
+
+import (
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// PrefixHeaderKeyCase is the case of the keys of prefix headers, see
+// PrefixHeaderOptions.KeyCase.
+type PrefixHeaderKeyCase int
+
+// Enumeration values for PrefixHeaderKeyCase
+const (
+	// Keys are the remainder of the canonical header names, e.g. "Foo-Bar"
+	// for "x-amz-meta-foo-bar" with prefix "X-Amz-Meta-".
+	PrefixHeaderKeyCanonical PrefixHeaderKeyCase = iota
+
+	// Keys are the remainder of the header names in lower case, e.g.
+	// "foo-bar" for "X-Amz-Meta-Foo-Bar" with prefix "X-Amz-Meta-".
+	PrefixHeaderKeyLower
+)
+
+// PrefixHeaderDuplicates is how a prefix header with more than one value is
+// decoded, see PrefixHeaderOptions.Duplicates.
+type PrefixHeaderDuplicates int
+
+// Enumeration values for PrefixHeaderDuplicates
+const (
+	// The first value of the header is decoded.
+	PrefixHeaderFirstValue PrefixHeaderDuplicates = iota
+
+	// The values of the header are decoded joined by ", ", as a single
+	// header line of a comma joined list.
+	PrefixHeaderJoinValues
+)
+
+// PrefixHeaderOptions is the set of options that can be configured for
+// decoding prefix headers, see PrefixHeaders.
+type PrefixHeaderOptions struct {
+	// The case of the keys of the decoded headers. Defaults to
+	// PrefixHeaderKeyCanonical.
+	KeyCase PrefixHeaderKeyCase
+
+	// How a header with more than one value is decoded. Defaults to
+	// PrefixHeaderFirstValue.
+	Duplicates PrefixHeaderDuplicates
+}
+
+// RangePrefixHeaders calls fn with the name and values of each header whose
+// name starts with the given prefix, compared case-insensitively, in sorted
+// order of canonical name, until fn returns false. name is the remainder of
+// the canonical header name, e.g. "Foo" for "x-amz-meta-foo" with prefix
+// "X-Amz-Meta-". Values have surrounding whitespace trimmed.
+//
+// Header names that differ only in case, e.g. of a header that was not set
+// with its canonical name, are the same header, whose values are those of
+// each name in sorted order.
+func RangePrefixHeaders(header http.Header, prefix string, fn func(name string, values []string) bool) {
+	prefix = strings.TrimSpace(prefix)
+
+	type headerName struct{ canonical, name string }
+	var names []headerName
+	for k, vs := range header {
+		if len(k) <= len(prefix) || !strings.EqualFold(k[:len(prefix)], prefix) || len(vs) == 0 {
+			continue
+		}
+		names = append(names, headerName{canonical: textproto.CanonicalMIMEHeaderKey(k), name: k})
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i].canonical != names[j].canonical {
+			return names[i].canonical < names[j].canonical
+		}
+		return names[i].name < names[j].name
+	})
+
+	for i := 0; i < len(names); {
+		canonical := names[i].canonical
+
+		var values []string
+		for ; i < len(names) && names[i].canonical == canonical; i++ {
+			for _, v := range header[names[i].name] {
+				values = append(values, strings.TrimSpace(v))
+			}
+		}
+
+		if !fn(canonical[len(prefix):], values) {
+			return
+		}
+	}
+}
+
+// PrefixHeaders returns the headers whose names start with the given prefix,
+// as by RangePrefixHeaders, keyed by the remainder of their names, e.g. the
+// metadata of an output member bound to "X-Amz-Meta-" prefix headers. Returns
+// nil if there are none.
+func PrefixHeaders(header http.Header, prefix string, optFns ...func(*PrefixHeaderOptions)) map[string]string {
+	var o PrefixHeaderOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	var m map[string]string
+	RangePrefixHeaders(header, prefix, func(name string, values []string) bool {
+		if m == nil {
+			m = map[string]string{}
+		}
+		if o.KeyCase == PrefixHeaderKeyLower {
+			name = strings.ToLower(name)
+		}
+		if o.Duplicates == PrefixHeaderJoinValues {
+			m[name] = strings.Join(values, ", ")
+		} else {
+			m[name] = values[0]
+		}
+		return true
+	})
+	return m
+}
@@ -0,0 +1,97 @@
+package httpbinding
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestPrefixHeaders(t *testing.T) {
+	header := http.Header{
+		"X-Amz-Meta-Foo":     {" bar "},
+		"x-amz-meta-foo":     {"baz"},
+		"X-Amz-Meta-Foo-Bar": {"a", "b"},
+		"X-Amz-Meta-":        {"empty name"},
+		"X-Amz-Meta-Empty":   {},
+		"X-Amz-Request-Id":   {"abc"},
+		"Content-Type":       {"text/plain"},
+	}
+
+	cases := map[string]struct {
+		header   http.Header
+		prefix   string
+		optFns   []func(*PrefixHeaderOptions)
+		expected map[string]string
+	}{
+		"default": {
+			header: header,
+			prefix: " x-amz-meta- ",
+			expected: map[string]string{
+				"Foo":     "bar",
+				"Foo-Bar": "a",
+			},
+		},
+		"lower keys": {
+			header: header,
+			prefix: "X-Amz-Meta-",
+			optFns: []func(*PrefixHeaderOptions){func(o *PrefixHeaderOptions) {
+				o.KeyCase = PrefixHeaderKeyLower
+			}},
+			expected: map[string]string{
+				"foo":     "bar",
+				"foo-bar": "a",
+			},
+		},
+		"join values": {
+			header: header,
+			prefix: "X-Amz-Meta-",
+			optFns: []func(*PrefixHeaderOptions){func(o *PrefixHeaderOptions) {
+				o.Duplicates = PrefixHeaderJoinValues
+			}},
+			expected: map[string]string{
+				"Foo":     "bar, baz",
+				"Foo-Bar": "a, b",
+			},
+		},
+		"no match": {
+			header: header,
+			prefix: "X-Amz-Other-",
+		},
+		"nil header": {
+			prefix: "X-Amz-Meta-",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := PrefixHeaders(c.header, c.prefix, c.optFns...)
+			if e, a := c.expected, actual; !reflect.DeepEqual(e, a) {
+				t.Errorf("expected %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestRangePrefixHeaders(t *testing.T) {
+	header := http.Header{
+		"X-Amz-Meta-B": {"2"},
+		"x-amz-meta-a": {"1"},
+		"X-Amz-Meta-A": {"0"},
+		"X-Amz-Meta-C": {"3"},
+	}
+
+	var names []string
+	var values [][]string
+	RangePrefixHeaders(header, "X-Amz-Meta-", func(name string, vs []string) bool {
+		names = append(names, name)
+		values = append(values, vs)
+		return name != "B"
+	})
+
+	if e, a := []string{"A", "B"}, names; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+	if e, a := [][]string{{"0", "1"}, {"2"}}, values; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"math"
 	"math/big"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 // QueryValue is used to encode query key values
@@ -105,3 +107,102 @@ func (qv QueryValue) BigDecimal(v *big.Float) {
 	}
 	qv.updateKey(v.Text('e', -1))
 }
+
+// encodeQuery encodes the query of the encoder per its options.
+func (e *Encoder) encodeQuery() string {
+	return encodeValues(e.query, e.queryKeys, e.options)
+}
+
+// encodeValues encodes the values in query string form, e.g. "a=1&b=2", per
+// the query options. keys are the keys of the values in the order they were
+// first added, used for QueryKeyOrderInsertion. With the default query options
+// the values are encoded as by url.Values.Encode.
+func encodeValues(values url.Values, keys []string, o EncoderOptions) string {
+	if o.QuerySpaceEncoding == QuerySpacePlus && o.QueryKeyOrder == QueryKeyOrderSorted &&
+		o.QueryListStyle == QueryListRepeated {
+		return values.Encode()
+	}
+
+	if o.QueryKeyOrder != QueryKeyOrderInsertion {
+		keys = make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+	}
+
+	escape := url.QueryEscape
+	if o.QuerySpaceEncoding == QuerySpacePercent {
+		// spaces are the only characters QueryEscape encodes as "+"
+		escape = func(s string) string {
+			return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+		}
+	}
+
+	var b strings.Builder
+	for _, k := range keys {
+		vs := values[k]
+		if len(vs) == 0 {
+			continue
+		}
+
+		key := escape(k)
+		if o.QueryListStyle == QueryListCommaJoined {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(key)
+			b.WriteByte('=')
+			for i, v := range vs {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				b.WriteString(escape(v))
+			}
+			continue
+		}
+
+		for _, v := range vs {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(key)
+			b.WriteByte('=')
+			b.WriteString(escape(v))
+		}
+	}
+	return b.String()
+}
+
+// appendKey returns the keys with the key appended, unless already present.
+func appendKey(keys []string, key string) []string {
+	for _, k := range keys {
+		if k == key {
+			return keys
+		}
+	}
+	return append(keys, key)
+}
+
+// queryKeyOrder returns the keys of the query string in the order they first
+// occur. Keys that cannot be unescaped are skipped, as by url.ParseQuery.
+func queryKeyOrder(query string) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for len(query) != 0 {
+		var param string
+		param, query, _ = strings.Cut(query, "&")
+		if len(param) == 0 {
+			continue
+		}
+
+		key, _, _ := strings.Cut(param, "=")
+		key, err := url.QueryUnescape(key)
+		if err != nil || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,68 @@
+package httpbinding
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	smithytime "github.com/aws/smithy-go/time"
+)
+
+// TimestampFormat is the format of a timestamp bound to an HTTP header, see
+// HeaderValue.Timestamp.
+type TimestampFormat int
+
+// Enumeration values for TimestampFormat
+const (
+	// An HTTP-date, e.g. "Tue, 29 Apr 2014 18:30:38 GMT", the default format
+	// of timestamps bound to headers.
+	TimestampHTTPDate TimestampFormat = iota
+
+	// An RFC 3339 date-time, e.g. "2014-04-29T18:30:38.123Z".
+	TimestampDateTime
+
+	// Seconds since the Unix epoch with millisecond precision, e.g.
+	// "1398796238.123".
+	TimestampEpochSeconds
+)
+
+func (f TimestampFormat) String() string {
+	switch f {
+	case TimestampHTTPDate:
+		return "http-date"
+	case TimestampDateTime:
+		return "date-time"
+	case TimestampEpochSeconds:
+		return "epoch-seconds"
+	default:
+		return "TimestampFormat(" + strconv.Itoa(int(f)) + ")"
+	}
+}
+
+func formatTimestamp(v time.Time, format TimestampFormat) string {
+	switch format {
+	case TimestampDateTime:
+		return smithytime.FormatDateTime(v)
+	case TimestampEpochSeconds:
+		return strconv.FormatFloat(smithytime.FormatEpochSeconds(v), 'f', -1, 64)
+	default:
+		return smithytime.FormatHTTPDate(v)
+	}
+}
+
+func parseTimestamp(v string, format TimestampFormat) (time.Time, error) {
+	switch format {
+	case TimestampHTTPDate:
+		return smithytime.ParseHTTPDate(v)
+	case TimestampDateTime:
+		return smithytime.ParseDateTime(v)
+	case TimestampEpochSeconds:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return smithytime.ParseEpochSeconds(f), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown timestamp format %v", format)
+	}
+}
@@ -12,6 +12,9 @@ type URIValue struct {
 	path, rawPath, buffer *[]byte
 
 	key string
+
+	// template bound to the value of the label, if set by an Encoder
+	template *uriTemplate
 }
 
 func newURIValue(path *[]byte, rawPath *[]byte, buffer *[]byte, key string) URIValue {
@@ -19,6 +22,10 @@ func newURIValue(path *[]byte, rawPath *[]byte, buffer *[]byte, key string) URIV
 }
 
 func (u URIValue) modifyURI(value string) (err error) {
+	if u.template != nil {
+		return u.template.setValue(u.key, value)
+	}
+
 	*u.path, *u.buffer, err = replacePathElement(*u.path, *u.buffer, u.key, value, false)
 	if err != nil {
 		return err
@@ -0,0 +1,175 @@
+package httpbinding
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Reasons of a URITemplateError, which can be tested for with errors.Is.
+var (
+	// ErrMalformedLabel is a label of a URI template that is not closed,
+	// has no name, or does not occupy an entire path segment.
+	ErrMalformedLabel = errors.New("malformed label")
+
+	// ErrDuplicateLabel is a label that occurs more than once in a URI
+	// template.
+	ErrDuplicateLabel = errors.New("duplicate label")
+
+	// ErrMultipleGreedyLabels is a URI template with more than one greedy
+	// label, e.g. {Key+}.
+	ErrMultipleGreedyLabels = errors.New("more than one greedy label")
+
+	// ErrUnknownLabel is a label value set for a label that is not in the URI
+	// template.
+	ErrUnknownLabel = errors.New("label not in template")
+
+	// ErrEmptyLabelValue is a label value that is empty, rejected by
+	// Encoder.Encode with EncoderOptions.StrictValidation.
+	ErrEmptyLabelValue = errors.New("label value is empty")
+
+	// ErrMissingLabelValue is a label of the URI template whose value was not
+	// set when the request was encoded.
+	ErrMissingLabelValue = errors.New("label value not set")
+)
+
+// URITemplateError is an error of a URI path template, e.g.
+// "/{Bucket}/{Key+}", or of the values of its labels, returned by
+// NewEncoder, Encoder.SetURI values, Encoder.Encode, and NewDecoder.
+type URITemplateError struct {
+	// The URI path template.
+	Template string
+
+	// The label of the error, e.g. "Key", or the malformed path segment for
+	// ErrMalformedLabel. Empty if the error is not of a single label.
+	Label string
+
+	// The reason of the error, e.g. ErrDuplicateLabel.
+	Err error
+}
+
+func (e *URITemplateError) Error() string {
+	if len(e.Label) == 0 {
+		return fmt.Sprintf("uri template %s: %v", e.Template, e.Err)
+	}
+	return fmt.Sprintf("uri template %s: %v: %s", e.Template, e.Err, e.Label)
+}
+
+// Unwrap returns the reason of the error.
+func (e *URITemplateError) Unwrap() error {
+	return e.Err
+}
+
+// uriLabel is a label of a URI template.
+type uriLabel struct {
+	name   string
+	greedy bool
+}
+
+// uriTemplate is a validated URI path template, with the values of its
+// labels as they are set.
+type uriTemplate struct {
+	template string
+
+	// labels in template order
+	labels []uriLabel
+	values map[string]string
+}
+
+// parseURITemplate validates the URI path template, returning its labels.
+//
+// Each label occupies an entire path segment, e.g. "/{Bucket}", and has a
+// name that occurs once in the template. At most one label is greedy, e.g.
+// "/{Key+}". Path segments without braces are literals.
+func parseURITemplate(template string) (*uriTemplate, error) {
+	t := &uriTemplate{}
+	if err := t.parse(template); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// parse resets the template to the URI path template, reusing its label and
+// value buffers, see parseURITemplate.
+func (t *uriTemplate) parse(template string) error {
+	t.template, t.labels = template, t.labels[:0]
+	for k := range t.values {
+		delete(t.values, k)
+	}
+
+	greedy := false
+	for rest, more := template, true; more; {
+		var segment string
+		segment, rest, more = strings.Cut(rest, "/")
+		if !strings.ContainsAny(segment, "{}") {
+			continue
+		}
+
+		name, isGreedy, ok := parseLabel(segment)
+		if !ok || len(name) == 0 || strings.ContainsAny(name, "{}+") {
+			return &URITemplateError{Template: template, Label: segment, Err: ErrMalformedLabel}
+		}
+		if t.hasLabel(name) {
+			return &URITemplateError{Template: template, Label: name, Err: ErrDuplicateLabel}
+		}
+		if isGreedy && greedy {
+			return &URITemplateError{Template: template, Label: name, Err: ErrMultipleGreedyLabels}
+		}
+		greedy = greedy || isGreedy
+
+		t.labels = append(t.labels, uriLabel{name: name, greedy: isGreedy})
+	}
+
+	return nil
+}
+
+// setValue sets the value of the label. Returns an error if the template has
+// no such label.
+func (t *uriTemplate) setValue(key, value string) error {
+	if !t.hasLabel(key) {
+		return &URITemplateError{Template: t.template, Label: key, Err: ErrUnknownLabel}
+	}
+
+	if t.values == nil {
+		t.values = make(map[string]string, len(t.labels))
+	}
+	t.values[key] = value
+	return nil
+}
+
+func (t *uriTemplate) hasLabel(key string) bool {
+	for _, l := range t.labels {
+		if l.name == key {
+			return true
+		}
+	}
+	return false
+}
+
+// expand replaces the labels of the path and raw path with their values,
+// escaping them in the raw path. A greedy label's value has each of its
+// segments escaped, keeping the slashes between them. Returns an error if the
+// value of a label is not set.
+func (t *uriTemplate) expand(path, rawPath, buffer []byte) ([]byte, []byte, []byte, error) {
+	for _, l := range t.labels {
+		if _, ok := t.values[l.name]; !ok {
+			return path, rawPath, buffer, &URITemplateError{Template: t.template, Label: l.name, Err: ErrMissingLabelValue}
+		}
+	}
+
+	// labels are replaced from the end of the template, such that a value
+	// is never searched for the labels before it
+	var err error
+	for i := len(t.labels) - 1; i >= 0; i-- {
+		key := t.labels[i].name
+		value := t.values[key]
+
+		if path, buffer, err = replacePathElement(path, buffer, key, value, false); err != nil {
+			return path, rawPath, buffer, err
+		}
+		if rawPath, buffer, err = replacePathElement(rawPath, buffer, key, value, true); err != nil {
+			return path, rawPath, buffer, err
+		}
+	}
+	return path, rawPath, buffer, nil
+}
@@ -0,0 +1,200 @@
+package httpbinding
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestParseURITemplate(t *testing.T) {
+	cases := map[string]struct {
+		template    string
+		expectErr   error
+		expectLabel string
+	}{
+		"literal": {
+			template: "/some/path",
+		},
+		"labels": {
+			template: "/{Bucket}/{Key+}/suffix/{Other}",
+		},
+		"unclosed label": {
+			template:    "/{Bucket",
+			expectErr:   ErrMalformedLabel,
+			expectLabel: "{Bucket",
+		},
+		"empty label": {
+			template:    "/{}",
+			expectErr:   ErrMalformedLabel,
+			expectLabel: "{}",
+		},
+		"empty greedy label": {
+			template:    "/{+}",
+			expectErr:   ErrMalformedLabel,
+			expectLabel: "{+}",
+		},
+		"partial segment": {
+			template:    "/prefix-{Bucket}",
+			expectErr:   ErrMalformedLabel,
+			expectLabel: "prefix-{Bucket}",
+		},
+		"contiguous labels": {
+			template:    "/{A}{B}",
+			expectErr:   ErrMalformedLabel,
+			expectLabel: "{A}{B}",
+		},
+		"duplicate label": {
+			template:    "/{Bucket}/path/{Bucket}",
+			expectErr:   ErrDuplicateLabel,
+			expectLabel: "Bucket",
+		},
+		"duplicate greedy label": {
+			template:    "/{Bucket}/{Bucket+}",
+			expectErr:   ErrDuplicateLabel,
+			expectLabel: "Bucket",
+		},
+		"multiple greedy labels": {
+			template:    "/{A+}/{B+}",
+			expectErr:   ErrMultipleGreedyLabels,
+			expectLabel: "B",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseURITemplate(c.template)
+			if c.expectErr == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			var terr *URITemplateError
+			if !errors.As(err, &terr) {
+				t.Fatalf("expected URITemplateError, got %v", err)
+			}
+			if !errors.Is(err, c.expectErr) {
+				t.Errorf("expected %v, got %v", c.expectErr, err)
+			}
+			if e, a := c.template, terr.Template; e != a {
+				t.Errorf("expected template %v, got %v", e, a)
+			}
+			if e, a := c.expectLabel, terr.Label; e != a {
+				t.Errorf("expected label %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestEncoder_URITemplate(t *testing.T) {
+	cases := map[string]struct {
+		template     string
+		labels       [][2]string
+		expectPath   string
+		expectRaw    string
+		expectSetErr error
+		expectErr    error
+	}{
+		"greedy label escaped per segment": {
+			template:   "/{Bucket}/{Key+}",
+			labels:     [][2]string{{"Bucket", "a/b"}, {"Key", "c d/e?f/g"}},
+			expectPath: "/a/b/c d/e?f/g",
+			expectRaw:  "/a%2Fb/c%20d/e%3Ff/g",
+		},
+		"label name prefix of another": {
+			template:   "/{ab}/{a}",
+			labels:     [][2]string{{"a", "1"}, {"ab", "2"}},
+			expectPath: "/2/1",
+			expectRaw:  "/2/1",
+		},
+		"value containing label": {
+			template:   "/{A}/{B}",
+			labels:     [][2]string{{"A", "{B}"}, {"B", "b"}},
+			expectPath: "/{B}/b",
+			expectRaw:  "/%7BB%7D/b",
+		},
+		"value set twice": {
+			template:   "/{A}",
+			labels:     [][2]string{{"A", "a"}, {"A", "b"}},
+			expectPath: "/b",
+			expectRaw:  "/b",
+		},
+		"unknown label": {
+			template:     "/{A}",
+			labels:       [][2]string{{"B", "b"}},
+			expectSetErr: ErrUnknownLabel,
+		},
+		"empty value": {
+			template:   "/{A}/b",
+			labels:     [][2]string{{"A", ""}},
+			expectPath: "//b",
+			expectRaw:  "//b",
+		},
+		"missing value": {
+			template:  "/{A}/{B+}",
+			labels:    [][2]string{{"A", "a"}},
+			expectErr: ErrMissingLabelValue,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder, err := NewEncoder(c.template, "", http.Header{})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			for _, l := range c.labels {
+				err := encoder.SetURI(l[0]).String(l[1])
+				if c.expectSetErr != nil {
+					if !errors.Is(err, c.expectSetErr) {
+						t.Fatalf("expected %v, got %v", c.expectSetErr, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+			}
+
+			req, err := encoder.Encode(&http.Request{URL: &url.URL{}})
+			if c.expectErr != nil {
+				if !errors.Is(err, c.expectErr) {
+					t.Fatalf("expected %v, got %v", c.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if e, a := c.expectPath, req.URL.Path; e != a {
+				t.Errorf("expected path %v, got %v", e, a)
+			}
+			if e, a := c.expectRaw, req.URL.RawPath; e != a {
+				t.Errorf("expected raw path %v, got %v", e, a)
+			}
+
+			// encoding again writes the same path
+			req, err = encoder.Encode(&http.Request{URL: &url.URL{}})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if e, a := c.expectPath, req.URL.Path; e != a {
+				t.Errorf("expected path %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestNewEncoder_MalformedTemplate(t *testing.T) {
+	_, err := NewEncoder("/{A}/{A}", "", http.Header{})
+	if !errors.Is(err, ErrDuplicateLabel) {
+		t.Errorf("expected %v, got %v", ErrDuplicateLabel, err)
+	}
+	if e, a := "uri template /{A}/{A}: duplicate label: A", err.Error(); e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+}
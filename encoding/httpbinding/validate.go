@@ -0,0 +1,147 @@
+package httpbinding
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Reasons of a ValidationError, which can be tested for with errors.Is, see
+// EncoderOptions.StrictValidation.
+var (
+	// ErrInvalidHeaderName is a header name that is empty or is not an
+	// RFC 7230 token, e.g. contains whitespace or a colon.
+	ErrInvalidHeaderName = errors.New("invalid header name")
+
+	// ErrInvalidHeaderValue is a header value that contains a control
+	// character other than horizontal tab, e.g. CR or LF.
+	ErrInvalidHeaderValue = errors.New("invalid header value")
+
+	// ErrInvalidQueryKey is a query key that is empty, or contains a control
+	// character or an RFC 3986 reserved character, e.g. "&" or "=".
+	ErrInvalidQueryKey = errors.New("invalid query key")
+
+	// ErrInvalidLabelSegment is a greedy label value with an empty or dot
+	// path segment, e.g. "a//b" or "a/../b", which is rejected or normalized
+	// by servers.
+	ErrInvalidLabelSegment = errors.New("label value has empty or dot path segment")
+)
+
+// ValidationError is an error of a header or query value of an encoded
+// request, returned by Encoder.Encode if the encoder has
+// EncoderOptions.StrictValidation set.
+type ValidationError struct {
+	// The component of the request of the error, "header" or "query".
+	Component string
+
+	// The header name or query key of the error. The value is not included,
+	// since it may be sensitive.
+	Name string
+
+	// The reason of the error, e.g. ErrInvalidHeaderValue.
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s %q: %v", e.Component, e.Name, e.Err)
+}
+
+// Unwrap returns the reason of the error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// validate returns an error if a header, query key, or label value of the
+// encoder is invalid. Headers and query keys are validated in sorted
+// order, such that the error is deterministic.
+func (e *Encoder) validate() error {
+	for _, k := range sortedKeys(e.header) {
+		if !validHeaderName(k) {
+			return &ValidationError{Component: "header", Name: k, Err: ErrInvalidHeaderName}
+		}
+		for _, v := range e.header[k] {
+			if !validHeaderValue(v) {
+				return &ValidationError{Component: "header", Name: k, Err: ErrInvalidHeaderValue}
+			}
+		}
+	}
+
+	for _, k := range sortedKeys(e.query) {
+		if !validQueryKey(k) {
+			return &ValidationError{Component: "query", Name: k, Err: ErrInvalidQueryKey}
+		}
+	}
+
+	for _, l := range e.template.labels {
+		v, ok := e.template.values[l.name]
+		if !ok {
+			continue
+		}
+		if len(v) == 0 {
+			return &URITemplateError{Template: e.template.template, Label: l.name, Err: ErrEmptyLabelValue}
+		}
+		if l.greedy && !validGreedyLabelValue(v) {
+			return &URITemplateError{Template: e.template.template, Label: l.name, Err: ErrInvalidLabelSegment}
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func validHeaderName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) != -1:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func validHeaderValue(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c < ' ' && c != '\t' || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func validQueryKey(key string) bool {
+	if len(key) == 0 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if c := key[i]; c < ' ' || c == 0x7f || strings.IndexByte(":/?#[]@!$&'()*+,;=", c) != -1 {
+			return false
+		}
+	}
+	return true
+}
+
+func validGreedyLabelValue(value string) bool {
+	for rest, more := value, true; more; {
+		var segment string
+		segment, rest, more = strings.Cut(rest, "/")
+		if len(segment) == 0 || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
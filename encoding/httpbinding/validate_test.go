@@ -0,0 +1,154 @@
+package httpbinding
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestEncoder_StrictValidation(t *testing.T) {
+	cases := map[string]struct {
+		encode      func(*Encoder)
+		expectErr   error
+		expectName  string
+		expectLabel string
+	}{
+		"valid": {
+			encode: func(e *Encoder) {
+				e.SetHeader("X-Amz-Meta-Key").String("a\tb é")
+				e.SetQuery("list-type").Integer(2)
+				e.SetQuery("Filter.1.Name").String("a&b=c")
+				e.SetURI("Key").String("a/b c/.d")
+			},
+		},
+		"header value CRLF": {
+			encode: func(e *Encoder) {
+				e.SetHeader("X-Amz-Meta-Key").String("a\r\nX-Injected: b")
+			},
+			expectErr:  ErrInvalidHeaderValue,
+			expectName: "X-Amz-Meta-Key",
+		},
+		"header value NUL": {
+			encode: func(e *Encoder) {
+				e.AddHeader("X-Amz-Meta-Key").String("a")
+				e.AddHeader("X-Amz-Meta-Key").String("\x00")
+			},
+			expectErr:  ErrInvalidHeaderValue,
+			expectName: "X-Amz-Meta-Key",
+		},
+		"header name": {
+			encode: func(e *Encoder) {
+				e.Headers("X-Amz-Meta-").SetHeader("a b").String("v")
+			},
+			expectErr:  ErrInvalidHeaderName,
+			expectName: "X-Amz-Meta-a b",
+		},
+		"query key reserved": {
+			encode: func(e *Encoder) {
+				e.SetQuery("a=b").String("v")
+			},
+			expectErr:  ErrInvalidQueryKey,
+			expectName: "a=b",
+		},
+		"query key empty": {
+			encode: func(e *Encoder) {
+				e.SetQuery("").String("v")
+			},
+			expectErr:  ErrInvalidQueryKey,
+			expectName: "",
+		},
+		"greedy label empty segment": {
+			encode: func(e *Encoder) {
+				e.SetURI("Key").String("a//b")
+			},
+			expectErr:   ErrInvalidLabelSegment,
+			expectLabel: "Key",
+		},
+		"greedy label dot segment": {
+			encode: func(e *Encoder) {
+				e.SetURI("Key").String("a/../b")
+			},
+			expectErr:   ErrInvalidLabelSegment,
+			expectLabel: "Key",
+		},
+		"label empty": {
+			encode: func(e *Encoder) {
+				e.SetURI("Key").String("")
+			},
+			expectErr:   ErrEmptyLabelValue,
+			expectLabel: "Key",
+		},
+		"greedy label trailing slash": {
+			encode: func(e *Encoder) {
+				e.SetURI("Key").String("a/")
+			},
+			expectErr:   ErrInvalidLabelSegment,
+			expectLabel: "Key",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder, err := NewEncoder("/{Key+}", "", http.Header{}, func(o *EncoderOptions) {
+				o.StrictValidation = true
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if len(c.expectLabel) == 0 {
+				encoder.SetURI("Key").String("key")
+			}
+			c.encode(encoder)
+
+			_, err = encoder.Encode(&http.Request{URL: &url.URL{}})
+			if c.expectErr == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, c.expectErr) {
+				t.Fatalf("expected %v, got %v", c.expectErr, err)
+			}
+
+			var verr *ValidationError
+			var terr *URITemplateError
+			switch {
+			case errors.As(err, &verr):
+				if e, a := c.expectName, verr.Name; e != a {
+					t.Errorf("expected name %q, got %q", e, a)
+				}
+			case errors.As(err, &terr):
+				if e, a := c.expectLabel, terr.Label; e != a {
+					t.Errorf("expected label %q, got %q", e, a)
+				}
+			default:
+				t.Fatalf("expected validation error, got %T", err)
+			}
+
+			// the error does not include the value
+			if strings.Contains(err.Error(), "Injected") {
+				t.Errorf("expected value not in error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEncoder_NotStrict(t *testing.T) {
+	encoder, err := NewEncoder("/{Key+}", "", http.Header{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	encoder.SetURI("Key").String("a//b")
+	encoder.SetQuery("a=b").String("v")
+
+	req, err := encoder.Encode(&http.Request{URL: &url.URL{}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if e, a := "a%3Db=v", req.URL.RawQuery; e != a {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+}
@@ -0,0 +1,32 @@
+// Package chunk provides reading the contents of an io.Reader in chunks, for
+// the encoders that write a value from a reader directly into their output.
+package chunk
+
+import "io"
+
+// Base64Size is the number of bytes read from an io.Reader at a time to be
+// encoded as base64. It is a multiple of 3 so that only the final chunk needs
+// padding.
+const Base64Size = 3 * 1024
+
+// maxConsecutiveEmptyReads is the number of reads returning no data and no
+// error after which Read gives up, as by bufio.Reader.
+const maxConsecutiveEmptyReads = 100
+
+// Read reads from r until p is full or r returns an error. Returns
+// io.ErrNoProgress if r returns no data and no error too many times in a row.
+func Read(r io.Reader, p []byte) (n int, err error) {
+	empty := 0
+	for n < len(p) && err == nil {
+		var m int
+		m, err = r.Read(p[n:])
+		n += m
+
+		if m > 0 {
+			empty = 0
+		} else if empty++; empty >= maxConsecutiveEmptyReads && err == nil {
+			err = io.ErrNoProgress
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,51 @@
+package chunk
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type emptyReader struct {
+	reads int
+}
+
+func (r *emptyReader) Read(p []byte) (int, error) {
+	r.reads++
+	return 0, nil
+}
+
+func TestRead(t *testing.T) {
+	p := make([]byte, 4)
+	n, err := Read(iotest.OneByteReader(strings.NewReader("abcdef")), p)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "abcd", string(p[:n]); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+
+	n, err = Read(strings.NewReader("ab"), p)
+	if err != io.EOF {
+		t.Fatalf("expect %v, got %v", io.EOF, err)
+	}
+	if e, a := "ab", string(p[:n]); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+}
+
+func TestRead_NoProgress(t *testing.T) {
+	r := &emptyReader{}
+	n, err := Read(r, make([]byte, 4))
+	if !errors.Is(err, io.ErrNoProgress) {
+		t.Fatalf("expect %v, got %v", io.ErrNoProgress, err)
+	}
+	if n != 0 {
+		t.Errorf("expect no bytes read, got %d", n)
+	}
+	if e, a := maxConsecutiveEmptyReads, r.reads; e != a {
+		t.Errorf("expect %d reads, got %d", e, a)
+	}
+}
@@ -0,0 +1,71 @@
+package json
+
+import (
+	"bytes"
+	"encoding/base64"
+	"math/big"
+	"strconv"
+
+	"github.com/aws/smithy-go/encoding"
+)
+
+// The Append functions encode a single JSON value onto the end of a
+// caller-provided buffer and return the extended buffer, in the manner of
+// strconv.AppendInt. They allow callers that manage their own buffers to skip
+// the allocation of an Encoder entirely.
+
+// AppendString appends v encoded as a JSON string to dst.
+func AppendString(dst []byte, v string) []byte {
+	w := bytes.NewBuffer(dst)
+	escapeString(w, v, EncoderOptions{})
+	return w.Bytes()
+}
+
+// AppendLong appends v encoded as a JSON number to dst.
+func AppendLong(dst []byte, v int64) []byte {
+	return strconv.AppendInt(dst, v, 10)
+}
+
+// AppendULong appends v encoded as a JSON number to dst.
+func AppendULong(dst []byte, v uint64) []byte {
+	return strconv.AppendUint(dst, v, 10)
+}
+
+// AppendFloat appends v encoded as a JSON number to dst.
+func AppendFloat(dst []byte, v float32) []byte {
+	return encoding.EncodeFloat(dst, float64(v), 32)
+}
+
+// AppendDouble appends v encoded as a JSON number to dst.
+func AppendDouble(dst []byte, v float64) []byte {
+	return encoding.EncodeFloat(dst, v, 64)
+}
+
+// AppendBoolean appends v encoded as a JSON boolean to dst.
+func AppendBoolean(dst []byte, v bool) []byte {
+	return strconv.AppendBool(dst, v)
+}
+
+// AppendNull appends a JSON null to dst.
+func AppendNull(dst []byte) []byte {
+	return append(dst, null...)
+}
+
+// AppendBase64EncodeBytes appends v as a base64 value in a JSON string to dst.
+// A nil v is appended as a JSON null.
+func AppendBase64EncodeBytes(dst []byte, v []byte) []byte {
+	if v == nil {
+		return AppendNull(dst)
+	}
+
+	dst = append(dst, quote)
+	n := len(dst)
+	dst = append(dst, make([]byte, base64.StdEncoding.EncodedLen(len(v)))...)
+	base64.StdEncoding.Encode(dst[n:], v)
+	return append(dst, quote)
+}
+
+// AppendBigInteger appends v encoded as a JSON number to dst.
+func AppendBigInteger(dst []byte, v *big.Int) []byte {
+	return v.Append(dst, 10)
+}
@@ -0,0 +1,119 @@
+package json
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestAppend(t *testing.T) {
+	cases := map[string]struct {
+		append   func([]byte) []byte
+		expected string
+	}{
+		"string": {
+			append:   func(p []byte) []byte { return AppendString(p, "foo\n\"bar\"") },
+			expected: `prefix:"foo\n\"bar\""`,
+		},
+		"long": {
+			append:   func(p []byte) []byte { return AppendLong(p, -1024) },
+			expected: `prefix:-1024`,
+		},
+		"ulong": {
+			append:   func(p []byte) []byte { return AppendULong(p, 18446744073709551615) },
+			expected: `prefix:18446744073709551615`,
+		},
+		"float": {
+			append:   func(p []byte) []byte { return AppendFloat(p, 3.14) },
+			expected: `prefix:3.14`,
+		},
+		"double": {
+			append:   func(p []byte) []byte { return AppendDouble(p, 1e21) },
+			expected: `prefix:1e+21`,
+		},
+		"boolean": {
+			append:   func(p []byte) []byte { return AppendBoolean(p, true) },
+			expected: `prefix:true`,
+		},
+		"null": {
+			append:   AppendNull,
+			expected: `prefix:null`,
+		},
+		"base64": {
+			append:   func(p []byte) []byte { return AppendBase64EncodeBytes(p, []byte("foo bar")) },
+			expected: `prefix:"Zm9vIGJhcg=="`,
+		},
+		"base64 nil": {
+			append:   func(p []byte) []byte { return AppendBase64EncodeBytes(p, nil) },
+			expected: `prefix:null`,
+		},
+		"big integer": {
+			append:   func(p []byte) []byte { return AppendBigInteger(p, big.NewInt(-42)) },
+			expected: `prefix:-42`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if a := string(c.append([]byte("prefix:"))); c.expected != a {
+				t.Errorf("expected %s, got %s", c.expected, a)
+			}
+		})
+	}
+}
+
+func TestEncoderPool(t *testing.T) {
+	e := GetEncoder()
+	e.Value.String("foo")
+	if e, a := `"foo"`, e.String(); e != a {
+		t.Fatalf("expected %s, got %s", e, a)
+	}
+	PutEncoder(e)
+
+	e = GetEncoder()
+	defer PutEncoder(e)
+	if a := e.Bytes(); len(a) != 0 {
+		t.Errorf("expect pooled encoder to be empty, got %s", a)
+	}
+}
+
+func benchmarkEncode(e *Encoder) {
+	o := e.Object()
+	o.Key("stringKey").String("stringValue")
+	o.Key("integerKey").Long(1024)
+	o.Key("floatKey").Double(3.14)
+	o.Key("byteSlice").Base64EncodeBytes([]byte("foo bar"))
+	o.Close()
+}
+
+func BenchmarkEncoder_New(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkEncode(NewEncoder())
+	}
+}
+
+func BenchmarkEncoder_Pooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e := GetEncoder()
+		benchmarkEncode(e)
+		PutEncoder(e)
+	}
+}
+
+func BenchmarkAppend(b *testing.B) {
+	b.ReportAllocs()
+	p := make([]byte, 0, 256)
+	for i := 0; i < b.N; i++ {
+		p = p[:0]
+		p = append(p, `{"stringKey":`...)
+		p = AppendString(p, "stringValue")
+		p = append(p, `,"integerKey":`...)
+		p = AppendLong(p, 1024)
+		p = append(p, `,"floatKey":`...)
+		p = AppendDouble(p, 3.14)
+		p = append(p, `,"byteSlice":`...)
+		p = AppendBase64EncodeBytes(p, []byte("foo bar"))
+		p = append(p, '}')
+	}
+}
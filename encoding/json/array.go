@@ -9,11 +9,13 @@ type Array struct {
 	w          *bytes.Buffer
 	writeComma bool
 	scratch    *[]byte
+	state      *encoderState
 }
 
-func newArray(w *bytes.Buffer, scratch *[]byte) *Array {
+func newArray(w *bytes.Buffer, scratch *[]byte, state *encoderState) *Array {
 	w.WriteRune(leftBracket)
-	return &Array{w: w, scratch: scratch}
+	state.depth++
+	return &Array{w: w, scratch: scratch, state: state}
 }
 
 // Value adds a new element to the JSON Array.
@@ -25,11 +27,21 @@ func (a *Array) Value() Value {
 	} else {
 		a.writeComma = true
 	}
+	a.state.writeIndent(a.w)
 
-	return newValue(a.w, a.scratch)
+	return newValue(a.w, a.scratch, a.state)
+}
+
+// WriteRaw adds the pre-encoded JSON value v to the JSON Array verbatim.
+func (a *Array) WriteRaw(v RawMessage) {
+	a.Value().WriteRaw(v)
 }
 
 // Close encodes the end of the JSON Array
 func (a *Array) Close() {
+	a.state.depth--
+	if a.writeComma {
+		a.state.writeIndent(a.w)
+	}
 	a.w.WriteRune(rightBracket)
 }
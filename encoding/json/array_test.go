@@ -9,7 +9,7 @@ func TestArray(t *testing.T) {
 	buffer := bytes.NewBuffer(nil)
 	scratch := make([]byte, 64)
 
-	array := newArray(buffer, &scratch)
+	array := newArray(buffer, &scratch, &encoderState{})
 	array.Value().String("bar")
 	array.Value().String("baz")
 	array.Close()
@@ -19,3 +19,19 @@ func TestArray(t *testing.T) {
 		t.Errorf("expected %+q, but got %+q", e, a)
 	}
 }
+
+func TestArray_WriteRaw(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	scratch := make([]byte, 64)
+
+	array := newArray(buffer, &scratch, &encoderState{})
+	array.WriteRaw(RawMessage(`{"foo":"bar"}`))
+	array.Value().String("baz")
+	array.WriteRaw(RawMessage(`true`))
+	array.Close()
+
+	e := []byte(`[{"foo":"bar"},"baz",true]`)
+	if a := buffer.Bytes(); bytes.Compare(e, a) != 0 {
+		t.Errorf("expected %+q, but got %+q", e, a)
+	}
+}
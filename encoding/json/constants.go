@@ -12,4 +12,8 @@ const (
 	colon = ':'
 
 	null = "null"
+
+	floatNaN         = "NaN"
+	floatInfinity    = "Infinity"
+	floatNegInfinity = "-Infinity"
 )
@@ -0,0 +1,163 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DecoderOptions is the set of options that can be configured for a Decoder.
+//
+// The zero value is lenient, as is appropriate for clients that should
+// tolerate responses from newer versions of a service. Servers validating
+// requests may wish to enable each of the strict options.
+type DecoderOptions struct {
+	// Return a DuplicateKeyError if an object contains the same key more than
+	// once. By default the last value for the key is kept.
+	DisallowDuplicateKeys bool
+
+	// Return an error from DecodeNode if any data other than whitespace
+	// follows the top-level value. By default such data is not read.
+	DisallowTrailingData bool
+}
+
+// DuplicateKeyError is returned when a decoded object contains the same key
+// more than once, and duplicate keys are disallowed. It is also recorded by an
+// Encoder configured with DuplicateKeyReject.
+type DuplicateKeyError struct {
+	Key string
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate object key %q", e.Key)
+}
+
+// Decoder decodes a stream of JSON values into a tree of Nodes.
+type Decoder struct {
+	d       *json.Decoder
+	options DecoderOptions
+}
+
+// NewDecoder returns a Decoder that reads from r.
+//
+// The Decoder buffers its input, and may read data from r beyond the JSON
+// values requested.
+func NewDecoder(r io.Reader, optFns ...func(*DecoderOptions)) *Decoder {
+	var o DecoderOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	d := json.NewDecoder(r)
+	d.UseNumber()
+
+	return &Decoder{d: d, options: o}
+}
+
+// Decode reads the next JSON value from the input and returns it. Decode
+// returns io.EOF once the input is exhausted.
+func (d *Decoder) Decode() (Node, error) {
+	t, err := d.d.Token()
+	if err != nil {
+		return nil, err
+	}
+	return d.decode(t)
+}
+
+// DecodeNode decodes the single JSON value read from r.
+func DecodeNode(r io.Reader, optFns ...func(*DecoderOptions)) (Node, error) {
+	d := NewDecoder(r, optFns...)
+	n, err := d.Decode()
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.options.DisallowTrailingData {
+		return n, nil
+	}
+	if _, err := d.d.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after top-level value")
+	}
+	return n, nil
+}
+
+func (d *Decoder) decode(t json.Token) (Node, error) {
+	switch v := t.(type) {
+	case json.Delim:
+		if v == leftBrace {
+			return d.decodeObject()
+		}
+		if v == leftBracket {
+			return d.decodeArray()
+		}
+		return nil, fmt.Errorf("unexpected delimiter %v", v)
+	case string:
+		return StringNode(v), nil
+	case json.Number:
+		return NumberNode(v), nil
+	case bool:
+		return BoolNode(v), nil
+	case nil:
+		return NullNode{}, nil
+	default:
+		return nil, fmt.Errorf("unexpected token %T", t)
+	}
+}
+
+func (d *Decoder) decodeObject() (ObjectNode, error) {
+	o := ObjectNode{}
+	for d.d.More() {
+		t, err := d.d.Token()
+		if err != nil {
+			return nil, fmt.Errorf("decode key: %w", err)
+		}
+		key, ok := t.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string key, found %T", t)
+		}
+		if _, ok := o[key]; ok && d.options.DisallowDuplicateKeys {
+			return nil, &DuplicateKeyError{Key: key}
+		}
+
+		t, err = d.d.Token()
+		if err != nil {
+			return nil, fmt.Errorf("decode value of %q: %w", key, err)
+		}
+		v, err := d.decode(t)
+		if err != nil {
+			return nil, fmt.Errorf("decode value of %q: %w", key, err)
+		}
+
+		o[key] = v
+	}
+
+	// consume the closing brace, Token validates it matches
+	if _, err := d.d.Token(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (d *Decoder) decodeArray() (ArrayNode, error) {
+	a := ArrayNode{}
+	for d.d.More() {
+		t, err := d.d.Token()
+		if err != nil {
+			return nil, fmt.Errorf("decode element %d: %w", len(a), err)
+		}
+		v, err := d.decode(t)
+		if err != nil {
+			return nil, fmt.Errorf("decode element %d: %w", len(a), err)
+		}
+
+		a = append(a, v)
+	}
+
+	if _, err := d.d.Token(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
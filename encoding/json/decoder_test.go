@@ -0,0 +1,138 @@
+package json
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeNode(t *testing.T) {
+	cases := map[string]struct {
+		input    string
+		options  DecoderOptions
+		expected Node
+		err      string
+	}{
+		"string": {
+			input:    `"foo"`,
+			expected: StringNode("foo"),
+		},
+		"number": {
+			input:    `-1.5e3`,
+			expected: NumberNode("-1.5e3"),
+		},
+		"large number": {
+			input:    `18446744073709551616`,
+			expected: NumberNode("18446744073709551616"),
+		},
+		"bool": {
+			input:    `true`,
+			expected: BoolNode(true),
+		},
+		"null": {
+			input:    `null`,
+			expected: NullNode{},
+		},
+		"empty object": {
+			input:    `{}`,
+			expected: ObjectNode{},
+		},
+		"empty array": {
+			input:    `[]`,
+			expected: ArrayNode{},
+		},
+		"nested": {
+			input: `{"foo": [1, "bar", {"baz": null}], "qux": false}`,
+			expected: ObjectNode{
+				"foo": ArrayNode{
+					NumberNode("1"),
+					StringNode("bar"),
+					ObjectNode{"baz": NullNode{}},
+				},
+				"qux": BoolNode(false),
+			},
+		},
+		"empty input": {
+			input: ``,
+			err:   "unexpected EOF",
+		},
+		"unterminated object": {
+			input: `{"foo": 1`,
+			err:   "unexpected end of JSON input",
+		},
+		"mismatched delimiter": {
+			input: `[1}`,
+			err:   "invalid character",
+		},
+		"trailing data": {
+			input:    `1 2`,
+			expected: NumberNode("1"),
+		},
+		"duplicate key": {
+			input:    `{"a": 1, "a": 2}`,
+			expected: ObjectNode{"a": NumberNode("2")},
+		},
+		"disallow trailing data": {
+			input:   `1 2`,
+			options: DecoderOptions{DisallowTrailingData: true},
+			err:     "unexpected data after top-level value",
+		},
+		"disallow trailing data, trailing whitespace": {
+			input:    "1 \n",
+			options:  DecoderOptions{DisallowTrailingData: true},
+			expected: NumberNode("1"),
+		},
+		"disallow duplicate keys": {
+			input:   `{"a": 1, "b": {"c": 2, "c": 3}}`,
+			options: DecoderOptions{DisallowDuplicateKeys: true},
+			err:     `duplicate object key "c"`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := DecodeNode(strings.NewReader(c.input), func(o *DecoderOptions) {
+				*o = c.options
+			})
+			if len(c.err) != 0 {
+				if err == nil {
+					t.Fatalf("expect error %q", c.err)
+				}
+				if !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expect error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("expect %#v, got %#v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDecoder_Stream(t *testing.T) {
+	d := NewDecoder(strings.NewReader(`{"a": 1} [true] "b"`))
+
+	expected := []Node{
+		ObjectNode{"a": NumberNode("1")},
+		ArrayNode{BoolNode(true)},
+		StringNode("b"),
+	}
+	for i, e := range expected {
+		a, err := d.Decode()
+		if err != nil {
+			t.Fatalf("%d: expect no error, got %v", i, err)
+		}
+		if !reflect.DeepEqual(e, a) {
+			t.Errorf("%d: expect %#v, got %#v", i, e, a)
+		}
+	}
+
+	if _, err := d.Decode(); err != io.EOF {
+		t.Errorf("expect io.EOF, got %v", err)
+	}
+}
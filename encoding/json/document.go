@@ -0,0 +1,268 @@
+package json
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+
+	"github.com/aws/smithy-go/document"
+)
+
+// UnsupportedDocumentTypeError is returned by Value.Document for a value that
+// is not one of the Go native document types.
+type UnsupportedDocumentTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedDocumentTypeError) Error() string {
+	return fmt.Sprintf("unsupported document type %v", e.Type)
+}
+
+// Document encodes v as JSON with Smithy document semantics. v must be
+// composed entirely of the Go native document types:
+//
+//   - nil, encoded as null, as are nil maps and slices
+//   - bool and string
+//   - the signed and unsigned integer types, float32, and float64
+//   - *big.Int, *big.Float, and document.Number
+//   - map[string]interface{}, encoded as an object with its keys sorted
+//   - []interface{}, encoded as an array
+//   - the Node types of this package, and RawMessage
+//
+// as produced by unmarshaling a document into an empty interface.
+//
+// v is validated before anything is written, such that no output is produced
+// if an error is returned. An UnsupportedDocumentTypeError is returned if v
+// contains any other type, e.g. a struct, and document.InvalidMarshalError if
+// it contains an empty map key, an invalid number literal, or a NaN or
+// infinite float that the encoder's NonFiniteFloatMode does not encode as a
+// string.
+func (jv Value) Document(v interface{}) error {
+	if err := checkDocument(v, jv.state.options.NonFiniteFloats); err != nil {
+		return err
+	}
+
+	jv.document(v)
+	return nil
+}
+
+func checkDocument(v interface{}, floats NonFiniteFloatMode) error {
+	switch vv := v.(type) {
+	case nil, bool, string,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		*big.Int, *big.Float,
+		StringNode, BoolNode, NullNode, RawMessage:
+		return nil
+	case float32:
+		return checkFloat(float64(vv), floats)
+	case float64:
+		return checkFloat(vv, floats)
+	case document.Number:
+		return checkNumberLiteral(string(vv))
+	case NumberNode:
+		return checkNumberLiteral(string(vv))
+	case map[string]interface{}:
+		for k, item := range vv {
+			if err := checkDocumentKey(k); err != nil {
+				return err
+			}
+			if err := checkDocument(item, floats); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		for _, item := range vv {
+			if err := checkDocument(item, floats); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ObjectNode:
+		for k, item := range vv {
+			if err := checkDocumentKey(k); err != nil {
+				return err
+			}
+			if err := checkDocument(item, floats); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ArrayNode:
+		for _, item := range vv {
+			if err := checkDocument(item, floats); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return &UnsupportedDocumentTypeError{Type: reflect.TypeOf(v)}
+	}
+}
+
+func checkDocumentKey(k string) error {
+	if k == "" {
+		return &document.InvalidMarshalError{Message: "map key cannot be empty"}
+	}
+	return nil
+}
+
+func checkFloat(v float64, floats NonFiniteFloatMode) error {
+	if floats != NonFiniteFloatString && (math.IsInf(v, 0) || math.IsNaN(v)) {
+		return &document.InvalidMarshalError{Message: fmt.Sprintf("unsupported float value: %v", v)}
+	}
+	return nil
+}
+
+func checkNumberLiteral(s string) error {
+	if !isValidNumber(s) {
+		return &document.InvalidMarshalError{Message: fmt.Sprintf("invalid number literal: %s", s)}
+	}
+	return nil
+}
+
+// document writes v, which must have been validated by checkDocument.
+func (jv Value) document(v interface{}) {
+	switch vv := v.(type) {
+	case nil, NullNode:
+		jv.Null()
+	case bool:
+		jv.Boolean(vv)
+	case BoolNode:
+		jv.Boolean(bool(vv))
+	case string:
+		jv.String(vv)
+	case StringNode:
+		jv.String(string(vv))
+	case int:
+		jv.Long(int64(vv))
+	case int8:
+		jv.Long(int64(vv))
+	case int16:
+		jv.Long(int64(vv))
+	case int32:
+		jv.Long(int64(vv))
+	case int64:
+		jv.Long(vv)
+	case uint:
+		jv.ULong(uint64(vv))
+	case uint8:
+		jv.ULong(uint64(vv))
+	case uint16:
+		jv.ULong(uint64(vv))
+	case uint32:
+		jv.ULong(uint64(vv))
+	case uint64:
+		jv.ULong(vv)
+	case float32:
+		jv.Float(vv)
+	case float64:
+		jv.Double(vv)
+	case *big.Int:
+		if vv == nil {
+			jv.Null()
+			return
+		}
+		jv.BigInteger(vv)
+	case *big.Float:
+		if vv == nil {
+			jv.Null()
+			return
+		}
+		jv.BigDecimal(vv)
+	case document.Number:
+		jv.Write([]byte(vv))
+	case NumberNode:
+		jv.Write([]byte(vv))
+	case RawMessage:
+		jv.WriteRaw(vv)
+	case map[string]interface{}:
+		if vv == nil {
+			jv.Null()
+			return
+		}
+		o := jv.Object()
+		for _, k := range sortedKeys(vv) {
+			o.Key(k).document(vv[k])
+		}
+		o.Close()
+	case ObjectNode:
+		o := jv.Object()
+		keys := make([]string, 0, len(vv))
+		for k := range vv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			o.Key(k).document(vv[k])
+		}
+		o.Close()
+	case []interface{}:
+		if vv == nil {
+			jv.Null()
+			return
+		}
+		a := jv.Array()
+		for _, item := range vv {
+			a.Value().document(item)
+		}
+		a.Close()
+	case ArrayNode:
+		a := jv.Array()
+		for _, item := range vv {
+			a.Value().document(item)
+		}
+		a.Close()
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isValidNumber reports whether s is a valid JSON number literal, per the
+// grammar of RFC 8259 section 6.
+func isValidNumber(s string) bool {
+	i := 0
+	digits := func() bool {
+		start := i
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+		return i > start
+	}
+
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	if i < len(s) && s[i] == '0' {
+		i++
+	} else if !digits() {
+		return false
+	}
+
+	if i < len(s) && s[i] == '.' {
+		i++
+		if !digits() {
+			return false
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if !digits() {
+			return false
+		}
+	}
+	return i == len(s)
+}
@@ -0,0 +1,159 @@
+package json
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/aws/smithy-go/document"
+)
+
+func TestValue_Document(t *testing.T) {
+	cases := map[string]struct {
+		value    interface{}
+		expected string
+	}{
+		"nil": {
+			value:    nil,
+			expected: `null`,
+		},
+		"scalars": {
+			value: []interface{}{
+				true, "foo", int8(-1), uint16(2), int64(-3), uint64(4), float32(1.5), 2.5,
+			},
+			expected: `[true,"foo",-1,2,-3,4,1.5,2.5]`,
+		},
+		"big numbers": {
+			value: []interface{}{
+				new(big.Int).Lsh(big.NewInt(1), 64),
+				big.NewFloat(0.5),
+				(*big.Int)(nil),
+				document.Number("1.23e-400"),
+			},
+			expected: `[18446744073709551616,5e-01,null,1.23e-400]`,
+		},
+		"object keys sorted": {
+			value: map[string]interface{}{
+				"b": []interface{}{},
+				"a": map[string]interface{}{"c": nil},
+			},
+			expected: `{"a":{"c":null},"b":[]}`,
+		},
+		"nil map and slice": {
+			value:    []interface{}{map[string]interface{}(nil), []interface{}(nil)},
+			expected: `[null,null]`,
+		},
+		"nodes": {
+			value: ObjectNode{
+				"n": NumberNode("9007199254740993"),
+				"l": ArrayNode{BoolNode(false), NullNode{}, StringNode("s")},
+			},
+			expected: `{"l":[false,null,"s"],"n":9007199254740993}`,
+		},
+		"raw message": {
+			value:    map[string]interface{}{"raw": RawMessage(`{"x":[1]}`)},
+			expected: `{"raw":{"x":[1]}}`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := NewEncoder()
+			if err := e.Value.Document(c.value); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.expected, e.String(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestValue_DocumentErrors(t *testing.T) {
+	cases := map[string]struct {
+		value       interface{}
+		unsupported bool
+	}{
+		"struct": {
+			value:       map[string]interface{}{"a": []interface{}{struct{}{}}},
+			unsupported: true,
+		},
+		"typed slice": {
+			value:       []string{"a"},
+			unsupported: true,
+		},
+		"empty key": {
+			value: map[string]interface{}{"": 1},
+		},
+		"invalid number": {
+			value: []interface{}{document.Number("1.")},
+		},
+		"NaN": {
+			value: map[string]interface{}{"a": math.NaN()},
+		},
+		"infinite float32": {
+			value: []interface{}{float32(math.Inf(-1))},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := NewEncoder()
+			err := e.Value.Document(c.value)
+			if err == nil {
+				t.Fatalf("expect error")
+			}
+
+			var unsupported *UnsupportedDocumentTypeError
+			if e, a := c.unsupported, errors.As(err, &unsupported); e != a {
+				t.Errorf("expect unsupported type error %v, got %v", e, err)
+			}
+			var invalid *document.InvalidMarshalError
+			if e, a := !c.unsupported, errors.As(err, &invalid); e != a {
+				t.Errorf("expect invalid marshal error %v, got %v", e, err)
+			}
+
+			if n := len(e.Bytes()); n != 0 {
+				t.Errorf("expect no output, got %q", e.Bytes())
+			}
+		})
+	}
+}
+
+func TestValue_DocumentNonFiniteFloatString(t *testing.T) {
+	e := NewEncoder(func(o *EncoderOptions) {
+		o.NonFiniteFloats = NonFiniteFloatString
+	})
+	if err := e.Value.Document([]interface{}{math.NaN(), float32(math.Inf(1))}); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := `["NaN","Infinity"]`, e.String(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
+
+func TestIsValidNumber(t *testing.T) {
+	cases := map[string]bool{
+		"0":       true,
+		"-0":      true,
+		"123":     true,
+		"1.5e+10": true,
+		"1E-2":    true,
+		"":        false,
+		"-":       false,
+		"01":      false,
+		"1.":      false,
+		".5":      false,
+		"1e":      false,
+		"1e+":     false,
+		"0x10":    false,
+		"NaN":     false,
+	}
+
+	for s, expected := range cases {
+		if e, a := expected, isValidNumber(s); e != a {
+			t.Errorf("%q: expect %v, got %v", s, e, a)
+		}
+	}
+}
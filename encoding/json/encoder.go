@@ -2,21 +2,105 @@ package json
 
 import (
 	"bytes"
+	"sync"
 )
 
+// maxPooledBufferSize is the largest buffer capacity that PutEncoder will
+// retain, so that a single large payload does not pin memory indefinitely.
+const maxPooledBufferSize = 64 * 1024
+
+var encoderPool = sync.Pool{
+	New: func() interface{} {
+		return NewEncoder()
+	},
+}
+
+// EncoderOptions is the set of options that can be configured for an Encoder.
+type EncoderOptions struct {
+	// Controls how NaN and infinite float values are encoded. Defaults to
+	// NonFiniteFloatPanic.
+	NonFiniteFloats NonFiniteFloatMode
+
+	// Controls how a key written to an Object more than once is handled.
+	// Defaults to DuplicateKeyAllow.
+	DuplicateKeys DuplicateKeyMode
+
+	// Escape the HTML characters <, >, and & in strings as \u003c, \u003e,
+	// and \u0026, such that output can be safely embedded in HTML.
+	EscapeHTML bool
+
+	// Do not escape U+2028 LINE SEPARATOR and U+2029 PARAGRAPH SEPARATOR in
+	// strings. They are escaped by default since they are not valid in
+	// JavaScript string literals prior to ES2019.
+	DisableLineSeparatorEscaping bool
+
+	// Escape all non-ASCII characters in strings as \uXXXX, using UTF-16
+	// surrogate pairs for characters outside the Basic Multilingual Plane.
+	EscapeNonASCII bool
+
+	// If set, each element of an Object or Array is written on a new line,
+	// indented by one copy of Indent per level of nesting, e.g. "  " or
+	// "\t". Values written with WriteRaw are not re-indented. Intended for
+	// human-readable output such as debug logging; protocol serializers
+	// should leave this unset.
+	Indent string
+}
+
+// encoderState is shared by the values of a single Encoder.
+type encoderState struct {
+	options EncoderOptions
+	err     error
+
+	// nesting level of the Object or Array being written
+	depth int
+}
+
+func (s *encoderState) writeIndent(w *bytes.Buffer) {
+	if len(s.options.Indent) == 0 {
+		return
+	}
+
+	w.WriteByte('\n')
+	for i := 0; i < s.depth; i++ {
+		w.WriteString(s.options.Indent)
+	}
+}
+
+func (s *encoderState) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
 // Encoder is JSON encoder that supports construction of JSON values
 // using methods.
 type Encoder struct {
-	w *bytes.Buffer
+	w     *bytes.Buffer
+	state *encoderState
 	Value
 }
 
 // NewEncoder returns a new JSON encoder
-func NewEncoder() *Encoder {
+func NewEncoder(optFns ...func(*EncoderOptions)) *Encoder {
+	var o EncoderOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
 	writer := bytes.NewBuffer(nil)
 	scratch := make([]byte, 64)
+	state := &encoderState{options: o}
+
+	return &Encoder{w: writer, state: state, Value: newValue(writer, &scratch, state)}
+}
 
-	return &Encoder{w: writer, Value: newValue(writer, &scratch)}
+// Err returns the first error encountered while encoding, if any. Errors are
+// only recorded for values the Encoder was configured to reject, e.g. with
+// NonFiniteFloatError or DuplicateKeyReject, and for readers that failed to be read from, e.g. by
+// Base64EncodeReader. The output of an Encoder with an error is still
+// well-formed JSON, but should not be used.
+func (e *Encoder) Err() error {
+	return e.state.err
 }
 
 // String returns the String output of the JSON encoder
@@ -28,3 +112,29 @@ func (e Encoder) String() string {
 func (e Encoder) Bytes() []byte {
 	return e.w.Bytes()
 }
+
+// Reset discards the output and any error of the encoder, retaining its
+// underlying buffer for reuse.
+func (e *Encoder) Reset() {
+	e.w.Reset()
+	e.state.err = nil
+	e.state.depth = 0
+}
+
+// GetEncoder returns an empty Encoder from a shared pool. Callers should
+// return the Encoder with PutEncoder once its output is no longer needed.
+func GetEncoder() *Encoder {
+	return encoderPool.Get().(*Encoder)
+}
+
+// PutEncoder resets e and returns it to the pool used by GetEncoder. Neither e
+// nor any slice previously returned by its Bytes method may be used after
+// calling PutEncoder. Encoders configured with options are not pooled.
+func PutEncoder(e *Encoder) {
+	if e.w.Cap() > maxPooledBufferSize || e.state.options != (EncoderOptions{}) {
+		return
+	}
+
+	e.Reset()
+	encoderPool.Put(e)
+}
@@ -2,6 +2,8 @@ package json_test
 
 import (
 	"bytes"
+	"errors"
+	"math"
 	"testing"
 
 	"github.com/aws/smithy-go/encoding/json"
@@ -32,3 +34,118 @@ func TestEncoder(t *testing.T) {
 		t.Errorf("expected %s, but got %s", e, a)
 	}
 }
+
+func TestEncoder_NonFiniteFloats(t *testing.T) {
+	cases := map[string]struct {
+		mode      json.NonFiniteFloatMode
+		expected  string
+		expectErr bool
+	}{
+		"string": {
+			mode:     json.NonFiniteFloatString,
+			expected: `["NaN","Infinity","-Infinity","-Infinity",1.5]`,
+		},
+		"error": {
+			mode:      json.NonFiniteFloatError,
+			expected:  `[null,null,null,null,1.5]`,
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder := json.NewEncoder(func(o *json.EncoderOptions) {
+				o.NonFiniteFloats = tt.mode
+			})
+
+			array := encoder.Array()
+			array.Value().Double(math.NaN())
+			array.Value().Double(math.Inf(1))
+			array.Value().Double(math.Inf(-1))
+			array.Value().Float(float32(math.Inf(-1)))
+			array.Value().Double(1.5)
+			array.Close()
+
+			if e, a := tt.expected, encoder.String(); e != a {
+				t.Errorf("expected %s, but got %s", e, a)
+			}
+
+			var ferr *json.InvalidFloatError
+			if err := encoder.Err(); tt.expectErr != errors.As(err, &ferr) {
+				t.Fatalf("expect error %v, got %v", tt.expectErr, err)
+			}
+			if tt.expectErr && !math.IsNaN(ferr.Value) {
+				t.Errorf("expect first error to be recorded, got %v", ferr.Value)
+			}
+
+			encoder.Reset()
+			if err := encoder.Err(); err != nil {
+				t.Errorf("expect no error after reset, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEncoder_NonFiniteFloatPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expect panic")
+		}
+	}()
+
+	json.NewEncoder().Double(math.NaN())
+}
+
+func TestEncoder_EscapeOptions(t *testing.T) {
+	encoder := json.NewEncoder(func(o *json.EncoderOptions) {
+		o.EscapeHTML = true
+		o.EscapeNonASCII = true
+	})
+
+	object := encoder.Object()
+	object.Key("<key>").String("café & co")
+	array := object.Key("list").Array()
+	array.Value().String("ü")
+	array.Close()
+	object.Close()
+
+	e := `{"\u003ckey\u003e":"caf\u00e9 \u0026 co","list":["\u00fc"]}`
+	if a := encoder.String(); e != a {
+		t.Errorf("expected %s, but got %s", e, a)
+	}
+}
+
+func TestEncoder_Indent(t *testing.T) {
+	encoder := json.NewEncoder(func(o *json.EncoderOptions) {
+		o.Indent = "  "
+	})
+
+	object := encoder.Object()
+	object.Key("string").String("foo")
+	list := object.Key("list").Array()
+	list.Value().Long(1)
+	nested := list.Value().Object()
+	nested.Key("bool").Boolean(true)
+	nested.Close()
+	list.Close()
+	object.Key("emptyObject").Object().Close()
+	object.Key("emptyArray").Array().Close()
+	object.WriteRaw("raw", json.RawMessage(`{"a":1}`))
+	object.Close()
+
+	e := `{
+  "string": "foo",
+  "list": [
+    1,
+    {
+      "bool": true
+    }
+  ],
+  "emptyObject": {},
+  "emptyArray": [],
+  "raw": {"a":1}
+}`
+	if a := encoder.String(); e != a {
+		t.Errorf("expected\n%s\nbut got\n%s", e, a)
+	}
+}
@@ -8,6 +8,8 @@ package json
 
 import (
 	"bytes"
+	"math/bits"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
@@ -119,36 +121,125 @@ var safeSet = [utf8.RuneSelf]bool{
 // copied from Go 1.8 stdlib's encoding/json/#hex
 var hex = "0123456789abcdef"
 
-// escapeStringBytes escapes and writes the passed in string bytes to the dst
-// buffer
+// Escape table entries, classifying each byte of a string being encoded.
+const (
+	escapeNone = 0 // written as is
+
+	// the first byte of a multi-byte UTF-8 sequence, or an invalid byte,
+	// which must be decoded to determine whether it is escaped
+	escapeRune = 1
+
+	// any other value is the character following the backslash of the
+	// escape sequence, e.g. 'n' for a newline, with 'u' for \u00XX
+)
+
+// escapeTable and htmlEscapeTable classify each byte of a string being
+// encoded, the latter for when EncoderOptions.EscapeHTML is set. They are
+// derived from safeSet.
+var escapeTable, htmlEscapeTable [256]byte
+
+func init() {
+	for b := 0; b < len(escapeTable); b++ {
+		var v byte
+		switch {
+		case b >= utf8.RuneSelf:
+			v = escapeRune
+		case safeSet[b]:
+			v = escapeNone
+		case b == '\\' || b == '"':
+			v = byte(b)
+		case b == '\n':
+			v = 'n'
+		case b == '\r':
+			v = 'r'
+		case b == '\t':
+			v = 't'
+		default:
+			v = 'u'
+		}
+
+		escapeTable[b] = v
+		htmlEscapeTable[b] = v
+		if isHTMLChar(byte(b)) {
+			htmlEscapeTable[b] = 'u'
+		}
+	}
+}
+
+// isHTMLChar returns whether b is one of the ASCII characters escaped by
+// EncoderOptions.EscapeHTML.
+func isHTMLChar(b byte) bool {
+	return b == '<' || b == '>' || b == '&'
+}
+
+const (
+	lsb = 0x0101010101010101
+	msb = 0x8080808080808080
+)
+
+// escapeCandidates returns a mask with the high bit set in the lane of each
+// byte of the 8 starting at s[i] that may need escaping, i.e. is a control
+// character, '"', '\\', not ASCII, or one of the HTML characters if html is
+// set. The 8 bytes are tested at once as the little-endian lanes of a uint64.
+//
+// Lanes above the first flagged one may be false positives, due to borrows in
+// the subtractions, but the lowest set bit always marks the first byte that
+// does need attention.
+func escapeCandidates(s string, i int, html bool) uint64 {
+	x := uint64(s[i]) | uint64(s[i+1])<<8 | uint64(s[i+2])<<16 | uint64(s[i+3])<<24 |
+		uint64(s[i+4])<<32 | uint64(s[i+5])<<40 | uint64(s[i+6])<<48 | uint64(s[i+7])<<56
+
+	m := x | (x-lsb*0x20)&^x | hasZeroByte(x^(lsb*'"')) | hasZeroByte(x^(lsb*'\\'))
+	if html {
+		m |= hasZeroByte(x^(lsb*'<')) | hasZeroByte(x^(lsb*'>')) | hasZeroByte(x^(lsb*'&'))
+	}
+	return m & msb
+}
+
+func hasZeroByte(x uint64) uint64 {
+	return (x - lsb) &^ x
+}
+
+// escapeString escapes and writes the passed in string to the dst buffer,
+// according to the escaping options of o.
+//
+// Runs of bytes that need no escaping are found 8 bytes at a time with
+// escapeCandidates, and copied in bulk.
 //
 // Copied and modifed from Go 1.8 stdlib's encodeing/json/#encodeState.stringBytes
-func escapeStringBytes(e *bytes.Buffer, s []byte) {
+func escapeString(e *bytes.Buffer, s string, o EncoderOptions) {
+	table := &escapeTable
+	if o.EscapeHTML {
+		table = &htmlEscapeTable
+	}
+
 	e.WriteByte('"')
 	start := 0
 	for i := 0; i < len(s); {
-		if b := s[i]; b < utf8.RuneSelf {
-			if safeSet[b] {
-				i++
-				continue
+		// runs of non-ASCII text are left to the per-rune path below
+		for i+8 <= len(s) && s[i] < utf8.RuneSelf {
+			if m := escapeCandidates(s, i, o.EscapeHTML); m != 0 {
+				i += bits.TrailingZeros64(m) / 8
+				break
 			}
+			i += 8
+		}
+		if i >= len(s) {
+			break
+		}
+
+		b := s[i]
+		esc := table[b]
+		if esc == escapeNone {
+			i++
+			continue
+		}
+		if esc != escapeRune {
 			if start < i {
-				e.Write(s[start:i])
+				e.WriteString(s[start:i])
 			}
-			switch b {
-			case '\\', '"':
-				e.WriteByte('\\')
-				e.WriteByte(b)
-			case '\n':
-				e.WriteByte('\\')
-				e.WriteByte('n')
-			case '\r':
-				e.WriteByte('\\')
-				e.WriteByte('r')
-			case '\t':
-				e.WriteByte('\\')
-				e.WriteByte('t')
-			default:
+			switch esc {
+			case 'u':
 				// This encodes bytes < 0x20 except for \t, \n and \r.
 				// If escapeHTML is set, it also escapes <, >, and &
 				// because they can lead to security holes when
@@ -157,15 +248,19 @@ func escapeStringBytes(e *bytes.Buffer, s []byte) {
 				e.WriteString(`\u00`)
 				e.WriteByte(hex[b>>4])
 				e.WriteByte(hex[b&0xF])
+			default:
+				e.WriteByte('\\')
+				e.WriteByte(esc)
 			}
 			i++
 			start = i
 			continue
 		}
-		c, size := utf8.DecodeRune(s[i:])
+
+		c, size := utf8.DecodeRuneInString(s[i:])
 		if c == utf8.RuneError && size == 1 {
 			if start < i {
-				e.Write(s[start:i])
+				e.WriteString(s[start:i])
 			}
 			e.WriteString(`\ufffd`)
 			i += size
@@ -179,9 +274,9 @@ func escapeStringBytes(e *bytes.Buffer, s []byte) {
 		// and can lead to security holes there. It is valid JSON to
 		// escape them, so we do so unconditionally.
 		// See http://timelessrepo.com/json-isnt-a-javascript-subset for discussion.
-		if c == '\u2028' || c == '\u2029' {
+		if (c == '\u2028' || c == '\u2029') && !o.DisableLineSeparatorEscaping {
 			if start < i {
-				e.Write(s[start:i])
+				e.WriteString(s[start:i])
 			}
 			e.WriteString(`\u202`)
 			e.WriteByte(hex[c&0xF])
@@ -189,10 +284,34 @@ func escapeStringBytes(e *bytes.Buffer, s []byte) {
 			start = i
 			continue
 		}
+		if o.EscapeNonASCII {
+			if start < i {
+				e.WriteString(s[start:i])
+			}
+			if c > 0xFFFF {
+				r1, r2 := utf16.EncodeRune(c)
+				writeUnicodeEscape(e, r1)
+				writeUnicodeEscape(e, r2)
+			} else {
+				writeUnicodeEscape(e, c)
+			}
+			i += size
+			start = i
+			continue
+		}
 		i += size
 	}
 	if start < len(s) {
-		e.Write(s[start:])
+		e.WriteString(s[start:])
 	}
 	e.WriteByte('"')
 }
+
+// writeUnicodeEscape writes the \uXXXX escape of the UTF-16 code unit r.
+func writeUnicodeEscape(e *bytes.Buffer, r rune) {
+	e.WriteString(`\u`)
+	e.WriteByte(hex[r>>12&0xF])
+	e.WriteByte(hex[r>>8&0xF])
+	e.WriteByte(hex[r>>4&0xF])
+	e.WriteByte(hex[r&0xF])
+}
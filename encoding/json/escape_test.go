@@ -2,13 +2,17 @@ package json
 
 import (
 	"bytes"
+	"strings"
 	"testing"
+
+	"github.com/aws/smithy-go/internal/testutil"
 )
 
-func TestEscapeStringBytes(t *testing.T) {
+func TestEscapeString(t *testing.T) {
 	cases := map[string]struct {
 		expected string
 		input    []byte
+		options  EncoderOptions
 	}{
 		"safeSet only": {
 			expected: `"mountainPotato"`,
@@ -34,11 +38,38 @@ func TestEscapeStringBytes(t *testing.T) {
 			expected: `"foo\tbar"`,
 			input:    []byte("foo\tbar"),
 		},
+		"html": {
+			expected: `"<a href=\"?x&y\">"`,
+			input:    []byte(`<a href="?x&y">`),
+		},
+		"escape html": {
+			expected: `"\u003ca href=\"?x\u0026y\"\u003e"`,
+			input:    []byte(`<a href="?x&y">`),
+			options:  EncoderOptions{EscapeHTML: true},
+		},
+		"line separators": {
+			expected: `"a\u2028b\u2029c"`,
+			input:    []byte("a\u2028b\u2029c"),
+		},
+		"disable line separator escaping": {
+			expected: "\"a\u2028b\u2029c\"",
+			input:    []byte("a\u2028b\u2029c"),
+			options:  EncoderOptions{DisableLineSeparatorEscaping: true},
+		},
+		"non-ascii": {
+			expected: "\"caf\u00e9 \U0001F600\"",
+			input:    []byte("caf\u00e9 \U0001F600"),
+		},
+		"escape non-ascii": {
+			expected: `"caf\u00e9 \ud83d\ude00 \u2028 \ufffd"`,
+			input:    []byte("caf\u00e9 \U0001F600 \u2028 \xff"),
+			options:  EncoderOptions{EscapeNonASCII: true, DisableLineSeparatorEscaping: true},
+		},
 	}
 	for name, c := range cases {
 		t.Run(name, func(t *testing.T) {
 			var buffer bytes.Buffer
-			escapeStringBytes(&buffer, c.input)
+			escapeString(&buffer, string(c.input), c.options)
 			expected := c.expected
 			actual := buffer.String()
 			if expected != actual {
@@ -47,3 +78,99 @@ func TestEscapeStringBytes(t *testing.T) {
 		})
 	}
 }
+
+// stringCase is a single entry in testdata/strings.json, the golden corpus of
+// JSON string encodings shared with other runtimes.
+type stringCase struct {
+	ID       string         `json:"id"`
+	Input    string         `json:"input"`
+	Expected string         `json:"expected"`
+	Options  EncoderOptions `json:"options"`
+}
+
+func TestEscapeString_Corpus(t *testing.T) {
+	var cases []stringCase
+	testutil.LoadCorpus(t, "testdata/strings.json").Decode(t, &cases)
+
+	for _, c := range cases {
+		t.Run(c.ID, func(t *testing.T) {
+			var buffer bytes.Buffer
+			escapeString(&buffer, c.Input, c.Options)
+			if e, a := c.Expected, buffer.String(); e != a {
+				t.Errorf("expected %q, actual %q", e, a)
+			}
+		})
+	}
+}
+
+var benchmarkStrings = map[string]string{
+	"short":   "stringValue",
+	"ascii":   strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100),
+	"escapes": strings.Repeat("line \"one\"\n\tline two\\ ", 100),
+	"unicode": strings.Repeat("Übergrößenträger – 日本語のテキスト ", 100),
+}
+
+func BenchmarkEscapeString(b *testing.B) {
+	for name, s := range benchmarkStrings {
+		b.Run(name, func(b *testing.B) {
+			e := NewEncoder()
+			b.ReportAllocs()
+			b.SetBytes(int64(len(s)))
+			for i := 0; i < b.N; i++ {
+				e.Reset()
+				e.Value.String(s)
+			}
+		})
+	}
+}
+
+// TestEscapeString_WordBoundaries places each byte value, alone and in pairs
+// with other bytes that need escaping, at each position of a string long
+// enough to be scanned a word at a time, comparing with the byte escaped on
+// its own.
+func TestEscapeString_WordBoundaries(t *testing.T) {
+	escapeByte := func(b byte, o EncoderOptions) string {
+		var buffer bytes.Buffer
+		escapeString(&buffer, string([]byte{b}), o)
+		return buffer.String()[1 : buffer.Len()-1]
+	}
+
+	const n = 19
+	for _, o := range []EncoderOptions{{}, {EscapeHTML: true}} {
+		for b := 0; b < 256; b++ {
+			for i := 0; i < n; i++ {
+				input := []byte(strings.Repeat("a", n))
+				input[i] = byte(b)
+				expected := `"` + strings.Repeat("a", i) + escapeByte(byte(b), o) + strings.Repeat("a", n-i-1) + `"`
+
+				var buffer bytes.Buffer
+				escapeString(&buffer, string(input), o)
+				if e, a := expected, buffer.String(); e != a {
+					t.Fatalf("byte 0x%02x at %d, %+v: expected %q, actual %q", b, i, o, e, a)
+				}
+			}
+		}
+
+		for _, pair := range []string{`"\`, "\n\"", `\<`, "\x00\x1f", "&>"} {
+			for i := 0; i < n; i++ {
+				for j := i + 1; j < n; j++ {
+					input := []byte(strings.Repeat("a", n))
+					input[i], input[j] = pair[0], pair[1]
+
+					var expected strings.Builder
+					expected.WriteByte('"')
+					for _, b := range input {
+						expected.WriteString(escapeByte(b, o))
+					}
+					expected.WriteByte('"')
+
+					var buffer bytes.Buffer
+					escapeString(&buffer, string(input), o)
+					if e, a := expected.String(), buffer.String(); e != a {
+						t.Fatalf("%q at %d and %d, %+v: expected %q, actual %q", pair, i, j, o, e, a)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package json
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// NonFiniteFloatMode controls how an Encoder encodes NaN and infinite float
+// values, which have no representation as a JSON number.
+type NonFiniteFloatMode int
+
+// Enumeration values for NonFiniteFloatMode.
+const (
+	// Panic when a non-finite float is encoded.
+	NonFiniteFloatPanic NonFiniteFloatMode = iota
+
+	// Encode non-finite floats as the JSON strings "NaN", "Infinity", and
+	// "-Infinity", as specified by the Smithy JSON protocols.
+	NonFiniteFloatString
+
+	// Encode non-finite floats as null, and record an InvalidFloatError to be
+	// returned by Encoder.Err.
+	NonFiniteFloatError
+)
+
+// InvalidFloatError is returned by Encoder.Err when a non-finite float was
+// encoded with NonFiniteFloatError.
+type InvalidFloatError struct {
+	Value float64
+}
+
+func (e *InvalidFloatError) Error() string {
+	return fmt.Sprintf("json: unsupported float value %s", strconv.FormatFloat(e.Value, 'g', -1, 64))
+}
+
+func nonFiniteFloatString(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return floatNaN
+	case math.IsInf(v, 1):
+		return floatInfinity
+	default:
+		return floatNegInfinity
+	}
+}
@@ -0,0 +1,136 @@
+package jsonlines
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/smithy-go/encoding/json"
+)
+
+const defaultMaxLineSize = 1024 * 1024
+
+// ReaderOptions is the set of options that can be configured for a Reader.
+type ReaderOptions struct {
+	// The maximum length of a single line, in bytes, excluding the line
+	// terminator. Longer lines fail with a LineTooLongError. Defaults to
+	// 1MiB.
+	MaxLineSize int
+}
+
+// LineTooLongError is returned by a Reader when a line exceeds the configured
+// MaxLineSize.
+type LineTooLongError struct {
+	Line        int
+	MaxLineSize int
+}
+
+func (e *LineTooLongError) Error() string {
+	return fmt.Sprintf("jsonlines: line %d exceeds max size of %d bytes", e.Line, e.MaxLineSize)
+}
+
+// Reader reads JSON values from an underlying io.Reader, one per line.
+//
+// Values are yielded as soon as their line terminator is read, regardless of
+// how the underlying reader splits the stream, so a Reader may be used on a
+// response body that is still being received. Blank lines are skipped, "\r\n"
+// terminators are accepted, and the final line need not be terminated.
+//
+// A Reader is not safe for concurrent use.
+type Reader struct {
+	options ReaderOptions
+
+	r    *bufio.Reader
+	line int
+	err  error
+}
+
+// NewReader returns a Reader that reads from r.
+func NewReader(r io.Reader, optFns ...func(*ReaderOptions)) *Reader {
+	o := ReaderOptions{
+		MaxLineSize: defaultMaxLineSize,
+	}
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	return &Reader{
+		options: o,
+		r:       bufio.NewReader(r),
+	}
+}
+
+// Line returns the number of the line most recently read, starting at 1.
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Next returns the encoded JSON value on the next non-blank line, with its
+// line terminator removed. The value is not validated. Next returns io.EOF
+// once the input is exhausted.
+//
+// The returned slice is owned by the caller.
+func (r *Reader) Next() ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			r.err = err
+			return nil, err
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) != 0 {
+			return line, nil
+		}
+	}
+}
+
+// Decode returns the JSON value on the next non-blank line as a json.Node.
+// Decode returns io.EOF once the input is exhausted.
+func (r *Reader) Decode() (json.Node, error) {
+	line, err := r.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	// each line must hold exactly one value
+	n, err := json.DecodeNode(bytes.NewReader(line), func(o *json.DecoderOptions) {
+		o.DisallowTrailingData = true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("jsonlines: decode line %d: %w", r.line, err)
+	}
+	return n, nil
+}
+
+func (r *Reader) readLine() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := r.r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(bytes.TrimRight(line, "\r\n")) > r.options.MaxLineSize {
+			return nil, &LineTooLongError{Line: r.line + 1, MaxLineSize: r.options.MaxLineSize}
+		}
+
+		switch {
+		case err == nil:
+			r.line++
+			return line, nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case err == io.EOF && len(line) != 0:
+			r.line++
+			return line, nil
+		case err == io.EOF:
+			return nil, io.EOF
+		default:
+			return nil, fmt.Errorf("jsonlines: read: %w", err)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package jsonlines
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aws/smithy-go/encoding/json"
+)
+
+func readAll(t *testing.T, r *Reader) []string {
+	t.Helper()
+
+	var values []string
+	for {
+		p, err := r.Next()
+		if err == io.EOF {
+			return values
+		}
+		if err != nil {
+			t.Fatalf("line %d: %v", r.Line(), err)
+		}
+		values = append(values, string(p))
+	}
+}
+
+func TestReader_Next(t *testing.T) {
+	cases := map[string]struct {
+		input    string
+		expected []string
+	}{
+		"empty": {},
+		"single": {
+			input:    "{\"a\":1}\n",
+			expected: []string{`{"a":1}`},
+		},
+		"unterminated final line": {
+			input:    "1\n2",
+			expected: []string{"1", "2"},
+		},
+		"crlf": {
+			input:    "1\r\n2\r\n",
+			expected: []string{"1", "2"},
+		},
+		"blank lines": {
+			input:    "\n1\n\n  \n2\n\n",
+			expected: []string{"1", "2"},
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := readAll(t, NewReader(strings.NewReader(tt.input)))
+			if !reflect.DeepEqual(tt.expected, actual) {
+				t.Errorf("expect %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReader_PartialReads(t *testing.T) {
+	long := `"` + strings.Repeat("x", 10000) + `"`
+	input := "[1,2]\n" + long + "\n{}\n"
+
+	actual := readAll(t, NewReader(iotest.OneByteReader(strings.NewReader(input))))
+	if expected := []string{"[1,2]", long, "{}"}; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("values mismatch")
+	}
+}
+
+func TestReader_Incremental(t *testing.T) {
+	pr, pw := io.Pipe()
+	r := NewReader(pr)
+
+	go pw.Write([]byte("1\n2"))
+
+	// the first value is available before the rest of the stream is written
+	p, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := "1", string(p); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+
+	go func() {
+		pw.Write([]byte("3\n"))
+		pw.Close()
+	}()
+
+	p, err = r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := "23", string(p); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expect EOF, got %v", err)
+	}
+}
+
+func TestReader_MaxLineSize(t *testing.T) {
+	r := NewReader(strings.NewReader("1234\r\n12345\n"), func(o *ReaderOptions) {
+		o.MaxLineSize = 4
+	})
+
+	if p, err := r.Next(); err != nil || string(p) != "1234" {
+		t.Fatalf("expect 1234, got %q, %v", p, err)
+	}
+
+	_, err := r.Next()
+	var lerr *LineTooLongError
+	if !errors.As(err, &lerr) {
+		t.Fatalf("expect %T, got %v", lerr, err)
+	}
+	if e, a := 2, lerr.Line; e != a {
+		t.Errorf("expect line %d, got %d", e, a)
+	}
+
+	// errors are sticky
+	if _, err := r.Next(); !errors.As(err, &lerr) {
+		t.Errorf("expect %T, got %v", lerr, err)
+	}
+}
+
+func TestReader_Decode(t *testing.T) {
+	r := NewReader(strings.NewReader("{\"a\":[true]}\n\"b\"\n{\"c\":\n"))
+
+	n, err := r.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := (json.ObjectNode{"a": json.ArrayNode{json.BoolNode(true)}}), n; !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	n, err = r.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := json.StringNode("b"), n; e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	if _, err := r.Decode(); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expect line 3 decode error, got %v", err)
+	}
+}
+
+func TestReader_DecodeMultipleValues(t *testing.T) {
+	r := NewReader(strings.NewReader("1 2\n"))
+
+	if _, err := r.Decode(); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("expect line 1 decode error, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+// Package jsonlines implements framing of JSON values for streaming
+// operations in the JSON Lines (a.k.a. newline-delimited JSON, or NDJSON)
+// format, in which each value is encoded on a single line terminated by "\n".
+package jsonlines
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/aws/smithy-go/encoding/json"
+)
+
+// Writer writes JSON values to an underlying io.Writer, one per line.
+//
+// A Writer is not safe for concurrent use.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a Writer that writes to w.
+//
+// Each value is written to w with a single call to its Write method, so
+// callers streaming values to a network connection may wish to wrap w with a
+// bufio.Writer.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write writes the encoded JSON value p followed by a newline. p must not
+// contain a newline, i.e. it must be compactly encoded.
+func (w *Writer) Write(p []byte) error {
+	if len(p) == 0 {
+		return fmt.Errorf("jsonlines: empty value")
+	}
+	if bytes.IndexByte(p, '\n') != -1 {
+		return fmt.Errorf("jsonlines: value contains a newline")
+	}
+
+	line := make([]byte, 0, len(p)+1)
+	line = append(line, p...)
+	line = append(line, '\n')
+	if _, err := w.w.Write(line); err != nil {
+		return fmt.Errorf("jsonlines: write: %w", err)
+	}
+	return nil
+}
+
+// Encode writes the value built by fn with a pooled json.Encoder, followed by
+// a newline. Nothing is written if the encoder records an error, e.g. of a
+// reader passed to Base64EncodeReader, which is returned instead.
+func (w *Writer) Encode(fn func(json.Value)) error {
+	e := json.GetEncoder()
+	defer json.PutEncoder(e)
+
+	fn(e.Value)
+	if err := e.Err(); err != nil {
+		return fmt.Errorf("jsonlines: encode: %w", err)
+	}
+	return w.Write(e.Bytes())
+}
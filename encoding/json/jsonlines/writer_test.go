@@ -0,0 +1,76 @@
+package jsonlines
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aws/smithy-go/encoding/json"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	if err := w.Write([]byte(`{"a":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Encode(func(v json.Value) {
+		o := v.Object()
+		o.Key("b").String("line\nbreak")
+		o.Close()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Encode(func(v json.Value) { v.Null() }); err != nil {
+		t.Fatal(err)
+	}
+
+	if e, a := "{\"a\":1}\n{\"b\":\"line\\nbreak\"}\nnull\n", buf.String(); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+}
+
+func TestWriter_EncodeErr(t *testing.T) {
+	var buf bytes.Buffer
+	readErr := errors.New("read failed")
+	err := NewWriter(&buf).Encode(func(v json.Value) {
+		v.Base64EncodeReader(iotest.ErrReader(readErr))
+	})
+	if !errors.Is(err, readErr) {
+		t.Errorf("expect %v, got %v", readErr, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expect nothing written, got %q", buf.String())
+	}
+}
+
+func TestWriter_Invalid(t *testing.T) {
+	cases := map[string]struct {
+		input string
+		err   string
+	}{
+		"empty": {
+			err: "empty value",
+		},
+		"newline": {
+			input: "{\n}",
+			err:   "contains a newline",
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := NewWriter(&buf).Write([]byte(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expect error containing %q, got %v", tt.err, err)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("expect nothing written, got %q", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package json
+
+// MergePatch returns the result of applying the JSON merge patch to target,
+// as defined by RFC 7386.
+//
+// If patch is an object, each of its members is merged into target
+// recursively, with a null member removing the member from target. A target
+// that is not an object is replaced by an empty object before merging. Any
+// other patch replaces target entirely.
+//
+// Neither target nor patch is modified, though the result shares the nodes
+// of both that were not changed by the merge.
+func MergePatch(target, patch Node) Node {
+	p, ok := patch.(ObjectNode)
+	if !ok {
+		return patch
+	}
+
+	t, _ := target.(ObjectNode)
+	result := make(ObjectNode, len(t)+len(p))
+	for k, v := range t {
+		result[k] = v
+	}
+	for k, v := range p {
+		if _, ok := v.(NullNode); ok {
+			delete(result, k)
+			continue
+		}
+		result[k] = MergePatch(result[k], v)
+	}
+	return result
+}
@@ -0,0 +1,142 @@
+package json
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+)
+
+// Node describes a decoded JSON value.
+//
+// The following types implement Node:
+//   - [ObjectNode]
+//   - [ArrayNode]
+//   - [StringNode]
+//   - [NumberNode]
+//   - [BoolNode]
+//   - [NullNode]
+//
+// The tree types are named Node to distinguish them from the encoder API,
+// e.g. an ObjectNode is the result of decoding what an Object encodes.
+type Node interface {
+	isNode()
+}
+
+var (
+	_ Node = ObjectNode(nil)
+	_ Node = ArrayNode(nil)
+	_ Node = StringNode("")
+	_ Node = NumberNode("0")
+	_ Node = BoolNode(false)
+	_ Node = NullNode{}
+)
+
+// ObjectNode describes a JSON object.
+type ObjectNode map[string]Node
+
+// UnknownMemberError is returned by ObjectNode.CheckMembers for a member that
+// is not one of the known members of the object.
+type UnknownMemberError struct {
+	Name string
+}
+
+func (e *UnknownMemberError) Error() string {
+	return fmt.Sprintf("unknown object member %q", e.Name)
+}
+
+// CheckMembers returns an UnknownMemberError if the object contains a member
+// not named in known, for deserializers that reject unknown members. Members
+// are checked in lexical order, such that the error is deterministic.
+func (o ObjectNode) CheckMembers(known ...string) error {
+	names := make([]string, 0, len(o))
+	for name := range o {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !containsString(known, name) {
+			return &UnknownMemberError{Name: name}
+		}
+	}
+	return nil
+}
+
+func containsString(vs []string, v string) bool {
+	for _, s := range vs {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ArrayNode describes a JSON array.
+type ArrayNode []Node
+
+// StringNode describes a JSON string.
+type StringNode string
+
+// NumberNode describes a JSON number.
+//
+// The number is kept as the raw literal read from the input, such that values
+// outside the range or precision of the native Go numeric types are not lost.
+// The typed accessors convert the literal on demand.
+type NumberNode string
+
+// String returns the number literal.
+func (n NumberNode) String() string {
+	return string(n)
+}
+
+// Int64 returns the number as an int64.
+func (n NumberNode) Int64() (int64, error) {
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// Uint64 returns the number as a uint64.
+func (n NumberNode) Uint64() (uint64, error) {
+	return strconv.ParseUint(string(n), 10, 64)
+}
+
+// Float64 returns the number as a float64.
+func (n NumberNode) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// BigInteger returns the number as a big.Int. Returns an error if the number
+// is not integral.
+func (n NumberNode) BigInteger() (*big.Int, error) {
+	i, ok := new(big.Int).SetString(string(n), 10)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert %s to big.Int", n)
+	}
+	return i, nil
+}
+
+// BigDecimal returns the number as a big.Float. The precision of the result
+// is sized to the number of digits in the literal rather than the big.Float
+// default of 64 bits.
+func (n NumberNode) BigDecimal() (*big.Float, error) {
+	// ~3.33 bits per decimal digit, rounded up
+	prec := uint(len(n))*4 + 64
+	f, _, err := big.ParseFloat(string(n), 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s to big.Float: %w", n, err)
+	}
+	return f, nil
+}
+
+// BoolNode describes a JSON boolean.
+type BoolNode bool
+
+// NullNode is the JSON null literal.
+type NullNode struct{}
+
+func (ObjectNode) isNode() {}
+func (ArrayNode) isNode()  {}
+func (StringNode) isNode() {}
+func (NumberNode) isNode() {}
+func (BoolNode) isNode()   {}
+func (NullNode) isNode()   {}
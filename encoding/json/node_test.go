@@ -0,0 +1,64 @@
+package json
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNumberNode(t *testing.T) {
+	n := NumberNode("9007199254740993")
+	if i, err := n.Int64(); err != nil || i != 9007199254740993 {
+		t.Errorf("expect exact int64, got %v, %v", i, err)
+	}
+	if u, err := n.Uint64(); err != nil || u != 9007199254740993 {
+		t.Errorf("expect exact uint64, got %v, %v", u, err)
+	}
+	if f, err := n.Float64(); err != nil || f != 9007199254740992 {
+		t.Errorf("expect rounded float64, got %v, %v", f, err)
+	}
+
+	n = NumberNode("-123456789012345678901234567890")
+	if _, err := n.Int64(); err == nil {
+		t.Errorf("expect int64 out of range error")
+	}
+	bi, err := n.BigInteger()
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := string(n), bi.String(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	n = NumberNode("1.2345678901234567890123456789e-5")
+	bd, err := n.BigDecimal()
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "1.2345678901234567890123456789e-05", bd.Text('e', 28); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if _, err := n.BigInteger(); err == nil {
+		t.Errorf("expect big.Int error for non-integral number")
+	}
+
+	if _, err := NumberNode("abc").BigDecimal(); err == nil {
+		t.Errorf("expect error for invalid literal")
+	}
+}
+
+func TestObjectNode_CheckMembers(t *testing.T) {
+	o := ObjectNode{"a": NullNode{}, "b": NullNode{}, "c": NullNode{}}
+
+	if err := o.CheckMembers("a", "b", "c", "d"); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	err := o.CheckMembers("a")
+	var unknown *UnknownMemberError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("expect UnknownMemberError, got %v", err)
+	}
+	if e, a := "b", unknown.Name; e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+}
@@ -4,37 +4,98 @@ import (
 	"bytes"
 )
 
+// DuplicateKeyMode controls how an Encoder handles a key written to an Object
+// more than once, which is typically a bug in the caller.
+type DuplicateKeyMode int
+
+// Enumeration values for DuplicateKeyMode.
+const (
+	// Write every key, including duplicates.
+	DuplicateKeyAllow DuplicateKeyMode = iota
+
+	// Keep the first value written for a key, discarding the value of any
+	// duplicate.
+	DuplicateKeyIgnore
+
+	// Keep the first value written for a key, discarding the value of any
+	// duplicate, and record a DuplicateKeyError to be returned by
+	// Encoder.Err.
+	DuplicateKeyReject
+)
+
 // Object represents the encoding of a JSON Object type
 type Object struct {
 	w          *bytes.Buffer
 	writeComma bool
 	scratch    *[]byte
+	state      *encoderState
+
+	// keys written, if duplicate keys are not allowed
+	keys map[string]struct{}
 }
 
-func newObject(w *bytes.Buffer, scratch *[]byte) *Object {
+func newObject(w *bytes.Buffer, scratch *[]byte, state *encoderState) *Object {
 	w.WriteRune(leftBrace)
-	return &Object{w: w, scratch: scratch}
+	state.depth++
+	return &Object{w: w, scratch: scratch, state: state}
 }
 
 func (o *Object) writeKey(key string) {
-	escapeStringBytes(o.w, []byte(key))
+	o.state.writeIndent(o.w)
+	escapeString(o.w, key, o.state.options)
 	o.w.WriteRune(colon)
+	if len(o.state.options.Indent) != 0 {
+		o.w.WriteByte(' ')
+	}
 }
 
 // Key adds the given named key to the JSON object.
 // Returns a Value encoder that should be used to encode
 // a JSON value type.
+//
+// If the Encoder was configured to not allow duplicate keys, a key that was
+// already written is not written again, and the returned Value discards what
+// is written to it.
 func (o *Object) Key(name string) Value {
+	if o.state.options.DuplicateKeys != DuplicateKeyAllow && o.isDuplicate(name) {
+		if o.state.options.DuplicateKeys == DuplicateKeyReject {
+			o.state.setErr(&DuplicateKeyError{Key: name})
+		}
+		return newValue(&bytes.Buffer{}, o.scratch, o.state)
+	}
+
 	if o.writeComma {
 		o.w.WriteRune(comma)
 	} else {
 		o.writeComma = true
 	}
 	o.writeKey(name)
-	return newValue(o.w, o.scratch)
+	return newValue(o.w, o.scratch, o.state)
+}
+
+// isDuplicate reports whether key was already written, recording it if not.
+func (o *Object) isDuplicate(key string) bool {
+	if o.keys == nil {
+		o.keys = map[string]struct{}{}
+	}
+	if _, ok := o.keys[key]; ok {
+		return true
+	}
+	o.keys[key] = struct{}{}
+	return false
+}
+
+// WriteRaw adds the given named key to the JSON object, with the
+// pre-encoded JSON value v written verbatim as its value.
+func (o *Object) WriteRaw(name string, v RawMessage) {
+	o.Key(name).WriteRaw(v)
 }
 
 // Close encodes the end of the JSON Object
 func (o *Object) Close() {
+	o.state.depth--
+	if o.writeComma {
+		o.state.writeIndent(o.w)
+	}
 	o.w.WriteRune(rightBrace)
 }
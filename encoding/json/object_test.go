@@ -2,6 +2,7 @@ package json
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -9,7 +10,7 @@ func TestObject(t *testing.T) {
 	buffer := bytes.NewBuffer(nil)
 	scratch := make([]byte, 64)
 
-	object := newObject(buffer, &scratch)
+	object := newObject(buffer, &scratch, &encoderState{})
 	object.Key("foo").String("bar")
 	object.Key("faz").String("baz")
 	object.Close()
@@ -32,3 +33,100 @@ func TestObjectKey_escaped(t *testing.T) {
 		t.Errorf("expected %+q, but got %+q", e, a)
 	}
 }
+
+func TestObject_WriteRaw(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	scratch := make([]byte, 64)
+
+	object := newObject(buffer, &scratch, &encoderState{})
+	object.Key("foo").String("bar")
+	object.WriteRaw("raw", RawMessage(`{"nested":[1,2]}`))
+	object.WriteRaw("empty", nil)
+	object.Close()
+
+	e := []byte(`{"foo":"bar","raw":{"nested":[1,2]},"empty":null}`)
+	if a := buffer.Bytes(); bytes.Compare(e, a) != 0 {
+		t.Errorf("expected %+q, but got %+q", e, a)
+	}
+}
+
+func TestObject_DuplicateKeys(t *testing.T) {
+	cases := map[string]struct {
+		mode     DuplicateKeyMode
+		expected string
+		err      bool
+	}{
+		"allow": {
+			mode:     DuplicateKeyAllow,
+			expected: `{"a":1,"b":{"c":true},"a":2,"b":{"d":false}}`,
+		},
+		"ignore": {
+			mode:     DuplicateKeyIgnore,
+			expected: `{"a":1,"b":{"c":true}}`,
+		},
+		"reject": {
+			mode:     DuplicateKeyReject,
+			expected: `{"a":1,"b":{"c":true}}`,
+			err:      true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder := NewEncoder(func(o *EncoderOptions) {
+				o.DuplicateKeys = c.mode
+			})
+
+			object := encoder.Object()
+			object.Key("a").Long(1)
+			inner := object.Key("b").Object()
+			inner.Key("c").Boolean(true)
+			inner.Close()
+			object.Key("a").Long(2)
+			inner = object.Key("b").Object()
+			inner.Key("d").Boolean(false)
+			inner.Close()
+			object.Close()
+
+			if e, a := c.expected, encoder.String(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+
+			err := encoder.Err()
+			if !c.err {
+				if err != nil {
+					t.Errorf("expect no error, got %v", err)
+				}
+				return
+			}
+			var derr *DuplicateKeyError
+			if !errors.As(err, &derr) {
+				t.Fatalf("expect DuplicateKeyError, got %v", err)
+			}
+			if e, a := "a", derr.Key; e != a {
+				t.Errorf("expect key %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestObject_DuplicateKeysScopedToObject(t *testing.T) {
+	encoder := NewEncoder(func(o *EncoderOptions) {
+		o.DuplicateKeys = DuplicateKeyReject
+	})
+
+	array := encoder.Array()
+	for i := 0; i < 2; i++ {
+		object := array.Value().Object()
+		object.Key("a").Long(int64(i))
+		object.Close()
+	}
+	array.Close()
+
+	if e, a := `[{"a":0},{"a":1}]`, encoder.String(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if err := encoder.Err(); err != nil {
+		t.Errorf("expect no error, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package json
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// InvalidPointerError is returned for a JSON pointer that is not valid per
+// RFC 6901.
+type InvalidPointerError struct {
+	Pointer string
+	Reason  string
+}
+
+func (e *InvalidPointerError) Error() string {
+	return fmt.Sprintf("invalid JSON pointer %q, %s", e.Pointer, e.Reason)
+}
+
+// PointerNotFoundError is returned by GetPointer for a JSON pointer that does
+// not reference a value of the document.
+type PointerNotFoundError struct {
+	Pointer string
+
+	// The prefix of Pointer that could not be resolved.
+	Prefix string
+}
+
+func (e *PointerNotFoundError) Error() string {
+	return fmt.Sprintf("JSON pointer %q not found, no value at %q", e.Pointer, e.Prefix)
+}
+
+// GetPointer returns the value of n referenced by the JSON pointer, e.g.
+// "/a/0/b", as defined by RFC 6901. The empty pointer references n itself.
+//
+// Returns an InvalidPointerError if the pointer is malformed, and a
+// PointerNotFoundError if it does not reference a value, including the "-"
+// array index which references the element after the last.
+func GetPointer(n Node, pointer string) (Node, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, token := range tokens {
+		switch v := n.(type) {
+		case ObjectNode:
+			member, ok := v[token]
+			if !ok {
+				return nil, newPointerNotFoundError(pointer, i)
+			}
+			n = member
+		case ArrayNode:
+			j, ok := parseArrayIndex(token)
+			if !ok || j >= len(v) {
+				return nil, newPointerNotFoundError(pointer, i)
+			}
+			n = v[j]
+		default:
+			return nil, newPointerNotFoundError(pointer, i)
+		}
+	}
+	return n, nil
+}
+
+// newPointerNotFoundError returns the error for the pointer whose i'th
+// reference token could not be resolved.
+func newPointerNotFoundError(pointer string, i int) *PointerNotFoundError {
+	end := 0
+	for ; i >= 0; i-- {
+		next := strings.IndexByte(pointer[end+1:], '/')
+		if next == -1 {
+			end = len(pointer)
+			break
+		}
+		end += next + 1
+	}
+	return &PointerNotFoundError{Pointer: pointer, Prefix: pointer[:end]}
+}
+
+// parsePointer returns the unescaped reference tokens of pointer.
+func parsePointer(pointer string) ([]string, error) {
+	if len(pointer) == 0 {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, &InvalidPointerError{Pointer: pointer, Reason: "must be empty or start with '/'"}
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		if strings.IndexByte(token, '~') == -1 {
+			continue
+		}
+
+		var sb strings.Builder
+		for j := 0; j < len(token); j++ {
+			if token[j] != '~' {
+				sb.WriteByte(token[j])
+				continue
+			}
+			if j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1') {
+				return nil, &InvalidPointerError{Pointer: pointer, Reason: "'~' must be followed by '0' or '1'"}
+			}
+			if token[j+1] == '0' {
+				sb.WriteByte('~')
+			} else {
+				sb.WriteByte('/')
+			}
+			j++
+		}
+		tokens[i] = sb.String()
+	}
+	return tokens, nil
+}
+
+// parseArrayIndex parses an array index reference token, which must be 0 or
+// have no leading zeros.
+func parseArrayIndex(token string) (int, bool) {
+	if len(token) == 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+	for i := 0; i < len(token); i++ {
+		if !isDigit(token[i]) {
+			return 0, false
+		}
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, false
+	}
+	return i, true
+}
+
+// EscapePointerToken escapes token for use as a reference token of a JSON
+// pointer, such that object member names containing '~' or '/' can be
+// referenced.
+func EscapePointerToken(token string) string {
+	if strings.IndexAny(token, "~/") == -1 {
+		return token
+	}
+	return pointerTokenEscaper.Replace(token)
+}
+
+var pointerTokenEscaper = strings.NewReplacer("~", "~0", "/", "~1")
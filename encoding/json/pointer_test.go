@@ -0,0 +1,96 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetPointer(t *testing.T) {
+	// RFC 6901 section 5
+	doc, err := DecodeNode(strings.NewReader(`{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"g|h": 4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ": 7,
+		"m~n": 8
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		pointer  string
+		expected Node
+		notFound string
+		invalid  bool
+	}{
+		"whole document": {pointer: "", expected: doc},
+		"array":          {pointer: "/foo", expected: ArrayNode{StringNode("bar"), StringNode("baz")}},
+		"array element":  {pointer: "/foo/0", expected: StringNode("bar")},
+		"empty name":     {pointer: "/", expected: NumberNode("0")},
+		"escaped slash":  {pointer: "/a~1b", expected: NumberNode("1")},
+		"percent":        {pointer: "/c%d", expected: NumberNode("2")},
+		"caret":          {pointer: "/e^f", expected: NumberNode("3")},
+		"pipe":           {pointer: "/g|h", expected: NumberNode("4")},
+		"backslash":      {pointer: "/i\\j", expected: NumberNode("5")},
+		"quote":          {pointer: "/k\"l", expected: NumberNode("6")},
+		"space":          {pointer: "/ ", expected: NumberNode("7")},
+		"escaped tilde":  {pointer: "/m~0n", expected: NumberNode("8")},
+		"missing member": {pointer: "/bar/0", notFound: "/bar"},
+		"out of range":   {pointer: "/foo/2", notFound: "/foo/2"},
+		"past the end":   {pointer: "/foo/-", notFound: "/foo/-"},
+		"leading zero":   {pointer: "/foo/01", notFound: "/foo/01"},
+		"scalar":         {pointer: "/foo/0/x", notFound: "/foo/0/x"},
+		"no root slash":  {pointer: "foo", invalid: true},
+		"bad escape":     {pointer: "/m~2n", invalid: true},
+		"trailing tilde": {pointer: "/m~", invalid: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := GetPointer(doc, c.pointer)
+			if c.invalid {
+				var perr *InvalidPointerError
+				if !errors.As(err, &perr) {
+					t.Fatalf("expect InvalidPointerError, got %v", err)
+				}
+				return
+			}
+			if len(c.notFound) != 0 {
+				var perr *PointerNotFoundError
+				if !errors.As(err, &perr) {
+					t.Fatalf("expect PointerNotFoundError, got %v", err)
+				}
+				if e, a := c.notFound, perr.Prefix; e != a {
+					t.Errorf("expect prefix %q, got %q", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("expect %#v, got %#v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestEscapePointerToken(t *testing.T) {
+	doc := ObjectNode{"a/~b": StringNode("c")}
+
+	actual, err := GetPointer(doc, "/"+EscapePointerToken("a/~b"))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := StringNode("c"), actual; e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
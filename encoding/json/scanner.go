@@ -0,0 +1,555 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// TokenKind identifies the kind of a token read by a Scanner.
+type TokenKind int
+
+// Enumeration of token kinds.
+const (
+	InvalidToken TokenKind = iota
+	ObjectStart
+	ObjectEnd
+	ArrayStart
+	ArrayEnd
+	Key
+	String
+	Number
+	Bool
+	Null
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case ObjectStart:
+		return "ObjectStart"
+	case ObjectEnd:
+		return "ObjectEnd"
+	case ArrayStart:
+		return "ArrayStart"
+	case ArrayEnd:
+		return "ArrayEnd"
+	case Key:
+		return "Key"
+	case String:
+		return "String"
+	case Number:
+		return "Number"
+	case Bool:
+		return "Bool"
+	case Null:
+		return "Null"
+	default:
+		return "InvalidToken"
+	}
+}
+
+// SyntaxError is returned by a Scanner for input that is not valid JSON.
+type SyntaxError struct {
+	// The offset of the input byte at which the error was detected.
+	Offset int64
+
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid JSON at offset %d: %s", e.Offset, e.Message)
+}
+
+// scanner states, i.e. what the next token may be
+const (
+	scanValue      = iota // a value, at the top level or following ':' or ',' in an array
+	scanFirstValue        // a value or ']', following '['
+	scanFirstKey          // a key or '}', following '{'
+	scanKey               // a key, following ',' in an object
+	scanEnd               // ',' or the end of the enclosing object or array
+)
+
+const scannerBufferSize = 4096
+
+// Scanner reads a stream of JSON tokens, without building values for them.
+//
+// Scanner is intended for deserializers of large documents: members that are
+// not modeled can be skipped with Skip, which validates them but does not
+// unescape or copy their contents.
+//
+// Like Decoder, a Scanner reads a stream of top-level values.
+type Scanner struct {
+	r   io.Reader
+	err error
+
+	buf       []byte
+	pos, end  int
+	bufOffset int64
+
+	// enclosing containers, '{' or '['
+	stack []byte
+	state int
+
+	token []byte
+}
+
+// NewScanner returns a Scanner that reads from r.
+//
+// The Scanner buffers its input, and may read data from r beyond the JSON
+// values requested.
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{
+		r:   r,
+		buf: make([]byte, scannerBufferSize),
+	}
+}
+
+// Next returns the kind of the next token in the stream. io.EOF is returned
+// once the stream ends between top-level values.
+//
+// Object members are returned as a Key token followed by the tokens of their
+// value.
+func (s *Scanner) Next() (TokenKind, error) {
+	return s.next(false)
+}
+
+// Bytes returns the contents of the most recently returned token: the
+// unescaped contents of a Key or String, or the literal text of a Number,
+// Bool, or Null. The returned slice is only valid until the next call to the
+// Scanner.
+func (s *Scanner) Bytes() []byte {
+	return s.token
+}
+
+// Text returns the contents of the most recently returned token as a string.
+// See Bytes.
+func (s *Scanner) Text() string {
+	return string(s.token)
+}
+
+// Bool returns whether the most recently returned token was the literal true.
+func (s *Scanner) Bool() bool {
+	return string(s.token) == "true"
+}
+
+// Depth returns the number of objects and arrays enclosing the scanner's
+// position in the stream.
+func (s *Scanner) Depth() int {
+	return len(s.stack)
+}
+
+// Skip skips the next value in the stream, including all nested values if it
+// is an object or array. When called after Next returns a Key, Skip skips the
+// value of that member.
+//
+// An error is returned if the next token is the end of an object or array.
+func (s *Scanner) Skip() error {
+	depth := len(s.stack)
+
+	k, err := s.next(true)
+	if err != nil {
+		return err
+	}
+	if k == ObjectEnd || k == ArrayEnd {
+		return s.syntaxError(fmt.Sprintf("expected value to skip, found %v", k))
+	}
+
+	for len(s.stack) > depth {
+		if _, err := s.next(true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// next reads the next token. If skip is set, the contents of strings and
+// numbers are validated but not retained.
+func (s *Scanner) next(skip bool) (TokenKind, error) {
+	s.token = s.token[:0]
+
+	c, err := s.skipWhitespace()
+	if err == io.EOF && len(s.stack) == 0 {
+		return InvalidToken, io.EOF
+	}
+	if err != nil {
+		return InvalidToken, s.unexpectedEOF(err)
+	}
+
+	switch s.state {
+	case scanEnd:
+		if c == ',' {
+			s.pos++
+			if s.stack[len(s.stack)-1] == '{' {
+				s.state = scanKey
+			} else {
+				s.state = scanValue
+			}
+			return s.next(skip)
+		}
+		return s.scanClose(c)
+
+	case scanFirstKey, scanKey:
+		if c == '}' && s.state == scanFirstKey {
+			return s.scanClose(c)
+		}
+		if c != '"' {
+			return InvalidToken, s.syntaxError(fmt.Sprintf("expected object key, found %q", c))
+		}
+		if err := s.scanString(skip); err != nil {
+			return InvalidToken, err
+		}
+
+		c, err := s.skipWhitespace()
+		if err != nil {
+			return InvalidToken, s.unexpectedEOF(err)
+		}
+		if c != ':' {
+			return InvalidToken, s.syntaxError(fmt.Sprintf("expected ':' after object key, found %q", c))
+		}
+		s.pos++
+		s.state = scanValue
+		return Key, nil
+
+	case scanFirstValue:
+		if c == ']' {
+			return s.scanClose(c)
+		}
+	}
+
+	return s.scanValue(c, skip)
+}
+
+func (s *Scanner) scanValue(c byte, skip bool) (TokenKind, error) {
+	switch {
+	case c == '{':
+		s.pos++
+		s.stack = append(s.stack, c)
+		s.state = scanFirstKey
+		return ObjectStart, nil
+	case c == '[':
+		s.pos++
+		s.stack = append(s.stack, c)
+		s.state = scanFirstValue
+		return ArrayStart, nil
+	case c == '"':
+		if err := s.scanString(skip); err != nil {
+			return InvalidToken, err
+		}
+		s.endValue()
+		return String, nil
+	case c == '-' || isDigit(c):
+		if err := s.scanNumber(skip); err != nil {
+			return InvalidToken, err
+		}
+		s.endValue()
+		return Number, nil
+	case c == 't':
+		return s.scanLiteral("true", Bool)
+	case c == 'f':
+		return s.scanLiteral("false", Bool)
+	case c == 'n':
+		return s.scanLiteral("null", Null)
+	default:
+		return InvalidToken, s.syntaxError(fmt.Sprintf("invalid character %q looking for beginning of value", c))
+	}
+}
+
+func (s *Scanner) scanClose(c byte) (TokenKind, error) {
+	open := s.stack[len(s.stack)-1]
+	switch {
+	case c == '}' && open == '{':
+		s.pos++
+		s.stack = s.stack[:len(s.stack)-1]
+		s.endValue()
+		return ObjectEnd, nil
+	case c == ']' && open == '[':
+		s.pos++
+		s.stack = s.stack[:len(s.stack)-1]
+		s.endValue()
+		return ArrayEnd, nil
+	case open == '{':
+		return InvalidToken, s.syntaxError(fmt.Sprintf("expected ',' or '}' in object, found %q", c))
+	default:
+		return InvalidToken, s.syntaxError(fmt.Sprintf("expected ',' or ']' in array, found %q", c))
+	}
+}
+
+func (s *Scanner) endValue() {
+	if len(s.stack) == 0 {
+		s.state = scanValue
+	} else {
+		s.state = scanEnd
+	}
+}
+
+func (s *Scanner) scanLiteral(lit string, k TokenKind) (TokenKind, error) {
+	for i := 0; i < len(lit); i++ {
+		c, err := s.peek()
+		if err != nil {
+			return InvalidToken, s.unexpectedEOF(err)
+		}
+		if c != lit[i] {
+			return InvalidToken, s.syntaxError(fmt.Sprintf("invalid character %q in literal %s", c, lit))
+		}
+		s.pos++
+	}
+
+	s.token = append(s.token, lit...)
+	s.endValue()
+	return k, nil
+}
+
+// scanString scans a string starting at the opening quote, unescaping its
+// contents into s.token unless skip is set.
+func (s *Scanner) scanString(skip bool) error {
+	s.pos++ // opening quote
+
+	for {
+		c, err := s.peek()
+		if err != nil {
+			return s.unexpectedEOF(err)
+		}
+		s.pos++
+
+		switch {
+		case c == '"':
+			return nil
+		case c < 0x20:
+			return s.syntaxError(fmt.Sprintf("invalid control character %q in string", c))
+		case c == '\\':
+			if err := s.scanEscape(skip); err != nil {
+				return err
+			}
+		default:
+			if !skip {
+				s.token = append(s.token, c)
+			}
+		}
+	}
+}
+
+// scanEscape scans an escape sequence following a backslash, appending the
+// rune it represents to s.token unless skip is set. Unpaired surrogates are
+// replaced with U+FFFD.
+func (s *Scanner) scanEscape(skip bool) error {
+	c, err := s.peek()
+	if err != nil {
+		return s.unexpectedEOF(err)
+	}
+	s.pos++
+
+	var r rune
+	switch c {
+	case '"', '\\', '/':
+		r = rune(c)
+	case 'b':
+		r = '\b'
+	case 'f':
+		r = '\f'
+	case 'n':
+		r = '\n'
+	case 'r':
+		r = '\r'
+	case 't':
+		r = '\t'
+	case 'u':
+		if r, err = s.scanHex4(); err != nil {
+			return err
+		}
+		// a high surrogate is only combined with an immediately following
+		// escaped low surrogate, which is otherwise left to be scanned as an
+		// escape of its own
+		if utf16.IsSurrogate(r) && s.fill(6) && s.buf[s.pos] == '\\' && s.buf[s.pos+1] == 'u' {
+			if r2, ok := parseHex4(s.buf[s.pos+2 : s.pos+6]); ok {
+				if pair := utf16.DecodeRune(r, r2); pair != utf8.RuneError {
+					s.pos += 6
+					r = pair
+				}
+			}
+		}
+		if utf16.IsSurrogate(r) {
+			r = utf8.RuneError
+		}
+	default:
+		return s.syntaxError(fmt.Sprintf("invalid escape character %q in string", c))
+	}
+
+	if !skip {
+		s.token = utf8.AppendRune(s.token, r)
+	}
+	return nil
+}
+
+func (s *Scanner) scanHex4() (rune, error) {
+	if !s.fill(4) {
+		if s.err == io.EOF {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, s.err
+	}
+
+	r, ok := parseHex4(s.buf[s.pos : s.pos+4])
+	if !ok {
+		return 0, s.syntaxError("invalid \\u escape in string")
+	}
+	s.pos += 4
+	return r, nil
+}
+
+func parseHex4(p []byte) (rune, bool) {
+	var r rune
+	for _, c := range p {
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c = c - 'a' + 10
+		case 'A' <= c && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, true
+}
+
+// scanNumber scans a number, retaining its literal text in s.token unless skip
+// is set.
+func (s *Scanner) scanNumber(skip bool) error {
+	nextDigits := func(required bool) error {
+		n := 0
+		for {
+			c, err := s.peek()
+			if err == io.EOF || (err == nil && !isDigit(c)) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if !skip {
+				s.token = append(s.token, c)
+			}
+			s.pos++
+			n++
+		}
+		if required && n == 0 {
+			return s.digitError()
+		}
+		return nil
+	}
+	accept := func(set string) bool {
+		c, err := s.peek()
+		if err != nil {
+			return false
+		}
+		for i := 0; i < len(set); i++ {
+			if c == set[i] {
+				if !skip {
+					s.token = append(s.token, c)
+				}
+				s.pos++
+				return true
+			}
+		}
+		return false
+	}
+
+	accept("-")
+	if accept("0") {
+		if c, err := s.peek(); err == nil && isDigit(c) {
+			return s.syntaxError("invalid leading zero in number")
+		}
+	} else if err := nextDigits(true); err != nil {
+		return err
+	}
+
+	if accept(".") {
+		if err := nextDigits(true); err != nil {
+			return err
+		}
+	}
+	if accept("eE") {
+		accept("+-")
+		if err := nextDigits(true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Scanner) digitError() error {
+	c, err := s.peek()
+	if err != nil {
+		return s.unexpectedEOF(err)
+	}
+	return s.syntaxError(fmt.Sprintf("invalid character %q in number, expected digit", c))
+}
+
+func (s *Scanner) skipWhitespace() (byte, error) {
+	for {
+		c, err := s.peek()
+		if err != nil {
+			return 0, err
+		}
+		if !isWhitespace(c) {
+			return c, nil
+		}
+		s.pos++
+	}
+}
+
+// peek returns the next byte of input without consuming it.
+func (s *Scanner) peek() (byte, error) {
+	if s.pos < s.end {
+		return s.buf[s.pos], nil
+	}
+	if !s.fill(1) {
+		return 0, s.err
+	}
+	return s.buf[s.pos], nil
+}
+
+// fill attempts to buffer at least n bytes of input from the current
+// position, returning false if they could not be read.
+func (s *Scanner) fill(n int) bool {
+	if s.end-s.pos >= n {
+		return true
+	}
+
+	// shift the unread bytes to the front of the buffer
+	s.bufOffset += int64(s.pos)
+	s.end = copy(s.buf, s.buf[s.pos:s.end])
+	s.pos = 0
+
+	for s.end < n && s.err == nil {
+		var m int
+		m, s.err = s.r.Read(s.buf[s.end:])
+		s.end += m
+	}
+	return s.end >= n
+}
+
+func (s *Scanner) offset() int64 {
+	return s.bufOffset + int64(s.pos)
+}
+
+func (s *Scanner) syntaxError(msg string) error {
+	return &SyntaxError{Offset: s.offset(), Message: msg}
+}
+
+func (s *Scanner) unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,281 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type scannedToken struct {
+	Kind TokenKind
+	Text string
+}
+
+func scanAll(s *Scanner) ([]scannedToken, error) {
+	var tokens []scannedToken
+	for {
+		k, err := s.Next()
+		if err == io.EOF {
+			return tokens, nil
+		}
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, scannedToken{k, s.Text()})
+	}
+}
+
+func TestScanner_Next(t *testing.T) {
+	cases := map[string]struct {
+		input    string
+		expected []scannedToken
+		err      string
+	}{
+		"scalars": {
+			input: `"foo" -1.5e+3 0 true false null`,
+			expected: []scannedToken{
+				{String, "foo"},
+				{Number, "-1.5e+3"},
+				{Number, "0"},
+				{Bool, "true"},
+				{Bool, "false"},
+				{Null, "null"},
+			},
+		},
+		"nested": {
+			input: `{"a": [1, {}], "b": {"c": []}}`,
+			expected: []scannedToken{
+				{ObjectStart, ""},
+				{Key, "a"},
+				{ArrayStart, ""},
+				{Number, "1"},
+				{ObjectStart, ""},
+				{ObjectEnd, ""},
+				{ArrayEnd, ""},
+				{Key, "b"},
+				{ObjectStart, ""},
+				{Key, "c"},
+				{ArrayStart, ""},
+				{ArrayEnd, ""},
+				{ObjectEnd, ""},
+				{ObjectEnd, ""},
+			},
+		},
+		"escapes": {
+			input: `"\"\\\/\b\f\n\r\té😀"`,
+			expected: []scannedToken{
+				{String, "\"\\/\b\f\n\r\té\U0001F600"},
+			},
+		},
+		"unpaired surrogate": {
+			input: `"\ud83dA" "\ude00"`,
+			expected: []scannedToken{
+				{String, "�A"},
+				{String, "�"},
+			},
+		},
+		"empty input": {
+			input: " \n",
+		},
+		"trailing comma in array": {
+			input: `[1,]`,
+			err:   "looking for beginning of value",
+		},
+		"trailing comma in object": {
+			input: `{"a":1,}`,
+			err:   "expected object key",
+		},
+		"missing colon": {
+			input: `{"a" 1}`,
+			err:   "expected ':'",
+		},
+		"mismatched delimiter": {
+			input: `[1}`,
+			err:   "expected ',' or ']'",
+		},
+		"leading zero": {
+			input: `01`,
+			err:   "leading zero",
+		},
+		"missing fraction": {
+			input: `1.e5`,
+			err:   "expected digit",
+		},
+		"invalid literal": {
+			input: `tru`,
+			err:   "unexpected EOF",
+		},
+		"control character": {
+			input: "\"a\nb\"",
+			err:   "invalid control character",
+		},
+		"invalid escape": {
+			input: `"\x"`,
+			err:   "invalid escape",
+		},
+		"unterminated object": {
+			input: `{"a": 1`,
+			err:   "unexpected EOF",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := scanAll(NewScanner(strings.NewReader(c.input)))
+			if len(c.err) != 0 {
+				if err == nil {
+					t.Fatalf("expect error %q", c.err)
+				}
+				if !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expect error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("expect %v, got %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestScanner_OneByteReader(t *testing.T) {
+	input := `{"key": "é😀", "n": [-0.5E-7, true]}`
+
+	expect, err := scanAll(NewScanner(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	actual, err := scanAll(NewScanner(iotest.OneByteReader(strings.NewReader(input))))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if !reflect.DeepEqual(expect, actual) {
+		t.Errorf("expect %v, got %v", expect, actual)
+	}
+}
+
+func TestScanner_Skip(t *testing.T) {
+	s := NewScanner(strings.NewReader(`{
+		"unmodeled": {"a": [1, 2, {"b": "c"}], "c": null},
+		"id": "foo",
+		"other": [[], {}],
+		"last": 1
+	} "next"`))
+
+	var ids []string
+	if k, err := s.Next(); err != nil || k != ObjectStart {
+		t.Fatalf("expect ObjectStart, got %v, %v", k, err)
+	}
+	for {
+		k, err := s.Next()
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if k == ObjectEnd {
+			break
+		}
+		if s.Text() != "id" {
+			if err := s.Skip(); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			continue
+		}
+
+		if k, err := s.Next(); err != nil || k != String {
+			t.Fatalf("expect String, got %v, %v", k, err)
+		}
+		ids = append(ids, s.Text())
+	}
+
+	if e, a := []string{"foo"}, ids; !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if e, a := 0, s.Depth(); e != a {
+		t.Errorf("expect depth %v, got %v", e, a)
+	}
+
+	// skipping a top-level value, then the end of the stream
+	if err := s.Skip(); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if err := s.Skip(); err != io.EOF {
+		t.Errorf("expect EOF, got %v", err)
+	}
+}
+
+func TestScanner_SkipErrors(t *testing.T) {
+	cases := map[string]struct {
+		input string
+		err   string
+	}{
+		"end of array": {
+			input: `[]`,
+			err:   "expected value to skip",
+		},
+		"invalid nested value": {
+			input: `[{"a": [1, 2}]`,
+			err:   "expected ',' or ']'",
+		},
+		"unterminated string": {
+			input: `["abc`,
+			err:   "unexpected EOF",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewScanner(strings.NewReader(c.input))
+			if k, err := s.Next(); err != nil || k != ArrayStart {
+				t.Fatalf("expect ArrayStart, got %v, %v", k, err)
+			}
+
+			err := s.Skip()
+			if err == nil {
+				t.Fatalf("expect error %q", c.err)
+			}
+			if !strings.Contains(err.Error(), c.err) {
+				t.Errorf("expect error %q, got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestScanner_SyntaxErrorOffset(t *testing.T) {
+	_, err := scanAll(NewScanner(strings.NewReader(`[1, x]`)))
+
+	var serr *SyntaxError
+	if !errors.As(err, &serr) {
+		t.Fatalf("expect SyntaxError, got %v", err)
+	}
+	if e, a := int64(4), serr.Offset; e != a {
+		t.Errorf("expect offset %v, got %v", e, a)
+	}
+}
+
+func BenchmarkScanner_Skip(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString(`{"unmodeled": [`)
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(`{"name": "a somewhat long string value\n", "n": 12345.678, "ok": true}`)
+	}
+	sb.WriteString(`]}`)
+	input := sb.String()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(input)))
+	for i := 0; i < b.N; i++ {
+		s := NewScanner(strings.NewReader(input))
+		if err := s.Skip(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
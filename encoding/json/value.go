@@ -3,10 +3,13 @@ package json
 import (
 	"bytes"
 	"encoding/base64"
+	"io"
+	"math"
 	"math/big"
 	"strconv"
 
 	"github.com/aws/smithy-go/encoding"
+	"github.com/aws/smithy-go/encoding/internal/chunk"
 )
 
 // Value represents a JSON Value type
@@ -14,16 +17,17 @@ import (
 type Value struct {
 	w       *bytes.Buffer
 	scratch *[]byte
+	state   *encoderState
 }
 
 // newValue returns a new Value encoder
-func newValue(w *bytes.Buffer, scratch *[]byte) Value {
-	return Value{w: w, scratch: scratch}
+func newValue(w *bytes.Buffer, scratch *[]byte, state *encoderState) Value {
+	return Value{w: w, scratch: scratch, state: state}
 }
 
 // String encodes v as a JSON string
 func (jv Value) String(v string) {
-	escapeStringBytes(jv.w, []byte(v))
+	escapeString(jv.w, v, jv.state.options)
 }
 
 // Byte encodes v as a JSON number
@@ -64,6 +68,18 @@ func (jv Value) Double(v float64) {
 }
 
 func (jv Value) float(v float64, bits int) {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		switch jv.state.options.NonFiniteFloats {
+		case NonFiniteFloatString:
+			jv.String(nonFiniteFloatString(v))
+			return
+		case NonFiniteFloatError:
+			jv.state.setErr(&InvalidFloatError{Value: v})
+			jv.Null()
+			return
+		}
+	}
+
 	*jv.scratch = encoding.EncodeFloat((*jv.scratch)[:0], v, bits)
 	jv.w.Write(*jv.scratch)
 }
@@ -79,19 +95,66 @@ func (jv Value) Base64EncodeBytes(v []byte) {
 	encodeByteSlice(jv.w, (*jv.scratch)[:0], v)
 }
 
+// Base64EncodeReader writes the contents of r as a base64 value in a JSON
+// string. r is read and encoded in chunks directly into the output, so large
+// blobs need not be held in memory in both their raw and encoded forms.
+//
+// If reading from r fails the string is closed, such that the output is still
+// well-formed JSON, and the error is returned and recorded by the Encoder.
+func (jv Value) Base64EncodeReader(r io.Reader) error {
+	size := chunk.Base64Size + base64.StdEncoding.EncodedLen(chunk.Base64Size)
+	if cap(*jv.scratch) < size {
+		*jv.scratch = make([]byte, size)
+	}
+	raw := (*jv.scratch)[:chunk.Base64Size]
+	encoded := (*jv.scratch)[chunk.Base64Size:size]
+
+	jv.w.WriteRune(quote)
+	defer jv.w.WriteRune(quote)
+
+	for {
+		n, err := chunk.Read(r, raw)
+		if n > 0 {
+			base64.StdEncoding.Encode(encoded, raw[:n])
+			jv.w.Write(encoded[:base64.StdEncoding.EncodedLen(n)])
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			jv.state.setErr(err)
+			return err
+		}
+	}
+}
+
 // Write writes v directly to the JSON document
 func (jv Value) Write(v []byte) {
 	jv.w.Write(v)
 }
 
+// RawMessage is a pre-encoded JSON value.
+type RawMessage []byte
+
+// WriteRaw writes the pre-encoded JSON value v verbatim. The caller is
+// responsible for v being a single valid JSON value, it is not validated. An
+// empty v is written as a JSON null.
+func (jv Value) WriteRaw(v RawMessage) {
+	if len(v) == 0 {
+		jv.Null()
+		return
+	}
+	jv.w.Write(v)
+}
+
 // Array returns a new Array encoder
 func (jv Value) Array() *Array {
-	return newArray(jv.w, jv.scratch)
+	return newArray(jv.w, jv.scratch, jv.state)
 }
 
 // Object returns a new Object encoder
 func (jv Value) Object() *Object {
-	return newObject(jv.w, jv.scratch)
+	return newObject(jv.w, jv.scratch, jv.state)
 }
 
 // Null encodes a null JSON value
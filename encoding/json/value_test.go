@@ -2,10 +2,15 @@ package json
 
 import (
 	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
 	"math"
 	"math/big"
 	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 var (
@@ -144,7 +149,7 @@ func TestValue(t *testing.T) {
 	for name, tt := range cases {
 		t.Run(name, func(t *testing.T) {
 			var b bytes.Buffer
-			value := newValue(&b, &scratch)
+			value := newValue(&b, &scratch, &encoderState{})
 
 			tt.setter(value)
 
@@ -154,3 +159,59 @@ func TestValue(t *testing.T) {
 		})
 	}
 }
+
+func TestValue_Base64EncodeReader(t *testing.T) {
+	long := strings.Repeat("0123456789", 1000)
+
+	cases := map[string]struct {
+		reader   io.Reader
+		expected string
+		err      error
+	}{
+		"empty": {
+			reader:   strings.NewReader(""),
+			expected: `""`,
+		},
+		"short": {
+			reader:   strings.NewReader("foo bar"),
+			expected: `"Zm9vIGJhcg=="`,
+		},
+		"multiple chunks": {
+			reader:   strings.NewReader(long),
+			expected: `"` + base64.StdEncoding.EncodeToString([]byte(long)) + `"`,
+		},
+		"one byte reader": {
+			reader:   iotest.OneByteReader(strings.NewReader(long)),
+			expected: `"` + base64.StdEncoding.EncodeToString([]byte(long)) + `"`,
+		},
+		"data with EOF": {
+			reader:   iotest.DataErrReader(strings.NewReader("foo bar")),
+			expected: `"Zm9vIGJhcg=="`,
+		},
+		"read error": {
+			reader:   io.MultiReader(strings.NewReader("foo"), iotest.ErrReader(errors.New("read failed"))),
+			expected: `"Zm9v"`,
+			err:      errors.New("read failed"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			enc := NewEncoder()
+			err := enc.Base64EncodeReader(c.reader)
+			if c.err != nil {
+				if err == nil || err.Error() != c.err.Error() {
+					t.Fatalf("expect error %v, got %v", c.err, err)
+				}
+				if enc.Err() != err {
+					t.Errorf("expect encoder error %v, got %v", err, enc.Err())
+				}
+			} else if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.expected, enc.String(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
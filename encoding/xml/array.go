@@ -15,6 +15,9 @@ type Array struct {
 
 	// isFlattened indicates if the array is a flattened array.
 	isFlattened bool
+
+	// namespace scope of the members
+	ns *namespaceScope
 }
 
 // newArray returns an array encoder.
@@ -43,7 +46,7 @@ func newArray(w writer, scratch *[]byte, memberStartElement StartElement, arrayS
 // Member adds a new member to the XML array.
 // It returns a Value encoder.
 func (a *Array) Member() Value {
-	v := newValue(a.w, a.scratch, a.memberStartElement)
+	v := newElementValue(a.w, a.scratch, a.ns, a.memberStartElement)
 	v.isFlattened = a.isFlattened
 	return v
 }
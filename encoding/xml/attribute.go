@@ -0,0 +1,143 @@
+package xml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Attributes is the attributes of an element, as decoded, with accessors of
+// attribute values by name. See NodeDecoder.Attributes and Node.Attributes.
+//
+// Attributes are in document order, and include namespace declarations, so
+// that they can be written as the attributes of an encoded element as is.
+type Attributes []Attr
+
+// Attributes returns the attributes of the node decoder's start element.
+//
+// The name space of an attribute is the prefix used in the document if the
+// prefix is declared by the element, see Token, or the namespace URI if the
+// prefix is declared by an ancestor of the element.
+func (d NodeDecoder) Attributes() Attributes {
+	if len(d.StartEl.Attr) == 0 {
+		return nil
+	}
+
+	attrs := make(Attributes, len(d.StartEl.Attr))
+	for i, a := range d.StartEl.Attr {
+		attrs[i] = Attr{Name: Name(a.Name), Value: a.Value}
+	}
+	return attrs
+}
+
+// Attributes returns the attributes of the node. The name space of an
+// attribute is the prefix used in the document.
+func (n *Node) Attributes() Attributes {
+	return Attributes(n.Attr)
+}
+
+// Get returns the value of the attribute with the name, and whether it was
+// present. The name is the attribute's local name, e.g. "id", or its prefix
+// and local name, e.g. "xsi:type", which matches the attribute with that name
+// space and local name only. Namespace declarations are not matched.
+func (as Attributes) Get(name string) (string, bool) {
+	var space string
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		space, name = name[:i], name[i+1:]
+	}
+
+	for _, a := range as {
+		if a.Name.Local == name && a.Name.Space == space && !isNamespaceDecl(a) {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// GetNS returns the value of the attribute with the namespace URI and local
+// name, and whether it was present. The attribute's name space is the URI, or
+// a prefix bound to the URI by a namespace declaration of the attributes.
+func (as Attributes) GetNS(uri, local string) (string, bool) {
+	for _, a := range as {
+		if a.Name.Local != local || len(a.Name.Space) == 0 || isNamespaceDecl(a) {
+			continue
+		}
+		if a.Name.Space == uri || as.lookupURI(a.Name.Space) == uri {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// lookupURI returns the namespace URI bound to the prefix by a namespace
+// declaration of the attributes, or empty if there is none.
+func (as Attributes) lookupURI(prefix string) string {
+	for _, a := range as {
+		if a.Name.Space == "xmlns" && a.Name.Local == prefix {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// String returns the value of the attribute with the name, or nil if it is
+// not present. See Get for how names are matched.
+func (as Attributes) String(name string) *string {
+	v, ok := as.Get(name)
+	if !ok {
+		return nil
+	}
+	return &v
+}
+
+// Bool returns the value of the attribute with the name parsed as a boolean,
+// or nil if it is not present. See Get for how names are matched.
+func (as Attributes) Bool(name string) (*bool, error) {
+	v, ok := as.Get(name)
+	if !ok {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("attribute %s, %w", name, err)
+	}
+	return &b, nil
+}
+
+// Int64 returns the value of the attribute with the name parsed as an
+// integer, or nil if it is not present. See Get for how names are matched.
+func (as Attributes) Int64(name string) (*int64, error) {
+	v, ok := as.Get(name)
+	if !ok {
+		return nil, nil
+	}
+
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("attribute %s, %w", name, err)
+	}
+	return &i, nil
+}
+
+// Float64 returns the value of the attribute with the name parsed as a
+// floating point number, or nil if it is not present. "NaN", "Infinity", and
+// "-Infinity" are accepted. See Get for how names are matched.
+func (as Attributes) Float64(name string) (*float64, error) {
+	v, ok := as.Get(name)
+	if !ok {
+		return nil, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, fmt.Errorf("attribute %s, %w", name, err)
+	}
+	return &f, nil
+}
+
+// isNamespaceDecl returns whether the attribute declares a namespace, e.g.
+// xmlns:prefix="uri" or xmlns="uri".
+func isNamespaceDecl(a Attr) bool {
+	return a.Name.Space == "xmlns" || len(a.Name.Space) == 0 && a.Name.Local == "xmlns"
+}
@@ -0,0 +1,146 @@
+package xml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAttributes(t *testing.T) {
+	attrs := Attributes{
+		{Name: Name{Local: "xmlns"}, Value: "https://example.com"},
+		{Name: Name{Space: "xmlns", Local: "xsi"}, Value: "http://www.w3.org/2001/XMLSchema-instance"},
+		{Name: Name{Space: "xsi", Local: "type"}, Value: "CanonicalUser"},
+		{Name: Name{Space: "https://example.com/ext", Local: "type"}, Value: "ext"},
+		{Name: Name{Local: "id"}, Value: "1"},
+	}
+
+	cases := map[string]struct {
+		get    func() (string, bool)
+		expect string
+		ok     bool
+	}{
+		"local": {
+			get:    func() (string, bool) { return attrs.Get("id") },
+			expect: "1", ok: true,
+		},
+		"prefixed": {
+			get:    func() (string, bool) { return attrs.Get("xsi:type") },
+			expect: "CanonicalUser", ok: true,
+		},
+		"local does not match namespaced": {
+			get: func() (string, bool) { return attrs.Get("type") },
+		},
+		"namespace declaration": {
+			get: func() (string, bool) { return attrs.Get("xmlns") },
+		},
+		"namespace uri by declared prefix": {
+			get:    func() (string, bool) { return attrs.GetNS("http://www.w3.org/2001/XMLSchema-instance", "type") },
+			expect: "CanonicalUser", ok: true,
+		},
+		"namespace uri": {
+			get:    func() (string, bool) { return attrs.GetNS("https://example.com/ext", "type") },
+			expect: "ext", ok: true,
+		},
+		"default namespace does not apply": {
+			get: func() (string, bool) { return attrs.GetNS("https://example.com", "id") },
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			v, ok := c.get()
+			if e, a := c.ok, ok; e != a {
+				t.Fatalf("expect present %v, got %v", e, a)
+			}
+			if e, a := c.expect, v; e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestAttributes_Typed(t *testing.T) {
+	attrs := Attributes{
+		{Name: Name{Local: "id"}, Value: "-12"},
+		{Name: Name{Local: "enabled"}, Value: "true"},
+		{Name: Name{Local: "ratio"}, Value: "-Infinity"},
+		{Name: Name{Local: "bad"}, Value: "abc"},
+	}
+
+	if v := attrs.String("id"); v == nil || *v != "-12" {
+		t.Errorf("expect id -12, got %v", v)
+	}
+	if v := attrs.String("missing"); v != nil {
+		t.Errorf("expect missing nil, got %v", *v)
+	}
+
+	if v, err := attrs.Int64("id"); err != nil || v == nil || *v != -12 {
+		t.Errorf("expect id -12, got %v, %v", v, err)
+	}
+	if v, err := attrs.Bool("enabled"); err != nil || v == nil || !*v {
+		t.Errorf("expect enabled true, got %v, %v", v, err)
+	}
+	if v, err := attrs.Float64("ratio"); err != nil || v == nil || *v > 0 {
+		t.Errorf("expect ratio -Infinity, got %v, %v", v, err)
+	}
+	if v, err := attrs.Int64("missing"); err != nil || v != nil {
+		t.Errorf("expect missing nil, got %v, %v", v, err)
+	}
+
+	for name, fn := range map[string]func() error{
+		"bool":    func() error { _, err := attrs.Bool("bad"); return err },
+		"int64":   func() error { _, err := attrs.Int64("bad"); return err },
+		"float64": func() error { _, err := attrs.Float64("bad"); return err },
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := fn()
+			if err == nil {
+				t.Fatalf("expect error")
+			}
+			if e, a := "attribute bad", err.Error(); !strings.Contains(a, e) {
+				t.Errorf("expect %v in error, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestNodeDecoder_Attributes(t *testing.T) {
+	d := newTestNodeDecoder(t, `<Response xmlns:ext="https://example.com/ext">`+
+		`<Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser" ext:id="1"/>`+
+		`</Response>`)
+	el, err := d.GetElement("Grantee")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	attrs := WrapNodeDecoder(d.Decoder, el).Attributes()
+
+	if v := attrs.String("xsi:type"); v == nil || *v != "CanonicalUser" {
+		t.Errorf("expect xsi:type CanonicalUser, got %v", v)
+	}
+	// prefix declared by an ancestor is resolved to its namespace URI
+	if v, ok := attrs.GetNS("https://example.com/ext", "id"); !ok || v != "1" {
+		t.Errorf("expect ext:id 1, got %v, %v", v, ok)
+	}
+}
+
+func TestNode_AttributesRoundTrip(t *testing.T) {
+	input := `<Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser" id="a&amp;b"></Grantee>`
+	n, err := DecodeNode(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	attrs := n.Attributes()
+	if v := attrs.String("id"); v == nil || *v != "a&b" {
+		t.Errorf("expect id a&b, got %v", v)
+	}
+
+	encoder := NewEncoder(bytes.NewBuffer(nil))
+	root := encoder.RootElement(StartElement{Name: n.Name, Attr: attrs})
+	root.Close()
+
+	if e, a := input, encoder.String(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
@@ -0,0 +1,323 @@
+package xml
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CanonicalOptions is the set of options that can be configured for
+// Canonicalize.
+type CanonicalOptions struct {
+	// Prefixes of namespaces that are rendered on each element they are in
+	// scope of, unless already rendered by an ancestor, as with inclusive
+	// canonicalization, rather than only on elements that use them. The
+	// default namespace is identified by "#default". This is the
+	// InclusiveNamespaces PrefixList of exclusive canonicalization.
+	InclusiveNamespaces []string
+
+	// If set, comments are kept, as with the #WithComments variant of
+	// exclusive canonicalization. Comments are removed by default.
+	WithComments bool
+}
+
+// Canonicalize reads the XML document from r, and writes its canonical form,
+// per Exclusive XML Canonicalization 1.0
+// (https://www.w3.org/TR/xml-exc-c14n/), to w. Documents that are equivalent
+// have the same canonical form, regardless of attribute order, namespace
+// declaration placement, empty element and CDATA syntax, or character
+// escaping, so that the canonical form can be signed, or compared with a
+// snapshot, byte for byte.
+//
+// In the canonical form:
+//   - the XML declaration, and document type declaration, are removed
+//   - empty elements are written as start and end element pairs
+//   - whitespace outside of the root element is removed
+//   - namespaces are declared on the elements that use them, unless already
+//     declared by an ancestor, ordered by prefix
+//   - attributes are ordered by namespace URI, then local name
+//   - text and attribute values are written with a fixed set of escapes
+//
+// An element or attribute with a prefix that is not declared in scope is
+// returned as an error. Attribute values are as decoded by encoding/xml,
+// which does not normalize whitespace within them.
+func Canonicalize(w io.Writer, r io.Reader, optFns ...func(*CanonicalOptions)) error {
+	var o CanonicalOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	c := &canonicalizer{w: bufio.NewWriter(w), inclusive: map[string]bool{}}
+	for _, p := range o.InclusiveNamespaces {
+		if p == "#default" {
+			p = ""
+		}
+		c.inclusive[p] = true
+	}
+
+	d := xml.NewDecoder(r)
+	for {
+		t, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch tt := t.(type) {
+		case xml.StartElement:
+			if c.done {
+				return fmt.Errorf("element %s after the root element", rawName(tt.Name))
+			}
+			if err := c.startElement(tt); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			if err := c.endElement(tt); err != nil {
+				return err
+			}
+		case xml.CharData:
+			// whitespace outside of the root element is not part of the
+			// document's content
+			if len(c.stack) != 0 {
+				writeCanonicalText(c.w, string(tt))
+			}
+		case xml.Comment:
+			if o.WithComments {
+				c.writeOutside("<!--" + string(tt) + "-->")
+			}
+		case xml.ProcInst:
+			// the XML declaration is removed
+			if tt.Target == "xml" {
+				continue
+			}
+			pi := "<?" + tt.Target
+			if len(tt.Inst) != 0 {
+				pi += " " + string(tt.Inst)
+			}
+			c.writeOutside(pi + "?>")
+		}
+		// directives, i.e. the document type declaration, are removed
+	}
+
+	if !c.done {
+		return io.ErrUnexpectedEOF
+	}
+	return c.w.Flush()
+}
+
+// canonicalizer is the state of the canonicalization of a document.
+type canonicalizer struct {
+	w *bufio.Writer
+
+	// prefixes of namespaces rendered as with inclusive canonicalization
+	inclusive map[string]bool
+
+	// elements that have not been closed, innermost last
+	stack []canonicalElement
+
+	// whether the root element has been closed
+	done bool
+}
+
+// canonicalElement is an element whose end element has not been written.
+type canonicalElement struct {
+	name string
+
+	// namespace prefix to URI, in scope of the element
+	inScope map[string]string
+
+	// namespace prefix to URI, rendered by the element or its ancestors
+	rendered map[string]string
+}
+
+// writeOutside writes a comment or processing instruction. Those outside of
+// the root element are separated from it by a newline.
+func (c *canonicalizer) writeOutside(s string) {
+	switch {
+	case c.done:
+		c.w.WriteByte('\n')
+		c.w.WriteString(s)
+	case len(c.stack) == 0:
+		c.w.WriteString(s)
+		c.w.WriteByte('\n')
+	default:
+		c.w.WriteString(s)
+	}
+}
+
+func (c *canonicalizer) startElement(t xml.StartElement) error {
+	parent := canonicalElement{
+		inScope:  map[string]string{"xml": xmlNamespaceURI},
+		rendered: map[string]string{},
+	}
+	if len(c.stack) != 0 {
+		parent = c.stack[len(c.stack)-1]
+	}
+
+	el := canonicalElement{
+		name:     rawName(t.Name),
+		inScope:  parent.inScope,
+		rendered: parent.rendered,
+	}
+
+	var attrs []canonicalAttr
+	declared := false
+	for _, a := range t.Attr {
+		switch {
+		case a.Name.Space == "xmlns" || len(a.Name.Space) == 0 && a.Name.Local == "xmlns":
+			if !declared {
+				el.inScope = copyNamespaces(parent.inScope)
+				declared = true
+			}
+			prefix := a.Name.Local
+			if len(a.Name.Space) == 0 {
+				prefix = ""
+			}
+			el.inScope[prefix] = a.Value
+		default:
+			attrs = append(attrs, canonicalAttr{Attr: Attr{Name: Name(a.Name), Value: a.Value}})
+		}
+	}
+
+	// namespaces visibly utilized by the element and its attributes
+	utilized := map[string]bool{t.Name.Space: true}
+	for i, a := range attrs {
+		if len(a.Name.Space) == 0 {
+			continue
+		}
+		uri, ok := el.inScope[a.Name.Space]
+		if !ok {
+			return fmt.Errorf("element %s attribute %s namespace prefix %q is not declared",
+				el.name, rawName(xml.Name(a.Name)), a.Name.Space)
+		}
+		attrs[i].uri = uri
+		utilized[a.Name.Space] = true
+	}
+	if _, ok := el.inScope[t.Name.Space]; !ok && len(t.Name.Space) != 0 {
+		return fmt.Errorf("element %s namespace prefix %q is not declared", el.name, t.Name.Space)
+	}
+	for p := range c.inclusive {
+		if _, ok := el.inScope[p]; ok {
+			utilized[p] = true
+		}
+	}
+
+	var namespaces []Namespace
+	for p := range utilized {
+		if p == "xml" {
+			continue
+		}
+		uri := el.inScope[p]
+		if rendered, ok := el.rendered[p]; ok && rendered == uri || !ok && len(uri) == 0 {
+			continue
+		}
+		namespaces = append(namespaces, Namespace{Prefix: p, URI: uri})
+	}
+	if len(namespaces) != 0 {
+		el.rendered = copyNamespaces(parent.rendered)
+		for _, ns := range namespaces {
+			el.rendered[ns.Prefix] = ns.URI
+		}
+	}
+
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Prefix < namespaces[j].Prefix })
+	sort.SliceStable(attrs, func(i, j int) bool {
+		if attrs[i].uri != attrs[j].uri {
+			return attrs[i].uri < attrs[j].uri
+		}
+		return attrs[i].Name.Local < attrs[j].Name.Local
+	})
+
+	c.w.WriteByte('<')
+	c.w.WriteString(el.name)
+	for _, ns := range namespaces {
+		c.w.WriteString(" xmlns")
+		if len(ns.Prefix) != 0 {
+			c.w.WriteByte(':')
+			c.w.WriteString(ns.Prefix)
+		}
+		c.w.WriteString(`="`)
+		writeCanonicalAttrValue(c.w, ns.URI)
+		c.w.WriteByte('"')
+	}
+	for _, a := range attrs {
+		c.w.WriteByte(' ')
+		c.w.WriteString(rawName(xml.Name(a.Name)))
+		c.w.WriteString(`="`)
+		writeCanonicalAttrValue(c.w, a.Value)
+		c.w.WriteByte('"')
+	}
+	c.w.WriteByte('>')
+
+	c.stack = append(c.stack, el)
+	return nil
+}
+
+func (c *canonicalizer) endElement(t xml.EndElement) error {
+	name := rawName(t.Name)
+	if len(c.stack) == 0 {
+		return fmt.Errorf("unexpected end element %s", name)
+	}
+	if top := c.stack[len(c.stack)-1]; top.name != name {
+		return fmt.Errorf("element %s closed by end element %s", top.name, name)
+	}
+
+	c.w.WriteString("</")
+	c.w.WriteString(name)
+	c.w.WriteByte('>')
+
+	c.stack = c.stack[:len(c.stack)-1]
+	c.done = len(c.stack) == 0
+	return nil
+}
+
+// canonicalAttr is an attribute with its namespace URI resolved.
+type canonicalAttr struct {
+	Attr
+	uri string
+}
+
+func copyNamespaces(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m)+1)
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// rawName returns the name as written in the document, with its prefix.
+func rawName(n xml.Name) string {
+	if len(n.Space) == 0 {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+var canonicalTextReplacer = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"\r", "&#xD;",
+)
+
+var canonicalAttrValueReplacer = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	`"`, "&quot;",
+	"\t", "&#x9;",
+	"\n", "&#xA;",
+	"\r", "&#xD;",
+)
+
+func writeCanonicalText(w io.Writer, s string) {
+	canonicalTextReplacer.WriteString(w, s)
+}
+
+func writeCanonicalAttrValue(w io.Writer, s string) {
+	canonicalAttrValueReplacer.WriteString(w, s)
+}
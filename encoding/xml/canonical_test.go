@@ -0,0 +1,153 @@
+package xml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	cases := map[string]struct {
+		input     string
+		optFns    []func(*CanonicalOptions)
+		expected  string
+		expectErr string
+	}{
+		"declaration, doctype, and outside whitespace": {
+			input:    "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<!DOCTYPE doc>\n<doc>\n  <a/>\n</doc>\n",
+			expected: "<doc>\n  <a></a>\n</doc>",
+		},
+		"attribute order": {
+			input: `<doc xmlns:b="https://b.example.com" xmlns:a="https://a.example.com" ` +
+				`b:attr="1" z="2" a:attr="3" y="4"/>`,
+			expected: `<doc xmlns:a="https://a.example.com" xmlns:b="https://b.example.com" ` +
+				`y="4" z="2" a:attr="3" b:attr="1"></doc>`,
+		},
+		"attribute order by namespace uri rather than prefix": {
+			input:    `<doc xmlns:a="https://z.example.com" xmlns:z="https://a.example.com" a:x="1" z:x="2"/>`,
+			expected: `<doc xmlns:a="https://z.example.com" xmlns:z="https://a.example.com" z:x="2" a:x="1"></doc>`,
+		},
+		"unused namespaces are removed": {
+			input:    `<doc xmlns:a="https://a.example.com" xmlns:b="https://b.example.com"><a:el/></doc>`,
+			expected: `<doc><a:el xmlns:a="https://a.example.com"></a:el></doc>`,
+		},
+		"namespaces are not redeclared": {
+			input: `<a:doc xmlns:a="https://a.example.com"><a:el xmlns:a="https://a.example.com">` +
+				`<a:el xmlns:a="https://other.example.com"/></a:el></a:doc>`,
+			expected: `<a:doc xmlns:a="https://a.example.com"><a:el>` +
+				`<a:el xmlns:a="https://other.example.com"></a:el></a:el></a:doc>`,
+		},
+		"default namespace": {
+			input: `<doc xmlns="https://example.com"><el xmlns=""><inner/></el>` +
+				`<el xmlns:a="https://a.example.com"/></doc>`,
+			expected: `<doc xmlns="https://example.com"><el xmlns=""><inner></inner></el>` +
+				`<el></el></doc>`,
+		},
+		"empty default namespace not declared": {
+			input:    `<doc xmlns=""><el/></doc>`,
+			expected: `<doc><el></el></doc>`,
+		},
+		"xml prefix": {
+			input:    `<doc xml:lang="en" a="1"/>`,
+			expected: `<doc a="1" xml:lang="en"></doc>`,
+		},
+		"escapes and cdata": {
+			input:    "<doc a='&apos;\"&lt;&gt;&#x9;&#xA;&#xD;'>&#34;&apos;<![CDATA[<&>]]>&#xD;</doc>",
+			expected: "<doc a=\"'&quot;&lt;>&#x9;&#xA;&#xD;\">\"'&lt;&amp;&gt;&#xD;</doc>",
+		},
+		"comments removed": {
+			input:    `<!-- before --><doc><!-- inside -->a</doc><!-- after -->`,
+			expected: `<doc>a</doc>`,
+		},
+		"comments": {
+			input:    `<!-- before --><doc><!-- inside -->a</doc><!-- after -->`,
+			optFns:   []func(*CanonicalOptions){func(o *CanonicalOptions) { o.WithComments = true }},
+			expected: "<!-- before -->\n<doc><!-- inside -->a</doc>\n<!-- after -->",
+		},
+		"processing instructions": {
+			input:    `<?xml version="1.0"?><?pi data?><doc><?empty?></doc>`,
+			expected: "<?pi data?>\n<doc><?empty?></doc>",
+		},
+		"inclusive namespaces": {
+			input: `<doc xmlns="https://example.com" xmlns:a="https://a.example.com" xmlns:b="https://b.example.com">` +
+				`<x:el xmlns:x="https://x.example.com"/></doc>`,
+			optFns: []func(*CanonicalOptions){func(o *CanonicalOptions) {
+				o.InclusiveNamespaces = []string{"a", "#default", "missing"}
+			}},
+			expected: `<doc xmlns="https://example.com" xmlns:a="https://a.example.com">` +
+				`<x:el xmlns:x="https://x.example.com"></x:el></doc>`,
+		},
+		"undeclared element prefix": {
+			input:     `<a:doc/>`,
+			expectErr: `element a:doc namespace prefix "a" is not declared`,
+		},
+		"undeclared attribute prefix": {
+			input:     `<doc a:b="1"/>`,
+			expectErr: `element doc attribute a:b namespace prefix "a" is not declared`,
+		},
+		"mismatched end element": {
+			input:     `<doc><a></b></doc>`,
+			expectErr: "element a closed by end element b",
+		},
+		"unclosed root": {
+			input:     `<doc><a></a>`,
+			expectErr: "unexpected EOF",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Canonicalize(&buf, strings.NewReader(c.input), c.optFns...)
+			if len(c.expectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error")
+				}
+				if e, a := c.expectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect %v in error, got %v", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.expected, buf.String(); e != a {
+				t.Errorf("expect\n%v\ngot\n%v", e, a)
+			}
+		})
+	}
+}
+
+func TestCanonicalize_EquivalentDocuments(t *testing.T) {
+	encoder := NewEncoder(bytes.NewBuffer(nil))
+	root := encoder.RootElementNS(StartElement{
+		Name: Name{Local: "doc"},
+		Attr: []Attr{NewAttribute("b", "2"), NewAttribute("a", "1")},
+	}, Namespace{URI: "https://example.com", IsDefault: true}, Namespace{Prefix: "x", URI: "https://x.example.com"})
+	root.MemberElement(StartElement{Name: Name{Local: "empty"}}).Close()
+	root.Close()
+
+	documents := []string{
+		encoder.String(),
+		`<?xml version="1.0"?>
+<doc a="1" b="2" xmlns:x="https://x.example.com" xmlns='https://example.com'><empty/></doc>`,
+	}
+
+	var expected string
+	for i, doc := range documents {
+		var buf bytes.Buffer
+		if err := Canonicalize(&buf, strings.NewReader(doc)); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if i == 0 {
+			expected = buf.String()
+			continue
+		}
+		if e, a := expected, buf.String(); e != a {
+			t.Errorf("expect %v, got %v", e, a)
+		}
+	}
+	if e, a := `<doc xmlns="https://example.com" a="1" b="2"><empty></empty></doc>`, expected; e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
@@ -0,0 +1,229 @@
+package xml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ListOptions describes the XML representation of a list, see
+// NodeDecoder.DecodeList.
+type ListOptions struct {
+	// The list is flattened, such that each member is an element named for
+	// the list, rather than wrapped in a list element.
+	Flattened bool
+
+	// The element name of members of a wrapped list. Defaults to "member".
+	MemberName string
+}
+
+// MapOptions describes the XML representation of a map, see
+// NodeDecoder.DecodeMap.
+type MapOptions struct {
+	// The map is flattened, such that each entry is an element named for the
+	// map, rather than wrapped in a map element.
+	Flattened bool
+
+	// The element name of entries of a wrapped map. Defaults to "entry".
+	EntryName string
+
+	// The element names of the key and value of each entry. Default to "key"
+	// and "value".
+	KeyName   string
+	ValueName string
+}
+
+// DecodeList calls fn with the decoder of each member of the list, in
+// document order, such that wrapped and flattened lists, and lists with
+// custom member names, are decoded alike.
+//
+// For a wrapped list, the NodeDecoder is of the list element, and elements
+// other than members are skipped. For a flattened list, the NodeDecoder is of
+// the single member being decoded, i.e. an element named for the list, and fn
+// is called with it once.
+//
+// fn must decode the member through its end element, e.g. with Value.
+func (d NodeDecoder) DecodeList(o ListOptions, fn func(member NodeDecoder) error) error {
+	if o.Flattened {
+		return fn(d)
+	}
+
+	memberName := o.MemberName
+	if len(memberName) == 0 {
+		memberName = "member"
+	}
+
+	for {
+		t, done, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		if !strings.EqualFold(memberName, t.Name.Local) {
+			if err := d.Decoder.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(WrapNodeDecoder(d.Decoder, t)); err != nil {
+			return err
+		}
+	}
+}
+
+// DecodeMap calls fn with the key, and the decoder of the value element, of
+// each entry of the map, in document order, such that wrapped and flattened
+// maps, and maps with custom entry, key, and value names, are decoded alike.
+//
+// For a wrapped map, the NodeDecoder is of the map element, and elements
+// other than entries are skipped. For a flattened map, the NodeDecoder is of
+// the single entry being decoded, i.e. an element named for the map, and fn
+// is called with it once.
+//
+// An entry's value may precede its key, in which case the value is buffered
+// until the key is decoded. An entry without a value element is decoded as
+// having an empty value element. Returns an error if an entry has no key.
+//
+// fn must decode the value through its end element, e.g. with Value.
+func (d NodeDecoder) DecodeMap(o MapOptions, fn func(key string, value NodeDecoder) error) error {
+	if len(o.EntryName) == 0 {
+		o.EntryName = "entry"
+	}
+	if len(o.KeyName) == 0 {
+		o.KeyName = "key"
+	}
+	if len(o.ValueName) == 0 {
+		o.ValueName = "value"
+	}
+
+	if o.Flattened {
+		return decodeMapEntry(d, o, fn)
+	}
+
+	for {
+		t, done, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		if !strings.EqualFold(o.EntryName, t.Name.Local) {
+			if err := d.Decoder.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := decodeMapEntry(WrapNodeDecoder(d.Decoder, t), o, fn); err != nil {
+			return err
+		}
+	}
+}
+
+func decodeMapEntry(d NodeDecoder, o MapOptions, fn func(string, NodeDecoder) error) error {
+	var key string
+	var hasKey, hasValue bool
+
+	// value decoded before its key
+	var value *Node
+
+	for {
+		t, done, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if done {
+			break
+		}
+
+		switch {
+		case !hasKey && strings.EqualFold(o.KeyName, t.Name.Local):
+			k, err := WrapNodeDecoder(d.Decoder, t).Value()
+			if err != nil {
+				return fmt.Errorf("decode map key, %w", err)
+			}
+			key, hasKey = string(k), true
+
+			if value != nil {
+				if err := fn(key, value.decoder()); err != nil {
+					return err
+				}
+			}
+
+		case !hasValue && strings.EqualFold(o.ValueName, t.Name.Local):
+			hasValue = true
+			vd := WrapNodeDecoder(d.Decoder, t)
+			if hasKey {
+				if err := fn(key, vd); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if value, err = vd.Node(); err != nil {
+				return fmt.Errorf("decode map value, %w", err)
+			}
+
+		default:
+			if err := d.Decoder.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !hasKey {
+		return fmt.Errorf("map entry has no %s element", o.KeyName)
+	}
+	if !hasValue {
+		return fn(key, (&Node{Name: Name{Local: o.ValueName}}).decoder())
+	}
+	return nil
+}
+
+// decoder returns a NodeDecoder of the element, whose tokens are replayed
+// from the node.
+func (n *Node) decoder() NodeDecoder {
+	r := &nodeTokenReader{}
+	r.append(n)
+
+	d := xml.NewTokenDecoder(r)
+	// the first token is the node's start element
+	t, _ := d.Token()
+	return WrapNodeDecoder(d, t.(xml.StartElement))
+}
+
+// nodeTokenReader is an xml.TokenReader of the tokens of a node.
+type nodeTokenReader struct {
+	tokens []xml.Token
+}
+
+func (r *nodeTokenReader) append(n *Node) {
+	start := xml.StartElement{Name: xml.Name(n.Name)}
+	for _, a := range n.Attr {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name(a.Name), Value: a.Value})
+	}
+
+	r.tokens = append(r.tokens, start)
+	if len(n.Text) != 0 {
+		r.tokens = append(r.tokens, xml.CharData(n.Text))
+	}
+	for _, c := range n.Children {
+		r.append(c)
+	}
+	r.tokens = append(r.tokens, start.End())
+}
+
+func (r *nodeTokenReader) Token() (xml.Token, error) {
+	if len(r.tokens) == 0 {
+		return nil, io.EOF
+	}
+
+	t := r.tokens[0]
+	r.tokens = r.tokens[1:]
+	return t, nil
+}
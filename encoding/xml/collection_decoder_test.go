@@ -0,0 +1,187 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestNodeDecoder(t *testing.T, input string) NodeDecoder {
+	t.Helper()
+
+	d := xml.NewDecoder(strings.NewReader(input))
+	root, err := FetchRootElement(d)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	return WrapNodeDecoder(d, root)
+}
+
+func TestNodeDecoder_DecodeList(t *testing.T) {
+	cases := map[string]struct {
+		input   string
+		options ListOptions
+		expect  []string
+	}{
+		"wrapped": {
+			input:  `<List><member>a</member><other>x</other><member>b</member></List>`,
+			expect: []string{"a", "b"},
+		},
+		"custom member name": {
+			input:   `<List><Item>a</Item><item>b</item></List>`,
+			options: ListOptions{MemberName: "Item"},
+			expect:  []string{"a", "b"},
+		},
+		"empty": {
+			input: `<List/>`,
+		},
+		"flattened": {
+			input:   `<List>a</List>`,
+			options: ListOptions{Flattened: true},
+			expect:  []string{"a"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var actual []string
+			err := newTestNodeDecoder(t, c.input).DecodeList(c.options, func(member NodeDecoder) error {
+				v, err := member.Value()
+				actual = append(actual, string(v))
+				return err
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.expect, actual; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestNodeDecoder_DecodeMap(t *testing.T) {
+	cases := map[string]struct {
+		input   string
+		options MapOptions
+		expect  map[string]string
+		err     string
+	}{
+		"wrapped": {
+			input: `<Map><entry><key>a</key><value>1</value></entry>` +
+				`<entry><key>b</key><value>2</value></entry></Map>`,
+			expect: map[string]string{"a": "1", "b": "2"},
+		},
+		"custom names": {
+			input: `<Map><Attribute><Name>a</Name><Value>1</Value></Attribute>` +
+				`<skipped/><Attribute><Name>b</Name><Value>2</Value></Attribute></Map>`,
+			options: MapOptions{EntryName: "Attribute", KeyName: "Name", ValueName: "Value"},
+			expect:  map[string]string{"a": "1", "b": "2"},
+		},
+		"flattened": {
+			input:   `<Map><key>a</key><value>1</value></Map>`,
+			options: MapOptions{Flattened: true},
+			expect:  map[string]string{"a": "1"},
+		},
+		"value before key": {
+			input:  `<Map><entry><value>1</value><key>a</key></entry></Map>`,
+			expect: map[string]string{"a": "1"},
+		},
+		"no value": {
+			input:  `<Map><entry><key>a</key></entry></Map>`,
+			expect: map[string]string{"a": ""},
+		},
+		"no key": {
+			input: `<Map><entry><value>1</value></entry></Map>`,
+			err:   "map entry has no key element",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := map[string]string{}
+			err := newTestNodeDecoder(t, c.input).DecodeMap(c.options, func(key string, value NodeDecoder) error {
+				v, err := value.Value()
+				actual[key] = string(v)
+				return err
+			})
+			if len(c.err) != 0 {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expect error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.expect, actual; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestNodeDecoder_DecodeMapNestedValue(t *testing.T) {
+	input := `<Map><entry><value><List><member>x</member><member>y</member></List></value><key>a</key></entry>` +
+		`<entry><key>b</key><value><List><member>z</member></List></value></entry></Map>`
+
+	actual := map[string][]string{}
+	err := newTestNodeDecoder(t, input).DecodeMap(MapOptions{}, func(key string, value NodeDecoder) error {
+		t, err := value.GetElement("List")
+		if err != nil {
+			return err
+		}
+		err = WrapNodeDecoder(value.Decoder, t).DecodeList(ListOptions{}, func(member NodeDecoder) error {
+			v, err := member.Value()
+			actual[key] = append(actual[key], string(v))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		// consume the value's end element
+		_, _, err = value.Token()
+		return err
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	expect := map[string][]string{"a": {"x", "y"}, "b": {"z"}}
+	if !reflect.DeepEqual(expect, actual) {
+		t.Errorf("expect %v, got %v", expect, actual)
+	}
+}
+
+func TestNodeDecoder_DecodeListTrailingElements(t *testing.T) {
+	input := `<Response><List><member>a</member></List><Count>1</Count></Response>`
+	d := newTestNodeDecoder(t, input)
+
+	list, err := d.GetElement("List")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	var members [][]byte
+	err = WrapNodeDecoder(d.Decoder, list).DecodeList(ListOptions{}, func(member NodeDecoder) error {
+		v, err := member.Value()
+		members = append(members, v)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := [][]byte{[]byte("a")}, members; len(a) != 1 || !bytes.Equal(e[0], a[0]) {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+
+	count, err := d.GetElement("Count")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	v, err := WrapNodeDecoder(d.Decoder, count).Value()
+	if err != nil || string(v) != "1" {
+		t.Errorf("expect count 1, got %q, %v", v, err)
+	}
+}
@@ -45,5 +45,50 @@ Flattened map rely on Value being marked as flattened.
 If a shape is marked as flattened, Map() will use the shape element name as wrapper for map entry elements.
 
 	<flattenedMap><Key>apple</Key><Value>tree</Value></flattenedMap><flattenedMap><Key>snow</Key><Value>ice</Value></flattenedMap>
+
+Namespaces
+
+RootElementNS and MemberElementNS encode elements with namespace management. Namespaces are declared once on an
+element, and are in scope of its descendants. Element and attribute names are qualified by namespace URI, which is
+resolved to the prefix bound in scope, and a prefix is generated for a namespace declared without one. A namespace
+that is not in scope is reported by Encoder.Err.
+
+	root := encoder.RootElementNS(rootElement, xml.Namespace{URI: "https://example.com", IsDefault: true},
+		xml.Namespace{URI: "https://example.com/ext"})
+
+encodes `<root xmlns="https://example.com" xmlns:ns1="https://example.com/ext">`, such that a member element with
+Name.Space "https://example.com/ext" is written as `<ns1:member>`.
+
+Streaming
+
+NewStreamEncoder returns an encoder that writes the document to an io.Writer as elements are closed, rather than
+building the entire document in memory. Encoder.Flush must be called once the root element is closed.
+
+Canonicalization
+
+Canonicalize writes the exclusive canonical form of an XML document, see https://www.w3.org/TR/xml-exc-c14n/, such
+that equivalent documents, e.g. with attributes in a different order, are written as the same bytes for signing or
+snapshot testing.
+
+	var buf bytes.Buffer
+	err := xml.Canonicalize(&buf, bytes.NewReader(encoder.Bytes()))
+
+Node Decoding
+
+DecodeNode decodes an XML document into a tree of Node elements, each with its name, attributes, children, and
+character data. NodeDecoder.Node decodes a single element of a document being walked with a NodeDecoder.
+
+	<Response><Items><member>a</member></Items></Response>
+
+decodes such that node.Child("Items").ChildrenNamed("member")[0].Text is "a".
+
+Attributes
+
+NodeDecoder.Attributes and Node.Attributes return the attributes of an element, with typed accessors of attribute
+values by local name, by prefixed name, or by namespace URI with GetNS.
+
+	<Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="CanonicalUser" id="1"/>
+
+decodes such that attrs.String("xsi:type") is "CanonicalUser", and attrs.Int64("id") is 1.
 */
 package xml
@@ -1,5 +1,13 @@
 package xml
 
+import "sync"
+
+var encoderPool = sync.Pool{
+	New: func() interface{} {
+		return NewEncoder(nil)
+	},
+}
+
 // writer interface used by the xml encoder to write an encoded xml
 // document in a writer.
 type writer interface {
@@ -25,13 +33,84 @@ type writer interface {
 type Encoder struct {
 	w       writer
 	scratch *[]byte
+
+	// base namespace scope, of root elements
+	ns *namespaceScope
+}
+
+// EncoderOptions is the set of options that can be configured for an
+// Encoder.
+type EncoderOptions struct {
+	// If set, newline, tab, and carriage return characters in text and
+	// attribute values are written as is, rather than as numeric character
+	// references, e.g. "&#xA;". As are the U+0085 and U+2028 line
+	// separators, unless EscapeNonASCII is set.
+	//
+	// XML parsers normalize carriage returns in text to newlines, and each of
+	// these characters in attribute values to a space, when written as is.
+	RawWhitespace bool
+
+	// If set, non-ASCII characters in text and attribute values are written
+	// as numeric character references, e.g. "&#xe9;", rather than as UTF-8.
+	// Text written with Value.WriteCDATA is not affected.
+	EscapeNonASCII bool
+
+	// How Value.NilableStringElement writes a nil string. Defaults to
+	// NilStringOmit.
+	NilStrings NilStringMode
 }
 
 // NewEncoder returns an XML encoder
-func NewEncoder(w writer) *Encoder {
+func NewEncoder(w writer, optFns ...func(*EncoderOptions)) *Encoder {
+	var o EncoderOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
 	scratch := make([]byte, 64)
+	ns := newBaseNamespaceScope()
+	ns.state.escape = escapePolicy{
+		rawWhitespace:  o.RawWhitespace,
+		escapeNonASCII: o.EscapeNonASCII,
+	}
+	ns.state.nilStrings = o.NilStrings
+
+	return &Encoder{w: w, scratch: &scratch, ns: ns}
+}
 
-	return &Encoder{w: w, scratch: &scratch}
+// Reset discards the state of the encoder, such that it encodes a new
+// document to w with the same options, reusing its internal buffers. Values
+// of the previous document must not be used after Reset.
+//
+// The writer of a stream encoder is replaced by w, such that it no longer
+// writes to its io.Writer. Use NewStreamEncoder for another stream instead.
+func (e *Encoder) Reset(w writer) {
+	e.w = w
+	e.ns.reset()
+}
+
+// GetEncoder returns an Encoder from a shared pool, reset to encode a new
+// document to w. Callers should return the Encoder with PutEncoder once the
+// document is encoded.
+func GetEncoder(w writer) *Encoder {
+	e := encoderPool.Get().(*Encoder)
+	e.Reset(w)
+	return e
+}
+
+// PutEncoder returns e to the pool used by GetEncoder. Neither e nor any Value
+// of its document may be used after calling PutEncoder. Encoders configured
+// with options, and stream encoders, are not pooled.
+func PutEncoder(e *Encoder) {
+	if _, ok := e.w.(*streamWriter); ok {
+		return
+	}
+	if e.ns.state.escape != (escapePolicy{}) || e.ns.state.nilStrings != NilStringOmit {
+		return
+	}
+
+	e.Reset(nil)
+	encoderPool.Put(e)
 }
 
 // String returns the string output of the XML encoder
@@ -47,5 +126,42 @@ func (e Encoder) Bytes() []byte {
 // RootElement builds a root element encoding
 // It writes it's start element tag. The value should be closed.
 func (e Encoder) RootElement(element StartElement) Value {
-	return newValue(e.w, e.scratch, element)
+	return newElementValue(e.w, e.scratch, e.ns, element)
+}
+
+// RootElementNS builds a root element encoding with namespace management,
+// declaring the namespaces on the element. The value should be closed.
+//
+// The Name.Space of the element, and of its descendants, is a namespace URI
+// rather than a prefix, which is resolved to the prefix bound to it in scope.
+// An element without a Name.Space is written without prefix, and so is in the
+// default namespace in scope, if any. Namespace attributes of elements are
+// declared as namespaces, and namespaces already in scope are not redeclared.
+// A namespace that is not in scope is reported by Err.
+func (e Encoder) RootElementNS(element StartElement, namespaces ...Namespace) Value {
+	return newNamespacedValue(e.w, e.scratch, e.ns, element, namespaces)
+}
+
+// Flush writes the content buffered by a stream encoder, see
+// NewStreamEncoder, to its io.Writer. Returns the first error writing to the
+// io.Writer. Flush does nothing for other encoders.
+func (e Encoder) Flush() error {
+	if s, ok := e.w.(*streamWriter); ok {
+		return s.flush()
+	}
+	return nil
+}
+
+// Err returns the first error resolving the namespaces of elements and
+// attributes encoded with namespace management, see RootElementNS, or reading
+// the content of a value from an io.Reader, see Value.StringFromReader, or
+// else the first error writing to the io.Writer of a stream encoder.
+func (e Encoder) Err() error {
+	if e.ns != nil && e.ns.state.err != nil {
+		return e.ns.state.err
+	}
+	if s, ok := e.w.(*streamWriter); ok {
+		return s.err
+	}
+	return nil
 }
@@ -0,0 +1,46 @@
+//go:build !race
+// +build !race
+
+// The race detector randomly drops items put into a sync.Pool, so allocation
+// counts are only meaningful without it.
+
+package xml_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/smithy-go/encoding/xml"
+)
+
+func TestEncoder_ResetAllocations(t *testing.T) {
+	b := bytes.NewBuffer(make([]byte, 0, 1024))
+	member := xml.StartElement{Name: xml.Name{Local: "member"}, Attr: []xml.Attr{xml.NewAttribute("a", "<&>")}}
+	encode := func(e *xml.Encoder) {
+		r := e.RootElement(root)
+		r.MemberElement(member).String("abc & é")
+		r.MemberElement(member).Long(1024)
+		r.MemberElement(member).Boolean(true)
+		r.Close()
+	}
+
+	encoder := xml.NewEncoder(b, func(o *xml.EncoderOptions) { o.EscapeNonASCII = true })
+	if n := testing.AllocsPerRun(10, func() {
+		b.Reset()
+		encoder.Reset(b)
+		encode(encoder)
+	}); n != 0 {
+		t.Errorf("expect no allocations, got %v", n)
+	}
+
+	// warm the pool
+	xml.PutEncoder(xml.GetEncoder(b))
+	if n := testing.AllocsPerRun(10, func() {
+		b.Reset()
+		e := xml.GetEncoder(b)
+		encode(e)
+		xml.PutEncoder(e)
+	}); n > 1 {
+		t.Errorf("expect at most 1 allocation, got %v", n)
+	}
+}
@@ -558,3 +558,112 @@ func ExampleEncoder() {
 	m2.MemberElement(value).Integer(123)
 	m2.Close()
 }
+
+func TestEncoderOptions_Escaping(t *testing.T) {
+	const text = "a\tb\nc\r\u00e9\u2028<"
+
+	cases := map[string]struct {
+		optFns []func(*xml.EncoderOptions)
+		expect string
+	}{
+		"default": {
+			expect: `<root attr="a&#x9;b&#xA;c&#xD;` + "\u00e9" + `&#x2028;&lt;">` +
+				`a&#x9;b&#xA;c&#xD;` + "\u00e9" + `&#x2028;&lt;</root>`,
+		},
+		"raw whitespace": {
+			optFns: []func(*xml.EncoderOptions){func(o *xml.EncoderOptions) {
+				o.RawWhitespace = true
+			}},
+			expect: `<root attr="` + "a\tb\nc\r\u00e9\u2028" + `&lt;">` + "a\tb\nc\r\u00e9\u2028" + `&lt;</root>`,
+		},
+		"escape non-ASCII": {
+			optFns: []func(*xml.EncoderOptions){func(o *xml.EncoderOptions) {
+				o.EscapeNonASCII = true
+			}},
+			expect: `<root attr="a&#x9;b&#xA;c&#xD;&#xe9;&#x2028;&lt;">a&#x9;b&#xA;c&#xD;&#xe9;&#x2028;&lt;</root>`,
+		},
+		"raw whitespace, escape non-ASCII": {
+			optFns: []func(*xml.EncoderOptions){func(o *xml.EncoderOptions) {
+				o.RawWhitespace = true
+				o.EscapeNonASCII = true
+			}},
+			expect: `<root attr="` + "a\tb\nc\r" + `&#xe9;&#x2028;&lt;">` + "a\tb\nc\r" + `&#xe9;&#x2028;&lt;</root>`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder := xml.NewEncoder(bytes.NewBuffer(nil), c.optFns...)
+			encoder.RootElement(xml.StartElement{
+				Name: xml.Name{Local: "root"},
+				Attr: []xml.Attr{xml.NewAttribute("attr", text)},
+			}).String(text)
+
+			if e, a := c.expect, encoder.String(); e != a {
+				t.Errorf("expect %q, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestEncoderOptions_EscapeNonASCIIWrite(t *testing.T) {
+	encoder := xml.NewEncoder(bytes.NewBuffer(nil), func(o *xml.EncoderOptions) {
+		o.EscapeNonASCII = true
+	})
+	r := encoder.RootElementNS(root)
+	r.MemberElement(xml.StartElement{Name: xml.Name{Local: "v"}}).Write([]byte("\U0001F600"), true)
+	r.Close()
+
+	if e, a := `<root><v>&#x1f600;</v></root>`, encoder.String(); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+}
+
+func TestEncoder_Reset(t *testing.T) {
+	encoder := xml.NewEncoder(bytes.NewBuffer(nil), func(o *xml.EncoderOptions) {
+		o.EscapeNonASCII = true
+	})
+	encode := func() {
+		r := encoder.RootElementNS(root, xml.Namespace{URI: "https://example.com"})
+		r.MemberElement(xml.StartElement{Name: xml.Name{Space: "https://example.com", Local: "v"}}).String("é")
+		r.MemberElement(xml.StartElement{Name: xml.Name{Space: "https://undeclared.example.com", Local: "u"}}).Close()
+		r.Close()
+	}
+
+	encode()
+	first := encoder.String()
+	if encoder.Err() == nil {
+		t.Fatalf("expect undeclared namespace error")
+	}
+
+	b := bytes.NewBuffer(nil)
+	encoder.Reset(b)
+	if err := encoder.Err(); err != nil {
+		t.Fatalf("expect error to be reset, got %v", err)
+	}
+	if e, a := "", encoder.String(); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+
+	// generated prefixes and options are as for the first document
+	encode()
+	if e, a := first, b.String(); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+	if e, a := `<root xmlns:ns1="https://example.com"><ns1:v>&#xe9;</ns1:v><u></u></root>`, first; e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+}
+
+func TestGetEncoder(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		b := bytes.NewBuffer(nil)
+		encoder := xml.GetEncoder(b)
+		encoder.RootElement(root).String("abc")
+
+		if e, a := `<root>abc</root>`, b.String(); e != a {
+			t.Errorf("expect %q, got %q", e, a)
+		}
+		xml.PutEncoder(encoder)
+	}
+}
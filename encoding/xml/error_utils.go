@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"strings"
 )
 
 // ErrorComponents represents the error response fields
@@ -11,6 +12,9 @@ import (
 type ErrorComponents struct {
 	Code    string
 	Message string
+
+	// RequestID is only set by SniffErrorComponents.
+	RequestID string
 }
 
 // GetErrorResponseComponents returns the error fields from an xml error response body
@@ -49,3 +53,84 @@ type wrappedErrorResponse struct {
 	Code    string `xml:"Error>Code"`
 	Message string `xml:"Error>Message"`
 }
+
+// SniffErrorComponents returns the error fields from an xml error response
+// body, without decoding the entire body. Both the wrapped form, in which the
+// Code and Message are within an <Error> element of the root element, e.g.
+// <ErrorResponse><Error><Code>, and the unwrapped form, in which they are
+// elements of the root <Error> or <ErrorResponse> element, are supported, as
+// are errors within an <Errors> list. The RequestId, or RequestID, element is
+// looked for alongside them, and the first of each element found is used.
+//
+// The body is read only until each of the fields is found, and elements
+// other than those of the fields and their wrappers are skipped.
+func SniffErrorComponents(r io.Reader) (ErrorComponents, error) {
+	var ec ErrorComponents
+
+	d := xml.NewDecoder(r)
+	root, err := FetchRootElement(d)
+	if err == io.EOF {
+		return ec, nil
+	}
+	if err != nil {
+		return ec, fmt.Errorf("error while deserializing xml error response: %w", err)
+	}
+
+	if _, err := sniffErrorElement(WrapNodeDecoder(d, root), &ec, 0); err != nil {
+		return ErrorComponents{}, fmt.Errorf("error while deserializing xml error response: %w", err)
+	}
+	return ec, nil
+}
+
+// maxErrorWrapperDepth is the depth of wrapper elements within the root
+// element of an error response, e.g. <Response><Errors><Error>, searched by
+// SniffErrorComponents.
+const maxErrorWrapperDepth = 2
+
+// sniffErrorElement sets the fields of ec not yet set from the elements of
+// the node decoder's element, or its error wrapper elements, returning true
+// once all fields are set.
+func sniffErrorElement(d NodeDecoder, ec *ErrorComponents, depth int) (bool, error) {
+	for {
+		t, done, err := d.Token()
+		if err != nil {
+			return false, err
+		}
+		if done {
+			return false, nil
+		}
+
+		var field *string
+		switch name := t.Name.Local; {
+		case strings.EqualFold("Code", name):
+			field = &ec.Code
+		case strings.EqualFold("Message", name):
+			field = &ec.Message
+		case strings.EqualFold("RequestId", name):
+			field = &ec.RequestID
+		case depth < maxErrorWrapperDepth && (strings.EqualFold("Error", name) || strings.EqualFold("Errors", name)):
+			found, err := sniffErrorElement(WrapNodeDecoder(d.Decoder, t), ec, depth+1)
+			if found || err != nil {
+				return found, err
+			}
+			continue
+		}
+
+		if field == nil || len(*field) != 0 {
+			if err := d.Decoder.Skip(); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		v, err := WrapNodeDecoder(d.Decoder, t).Value()
+		if err != nil {
+			return false, err
+		}
+		*field = string(v)
+
+		if len(ec.Code) != 0 && len(ec.Message) != 0 && len(ec.RequestID) != 0 {
+			return true, nil
+		}
+	}
+}
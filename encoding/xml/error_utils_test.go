@@ -60,3 +60,79 @@ func TestGetResponseErrorCode(t *testing.T) {
 		})
 	}
 }
+
+func TestSniffErrorComponents(t *testing.T) {
+	cases := map[string]struct {
+		errorResponse string
+		expected      ErrorComponents
+		expectErr     string
+	}{
+		"wrapped": {
+			errorResponse: `<?xml version="1.0"?><ErrorResponse>
+    <Error>
+        <Type>Sender</Type>
+        <Code>InvalidGreeting</Code>
+        <Message>Hi</Message>
+        <AnotherSetting><Nested>setting</Nested></AnotherSetting>
+    </Error>
+    <RequestId>foo-id</RequestId>
+</ErrorResponse>`,
+			expected: ErrorComponents{Code: "InvalidGreeting", Message: "Hi", RequestID: "foo-id"},
+		},
+		"unwrapped error response": {
+			errorResponse: `<ErrorResponse>
+    <Type>Sender</Type>
+    <Code>InvalidGreeting</Code>
+    <Message>Hi</Message>
+    <RequestId>foo-id</RequestId>
+</ErrorResponse>`,
+			expected: ErrorComponents{Code: "InvalidGreeting", Message: "Hi", RequestID: "foo-id"},
+		},
+		"unwrapped error": {
+			errorResponse: `<Error><Code>NoSuchKey</Code><Message>The resource you requested does not exist</Message>` +
+				`<Resource>/mybucket/myfoto.jpg</Resource><RequestId>4442587FB7D0A2F9</RequestId></Error>`,
+			expected: ErrorComponents{
+				Code: "NoSuchKey", Message: "The resource you requested does not exist", RequestID: "4442587FB7D0A2F9",
+			},
+		},
+		"errors list": {
+			errorResponse: `<Response><Errors><Error><Code>InvalidInstanceID</Code><Message>bad</Message></Error>` +
+				`<Error><Code>Other</Code></Error></Errors><RequestID>foo-id</RequestID></Response>`,
+			expected: ErrorComponents{Code: "InvalidInstanceID", Message: "bad", RequestID: "foo-id"},
+		},
+		"missing fields": {
+			errorResponse: `<Error><Code>Throttling</Code></Error>`,
+			expected:      ErrorComponents{Code: "Throttling"},
+		},
+		"no response body": {},
+		"stops once found": {
+			errorResponse: `<Error><Code>A</Code><Message>B</Message><RequestId>C</RequestId><Malformed>`,
+			expected:      ErrorComponents{Code: "A", Message: "B", RequestID: "C"},
+		},
+		"malformed": {
+			errorResponse: `<Error><Code>A</Message></Error>`,
+			expectErr:     "error while deserializing xml error response",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ec, err := SniffErrorComponents(strings.NewReader(c.errorResponse))
+			if len(c.expectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				if e, a := c.expectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expected %v in error, got %v", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if e, a := c.expected, ec; e != a {
+				t.Errorf("expected %v, got %v", e, a)
+			}
+		})
+	}
+}
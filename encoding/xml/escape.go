@@ -39,99 +39,180 @@ func isInCharacterRange(r rune) (inrange bool) {
 		r >= 0x10000 && r <= 0x10FFFF
 }
 
+// escapePolicy is the escaping of characters in text and attribute values
+// that may be written either as is or as character references. The zero
+// value escapes whitespace other than space, and writes non-ASCII characters
+// as UTF-8.
+type escapePolicy struct {
+	// write newline, tab, and carriage return as is
+	rawWhitespace bool
+
+	// write non-ASCII characters as numeric character references
+	escapeNonASCII bool
+}
+
+// escapeRune returns the escaped form of r, with its encoded width, and
+// whether r is escaped. A rune that is escaped as a numeric character
+// reference has a nil escaped form, and is written with writeCharRef.
+func (p escapePolicy) escapeRune(r rune, width int) ([]byte, bool) {
+	switch r {
+	case '"':
+		return escQuot, true
+	case '\'':
+		return escApos, true
+	case '&':
+		return escAmp, true
+	case '<':
+		return escLT, true
+	case '>':
+		return escGT, true
+	case '\t':
+		if !p.rawWhitespace {
+			return escTab, true
+		}
+		return nil, false
+	case '\n':
+		// This always escapes newline by default, which is different than
+		// stdlib's optional escape of new line.
+		if !p.rawWhitespace {
+			return escNL, true
+		}
+		return nil, false
+	case '\r':
+		if !p.rawWhitespace {
+			return escCR, true
+		}
+		return nil, false
+	}
+
+	if !isInCharacterRange(r) || (r == 0xFFFD && width == 1) {
+		return escFFFD, true
+	}
+	if p.escapeNonASCII && r >= utf8.RuneSelf {
+		return nil, true
+	}
+	switch r {
+	case '\u0085':
+		// Not escaped by stdlib
+		if !p.rawWhitespace {
+			return escNextLine, true
+		}
+	case '\u2028':
+		// Not escaped by stdlib
+		if !p.rawWhitespace {
+			return escLS, true
+		}
+	}
+	return nil, false
+}
+
+// writeCharRef writes r as a hexadecimal numeric character reference, e.g.
+// "&#xe9;", without allocating.
+func writeCharRef(e writer, r rune) {
+	const hex = "0123456789abcdef"
+
+	e.WriteString("&#x")
+	shift := 0
+	for r>>shift >= 16 {
+		shift += 4
+	}
+	for ; shift >= 0; shift -= 4 {
+		e.WriteRune(rune(hex[r>>shift&0xF]))
+	}
+	e.WriteRune(';')
+}
+
 // TODO: When do we need to escape the string?
 // Based on encoding/xml escapeString from the Go Standard Library.
 // https://golang.org/src/encoding/xml/xml.go
-func escapeString(e writer, s string) {
-	var esc []byte
+func escapeString(e writer, s string, p escapePolicy) {
 	last := 0
 	for i := 0; i < len(s); {
 		r, width := utf8.DecodeRuneInString(s[i:])
 		i += width
-		switch r {
-		case '"':
-			esc = escQuot
-		case '\'':
-			esc = escApos
-		case '&':
-			esc = escAmp
-		case '<':
-			esc = escLT
-		case '>':
-			esc = escGT
-		case '\t':
-			esc = escTab
-		case '\n':
-			esc = escNL
-		case '\r':
-			esc = escCR
-		case '\u0085':
-			// Not escaped by stdlib
-			esc = escNextLine
-		case '\u2028':
-			// Not escaped by stdlib
-			esc = escLS
-		default:
-			if !isInCharacterRange(r) || (r == 0xFFFD && width == 1) {
-				esc = escFFFD
-				break
-			}
+		esc, ok := p.escapeRune(r, width)
+		if !ok {
 			continue
 		}
 		e.WriteString(s[last : i-width])
-		e.Write(esc)
+		if esc == nil {
+			writeCharRef(e, r)
+		} else {
+			e.Write(esc)
+		}
 		last = i
 	}
 	e.WriteString(s[last:])
 }
 
 // escapeText writes to w the properly escaped XML equivalent
-// of the plain text data s. If escapeNewline is true, newline
-// characters will be escaped.
+// of the plain text data s.
 //
 // Based on encoding/xml escapeText from the Go Standard Library.
 // https://golang.org/src/encoding/xml/xml.go
-func escapeText(e writer, s []byte) {
-	var esc []byte
+func escapeText(e writer, s []byte, p escapePolicy) {
 	last := 0
 	for i := 0; i < len(s); {
 		r, width := utf8.DecodeRune(s[i:])
 		i += width
-		switch r {
-		case '"':
-			esc = escQuot
-		case '\'':
-			esc = escApos
-		case '&':
-			esc = escAmp
-		case '<':
-			esc = escLT
-		case '>':
-			esc = escGT
-		case '\t':
-			esc = escTab
-		case '\n':
-			// This always escapes newline, which is different than stdlib's optional
-			// escape of new line.
-			esc = escNL
-		case '\r':
-			esc = escCR
-		case '\u0085':
-			// Not escaped by stdlib
-			esc = escNextLine
-		case '\u2028':
-			// Not escaped by stdlib
-			esc = escLS
-		default:
-			if !isInCharacterRange(r) || (r == 0xFFFD && width == 1) {
-				esc = escFFFD
-				break
-			}
+		esc, ok := p.escapeRune(r, width)
+		if !ok {
 			continue
 		}
 		e.Write(s[last : i-width])
-		e.Write(esc)
+		if esc == nil {
+			writeCharRef(e, r)
+		} else {
+			e.Write(esc)
+		}
 		last = i
 	}
 	e.Write(s[last:])
 }
+
+const (
+	cdataStart = "<![CDATA["
+	cdataEnd   = "]]>"
+)
+
+// writeCDATA writes s as one or more CDATA sections. A "]]>" in s is split
+// across two sections, since it would otherwise end the section. Carriage
+// returns are written as character references between sections, since they
+// would otherwise be normalized to newlines by XML parsers, and characters
+// outside the XML character range are replaced, as by escapeString.
+func writeCDATA(e writer, s string) {
+	if len(s) == 0 {
+		return
+	}
+
+	e.WriteString(cdataStart)
+	last := 0
+	for i := 0; i < len(s); {
+		r, width := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == ']' && len(s)-i >= len(cdataEnd) && s[i:i+len(cdataEnd)] == cdataEnd:
+			// end the section between "]]" and ">"
+			i += 2
+			e.WriteString(s[last:i])
+			e.WriteString(cdataEnd)
+			e.WriteString(cdataStart)
+			last = i
+			continue
+		case r == '\r':
+			e.WriteString(s[last:i])
+			e.WriteString(cdataEnd)
+			e.Write(escCR)
+			e.WriteString(cdataStart)
+		case !isInCharacterRange(r) || (r == 0xFFFD && width == 1):
+			e.WriteString(s[last:i])
+			e.Write(escFFFD)
+		default:
+			i += width
+			continue
+		}
+		i += width
+		last = i
+	}
+	e.WriteString(s[last:])
+	e.WriteString(cdataEnd)
+}
@@ -15,6 +15,9 @@ type Map struct {
 
 	// isFlattened returns true if the map is a flattened map
 	isFlattened bool
+
+	// namespace scope of the entries
+	ns *namespaceScope
 }
 
 // newMap returns a map encoder which sets the default map
@@ -47,7 +50,7 @@ func newFlattenedMap(w writer, scratch *[]byte, memberWrapper StartElement) *Map
 // Entry returns a Value encoder with map's element.
 // It writes the member wrapper start tag for each entry.
 func (m *Map) Entry() Value {
-	v := newValue(m.w, m.scratch, m.memberStartElement)
+	v := newElementValue(m.w, m.scratch, m.ns, m.memberStartElement)
 	v.isFlattened = m.isFlattened
 	return v
 }
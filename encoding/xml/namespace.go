@@ -0,0 +1,263 @@
+package xml
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// xmlNamespaceURI is the namespace bound to the reserved "xml" prefix, e.g.
+// of the xml:lang attribute.
+const xmlNamespaceURI = "http://www.w3.org/XML/1998/namespace"
+
+// Namespace is an XML namespace declared on an element, see
+// Encoder.RootElementNS and Value.MemberElementNS.
+type Namespace struct {
+	// The prefix bound to the namespace. If empty, and the namespace is not
+	// the default namespace, a prefix is generated, e.g. "ns1", unless a
+	// prefix is already bound to the namespace in scope.
+	Prefix string
+
+	// The namespace URI.
+	URI string
+
+	// Declares the namespace as the default namespace, xmlns="URI", of the
+	// element and its descendants. Prefix is ignored.
+	IsDefault bool
+}
+
+// encoderState is the state shared by the namespace scopes, and so the
+// Values, of an encoder.
+type encoderState struct {
+	// escaping of text and attribute values
+	escape escapePolicy
+
+	// encoding of nil strings
+	nilStrings NilStringMode
+
+	// last generated prefix number
+	generated int
+
+	// first error resolving a namespace
+	err error
+
+	// buffers of the attributes of resolved elements, which are reused
+	// since an element is written once resolved
+	attrs, resolvedAttrs []Attr
+}
+
+func (s *encoderState) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// namespaceScope is the namespaces in scope of an element, which are those
+// declared by the element and its ancestors.
+//
+// The base scope of an encoder, which has no parent, binds only the "xml"
+// prefix. Elements of a Value in the base scope are written as is, with
+// namespace prefixes as their name space, whereas elements of a Value in a
+// child of the base scope have their namespace URIs resolved to prefixes.
+type namespaceScope struct {
+	parent *namespaceScope
+	state  *encoderState
+
+	// namespaces declared by the element, in order. The default namespace
+	// has an empty prefix.
+	bindings []Namespace
+}
+
+func newBaseNamespaceScope() *namespaceScope {
+	return &namespaceScope{
+		state:    &encoderState{},
+		bindings: []Namespace{{Prefix: "xml", URI: xmlNamespaceURI}},
+	}
+}
+
+// reset discards the namespaces declared in the base scope's descendants,
+// and the state of their resolution.
+func (s *namespaceScope) reset() {
+	s.state.generated = 0
+	s.state.err = nil
+}
+
+// escapePolicy returns the escaping of text and attribute values of the
+// scope's elements.
+func (s *namespaceScope) escapePolicy() escapePolicy {
+	if s == nil {
+		return escapePolicy{}
+	}
+	return s.state.escape
+}
+
+// nilStrings returns the encoding of nil strings of the scope's elements.
+func (s *namespaceScope) nilStrings() NilStringMode {
+	if s == nil {
+		return NilStringOmit
+	}
+	return s.state.nilStrings
+}
+
+// isManaged returns whether namespaces of the scope's elements are resolved.
+func (s *namespaceScope) isManaged() bool {
+	return s != nil && s.parent != nil
+}
+
+// lookupURI returns the namespace URI bound to prefix in scope. The empty
+// prefix is the default namespace.
+func (s *namespaceScope) lookupURI(prefix string) (string, bool) {
+	for ; s != nil; s = s.parent {
+		for _, b := range s.bindings {
+			if b.Prefix == prefix {
+				return b.URI, true
+			}
+		}
+	}
+	return "", false
+}
+
+// lookupPrefix returns a non-empty prefix bound to uri in scope, which is not
+// shadowed by a descendant's declaration of the same prefix.
+func (s *namespaceScope) lookupPrefix(uri string) (string, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		for _, b := range scope.bindings {
+			if len(b.Prefix) == 0 || b.URI != uri {
+				continue
+			}
+			if bound, _ := s.lookupURI(b.Prefix); bound == uri {
+				return b.Prefix, true
+			}
+		}
+	}
+	return "", false
+}
+
+// resolve returns the scope of the element with its namespaces declared, and
+// the element as written, with namespace URIs resolved to prefixes and
+// declarations of namespaces not already in scope.
+//
+// Namespace attributes of the element, e.g. from NewNamespaceAttribute, are
+// declared after namespaces. An element or attribute namespace that is not in
+// scope is recorded as an error of the encoder, and written without prefix.
+func (s *namespaceScope) resolve(element StartElement, namespaces []Namespace) (*namespaceScope, StartElement) {
+	if s == nil {
+		s = newBaseNamespaceScope()
+	}
+
+	attrs := s.state.attrs[:0]
+	for _, a := range element.Attr {
+		switch {
+		case a.Name.Space == "xmlns":
+			namespaces = append(namespaces, Namespace{
+				Prefix: a.Name.Local, URI: a.Value, IsDefault: len(a.Name.Local) == 0,
+			})
+		case len(a.Name.Space) == 0 && a.Name.Local == "xmlns":
+			namespaces = append(namespaces, Namespace{URI: a.Value, IsDefault: true})
+		default:
+			attrs = append(attrs, a)
+		}
+	}
+
+	scope := &namespaceScope{parent: s, state: s.state}
+	for _, ns := range namespaces {
+		if err := scope.declare(ns); err != nil {
+			s.state.setErr(fmt.Errorf("element %s, %w", element.Name.Local, err))
+		}
+	}
+	if len(scope.bindings) == 0 && s.isManaged() {
+		scope = s
+	}
+
+	resolved := StartElement{
+		Name: Name{Local: element.Name.Local},
+		Attr: s.state.resolvedAttrs[:0],
+	}
+	if uri := element.Name.Space; len(uri) != 0 {
+		if def, _ := scope.lookupURI(""); def != uri {
+			if p, ok := scope.lookupPrefix(uri); ok {
+				resolved.Name.Space = p
+			} else {
+				s.state.setErr(fmt.Errorf("element %s namespace %q is not declared", element.Name.Local, uri))
+			}
+		}
+	}
+
+	if scope != s {
+		for _, b := range scope.bindings {
+			if len(b.Prefix) == 0 {
+				resolved.Attr = append(resolved.Attr, Attr{Name: Name{Local: "xmlns"}, Value: b.URI})
+			} else {
+				resolved.Attr = append(resolved.Attr, NewNamespaceAttribute(b.Prefix, b.URI))
+			}
+		}
+	}
+	for _, a := range attrs {
+		if uri := a.Name.Space; len(uri) != 0 {
+			// the default namespace does not apply to attributes
+			a.Name.Space = ""
+			if p, ok := scope.lookupPrefix(uri); ok {
+				a.Name.Space = p
+			} else {
+				s.state.setErr(fmt.Errorf("element %s attribute %s namespace %q is not declared",
+					element.Name.Local, a.Name.Local, uri))
+			}
+		}
+		resolved.Attr = append(resolved.Attr, a)
+	}
+	s.state.attrs, s.state.resolvedAttrs = attrs[:0], resolved.Attr[:0]
+
+	return scope, resolved
+}
+
+// declare binds the namespace in the scope, unless it is already in scope.
+func (s *namespaceScope) declare(ns Namespace) error {
+	if ns.IsDefault {
+		if uri, ok := s.lookupURI(""); ok && uri == ns.URI || !ok && len(ns.URI) == 0 {
+			return nil
+		}
+		return s.bind(Namespace{URI: ns.URI})
+	}
+
+	if len(ns.URI) == 0 {
+		return fmt.Errorf("namespace prefix %q must have a URI", ns.Prefix)
+	}
+
+	if len(ns.Prefix) == 0 {
+		if _, ok := s.lookupPrefix(ns.URI); ok {
+			return nil
+		}
+		for {
+			s.state.generated++
+			prefix := "ns" + strconv.Itoa(s.state.generated)
+			if _, ok := s.lookupURI(prefix); !ok {
+				return s.bind(Namespace{Prefix: prefix, URI: ns.URI})
+			}
+		}
+	}
+
+	switch {
+	case ns.Prefix == "xmlns":
+		return fmt.Errorf("namespace prefix xmlns is reserved")
+	case ns.Prefix == "xml" && ns.URI != xmlNamespaceURI:
+		return fmt.Errorf("namespace prefix xml is reserved for %s", xmlNamespaceURI)
+	}
+	if uri, _ := s.lookupURI(ns.Prefix); uri == ns.URI {
+		return nil
+	}
+	return s.bind(Namespace{Prefix: ns.Prefix, URI: ns.URI})
+}
+
+// bind adds the binding to the scope. Returns an error if the prefix is
+// already bound by the scope's element.
+func (s *namespaceScope) bind(ns Namespace) error {
+	for _, b := range s.bindings {
+		if b.Prefix == ns.Prefix {
+			if len(ns.Prefix) == 0 {
+				return fmt.Errorf("default namespace declared more than once")
+			}
+			return fmt.Errorf("namespace prefix %q declared more than once", ns.Prefix)
+		}
+	}
+	s.bindings = append(s.bindings, ns)
+	return nil
+}
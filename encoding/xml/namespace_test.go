@@ -0,0 +1,197 @@
+package xml_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/encoding/xml"
+)
+
+const (
+	testNamespaceA = "https://example.com/a"
+	testNamespaceB = "https://example.com/b"
+)
+
+func TestEncodeNamespaces(t *testing.T) {
+	cases := map[string]struct {
+		encode func(*xml.Encoder)
+		expect string
+		err    string
+	}{
+		"default namespace": {
+			encode: func(e *xml.Encoder) {
+				r := e.RootElementNS(root, xml.Namespace{URI: testNamespaceA, IsDefault: true})
+				defer r.Close()
+				r.MemberElement(xml.StartElement{Name: xml.Name{Local: "a"}}).String("1")
+				r.MemberElement(xml.StartElement{Name: xml.Name{Space: testNamespaceA, Local: "b"}}).String("2")
+			},
+			expect: `<root xmlns="https://example.com/a"><a>1</a><b>2</b></root>`,
+		},
+		"explicit prefix": {
+			encode: func(e *xml.Encoder) {
+				r := e.RootElementNS(xml.StartElement{Name: xml.Name{Space: testNamespaceA, Local: "root"}},
+					xml.Namespace{Prefix: "a", URI: testNamespaceA})
+				defer r.Close()
+				r.MemberElement(xml.StartElement{Name: xml.Name{Space: testNamespaceA, Local: "b"}}).String("1")
+			},
+			expect: `<a:root xmlns:a="https://example.com/a"><a:b>1</a:b></a:root>`,
+		},
+		"generated prefixes": {
+			encode: func(e *xml.Encoder) {
+				r := e.RootElementNS(root, xml.Namespace{URI: testNamespaceA}, xml.Namespace{URI: testNamespaceB})
+				defer r.Close()
+				r.MemberElement(xml.StartElement{Name: xml.Name{Space: testNamespaceB, Local: "b"}}).String("1")
+				r.MemberElement(xml.StartElement{Name: xml.Name{Space: testNamespaceA, Local: "a"}}).String("2")
+			},
+			expect: `<root xmlns:ns1="https://example.com/a" xmlns:ns2="https://example.com/b"><ns2:b>1</ns2:b><ns1:a>2</ns1:a></root>`,
+		},
+		"generated prefix skips bound prefix": {
+			encode: func(e *xml.Encoder) {
+				r := e.RootElementNS(root, xml.Namespace{Prefix: "ns1", URI: testNamespaceA}, xml.Namespace{URI: testNamespaceB})
+				r.Close()
+			},
+			expect: `<root xmlns:ns1="https://example.com/a" xmlns:ns2="https://example.com/b"></root>`,
+		},
+		"nested scopes": {
+			encode: func(e *xml.Encoder) {
+				r := e.RootElementNS(root, xml.Namespace{Prefix: "a", URI: testNamespaceA})
+				defer r.Close()
+
+				// redeclared namespace is not repeated
+				n := r.MemberElementNS(xml.StartElement{Name: xml.Name{Local: "n"}},
+					xml.Namespace{Prefix: "a", URI: testNamespaceA}, xml.Namespace{URI: testNamespaceA})
+				n.MemberElement(xml.StartElement{Name: xml.Name{Space: testNamespaceA, Local: "v"}}).String("1")
+				n.Close()
+
+				// shadowed prefix
+				s := r.MemberElementNS(xml.StartElement{Name: xml.Name{Local: "s"}},
+					xml.Namespace{Prefix: "a", URI: testNamespaceB})
+				s.MemberElement(xml.StartElement{Name: xml.Name{Space: testNamespaceB, Local: "v"}}).String("2")
+				s.Close()
+
+				// declaration is scoped to its element
+				r.MemberElement(xml.StartElement{Name: xml.Name{Space: testNamespaceA, Local: "v"}}).String("3")
+			},
+			expect: `<root xmlns:a="https://example.com/a"><n><a:v>1</a:v></n>` +
+				`<s xmlns:a="https://example.com/b"><a:v>2</a:v></s><a:v>3</a:v></root>`,
+		},
+		"collections": {
+			encode: func(e *xml.Encoder) {
+				r := e.RootElementNS(root, xml.Namespace{Prefix: "a", URI: testNamespaceA})
+				defer r.Close()
+
+				list := r.FlattenedElement(xml.StartElement{Name: xml.Name{Space: testNamespaceA, Local: "l"}})
+				list.Array().Member().String("1")
+
+				m := r.MemberElement(xml.StartElement{Name: xml.Name{Local: "m"}})
+				entry := m.Map().Entry()
+				entry.MemberElement(xml.StartElement{Name: xml.Name{Space: testNamespaceA, Local: "key"}}).String("k")
+				entry.Close()
+				m.Close()
+			},
+			expect: `<root xmlns:a="https://example.com/a"><a:l>1</a:l><m><entry><a:key>k</a:key></entry></m></root>`,
+		},
+		"attributes": {
+			encode: func(e *xml.Encoder) {
+				r := e.RootElementNS(xml.StartElement{
+					Name: xml.Name{Local: "root"},
+					Attr: []xml.Attr{
+						{Name: xml.Name{Space: testNamespaceA, Local: "type"}, Value: "t"},
+						{Name: xml.Name{Space: "http://www.w3.org/XML/1998/namespace", Local: "lang"}, Value: "en"},
+						xml.NewAttribute("plain", "p"),
+					},
+				}, xml.Namespace{URI: testNamespaceA, IsDefault: true}, xml.Namespace{Prefix: "a", URI: testNamespaceA})
+				r.Close()
+			},
+			expect: `<root xmlns="https://example.com/a" xmlns:a="https://example.com/a" a:type="t" xml:lang="en" plain="p"></root>`,
+		},
+		"namespace attributes": {
+			encode: func(e *xml.Encoder) {
+				r := e.RootElementNS(xml.StartElement{
+					Name: xml.Name{Local: "root"},
+					Attr: []xml.Attr{
+						xml.NewNamespaceAttribute("a", testNamespaceA),
+						xml.NewNamespaceAttribute("", testNamespaceB),
+					},
+				})
+				defer r.Close()
+				r.MemberElement(xml.StartElement{
+					Name: xml.Name{Local: "v"},
+					Attr: []xml.Attr{xml.NewNamespaceAttribute("a", testNamespaceA)},
+				}).String("1")
+			},
+			expect: `<root xmlns:a="https://example.com/a" xmlns="https://example.com/b"><v>1</v></root>`,
+		},
+		"undeclared element namespace": {
+			encode: func(e *xml.Encoder) {
+				r := e.RootElementNS(root)
+				defer r.Close()
+				r.MemberElement(xml.StartElement{Name: xml.Name{Space: testNamespaceA, Local: "v"}}).String("1")
+			},
+			expect: `<root><v>1</v></root>`,
+			err:    `element v namespace "https://example.com/a" is not declared`,
+		},
+		"undeclared attribute namespace": {
+			encode: func(e *xml.Encoder) {
+				r := e.RootElementNS(xml.StartElement{
+					Name: xml.Name{Local: "root"},
+					Attr: []xml.Attr{{Name: xml.Name{Space: testNamespaceA, Local: "type"}, Value: "t"}},
+				}, xml.Namespace{URI: testNamespaceA, IsDefault: true})
+				r.Close()
+			},
+			expect: `<root xmlns="https://example.com/a" type="t"></root>`,
+			err:    `element root attribute type namespace "https://example.com/a" is not declared`,
+		},
+		"reserved prefix": {
+			encode: func(e *xml.Encoder) {
+				e.RootElementNS(root, xml.Namespace{Prefix: "xmlns", URI: testNamespaceA}).Close()
+			},
+			expect: `<root></root>`,
+			err:    "namespace prefix xmlns is reserved",
+		},
+		"duplicate prefix": {
+			encode: func(e *xml.Encoder) {
+				e.RootElementNS(root,
+					xml.Namespace{Prefix: "a", URI: testNamespaceA},
+					xml.Namespace{Prefix: "a", URI: testNamespaceB},
+				).Close()
+			},
+			expect: `<root xmlns:a="https://example.com/a"></root>`,
+			err:    `namespace prefix "a" declared more than once`,
+		},
+		"member element of unmanaged value": {
+			encode: func(e *xml.Encoder) {
+				r := e.RootElement(root)
+				defer r.Close()
+				r.MemberElement(xml.StartElement{Name: xml.Name{Space: "p", Local: "v"}}).String("1")
+
+				n := r.MemberElementNS(xml.StartElement{Name: xml.Name{Local: "n"}}, xml.Namespace{URI: testNamespaceA})
+				defer n.Close()
+				n.MemberElement(xml.StartElement{Name: xml.Name{Space: testNamespaceA, Local: "v"}}).String("2")
+			},
+			expect: `<root><p:v>1</p:v><n xmlns:ns1="https://example.com/a"><ns1:v>2</ns1:v></n></root>`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder := xml.NewEncoder(bytes.NewBuffer(nil))
+			c.encode(encoder)
+
+			if e, a := c.expect, encoder.String(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+			err := encoder.Err()
+			if len(c.err) == 0 {
+				if err != nil {
+					t.Errorf("expect no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("expect error %q, got %v", c.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package xml
+
+const (
+	// xsiNamespaceURI is the XML Schema instance namespace, of the xsi:nil
+	// attribute.
+	xsiNamespaceURI = "http://www.w3.org/2001/XMLSchema-instance"
+
+	// xsiPrefix is the conventional prefix of the XML Schema instance
+	// namespace.
+	xsiPrefix = "xsi"
+)
+
+// NilStringMode is how a nil string is encoded, see
+// EncoderOptions.NilStrings.
+type NilStringMode int
+
+// Enumeration values for NilStringMode
+const (
+	// A nil string is omitted, such that no element is written.
+	NilStringOmit NilStringMode = iota
+
+	// A nil string is written as an empty element, e.g. `<name/>`, which
+	// decodes as an empty string.
+	NilStringEmptyElement
+
+	// A nil string is written as an empty element with xsi:nil="true", which
+	// decodes as nil.
+	NilStringNilElement
+)
+
+// IsNil returns whether the node decoder's start element has the
+// xsi:nil="true" attribute, which identifies a nil value. Value returns a nil
+// slice for such an element.
+func (d NodeDecoder) IsNil() bool {
+	if len(d.StartEl.Attr) == 0 {
+		return false
+	}
+	return hasNilAttr(d.Attributes())
+}
+
+// IsNil returns whether the node has the xsi:nil="true" attribute, which
+// identifies a nil value.
+func (n *Node) IsNil() bool {
+	return hasNilAttr(n.Attributes())
+}
+
+// hasNilAttr returns whether the attributes include xsi:nil="true". The
+// attribute's namespace is the XML Schema instance namespace, or a prefix
+// restored for it, either the conventional prefix or one declared by the
+// attributes.
+func hasNilAttr(attrs Attributes) bool {
+	v, ok := attrs.GetNS(xsiNamespaceURI, "nil")
+	if !ok {
+		v, ok = attrs.Get(xsiPrefix + ":nil")
+	}
+	return ok && (v == "true" || v == "1")
+}
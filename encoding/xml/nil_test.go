@@ -0,0 +1,159 @@
+package xml
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestValue_NilElements(t *testing.T) {
+	abc := "abc"
+	member := StartElement{Name: Name{Local: "member"}}
+
+	cases := map[string]struct {
+		optFns []func(*EncoderOptions)
+		encode func(Value)
+		expect string
+	}{
+		"empty element": {
+			encode: func(v Value) {
+				v.EmptyElement(StartElement{Name: Name{Local: "member"}, Attr: []Attr{NewAttribute("a", "1")}})
+			},
+			expect: `<root><member a="1"/></root>`,
+		},
+		"nil element": {
+			encode: func(v Value) { v.NilElement(member) },
+			expect: `<root><member xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/></root>`,
+		},
+		"nilable string": {
+			encode: func(v Value) { v.NilableStringElement(member, &abc) },
+			expect: `<root><member>abc</member></root>`,
+		},
+		"nil string omitted": {
+			encode: func(v Value) { v.NilableStringElement(member, nil) },
+			expect: `<root></root>`,
+		},
+		"nil string empty element": {
+			optFns: []func(*EncoderOptions){func(o *EncoderOptions) {
+				o.NilStrings = NilStringEmptyElement
+			}},
+			encode: func(v Value) { v.NilableStringElement(member, nil) },
+			expect: `<root><member/></root>`,
+		},
+		"nil string nil element": {
+			optFns: []func(*EncoderOptions){func(o *EncoderOptions) {
+				o.NilStrings = NilStringNilElement
+			}},
+			encode: func(v Value) { v.NilableStringElement(member, nil) },
+			expect: `<root><member xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/></root>`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder := NewEncoder(bytes.NewBuffer(nil), c.optFns...)
+			root := encoder.RootElement(StartElement{Name: Name{Local: "root"}})
+			c.encode(root)
+			root.Close()
+
+			if e, a := c.expect, encoder.String(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestValue_NilElementNamespaced(t *testing.T) {
+	encoder := NewEncoder(bytes.NewBuffer(nil))
+	root := encoder.RootElementNS(StartElement{Name: Name{Local: "root"}},
+		Namespace{Prefix: "xsi", URI: xsiNamespaceURI})
+	root.NilElement(StartElement{Name: Name{Local: "a"}})
+	root.NilElement(StartElement{Name: Name{Local: "b"}})
+	root.Close()
+
+	expect := `<root xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><a xsi:nil="true"/><b xsi:nil="true"/></root>`
+	if e, a := expect, encoder.String(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if err := encoder.Err(); err != nil {
+		t.Errorf("expect no error, got %v", err)
+	}
+}
+
+func TestNodeDecoder_NilValue(t *testing.T) {
+	cases := map[string]struct {
+		input  string
+		isNil  bool
+		expect []byte
+	}{
+		"value": {
+			input:  `<member>abc</member>`,
+			expect: []byte("abc"),
+		},
+		"empty": {
+			input:  `<member/>`,
+			expect: []byte{},
+		},
+		"xsi nil": {
+			input: `<member xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/>`,
+			isNil: true,
+		},
+		"custom prefix": {
+			input: `<member xmlns:i="http://www.w3.org/2001/XMLSchema-instance" i:nil="1"></member>`,
+			isNil: true,
+		},
+		"xsi nil false": {
+			input:  `<member xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="false">abc</member>`,
+			expect: []byte("abc"),
+		},
+		"other namespace": {
+			input:  `<member xmlns:x="https://example.com" x:nil="true">abc</member>`,
+			expect: []byte("abc"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := newTestNodeDecoder(t, `<root>`+c.input+`<next>1</next></root>`)
+			member, err := d.GetElement("member")
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			md := WrapNodeDecoder(d.Decoder, member)
+			if e, a := c.isNil, md.IsNil(); e != a {
+				t.Errorf("expect nil %v, got %v", e, a)
+			}
+
+			v, err := md.Value()
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if c.isNil {
+				if v != nil {
+					t.Errorf("expect nil value, got %q", v)
+				}
+			} else if v == nil || !bytes.Equal(c.expect, v) {
+				t.Errorf("expect %q, got %q", c.expect, v)
+			}
+
+			// decoder is positioned after the member
+			if _, err := d.GetElement("next"); err != nil {
+				t.Errorf("expect next element, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNode_IsNil(t *testing.T) {
+	n, err := DecodeNode(bytes.NewReader([]byte(
+		`<root xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"><a xsi:nil="true"/><b/></root>`,
+	)))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if !n.Child("a").IsNil() {
+		t.Errorf("expect a to be nil")
+	}
+	if n.Child("b").IsNil() {
+		t.Errorf("expect b not to be nil")
+	}
+}
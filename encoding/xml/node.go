@@ -0,0 +1,163 @@
+package xml
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// Node is an element of a decoded XML document, as returned by DecodeNode.
+//
+// Element names have their namespace prefix resolved, such that Name.Space is
+// the namespace URI, whereas attribute names keep the prefix used in the
+// document, e.g. "xsi" for xsi:type, since attributes are matched by prefix
+// by restXml deserializers.
+type Node struct {
+	Name     Name
+	Attr     []Attr
+	Children []*Node
+
+	// The character data directly within the element, excluding that of its
+	// children. Character data of an element with children that is only
+	// whitespace, e.g. indentation, is not included.
+	Text string
+}
+
+// Child returns the first child element with the local name, or nil if there
+// is none.
+func (n *Node) Child(local string) *Node {
+	for _, c := range n.Children {
+		if c.Name.Local == local {
+			return c
+		}
+	}
+	return nil
+}
+
+// ChildrenNamed returns the child elements with the local name in document
+// order, e.g. the members of a flattened list.
+func (n *Node) ChildrenNamed(local string) []*Node {
+	var cs []*Node
+	for _, c := range n.Children {
+		if c.Name.Local == local {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// Attribute returns the value of the attribute with the local name, ignoring
+// namespace prefix, and whether it was present.
+func (n *Node) Attribute(local string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Name.Local == local && a.Name.Space != "xmlns" {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// DecodeNode decodes the root element of the XML document read from r, and
+// its descendants, as a tree of Nodes. The XML declaration, comments, and
+// processing instructions are skipped.
+func DecodeNode(r io.Reader) (*Node, error) {
+	d := xml.NewDecoder(r)
+	root, err := FetchRootElement(d)
+	if err != nil {
+		return nil, err
+	}
+	return decodeNode(d, root)
+}
+
+// Node decodes the start element of the NodeDecoder and its descendants as a
+// tree of Nodes. The underlying decoder must be positioned immediately after
+// the start element, and is positioned after its end element on return.
+func (d NodeDecoder) Node() (*Node, error) {
+	return decodeNode(d.Decoder, d.StartEl)
+}
+
+// nodeBuilder is an element whose end element has not been decoded.
+type nodeBuilder struct {
+	node *Node
+	text strings.Builder
+
+	// whether the element's character data includes any non-whitespace
+	hasText bool
+
+	// namespace URI to document prefix, of the element and its ancestors
+	prefixes map[string]string
+}
+
+func decodeNode(d *xml.Decoder, start xml.StartElement) (*Node, error) {
+	stack := []*nodeBuilder{newNodeBuilder(start, nil)}
+
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		top := stack[len(stack)-1]
+		switch tt := t.(type) {
+		case xml.StartElement:
+			child := newNodeBuilder(tt, top.prefixes)
+			top.node.Children = append(top.node.Children, child.node)
+			stack = append(stack, child)
+
+		case xml.CharData:
+			top.text.Write(tt)
+			if len(strings.TrimSpace(string(tt))) != 0 {
+				top.hasText = true
+			}
+
+		case xml.EndElement:
+			// the decoder verifies that end elements match their start
+			if top.hasText || len(top.node.Children) == 0 {
+				top.node.Text = top.text.String()
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return top.node, nil
+			}
+		}
+		// comments, processing instructions, and directives are skipped
+	}
+}
+
+func newNodeBuilder(start xml.StartElement, parentPrefixes map[string]string) *nodeBuilder {
+	prefixes := parentPrefixes
+	copied := false
+	for _, a := range start.Attr {
+		if a.Name.Space != "xmlns" {
+			continue
+		}
+		if !copied {
+			// copy on write, so that declarations are scoped to the element
+			prefixes = make(map[string]string, len(parentPrefixes)+1)
+			for k, v := range parentPrefixes {
+				prefixes[k] = v
+			}
+			copied = true
+		}
+		prefixes[a.Value] = a.Name.Local
+	}
+
+	n := &Node{
+		Name: Name{Space: start.Name.Space, Local: start.Name.Local},
+	}
+	if len(start.Attr) != 0 {
+		n.Attr = make([]Attr, len(start.Attr))
+		for i, a := range start.Attr {
+			space := a.Name.Space
+			if p, ok := prefixes[space]; ok && space != "xmlns" {
+				space = p
+			}
+			n.Attr[i] = Attr{Name: Name{Space: space, Local: a.Name.Local}, Value: a.Value}
+		}
+	}
+
+	return &nodeBuilder{node: n, prefixes: prefixes}
+}
@@ -0,0 +1,172 @@
+package xml
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeNode(t *testing.T) {
+	cases := map[string]struct {
+		input    string
+		expected *Node
+		err      string
+	}{
+		"scalar": {
+			input:    `<?xml version="1.0" encoding="UTF-8"?><!-- c --><Value>abc</Value>`,
+			expected: &Node{Name: Name{Local: "Value"}, Text: "abc"},
+		},
+		"empty": {
+			input:    `<Value/>`,
+			expected: &Node{Name: Name{Local: "Value"}},
+		},
+		"significant whitespace": {
+			input:    `<Value>  a b  </Value>`,
+			expected: &Node{Name: Name{Local: "Value"}, Text: "  a b  "},
+		},
+		"whitespace only value": {
+			input:    `<Value>  </Value>`,
+			expected: &Node{Name: Name{Local: "Value"}, Text: "  "},
+		},
+		"escapes and cdata": {
+			input:    `<Value>&lt;a&amp;b&gt;<![CDATA[<c>]]></Value>`,
+			expected: &Node{Name: Name{Local: "Value"}, Text: "<a&b><c>"},
+		},
+		"nested with indentation": {
+			input: `<Response>
+				<Items>
+					<member>a</member>
+					<member>b</member>
+				</Items>
+				<!-- comment -->
+				<Count>2</Count>
+			</Response>`,
+			expected: &Node{
+				Name: Name{Local: "Response"},
+				Children: []*Node{
+					{
+						Name: Name{Local: "Items"},
+						Children: []*Node{
+							{Name: Name{Local: "member"}, Text: "a"},
+							{Name: Name{Local: "member"}, Text: "b"},
+						},
+					},
+					{Name: Name{Local: "Count"}, Text: "2"},
+				},
+			},
+		},
+		"namespaces": {
+			input: `<Response xmlns="https://example.com/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">` +
+				`<Grantee xsi:type="CanonicalUser" id="1"/></Response>`,
+			expected: &Node{
+				Name: Name{Space: "https://example.com/", Local: "Response"},
+				Attr: []Attr{
+					{Name: Name{Local: "xmlns"}, Value: "https://example.com/"},
+					{Name: Name{Space: "xmlns", Local: "xsi"}, Value: "http://www.w3.org/2001/XMLSchema-instance"},
+				},
+				Children: []*Node{
+					{
+						Name: Name{Space: "https://example.com/", Local: "Grantee"},
+						Attr: []Attr{
+							{Name: Name{Space: "xsi", Local: "type"}, Value: "CanonicalUser"},
+							{Name: Name{Local: "id"}, Value: "1"},
+						},
+					},
+				},
+			},
+		},
+		"mismatched end element": {
+			input: `<Response><Value>a</Response>`,
+			err:   "element <Value> closed by </Response>",
+		},
+		"unterminated": {
+			input: `<Response><Value>a</Value>`,
+			err:   "unexpected EOF",
+		},
+		"empty document": {
+			input: ``,
+			err:   "EOF",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := DecodeNode(strings.NewReader(c.input))
+			if len(c.err) != 0 {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expect error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("expect %+v, got %+v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNode_Accessors(t *testing.T) {
+	n, err := DecodeNode(strings.NewReader(`<Response xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="T">` +
+		`<Entry>a</Entry><Other/><Entry>b</Entry></Response>`))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if c := n.Child("Entry"); c == nil || c.Text != "a" {
+		t.Errorf("expect first Entry child, got %+v", c)
+	}
+	if c := n.Child("Missing"); c != nil {
+		t.Errorf("expect no child, got %+v", c)
+	}
+
+	var texts []string
+	for _, c := range n.ChildrenNamed("Entry") {
+		texts = append(texts, c.Text)
+	}
+	if e, a := []string{"a", "b"}, texts; !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	if v, ok := n.Attribute("type"); !ok || v != "T" {
+		t.Errorf("expect type attribute T, got %q, %v", v, ok)
+	}
+	if _, ok := n.Attribute("xsi"); ok {
+		t.Errorf("expect namespace declaration not to be an attribute")
+	}
+}
+
+func TestNodeDecoder_Node(t *testing.T) {
+	d := xml.NewDecoder(strings.NewReader(`<Response><Skip>x</Skip><Struct><A>1</A></Struct><After/></Response>`))
+	root, err := FetchRootElement(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nd := WrapNodeDecoder(d, root)
+	start, err := nd.GetElement("Struct")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := WrapNodeDecoder(d, start).Node()
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	expect := &Node{
+		Name:     Name{Local: "Struct"},
+		Children: []*Node{{Name: Name{Local: "A"}, Text: "1"}},
+	}
+	if !reflect.DeepEqual(expect, actual) {
+		t.Errorf("expect %+v, got %+v", expect, actual)
+	}
+
+	// positioned after the end of the decoded element
+	next, done, err := nd.Token()
+	if err != nil || done || next.Name.Local != "After" {
+		t.Errorf("expect After element, got %v, %v, %v", next, done, err)
+	}
+}
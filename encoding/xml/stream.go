@@ -0,0 +1,76 @@
+package xml
+
+import (
+	"bytes"
+	"io"
+)
+
+// defaultStreamFlushSize is the default StreamEncoderOptions.FlushSize.
+const defaultStreamFlushSize = 32 * 1024
+
+// StreamEncoderOptions is the options for an Encoder returned by
+// NewStreamEncoder.
+type StreamEncoderOptions struct {
+	// The number of buffered bytes at which the encoder writes the buffer to
+	// the underlying io.Writer when an element is closed. Defaults to 32 KiB
+	// if zero or negative.
+	FlushSize int
+}
+
+// NewStreamEncoder returns an XML encoder that writes the encoded document to
+// w as elements are closed, rather than building the entire document in
+// memory. The encoder buffers at most about StreamEncoderOptions.FlushSize
+// bytes, plus the size of the largest value written.
+//
+// Flush must be called once the root element is closed to write the end of
+// the document. The first error writing to w is returned by Flush and Err,
+// after which nothing more is written.
+//
+// String and Bytes of a stream encoder return only the buffered content that
+// has not been written to w.
+func NewStreamEncoder(w io.Writer, optFns ...func(*StreamEncoderOptions)) *Encoder {
+	options := StreamEncoderOptions{}
+	for _, fn := range optFns {
+		fn(&options)
+	}
+	if options.FlushSize <= 0 {
+		options.FlushSize = defaultStreamFlushSize
+	}
+
+	return NewEncoder(&streamWriter{w: w, flushSize: options.FlushSize})
+}
+
+// streamWriter is a writer that buffers the encoded document, and writes the
+// buffer to the underlying io.Writer once it reaches flushSize and an element
+// is closed.
+type streamWriter struct {
+	bytes.Buffer
+
+	w         io.Writer
+	flushSize int
+
+	// first error writing to w
+	err error
+}
+
+// flushIfFull writes the buffer to w if it reached flushSize. It is called by
+// a Value once its end element, or a chunk of content read from an
+// io.Reader, is written.
+func (s *streamWriter) flushIfFull() {
+	if s.Len() >= s.flushSize {
+		s.flush()
+	}
+}
+
+// flush writes the buffer to w, unless a previous write failed.
+func (s *streamWriter) flush() error {
+	if s.err != nil {
+		s.Reset()
+		return s.err
+	}
+	if _, err := s.WriteTo(s.w); err != nil {
+		s.err = err
+		s.Reset()
+	}
+	return s.err
+}
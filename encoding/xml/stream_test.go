@@ -0,0 +1,143 @@
+package xml_test
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/encoding/xml"
+)
+
+// recordingWriter records the writes made to it.
+type recordingWriter struct {
+	bytes.Buffer
+	writes int
+	err    error
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func encodeStreamTestDocument(encoder *xml.Encoder, members int) {
+	r := encoder.RootElement(root)
+	defer r.Close()
+
+	list := r.MemberElement(xml.StartElement{Name: xml.Name{Local: "list"}})
+	defer list.Close()
+
+	a := list.Array()
+	for i := 0; i < members; i++ {
+		a.Member().String(strconv.Itoa(i))
+	}
+}
+
+func TestStreamEncoder(t *testing.T) {
+	var buffered bytes.Buffer
+	encodeStreamTestDocument(xml.NewEncoder(&buffered), 100)
+
+	var w recordingWriter
+	encoder := xml.NewStreamEncoder(&w, func(o *xml.StreamEncoderOptions) {
+		o.FlushSize = 64
+	})
+	encodeStreamTestDocument(encoder, 100)
+
+	if w.writes < 2 {
+		t.Errorf("expect document written as elements are closed, got %d writes", w.writes)
+	}
+	if n := len(encoder.Bytes()); n >= 64 {
+		t.Errorf("expect less than flush size buffered, got %d", n)
+	}
+
+	if err := encoder.Flush(); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := buffered.String(), w.String(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if n := len(encoder.Bytes()); n != 0 {
+		t.Errorf("expect nothing buffered after flush, got %d", n)
+	}
+}
+
+func TestStreamEncoder_DefaultFlushSize(t *testing.T) {
+	var w recordingWriter
+	encoder := xml.NewStreamEncoder(&w)
+	encodeStreamTestDocument(encoder, 10)
+
+	if e, a := 0, w.writes; e != a {
+		t.Errorf("expect %d writes before flush, got %d", e, a)
+	}
+	if err := encoder.Flush(); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := 1, w.writes; e != a {
+		t.Errorf("expect %d writes, got %d", e, a)
+	}
+}
+
+func TestStreamEncoder_WriteError(t *testing.T) {
+	writeErr := errors.New("write error")
+	w := recordingWriter{err: writeErr}
+	encoder := xml.NewStreamEncoder(&w, func(o *xml.StreamEncoderOptions) {
+		o.FlushSize = 16
+	})
+	encodeStreamTestDocument(encoder, 100)
+
+	if err := encoder.Err(); !errors.Is(err, writeErr) {
+		t.Errorf("expect write error, got %v", err)
+	}
+	if err := encoder.Flush(); !errors.Is(err, writeErr) {
+		t.Errorf("expect write error, got %v", err)
+	}
+	if w.Len() != 0 {
+		t.Errorf("expect nothing written, got %q", w.String())
+	}
+}
+
+func TestEncoder_FlushNotStream(t *testing.T) {
+	var b bytes.Buffer
+	encoder := xml.NewEncoder(&b)
+	encodeStreamTestDocument(encoder, 1)
+
+	if err := encoder.Flush(); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := `<root><list><member>0</member></list></root>`, b.String(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
+
+func TestStreamEncoder_StringFromReader(t *testing.T) {
+	w := &recordingWriter{}
+	encoder := xml.NewStreamEncoder(w, func(o *xml.StreamEncoderOptions) {
+		o.FlushSize = 1024
+	})
+
+	text := strings.Repeat("a&b", 10*1024)
+	r := encoder.RootElement(root)
+	if err := r.MemberElement(xml.StartElement{Name: xml.Name{Local: "text"}}).StringFromReader(strings.NewReader(text)); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	// chunks of the text are written as they are read
+	if w.writes < 2 {
+		t.Errorf("expect text to be written in more than one write, got %v", w.writes)
+	}
+
+	r.Close()
+	if err := encoder.Flush(); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	expected := "<root><text>" + strings.ReplaceAll(text, "&", "&amp;") + "</text></root>"
+	if e, a := expected, w.String(); e != a {
+		t.Errorf("expect %d bytes, got %d", len(e), len(a))
+	}
+}
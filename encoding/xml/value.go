@@ -3,10 +3,13 @@ package xml
 import (
 	"encoding/base64"
 	"fmt"
+	"io"
 	"math/big"
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/aws/smithy-go/encoding"
+	"github.com/aws/smithy-go/encoding/internal/chunk"
 )
 
 // Value represents an XML Value type
@@ -20,6 +23,12 @@ type Value struct {
 
 	// indicates if the Value represents a flattened shape
 	isFlattened bool
+
+	// namespace scope of the Value's element
+	ns *namespaceScope
+
+	// end element of the Value's element, if its namespace was resolved
+	endElement EndElement
 }
 
 // newFlattenedValue returns a Value encoder. newFlattenedValue does NOT write the start element tag
@@ -33,13 +42,57 @@ func newFlattenedValue(w writer, scratch *[]byte, startElement StartElement) Val
 
 // newValue writes the start element xml tag and returns a Value
 func newValue(w writer, scratch *[]byte, startElement StartElement) Value {
-	writeStartElement(w, startElement)
+	writeStartElement(w, startElement, escapePolicy{})
 	return Value{w: w, scratch: scratch, startElement: startElement}
 }
 
+// newElementValue writes the start element xml tag, with its namespaces
+// resolved if ns is managed, and returns a Value.
+func newElementValue(w writer, scratch *[]byte, ns *namespaceScope, startElement StartElement) Value {
+	if !ns.isManaged() {
+		writeStartElement(w, startElement, ns.escapePolicy())
+		return Value{w: w, scratch: scratch, startElement: startElement, ns: ns}
+	}
+	return newNamespacedValue(w, scratch, ns, startElement, nil)
+}
+
+// newNamespacedValue writes the start element xml tag with its namespaces
+// resolved and declared, and returns a Value in the element's scope.
+func newNamespacedValue(w writer, scratch *[]byte, ns *namespaceScope, startElement StartElement, namespaces []Namespace) Value {
+	scope, resolved := ns.resolve(startElement, namespaces)
+	writeStartElement(w, resolved, scope.escapePolicy())
+	return Value{
+		w:            w,
+		scratch:      scratch,
+		startElement: startElement,
+		ns:           scope,
+		endElement:   resolved.End(),
+	}
+}
+
 // writeStartElement takes in a start element and writes it.
 // It handles namespace, attributes in start element.
-func writeStartElement(w writer, el StartElement) error {
+func writeStartElement(w writer, el StartElement, p escapePolicy) error {
+	if err := writeStartTag(w, el, p); err != nil {
+		return err
+	}
+	w.WriteRune(rightAngleBracket)
+	return nil
+}
+
+// writeEmptyElement takes in a start element and writes it as a
+// self-closing element tag.
+func writeEmptyElement(w writer, el StartElement, p escapePolicy) error {
+	if err := writeStartTag(w, el, p); err != nil {
+		return err
+	}
+	w.WriteRune(forwardSlash)
+	w.WriteRune(rightAngleBracket)
+	return nil
+}
+
+// writeStartTag writes the start element's tag without its closing bracket.
+func writeStartTag(w writer, el StartElement, p escapePolicy) error {
 	if el.isZero() {
 		return fmt.Errorf("xml start element cannot be nil")
 	}
@@ -47,26 +100,24 @@ func writeStartElement(w writer, el StartElement) error {
 	w.WriteRune(leftAngleBracket)
 
 	if len(el.Name.Space) != 0 {
-		escapeString(w, el.Name.Space)
+		escapeString(w, el.Name.Space, escapePolicy{})
 		w.WriteRune(colon)
 	}
-	escapeString(w, el.Name.Local)
+	escapeString(w, el.Name.Local, escapePolicy{})
 	for _, attr := range el.Attr {
 		w.WriteRune(' ')
-		writeAttribute(w, &attr)
+		writeAttribute(w, &attr, p)
 	}
-
-	w.WriteRune(rightAngleBracket)
 	return nil
 }
 
 // writeAttribute writes an attribute from a provided Attribute
 // For a namespace attribute, the attr.Name.Space must be defined as "xmlns".
 // https://www.w3.org/TR/REC-xml-names/#NT-DefaultAttName
-func writeAttribute(w writer, attr *Attr) {
+func writeAttribute(w writer, attr *Attr, p escapePolicy) {
 	// if local, space both are not empty
 	if len(attr.Name.Space) != 0 && len(attr.Name.Local) != 0 {
-		escapeString(w, attr.Name.Space)
+		escapeString(w, attr.Name.Space, escapePolicy{})
 		w.WriteRune(colon)
 	}
 
@@ -75,10 +126,10 @@ func writeAttribute(w writer, attr *Attr) {
 		attr.Name.Local = attr.Name.Space
 	}
 
-	escapeString(w, attr.Name.Local)
+	escapeString(w, attr.Name.Local, escapePolicy{})
 	w.WriteRune(equals)
 	w.WriteRune(quote)
-	escapeString(w, attr.Value)
+	escapeString(w, attr.Value, p)
 	w.WriteRune(quote)
 }
 
@@ -92,10 +143,10 @@ func writeEndElement(w writer, el EndElement) error {
 	w.WriteRune(forwardSlash)
 
 	if len(el.Name.Space) != 0 {
-		escapeString(w, el.Name.Space)
+		escapeString(w, el.Name.Space, escapePolicy{})
 		w.WriteRune(colon)
 	}
-	escapeString(w, el.Name.Local)
+	escapeString(w, el.Name.Local, escapePolicy{})
 	w.WriteRune(rightAngleBracket)
 
 	return nil
@@ -104,7 +155,128 @@ func writeEndElement(w writer, el EndElement) error {
 // String encodes v as a XML string.
 // It will auto close the parent xml element tag.
 func (xv Value) String(v string) {
-	escapeString(xv.w, v)
+	escapeString(xv.w, v, xv.ns.escapePolicy())
+	xv.Close()
+}
+
+// textChunkSize is the number of bytes read from the io.Reader of
+// StringFromReader at a time.
+const textChunkSize = 4 * 1024
+
+// StringFromReader encodes the contents of r as a XML string, escaping it as
+// by String. r is read and escaped in chunks directly into the output, so
+// large text need not be held in memory first. The contents of r are UTF-8,
+// of which invalid sequences are replaced as by String.
+//
+// If reading from r fails the element is closed, such that the output is
+// still well-formed XML, and the error is returned and recorded by the
+// Encoder, see Encoder.Err.
+// It will auto close the parent xml element tag.
+func (xv Value) StringFromReader(r io.Reader) error {
+	defer xv.Close()
+
+	text := xv.scratchSize(textChunkSize)
+	carry := 0
+	for {
+		n, err := chunk.Read(r, text[carry:])
+		n += carry
+
+		// a rune split across chunks is escaped with the next chunk
+		carry = 0
+		if err == nil {
+			carry = incompleteRuneSuffix(text[:n])
+		}
+		escapeText(xv.w, text[:n-carry], xv.ns.escapePolicy())
+		copy(text, text[n-carry:n])
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return xv.readerErr(err)
+		}
+		xv.flushStream()
+	}
+}
+
+// Base64EncodeReader writes the contents of r as a base64 value in XML
+// string. r is read and encoded in chunks directly into the output, so large
+// blobs need not be held in memory in both their raw and encoded forms.
+//
+// If reading from r fails the element is closed, such that the output is
+// still well-formed XML, and the error is returned and recorded by the
+// Encoder, see Encoder.Err.
+// It will auto close the parent xml element tag.
+func (xv Value) Base64EncodeReader(r io.Reader) error {
+	defer xv.Close()
+
+	size := chunk.Base64Size + base64.StdEncoding.EncodedLen(chunk.Base64Size)
+	buf := xv.scratchSize(size)
+	raw, encoded := buf[:chunk.Base64Size], buf[chunk.Base64Size:size]
+
+	for {
+		n, err := chunk.Read(r, raw)
+		if n > 0 {
+			base64.StdEncoding.Encode(encoded, raw[:n])
+			xv.w.Write(encoded[:base64.StdEncoding.EncodedLen(n)])
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return xv.readerErr(err)
+		}
+		xv.flushStream()
+	}
+}
+
+// scratchSize returns the scratch buffer, grown to at least size bytes.
+func (xv Value) scratchSize(size int) []byte {
+	if cap(*xv.scratch) < size {
+		*xv.scratch = make([]byte, size)
+	}
+	return (*xv.scratch)[:size]
+}
+
+// readerErr records the error reading the content of the value from an
+// io.Reader as an error of the encoder.
+func (xv Value) readerErr(err error) error {
+	err = fmt.Errorf("element %s, %w", xv.startElement.Name.Local, err)
+	if xv.ns != nil {
+		xv.ns.state.setErr(err)
+	}
+	return err
+}
+
+// flushStream writes the content buffered by a stream encoder if its buffer
+// is full.
+func (xv Value) flushStream() {
+	if s, ok := xv.w.(*streamWriter); ok {
+		s.flushIfFull()
+	}
+}
+
+// incompleteRuneSuffix returns the length of the incomplete UTF-8 encoded
+// rune at the end of p, if any.
+func incompleteRuneSuffix(p []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(p); i++ {
+		b := p[len(p)-i]
+		if utf8.RuneStart(b) {
+			if utf8.FullRune(p[len(p)-i:]) {
+				return 0
+			}
+			return i
+		}
+	}
+	return 0
+}
+
+// WriteCDATA encodes v as a XML string within CDATA sections, such that
+// markup in v, e.g. "<" and "&", is written as is rather than escaped.
+// Any "]]>" in v is split across two sections.
+// It will auto close the parent xml element tag.
+func (xv Value) WriteCDATA(v string) {
+	writeCDATA(xv.w, v)
 	xv.Close()
 }
 
@@ -195,7 +367,7 @@ func (xv Value) BigDecimal(v *big.Float) {
 func (xv Value) Write(v []byte, escapeXMLText bool) {
 	// escape and write xml text
 	if escapeXMLText {
-		escapeText(xv.w, v)
+		escapeText(xv.w, v, xv.ns.escapePolicy())
 	} else {
 		// write xml directly
 		xv.w.Write(v)
@@ -210,7 +382,68 @@ func (xv Value) Write(v []byte, escapeXMLText bool) {
 // A call to MemberElement will write nested element tags directly using the
 // provided start element. The value returned by MemberElement should be closed.
 func (xv Value) MemberElement(element StartElement) Value {
-	return newValue(xv.w, xv.scratch, element)
+	return newElementValue(xv.w, xv.scratch, xv.ns, element)
+}
+
+// MemberElementNS does member element encoding with namespace management,
+// declaring the namespaces on the element. It returns a Value, which should
+// be closed.
+//
+// The namespaces are in scope of the element and its descendants, which are
+// encoded with namespace management as described by Encoder.RootElementNS.
+func (xv Value) MemberElementNS(element StartElement, namespaces ...Namespace) Value {
+	return newNamespacedValue(xv.w, xv.scratch, xv.ns, element, namespaces)
+}
+
+// EmptyElement writes the element as a self-closing element tag, e.g.
+// `<name/>`, which decodes as an empty value.
+func (xv Value) EmptyElement(element StartElement) {
+	xv.writeEmptyElement(element, nil)
+}
+
+// NilElement writes the element as a self-closing element tag with the
+// xsi:nil="true" attribute, which identifies a nil value, e.g.
+// `<name xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/>`.
+// The xsi namespace is declared on the element unless it is already in scope
+// of a Value with namespace management, see Encoder.RootElementNS.
+func (xv Value) NilElement(element StartElement) {
+	element = element.Copy()
+	if !xv.ns.isManaged() {
+		element.Attr = append(element.Attr,
+			NewNamespaceAttribute(xsiPrefix, xsiNamespaceURI),
+			Attr{Name: Name{Space: xsiPrefix, Local: "nil"}, Value: "true"},
+		)
+		xv.writeEmptyElement(element, nil)
+		return
+	}
+
+	element.Attr = append(element.Attr, Attr{Name: Name{Space: xsiNamespaceURI, Local: "nil"}, Value: "true"})
+	xv.writeEmptyElement(element, []Namespace{{Prefix: xsiPrefix, URI: xsiNamespaceURI}})
+}
+
+// NilableStringElement writes the element with the string value v, or, if v
+// is nil, as configured by EncoderOptions.NilStrings.
+func (xv Value) NilableStringElement(element StartElement, v *string) {
+	if v != nil {
+		xv.MemberElement(element).String(*v)
+		return
+	}
+
+	switch xv.ns.nilStrings() {
+	case NilStringEmptyElement:
+		xv.EmptyElement(element)
+	case NilStringNilElement:
+		xv.NilElement(element)
+	}
+}
+
+func (xv Value) writeEmptyElement(element StartElement, namespaces []Namespace) {
+	if xv.ns.isManaged() {
+		_, element = xv.ns.resolve(element, namespaces)
+	}
+	writeEmptyElement(xv.w, element, xv.ns.escapePolicy())
+
+	xv.flushStream()
 }
 
 // FlattenedElement returns flattened element encoding. It returns a Value.
@@ -223,6 +456,7 @@ func (xv Value) MemberElement(element StartElement) Value {
 func (xv Value) FlattenedElement(element StartElement) Value {
 	v := newFlattenedValue(xv.w, xv.scratch, element)
 	v.isFlattened = true
+	v.ns = xv.ns
 	return v
 }
 
@@ -231,7 +465,9 @@ func (xv Value) FlattenedElement(element StartElement) Value {
 // If value is marked as flattened, the start element is used to wrap the members instead of
 // the `<member>` element.
 func (xv Value) Array() *Array {
-	return newArray(xv.w, xv.scratch, arrayMemberWrapper, xv.startElement, xv.isFlattened)
+	a := newArray(xv.w, xv.scratch, arrayMemberWrapper, xv.startElement, xv.isFlattened)
+	a.ns = xv.ns
+	return a
 }
 
 /*
@@ -242,7 +478,9 @@ for eg, `<someList><customName>entry1</customName></someList>`
 Here `customName` named start element will be wrapped on each array member.
 */
 func (xv Value) ArrayWithCustomName(element StartElement) *Array {
-	return newArray(xv.w, xv.scratch, element, xv.startElement, xv.isFlattened)
+	a := newArray(xv.w, xv.scratch, element, xv.startElement, xv.isFlattened)
+	a.ns = xv.ns
+	return a
 }
 
 /*
@@ -253,13 +491,16 @@ If value is marked as flattened, the start element is used to wrap the entry ins
 the `<member>` element.
 */
 func (xv Value) Map() *Map {
-	// flattened map
+	var m *Map
 	if xv.isFlattened {
-		return newFlattenedMap(xv.w, xv.scratch, xv.startElement)
+		// flattened map
+		m = newFlattenedMap(xv.w, xv.scratch, xv.startElement)
+	} else {
+		// un-flattened map
+		m = newMap(xv.w, xv.scratch)
 	}
-
-	// un-flattened map
-	return newMap(xv.w, xv.scratch)
+	m.ns = xv.ns
+	return m
 }
 
 // encodeByteSlice is modified copy of json encoder's encodeByteSlice.
@@ -298,5 +539,11 @@ func (xv Value) IsFlattened() bool {
 
 // Close closes the value.
 func (xv Value) Close() {
-	writeEndElement(xv.w, xv.startElement.End())
+	if !xv.endElement.isZero() {
+		writeEndElement(xv.w, xv.endElement)
+	} else {
+		writeEndElement(xv.w, xv.startElement.End())
+	}
+
+	xv.flushStream()
 }
@@ -2,11 +2,18 @@ package xml
 
 import (
 	"bytes"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"math/big"
 	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
+
+	"github.com/aws/smithy-go/encoding/internal/chunk"
 )
 
 var (
@@ -33,6 +40,30 @@ func TestValue(t *testing.T) {
 			},
 			expected: fmt.Sprintf("{%sfoo%s:%sbar%s}", escQuot, escQuot, escQuot, escQuot),
 		},
+		"cdata": {
+			setter: func(value Value) {
+				value.WriteCDATA(`<a href="x">&amp;</a>`)
+			},
+			expected: `<![CDATA[<a href="x">&amp;</a>]]>`,
+		},
+		"cdata empty": {
+			setter: func(value Value) {
+				value.WriteCDATA("")
+			},
+			expected: ``,
+		},
+		"cdata end split": {
+			setter: func(value Value) {
+				value.WriteCDATA("a]]>b]]]>")
+			},
+			expected: `<![CDATA[a]]]]><![CDATA[>b]]]]]><![CDATA[>]]>`,
+		},
+		"cdata carriage return": {
+			setter: func(value Value) {
+				value.WriteCDATA("a\r\nb\x00")
+			},
+			expected: "<![CDATA[a]]>&#xD;<![CDATA[\nb\uFFFD]]>",
+		},
 		"integer": {
 			setter: func(value Value) {
 				value.Long(1024)
@@ -210,3 +241,141 @@ func TestWrappedValueWithNameSpaceAndAttributes(t *testing.T) {
 		t.Errorf("expected %+q, but got %+q", e, a)
 	}
 }
+
+func TestValue_WriteCDATARoundTrip(t *testing.T) {
+	for _, v := range []string{
+		"",
+		"plain",
+		"<markup attr='1'>&entity;</markup>",
+		"]]>",
+		"a]]]>b]]>>c",
+		"line\r\nbreaks\r",
+	} {
+		b := bytes.NewBuffer(nil)
+		scratch := make([]byte, 64)
+		newValue(b, &scratch, StartElement{Name: Name{Local: "root"}}).WriteCDATA(v)
+
+		var decoded struct {
+			Text string `xml:",chardata"`
+		}
+		if err := xml.Unmarshal(b.Bytes(), &decoded); err != nil {
+			t.Fatalf("%q: expect no error, got %v", v, err)
+		}
+		if e, a := v, decoded.Text; e != a {
+			t.Errorf("expect %q, got %q, from %s", e, a, b.String())
+		}
+	}
+}
+
+func TestValue_StringFromReader(t *testing.T) {
+	// multi-byte runes are split across chunks
+	long := strings.Repeat("a<\u00e9\U0001F600&", textChunkSize)
+
+	cases := map[string]struct {
+		reader func(string) io.Reader
+		input  string
+	}{
+		"empty": {
+			input: "",
+		},
+		"escaped": {
+			input: "<a href=\"x\">&amp;</a>\n\r\t",
+		},
+		"larger than chunk": {
+			input: long,
+		},
+		"rune split at chunk boundary": {
+			input: strings.Repeat("a", textChunkSize-1) + "\U0001F600" + strings.Repeat("b", textChunkSize-2) + "\u00e9c",
+		},
+		"invalid utf8 at chunk boundary": {
+			input: strings.Repeat("a", textChunkSize-1) + "\xe2\x82" + "b",
+		},
+		"one byte reads": {
+			reader: func(s string) io.Reader { return iotest.OneByteReader(strings.NewReader(s)) },
+			input:  "\u00e9\U0001F600\u2028",
+		},
+		"data and EOF": {
+			reader: func(s string) io.Reader { return iotest.DataErrReader(strings.NewReader(s)) },
+			input:  long[:textChunkSize+1],
+		},
+		"invalid utf8": {
+			input: "a\xffb\xe2\x82",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			expected := bytes.NewBuffer(nil)
+			scratch := make([]byte, 64)
+			newValue(expected, &scratch, StartElement{Name: Name{Local: "root"}}).String(c.input)
+
+			var r io.Reader = strings.NewReader(c.input)
+			if c.reader != nil {
+				r = c.reader(c.input)
+			}
+			b := bytes.NewBuffer(nil)
+			if err := newValue(b, &scratch, StartElement{Name: Name{Local: "root"}}).StringFromReader(r); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := expected.String(), b.String(); e != a {
+				t.Errorf("expect %q, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestValue_Base64EncodeReader(t *testing.T) {
+	for _, size := range []int{0, 1, 2, chunk.Base64Size, chunk.Base64Size + 1, 3*chunk.Base64Size + 2} {
+		t.Run(strconv.Itoa(size), func(t *testing.T) {
+			v := make([]byte, size)
+			for i := range v {
+				v[i] = byte(i)
+			}
+
+			expected := bytes.NewBuffer(nil)
+			scratch := make([]byte, 64)
+			newValue(expected, &scratch, StartElement{Name: Name{Local: "root"}}).Base64EncodeBytes(v)
+
+			b := bytes.NewBuffer(nil)
+			r := iotest.HalfReader(bytes.NewReader(v))
+			if err := newValue(b, &scratch, StartElement{Name: Name{Local: "root"}}).Base64EncodeReader(r); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := expected.String(), b.String(); e != a {
+				t.Errorf("expect %q, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestValue_ReaderError(t *testing.T) {
+	readErr := fmt.Errorf("read failed")
+
+	for name, encode := range map[string]func(Value, io.Reader) error{
+		"string": Value.StringFromReader,
+		"base64": Value.Base64EncodeReader,
+	} {
+		t.Run(name, func(t *testing.T) {
+			encoder := NewEncoder(bytes.NewBuffer(nil))
+			root := encoder.RootElement(StartElement{Name: Name{Local: "root"}})
+			member := root.MemberElement(StartElement{Name: Name{Local: "member"}})
+
+			err := encode(member, io.MultiReader(strings.NewReader("abc"), iotest.ErrReader(readErr)))
+			root.Close()
+			if !errors.Is(err, readErr) {
+				t.Fatalf("expect %v, got %v", readErr, err)
+			}
+			if e, a := "element member", err.Error(); !strings.Contains(a, e) {
+				t.Errorf("expect %v in error, got %v", e, a)
+			}
+			if !errors.Is(encoder.Err(), readErr) {
+				t.Errorf("expect encoder error %v, got %v", readErr, encoder.Err())
+			}
+
+			// the output is well-formed
+			if err := xml.Unmarshal(encoder.Bytes(), new(struct{})); err != nil {
+				t.Errorf("expect well-formed output, got %v, %s", err, encoder.String())
+			}
+		})
+	}
+}
@@ -103,7 +103,14 @@ func (d NodeDecoder) GetElement(name string) (t xml.StartElement, err error) {
 // Value provides an abstraction to retrieve char data value within an xml element.
 // The method will return an error if it encounters a nested xml element instead of char data.
 // This method should only be used to retrieve simple type or blob shape values as []byte.
+//
+// Value returns a nil slice for an element with the xsi:nil="true" attribute, see IsNil, and an
+// empty slice for an empty element.
 func (d NodeDecoder) Value() (c []byte, err error) {
+	if d.IsNil() {
+		return nil, d.Decoder.Skip()
+	}
+
 	t, e := d.Decoder.Token()
 	if e != nil {
 		return c, e
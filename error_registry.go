@@ -0,0 +1,106 @@
+package smithy
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ErrorResponse is the protocol agnostic description of an error response,
+// from which an ErrorConstructor builds the error.
+type ErrorResponse struct {
+	// The error code identifying the error, as resolved by the protocol
+	// deserializer, e.g. "ResourceNotFoundException".
+	Code string
+
+	// The error message, if the protocol deserializer resolved one.
+	Message string
+
+	// The fault of the error, if known.
+	Fault ErrorFault
+
+	// The error response body, from which the constructor may decode members
+	// of the error. Nil if the response had no body.
+	Body []byte
+}
+
+// ErrorConstructor returns the error for an error response. Returns an error
+// if the error could not be decoded from the response.
+type ErrorConstructor func(resp ErrorResponse) (APIError, error)
+
+// ErrorRegistry maps error codes to the constructors of the errors they
+// identify. Generated clients have an ErrorRegistry, which their
+// deserializers consult with NewError for error codes that are not modeled
+// by the client, such that errors added to an API, e.g. by a plugin or an
+// extended service, can be returned as typed errors without regenerating the
+// client.
+//
+// The zero value is an empty registry, and a nil *ErrorRegistry has no
+// errors. An ErrorRegistry is safe for concurrent use.
+type ErrorRegistry struct {
+	mu           sync.RWMutex
+	constructors map[string]ErrorConstructor
+}
+
+// Register adds the constructor for the error code to the registry. Returns
+// an error if a constructor is already registered for the code.
+func (r *ErrorRegistry) Register(code string, fn ErrorConstructor) error {
+	if len(code) == 0 || fn == nil {
+		return fmt.Errorf("error constructor must have a code and function")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.constructors[code]; ok {
+		return fmt.Errorf("error code %q is already registered", code)
+	}
+	if r.constructors == nil {
+		r.constructors = map[string]ErrorConstructor{}
+	}
+	r.constructors[code] = fn
+	return nil
+}
+
+// Lookup returns the constructor registered for the error code, if any.
+func (r *ErrorRegistry) Lookup(code string) (ErrorConstructor, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fn, ok := r.constructors[code]
+	return fn, ok
+}
+
+// NewError returns the error for the response, built by the constructor
+// registered for its code. If the code is not registered, a GenericAPIError
+// of the response's code, message, and fault is returned.
+//
+// An error decoding the response is returned as a DeserializationError with
+// the response body as its snapshot.
+func (r *ErrorRegistry) NewError(resp ErrorResponse) (APIError, error) {
+	fn, ok := r.Lookup(resp.Code)
+	if !ok {
+		return &GenericAPIError{
+			Code:    resp.Code,
+			Message: resp.Message,
+			Fault:   resp.Fault,
+		}, nil
+	}
+
+	apiErr, err := fn(resp)
+	if err != nil {
+		return nil, &DeserializationError{
+			Err:      fmt.Errorf("failed to decode %s error, %w", resp.Code, err),
+			Snapshot: resp.Body,
+		}
+	}
+	if apiErr == nil {
+		return nil, &DeserializationError{
+			Err:      fmt.Errorf("error constructor for %s returned no error", resp.Code),
+			Snapshot: resp.Body,
+		}
+	}
+	return apiErr, nil
+}
@@ -0,0 +1,127 @@
+package smithy
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type quotaExceededError struct {
+	Message string `json:"message"`
+	Quota   int    `json:"quota"`
+}
+
+func (e *quotaExceededError) Error() string          { return "quota exceeded: " + e.Message }
+func (e *quotaExceededError) ErrorCode() string      { return "QuotaExceeded" }
+func (e *quotaExceededError) ErrorMessage() string   { return e.Message }
+func (e *quotaExceededError) ErrorFault() ErrorFault { return FaultClient }
+
+func newQuotaExceededError(resp ErrorResponse) (APIError, error) {
+	var e quotaExceededError
+	if err := json.Unmarshal(resp.Body, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func TestErrorRegistry_NewError(t *testing.T) {
+	var registry ErrorRegistry
+	if err := registry.Register("QuotaExceeded", newQuotaExceededError); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	registry.Register("Empty", func(ErrorResponse) (APIError, error) { return nil, nil })
+
+	cases := map[string]struct {
+		registry *ErrorRegistry
+		resp     ErrorResponse
+		expect   APIError
+		err      string
+	}{
+		"registered": {
+			registry: &registry,
+			resp: ErrorResponse{
+				Code: "QuotaExceeded",
+				Body: []byte(`{"message":"too many","quota":10}`),
+			},
+			expect: &quotaExceededError{Message: "too many", Quota: 10},
+		},
+		"not registered": {
+			registry: &registry,
+			resp:     ErrorResponse{Code: "Other", Message: "msg", Fault: FaultServer},
+			expect:   &GenericAPIError{Code: "Other", Message: "msg", Fault: FaultServer},
+		},
+		"nil registry": {
+			resp:   ErrorResponse{Code: "QuotaExceeded", Message: "msg"},
+			expect: &GenericAPIError{Code: "QuotaExceeded", Message: "msg"},
+		},
+		"decode error": {
+			registry: &registry,
+			resp:     ErrorResponse{Code: "QuotaExceeded", Body: []byte(`{`)},
+			err:      "failed to decode QuotaExceeded error",
+		},
+		"no error": {
+			registry: &registry,
+			resp:     ErrorResponse{Code: "Empty"},
+			err:      "error constructor for Empty returned no error",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			apiErr, err := c.registry.NewError(c.resp)
+			if len(c.err) != 0 {
+				var dErr *DeserializationError
+				if !errors.As(err, &dErr) {
+					t.Fatalf("expect DeserializationError, got %v", err)
+				}
+				if !strings.Contains(err.Error(), c.err) {
+					t.Errorf("expect error %q, got %v", c.err, err)
+				}
+				if e, a := string(c.resp.Body), string(dErr.Snapshot); e != a {
+					t.Errorf("expect snapshot %q, got %q", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			switch e := c.expect.(type) {
+			case *quotaExceededError:
+				a, ok := apiErr.(*quotaExceededError)
+				if !ok || *e != *a {
+					t.Errorf("expect %#v, got %#v", e, apiErr)
+				}
+			case *GenericAPIError:
+				a, ok := apiErr.(*GenericAPIError)
+				if !ok || *e != *a {
+					t.Errorf("expect %#v, got %#v", e, apiErr)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorRegistry_Register(t *testing.T) {
+	var registry ErrorRegistry
+	if err := registry.Register("QuotaExceeded", newQuotaExceededError); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if err := registry.Register("QuotaExceeded", newQuotaExceededError); err == nil {
+		t.Errorf("expect error registering duplicate code")
+	}
+	if err := registry.Register("", newQuotaExceededError); err == nil {
+		t.Errorf("expect error registering empty code")
+	}
+	if err := registry.Register("Other", nil); err == nil {
+		t.Errorf("expect error registering nil constructor")
+	}
+
+	if _, ok := registry.Lookup("QuotaExceeded"); !ok {
+		t.Errorf("expect constructor to be registered")
+	}
+	if _, ok := registry.Lookup("Other"); ok {
+		t.Errorf("expect no constructor")
+	}
+}
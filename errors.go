@@ -1,6 +1,9 @@
 package smithy
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // APIError provides the generic API and protocol agnostic error type all SDK
 // generated exception types will implement.
@@ -22,6 +25,11 @@ type GenericAPIError struct {
 	Code    string
 	Message string
 	Fault   ErrorFault
+
+	// The amount of time the service asked the client to wait before retrying,
+	// if it was provided in the error response. Nil if not provided, such that
+	// a hint to retry immediately can be expressed as zero.
+	RetryAfter *time.Duration
 }
 
 // ErrorCode returns the error code for the API exception.
@@ -33,11 +41,62 @@ func (e *GenericAPIError) ErrorMessage() string { return e.Message }
 // ErrorFault returns the fault for the API exception.
 func (e *GenericAPIError) ErrorFault() ErrorFault { return e.Fault }
 
+// RetryAfterHint returns the RetryAfter duration, if it is set.
+func (e *GenericAPIError) RetryAfterHint() (time.Duration, bool) {
+	if e.RetryAfter == nil {
+		return 0, false
+	}
+	return *e.RetryAfter, true
+}
+
 func (e *GenericAPIError) Error() string {
 	return fmt.Sprintf("api error %s: %s", e.Code, e.Message)
 }
 
 var _ APIError = (*GenericAPIError)(nil)
+var _ RetryAfterHinter = (*GenericAPIError)(nil)
+
+// RetryAfterHinter is implemented by errors that carry a hint from the
+// service of how long the client should wait before retrying the request,
+// e.g. transport errors populated from the HTTP Retry-After header, or
+// modeled errors populated from a field of the error payload.
+//
+// Retry strategies should use GetRetryAfterHint to look for a hint rather
+// than asserting this interface on an error directly.
+type RetryAfterHinter interface {
+	// RetryAfterHint returns the hinted duration, and whether one was
+	// provided.
+	RetryAfterHint() (time.Duration, bool)
+}
+
+// GetRetryAfterHint returns the first hint provided by a RetryAfterHinter in
+// err's tree, in the order visited by errors.As. Errors in the tree that implement
+// RetryAfterHinter but do not provide a hint are skipped, such that a
+// transport error without a header does not mask a hint in the modeled error
+// it wraps.
+func GetRetryAfterHint(err error) (time.Duration, bool) {
+	if err == nil {
+		return 0, false
+	}
+
+	if h, ok := err.(RetryAfterHinter); ok {
+		if d, ok := h.RetryAfterHint(); ok {
+			return d, true
+		}
+	}
+
+	switch v := err.(type) {
+	case interface{ Unwrap() error }:
+		return GetRetryAfterHint(v.Unwrap())
+	case interface{ Unwrap() []error }:
+		for _, err := range v.Unwrap() {
+			if d, ok := GetRetryAfterHint(err); ok {
+				return d, true
+			}
+		}
+	}
+	return 0, false
+}
 
 // OperationError decorates an underlying error which occurred while invoking
 // an operation with names of the operation and API.
@@ -0,0 +1,83 @@
+package smithy
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/ptr"
+)
+
+type retryAfterError struct {
+	wait time.Duration
+	ok   bool
+}
+
+func (e *retryAfterError) Error() string { return "retry after error" }
+
+func (e *retryAfterError) RetryAfterHint() (time.Duration, bool) { return e.wait, e.ok }
+
+func TestGetRetryAfterHint(t *testing.T) {
+	cases := map[string]struct {
+		err        error
+		expectOK   bool
+		expectWait time.Duration
+	}{
+		"nil": {},
+		"no hinter": {
+			err: fmt.Errorf("plain"),
+		},
+		"wrapped": {
+			err:        fmt.Errorf("wrap: %w", &retryAfterError{wait: time.Second, ok: true}),
+			expectOK:   true,
+			expectWait: time.Second,
+		},
+		"operation error": {
+			err: &OperationError{
+				Err: &retryAfterError{wait: time.Second, ok: true},
+			},
+			expectOK:   true,
+			expectWait: time.Second,
+		},
+		"skips hinter without hint": {
+			err: fmt.Errorf("wrap: %w", &wrappedRetryAfterError{
+				retryAfterError: retryAfterError{},
+				err:             &GenericAPIError{RetryAfter: ptr.Duration(2 * time.Second)},
+			}),
+			expectOK:   true,
+			expectWait: 2 * time.Second,
+		},
+		"joined": {
+			err:        errors.Join(fmt.Errorf("plain"), &GenericAPIError{RetryAfter: ptr.Duration(time.Minute)}),
+			expectOK:   true,
+			expectWait: time.Minute,
+		},
+		"api error without hint": {
+			err: &GenericAPIError{Code: "Throttling"},
+		},
+		"api error zero hint": {
+			err:      &GenericAPIError{RetryAfter: ptr.Duration(0)},
+			expectOK: true,
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			wait, ok := GetRetryAfterHint(tt.err)
+			if e, a := tt.expectOK, ok; e != a {
+				t.Fatalf("expect ok %v, got %v", e, a)
+			}
+			if e, a := tt.expectWait, wait; e != a {
+				t.Errorf("expect wait %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+type wrappedRetryAfterError struct {
+	retryAfterError
+	err error
+}
+
+func (e *wrappedRetryAfterError) Unwrap() error { return e.err }
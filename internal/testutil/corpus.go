@@ -0,0 +1,215 @@
+// Package testutil provides test helpers shared between the test suites of
+// the packages in this module.
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// CorpusOptions is the set of options that can be configured when loading a
+// Corpus.
+type CorpusOptions struct {
+	// The name of the field of each case that uniquely identifies it within
+	// the corpus. Defaults to "id".
+	KeyField string
+}
+
+// Corpus is an ordered set of golden test cases, loaded from JSON files
+// shared between runtimes for conformance testing. Each file contains a JSON
+// array of objects, keyed by the value of a string field.
+//
+// Cases are kept as raw JSON, such that each test suite can unmarshal them
+// into its own case type with Decode.
+type Corpus struct {
+	keys  []string
+	cases map[string]json.RawMessage
+}
+
+// NewCorpus returns an empty Corpus.
+func NewCorpus() *Corpus {
+	return &Corpus{cases: map[string]json.RawMessage{}}
+}
+
+// LoadCorpus loads the corpus files matching the glob pattern, in lexical
+// order, and merges them with Merge. It fails the test if no files match, or
+// if any file is invalid.
+func LoadCorpus(tb testing.TB, pattern string, optFns ...func(*CorpusOptions)) *Corpus {
+	tb.Helper()
+
+	o := CorpusOptions{KeyField: "id"}
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	names, err := filepath.Glob(pattern)
+	if err != nil {
+		tb.Fatalf("glob corpus %q: %v", pattern, err)
+	}
+	if len(names) == 0 {
+		tb.Fatalf("no corpus files match %q", pattern)
+	}
+	sort.Strings(names)
+
+	corpus := NewCorpus()
+	for _, name := range names {
+		c, err := readCorpus(name, o.KeyField)
+		if err != nil {
+			tb.Fatalf("load corpus %s: %v", name, err)
+		}
+		corpus = corpus.Merge(c)
+	}
+	return corpus
+}
+
+func readCorpus(name, keyField string) (*Corpus, error) {
+	p, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(p, &raw); err != nil {
+		return nil, err
+	}
+
+	c := NewCorpus()
+	for i, r := range raw {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(r, &fields); err != nil {
+			return nil, fmt.Errorf("case %d: %w", i, err)
+		}
+
+		var key string
+		if err := json.Unmarshal(fields[keyField], &key); err != nil || len(key) == 0 {
+			return nil, fmt.Errorf("case %d: missing string key field %q", i, keyField)
+		}
+		if _, ok := c.cases[key]; ok {
+			return nil, fmt.Errorf("case %d: duplicate key %q", i, key)
+		}
+		c.add(key, r)
+	}
+	return c, nil
+}
+
+func (c *Corpus) add(key string, r json.RawMessage) {
+	if _, ok := c.cases[key]; !ok {
+		c.keys = append(c.keys, key)
+	}
+	c.cases[key] = r
+}
+
+// Len returns the number of cases in the corpus.
+func (c *Corpus) Len() int {
+	return len(c.keys)
+}
+
+// Keys returns the keys of the cases in the corpus, in order.
+func (c *Corpus) Keys() []string {
+	return append([]string(nil), c.keys...)
+}
+
+// Case returns the raw JSON of the case with the given key.
+func (c *Corpus) Case(key string) (json.RawMessage, bool) {
+	r, ok := c.cases[key]
+	return r, ok
+}
+
+// Merge returns a new Corpus containing the cases of c followed by those of
+// other. A case in other replaces the case in c with the same key, in place,
+// such that a runtime can overlay its own expectations on a shared corpus.
+func (c *Corpus) Merge(other *Corpus) *Corpus {
+	merged := NewCorpus()
+	for _, k := range c.keys {
+		merged.add(k, c.cases[k])
+	}
+	for _, k := range other.keys {
+		merged.add(k, other.cases[k])
+	}
+	return merged
+}
+
+// Decode unmarshals every case in the corpus, in order, into the slice
+// pointed to by v. It fails the test if any case cannot be unmarshaled.
+func (c *Corpus) Decode(tb testing.TB, v interface{}) {
+	tb.Helper()
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		tb.Fatalf("decode corpus into %T, expect pointer to slice", v)
+	}
+
+	s := reflect.MakeSlice(rv.Elem().Type(), len(c.keys), len(c.keys))
+	for i, k := range c.keys {
+		if err := json.Unmarshal(c.cases[k], s.Index(i).Addr().Interface()); err != nil {
+			tb.Fatalf("decode corpus case %q: %v", k, err)
+		}
+	}
+	rv.Elem().Set(s)
+}
+
+// CorpusDiff describes the differences between two corpora, by case key.
+type CorpusDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// Empty returns whether the corpora were equivalent.
+func (d CorpusDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (d CorpusDiff) String() string {
+	var b strings.Builder
+	for _, k := range d.Added {
+		fmt.Fprintf(&b, "+ %s\n", k)
+	}
+	for _, k := range d.Removed {
+		fmt.Fprintf(&b, "- %s\n", k)
+	}
+	for _, k := range d.Changed {
+		fmt.Fprintf(&b, "~ %s\n", k)
+	}
+	return b.String()
+}
+
+// Diff returns the cases added, removed, or changed in b relative to a. Cases
+// are compared as JSON values, such that the formatting of the files and the
+// order of fields in each case is not significant.
+func Diff(a, b *Corpus) CorpusDiff {
+	var d CorpusDiff
+	for _, k := range b.keys {
+		ar, ok := a.cases[k]
+		if !ok {
+			d.Added = append(d.Added, k)
+		} else if !jsonEqual(ar, b.cases[k]) {
+			d.Changed = append(d.Changed, k)
+		}
+	}
+	for _, k := range a.keys {
+		if _, ok := b.cases[k]; !ok {
+			d.Removed = append(d.Removed, k)
+		}
+	}
+	return d
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	var av, bv interface{}
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
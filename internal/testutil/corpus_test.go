@@ -0,0 +1,93 @@
+package testutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type testCase struct {
+	ID    string   `json:"id"`
+	Value int      `json:"value"`
+	Tags  []string `json:"tags"`
+}
+
+func mustCorpus(t *testing.T, cases ...string) *Corpus {
+	t.Helper()
+
+	c := NewCorpus()
+	for _, r := range cases {
+		var tc testCase
+		if err := json.Unmarshal([]byte(r), &tc); err != nil {
+			t.Fatal(err)
+		}
+		c.add(tc.ID, json.RawMessage(r))
+	}
+	return c
+}
+
+func TestLoadCorpus(t *testing.T) {
+	c := LoadCorpus(t, "testdata/*.json")
+
+	if e, a := []string{"one", "two", "three"}, c.Keys(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect keys %v, got %v", e, a)
+	}
+
+	var cases []testCase
+	c.Decode(t, &cases)
+
+	expect := []testCase{
+		{ID: "one", Value: 1},
+		{ID: "two", Value: 22},
+		{ID: "three", Value: 3},
+	}
+	if !reflect.DeepEqual(expect, cases) {
+		t.Errorf("expect %v, got %v", expect, cases)
+	}
+}
+
+func TestCorpus_Case(t *testing.T) {
+	c := LoadCorpus(t, "testdata/a.json")
+	if r, ok := c.Case("two"); !ok || !jsonEqual(r, json.RawMessage(`{"tags":["x"],"value":2,"id":"two"}`)) {
+		t.Errorf("expect case two, got %s", r)
+	}
+	if _, ok := c.Case("three"); ok {
+		t.Errorf("expect no case three")
+	}
+}
+
+func TestReadCorpus_Invalid(t *testing.T) {
+	if _, err := readCorpus("testdata/a.json", "value"); err == nil {
+		t.Error("expect error for non-string key field")
+	}
+	if _, err := readCorpus("testdata/missing.json", "id"); err == nil {
+		t.Error("expect error for missing file")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := mustCorpus(t,
+		`{"id": "same", "value": 1, "tags": ["x"]}`,
+		`{"id": "changed", "value": 1}`,
+		`{"id": "removed"}`,
+	)
+	b := mustCorpus(t,
+		`{"tags": ["x"], "id": "same", "value": 1}`,
+		`{"id": "changed", "value": 2}`,
+		`{"id": "added"}`,
+	)
+
+	d := Diff(a, b)
+	expect := CorpusDiff{
+		Added:   []string{"added"},
+		Removed: []string{"removed"},
+		Changed: []string{"changed"},
+	}
+	if !reflect.DeepEqual(expect, d) {
+		t.Errorf("expect %v, got %v", expect, d)
+	}
+
+	if d := Diff(a, a); !d.Empty() {
+		t.Errorf("expect no diff, got\n%v", d)
+	}
+}
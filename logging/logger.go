@@ -29,6 +29,20 @@ func (f LoggerFunc) Logf(classification Classification, format string, v ...inte
 	f(classification, format, v...)
 }
 
+// LoggerProvider provides the Logger of a scope, e.g. of a client.
+type LoggerProvider interface {
+	Logger(scope string) Logger
+}
+
+// LoggerProviderFunc is a wrapper around a function to satisfy the
+// LoggerProvider interface.
+type LoggerProviderFunc func(scope string) Logger
+
+// Logger delegates to the wrapped function.
+func (f LoggerProviderFunc) Logger(scope string) Logger {
+	return f(scope)
+}
+
 // ContextLogger is an optional interface a Logger implementation may expose that provides
 // the ability to create context aware log entries.
 type ContextLogger interface {
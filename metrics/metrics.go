@@ -0,0 +1,78 @@
+// Package metrics defines the metrics APIs used by Smithy clients, which are
+// implemented by a MeterProvider of the application, e.g. an adapter to
+// OpenTelemetry.
+package metrics
+
+import (
+	"context"
+
+	"github.com/aws/smithy-go"
+)
+
+// MeterProvider is the entry point for creating a Meter.
+type MeterProvider interface {
+	Meter(scope string, opts ...MeterOption) Meter
+}
+
+// MeterOption applies configuration to a Meter.
+type MeterOption func(o *MeterOptions)
+
+// MeterOptions represents configuration for a Meter.
+type MeterOptions struct {
+	Properties smithy.Properties
+}
+
+// Meter is the entry point for creation of measurement instruments.
+type Meter interface {
+	Int64Counter(name string, opts ...InstrumentOption) (Int64Counter, error)
+	Float64Histogram(name string, opts ...InstrumentOption) (Float64Histogram, error)
+}
+
+// InstrumentOption applies configuration to an instrument.
+type InstrumentOption func(o *InstrumentOptions)
+
+// InstrumentOptions represents configuration for an instrument.
+type InstrumentOptions struct {
+	UnitLabel   string
+	Description string
+}
+
+// WithUnit sets the unit label of an instrument, e.g. "s".
+func WithUnit(unit string) InstrumentOption {
+	return func(o *InstrumentOptions) {
+		o.UnitLabel = unit
+	}
+}
+
+// WithDescription sets the description of an instrument.
+func WithDescription(description string) InstrumentOption {
+	return func(o *InstrumentOptions) {
+		o.Description = description
+	}
+}
+
+// Int64Counter measures a monotonically increasing int64 value.
+type Int64Counter interface {
+	Add(context.Context, int64, ...RecordMetricOption)
+}
+
+// Float64Histogram records a distribution of float64 values.
+type Float64Histogram interface {
+	Record(context.Context, float64, ...RecordMetricOption)
+}
+
+// RecordMetricOption applies configuration to a recorded metric.
+type RecordMetricOption func(o *RecordMetricOptions)
+
+// RecordMetricOptions represents configuration for a recorded metric.
+type RecordMetricOptions struct {
+	Properties smithy.Properties
+}
+
+// WithProperties sets the properties of a recorded metric, e.g. the
+// attributes of an OpenTelemetry measurement.
+func WithProperties(props smithy.Properties) RecordMetricOption {
+	return func(o *RecordMetricOptions) {
+		o.Properties.SetAll(&props)
+	}
+}
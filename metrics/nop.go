@@ -0,0 +1,28 @@
+package metrics
+
+import "context"
+
+// NopMeterProvider is a no-op metrics implementation.
+type NopMeterProvider struct{}
+
+var _ MeterProvider = (*NopMeterProvider)(nil)
+
+// Meter returns a meter which creates no-op instruments.
+func (NopMeterProvider) Meter(string, ...MeterOption) Meter {
+	return nopMeter{}
+}
+
+type nopMeter struct{}
+
+func (nopMeter) Int64Counter(string, ...InstrumentOption) (Int64Counter, error) {
+	return nopInstrument{}, nil
+}
+
+func (nopMeter) Float64Histogram(string, ...InstrumentOption) (Float64Histogram, error) {
+	return nopInstrument{}, nil
+}
+
+type nopInstrument struct{}
+
+func (nopInstrument) Add(context.Context, int64, ...RecordMetricOption)      {}
+func (nopInstrument) Record(context.Context, float64, ...RecordMetricOption) {}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+)
+
+// Attempt describes the attempt of an operation invocation that is in
+// progress. It is set by the retry middleware of the Finalize step before
+// each attempt, and can be read by the middleware that follow it, e.g. to add
+// a header of the attempt, or to adjust the timeout of each attempt.
+type Attempt struct {
+	// The number of the attempt, starting at 1.
+	Number int
+
+	// The maximum number of attempts of the invocation, or 0 if the number of
+	// attempts is not bounded.
+	MaxAttempts int
+}
+
+// IsRetry returns whether the attempt is a retry of an earlier attempt.
+func (a Attempt) IsRetry() bool {
+	return a.Number > 1
+}
+
+type attemptKey struct{}
+
+// WithAttempt returns a context with the attempt of the invocation set as a
+// stack value, replacing the attempt set by an earlier call, if any.
+func WithAttempt(ctx context.Context, attempt Attempt) context.Context {
+	return WithStackValue(ctx, attemptKey{}, attempt)
+}
+
+// GetAttempt returns the attempt of the invocation set with WithAttempt, if
+// any.
+func GetAttempt(ctx context.Context) (Attempt, bool) {
+	v, ok := GetStackValue(ctx, attemptKey{}).(Attempt)
+	return v, ok
+}
+
+// GetAttemptNumber returns the number of the attempt of the invocation,
+// starting at 1, or 0 if no attempt is set.
+func GetAttemptNumber(ctx context.Context) int {
+	v, _ := GetAttempt(ctx)
+	return v.Number
+}
+
+// GetMaxAttempts returns the maximum number of attempts of the invocation, or
+// 0 if no attempt is set or the number of attempts is not bounded.
+func GetMaxAttempts(ctx context.Context) int {
+	v, _ := GetAttempt(ctx)
+	return v.MaxAttempts
+}
+
+// IsRetry returns whether the attempt of the invocation is a retry of an
+// earlier attempt. Returns false if no attempt is set.
+func IsRetry(ctx context.Context) bool {
+	v, _ := GetAttempt(ctx)
+	return v.IsRetry()
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAttempt(t *testing.T) {
+	cases := map[string]struct {
+		ctx         context.Context
+		expectOK    bool
+		expectNum   int
+		expectMax   int
+		expectRetry bool
+	}{
+		"not set": {
+			ctx: context.Background(),
+		},
+		"first attempt": {
+			ctx:       WithAttempt(context.Background(), Attempt{Number: 1, MaxAttempts: 3}),
+			expectOK:  true,
+			expectNum: 1,
+			expectMax: 3,
+		},
+		"retry": {
+			ctx: WithAttempt(
+				WithAttempt(context.Background(), Attempt{Number: 1, MaxAttempts: 3}),
+				Attempt{Number: 2, MaxAttempts: 3},
+			),
+			expectOK:    true,
+			expectNum:   2,
+			expectMax:   3,
+			expectRetry: true,
+		},
+		"unbounded": {
+			ctx:         WithAttempt(context.Background(), Attempt{Number: 5}),
+			expectOK:    true,
+			expectNum:   5,
+			expectRetry: true,
+		},
+		"cleared stack values": {
+			ctx: ClearStackValues(WithAttempt(context.Background(), Attempt{Number: 2})),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			attempt, ok := GetAttempt(c.ctx)
+			if e, a := c.expectOK, ok; e != a {
+				t.Errorf("expect ok %v, got %v", e, a)
+			}
+			if e, a := c.expectNum, attempt.Number; e != a {
+				t.Errorf("expect attempt %v, got %v", e, a)
+			}
+			if e, a := c.expectNum, GetAttemptNumber(c.ctx); e != a {
+				t.Errorf("expect attempt number %v, got %v", e, a)
+			}
+			if e, a := c.expectMax, GetMaxAttempts(c.ctx); e != a {
+				t.Errorf("expect max attempts %v, got %v", e, a)
+			}
+			if e, a := c.expectRetry, IsRetry(c.ctx); e != a {
+				t.Errorf("expect retry %v, got %v", e, a)
+			}
+		})
+	}
+}
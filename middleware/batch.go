@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultBatchConcurrency is the default number of inputs of a batch
+// invoked concurrently.
+const DefaultBatchConcurrency = 8
+
+// BatchOptions are the options of InvokeBatch.
+type BatchOptions struct {
+	// The maximum number of inputs invoked concurrently. Defaults to
+	// DefaultBatchConcurrency if zero or less.
+	Concurrency int
+
+	// Stop invoking inputs once an invocation fails. Inputs that have not
+	// been invoked fail with a *BatchCanceledError, and the context of the
+	// invocations in flight is canceled.
+	StopOnError bool
+}
+
+// BatchResult is the result of the invocation of an input of a batch.
+type BatchResult struct {
+	// The output and metadata of the invocation.
+	Output   interface{}
+	Metadata Metadata
+
+	// The error of the invocation, if any.
+	Err error
+}
+
+// BatchItemError is the error of the invocation of an input of a batch.
+type BatchItemError struct {
+	// The index of the input in the batch.
+	Index int
+
+	Err error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("batch input %d, %v", e.Index, e.Err)
+}
+
+// Unwrap returns the error of the invocation.
+func (e *BatchItemError) Unwrap() error { return e.Err }
+
+// BatchError is returned by InvokeBatch if the invocation of any input of the
+// batch fails, with the errors of each, in the order of the inputs.
+type BatchError struct {
+	Errs []*BatchItemError
+}
+
+func (e *BatchError) Error() string {
+	errs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		errs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d of batch failed: %s", len(e.Errs), strings.Join(errs, "; "))
+}
+
+// Unwrap returns the errors of the invocations that failed, such that
+// errors.Is and errors.As match any of them.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Errs))
+	for i, err := range e.Errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+// BatchCanceledError is the error of an input of a batch that was not
+// invoked, as the context of the batch was canceled, or an invocation failed
+// with BatchOptions.StopOnError.
+type BatchCanceledError struct {
+	Err error
+}
+
+func (e *BatchCanceledError) Error() string {
+	return fmt.Sprintf("batch input not invoked, %v", e.Err)
+}
+
+// Unwrap returns the cause of the cancellation.
+func (e *BatchCanceledError) Unwrap() error { return e.Err }
+
+// InvokeBatch invokes the handler with each input, with at most
+// BatchOptions.Concurrency inputs in flight, e.g. with a stack decorating a
+// handler with DecorateHandler. Returns the result of each input, in the order
+// of the inputs, and a *BatchError if any invocation failed.
+//
+// The handler must be safe for concurrent use.
+func InvokeBatch(ctx context.Context, h Handler, inputs []interface{}, optFns ...func(*BatchOptions)) (
+	[]BatchResult, error,
+) {
+	var options BatchOptions
+	for _, fn := range optFns {
+		fn(&options)
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = DefaultBatchConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		results = make([]BatchResult, len(inputs))
+		sem     = make(chan struct{}, options.Concurrency)
+		wg      sync.WaitGroup
+
+		mu        sync.Mutex
+		stopCause error
+	)
+	stopped := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		if stopCause != nil {
+			return stopCause
+		}
+		return ctx.Err()
+	}
+	acquire := func() error {
+		select {
+		case sem <- struct{}{}:
+			if err := stopped(); err != nil {
+				<-sem
+				return err
+			}
+			return nil
+		case <-ctx.Done():
+			return stopped()
+		}
+	}
+
+	for i, input := range inputs {
+		if err := acquire(); err != nil {
+			results[i].Err = &BatchCanceledError{Err: err}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, input interface{}) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			out, metadata, err := h.Handle(ctx, input)
+			results[i] = BatchResult{Output: out, Metadata: metadata, Err: err}
+			if err != nil && options.StopOnError {
+				mu.Lock()
+				if stopCause == nil {
+					stopCause = &BatchItemError{Index: i, Err: err}
+				}
+				mu.Unlock()
+				cancel()
+			}
+		}(i, input)
+	}
+	wg.Wait()
+
+	var berr BatchError
+	for i, r := range results {
+		if r.Err != nil {
+			berr.Errs = append(berr.Errs, &BatchItemError{Index: i, Err: r.Err})
+		}
+	}
+	if len(berr.Errs) != 0 {
+		return results, &berr
+	}
+	return results, nil
+}
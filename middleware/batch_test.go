@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestInvokeBatch(t *testing.T) {
+	failErr := errors.New("input failed")
+
+	cases := map[string]struct {
+		inputs        []interface{}
+		options       BatchOptions
+		expectOutputs []interface{}
+		expectErrs    []int
+		expectInvoked int
+	}{
+		"success": {
+			inputs:        []interface{}{1, 2, 3, 4, 5},
+			options:       BatchOptions{Concurrency: 2},
+			expectOutputs: []interface{}{2, 4, 6, 8, 10},
+			expectInvoked: 5,
+		},
+		"errors": {
+			inputs:        []interface{}{1, -1, 3, -1},
+			expectOutputs: []interface{}{2, nil, 6, nil},
+			expectErrs:    []int{1, 3},
+			expectInvoked: 4,
+		},
+		"stop on error": {
+			inputs:        []interface{}{1, -1, 3, 4},
+			options:       BatchOptions{Concurrency: 1, StopOnError: true},
+			expectOutputs: []interface{}{2, nil, nil, nil},
+			expectErrs:    []int{1, 2, 3},
+			expectInvoked: 2,
+		},
+		"no inputs": {
+			expectOutputs: []interface{}{},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			var invoked, inflight, maxInflight int
+			h := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+				mu.Lock()
+				invoked++
+				inflight++
+				if inflight > maxInflight {
+					maxInflight = inflight
+				}
+				mu.Unlock()
+				defer func() {
+					mu.Lock()
+					inflight--
+					mu.Unlock()
+				}()
+
+				if input.(int) < 0 {
+					return nil, Metadata{}, failErr
+				}
+				var metadata Metadata
+				metadata.Set("input", input)
+				return input.(int) * 2, metadata, nil
+			})
+
+			results, err := InvokeBatch(context.Background(), h, c.inputs, func(o *BatchOptions) {
+				*o = c.options
+			})
+
+			outputs := make([]interface{}, len(results))
+			for i, r := range results {
+				outputs[i] = r.Output
+				if r.Err == nil {
+					if e, a := c.inputs[i], r.Metadata.Get("input"); e != a {
+						t.Errorf("expect %v metadata, got %v", e, a)
+					}
+				}
+			}
+			if e, a := c.expectOutputs, outputs; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v outputs, got %v", e, a)
+			}
+			if e, a := c.expectInvoked, invoked; e != a {
+				t.Errorf("expect %v invoked, got %v", e, a)
+			}
+			if c.options.Concurrency != 0 && maxInflight > c.options.Concurrency {
+				t.Errorf("expect at most %v in flight, got %v", c.options.Concurrency, maxInflight)
+			}
+
+			if len(c.expectErrs) == 0 {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				return
+			}
+			var berr *BatchError
+			if !errors.As(err, &berr) {
+				t.Fatalf("expect batch error, got %v", err)
+			}
+			var indices []int
+			for _, ierr := range berr.Errs {
+				indices = append(indices, ierr.Index)
+			}
+			if e, a := c.expectErrs, indices; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v errors, got %v", e, a)
+			}
+			if !errors.Is(err, failErr) {
+				t.Errorf("expect %v error, got %v", failErr, err)
+			}
+		})
+	}
+}
+
+func TestInvokeBatch_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	h := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		t.Errorf("expect handler not to be invoked")
+		return nil, Metadata{}, nil
+	})
+	results, err := InvokeBatch(ctx, h, []interface{}{1, 2})
+	if err == nil {
+		t.Fatalf("expect error")
+	}
+	for i, r := range results {
+		var cerr *BatchCanceledError
+		if !errors.As(r.Err, &cerr) {
+			t.Errorf("expect input %d canceled, got %v", i, r.Err)
+		}
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expect %v error, got %v", context.Canceled, err)
+	}
+}
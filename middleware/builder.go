@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Plugin contributes a group of middleware to the stacks assembled by a
+// StackBuilder, e.g. the middleware of a protocol, or of an SDK
+// customization.
+type Plugin interface {
+	// Middleware returns the group of middleware the plugin contributes,
+	// whose name must be unique to the plugins of a stack.
+	Middleware() *MiddlewareGroup
+}
+
+// StackBuilder assembles stacks from the middleware of plugins, see
+// NewStackBuilder.
+type StackBuilder struct {
+	id         string
+	newRequest func() interface{}
+	plugins    []Plugin
+}
+
+// NewStackBuilder returns a builder of stacks with the ID and request
+// constructor, see NewStack.
+func NewStackBuilder(id string, newRequestFn func() interface{}) *StackBuilder {
+	return &StackBuilder{id: id, newRequest: newRequestFn}
+}
+
+// Add adds the plugins to the builder, whose middleware are added to each
+// stack built, in the order the plugins are added.
+func (b *StackBuilder) Add(plugins ...Plugin) *StackBuilder {
+	b.plugins = append(b.plugins, plugins...)
+	return b
+}
+
+// Build returns a new stack with the middleware of the builder's plugins,
+// each added to the stack as a group, see Stack.AddGroup.
+//
+// Middleware are added in the order of the plugins, and of the middleware of
+// each plugin, except that a middleware placed relative to a middleware of
+// another plugin is added once that middleware is, such that plugins do not
+// need to be added in the order they depend on each other. Returns an error
+// if a middleware cannot be added, its placement depends on itself, or the
+// order constraints of the stack are not satisfied, see Stack.Validate.
+func (b *StackBuilder) Build() (*Stack, error) {
+	s := NewStack(b.id, b.newRequest)
+
+	groups := make([]*MiddlewareGroup, 0, len(b.plugins))
+	names := map[string]bool{}
+	for _, p := range b.plugins {
+		g := p.Middleware()
+		if len(g.name) == 0 {
+			return nil, fmt.Errorf("build stack %s, middleware group of plugin %T must have a name", b.id, p)
+		}
+		if names[g.name] {
+			return nil, fmt.Errorf("build stack %s, duplicate middleware group %s", b.id, g.name)
+		}
+		names[g.name] = true
+		groups = append(groups, g)
+	}
+
+	if err := addPluginMembers(s, groups); err != nil {
+		return nil, fmt.Errorf("build stack %s, %w", b.id, err)
+	}
+
+	s.groups = make(map[string]*MiddlewareGroup, len(groups))
+	for _, g := range groups {
+		s.groups[g.name] = g
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("build stack %s, %w", b.id, err)
+	}
+	return s, nil
+}
+
+// addPluginMembers adds the members of the groups to the stack, each once
+// the member it is placed relative to is added, if it is a member of the
+// groups.
+func addPluginMembers(s *Stack, groups []*MiddlewareGroup) error {
+	type pending struct {
+		group  string
+		member groupMember
+	}
+
+	var members []pending
+	provided := map[MiddlewareRef]bool{}
+	for _, g := range groups {
+		for _, m := range g.members {
+			members = append(members, pending{group: g.name, member: m})
+			provided[MiddlewareRef{Step: m.step, ID: m.middleware.ID()}] = true
+		}
+	}
+
+	added := map[MiddlewareRef]bool{}
+	for len(members) != 0 {
+		progressed := false
+		for i := 0; i < len(members); i++ {
+			m := members[i].member
+			anchor := MiddlewareRef{Step: m.step, ID: m.relativeTo}
+			if len(m.relativeTo) != 0 && provided[anchor] && !added[anchor] {
+				continue
+			}
+
+			ids, err := stepIDs(s, m.step)
+			if err != nil {
+				return err
+			}
+			id := m.middleware.ID()
+			if len(m.relativeTo) == 0 {
+				err = ids.Add(m.middleware, m.pos)
+			} else {
+				err = ids.Insert(m.middleware, m.relativeTo, m.pos)
+			}
+			if err != nil {
+				return fmt.Errorf("add %s of group %s to %s step, %w", id, members[i].group, m.step, err)
+			}
+
+			added[MiddlewareRef{Step: m.step, ID: id}] = true
+			members = append(members[:i], members[i+1:]...)
+			progressed = true
+
+			// members earlier in order may now be placed
+			break
+		}
+
+		if !progressed {
+			cycle := make([]string, 0, len(members))
+			for _, m := range members {
+				cycle = append(cycle, m.member.step+"/"+m.member.middleware.ID())
+			}
+			return fmt.Errorf("middleware placed relative to each other, %s", strings.Join(cycle, ", "))
+		}
+	}
+	return nil
+}
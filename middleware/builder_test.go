@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type mockPlugin struct {
+	group *MiddlewareGroup
+}
+
+func (p mockPlugin) Middleware() *MiddlewareGroup { return p.group }
+
+func TestStackBuilder(t *testing.T) {
+	cases := map[string]struct {
+		plugins         []Plugin
+		expectErr       bool
+		expectViolation bool
+		expectBuild     []string
+		expectFinalize  []string
+		expectGroups    []string
+	}{
+		"plugins in order": {
+			plugins: []Plugin{
+				mockPlugin{NewMiddlewareGroup("protocol").
+					Build(mockBuildMiddleware("ContentLength"), "", After)},
+				mockPlugin{NewMiddlewareGroup("checksum").
+					Build(mockBuildMiddleware("Checksum"), "ContentLength", Before)},
+			},
+			expectBuild:  []string{"Checksum", "ContentLength"},
+			expectGroups: []string{"checksum", "protocol"},
+		},
+		"plugin before its dependency": {
+			plugins: []Plugin{
+				mockPlugin{NewMiddlewareGroup("checksum").
+					Build(mockBuildMiddleware("Checksum"), "ContentLength", Before).
+					Finalize(mockFinalizeMiddleware("Retry"), "", After)},
+				mockPlugin{NewMiddlewareGroup("protocol").
+					Build(mockBuildMiddleware("ContentLength"), "", After).
+					Build(mockBuildMiddleware("UserAgent"), "", After)},
+			},
+			expectBuild:    []string{"Checksum", "ContentLength", "UserAgent"},
+			expectFinalize: []string{"Retry"},
+			expectGroups:   []string{"checksum", "protocol"},
+		},
+		"cycle": {
+			plugins: []Plugin{
+				mockPlugin{NewMiddlewareGroup("a").
+					Build(mockBuildMiddleware("A"), "B", Before)},
+				mockPlugin{NewMiddlewareGroup("b").
+					Build(mockBuildMiddleware("B"), "A", Before)},
+			},
+			expectErr: true,
+		},
+		"relative to unknown": {
+			plugins: []Plugin{
+				mockPlugin{NewMiddlewareGroup("a").
+					Build(mockBuildMiddleware("A"), "Unknown", Before)},
+			},
+			expectErr: true,
+		},
+		"duplicate group": {
+			plugins: []Plugin{
+				mockPlugin{NewMiddlewareGroup("a")},
+				mockPlugin{NewMiddlewareGroup("a")},
+			},
+			expectErr: true,
+		},
+		"order constraint violated": {
+			plugins: []Plugin{
+				mockPlugin{NewMiddlewareGroup("signing").
+					Finalize(&mockConstrainedMiddleware{
+						id:          "Signing",
+						constraints: OrderConstraints{After: []string{"Retry"}},
+					}, "", After)},
+				mockPlugin{NewMiddlewareGroup("retry").
+					Finalize(mockFinalizeMiddleware("Retry"), "", After)},
+			},
+			expectErr:       true,
+			expectViolation: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewStackBuilder("fooStack", func() interface{} { return struct{}{} }).
+				Add(c.plugins...).
+				Build()
+			if c.expectErr {
+				if err == nil {
+					t.Fatalf("expect error")
+				}
+				var verr *OrderViolationError
+				if e, a := c.expectViolation, errors.As(err, &verr); e != a {
+					t.Errorf("expect violation %v, got %v", e, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := "fooStack", s.ID(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+			if e, a := c.expectBuild, s.Build.List(); !reflect.DeepEqual(e, a) {
+				t.Errorf("expect build %v, got %v", e, a)
+			}
+			if e, a := len(c.expectFinalize), len(s.Finalize.List()); e != a {
+				t.Errorf("expect finalize %v, got %v", c.expectFinalize, s.Finalize.List())
+			}
+			if e, a := c.expectGroups, s.Groups(); !reflect.DeepEqual(e, a) {
+				t.Errorf("expect groups %v, got %v", e, a)
+			}
+
+			// the groups can be removed as a unit
+			for _, g := range c.expectGroups {
+				if err := s.RemoveGroup(g); err != nil {
+					t.Errorf("expect no error, got %v", err)
+				}
+			}
+			if n := len(s.Build.List()) + len(s.Finalize.List()); n != 0 {
+				t.Errorf("expect empty stack, got %v", s.List())
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import "strings"
+
+// Clone returns a copy of the stack, whose steps can be modified without
+// modifying the stack. The middleware of the steps are not copied, such that
+// both stacks invoke the same middleware values.
+func (s *Stack) Clone() *Stack {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c := &Stack{
+		id:          s.id,
+		Initialize:  &InitializeStep{ids: s.Initialize.ids.clone()},
+		Serialize:   &SerializeStep{ids: s.Serialize.ids.clone(), newRequest: s.Serialize.newRequest},
+		Build:       &BuildStep{ids: s.Build.ids.clone()},
+		Finalize:    &FinalizeStep{ids: s.Finalize.ids.clone()},
+		Deserialize: &DeserializeStep{ids: s.Deserialize.ids.clone()},
+	}
+	if s.groups != nil {
+		c.groups = make(map[string]*MiddlewareGroup, len(s.groups))
+		for k, v := range s.groups {
+			c.groups[k] = v
+		}
+	}
+	if s.hooks != nil {
+		c.hooks = append([]StackHooks(nil), s.hooks...)
+	}
+	return c
+}
+
+// StackDiff is the difference between the middleware of two stacks, see
+// Diff.
+type StackDiff struct {
+	// The steps whose middleware differ, in stack order.
+	Steps []StepDiff
+}
+
+// StepDiff is the difference between the middleware of a step of two stacks.
+type StepDiff struct {
+	// The ID of the step, e.g. "Build stack step".
+	Step string
+
+	// The IDs of the middleware only in the second stack's step, in its
+	// order.
+	Added []string
+
+	// The IDs of the middleware only in the first stack's step, in its
+	// order.
+	Removed []string
+
+	// The IDs of the middleware in both steps whose order relative to the
+	// other middleware in both differs, in the second stack's order. The
+	// fewest middleware that account for the change in order are reported as
+	// moved.
+	Moved []string
+}
+
+// Diff returns the middleware added, removed, or moved in each step of stack
+// b relative to stack a, e.g. to assert that a plugin modified a stack as
+// intended. Middleware are compared by ID.
+func Diff(a, b *Stack) *StackDiff {
+	d := &StackDiff{}
+	d.diffStep(a.Initialize.ID(), a.Initialize.List(), b.Initialize.List())
+	d.diffStep(a.Serialize.ID(), a.Serialize.List(), b.Serialize.List())
+	d.diffStep(a.Build.ID(), a.Build.List(), b.Build.List())
+	d.diffStep(a.Finalize.ID(), a.Finalize.List(), b.Finalize.List())
+	d.diffStep(a.Deserialize.ID(), a.Deserialize.List(), b.Deserialize.List())
+	return d
+}
+
+// Empty returns whether the stacks have the same middleware in the same
+// order.
+func (d *StackDiff) Empty() bool {
+	return len(d.Steps) == 0
+}
+
+// String returns the difference with a line per step, e.g.
+//
+//	Build stack step: +ComputeChecksum -UserAgent ~Signing
+//
+// where added middleware are prefixed with "+", removed with "-", and moved
+// with "~".
+func (d *StackDiff) String() string {
+	var b strings.Builder
+	for _, s := range d.Steps {
+		b.WriteString(s.Step + ":")
+		for _, id := range s.Added {
+			b.WriteString(" +" + id)
+		}
+		for _, id := range s.Removed {
+			b.WriteString(" -" + id)
+		}
+		for _, id := range s.Moved {
+			b.WriteString(" ~" + id)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (d *StackDiff) diffStep(step string, a, b []string) {
+	inA := make(map[string]int, len(a))
+	for i, id := range a {
+		inA[id] = i
+	}
+	inB := make(map[string]bool, len(b))
+	for _, id := range b {
+		inB[id] = true
+	}
+
+	s := StepDiff{Step: step}
+	for _, id := range a {
+		if !inB[id] {
+			s.Removed = append(s.Removed, id)
+		}
+	}
+
+	// the positions in a of the middleware in both, in b's order
+	var common []string
+	var positions []int
+	for _, id := range b {
+		i, ok := inA[id]
+		if !ok {
+			s.Added = append(s.Added, id)
+			continue
+		}
+		common = append(common, id)
+		positions = append(positions, i)
+	}
+
+	// the middleware not in the longest run of unchanged relative order are
+	// moved
+	unmoved := longestIncreasing(positions)
+	for i, id := range common {
+		if !unmoved[i] {
+			s.Moved = append(s.Moved, id)
+		}
+	}
+
+	if len(s.Added) != 0 || len(s.Removed) != 0 || len(s.Moved) != 0 {
+		d.Steps = append(d.Steps, s)
+	}
+}
+
+// longestIncreasing returns the indices of a longest increasing subsequence
+// of the values.
+func longestIncreasing(values []int) map[int]bool {
+	// length of, and previous index in, the longest subsequence ending at i
+	length := make([]int, len(values))
+	prev := make([]int, len(values))
+
+	end := -1
+	for i := range values {
+		length[i], prev[i] = 1, -1
+		for j := 0; j < i; j++ {
+			if values[j] < values[i] && length[j]+1 > length[i] {
+				length[i], prev[i] = length[j]+1, j
+			}
+		}
+		if end == -1 || length[i] > length[end] {
+			end = i
+		}
+	}
+
+	indices := map[int]bool{}
+	for i := end; i != -1; i = prev[i] {
+		indices[i] = true
+	}
+	return indices
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStackClone(t *testing.T) {
+	s := newGroupTestStack(t)
+	noError(t, s.AddGroup(NewMiddlewareGroup("group").Build(mockBuildMiddleware("b"), "", After)))
+
+	c := s.Clone()
+	if e, a := s.List(), c.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if e, a := s.Describe(), c.Describe(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if e, a := s.Groups(), c.Groups(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	// modifying the clone does not modify the stack
+	list := s.List()
+	noError(t, c.Build.Add(mockBuildMiddleware("added"), After))
+	if _, err := c.Finalize.Remove("retry"); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	noError(t, c.RemoveGroup("group"))
+	if e, a := list, s.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if e, a := []string{"group"}, s.Groups(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	if e, a := s.Serialize.newRequest(), c.Serialize.newRequest(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := NewStack("fooStack", func() interface{} { return struct{}{} })
+	for _, id := range []string{"a", "b", "c", "d"} {
+		noError(t, a.Build.Add(mockBuildMiddleware(id), After))
+	}
+	noError(t, a.Finalize.Add(mockFinalizeMiddleware("retry"), After))
+
+	if d := Diff(a, a.Clone()); !d.Empty() {
+		t.Errorf("expect no difference, got %v", d)
+	}
+
+	b := a.Clone()
+	if _, err := b.Build.Remove("a"); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if _, err := b.Build.Remove("d"); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	noError(t, b.Build.Insert(mockBuildMiddleware("d"), "b", Before))
+	noError(t, b.Build.Add(mockBuildMiddleware("e"), After))
+	noError(t, b.Initialize.Add(mockInitializeMiddleware("f"), After))
+
+	d := Diff(a, b)
+	expect := &StackDiff{Steps: []StepDiff{
+		{Step: (*InitializeStep)(nil).ID(), Added: []string{"f"}},
+		{Step: (*BuildStep)(nil).ID(), Added: []string{"e"}, Removed: []string{"a"}, Moved: []string{"d"}},
+	}}
+	if !reflect.DeepEqual(expect, d) {
+		t.Errorf("expect %#v, got %#v", expect, d)
+	}
+	if d.Empty() {
+		t.Errorf("expect difference")
+	}
+
+	expectString := "Initialize stack step: +f\nBuild stack step: +e -a ~d\n"
+	if e, a := expectString, d.String(); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+}
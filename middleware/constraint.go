@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OrderConstraints are constraints on the order of a middleware relative to
+// other middleware of a stack, by ID, checked by Stack.Validate. The
+// middleware of a stack are ordered by step, and in order within each step,
+// e.g. a Finalize middleware runs after every Build middleware.
+type OrderConstraints struct {
+	// The IDs of middleware that must run before the middleware, e.g.
+	// "ResolveEndpoint".
+	After []string
+
+	// The IDs of middleware that must run after the middleware, e.g.
+	// "Signing".
+	Before []string
+
+	// The middleware of After and Before must be in the stack. By default a
+	// constraint on a middleware not in the stack is satisfied.
+	Required bool
+}
+
+// OrderConstrainer is implemented by middleware that constrain their order
+// relative to other middleware of a stack, see Stack.Validate.
+type OrderConstrainer interface {
+	OrderConstraints() OrderConstraints
+}
+
+// OrderViolation is a middleware whose order constraint is not satisfied.
+type OrderViolation struct {
+	// The step and ID of the middleware with the constraint.
+	Step string
+	ID   string
+
+	// The ID of the middleware the constraint is relative to.
+	Other string
+
+	// The constraint that is not satisfied, "after" if Other must run before
+	// the middleware, "before" if Other must run after it, or "required" if
+	// Other is not in the stack.
+	Constraint string
+}
+
+func (v OrderViolation) String() string {
+	if v.Constraint == "required" {
+		return fmt.Sprintf("%s requires %s, which is not in the stack", v.ID, v.Other)
+	}
+	return fmt.Sprintf("%s must run %s %s", v.ID, v.Constraint, v.Other)
+}
+
+// OrderViolationError is returned by Stack.Validate with the order
+// constraints that are not satisfied.
+type OrderViolationError struct {
+	Violations []OrderViolation
+}
+
+func (e *OrderViolationError) Error() string {
+	vs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		vs[i] = v.String()
+	}
+	return fmt.Sprintf("middleware order constraints not satisfied: %s", strings.Join(vs, "; "))
+}
+
+// Validate checks the order constraints of the stack's middleware that
+// implement OrderConstrainer, e.g. when a client is constructed rather than
+// when a request is sent. Returns an *OrderViolationError with each
+// constraint that is not satisfied, in stack order.
+//
+// A constraint relative to an ID that occurs in more than one step must be
+// satisfied by each occurrence.
+func (s *Stack) Validate() error {
+	type entry struct {
+		step string
+		m    interface{}
+	}
+
+	var order []entry
+	positions := map[string][]int{}
+	for _, step := range []struct {
+		id  string
+		ids *orderedIDs
+	}{
+		{s.Initialize.ID(), s.Initialize.ids},
+		{s.Serialize.ID(), s.Serialize.ids},
+		{s.Build.ID(), s.Build.ids},
+		{s.Finalize.ID(), s.Finalize.ids},
+		{s.Deserialize.ID(), s.Deserialize.ids},
+	} {
+		for _, m := range step.ids.GetOrder() {
+			id := m.(ider).ID()
+			positions[id] = append(positions[id], len(order))
+			order = append(order, entry{step: step.id, m: m})
+		}
+	}
+
+	var violations []OrderViolation
+	for i, e := range order {
+		c, ok := e.m.(OrderConstrainer)
+		if !ok {
+			continue
+		}
+		constraints := c.OrderConstraints()
+		id := e.m.(ider).ID()
+
+		check := func(others []string, constraint string, satisfied func(j int) bool) {
+			for _, other := range others {
+				ps := positions[other]
+				if len(ps) == 0 && constraints.Required {
+					violations = append(violations, OrderViolation{Step: e.step, ID: id, Other: other, Constraint: "required"})
+				}
+				for _, j := range ps {
+					if !satisfied(j) {
+						violations = append(violations, OrderViolation{Step: e.step, ID: id, Other: other, Constraint: constraint})
+						break
+					}
+				}
+			}
+		}
+		check(constraints.After, "after", func(j int) bool { return j < i })
+		check(constraints.Before, "before", func(j int) bool { return j > i })
+	}
+
+	if len(violations) != 0 {
+		return &OrderViolationError{Violations: violations}
+	}
+	return nil
+}
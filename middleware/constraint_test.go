@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type mockConstrainedMiddleware struct {
+	id          string
+	constraints OrderConstraints
+}
+
+func (m *mockConstrainedMiddleware) ID() string { return m.id }
+
+func (m *mockConstrainedMiddleware) OrderConstraints() OrderConstraints { return m.constraints }
+
+func (*mockConstrainedMiddleware) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	return next.HandleFinalize(ctx, in)
+}
+
+func TestStackValidate(t *testing.T) {
+	auth := func(required bool) *mockConstrainedMiddleware {
+		return &mockConstrainedMiddleware{id: "auth", constraints: OrderConstraints{
+			After:    []string{"ResolveEndpoint", "serialize"},
+			Before:   []string{"Signing"},
+			Required: required,
+		}}
+	}
+
+	cases := map[string]struct {
+		finalize []FinalizeMiddleware
+		expect   []OrderViolation
+	}{
+		"satisfied": {
+			finalize: []FinalizeMiddleware{
+				mockFinalizeMiddleware("ResolveEndpoint"),
+				auth(true),
+				mockFinalizeMiddleware("Signing"),
+			},
+		},
+		"not required and missing": {
+			finalize: []FinalizeMiddleware{auth(false)},
+		},
+		"violated": {
+			finalize: []FinalizeMiddleware{
+				mockFinalizeMiddleware("Signing"),
+				auth(false),
+				mockFinalizeMiddleware("ResolveEndpoint"),
+			},
+			expect: []OrderViolation{
+				{Step: (*FinalizeStep)(nil).ID(), ID: "auth", Other: "ResolveEndpoint", Constraint: "after"},
+				{Step: (*FinalizeStep)(nil).ID(), ID: "auth", Other: "Signing", Constraint: "before"},
+			},
+		},
+		"required and missing": {
+			finalize: []FinalizeMiddleware{
+				auth(true),
+				mockFinalizeMiddleware("Signing"),
+			},
+			expect: []OrderViolation{
+				{Step: (*FinalizeStep)(nil).ID(), ID: "auth", Other: "ResolveEndpoint", Constraint: "required"},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			// a middleware of an earlier step runs before every Finalize middleware
+			noError(t, s.Serialize.Add(mockSerializeMiddleware("serialize"), After))
+			for _, m := range c.finalize {
+				noError(t, s.Finalize.Add(m, After))
+			}
+
+			err := s.Validate()
+			if len(c.expect) == 0 {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				return
+			}
+
+			var verr *OrderViolationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expect order violation error, got %v", err)
+			}
+			if e, a := c.expect, verr.Violations; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestOrderViolationError(t *testing.T) {
+	err := &OrderViolationError{Violations: []OrderViolation{
+		{ID: "auth", Other: "ResolveEndpoint", Constraint: "after"},
+		{ID: "auth", Other: "Signing", Constraint: "required"},
+	}}
+	for _, e := range []string{
+		"auth must run after ResolveEndpoint",
+		"auth requires Signing, which is not in the stack",
+	} {
+		if a := err.Error(); !strings.Contains(a, e) {
+			t.Errorf("expect %q in %q", e, a)
+		}
+	}
+}
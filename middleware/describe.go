@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// StackDescription is a structured listing of the middleware of a Stack, in
+// the order they are invoked, for debugging the order of middleware, see
+// Stack.Describe.
+type StackDescription struct {
+	ID         string
+	Middleware []MiddlewareDescription
+}
+
+// MiddlewareDescription describes a single middleware of a stack, and how it
+// was placed in its step.
+type MiddlewareDescription struct {
+	// The ID of the step of the middleware, e.g. "Build stack step".
+	Step string
+
+	// The index of the middleware in its step, in invocation order.
+	Position int
+
+	// The ID of the middleware.
+	ID string
+
+	// The position the middleware was added or inserted at, relative to
+	// RelativeTo.
+	Placement RelativePosition
+
+	// The ID of the middleware the middleware was inserted relative to, or
+	// empty if it was added relative to the step, e.g. with Add.
+	RelativeTo string
+}
+
+// Describe returns a description of the stack's middleware, in the order they
+// are invoked, with the position of each in its step and how it was placed.
+//
+// The description shares no state with the stack, such that it may be
+// retained after the stack is modified.
+func (s *Stack) Describe() *StackDescription {
+	d := &StackDescription{ID: s.id}
+
+	d.describeStep(s.Initialize.ID(), s.Initialize.ids)
+	d.describeStep(s.Serialize.ID(), s.Serialize.ids)
+	d.describeStep(s.Build.ID(), s.Build.ids)
+	d.describeStep(s.Finalize.ID(), s.Finalize.ids)
+	d.describeStep(s.Deserialize.ID(), s.Deserialize.ids)
+
+	return d
+}
+
+func (d *StackDescription) describeStep(step string, ids *orderedIDs) {
+	list, placements := ids.listPlacements()
+	for i, id := range list {
+		p := placements[i]
+		d.Middleware = append(d.Middleware, MiddlewareDescription{
+			Step:       step,
+			Position:   i,
+			ID:         id,
+			Placement:  p.pos,
+			RelativeTo: p.relativeTo,
+		})
+	}
+}
+
+// String returns the description as a table of the step, position, ID, and
+// placement of each middleware, e.g.
+//
+//	STEP              POSITION  ID         PLACEMENT
+//	Build stack step  0         Checksum   Before UserAgent
+//	Build stack step  1         UserAgent  After step
+func (d *StackDescription) String() string {
+	var b strings.Builder
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "STEP\tPOSITION\tID\tPLACEMENT\n")
+	for _, m := range d.Middleware {
+		relativeTo := m.RelativeTo
+		if len(relativeTo) == 0 {
+			relativeTo = "step"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%v %s\n", m.Step, m.Position, m.ID, m.Placement, relativeTo)
+	}
+	w.Flush()
+
+	return b.String()
+}
+
+// DOT returns the description as a Graphviz DOT graph, with a cluster of
+// nodes for each step. Solid edges are the order middleware are invoked in,
+// and dashed edges are from a middleware to the middleware it was inserted
+// relative to.
+func (d *StackDescription) DOT() string {
+	var b strings.Builder
+	node := func(m MiddlewareDescription) string {
+		return strconv.Quote(m.Step + "/" + m.ID)
+	}
+
+	fmt.Fprintf(&b, "digraph %s {\n", strconv.Quote(d.ID))
+	b.WriteString("\trankdir=LR;\n")
+
+	for i := 0; i < len(d.Middleware); {
+		step := d.Middleware[i].Step
+		fmt.Fprintf(&b, "\tsubgraph %s {\n", strconv.Quote("cluster_"+step))
+		fmt.Fprintf(&b, "\t\tlabel=%s;\n", strconv.Quote(step))
+		for ; i < len(d.Middleware) && d.Middleware[i].Step == step; i++ {
+			m := d.Middleware[i]
+			fmt.Fprintf(&b, "\t\t%s [label=%s];\n", node(m), strconv.Quote(m.ID))
+		}
+		b.WriteString("\t}\n")
+	}
+
+	for i := 1; i < len(d.Middleware); i++ {
+		fmt.Fprintf(&b, "\t%s -> %s;\n", node(d.Middleware[i-1]), node(d.Middleware[i]))
+	}
+
+	for _, m := range d.Middleware {
+		if len(m.RelativeTo) == 0 {
+			continue
+		}
+		for _, r := range d.Middleware {
+			if r.Step == m.Step && r.ID == m.RelativeTo {
+				fmt.Fprintf(&b, "\t%s -> %s [style=dashed, label=%s];\n",
+					node(m), node(r), strconv.Quote(strings.ToLower(m.Placement.String())))
+				break
+			}
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStackDescribe(t *testing.T) {
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+
+	noError(t, s.Initialize.Add(mockInitializeMiddleware("first"), After))
+	noError(t, s.Build.Add(mockBuildMiddleware("second"), After))
+	noError(t, s.Build.Add(mockBuildMiddleware("third"), Before))
+	noError(t, s.Build.Insert(mockBuildMiddleware("fourth"), "second", Before))
+	noError(t, s.Build.Add(mockBuildMiddleware("removed"), After))
+	noError(t, s.Finalize.Add(mockFinalizeMiddleware("fifth"), After))
+	noError(t, s.Finalize.Insert(mockFinalizeMiddleware("sixth"), "fifth", After))
+	if _, err := s.Finalize.Swap("fifth", mockFinalizeMiddleware("swapped")); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if _, err := s.Build.Remove("removed"); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	initialize := (*InitializeStep)(nil).ID()
+	build := (*BuildStep)(nil).ID()
+	finalize := (*FinalizeStep)(nil).ID()
+
+	expect := &StackDescription{
+		ID: "fooStack",
+		Middleware: []MiddlewareDescription{
+			{Step: initialize, Position: 0, ID: "first", Placement: After},
+			{Step: build, Position: 0, ID: "third", Placement: Before},
+			{Step: build, Position: 1, ID: "fourth", Placement: Before, RelativeTo: "second"},
+			{Step: build, Position: 2, ID: "second", Placement: After},
+			{Step: finalize, Position: 0, ID: "swapped", Placement: After},
+			{Step: finalize, Position: 1, ID: "sixth", Placement: After, RelativeTo: "swapped"},
+		},
+	}
+	description := s.Describe()
+	if !reflect.DeepEqual(expect, description) {
+		t.Errorf("expect description\n%#v\ngot\n%#v", expect, description)
+	}
+
+	// the description does not change with the stack
+	s.Build.Clear()
+	if e, a := 6, len(description.Middleware); e != a {
+		t.Errorf("expect %v middleware, got %v", e, a)
+	}
+
+	expectListing := `STEP                   POSITION  ID       PLACEMENT
+Initialize stack step  0         first    After step
+Build stack step       0         third    Before step
+Build stack step       1         fourth   Before second
+Build stack step       2         second   After step
+Finalize stack step    0         swapped  After step
+Finalize stack step    1         sixth    After swapped
+`
+	if e, a := expectListing, description.String(); e != a {
+		t.Errorf("expect listing\n%s\ngot\n%s", e, a)
+	}
+
+	dot := description.DOT()
+	for _, line := range []string{
+		`digraph "fooStack" {`,
+		`subgraph "cluster_Build stack step" {`,
+		`"Build stack step/fourth" [label="fourth"];`,
+		`"Initialize stack step/first" -> "Build stack step/third";`,
+		`"Build stack step/second" -> "Finalize stack step/swapped";`,
+		`"Build stack step/fourth" -> "Build stack step/second" [style=dashed, label="before"];`,
+		`"Finalize stack step/sixth" -> "Finalize stack step/swapped" [style=dashed, label="after"];`,
+	} {
+		if !strings.Contains(dot, line) {
+			t.Errorf("expect DOT to contain %q, got\n%s", line, dot)
+		}
+	}
+	if strings.Contains(dot, "Serialize") {
+		t.Errorf("expect no cluster for empty steps, got\n%s", dot)
+	}
+}
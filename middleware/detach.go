@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+)
+
+// DetachContext returns a context for work spawned by a middleware that
+// outlives the invocation of its stack, e.g. a goroutine, that has none of
+// the deadline, cancellation, or values of ctx, other than:
+//
+//   - the logger of ctx, see SetLogger
+//   - the stack values of ctx, see WithStackValue
+//   - the values of ctx with the keys
+//
+// The values are captured when DetachContext is called, such that they
+// remain available once ctx is canceled, unlike a context of
+// context.WithSuppressCancel of the smithy-go context package.
+func DetachContext(ctx context.Context, keys ...interface{}) context.Context {
+	detached := context.Background()
+
+	if v := ctx.Value(loggerKey{}); v != nil {
+		detached = context.WithValue(detached, loggerKey{}, v)
+	}
+	if v := ctx.Value(stackValuesKey{}); v != nil {
+		detached = context.WithValue(detached, stackValuesKey{}, v)
+	}
+	for _, k := range keys {
+		if v := ctx.Value(k); v != nil {
+			detached = context.WithValue(detached, k, v)
+		}
+	}
+	return detached
+}
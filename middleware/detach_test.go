@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/logging"
+)
+
+func TestDetachContext(t *testing.T) {
+	type customKey struct{}
+	type otherKey struct{}
+
+	logger := logging.Nop{}
+	ctx := SetLogger(context.Background(), logger)
+	ctx = WithStackValue(ctx, "operation", "GetFoo")
+	ctx = context.WithValue(ctx, customKey{}, "custom")
+	ctx = context.WithValue(ctx, otherKey{}, "other")
+	ctx, cancel := context.WithTimeout(ctx, time.Hour)
+
+	detached := DetachContext(ctx, customKey{})
+	cancel()
+
+	if _, ok := detached.Deadline(); ok {
+		t.Errorf("expect no deadline")
+	}
+	if err := detached.Err(); err != nil {
+		t.Errorf("expect not canceled, got %v", err)
+	}
+
+	if _, ok := detached.Value(loggerKey{}).(logging.Nop); !ok {
+		t.Errorf("expect logger")
+	}
+	if e, a := "GetFoo", GetStackValue(detached, "operation"); e != a {
+		t.Errorf("expect stack value %v, got %v", e, a)
+	}
+	if e, a := "custom", detached.Value(customKey{}); e != a {
+		t.Errorf("expect value %v, got %v", e, a)
+	}
+	if v := detached.Value(otherKey{}); v != nil {
+		t.Errorf("expect no value, got %v", v)
+	}
+}
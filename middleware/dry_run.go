@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+)
+
+// DryRunError is the error of a stack invocation that stopped before its
+// transport request was sent, see AddDryRunMiddleware.
+type DryRunError struct {
+	// The transport request as it would have been sent, e.g. a
+	// *smithyhttp.Request.
+	Request interface{}
+}
+
+func (e *DryRunError) Error() string {
+	return "dry run, request not sent"
+}
+
+// DryRunMiddleware stops the invocation of a stack before its transport
+// request is sent, returning the request in a *DryRunError, such that the
+// request can be inspected or persisted exactly as it would be sent.
+type DryRunMiddleware struct{}
+
+// AddDryRunMiddleware adds a DryRunMiddleware to the front of the Deserialize
+// step of the stack, such that the Initialize, Serialize, Build, and Finalize
+// steps are invoked, but the Deserialize step and the handler are not.
+func AddDryRunMiddleware(stack *Stack) error {
+	return stack.Deserialize.Add(&DryRunMiddleware{}, Before)
+}
+
+// ID returns the identifier for the middleware.
+func (*DryRunMiddleware) ID() string {
+	return "DryRun"
+}
+
+// HandleDeserialize returns the request of the input in a *DryRunError,
+// without invoking the next handler.
+func (*DryRunMiddleware) HandleDeserialize(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+	out DeserializeOutput, metadata Metadata, err error,
+) {
+	return out, metadata, &DryRunError{Request: in.Request}
+}
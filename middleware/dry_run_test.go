@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDryRunMiddleware(t *testing.T) {
+	type request struct {
+		headers []string
+	}
+
+	s := NewStack("fooStack", func() interface{} { return &request{} })
+	s.Build.Add(BuildMiddlewareFunc("header", func(
+		ctx context.Context, in BuildInput, next BuildHandler,
+	) (BuildOutput, Metadata, error) {
+		in.Request.(*request).headers = append(in.Request.(*request).headers, "build")
+		return next.HandleBuild(ctx, in)
+	}), After)
+	if err := AddDryRunMiddleware(s); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	// middleware added after the dry run middleware are invoked
+	s.Finalize.Add(FinalizeMiddlewareFunc("sign", func(
+		ctx context.Context, in FinalizeInput, next FinalizeHandler,
+	) (FinalizeOutput, Metadata, error) {
+		in.Request.(*request).headers = append(in.Request.(*request).headers, "sign")
+		return next.HandleFinalize(ctx, in)
+	}), After)
+	s.Deserialize.Add(DeserializeMiddlewareFunc("deserialize", func(
+		ctx context.Context, in DeserializeInput, next DeserializeHandler,
+	) (DeserializeOutput, Metadata, error) {
+		t.Errorf("expect deserialize middleware not to be invoked")
+		return next.HandleDeserialize(ctx, in)
+	}), After)
+
+	_, _, err := s.HandleMiddleware(context.Background(), struct{}{}, HandlerFunc(
+		func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			t.Errorf("expect handler not to be invoked")
+			return nil, Metadata{}, nil
+		}))
+
+	var dryRun *DryRunError
+	if !errors.As(err, &dryRun) {
+		t.Fatalf("expect DryRunError, got %v", err)
+	}
+	req, ok := dryRun.Request.(*request)
+	if !ok {
+		t.Fatalf("expect request, got %T", dryRun.Request)
+	}
+	if e, a := []string{"build", "sign"}, req.headers; len(e) != len(a) || e[0] != a[0] || e[1] != a[1] {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
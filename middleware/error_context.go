@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/smithy-go"
+)
+
+var (
+	requestIDKey    = NewMetadataKey[string]("RequestID")
+	attemptCountKey = NewMetadataKey[int]("AttemptCount")
+)
+
+// SetRequestID sets the ID of the transport request of an invocation, e.g.
+// from a response header, in its metadata. Set by the deserializer of a
+// protocol, see GetRequestID.
+func SetRequestID(metadata *Metadata, id string) {
+	requestIDKey.Set(metadata, id)
+}
+
+// GetRequestID returns the ID of the transport request of an invocation from
+// its metadata, if set with SetRequestID.
+func GetRequestID(metadata MetadataReader) (string, bool) {
+	return requestIDKey.Get(metadata)
+}
+
+// SetAttemptCount sets the number of attempts of an invocation in its
+// metadata. Set by the retry middleware of the Finalize step, see
+// GetAttemptCount.
+func SetAttemptCount(metadata *Metadata, n int) {
+	attemptCountKey.Set(metadata, n)
+}
+
+// GetAttemptCount returns the number of attempts of an invocation from its
+// metadata, if set with SetAttemptCount.
+func GetAttemptCount(metadata MetadataReader) (int, bool) {
+	return attemptCountKey.Get(metadata)
+}
+
+// RequestContextError is an error of an invocation with the diagnostic
+// context of its request, see AddErrorContextMiddleware.
+type RequestContextError struct {
+	// The ID of the transport request, if known, see GetRequestID.
+	RequestID string
+
+	// The number of attempts of the request, or 0 if not known, see
+	// GetAttemptCount.
+	Attempts int
+
+	Err error
+}
+
+func (e *RequestContextError) Error() string {
+	var b strings.Builder
+	if len(e.RequestID) != 0 {
+		b.WriteString("request id: ")
+		b.WriteString(e.RequestID)
+		b.WriteString(", ")
+	}
+	if e.Attempts > 0 {
+		b.WriteString("attempts: ")
+		b.WriteString(strconv.Itoa(e.Attempts))
+		b.WriteString(", ")
+	}
+	return fmt.Sprintf("%s%v", b.String(), e.Err)
+}
+
+// Unwrap returns the error of the invocation.
+func (e *RequestContextError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorContextOptions is the set of options that can be configured for an
+// ErrorContextMiddleware.
+type ErrorContextOptions struct {
+	// The service and operation names of the smithy.OperationError errors
+	// are returned as.
+	ServiceID     string
+	OperationName string
+}
+
+// ErrorContextMiddleware returns the errors of the stack it is added to with
+// uniform diagnostic context, as a *smithy.OperationError wrapping a
+// *RequestContextError with the request ID and attempt count of the
+// invocation's metadata. An error that is a *smithy.OperationError, e.g. of a
+// RecoverMiddleware, is not wrapped again, but has its error wrapped.
+type ErrorContextMiddleware struct {
+	Options ErrorContextOptions
+}
+
+// AddErrorContextMiddleware adds an ErrorContextMiddleware to the front of
+// the Initialize step of the stack, such that all errors of the stack are
+// returned with their context.
+func AddErrorContextMiddleware(stack *Stack, optFns ...func(*ErrorContextOptions)) error {
+	var o ErrorContextOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	return stack.Initialize.Add(&ErrorContextMiddleware{Options: o}, Before)
+}
+
+// ID returns the identifier for the middleware.
+func (*ErrorContextMiddleware) ID() string {
+	return "ErrorContext"
+}
+
+// HandleInitialize invokes the next handler, returning its error with the
+// context of the invocation.
+func (m *ErrorContextMiddleware) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	out, metadata, err = next.HandleInitialize(ctx, in)
+	if err == nil {
+		return out, metadata, nil
+	}
+
+	var rerr *RequestContextError
+	if errors.As(err, &rerr) {
+		return out, metadata, err
+	}
+
+	oerr := &smithy.OperationError{
+		ServiceID:     m.Options.ServiceID,
+		OperationName: m.Options.OperationName,
+		Err:           err,
+	}
+	if v, ok := err.(*smithy.OperationError); ok {
+		oerr.ServiceID, oerr.OperationName, oerr.Err = v.ServiceID, v.OperationName, v.Err
+	}
+
+	requestID, _ := GetRequestID(metadata)
+	attempts, _ := GetAttemptCount(metadata)
+	oerr.Err = &RequestContextError{RequestID: requestID, Attempts: attempts, Err: oerr.Err}
+	return out, metadata, oerr
+}
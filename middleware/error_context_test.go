@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestErrorContextMiddleware(t *testing.T) {
+	sendErr := errors.New("connection reset")
+
+	cases := map[string]struct {
+		err       error
+		metadata  func(*Metadata)
+		expectMsg string
+	}{
+		"request id and attempts": {
+			err: sendErr,
+			metadata: func(m *Metadata) {
+				SetRequestID(m, "abc-123")
+				SetAttemptCount(m, 3)
+			},
+			expectMsg: "operation error FooService: GetFoo, request id: abc-123, attempts: 3, connection reset",
+		},
+		"no metadata": {
+			err:       sendErr,
+			metadata:  func(*Metadata) {},
+			expectMsg: "operation error FooService: GetFoo, connection reset",
+		},
+		"operation error": {
+			err: &smithy.OperationError{ServiceID: "BarService", OperationName: "GetBar", Err: sendErr},
+			metadata: func(m *Metadata) {
+				SetAttemptCount(m, 1)
+			},
+			expectMsg: "operation error BarService: GetBar, attempts: 1, connection reset",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			AddErrorContextMiddleware(s, func(o *ErrorContextOptions) {
+				o.ServiceID, o.OperationName = "FooService", "GetFoo"
+			})
+
+			_, _, err := s.HandleMiddleware(context.Background(), struct{}{}, HandlerFunc(
+				func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+					var metadata Metadata
+					c.metadata(&metadata)
+					return nil, metadata, c.err
+				}))
+
+			if e, a := c.expectMsg, err.Error(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+			if !errors.Is(err, sendErr) {
+				t.Errorf("expect %v, got %v", sendErr, err)
+			}
+			var rerr *RequestContextError
+			if !errors.As(err, &rerr) {
+				t.Errorf("expect RequestContextError, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import "fmt"
+
+// FoundMiddleware is a middleware of a stack found by its ID, see
+// Stack.Find.
+type FoundMiddleware struct {
+	// The step of the middleware, one of "Initialize", "Serialize", "Build",
+	// "Finalize", or "Deserialize".
+	Step string
+
+	// The position of the middleware in its step, starting at 0.
+	Position int
+
+	// The middleware, e.g. a BuildMiddleware for the Build step.
+	Middleware interface{}
+}
+
+// stackSteps are the steps of a stack, in order.
+var stackSteps = []string{"Initialize", "Serialize", "Build", "Finalize", "Deserialize"}
+
+// Find returns the middleware of the stack with the ID, or false if there is
+// none. The steps are searched in order, such that the middleware of the
+// first step with the ID is returned.
+func (s *Stack) Find(id string) (FoundMiddleware, bool) {
+	for _, step := range stackSteps {
+		ids, _ := stepIDs(s, step)
+		for i, v := range ids.List() {
+			if v != id {
+				continue
+			}
+			if m, ok := ids.Get(id); ok {
+				return FoundMiddleware{Step: step, Position: i, Middleware: m}, true
+			}
+		}
+	}
+	return FoundMiddleware{}, false
+}
+
+// ReplaceFunc replaces the middleware of the stack with the ID, as found by
+// Find, with the middleware returned by fn, in its position, e.g. to wrap it.
+// fn is called with the step and the middleware, and must return a
+// middleware of the step, e.g. a BuildMiddleware for the Build step, whose ID
+// may differ. Returns an error if there is no such middleware, or fn returns
+// an error.
+func (s *Stack) ReplaceFunc(id string, fn func(step string, m interface{}) (interface{}, error)) error {
+	found, ok := s.Find(id)
+	if !ok {
+		return fmt.Errorf("replace %s, middleware not found", id)
+	}
+
+	v, err := fn(found.Step, found.Middleware)
+	if err != nil {
+		return fmt.Errorf("replace %s, %w", id, err)
+	}
+	if err := checkStepMiddleware(found.Step, v); err != nil {
+		return fmt.Errorf("replace %s, %w", id, err)
+	}
+
+	ids, _ := stepIDs(s, found.Step)
+	if _, err := ids.Swap(id, v.(ider)); err != nil {
+		return fmt.Errorf("replace %s, %w", id, err)
+	}
+	return nil
+}
+
+// checkStepMiddleware returns an error if m is not a middleware of the step.
+func checkStepMiddleware(step string, m interface{}) error {
+	var ok bool
+	switch step {
+	case "Initialize":
+		_, ok = m.(InitializeMiddleware)
+	case "Serialize":
+		_, ok = m.(SerializeMiddleware)
+	case "Build":
+		_, ok = m.(BuildMiddleware)
+	case "Finalize":
+		_, ok = m.(FinalizeMiddleware)
+	case "Deserialize":
+		_, ok = m.(DeserializeMiddleware)
+	default:
+		return fmt.Errorf("unknown stack step %q", step)
+	}
+	if !ok {
+		return fmt.Errorf("%T is not a %s middleware", m, step)
+	}
+	return nil
+}
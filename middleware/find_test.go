@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStackFind(t *testing.T) {
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+	s.Initialize.Add(mockInitializeMiddleware("first"), After)
+	s.Build.Add(mockBuildMiddleware("second"), After)
+	s.Build.Add(mockBuildMiddleware("third"), After)
+	s.Deserialize.Add(mockDeserializeMiddleware("third"), After)
+
+	found, ok := s.Find("third")
+	if !ok {
+		t.Fatalf("expect middleware found")
+	}
+	if e, a := "Build", found.Step; e != a {
+		t.Errorf("expect step %v, got %v", e, a)
+	}
+	if e, a := 1, found.Position; e != a {
+		t.Errorf("expect position %v, got %v", e, a)
+	}
+	if _, ok := found.Middleware.(BuildMiddleware); !ok {
+		t.Errorf("expect build middleware, got %T", found.Middleware)
+	}
+
+	if _, ok := s.Find("unknown"); ok {
+		t.Errorf("expect middleware not found")
+	}
+}
+
+func TestStackReplaceFunc(t *testing.T) {
+	wrapErr := errors.New("wrapper failed")
+
+	cases := map[string]struct {
+		id        string
+		fn        func(step string, m interface{}) (interface{}, error)
+		expectErr bool
+		expect    []string
+	}{
+		"wrap": {
+			id: "second",
+			fn: func(step string, m interface{}) (interface{}, error) {
+				next := m.(BuildMiddleware)
+				return BuildMiddlewareFunc("second", func(
+					ctx context.Context, in BuildInput, h BuildHandler,
+				) (BuildOutput, Metadata, error) {
+					return next.HandleBuild(ctx, in, h)
+				}), nil
+			},
+			expect: []string{"first", "second", "third"},
+		},
+		"rename": {
+			id: "second",
+			fn: func(step string, m interface{}) (interface{}, error) {
+				return mockBuildMiddleware("renamed"), nil
+			},
+			expect: []string{"first", "renamed", "third"},
+		},
+		"not found": {
+			id: "unknown",
+			fn: func(step string, m interface{}) (interface{}, error) {
+				return m, nil
+			},
+			expectErr: true,
+			expect:    []string{"first", "second", "third"},
+		},
+		"wrong step": {
+			id: "second",
+			fn: func(step string, m interface{}) (interface{}, error) {
+				return mockInitializeMiddleware("second"), nil
+			},
+			expectErr: true,
+			expect:    []string{"first", "second", "third"},
+		},
+		"error": {
+			id: "second",
+			fn: func(step string, m interface{}) (interface{}, error) {
+				return nil, wrapErr
+			},
+			expectErr: true,
+			expect:    []string{"first", "second", "third"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			s.Build.Add(mockBuildMiddleware("first"), After)
+			s.Build.Add(mockBuildMiddleware("second"), After)
+			s.Build.Add(mockBuildMiddleware("third"), After)
+
+			err := s.ReplaceFunc(c.id, c.fn)
+			if e, a := c.expectErr, err != nil; e != a {
+				t.Fatalf("expect error %v, got %v", e, err)
+			}
+			if e, a := c.expect, s.Build.List(); !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package middleware
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MiddlewareGroup is a named set of middleware spanning the steps of a stack,
+// such as those of an SDK customization, e.g. "checksum", that is added to,
+// removed from, or replaced in a stack as a unit, see Stack.AddGroup.
+type MiddlewareGroup struct {
+	name    string
+	members []groupMember
+}
+
+// groupMember is a middleware of a group, and its placement in its step.
+type groupMember struct {
+	step       string
+	middleware ider
+	relativeTo string
+	pos        RelativePosition
+}
+
+// NewMiddlewareGroup returns an empty group with the name.
+func NewMiddlewareGroup(name string) *MiddlewareGroup {
+	return &MiddlewareGroup{name: name}
+}
+
+// Name returns the name of the group.
+func (g *MiddlewareGroup) Name() string { return g.name }
+
+// Initialize adds the middleware to the group's members of the Initialize
+// step, inserted relative to the relativeTo middleware, or added to the step
+// if relativeTo is empty. Members are added to a stack in the order they are
+// added to the group, such that a member may be relative to another member.
+func (g *MiddlewareGroup) Initialize(m InitializeMiddleware, relativeTo string, pos RelativePosition) *MiddlewareGroup {
+	return g.add("Initialize", m, relativeTo, pos)
+}
+
+// Serialize adds the middleware to the group's members of the Serialize step,
+// as Initialize does.
+func (g *MiddlewareGroup) Serialize(m SerializeMiddleware, relativeTo string, pos RelativePosition) *MiddlewareGroup {
+	return g.add("Serialize", m, relativeTo, pos)
+}
+
+// Build adds the middleware to the group's members of the Build step, as
+// Initialize does.
+func (g *MiddlewareGroup) Build(m BuildMiddleware, relativeTo string, pos RelativePosition) *MiddlewareGroup {
+	return g.add("Build", m, relativeTo, pos)
+}
+
+// Finalize adds the middleware to the group's members of the Finalize step,
+// as Initialize does.
+func (g *MiddlewareGroup) Finalize(m FinalizeMiddleware, relativeTo string, pos RelativePosition) *MiddlewareGroup {
+	return g.add("Finalize", m, relativeTo, pos)
+}
+
+// Deserialize adds the middleware to the group's members of the Deserialize
+// step, as Initialize does.
+func (g *MiddlewareGroup) Deserialize(m DeserializeMiddleware, relativeTo string, pos RelativePosition) *MiddlewareGroup {
+	return g.add("Deserialize", m, relativeTo, pos)
+}
+
+func (g *MiddlewareGroup) add(step string, m ider, relativeTo string, pos RelativePosition) *MiddlewareGroup {
+	g.members = append(g.members, groupMember{step: step, middleware: m, relativeTo: relativeTo, pos: pos})
+	return g
+}
+
+// List returns the members of the group as references to their steps and
+// IDs.
+func (g *MiddlewareGroup) List() []MiddlewareRef {
+	refs := make([]MiddlewareRef, 0, len(g.members))
+	for _, m := range g.members {
+		refs = append(refs, MiddlewareRef{Step: m.step, ID: m.middleware.ID()})
+	}
+	return refs
+}
+
+// AddGroup adds the members of the group to the stack. Returns an error if a
+// group with the same name was already added, or a member cannot be added,
+// e.g. its ID already exists in its step, in which case the stack is
+// unchanged.
+func (s *Stack) AddGroup(g *MiddlewareGroup) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(g.name) == 0 {
+		return fmt.Errorf("middleware group must have a name")
+	}
+	if _, ok := s.groups[g.name]; ok {
+		return fmt.Errorf("add middleware group %s, already exists", g.name)
+	}
+
+	if err := s.updateGroup(nil, g); err != nil {
+		return fmt.Errorf("add middleware group %s, %w", g.name, err)
+	}
+	return nil
+}
+
+// RemoveGroup removes the members of the group with the name from the stack.
+// Returns an error if the group was not added, or a member is no longer in
+// the stack, in which case the stack is unchanged.
+func (s *Stack) RemoveGroup(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.groups[name]
+	if !ok {
+		return fmt.Errorf("remove middleware group %s, not found", name)
+	}
+
+	if err := s.updateGroup(old, nil); err != nil {
+		return fmt.Errorf("remove middleware group %s, %w", name, err)
+	}
+	return nil
+}
+
+// ReplaceGroup replaces the members of the group with the same name in the
+// stack with those of g, or adds g if there is no such group. A member with
+// the same step and ID as a member of the replaced group is swapped in its
+// place. The members of the replaced group that are not in g are removed, and
+// the other members of g are added. Returns an error if a member cannot be
+// added or removed, in which case the stack is unchanged.
+func (s *Stack) ReplaceGroup(g *MiddlewareGroup) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(g.name) == 0 {
+		return fmt.Errorf("middleware group must have a name")
+	}
+
+	if err := s.updateGroup(s.groups[g.name], g); err != nil {
+		return fmt.Errorf("replace middleware group %s, %w", g.name, err)
+	}
+	return nil
+}
+
+// Groups returns the names of the groups added to the stack, in sorted order.
+func (s *Stack) Groups() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.groups))
+	for name := range s.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// updateGroup replaces the members of the old group with those of the new
+// group, either of which may be nil. The update is made to copies of the
+// steps a member of either group is in, which replace the steps only if it
+// succeeds. The steps are locked for the duration of the update, such that a
+// concurrent edit of a step is not lost to it, and each step is seen by an
+// invocation of the stack either before or after the update. An invocation
+// reads the order of each step as it runs the step, so an invocation
+// concurrent with the update may see it in some steps and not others.
+func (s *Stack) updateGroup(old, g *MiddlewareGroup) error {
+	touched := map[string]bool{}
+	for _, group := range []*MiddlewareGroup{old, g} {
+		if group == nil {
+			continue
+		}
+		for _, m := range group.members {
+			touched[m.step] = true
+		}
+	}
+
+	// steps are locked in the order of the stack, so that concurrent updates
+	// cannot deadlock
+	copies := map[string]*orderedIDs{}
+	for _, step := range stackSteps {
+		if !touched[step] {
+			continue
+		}
+		ids, _ := stepIDs(s, step)
+		ids.mu.Lock()
+		defer ids.mu.Unlock()
+		copies[step] = ids.cloneLocked()
+	}
+
+	// members of the new group that replace members of the old group
+	swapped := map[MiddlewareRef]bool{}
+	if g != nil {
+		for _, m := range g.members {
+			swapped[MiddlewareRef{Step: m.step, ID: m.middleware.ID()}] = false
+		}
+	}
+
+	if old != nil {
+		for _, m := range old.members {
+			ids, ok := copies[m.step]
+			if !ok {
+				return fmt.Errorf("unknown stack step %q", m.step)
+			}
+
+			ref := MiddlewareRef{Step: m.step, ID: m.middleware.ID()}
+			if _, ok := swapped[ref]; ok {
+				swapped[ref] = true
+				continue
+			}
+			if _, err := ids.Remove(ref.ID); err != nil {
+				return fmt.Errorf("remove %s from %s step, %w", ref.ID, ref.Step, err)
+			}
+		}
+	}
+
+	if g != nil {
+		for _, m := range g.members {
+			ids, ok := copies[m.step]
+			if !ok {
+				return fmt.Errorf("unknown stack step %q", m.step)
+			}
+
+			var err error
+			id := m.middleware.ID()
+			switch {
+			case swapped[MiddlewareRef{Step: m.step, ID: id}]:
+				_, err = ids.Swap(id, m.middleware)
+			case len(m.relativeTo) == 0:
+				err = ids.Add(m.middleware, m.pos)
+			default:
+				err = ids.Insert(m.middleware, m.relativeTo, m.pos)
+			}
+			if err != nil {
+				return fmt.Errorf("add %s to %s step, %w", id, m.step, err)
+			}
+		}
+	}
+
+	for step, c := range copies {
+		ids, _ := stepIDs(s, step)
+		ids.order, ids.items, ids.placements = c.order, c.items, c.placements
+	}
+
+	if old != nil {
+		delete(s.groups, old.name)
+	}
+	if g != nil {
+		if s.groups == nil {
+			s.groups = map[string]*MiddlewareGroup{}
+		}
+		s.groups[g.name] = g.clone()
+	}
+	return nil
+}
+
+// clone returns a copy of the group, such that a group added to a stack is not
+// changed by adding members to the group afterwards.
+func (g *MiddlewareGroup) clone() *MiddlewareGroup {
+	return &MiddlewareGroup{
+		name:    g.name,
+		members: append([]groupMember(nil), g.members...),
+	}
+}
@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func newGroupTestStack(t *testing.T) *Stack {
+	t.Helper()
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+	noError(t, s.Initialize.Add(mockInitializeMiddleware("validate"), After))
+	noError(t, s.Build.Add(mockBuildMiddleware("userAgent"), After))
+	noError(t, s.Finalize.Add(mockFinalizeMiddleware("retry"), After))
+	noError(t, s.Finalize.Add(mockFinalizeMiddleware("sign"), After))
+	return s
+}
+
+func TestStackGroup(t *testing.T) {
+	s := newGroupTestStack(t)
+
+	checksum := NewMiddlewareGroup("checksum").
+		Initialize(mockInitializeMiddleware("checksumValidation"), "", After).
+		Build(mockBuildMiddleware("computeChecksum"), "userAgent", Before).
+		Finalize(mockFinalizeMiddleware("checksumHeader"), "sign", Before).
+		Finalize(mockFinalizeMiddleware("checksumTrailer"), "checksumHeader", After)
+	if err := s.AddGroup(checksum); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	expect := []string{
+		"fooStack",
+		(*InitializeStep)(nil).ID(), "validate", "checksumValidation",
+		(*SerializeStep)(nil).ID(),
+		(*BuildStep)(nil).ID(), "computeChecksum", "userAgent",
+		(*FinalizeStep)(nil).ID(), "retry", "checksumHeader", "checksumTrailer", "sign",
+		(*DeserializeStep)(nil).ID(),
+	}
+	if e, a := expect, s.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if e, a := []string{"checksum"}, s.Groups(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if err := s.AddGroup(checksum); err == nil {
+		t.Errorf("expect error adding group twice")
+	}
+
+	// replaced members are swapped in place, others removed or added
+	replacement := NewMiddlewareGroup("checksum").
+		Build(mockBuildMiddleware("computeChecksum"), "", After).
+		Deserialize(mockDeserializeMiddleware("validateResponseChecksum"), "", After)
+	if err := s.ReplaceGroup(replacement); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	expect = []string{
+		"fooStack",
+		(*InitializeStep)(nil).ID(), "validate",
+		(*SerializeStep)(nil).ID(),
+		(*BuildStep)(nil).ID(), "computeChecksum", "userAgent",
+		(*FinalizeStep)(nil).ID(), "retry", "sign",
+		(*DeserializeStep)(nil).ID(), "validateResponseChecksum",
+	}
+	if e, a := expect, s.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	if err := s.RemoveGroup("checksum"); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := newGroupTestStack(t).List(), s.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if e, a := 0, len(s.Groups()); e != a {
+		t.Errorf("expect %v groups, got %v", e, a)
+	}
+	if err := s.RemoveGroup("checksum"); err == nil {
+		t.Errorf("expect error removing group twice")
+	}
+}
+
+func TestStackGroup_Atomic(t *testing.T) {
+	cases := map[string]struct {
+		setup  func(*Stack) error
+		update func(*Stack) error
+	}{
+		"add relative to missing": {
+			update: func(s *Stack) error {
+				return s.AddGroup(NewMiddlewareGroup("group").
+					Initialize(mockInitializeMiddleware("a"), "", After).
+					Build(mockBuildMiddleware("b"), "missing", Before))
+			},
+		},
+		"add duplicate ID": {
+			update: func(s *Stack) error {
+				return s.AddGroup(NewMiddlewareGroup("group").
+					Build(mockBuildMiddleware("b"), "", After).
+					Finalize(mockFinalizeMiddleware("sign"), "", After))
+			},
+		},
+		"remove member no longer in stack": {
+			setup: func(s *Stack) error {
+				if err := s.AddGroup(NewMiddlewareGroup("group").
+					Build(mockBuildMiddleware("b"), "", After).
+					Finalize(mockFinalizeMiddleware("c"), "", After)); err != nil {
+					return err
+				}
+				_, err := s.Finalize.Remove("c")
+				return err
+			},
+			update: func(s *Stack) error {
+				return s.RemoveGroup("group")
+			},
+		},
+		"replace with invalid member": {
+			setup: func(s *Stack) error {
+				return s.AddGroup(NewMiddlewareGroup("group").
+					Build(mockBuildMiddleware("b"), "", After))
+			},
+			update: func(s *Stack) error {
+				return s.ReplaceGroup(NewMiddlewareGroup("group").
+					Initialize(mockInitializeMiddleware("a"), "", After).
+					Finalize(mockFinalizeMiddleware("c"), "b", After))
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := newGroupTestStack(t)
+			if c.setup != nil {
+				noError(t, c.setup(s))
+			}
+			before, groups := s.List(), s.Groups()
+
+			if err := c.update(s); err == nil {
+				t.Fatalf("expect error")
+			}
+			if e, a := before, s.List(); !reflect.DeepEqual(e, a) {
+				t.Errorf("expect stack unchanged\n%v\ngot\n%v", e, a)
+			}
+			if e, a := groups, s.Groups(); !reflect.DeepEqual(e, a) {
+				t.Errorf("expect groups unchanged %v, got %v", e, a)
+			}
+
+			// the restored steps are still usable
+			noError(t, s.Build.Add(mockBuildMiddleware("after"), After))
+		})
+	}
+}
+
+func TestStackGroup_AddedGroupCopied(t *testing.T) {
+	s := newGroupTestStack(t)
+	g := NewMiddlewareGroup("group").Build(mockBuildMiddleware("a"), "", After)
+	noError(t, s.AddGroup(g))
+
+	// members added to the group after it is added to the stack are not part
+	// of the group in the stack
+	g.Build(mockBuildMiddleware("b"), "", After)
+	noError(t, s.Build.Add(mockBuildMiddleware("b"), After))
+	noError(t, s.RemoveGroup("group"))
+
+	if e, a := []string{"userAgent", "b"}, s.Build.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
+
+func TestStackGroup_Concurrent(t *testing.T) {
+	s := newGroupTestStack(t)
+	g := NewMiddlewareGroup("group").
+		Build(mockBuildMiddleware("a"), "", After).
+		Build(mockBuildMiddleware("b"), "", After)
+
+	const n = 100
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			if err := s.Build.Add(mockBuildMiddleware(fmt.Sprintf("c%d", i)), After); err != nil {
+				t.Errorf("expect no error, got %v", err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			ids := map[string]bool{}
+			for _, id := range s.Build.List() {
+				ids[id] = true
+			}
+			if ids["a"] != ids["b"] {
+				t.Errorf("expect group applied as a unit, got %v", s.Build.List())
+			}
+		}
+	}()
+	for i := 0; i < n; i++ {
+		noError(t, s.AddGroup(g))
+		noError(t, s.RemoveGroup("group"))
+	}
+	wg.Wait()
+
+	// none of the concurrent edits are lost
+	if e, a := n+1, len(s.Build.List()); e != a {
+		t.Errorf("expect %v middleware, got %v", e, a)
+	}
+}
+
+func TestMiddlewareGroup_List(t *testing.T) {
+	g := NewMiddlewareGroup("group").
+		Serialize(mockSerializeMiddleware("a"), "", Before).
+		Deserialize(mockDeserializeMiddleware("b"), "", After)
+
+	expect := []MiddlewareRef{{Step: "Serialize", ID: "a"}, {Step: "Deserialize", ID: "b"}}
+	if e, a := expect, g.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if e, a := "group", g.Name(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// StackHooks are functions called as a stack is invoked, such that the
+// execution of its steps and middleware can be observed, e.g. by tracing
+// integrations, without adding a middleware to each step. Any of the
+// functions may be nil.
+//
+// The hooks are called synchronously, and must not block.
+type StackHooks struct {
+	// Called when a step is invoked, before its middleware.
+	OnStepEnter func(context.Context, StepEvent)
+
+	// Called when a step returns, with the error it returns, if any.
+	OnStepExit func(context.Context, StepEvent, error)
+
+	// Called with the error of the middleware or handler the error
+	// originates from. An error that is returned, or wrapped, by the
+	// middleware that invoked the one it originates from is not reported
+	// again.
+	OnError func(context.Context, ErrorEvent)
+}
+
+// StepEvent is the step of a stack invocation a hook is called for.
+type StepEvent struct {
+	// The ID of the stack.
+	Stack string
+
+	// The ID of the step, e.g. "Build stack step".
+	Step string
+}
+
+// ErrorEvent is the error of a middleware or handler of a stack invocation.
+type ErrorEvent struct {
+	// The ID of the stack.
+	Stack string
+
+	// The ID of the step and middleware the error originates from. Both are
+	// empty if the error originates from the handler of the stack.
+	Step       string
+	Middleware string
+
+	// The error.
+	Err error
+}
+
+// AddHooks adds the hooks to the stack, which are called in the order they
+// are added with the events of each invocation of the stack.
+func (s *Stack) AddHooks(hooks StackHooks) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hooks = append(s.hooks, hooks)
+}
+
+// hookEmitter calls the hooks of a stack invocation.
+type hookEmitter struct {
+	stackID string
+	hooks   []StackHooks
+
+	// the last error reported, such that it is not reported again as it is
+	// returned through the middleware that invoked the one it originates
+	// from
+	mu   sync.Mutex
+	last error
+}
+
+// stepHooks calls the hooks of the invocation of a step.
+type stepHooks struct {
+	emitter *hookEmitter
+	stepID  string
+}
+
+// step returns the hooks of the step, or nil if the emitter is nil.
+func (e *hookEmitter) step(stepID string) *stepHooks {
+	if e == nil {
+		return nil
+	}
+	return &stepHooks{emitter: e, stepID: stepID}
+}
+
+// enter is called when a middleware or the handler is invoked.
+func (e *hookEmitter) enter() {
+	e.mu.Lock()
+	e.last = nil
+	e.mu.Unlock()
+}
+
+// returned is called with the error a middleware or the handler returns,
+// reporting it if it originates from it.
+func (e *hookEmitter) returned(ctx context.Context, stepID, middlewareID string, err error) {
+	if err == nil {
+		return
+	}
+
+	e.mu.Lock()
+	last := e.last
+	e.last = err
+	e.mu.Unlock()
+	if last != nil && errors.Is(err, last) {
+		return
+	}
+
+	event := ErrorEvent{Stack: e.stackID, Step: stepID, Middleware: middlewareID, Err: err}
+	for _, h := range e.hooks {
+		if h.OnError != nil {
+			h.OnError(ctx, event)
+		}
+	}
+}
+
+func (h *stepHooks) enterStep(ctx context.Context) {
+	event := StepEvent{Stack: h.emitter.stackID, Step: h.stepID}
+	for _, hooks := range h.emitter.hooks {
+		if hooks.OnStepEnter != nil {
+			hooks.OnStepEnter(ctx, event)
+		}
+	}
+}
+
+func (h *stepHooks) exitStep(ctx context.Context, err error) {
+	event := StepEvent{Stack: h.emitter.stackID, Step: h.stepID}
+	for _, hooks := range h.emitter.hooks {
+		if hooks.OnStepExit != nil {
+			hooks.OnStepExit(ctx, event, err)
+		}
+	}
+}
+
+// enter is called when a middleware of the step is invoked.
+func (h *stepHooks) enter() {
+	h.emitter.enter()
+}
+
+// returned is called with the error the middleware of the step returns.
+func (h *stepHooks) returned(ctx context.Context, middlewareID string, err error) {
+	h.emitter.returned(ctx, h.stepID, middlewareID, err)
+}
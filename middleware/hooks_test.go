@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestStackHooks(t *testing.T) {
+	attemptErr := errors.New("attempt failed")
+	handlerErr := errors.New("handler failed")
+
+	cases := map[string]struct {
+		handlerErr error
+	}{
+		"no error": {},
+		"handler error": {
+			handlerErr: handlerErr,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			s.Initialize.Add(mockInitializeMiddleware("initialize"), After)
+			s.Finalize.Add(FinalizeMiddlewareFunc("retry", func(
+				ctx context.Context, in FinalizeInput, next FinalizeHandler,
+			) (FinalizeOutput, Metadata, error) {
+				out, metadata, err := next.HandleFinalize(ctx, in)
+				if err == nil {
+					return out, metadata, err
+				}
+				_, _, err = next.HandleFinalize(ctx, in)
+				return out, metadata, fmt.Errorf("%w, %v", attemptErr, err)
+			}), After)
+			s.Deserialize.Add(DeserializeMiddlewareFunc("deserialize", func(
+				ctx context.Context, in DeserializeInput, next DeserializeHandler,
+			) (DeserializeOutput, Metadata, error) {
+				out, metadata, err := next.HandleDeserialize(ctx, in)
+				if err != nil {
+					return out, metadata, fmt.Errorf("deserialize: %w", err)
+				}
+				return out, metadata, err
+			}), After)
+
+			var steps []string
+			var errs []ErrorEvent
+			s.AddHooks(StackHooks{
+				OnStepEnter: func(ctx context.Context, e StepEvent) {
+					steps = append(steps, "enter "+e.Step)
+				},
+				OnStepExit: func(ctx context.Context, e StepEvent, err error) {
+					steps = append(steps, fmt.Sprintf("exit %v %v", e.Step, err != nil))
+				},
+				OnError: func(ctx context.Context, e ErrorEvent) {
+					errs = append(errs, e)
+				},
+			})
+			// hooks with nil functions are skipped
+			s.AddHooks(StackHooks{})
+
+			_, _, err := s.HandleMiddleware(context.Background(), struct{}{}, HandlerFunc(
+				func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+					return nil, Metadata{}, c.handlerErr
+				}))
+			if e, a := c.handlerErr != nil, err != nil; e != a {
+				t.Fatalf("expect error %v, got %v", e, err)
+			}
+
+			failed := c.handlerErr != nil
+			expectSteps := []string{
+				"enter Initialize stack step",
+				"enter Serialize stack step",
+				"enter Build stack step",
+				"enter Finalize stack step",
+				"enter Deserialize stack step",
+				fmt.Sprintf("exit Deserialize stack step %v", failed),
+			}
+			if failed {
+				// retried
+				expectSteps = append(expectSteps,
+					"enter Deserialize stack step",
+					"exit Deserialize stack step true",
+				)
+			}
+			expectSteps = append(expectSteps,
+				fmt.Sprintf("exit Finalize stack step %v", failed),
+				fmt.Sprintf("exit Build stack step %v", failed),
+				fmt.Sprintf("exit Serialize stack step %v", failed),
+				fmt.Sprintf("exit Initialize stack step %v", failed),
+			)
+			if !reflect.DeepEqual(expectSteps, steps) {
+				t.Errorf("expect steps\n%v\ngot\n%v", expectSteps, steps)
+			}
+
+			var expectErrors []ErrorEvent
+			if failed {
+				// the error of each attempt is reported by the handler, and
+				// the error of the retry middleware that wraps it
+				expectErrors = []ErrorEvent{
+					{Stack: "fooStack", Err: handlerErr},
+					{Stack: "fooStack", Err: handlerErr},
+					{Stack: "fooStack", Step: "Finalize stack step", Middleware: "retry"},
+				}
+			}
+			if e, a := len(expectErrors), len(errs); e != a {
+				t.Fatalf("expect %v errors, got %v: %v", e, a, errs)
+			}
+			for i, e := range expectErrors {
+				a := errs[i]
+				if e.Stack != a.Stack || e.Step != a.Step || e.Middleware != a.Middleware {
+					t.Errorf("expect error %v of %v, got %v", i, e, a)
+				}
+				if e.Err != nil && e.Err != a.Err {
+					t.Errorf("expect error %v %v, got %v", i, e.Err, a.Err)
+				}
+			}
+			if failed && !errors.Is(errs[2].Err, attemptErr) {
+				t.Errorf("expect %v, got %v", attemptErr, errs[2].Err)
+			}
+		})
+	}
+}
+
+func TestStackHooks_NestedStack(t *testing.T) {
+	inner := NewStack("inner", func() interface{} { return struct{}{} })
+
+	outer := NewStack("outer", func() interface{} { return struct{}{} })
+	outer.Initialize.Add(InitializeMiddlewareFunc("invokeInner", func(
+		ctx context.Context, in InitializeInput, next InitializeHandler,
+	) (InitializeOutput, Metadata, error) {
+		inner.HandleMiddleware(ctx, struct{}{}, HandlerFunc(
+			func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+				return nil, Metadata{}, nil
+			}))
+		return next.HandleInitialize(ctx, in)
+	}), After)
+
+	var stacks []string
+	outer.AddHooks(StackHooks{
+		OnStepEnter: func(ctx context.Context, e StepEvent) {
+			stacks = append(stacks, e.Stack)
+		},
+	})
+
+	outer.HandleMiddleware(context.Background(), struct{}{}, HandlerFunc(
+		func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			return nil, Metadata{}, nil
+		}))
+
+	for _, s := range stacks {
+		if s != "outer" {
+			t.Errorf("expect only outer stack events, got %v", s)
+		}
+	}
+	if e, a := 5, len(stacks); e != a {
+		t.Errorf("expect %v events, got %v", e, a)
+	}
+}
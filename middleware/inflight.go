@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InflightOperation is an operation in flight through a stack tracked by an
+// InflightRegistry.
+type InflightOperation struct {
+	// The ID of the registration of the operation, unique to the registry.
+	ID uint64
+
+	// The ID of the stack of the operation, e.g. its operation name.
+	Stack string
+
+	// The time the operation was invoked.
+	Started time.Time
+}
+
+// InflightRegistry tracks the operations in flight through the stacks it is
+// added to, such that they can be listed and canceled, e.g. for the graceful
+// shutdown of a service. An InflightRegistry is safe for concurrent use, and
+// is shared by the stacks of the operations it tracks, see
+// AddInflightMiddleware.
+type InflightRegistry struct {
+	mu     sync.Mutex
+	nextID uint64
+	ops    map[uint64]*inflightOperation
+	done   chan struct{}
+}
+
+type inflightOperation struct {
+	InflightOperation
+	cancel context.CancelFunc
+}
+
+// NewInflightRegistry returns an empty InflightRegistry.
+func NewInflightRegistry() *InflightRegistry {
+	return &InflightRegistry{ops: map[uint64]*inflightOperation{}}
+}
+
+// List returns the operations in flight, in the order they were invoked.
+func (r *InflightRegistry) List() []InflightOperation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ops := make([]InflightOperation, 0, len(r.ops))
+	for _, op := range r.ops {
+		ops = append(ops, op.InflightOperation)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].ID < ops[j].ID
+	})
+	return ops
+}
+
+// Cancel cancels the context of the operation with the ID, returning whether
+// it was in flight.
+func (r *InflightRegistry) Cancel(id uint64) bool {
+	r.mu.Lock()
+	op, ok := r.ops[id]
+	r.mu.Unlock()
+
+	if ok {
+		op.cancel()
+	}
+	return ok
+}
+
+// CancelAll cancels the contexts of all operations in flight, returning the
+// number canceled. Operations invoked after CancelAll returns are not
+// canceled.
+func (r *InflightRegistry) CancelAll() int {
+	r.mu.Lock()
+	ops := make([]*inflightOperation, 0, len(r.ops))
+	for _, op := range r.ops {
+		ops = append(ops, op)
+	}
+	r.mu.Unlock()
+
+	for _, op := range ops {
+		op.cancel()
+	}
+	return len(ops)
+}
+
+// Wait blocks until no operations are in flight, or the context is done,
+// returning the context's error if so.
+func (r *InflightRegistry) Wait(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		if len(r.ops) == 0 {
+			r.mu.Unlock()
+			return nil
+		}
+		if r.done == nil {
+			r.done = make(chan struct{})
+		}
+		done := r.done
+		r.mu.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (r *InflightRegistry) register(ctx context.Context, stackID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	r.mu.Lock()
+	r.nextID++
+	op := &inflightOperation{
+		InflightOperation: InflightOperation{ID: r.nextID, Stack: stackID, Started: time.Now()},
+		cancel:            cancel,
+	}
+	r.ops[op.ID] = op
+	r.mu.Unlock()
+
+	return ctx, func() {
+		cancel()
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.ops, op.ID)
+		if len(r.ops) == 0 && r.done != nil {
+			close(r.done)
+			r.done = nil
+		}
+	}
+}
+
+// AddInflightMiddleware adds a middleware to the front of the Initialize
+// step of the stack that tracks each invocation of the stack in the
+// registry while it is in flight, invoking the stack with a context the
+// registry can cancel.
+//
+// The context of an invocation is canceled when the invocation returns, such
+// that it must not be added to the stacks of operations whose output is read
+// after they return, e.g. a streaming response body.
+func AddInflightMiddleware(stack *Stack, registry *InflightRegistry) error {
+	return stack.Initialize.Add(&inflightMiddleware{
+		stackID:  stack.ID(),
+		registry: registry,
+	}, Before)
+}
+
+type inflightMiddleware struct {
+	stackID  string
+	registry *InflightRegistry
+}
+
+// ID returns the identifier for the middleware.
+func (*inflightMiddleware) ID() string {
+	return "Inflight"
+}
+
+func (m *inflightMiddleware) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	ctx, done := m.registry.register(ctx, m.stackID)
+	defer done()
+
+	return next.HandleInitialize(ctx, in)
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInflightRegistry(t *testing.T) {
+	registry := NewInflightRegistry()
+
+	started := make(chan struct{})
+	newStack := func(id string) *Stack {
+		s := NewStack(id, func() interface{} { return struct{}{} })
+		AddInflightMiddleware(s, registry)
+		return s
+	}
+	handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, Metadata{}, ctx.Err()
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for _, s := range []*Stack{newStack("GetFoo"), newStack("GetBar")} {
+		wg.Add(1)
+		go func(s *Stack) {
+			defer wg.Done()
+			_, _, err := s.HandleMiddleware(context.Background(), struct{}{}, handler)
+			errs <- err
+		}(s)
+		<-started
+	}
+
+	ops := registry.List()
+	if e, a := 2, len(ops); e != a {
+		t.Fatalf("expect %v in flight, got %v", e, a)
+	}
+	if e, a := "GetFoo", ops[0].Stack; e != a {
+		t.Errorf("expect first %v, got %v", e, a)
+	}
+	if e, a := "GetBar", ops[1].Stack; e != a {
+		t.Errorf("expect second %v, got %v", e, a)
+	}
+
+	if !registry.Cancel(ops[0].ID) {
+		t.Errorf("expect operation canceled")
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("expect %v, got %v", context.Canceled, err)
+	}
+
+	if e, a := 1, registry.CancelAll(); e != a {
+		t.Errorf("expect %v canceled, got %v", e, a)
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("expect %v, got %v", context.Canceled, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := registry.Wait(ctx); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	wg.Wait()
+
+	if e, a := 0, len(registry.List()); e != a {
+		t.Errorf("expect %v in flight, got %v", e, a)
+	}
+	if registry.Cancel(ops[0].ID) {
+		t.Errorf("expect operation not in flight")
+	}
+}
+
+func TestInflightRegistry_WaitTimeout(t *testing.T) {
+	registry := NewInflightRegistry()
+	_, done := registry.register(context.Background(), "GetFoo")
+	defer done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := registry.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+}
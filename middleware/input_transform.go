@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// InputTransformFunc transforms the input parameters of an operation in
+// place, e.g. setting the default value of a member.
+type InputTransformFunc func(ctx context.Context, input interface{}) error
+
+// InputTransformOptions is the set of options that can be configured for an
+// input transform.
+type InputTransformOptions struct {
+	// Transforms the input parameters the operation was invoked with, rather
+	// than a deep copy of them. Must only be set if the caller does not
+	// retain, or share, the input parameters.
+	InPlace bool
+}
+
+// InputTransformMiddleware transforms the input parameters of an operation
+// with transforms added by AddInputTransform, in the order they were added.
+//
+// Unless a transform is in place, see InputTransformOptions, it receives a
+// deep copy of the input parameters, such that the caller's input is not
+// modified. The copy is made once per invocation, before the first transform
+// that is not in place, and is the input of the middleware that follow.
+//
+// The copy is of the exported fields of structs, and the elements of
+// pointers, slices, maps, and arrays. Values of interface, func, and channel
+// type, e.g. an io.Reader, are not copied, nor are pointers to structs
+// without exported fields, e.g. *big.Int, which are shared by the copy. The
+// unexported fields of structs are copied as is.
+type InputTransformMiddleware struct {
+	transforms []inputTransform
+}
+
+type inputTransform struct {
+	fn      InputTransformFunc
+	options InputTransformOptions
+}
+
+// AddInputTransform adds the transform to the stack's
+// InputTransformMiddleware, adding the middleware to the end of the
+// Initialize step of the stack if not present.
+func AddInputTransform(stack *Stack, fn InputTransformFunc, optFns ...func(*InputTransformOptions)) error {
+	var o InputTransformOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	t := inputTransform{fn: fn, options: o}
+
+	var m InputTransformMiddleware
+	found, ok := stack.Initialize.Get(m.ID())
+	if !ok {
+		m.transforms = []inputTransform{t}
+		return stack.Initialize.Add(&m, After)
+	}
+	existing, ok := found.(*InputTransformMiddleware)
+	if !ok {
+		return fmt.Errorf("middleware %s is a %T, not an InputTransformMiddleware", m.ID(), found)
+	}
+	n := len(existing.transforms)
+	m.transforms = append(existing.transforms[:n:n], t)
+
+	// the middleware is replaced, rather than modified, as it may be shared
+	// by a clone of the stack
+	_, err := stack.Initialize.Swap(m.ID(), &m)
+	return err
+}
+
+// ID returns the identifier for the middleware.
+func (*InputTransformMiddleware) ID() string {
+	return "InputTransform"
+}
+
+// HandleInitialize transforms the input parameters, invoking the next
+// handler with the transformed parameters.
+func (m *InputTransformMiddleware) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	copied := false
+	for _, t := range m.transforms {
+		if !t.options.InPlace && !copied {
+			in.Parameters = deepCopy(in.Parameters)
+			copied = true
+		}
+		if err := t.fn(ctx, in.Parameters); err != nil {
+			return out, metadata, fmt.Errorf("failed to transform input, %w", err)
+		}
+	}
+	return next.HandleInitialize(ctx, in)
+}
+
+// deepCopy returns a deep copy of v, see InputTransformMiddleware.
+func deepCopy(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	c := copier{pointers: map[uintptr]reflect.Value{}}
+	return c.copy(reflect.ValueOf(v)).Interface()
+}
+
+type copier struct {
+	// copies of the pointers copied, such that cycles and shared pointers
+	// are preserved
+	pointers map[uintptr]reflect.Value
+}
+
+func (c *copier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || isOpaque(v.Type().Elem()) {
+			return v
+		}
+		if p, ok := c.pointers[v.Pointer()]; ok && p.Type() == v.Type() {
+			return p
+		}
+		p := reflect.New(v.Type().Elem())
+		c.pointers[v.Pointer()] = p
+		p.Elem().Set(c.copy(v.Elem()))
+		return p
+
+	case reflect.Struct:
+		s := reflect.New(v.Type()).Elem()
+		s.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				s.Field(i).Set(c.copy(v.Field(i)))
+			}
+		}
+		return s
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return s
+
+	case reflect.Array:
+		a := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			a.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return a
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			m.SetMapIndex(iter.Key(), c.copy(iter.Value()))
+		}
+		return m
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		i := reflect.New(v.Type()).Elem()
+		i.Set(c.copy(v.Elem()))
+		return i
+
+	default:
+		return v
+	}
+}
+
+// isOpaque returns whether the type is a struct with fields, none of which
+// are exported.
+func isOpaque(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() == 0 {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type transformInput struct {
+	Name    *string
+	Tags    []string
+	Attrs   map[string]*string
+	Nested  *transformInput
+	Array   [2]*int
+	Big     *big.Int
+	Body    interface{}
+	private int
+}
+
+func TestDeepCopy(t *testing.T) {
+	name, attr, n := "foo", "bar", 1
+	body := strings.NewReader("body")
+	input := &transformInput{
+		Name:    &name,
+		Tags:    []string{"a", "b"},
+		Attrs:   map[string]*string{"k": &attr},
+		Nested:  &transformInput{Name: &name},
+		Array:   [2]*int{&n},
+		Big:     big.NewInt(1),
+		Body:    body,
+		private: 2,
+	}
+	input.Nested.Nested = input
+
+	c := deepCopy(input).(*transformInput)
+	if !reflect.DeepEqual(input, c) {
+		t.Fatalf("expect copy equal to input")
+	}
+
+	if c == input || c.Name == input.Name || c.Nested == input.Nested || c.Array[0] == input.Array[0] ||
+		c.Attrs["k"] == input.Attrs["k"] || &c.Tags[0] == &input.Tags[0] {
+		t.Errorf("expect pointers, slices, and maps to be copied")
+	}
+	if c.Nested.Nested != c {
+		t.Errorf("expect cycle to be preserved")
+	}
+	if c.Nested.Name != c.Name {
+		t.Errorf("expect shared pointer to be preserved")
+	}
+	if c.Big != input.Big || c.Body != input.Body {
+		t.Errorf("expect opaque values to be shared")
+	}
+	if e, a := 2, c.private; e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	if v := deepCopy(nil); v != nil {
+		t.Errorf("expect nil, got %v", v)
+	}
+}
+
+type transformUnion interface {
+	isTransformUnion()
+}
+
+type transformMemberS struct {
+	Value string
+}
+
+func (*transformMemberS) isTransformUnion() {}
+
+type transformMemberN struct {
+	Value int
+}
+
+func (transformMemberN) isTransformUnion() {}
+
+func TestDeepCopy_Interface(t *testing.T) {
+	input := map[string]transformUnion{
+		"s":   &transformMemberS{Value: "foo"},
+		"n":   transformMemberN{Value: 1},
+		"nil": nil,
+	}
+
+	c := deepCopy(input).(map[string]transformUnion)
+	if !reflect.DeepEqual(input, c) {
+		t.Fatalf("expect copy equal to input")
+	}
+
+	c["s"].(*transformMemberS).Value = "bar"
+	if e, a := "foo", input["s"].(*transformMemberS).Value; e != a {
+		t.Errorf("expect union member to be copied, got %v", a)
+	}
+}
+
+func TestAddInputTransform(t *testing.T) {
+	setName := func(v string) InputTransformFunc {
+		return func(ctx context.Context, input interface{}) error {
+			*input.(*transformInput).Name += v
+			return nil
+		}
+	}
+
+	cases := map[string]struct {
+		inPlace bool
+	}{
+		"copy":     {},
+		"in place": {inPlace: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			if err := AddInputTransform(s, setName("-a"), func(o *InputTransformOptions) {
+				o.InPlace = c.inPlace
+			}); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			clone := s.Clone()
+			if err := AddInputTransform(s, setName("-b")); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var received *transformInput
+			receive := SerializeMiddlewareFunc("receive", func(
+				ctx context.Context, in SerializeInput, next SerializeHandler,
+			) (SerializeOutput, Metadata, error) {
+				received = in.Parameters.(*transformInput)
+				return next.HandleSerialize(ctx, in)
+			})
+			s.Serialize.Add(receive, After)
+			clone.Serialize.Add(receive, After)
+
+			handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+				return nil, Metadata{}, nil
+			})
+
+			v := "foo"
+			input := &transformInput{Name: &v}
+			if _, _, err := s.HandleMiddleware(context.Background(), input, handler); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			expectInput := "foo"
+			if c.inPlace {
+				expectInput = "foo-a"
+			}
+			if e, a := expectInput, *input.Name; e != a {
+				t.Errorf("expect input %v, got %v", e, a)
+			}
+			if e, a := "foo-a-b", *received.Name; e != a {
+				t.Errorf("expect received %v, got %v", e, a)
+			}
+
+			// the clone is not modified by transforms added to the stack
+			v = "foo"
+			if _, _, err := clone.HandleMiddleware(context.Background(), input, handler); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := "foo-a", *received.Name; e != a {
+				t.Errorf("expect clone received %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestAddInputTransform_Error(t *testing.T) {
+	transformErr := errors.New("invalid input")
+
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+	AddInputTransform(s, func(ctx context.Context, input interface{}) error {
+		return transformErr
+	})
+
+	_, _, err := s.HandleMiddleware(context.Background(), &transformInput{}, HandlerFunc(
+		func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			t.Errorf("expect handler not to be invoked")
+			return nil, Metadata{}, nil
+		}))
+	if !errors.Is(err, transformErr) {
+		t.Errorf("expect %v, got %v", transformErr, err)
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+// MetadataKey is a typed key of a Metadata value, such that values are set
+// and retrieved as their type without type assertions, e.g.
+//
+//	var attemptsKey = middleware.NewMetadataKey[int]("Attempts")
+//
+//	attemptsKey.Set(&metadata, 3)
+//	attempts, ok := attemptsKey.Get(metadata)
+//
+// Keys are compared by identity, such that two keys created with the same
+// name are distinct. A key should be created once, as a package variable.
+type MetadataKey[T any] struct {
+	name string
+}
+
+// NewMetadataKey returns a new key of values of type T. The name describes
+// the key, e.g. in debugging output.
+func NewMetadataKey[T any](name string) *MetadataKey[T] {
+	return &MetadataKey[T]{name: name}
+}
+
+// Get returns the value of the key, and whether the metadata has a value of
+// the key.
+func (k *MetadataKey[T]) Get(m MetadataReader) (T, bool) {
+	v, ok := m.Get(k).(T)
+	return v, ok
+}
+
+// Set sets the value of the key, replacing any existing value.
+func (k *MetadataKey[T]) Set(m *Metadata, v T) {
+	m.Set(k, v)
+}
+
+// Has returns whether the metadata has a value of the key.
+func (k *MetadataKey[T]) Has(m MetadataReader) bool {
+	_, ok := k.Get(m)
+	return ok
+}
+
+// String returns the name of the key.
+func (k *MetadataKey[T]) String() string {
+	return k.name
+}
@@ -28,3 +28,40 @@ func TestMetadataClone(t *testing.T) {
 		t.Errorf("expect cloned metadata to not leak in to original")
 	}
 }
+
+func TestMetadataKey(t *testing.T) {
+	countKey := NewMetadataKey[int]("Count")
+	otherKey := NewMetadataKey[int]("Count")
+	nameKey := NewMetadataKey[string]("Name")
+
+	var m Metadata
+	if countKey.Has(m) {
+		t.Errorf("expect no value of key")
+	}
+	if v, ok := countKey.Get(m); ok || v != 0 {
+		t.Errorf("expect zero value not found, got %v, %v", v, ok)
+	}
+
+	countKey.Set(&m, 3)
+	nameKey.Set(&m, "foo")
+
+	if v, ok := countKey.Get(m); !ok || v != 3 {
+		t.Errorf("expect 3 found, got %v, %v", v, ok)
+	}
+	if v, ok := nameKey.Get(&m); !ok || v != "foo" {
+		t.Errorf("expect foo found, got %v, %v", v, ok)
+	}
+	if otherKey.Has(m) {
+		t.Errorf("expect keys of the same name to be distinct")
+	}
+
+	// values set without the key are not of the key
+	m.Set("Count", "bar")
+	if v, ok := countKey.Get(m); !ok || v != 3 {
+		t.Errorf("expect 3 found, got %v, %v", v, ok)
+	}
+
+	if e, a := "Count", countKey.String(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MiddlewareError is the error of a middleware of a stack, with the step and
+// ID of the middleware the error originates from, returned if enabled for
+// the context of the stack's invocation with WithMiddlewareErrors. Use
+// errors.As to get the step and ID of the middleware an error originates
+// from, e.g. to branch on it in a retry or logging layer.
+type MiddlewareError struct {
+	// The step of the middleware, one of "Initialize", "Serialize", "Build",
+	// "Finalize", or "Deserialize".
+	Step string
+
+	// The ID of the middleware.
+	ID string
+
+	// The error returned by the middleware.
+	Err error
+}
+
+func (e *MiddlewareError) Error() string {
+	return fmt.Sprintf("%s middleware %s failed, %v", e.Step, e.ID, e.Err)
+}
+
+// Unwrap returns the error returned by the middleware.
+func (e *MiddlewareError) Unwrap() error { return e.Err }
+
+// WithMiddlewareErrors returns a context that enables wrapping the errors of
+// the middleware of stacks invoked with the context in a *MiddlewareError
+// with the middleware the error originates from.
+//
+// An error is wrapped by the middleware that returns it first. An error that
+// is returned, or wrapped, by the middleware that invoked it is not wrapped
+// again, and neither are errors of the stack's handler.
+func WithMiddlewareErrors(ctx context.Context) context.Context {
+	return withDecorations(ctx, func(d *decorations) { d.errors = true })
+}
+
+// errorAttributor wraps the errors of the middleware of a stack invocation.
+type errorAttributor struct {
+	// the last error returned, such that it is not wrapped again as it is
+	// returned through the middleware that invoked the one it originates
+	// from
+	mu   sync.Mutex
+	last error
+}
+
+// stepErrors wraps the errors of the middleware of the invocation of a step.
+type stepErrors struct {
+	attributor *errorAttributor
+	step       string
+}
+
+// step returns the attributor of errors of the step, or nil if the
+// attributor is nil.
+func (a *errorAttributor) step(step string) *stepErrors {
+	if a == nil {
+		return nil
+	}
+	return &stepErrors{attributor: a, step: step}
+}
+
+// enter is called when a middleware or the handler is invoked.
+func (a *errorAttributor) enter() {
+	a.mu.Lock()
+	a.last = nil
+	a.mu.Unlock()
+}
+
+// returned is called with the error a middleware, or the handler if step is
+// empty, returns. Returns the error wrapped in a *MiddlewareError if it
+// originates from the middleware.
+func (a *errorAttributor) returned(step, id string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(step) == 0 || (a.last != nil && errors.Is(err, a.last)) {
+		a.last = err
+		return err
+	}
+
+	wrapped := &MiddlewareError{Step: step, ID: id, Err: err}
+	a.last = wrapped
+	return wrapped
+}
+
+// enter is called when a middleware of the step is invoked.
+func (e *stepErrors) enter() {
+	e.attributor.enter()
+}
+
+// returned is called with the error the middleware of the step returns.
+func (e *stepErrors) returned(id string, err error) error {
+	return e.attributor.returned(e.step, id, err)
+}
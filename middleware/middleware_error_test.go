@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestMiddlewareError(t *testing.T) {
+	finalizeErr := errors.New("finalize failed")
+	handlerErr := errors.New("handler failed")
+	deserializeErr := errors.New("deserialize failed")
+
+	cases := map[string]struct {
+		disabled       bool
+		finalizeErr    error
+		handlerErr     error
+		deserializeErr error
+		expect         *MiddlewareError
+		expectErr      error
+	}{
+		"no error": {},
+		"middleware error": {
+			finalizeErr: finalizeErr,
+			expect:      &MiddlewareError{Step: "Finalize", ID: "finalize"},
+			expectErr:   finalizeErr,
+		},
+		"handler error": {
+			handlerErr: handlerErr,
+			expectErr:  handlerErr,
+		},
+		"handler error replaced": {
+			handlerErr:     handlerErr,
+			deserializeErr: deserializeErr,
+			expect:         &MiddlewareError{Step: "Deserialize", ID: "deserialize"},
+			expectErr:      deserializeErr,
+		},
+		"disabled": {
+			disabled:    true,
+			finalizeErr: finalizeErr,
+			expectErr:   finalizeErr,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			s.Initialize.Add(InitializeMiddlewareFunc("initialize", func(
+				ctx context.Context, in InitializeInput, next InitializeHandler,
+			) (InitializeOutput, Metadata, error) {
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				if err != nil {
+					return out, metadata, fmt.Errorf("initialize: %w", err)
+				}
+				return out, metadata, err
+			}), After)
+			s.Finalize.Add(FinalizeMiddlewareFunc("finalize", func(
+				ctx context.Context, in FinalizeInput, next FinalizeHandler,
+			) (FinalizeOutput, Metadata, error) {
+				if c.finalizeErr != nil {
+					return FinalizeOutput{}, Metadata{}, c.finalizeErr
+				}
+				return next.HandleFinalize(ctx, in)
+			}), After)
+			s.Deserialize.Add(DeserializeMiddlewareFunc("deserialize", func(
+				ctx context.Context, in DeserializeInput, next DeserializeHandler,
+			) (DeserializeOutput, Metadata, error) {
+				out, metadata, err := next.HandleDeserialize(ctx, in)
+				if err != nil && c.deserializeErr != nil {
+					return out, metadata, c.deserializeErr
+				}
+				return out, metadata, err
+			}), After)
+
+			ctx := context.Background()
+			if !c.disabled {
+				ctx = WithMiddlewareErrors(ctx)
+			}
+			_, _, err := s.HandleMiddleware(ctx, struct{}{}, HandlerFunc(func(
+				ctx context.Context, input interface{},
+			) (interface{}, Metadata, error) {
+				return nil, Metadata{}, c.handlerErr
+			}))
+			if c.expectErr == nil {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, c.expectErr) {
+				t.Fatalf("expect %v error, got %v", c.expectErr, err)
+			}
+
+			var merr *MiddlewareError
+			if ok := errors.As(err, &merr); ok != (c.expect != nil) {
+				t.Fatalf("expect middleware error %v, got %v", c.expect != nil, err)
+			}
+			if c.expect == nil {
+				return
+			}
+			if e, a := c.expect.Step, merr.Step; e != a {
+				t.Errorf("expect %v step, got %v", e, a)
+			}
+			if e, a := c.expect.ID, merr.ID; e != a {
+				t.Errorf("expect %v ID, got %v", e, a)
+			}
+			if e, a := c.expectErr, merr.Err; e != a {
+				t.Errorf("expect %v error, got %v", e, a)
+			}
+		})
+	}
+}
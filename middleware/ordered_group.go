@@ -1,6 +1,9 @@
 package middleware
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
 // RelativePosition provides specifying the relative position of a middleware
 // in an ordered group.
@@ -12,29 +15,79 @@ const (
 	Before
 )
 
+func (p RelativePosition) String() string {
+	switch p {
+	case After:
+		return "After"
+	case Before:
+		return "Before"
+	default:
+		return fmt.Sprintf("RelativePosition(%d)", int(p))
+	}
+}
+
 type ider interface {
 	ID() string
 }
 
+// Prioritizer is implemented by middleware with a priority, which orders
+// middleware added at the same position relative to the same middleware, or
+// to the same end of a step. Middleware with a lower priority are invoked
+// first. Middleware that do not implement Prioritizer have a priority of 0.
+//
+// For example, middleware inserted After "Signing" with priorities 10 and -10
+// are invoked in the order -10, 10, after "Signing", regardless of the order
+// they are inserted in. Middleware of equal priority are ordered as if they
+// had no priority, e.g. each middleware inserted After "Signing" is invoked
+// before those inserted after it earlier.
+type Prioritizer interface {
+	Priority() int
+}
+
+func priorityOf(m ider) int {
+	if p, ok := m.(Prioritizer); ok {
+		return p.Priority()
+	}
+	return 0
+}
+
 // orderedIDs provides an ordered collection of items with relative ordering
-// by name.
+// by name. It is safe for concurrent use, such that items may be added or
+// removed while the order is read by in-flight invocations of a stack.
 type orderedIDs struct {
+	mu sync.RWMutex
+
 	order *relativeOrder
 	items map[string]ider
+
+	// how each item was placed, for describing the order
+	placements map[string]placement
+}
+
+// placement is the position an item was added at, relative to another item,
+// or to the group if relativeTo is empty.
+type placement struct {
+	pos        RelativePosition
+	relativeTo string
+	priority   int
 }
 
 const baseOrderedItems = 5
 
 func newOrderedIDs() *orderedIDs {
 	return &orderedIDs{
-		order: newRelativeOrder(),
-		items: make(map[string]ider, baseOrderedItems),
+		order:      newRelativeOrder(),
+		items:      make(map[string]ider, baseOrderedItems),
+		placements: make(map[string]placement, baseOrderedItems),
 	}
 }
 
 // Add injects the item to the relative position of the item group. Returns an
 // error if the item already exists.
 func (g *orderedIDs) Add(m ider, pos RelativePosition) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	id := m.ID()
 	if len(id) == 0 {
 		return fmt.Errorf("empty ID, ID must not be empty")
@@ -45,12 +98,17 @@ func (g *orderedIDs) Add(m ider, pos RelativePosition) error {
 	}
 
 	g.items[id] = m
+	g.placements[id] = placement{pos: pos, priority: priorityOf(m)}
+	g.prioritize(id)
 	return nil
 }
 
 // Insert injects the item relative to an existing item id. Returns an error if
 // the original item does not exist, or the item being added already exists.
 func (g *orderedIDs) Insert(m ider, relativeTo string, pos RelativePosition) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	if len(m.ID()) == 0 {
 		return fmt.Errorf("insert ID must not be empty")
 	}
@@ -63,11 +121,52 @@ func (g *orderedIDs) Insert(m ider, relativeTo string, pos RelativePosition) err
 	}
 
 	g.items[m.ID()] = m
+	g.placements[m.ID()] = placement{pos: pos, relativeTo: relativeTo, priority: priorityOf(m)}
+	g.prioritize(m.ID())
 	return nil
 }
 
+// prioritize moves the item just placed past the items placed at the same
+// position relative to the same item, or end of the group, that are invoked
+// before it by priority, see Prioritizer.
+//
+// An item is placed nearest to what it is relative to, after an item, or at
+// the front of the group, such that it moves forward past items of lower
+// priority, and is otherwise placed furthest from it, such that it moves
+// backward past items of higher priority.
+func (g *orderedIDs) prioritize(id string) {
+	p := g.placements[id]
+	order := g.order.order
+	i, _ := g.order.has(id)
+
+	sameAnchor := func(j int) (placement, bool) {
+		if j < 0 || j >= len(order) {
+			return placement{}, false
+		}
+		q := g.placements[order[j]]
+		return q, q.pos == p.pos && q.relativeTo == p.relativeTo
+	}
+
+	forward := (len(p.relativeTo) != 0) == (p.pos == After)
+	for {
+		j := i - 1
+		if forward {
+			j = i + 1
+		}
+		q, ok := sameAnchor(j)
+		if !ok || (forward && q.priority >= p.priority) || (!forward && q.priority <= p.priority) {
+			return
+		}
+		order[i], order[j] = order[j], order[i]
+		i = j
+	}
+}
+
 // Get returns the ider identified by id. If ider is not present, returns false.
 func (g *orderedIDs) Get(id string) (ider, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
 	v, ok := g.items[id]
 	return v, ok
 }
@@ -75,6 +174,9 @@ func (g *orderedIDs) Get(id string) (ider, bool) {
 // Swap removes the item by id, replacing it with the new item. Returns an error
 // if the original item doesn't exist.
 func (g *orderedIDs) Swap(id string, m ider) (ider, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	if len(id) == 0 {
 		return nil, fmt.Errorf("swap from ID must not be empty")
 	}
@@ -89,9 +191,22 @@ func (g *orderedIDs) Swap(id string, m ider) (ider, error) {
 	}
 
 	removed := g.items[id]
+	p := g.placements[id]
+	p.priority = priorityOf(m)
 
 	delete(g.items, id)
+	delete(g.placements, id)
 	g.items[iderID] = m
+	g.placements[iderID] = p
+
+	// items placed relative to the swapped item are relative to its
+	// replacement
+	for k, p := range g.placements {
+		if p.relativeTo == id {
+			p.relativeTo = iderID
+			g.placements[k] = p
+		}
+	}
 
 	return removed, nil
 }
@@ -99,6 +214,9 @@ func (g *orderedIDs) Swap(id string, m ider) (ider, error) {
 // Remove removes the item by id. Returns an error if the item
 // doesn't exist.
 func (g *orderedIDs) Remove(id string) (ider, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	if len(id) == 0 {
 		return nil, fmt.Errorf("remove ID must not be empty")
 	}
@@ -109,10 +227,14 @@ func (g *orderedIDs) Remove(id string) (ider, error) {
 
 	removed := g.items[id]
 	delete(g.items, id)
+	delete(g.placements, id)
 	return removed, nil
 }
 
 func (g *orderedIDs) List() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
 	items := g.order.List()
 	order := make([]string, len(items))
 	copy(order, items)
@@ -121,12 +243,57 @@ func (g *orderedIDs) List() []string {
 
 // Clear removes all entries and slots.
 func (g *orderedIDs) Clear() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	g.order.Clear()
 	g.items = map[string]ider{}
+	g.placements = map[string]placement{}
+}
+
+// clone returns a copy of the group, which shares no state with it.
+func (g *orderedIDs) clone() *orderedIDs {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.cloneLocked()
+}
+
+// cloneLocked is clone for a caller that holds the lock of the group.
+func (g *orderedIDs) cloneLocked() *orderedIDs {
+	c := &orderedIDs{
+		order:      &relativeOrder{order: append([]string(nil), g.order.order...)},
+		items:      make(map[string]ider, len(g.items)),
+		placements: make(map[string]placement, len(g.placements)),
+	}
+	for k, v := range g.items {
+		c.items[k] = v
+	}
+	for k, v := range g.placements {
+		c.placements[k] = v
+	}
+	return c
+}
+
+// listPlacements returns the IDs of the items in order, and how each was
+// placed.
+func (g *orderedIDs) listPlacements() ([]string, []placement) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	ids := append([]string(nil), g.order.List()...)
+	placements := make([]placement, len(ids))
+	for i, id := range ids {
+		placements[i] = g.placements[id]
+	}
+	return ids, placements
 }
 
 // GetOrder returns the item in the order it should be invoked in.
 func (g *orderedIDs) GetOrder() []interface{} {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
 	order := g.order.List()
 	ordered := make([]interface{}, len(order))
 	for i := 0; i < len(order); i++ {
@@ -241,3 +241,83 @@ func compareGetOrder(t *testing.T, expected []string, actual []interface{}) {
 		}
 	}
 }
+
+type mockPrioritizer struct {
+	mockIder
+	priority int
+}
+
+func (m *mockPrioritizer) Priority() int { return m.priority }
+
+func TestOrderedIDsPriority(t *testing.T) {
+	prioritized := func(id string, priority int) ider {
+		return &mockPrioritizer{mockIder: mockIder{id}, priority: priority}
+	}
+
+	cases := map[string]struct {
+		add    func(*orderedIDs)
+		expect []string
+	}{
+		"insert after": {
+			add: func(o *orderedIDs) {
+				noError(t, o.Insert(prioritized("b10", 10), "anchor", After))
+				noError(t, o.Insert(prioritized("b-10", -10), "anchor", After))
+				noError(t, o.Insert(&mockIder{"b0"}, "anchor", After))
+				noError(t, o.Insert(prioritized("b5", 5), "anchor", After))
+			},
+			expect: []string{"front", "anchor", "b-10", "b0", "b5", "b10", "back"},
+		},
+		"insert before": {
+			add: func(o *orderedIDs) {
+				noError(t, o.Insert(prioritized("a-10", -10), "anchor", Before))
+				noError(t, o.Insert(prioritized("a10", 10), "anchor", Before))
+				noError(t, o.Insert(&mockIder{"a0"}, "anchor", Before))
+			},
+			expect: []string{"front", "a-10", "a0", "a10", "anchor", "back"},
+		},
+		"add after": {
+			add: func(o *orderedIDs) {
+				noError(t, o.Add(prioritized("z10", 10), After))
+				noError(t, o.Add(prioritized("z-10", -10), After))
+			},
+			// invoked before the middleware added After without priority
+			expect: []string{"z-10", "front", "anchor", "back", "z10"},
+		},
+		"add before": {
+			add: func(o *orderedIDs) {
+				noError(t, o.Add(prioritized("y-10", -10), Before))
+				noError(t, o.Add(prioritized("y10", 10), Before))
+			},
+			expect: []string{"y-10", "y10", "front", "anchor", "back"},
+		},
+		"equal priority keeps insertion rule": {
+			add: func(o *orderedIDs) {
+				noError(t, o.Insert(prioritized("first", 1), "anchor", After))
+				noError(t, o.Insert(prioritized("second", 1), "anchor", After))
+			},
+			expect: []string{"front", "anchor", "second", "first", "back"},
+		},
+		"other anchors not reordered": {
+			add: func(o *orderedIDs) {
+				noError(t, o.Insert(prioritized("b10", 10), "anchor", After))
+				noError(t, o.Insert(prioritized("c", -10), "b10", After))
+				noError(t, o.Insert(prioritized("b5", 5), "anchor", After))
+			},
+			expect: []string{"front", "anchor", "b5", "b10", "c", "back"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			o := newOrderedIDs()
+			noError(t, o.Add(&mockIder{"front"}, After))
+			noError(t, o.Add(&mockIder{"anchor"}, After))
+			noError(t, o.Add(&mockIder{"back"}, After))
+
+			c.add(o)
+			if e, a := c.expect, o.List(); !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
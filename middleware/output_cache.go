@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go/container/private/cache"
+	"github.com/aws/smithy-go/container/private/cache/lru"
+)
+
+// DefaultOutputCacheMaxEntries is the maximum number of entries of an
+// OutputCache if not configured.
+const DefaultOutputCacheMaxEntries = 1000
+
+// OutputCacheOptions is the set of options that can be configured for an
+// OutputCache.
+type OutputCacheOptions struct {
+	// Returns the key of the serialized request, e.g. its method and URL, and
+	// whether its output may be cached. The key is scoped to the ID of the
+	// stack the request is of. Required.
+	Key func(ctx context.Context, request interface{}) (string, bool)
+
+	// The duration an output is cached for. A value of 0 (the default)
+	// caches outputs until they are evicted.
+	TTL time.Duration
+
+	// The maximum number of outputs cached, evicting the least recently used
+	// output when exceeded. Defaults to DefaultOutputCacheMaxEntries.
+	MaxEntries int
+}
+
+// OutputCache caches the deserialized outputs of operations, such that
+// serialized requests with the same key return the cached output without
+// being sent, see AddOutputCacheMiddleware. An OutputCache is safe for
+// concurrent use, and is shared by the stacks of the operations it caches.
+//
+// Only outputs of successful invocations are cached, with the metadata they
+// were returned with. A cached output is returned as is by each request of
+// its key, and must not be modified. Each request is returned a copy of the
+// cached metadata. Outputs with a stream member, e.g. the body of a response,
+// are not cached, as the stream is consumed by the caller it is returned to.
+type OutputCache struct {
+	options OutputCacheOptions
+
+	mu      sync.Mutex
+	entries cache.Cache
+
+	// returns the current time, for tests
+	now func() time.Time
+}
+
+type outputCacheKey struct {
+	stackID string
+	key     string
+}
+
+type outputCacheEntry struct {
+	result   interface{}
+	metadata Metadata
+	expires  time.Time
+}
+
+// NewOutputCache returns an empty OutputCache.
+func NewOutputCache(optFns ...func(*OutputCacheOptions)) (*OutputCache, error) {
+	var o OutputCacheOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	if o.Key == nil {
+		return nil, fmt.Errorf("output cache key func is required")
+	}
+	if o.MaxEntries <= 0 {
+		o.MaxEntries = DefaultOutputCacheMaxEntries
+	}
+
+	return &OutputCache{
+		options: o,
+		entries: lru.New(o.MaxEntries),
+		now:     time.Now,
+	}, nil
+}
+
+// Clear removes all cached outputs.
+func (c *OutputCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = lru.New(c.options.MaxEntries)
+}
+
+func (c *OutputCache) get(k outputCacheKey) (interface{}, Metadata, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.entries.Get(k)
+	if !ok {
+		return nil, Metadata{}, false
+	}
+	entry := v.(*outputCacheEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		return nil, Metadata{}, false
+	}
+	return entry.result, entry.metadata.Clone(), true
+}
+
+func (c *OutputCache) put(k outputCacheKey, result interface{}, metadata Metadata) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &outputCacheEntry{result: result, metadata: metadata.Clone()}
+	if c.options.TTL > 0 {
+		entry.expires = c.now().Add(c.options.TTL)
+	}
+	c.entries.Put(k, entry)
+}
+
+// AddOutputCacheMiddleware adds a middleware to the front of the Deserialize
+// step of the stack that returns the output cached in the cache for the key
+// of the serialized request, rather than sending it, and caches the output of
+// requests that are sent.
+func AddOutputCacheMiddleware(stack *Stack, cache *OutputCache) error {
+	return stack.Deserialize.Add(&outputCacheMiddleware{
+		stackID: stack.ID(),
+		cache:   cache,
+	}, Before)
+}
+
+type outputCacheMiddleware struct {
+	stackID string
+	cache   *OutputCache
+}
+
+// ID returns the identifier for the middleware.
+func (*outputCacheMiddleware) ID() string {
+	return "OutputCache"
+}
+
+func (m *outputCacheMiddleware) HandleDeserialize(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+	out DeserializeOutput, metadata Metadata, err error,
+) {
+	key, ok := m.cache.options.Key(ctx, in.Request)
+	if !ok {
+		return next.HandleDeserialize(ctx, in)
+	}
+
+	k := outputCacheKey{stackID: m.stackID, key: key}
+	if result, cached, ok := m.cache.get(k); ok {
+		out.Result = result
+		return out, cached, nil
+	}
+
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err == nil && out.Result != nil && !hasStream(out.Result) {
+		m.cache.put(k, out.Result, metadata)
+	}
+	return out, metadata, err
+}
+
+var (
+	readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	closerType = reflect.TypeOf((*io.Closer)(nil)).Elem()
+)
+
+// hasStream returns whether the output is, or is a struct with a member
+// that is, a stream, i.e. an io.Reader or io.Closer, such as the body of a
+// response or an event stream.
+func hasStream(output interface{}) bool {
+	isStream := func(t reflect.Type) bool {
+		return t.Implements(readerType) || t.Implements(closerType)
+	}
+
+	t := reflect.TypeOf(output)
+	if isStream(t) {
+		return true
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if isStream(t.Field(i).Type) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutputCacheMiddleware(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache, err := NewOutputCache(func(o *OutputCacheOptions) {
+		o.Key = func(ctx context.Context, request interface{}) (string, bool) {
+			key := request.(*string)
+			return *key, *key != "uncached"
+		}
+		o.TTL = time.Minute
+		o.MaxEntries = 2
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	cache.now = func() time.Time { return now }
+
+	sent := 0
+	var sendErr error
+	newStack := func(id string) *Stack {
+		var key string
+		s := NewStack(id, func() interface{} { return &key })
+		s.Serialize.Add(SerializeMiddlewareFunc("serialize", func(
+			ctx context.Context, in SerializeInput, next SerializeHandler,
+		) (SerializeOutput, Metadata, error) {
+			*in.Request.(*string) = in.Parameters.(string)
+			return next.HandleSerialize(ctx, in)
+		}), After)
+		s.Deserialize.Add(DeserializeMiddlewareFunc("deserialize", func(
+			ctx context.Context, in DeserializeInput, next DeserializeHandler,
+		) (DeserializeOutput, Metadata, error) {
+			out, metadata, err := next.HandleDeserialize(ctx, in)
+			if err != nil {
+				return out, metadata, err
+			}
+			out.Result = id + " " + *in.Request.(*string)
+			return out, metadata, nil
+		}), After)
+		AddOutputCacheMiddleware(s, cache)
+		return s
+	}
+	handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		sent++
+		return nil, Metadata{}, sendErr
+	})
+
+	invoke := func(s *Stack, key string, expectSent int) {
+		t.Helper()
+		out, _, err := s.HandleMiddleware(context.Background(), key, handler)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if e, a := s.ID()+" "+key, out; e != a {
+			t.Errorf("expect output %v, got %v", e, a)
+		}
+		if e, a := expectSent, sent; e != a {
+			t.Errorf("expect %v sent, got %v", e, a)
+		}
+	}
+
+	getFoo, getBar := newStack("GetFoo"), newStack("GetBar")
+
+	invoke(getFoo, "a", 1)
+	invoke(getFoo, "a", 1)
+	// keys are scoped to the stack
+	invoke(getBar, "a", 2)
+	invoke(getBar, "a", 2)
+
+	// not cacheable
+	invoke(getFoo, "uncached", 3)
+	invoke(getFoo, "uncached", 4)
+
+	// expired
+	now = now.Add(time.Minute)
+	invoke(getFoo, "a", 5)
+	invoke(getFoo, "a", 5)
+
+	// evicted
+	invoke(getFoo, "b", 6)
+	invoke(getFoo, "c", 7)
+	invoke(getFoo, "a", 8)
+
+	cache.Clear()
+	invoke(getFoo, "a", 9)
+
+	// errors are not cached
+	sendErr = errors.New("failed")
+	if _, _, err := getFoo.HandleMiddleware(context.Background(), "d", handler); err == nil {
+		t.Fatalf("expect error")
+	}
+	sendErr = nil
+	invoke(getFoo, "d", 11)
+}
+
+func TestOutputCacheMiddleware_Metadata(t *testing.T) {
+	type requestIDKey struct{}
+
+	cache, err := NewOutputCache(func(o *OutputCacheOptions) {
+		o.Key = func(ctx context.Context, request interface{}) (string, bool) { return "key", true }
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	m := &outputCacheMiddleware{stackID: "GetFoo", cache: cache}
+
+	sent := 0
+	handler := DeserializeHandlerFunc(func(ctx context.Context, in DeserializeInput) (
+		out DeserializeOutput, metadata Metadata, err error,
+	) {
+		sent++
+		out.Result = "output"
+		metadata.Set(requestIDKey{}, "abc")
+		return out, metadata, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		_, metadata, err := m.HandleDeserialize(context.Background(), DeserializeInput{}, handler)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if e, a := "abc", metadata.Get(requestIDKey{}); e != a {
+			t.Errorf("expect metadata %v, got %v", e, a)
+		}
+		// the cached metadata is not modified by its callers
+		metadata.Set(requestIDKey{}, "modified")
+	}
+	if e, a := 1, sent; e != a {
+		t.Errorf("expect %v sent, got %v", e, a)
+	}
+}
+
+func TestOutputCacheMiddleware_Stream(t *testing.T) {
+	type streamOutput struct {
+		Body io.ReadCloser
+	}
+
+	cache, err := NewOutputCache(func(o *OutputCacheOptions) {
+		o.Key = func(ctx context.Context, request interface{}) (string, bool) { return "key", true }
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	m := &outputCacheMiddleware{stackID: "GetFoo", cache: cache}
+
+	sent := 0
+	handler := DeserializeHandlerFunc(func(ctx context.Context, in DeserializeInput) (
+		out DeserializeOutput, metadata Metadata, err error,
+	) {
+		sent++
+		out.Result = &streamOutput{Body: io.NopCloser(strings.NewReader("body"))}
+		return out, metadata, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := m.HandleDeserialize(context.Background(), DeserializeInput{}, handler); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+	// outputs with a stream member are not cached
+	if e, a := 2, sent; e != a {
+		t.Errorf("expect %v sent, got %v", e, a)
+	}
+}
+
+func TestNewOutputCache_NoKey(t *testing.T) {
+	if _, err := NewOutputCache(); err == nil {
+		t.Errorf("expect error")
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+)
+
+// middlewareOverrides are the groups of middleware layered on the steps of
+// a stack for an invocation.
+type middlewareOverrides []*MiddlewareGroup
+
+// WithMiddlewareOverrides returns a context with the middleware of the group
+// layered on the stack next invoked with it, for that invocation only, e.g.
+// for middleware of the functional options of an operation. The stack is not
+// modified, nor copied, and stacks invoked by its middleware are not
+// overridden.
+//
+// The members of the group are placed in their steps as by Stack.AddGroup,
+// after those of groups given by earlier calls. The invocation fails if a
+// member cannot be placed, e.g. its ID is in its step.
+func WithMiddlewareOverrides(ctx context.Context, g *MiddlewareGroup) context.Context {
+	return withDecorations(ctx, func(d *decorations) {
+		n := len(d.overrides)
+		d.overrides = append(d.overrides[:n:n], g)
+	})
+}
+
+// apply returns the order of the middleware of the step with the members of
+// the overrides of the step placed in it.
+func (o middlewareOverrides) apply(step string, order []interface{}) ([]interface{}, error) {
+	copied := false
+	for _, g := range o {
+		for _, m := range g.members {
+			if m.step != step {
+				continue
+			}
+			if !copied {
+				order = append(make([]interface{}, 0, len(order)+1), order...)
+				copied = true
+			}
+
+			var err error
+			if order, err = placeOverride(order, m); err != nil {
+				return nil, fmt.Errorf("override middleware group %s, add %s to %s step, %w",
+					g.name, m.middleware.ID(), step, err)
+			}
+		}
+	}
+	return order, nil
+}
+
+func placeOverride(order []interface{}, m groupMember) ([]interface{}, error) {
+	id := m.middleware.ID()
+	at := -1
+	for i, v := range order {
+		switch v.(ider).ID() {
+		case id:
+			return nil, fmt.Errorf("already exists, %v", id)
+		case m.relativeTo:
+			at = i
+		}
+	}
+
+	switch {
+	case len(m.relativeTo) == 0 && m.pos == Before:
+		at = 0
+	case len(m.relativeTo) == 0 && m.pos == After:
+		at = len(order)
+	case at < 0:
+		return nil, fmt.Errorf("not found, %v", m.relativeTo)
+	case m.pos == After:
+		at++
+	case m.pos != Before:
+		return nil, fmt.Errorf("invalid position, %v", int(m.pos))
+	}
+
+	order = append(order, nil)
+	copy(order[at+1:], order[at:])
+	order[at] = m.middleware
+	return order, nil
+}
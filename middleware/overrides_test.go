@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestWithMiddlewareOverrides(t *testing.T) {
+	record := func(invoked *[]string, id string) BuildMiddleware {
+		return BuildMiddlewareFunc(id, func(ctx context.Context, in BuildInput, next BuildHandler) (
+			BuildOutput, Metadata, error,
+		) {
+			*invoked = append(*invoked, id)
+			return next.HandleBuild(ctx, in)
+		})
+	}
+
+	cases := map[string]struct {
+		groups    func(invoked *[]string) []*MiddlewareGroup
+		expect    []string
+		expectErr bool
+	}{
+		"none": {
+			groups: func(invoked *[]string) []*MiddlewareGroup { return nil },
+			expect: []string{"first", "second"},
+		},
+		"placed": {
+			groups: func(invoked *[]string) []*MiddlewareGroup {
+				return []*MiddlewareGroup{
+					NewMiddlewareGroup("a").
+						Build(record(invoked, "front"), "", Before).
+						Build(record(invoked, "afterFirst"), "first", After),
+					NewMiddlewareGroup("b").
+						Build(record(invoked, "back"), "", After).
+						Build(record(invoked, "beforeBack"), "back", Before),
+				}
+			},
+			expect: []string{"front", "first", "afterFirst", "second", "beforeBack", "back"},
+		},
+		"duplicate": {
+			groups: func(invoked *[]string) []*MiddlewareGroup {
+				return []*MiddlewareGroup{
+					NewMiddlewareGroup("a").Build(record(invoked, "first"), "", After),
+				}
+			},
+			expectErr: true,
+		},
+		"relative to unknown": {
+			groups: func(invoked *[]string) []*MiddlewareGroup {
+				return []*MiddlewareGroup{
+					NewMiddlewareGroup("a").Build(record(invoked, "x"), "unknown", After),
+				}
+			},
+			expectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var invoked []string
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			s.Build.Add(record(&invoked, "first"), After)
+			s.Build.Add(record(&invoked, "second"), After)
+
+			ctx := context.Background()
+			for _, g := range c.groups(&invoked) {
+				ctx = WithMiddlewareOverrides(ctx, g)
+			}
+
+			handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+				return nil, Metadata{}, nil
+			})
+			_, _, err := s.HandleMiddleware(ctx, struct{}{}, handler)
+			if e, a := c.expectErr, err != nil; e != a {
+				t.Fatalf("expect error %v, got %v", e, err)
+			}
+			if c.expectErr {
+				return
+			}
+			if e, a := c.expect, invoked; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+
+			// the stack is not modified
+			if e, a := []string{"first", "second"}, s.Build.List(); !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+			invoked = nil
+			if _, _, err := s.HandleMiddleware(context.Background(), struct{}{}, handler); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := []string{"first", "second"}, invoked; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestWithMiddlewareOverrides_NestedStack(t *testing.T) {
+	var invoked []string
+	handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		return nil, Metadata{}, nil
+	})
+
+	inner := NewStack("inner", func() interface{} { return struct{}{} })
+	outer := NewStack("outer", func() interface{} { return struct{}{} })
+	outer.Initialize.Add(InitializeMiddlewareFunc("invokeInner", func(
+		ctx context.Context, in InitializeInput, next InitializeHandler,
+	) (InitializeOutput, Metadata, error) {
+		if _, _, err := inner.HandleMiddleware(ctx, struct{}{}, handler); err != nil {
+			return InitializeOutput{}, Metadata{}, err
+		}
+		return next.HandleInitialize(ctx, in)
+	}), After)
+
+	ctx := WithMiddlewareOverrides(context.Background(), NewMiddlewareGroup("a").
+		Finalize(FinalizeMiddlewareFunc("override", func(
+			ctx context.Context, in FinalizeInput, next FinalizeHandler,
+		) (FinalizeOutput, Metadata, error) {
+			invoked = append(invoked, "override")
+			return next.HandleFinalize(ctx, in)
+		}), "", After))
+
+	if _, _, err := outer.HandleMiddleware(ctx, struct{}{}, handler); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := []string{"override"}, invoked; !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/logging"
+)
+
+// PanicError is the error of a panic recovered by a RecoverMiddleware.
+type PanicError struct {
+	// The value the panic was called with.
+	Value interface{}
+
+	// The stack trace of the goroutine that panicked, as by debug.Stack.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("recovered panic: %v", e.Value)
+}
+
+// Unwrap returns the value the panic was called with if it is an error, or
+// nil.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// RecoverOptions is the set of options that can be configured for a
+// RecoverMiddleware.
+type RecoverOptions struct {
+	// The service and operation names of the smithy.OperationError a panic
+	// is returned as.
+	ServiceID     string
+	OperationName string
+
+	// Re-panics with the value of a recovered panic once it is logged,
+	// rather than returning it as an error.
+	RePanic bool
+}
+
+// RecoverMiddleware recovers from panics in the middleware and handler it
+// decorates, such that a misbehaving middleware does not crash the process.
+// A panic is logged with the context's logger, see GetLogger, and returned as
+// a *smithy.OperationError wrapping a *PanicError with the stack trace.
+//
+// In the Finalize step, panics in the middleware after it, the Deserialize
+// step, and the handler are recovered, see AddRecoverMiddleware. In the
+// Deserialize step, panics are recovered per attempt of the request, such
+// that they are returned to a retry middleware of the Finalize step.
+type RecoverMiddleware struct {
+	Options RecoverOptions
+}
+
+// AddRecoverMiddleware adds a RecoverMiddleware to the front of the Finalize
+// step of the stack.
+func AddRecoverMiddleware(stack *Stack, optFns ...func(*RecoverOptions)) error {
+	var o RecoverOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	return stack.Finalize.Add(&RecoverMiddleware{Options: o}, Before)
+}
+
+// ID returns the identifier for the middleware.
+func (*RecoverMiddleware) ID() string {
+	return "Recover"
+}
+
+// HandleFinalize invokes the next handler, returning a panic as an error.
+func (m *RecoverMiddleware) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = m.recovered(ctx, v)
+		}
+	}()
+	return next.HandleFinalize(ctx, in)
+}
+
+// HandleDeserialize invokes the next handler, returning a panic as an error.
+func (m *RecoverMiddleware) HandleDeserialize(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+	out DeserializeOutput, metadata Metadata, err error,
+) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = m.recovered(ctx, v)
+		}
+	}()
+	return next.HandleDeserialize(ctx, in)
+}
+
+// recovered logs the panic value, and returns it as an error, or re-panics
+// with it if the middleware is configured to.
+func (m *RecoverMiddleware) recovered(ctx context.Context, v interface{}) error {
+	perr := &PanicError{Value: v, Stack: debug.Stack()}
+	GetLogger(ctx).Logf(logging.Warn, "%v\n%s", perr, perr.Stack)
+
+	if m.Options.RePanic {
+		panic(v)
+	}
+	return &smithy.OperationError{
+		ServiceID:     m.Options.ServiceID,
+		OperationName: m.Options.OperationName,
+		Err:           perr,
+	}
+}
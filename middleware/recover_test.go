@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/logging"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	panicErr := errors.New("boom")
+
+	cases := map[string]struct {
+		add        func(*Stack) error
+		panicValue interface{}
+		expectWrap error
+	}{
+		"finalize": {
+			add: func(s *Stack) error {
+				return AddRecoverMiddleware(s, func(o *RecoverOptions) {
+					o.ServiceID, o.OperationName = "FooService", "GetFoo"
+				})
+			},
+			panicValue: "customizer failed",
+		},
+		"deserialize": {
+			add: func(s *Stack) error {
+				return s.Deserialize.Add(&RecoverMiddleware{Options: RecoverOptions{
+					ServiceID: "FooService", OperationName: "GetFoo",
+				}}, Before)
+			},
+			panicValue: panicErr,
+			expectWrap: panicErr,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			if err := c.add(s); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			s.Deserialize.Add(DeserializeMiddlewareFunc("panics", func(
+				ctx context.Context, in DeserializeInput, next DeserializeHandler,
+			) (DeserializeOutput, Metadata, error) {
+				panic(c.panicValue)
+			}), After)
+
+			var logged []string
+			ctx := SetLogger(context.Background(), logging.LoggerFunc(
+				func(classification logging.Classification, format string, v ...interface{}) {
+					logged = append(logged, fmt.Sprintf(format, v...))
+				}))
+
+			_, _, err := s.HandleMiddleware(ctx, struct{}{}, HandlerFunc(
+				func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+					return nil, Metadata{}, nil
+				}))
+
+			var oe *smithy.OperationError
+			if !errors.As(err, &oe) {
+				t.Fatalf("expect operation error, got %v", err)
+			}
+			if e, a := "FooService", oe.Service(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+			if e, a := "GetFoo", oe.Operation(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+
+			var pe *PanicError
+			if !errors.As(err, &pe) {
+				t.Fatalf("expect panic error, got %v", err)
+			}
+			if e, a := c.panicValue, pe.Value; e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+			if e, a := "recover_test.go", string(pe.Stack); !strings.Contains(a, e) {
+				t.Errorf("expect %v in stack trace, got %v", e, a)
+			}
+			if c.expectWrap != nil && !errors.Is(err, c.expectWrap) {
+				t.Errorf("expect %v to be wrapped, got %v", c.expectWrap, err)
+			}
+
+			if e, a := 1, len(logged); e != a {
+				t.Fatalf("expect %v log entries, got %v", e, a)
+			}
+			if e, a := "recovered panic", logged[0]; !strings.Contains(a, e) {
+				t.Errorf("expect %v in log, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestRecoverMiddleware_RePanic(t *testing.T) {
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+	if err := AddRecoverMiddleware(s, func(o *RecoverOptions) { o.RePanic = true }); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	defer func() {
+		if e, a := "handler failed", recover(); e != a {
+			t.Errorf("expect re-panic with %v, got %v", e, a)
+		}
+	}()
+	s.HandleMiddleware(context.Background(), struct{}{}, HandlerFunc(
+		func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			panic("handler failed")
+		}))
+	t.Errorf("expect re-panic")
+}
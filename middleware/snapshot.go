@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ElidedValue replaces the value of sensitive configuration in a
+// StackSnapshot.
+const ElidedValue = "[elided]"
+
+// sensitiveKeys are the case-insensitive substrings of a configuration key
+// whose value is elided from a snapshot regardless of whether it was marked
+// Sensitive.
+var sensitiveKeys = []string{
+	"secret",
+	"password",
+	"token",
+	"credential",
+	"privatekey",
+	"private_key",
+	"apikey",
+	"api_key",
+	"authorization",
+}
+
+// Sensitive marks a configuration value that must never be exported in a
+// StackSnapshot, such as a credential.
+type Sensitive struct {
+	Value interface{}
+}
+
+// MarshalJSON elides the wrapped value.
+func (Sensitive) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ElidedValue)
+}
+
+// ConfigDescriber is implemented by middleware that can describe their
+// effective configuration for inclusion in a StackSnapshot.
+//
+// The returned map must contain only values that can be marshaled to JSON.
+// Values that are secrets should be wrapped in Sensitive, though values whose
+// keys look like secrets, e.g. "SessionToken", are elided regardless.
+type ConfigDescriber interface {
+	DescribeConfig() map[string]interface{}
+}
+
+// SnapshotOptions is the set of options that can be configured for a
+// StackSnapshot.
+type SnapshotOptions struct {
+	// Client level configuration included in the snapshot, e.g. the retry
+	// mode, auth schemes, endpoint source, and feature flags in effect.
+	Config map[string]interface{}
+}
+
+// StackSnapshot describes the effective configuration of a Stack, for
+// inclusion in support bundles and diagnostics. Sensitive values are elided
+// when the snapshot is taken.
+type StackSnapshot struct {
+	ID     string                 `json:"id"`
+	Config map[string]interface{} `json:"config,omitempty"`
+	Steps  []StepSnapshot         `json:"steps"`
+}
+
+// StepSnapshot describes the middleware of a single step, in the order they
+// are invoked.
+type StepSnapshot struct {
+	ID         string               `json:"id"`
+	Middleware []MiddlewareSnapshot `json:"middleware"`
+}
+
+// MiddlewareSnapshot describes a single middleware. Config is set only for
+// middleware that implement ConfigDescriber.
+type MiddlewareSnapshot struct {
+	ID     string                 `json:"id"`
+	Config map[string]interface{} `json:"config,omitempty"`
+}
+
+// Snapshot returns a snapshot of the stack's layout and configuration.
+//
+// The snapshot shares no state with the stack, such that it may be retained
+// and exported after the stack is modified or invoked.
+func (s *Stack) Snapshot(optFns ...func(*SnapshotOptions)) *StackSnapshot {
+	var o SnapshotOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	return &StackSnapshot{
+		ID:     s.id,
+		Config: elideConfig(o.Config),
+		Steps: []StepSnapshot{
+			snapshotStep(s.Initialize.ID(), s.Initialize.ids),
+			snapshotStep(s.Serialize.ID(), s.Serialize.ids),
+			snapshotStep(s.Build.ID(), s.Build.ids),
+			snapshotStep(s.Finalize.ID(), s.Finalize.ids),
+			snapshotStep(s.Deserialize.ID(), s.Deserialize.ids),
+		},
+	}
+}
+
+// JSON returns the snapshot as an indented JSON document.
+func (s *StackSnapshot) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func snapshotStep(id string, ids *orderedIDs) StepSnapshot {
+	step := StepSnapshot{
+		ID:         id,
+		Middleware: []MiddlewareSnapshot{},
+	}
+
+	for _, m := range ids.GetOrder() {
+		ms := MiddlewareSnapshot{ID: m.(ider).ID()}
+		if d, ok := m.(ConfigDescriber); ok {
+			ms.Config = elideConfig(d.DescribeConfig())
+		}
+		step.Middleware = append(step.Middleware, ms)
+	}
+	return step
+}
+
+// elideConfig returns a deep copy of config with sensitive values elided.
+func elideConfig(config map[string]interface{}) map[string]interface{} {
+	if config == nil {
+		return nil
+	}
+
+	c := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		if isSensitiveKey(k) {
+			c[k] = ElidedValue
+		} else {
+			c[k] = elideValue(v)
+		}
+	}
+	return c
+}
+
+func elideValue(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case Sensitive, *Sensitive:
+		return ElidedValue
+	case map[string]interface{}:
+		return elideConfig(vv)
+	case []interface{}:
+		l := make([]interface{}, len(vv))
+		for i, item := range vv {
+			l[i] = elideValue(item)
+		}
+		return l
+	default:
+		return v
+	}
+}
+
+func isSensitiveKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type mockDescribedMiddleware struct {
+	config map[string]interface{}
+}
+
+func (*mockDescribedMiddleware) ID() string { return "described" }
+
+func (m *mockDescribedMiddleware) DescribeConfig() map[string]interface{} { return m.config }
+
+func (*mockDescribedMiddleware) HandleBuild(ctx context.Context, in BuildInput, next BuildHandler) (
+	out BuildOutput, metadata Metadata, err error,
+) {
+	return next.HandleBuild(ctx, in)
+}
+
+func TestStackSnapshot(t *testing.T) {
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+
+	s.Initialize.Add(mockInitializeMiddleware("first"), After)
+	s.Build.Add(mockBuildMiddleware("second"), After)
+	s.Build.Add(&mockDescribedMiddleware{config: map[string]interface{}{
+		"Region":   "us-west-2",
+		"Password": "hunter2",
+		"Nested": map[string]interface{}{
+			"AccessKey": Sensitive{Value: "AKID"},
+			"List":      []interface{}{"a", &Sensitive{Value: "b"}},
+		},
+	}}, After)
+
+	snapshot := s.Snapshot(func(o *SnapshotOptions) {
+		o.Config = map[string]interface{}{
+			"RetryMode":    "standard",
+			"AuthSchemes":  []interface{}{"sigv4", "bearer"},
+			"SessionToken": "secret-token",
+		}
+	})
+
+	expect := &StackSnapshot{
+		ID: "fooStack",
+		Config: map[string]interface{}{
+			"RetryMode":    "standard",
+			"AuthSchemes":  []interface{}{"sigv4", "bearer"},
+			"SessionToken": ElidedValue,
+		},
+		Steps: []StepSnapshot{
+			{ID: (*InitializeStep)(nil).ID(), Middleware: []MiddlewareSnapshot{{ID: "first"}}},
+			{ID: (*SerializeStep)(nil).ID(), Middleware: []MiddlewareSnapshot{}},
+			{ID: (*BuildStep)(nil).ID(), Middleware: []MiddlewareSnapshot{
+				{ID: "second"},
+				{ID: "described", Config: map[string]interface{}{
+					"Region":   "us-west-2",
+					"Password": ElidedValue,
+					"Nested": map[string]interface{}{
+						"AccessKey": ElidedValue,
+						"List":      []interface{}{"a", ElidedValue},
+					},
+				}},
+			}},
+			{ID: (*FinalizeStep)(nil).ID(), Middleware: []MiddlewareSnapshot{}},
+			{ID: (*DeserializeStep)(nil).ID(), Middleware: []MiddlewareSnapshot{}},
+		},
+	}
+	if !reflect.DeepEqual(expect, snapshot) {
+		t.Errorf("expect snapshot\n%#v\ngot\n%#v", expect, snapshot)
+	}
+
+	p, err := snapshot.JSON()
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	for _, secret := range []string{"hunter2", "AKID", "secret-token"} {
+		if strings.Contains(string(p), secret) {
+			t.Errorf("expect %q to be elided from\n%s", secret, p)
+		}
+	}
+
+	var decoded StackSnapshot
+	if err := json.Unmarshal(p, &decoded); err != nil {
+		t.Fatalf("expect valid JSON, got %v", err)
+	}
+	if e, a := "fooStack", decoded.ID; e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
+
+func TestSensitive_MarshalJSON(t *testing.T) {
+	p, err := json.Marshal(map[string]interface{}{"Key": Sensitive{Value: "secret"}})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := `{"Key":"[elided]"}`, string(p); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
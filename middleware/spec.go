@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// MiddlewareFactory returns a stack mutator configured with the parameters of
+// a MiddlewareSpec, e.g. one that adds a middleware to the stack. params is
+// nil if the spec has none.
+//
+// A factory should return an error if params are invalid, so that an invalid
+// spec is rejected before the stack is modified.
+type MiddlewareFactory func(params map[string]interface{}) (func(*Stack) error, error)
+
+// StackSpec is a declarative specification of modifications to a Stack, such
+// as a standard hardening profile shared by the clients of an organization.
+// Specs are typically distributed as JSON, see ParseStackSpec.
+type StackSpec struct {
+	// The middleware removed from the stack, before any are added.
+	Remove []MiddlewareRef `json:"remove,omitempty"`
+
+	// The middleware factories applied to the stack, in order.
+	Middleware []MiddlewareSpec `json:"middleware,omitempty"`
+}
+
+// MiddlewareRef identifies a middleware of a stack step.
+type MiddlewareRef struct {
+	// The step of the middleware, one of "Initialize", "Serialize", "Build",
+	// "Finalize", or "Deserialize".
+	Step string `json:"step"`
+
+	// The ID of the middleware.
+	ID string `json:"id"`
+}
+
+// MiddlewareSpec names a registered MiddlewareFactory, and the parameters it
+// is applied with.
+type MiddlewareSpec struct {
+	Name   string                 `json:"name"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// ParseStackSpec parses a StackSpec from its JSON representation, e.g.
+//
+//	{
+//	  "remove": [{"step": "Build", "id": "UserAgent"}],
+//	  "middleware": [
+//	    {"name": "ConcurrencyLimit", "params": {"max": 64}}
+//	  ]
+//	}
+//
+// Unknown fields are rejected, so that a misspelled field is not ignored.
+func ParseStackSpec(data []byte) (*StackSpec, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+
+	var spec StackSpec
+	if err := d.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("failed to parse stack spec, %w", err)
+	}
+	return &spec, nil
+}
+
+// UnknownMiddlewareFactoryError is returned when a MiddlewareSpec names a
+// factory that is not registered.
+type UnknownMiddlewareFactoryError struct {
+	Name string
+}
+
+func (e *UnknownMiddlewareFactoryError) Error() string {
+	return fmt.Sprintf("unknown middleware factory %q", e.Name)
+}
+
+// FactoryRegistry is the set of MiddlewareFactory that a StackSpec can name.
+// The zero value is an empty registry.
+//
+// A FactoryRegistry is safe for concurrent use.
+type FactoryRegistry struct {
+	mu        sync.RWMutex
+	factories map[string]MiddlewareFactory
+}
+
+// Register adds the factory to the registry with the name. Returns an error
+// if a factory is already registered with the name.
+func (r *FactoryRegistry) Register(name string, factory MiddlewareFactory) error {
+	if len(name) == 0 || factory == nil {
+		return fmt.Errorf("middleware factory must have a name and function")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.factories[name]; ok {
+		return fmt.Errorf("middleware factory %q is already registered", name)
+	}
+	if r.factories == nil {
+		r.factories = map[string]MiddlewareFactory{}
+	}
+	r.factories[name] = factory
+	return nil
+}
+
+// Get returns the factory registered with the name, if any.
+func (r *FactoryRegistry) Get(name string) (MiddlewareFactory, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	factory, ok := r.factories[name]
+	return factory, ok
+}
+
+// Apply modifies the stack as specified by spec, removing middleware and then
+// applying the named factories in order.
+//
+// Every factory is resolved and invoked with its parameters before the stack
+// is modified, such that the stack is unchanged if spec names an unknown
+// factory, has invalid parameters, or removes middleware not in the stack.
+// An error applying a stack mutator may
+// leave the stack partially modified.
+func (r *FactoryRegistry) Apply(stack *Stack, spec *StackSpec) error {
+	fns := make([]func(*Stack) error, 0, len(spec.Middleware))
+	for i, m := range spec.Middleware {
+		factory, ok := r.Get(m.Name)
+		if !ok {
+			return fmt.Errorf("middleware %d, %w", i, &UnknownMiddlewareFactoryError{Name: m.Name})
+		}
+
+		fn, err := factory(m.Params)
+		if err != nil {
+			return fmt.Errorf("middleware %d, invalid %s params, %w", i, m.Name, err)
+		}
+		fns = append(fns, fn)
+	}
+
+	removals := make([]*orderedIDs, 0, len(spec.Remove))
+	for _, ref := range spec.Remove {
+		ids, err := stepIDs(stack, ref.Step)
+		if err != nil {
+			return fmt.Errorf("remove %s, %w", ref.ID, err)
+		}
+		if _, ok := ids.Get(ref.ID); !ok {
+			return fmt.Errorf("remove %s, not found in %s step", ref.ID, ref.Step)
+		}
+		removals = append(removals, ids)
+	}
+
+	for i, ids := range removals {
+		if _, err := ids.Remove(spec.Remove[i].ID); err != nil {
+			return fmt.Errorf("remove %s, %w", spec.Remove[i].ID, err)
+		}
+	}
+	for i, fn := range fns {
+		if err := fn(stack); err != nil {
+			return fmt.Errorf("middleware %d, apply %s, %w", i, spec.Middleware[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// stepIDs returns the middleware of the stack's step with the name, e.g.
+// "Build".
+func stepIDs(stack *Stack, step string) (*orderedIDs, error) {
+	switch step {
+	case "Initialize":
+		return stack.Initialize.ids, nil
+	case "Serialize":
+		return stack.Serialize.ids, nil
+	case "Build":
+		return stack.Build.ids, nil
+	case "Finalize":
+		return stack.Finalize.ids, nil
+	case "Deserialize":
+		return stack.Deserialize.ids, nil
+	default:
+		return nil, fmt.Errorf("unknown stack step %q", step)
+	}
+}
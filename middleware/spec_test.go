@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestFactoryRegistry(t *testing.T) *FactoryRegistry {
+	t.Helper()
+
+	var r FactoryRegistry
+	noError(t, r.Register("AddBuild", func(params map[string]interface{}) (func(*Stack) error, error) {
+		id, ok := params["id"].(string)
+		if !ok {
+			return nil, fmt.Errorf("id must be a string")
+		}
+		return func(s *Stack) error {
+			return s.Build.Add(mockBuildMiddleware(id), After)
+		}, nil
+	}))
+	noError(t, r.Register("AddInitialize", func(params map[string]interface{}) (func(*Stack) error, error) {
+		return func(s *Stack) error {
+			return s.Initialize.Add(mockInitializeMiddleware("spec"), Before)
+		}, nil
+	}))
+	return &r
+}
+
+func newTestSpecStack(t *testing.T) *Stack {
+	t.Helper()
+
+	s := NewStack("test", func() interface{} { return nil })
+	noError(t, s.Initialize.Add(mockInitializeMiddleware("first"), After))
+	noError(t, s.Build.Add(mockBuildMiddleware("UserAgent"), After))
+	return s
+}
+
+func TestFactoryRegistry_Apply(t *testing.T) {
+	spec, err := ParseStackSpec([]byte(`{
+		"remove": [{"step": "Build", "id": "UserAgent"}],
+		"middleware": [
+			{"name": "AddBuild", "params": {"id": "a"}},
+			{"name": "AddInitialize"},
+			{"name": "AddBuild", "params": {"id": "b"}}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	s := newTestSpecStack(t)
+	if err := newTestFactoryRegistry(t).Apply(s, spec); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := []string{"spec", "first"}, s.Initialize.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if e, a := []string{"a", "b"}, s.Build.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
+
+func TestFactoryRegistry_ApplyErrors(t *testing.T) {
+	cases := map[string]struct {
+		spec StackSpec
+		err  string
+	}{
+		"unknown factory": {
+			spec: StackSpec{
+				Middleware: []MiddlewareSpec{{Name: "AddBuild", Params: map[string]interface{}{"id": "a"}}, {Name: "Unknown"}},
+			},
+			err: `middleware 1, unknown middleware factory "Unknown"`,
+		},
+		"invalid params": {
+			spec: StackSpec{
+				Middleware: []MiddlewareSpec{{Name: "AddBuild"}},
+			},
+			err: "middleware 0, invalid AddBuild params, id must be a string",
+		},
+		"unknown step": {
+			spec: StackSpec{
+				Remove: []MiddlewareRef{{Step: "Sign", ID: "UserAgent"}},
+			},
+			err: `remove UserAgent, unknown stack step "Sign"`,
+		},
+		"remove missing middleware": {
+			spec: StackSpec{
+				Remove: []MiddlewareRef{{Step: "Build", ID: "UserAgent"}, {Step: "Build", ID: "Missing"}},
+			},
+			err: "remove Missing, not found in Build step",
+		},
+		"apply error": {
+			spec: StackSpec{
+				Middleware: []MiddlewareSpec{{Name: "AddBuild", Params: map[string]interface{}{"id": "UserAgent"}}},
+			},
+			err: "middleware 0, apply AddBuild",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := newTestSpecStack(t)
+			err := newTestFactoryRegistry(t).Apply(s, &c.spec)
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Fatalf("expect error %q, got %v", c.err, err)
+			}
+			if strings.Contains(c.err, "apply") {
+				return
+			}
+
+			// rejected before the stack was modified
+			if e, a := []string{"first"}, s.Initialize.List(); !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+			if e, a := []string{"UserAgent"}, s.Build.List(); !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+
+	var unknown *UnknownMiddlewareFactoryError
+	err := newTestFactoryRegistry(t).Apply(newTestSpecStack(t), &StackSpec{Middleware: []MiddlewareSpec{{Name: "Unknown"}}})
+	if !errors.As(err, &unknown) {
+		t.Errorf("expect UnknownMiddlewareFactoryError, got %v", err)
+	}
+}
+
+func TestFactoryRegistry_Register(t *testing.T) {
+	r := newTestFactoryRegistry(t)
+
+	err := r.Register("AddBuild", func(map[string]interface{}) (func(*Stack) error, error) { return nil, nil })
+	if err == nil || !strings.Contains(err.Error(), "already registered") {
+		t.Errorf("expect already registered error, got %v", err)
+	}
+	if err := r.Register("", nil); err == nil {
+		t.Errorf("expect error for empty registration")
+	}
+}
+
+func TestParseStackSpec_UnknownField(t *testing.T) {
+	_, err := ParseStackSpec([]byte(`{"middlware": []}`))
+	if err == nil || !strings.Contains(err.Error(), "unknown field") {
+		t.Errorf("expect unknown field error, got %v", err)
+	}
+}
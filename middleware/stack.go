@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"strings"
+	"sync"
 )
 
 // Stack provides protocol and transport agnostic set of middleware split into
@@ -22,6 +23,12 @@ import (
 // along the input down the chain, or return the result back up the chain.
 //
 //   Initialize <- Serialize -> Build -> Finalize <- Deserialize <- Handler
+//
+// A stack is safe for concurrent use. Middleware may be added to, or removed
+// from, its steps while it is invoked, e.g. by a per-request plugin, without
+// affecting in-flight invocations, which invoke the middleware of each step
+// as they were when the step was invoked. Use Clone to modify a stack for a
+// single invocation.
 type Stack struct {
 	// Initialize prepares the input, and sets any default parameters as
 	// needed, (e.g. idempotency token, and presigned URLs).
@@ -70,6 +77,15 @@ type Stack struct {
 	Deserialize *DeserializeStep
 
 	id string
+
+	// guards the groups and hooks of the stack
+	mu sync.RWMutex
+
+	// groups added to the stack by name, see AddGroup
+	groups map[string]*MiddlewareGroup
+
+	// hooks called as the stack is invoked, see AddHooks
+	hooks []StackHooks
 }
 
 // NewStack returns an initialize empty stack.
@@ -94,10 +110,26 @@ func (s *Stack) ID() string { return s.id }
 // The input value must be the input parameters of the operation being
 // performed.
 //
-// Will return the result of the operation, or error.
+// Will return the result of the operation, or error. If timing is enabled
+// for the context with WithTiming, the metadata has the duration of each step
+// and middleware, see GetStackTimings. The hooks of the stack are called as
+// it is invoked, see AddHooks. Middleware may be layered on the stack for the
+// invocation with the context, see WithMiddlewareOverrides. The errors of
+// middleware are attributed to the middleware they originate from if enabled
+// for the context with WithMiddlewareErrors.
 func (s *Stack) HandleMiddleware(ctx context.Context, input interface{}, next Handler) (
 	output interface{}, metadata Metadata, err error,
 ) {
+	ctx, d := startDecorations(ctx, s)
+	if d != nil {
+		next = d.decorateHandler(next)
+		if d.timing != nil {
+			defer func() {
+				setStackTimings(&metadata, d.timing.timings(s.id))
+			}()
+		}
+	}
+
 	h := DecorateHandler(next,
 		s.Initialize,
 		s.Serialize,
@@ -1,8 +1,11 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -66,3 +69,162 @@ func TestStackString(t *testing.T) {
 		t.Errorf("expect and actual stack list differ: %v != %v", expect, actual)
 	}
 }
+
+func TestStack_ConcurrentModification(t *testing.T) {
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+	s.Build.Add(mockBuildMiddleware("base"), After)
+
+	handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		return nil, Metadata{}, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, _, err := s.HandleMiddleware(context.Background(), struct{}{}, handler); err != nil {
+					t.Errorf("expect no error, got %v", err)
+				}
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				id := fmt.Sprintf("plugin-%d-%d", i, j)
+				if err := s.Build.Insert(mockBuildMiddleware(id), "base", Before); err != nil {
+					t.Errorf("expect no error, got %v", err)
+				}
+				s.Describe()
+				s.Clone()
+				if _, err := s.Build.Remove(id); err != nil {
+					t.Errorf("expect no error, got %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if e, a := []string{"base"}, s.Build.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
+
+func TestStack_Decorated(t *testing.T) {
+	cases := map[string]struct {
+		ctx    func(context.Context) context.Context
+		hooks  bool
+		expect bool
+
+		// whether a stack invoked within the stack is decorated, as hooks
+		// and overrides are not applied to it
+		expectInner bool
+	}{
+		"none": {},
+		"timing": {
+			ctx:         WithTiming,
+			expect:      true,
+			expectInner: true,
+		},
+		"hooks": {
+			hooks:  true,
+			expect: true,
+		},
+		"middleware errors": {
+			ctx:         WithMiddlewareErrors,
+			expect:      true,
+			expectInner: true,
+		},
+		"overrides": {
+			ctx: func(ctx context.Context) context.Context {
+				return WithMiddlewareOverrides(ctx, NewMiddlewareGroup("override").
+					Build(mockBuildMiddleware("override"), "", After))
+			},
+			expect: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var decorated, innerDecorated bool
+			inner := NewStack("inner", func() interface{} { return struct{}{} })
+			inner.Build.Add(BuildMiddlewareFunc("inner", func(
+				ctx context.Context, in BuildInput, next BuildHandler,
+			) (BuildOutput, Metadata, error) {
+				innerDecorated = getStackDecorations(ctx) != nil
+				return next.HandleBuild(ctx, in)
+			}), After)
+
+			s := NewStack("outer", func() interface{} { return struct{}{} })
+			if c.hooks {
+				s.AddHooks(StackHooks{OnStepEnter: func(context.Context, StepEvent) {}})
+			}
+			s.Build.Add(BuildMiddlewareFunc("outer", func(
+				ctx context.Context, in BuildInput, next BuildHandler,
+			) (BuildOutput, Metadata, error) {
+				decorated = getStackDecorations(ctx) != nil
+
+				_, _, err := inner.HandleMiddleware(ctx, struct{}{},
+					HandlerFunc(func(context.Context, interface{}) (interface{}, Metadata, error) {
+						return nil, Metadata{}, nil
+					}))
+				if err != nil {
+					return BuildOutput{}, Metadata{}, err
+				}
+				return next.HandleBuild(ctx, in)
+			}), After)
+
+			ctx := context.Background()
+			if c.ctx != nil {
+				ctx = c.ctx(ctx)
+			}
+			_, _, err := s.HandleMiddleware(ctx, struct{}{}, HandlerFunc(func(context.Context, interface{}) (interface{}, Metadata, error) {
+				return nil, Metadata{}, nil
+			}))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.expect, decorated; e != a {
+				t.Errorf("expect decorated %v, got %v", e, a)
+			}
+			if e, a := c.expectInner, innerDecorated; e != a {
+				t.Errorf("expect inner stack decorated %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func BenchmarkStack_HandleMiddleware(b *testing.B) {
+	s := NewStack("bench", func() interface{} { return struct{}{} })
+	for i := 0; i < 10; i++ {
+		s.Initialize.Add(InitializeMiddlewareFunc(fmt.Sprintf("init%d", i), func(
+			ctx context.Context, in InitializeInput, next InitializeHandler,
+		) (InitializeOutput, Metadata, error) {
+			return next.HandleInitialize(ctx, in)
+		}), After)
+		s.Finalize.Add(FinalizeMiddlewareFunc(fmt.Sprintf("finalize%d", i), func(
+			ctx context.Context, in FinalizeInput, next FinalizeHandler,
+		) (FinalizeOutput, Metadata, error) {
+			return next.HandleFinalize(ctx, in)
+		}), After)
+	}
+	handler := HandlerFunc(func(context.Context, interface{}) (interface{}, Metadata, error) {
+		return nil, Metadata{}, nil
+	})
+
+	cases := map[string]context.Context{
+		"undecorated": context.Background(),
+		"timing":      WithTiming(context.Background()),
+	}
+	for name, ctx := range cases {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := s.HandleMiddleware(ctx, struct{}{}, handler); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
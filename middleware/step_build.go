@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"time"
 )
 
 // BuildInput provides the input parameters for the BuildMiddleware to consume.
@@ -97,13 +98,27 @@ func (s *BuildStep) ID() string {
 func (s *BuildStep) HandleMiddleware(ctx context.Context, in interface{}, next Handler) (
 	out interface{}, metadata Metadata, err error,
 ) {
-	order := s.ids.GetOrder()
+	order, d, done, err := decorateStep(ctx, "Build", s.ID(), s.ids.GetOrder())
+	if err != nil {
+		return nil, metadata, err
+	}
+	if done != nil {
+		defer func() { done(err) }()
+	}
 
 	var h BuildHandler = buildWrapHandler{Next: next}
 	for i := len(order) - 1; i >= 0; i-- {
-		h = decoratedBuildHandler{
-			Next: h,
-			With: order[i].(BuildMiddleware),
+		if d == nil {
+			h = decoratedBuildHandler{
+				Next: h,
+				With: order[i].(BuildMiddleware),
+			}
+			continue
+		}
+		h = instrumentedBuildHandler{
+			Next:        h,
+			With:        order[i].(BuildMiddleware),
+			decorations: d.middleware(i),
 		}
 	}
 
@@ -200,6 +215,34 @@ func (h decoratedBuildHandler) HandleBuild(ctx context.Context, in BuildInput) (
 	return h.With.HandleBuild(ctx, in, h.Next)
 }
 
+// instrumentedBuildHandler is a decoratedBuildHandler for an invocation that
+// enables decorations of its middleware, e.g. timing.
+type instrumentedBuildHandler struct {
+	Next        BuildHandler
+	With        BuildMiddleware
+	decorations middlewareDecorations
+}
+
+var _ BuildHandler = (*instrumentedBuildHandler)(nil)
+
+func (h instrumentedBuildHandler) HandleBuild(ctx context.Context, in BuildInput) (
+	out BuildOutput, metadata Metadata, err error,
+) {
+	d := h.decorations
+	if d.timing != nil {
+		defer d.timing.record(time.Now())
+	}
+	if d.hooks != nil {
+		d.hooks.enter()
+		defer func() { d.hooks.returned(ctx, h.With.ID(), err) }()
+	}
+	if d.errs != nil {
+		d.errs.enter()
+		defer func() { err = d.errs.returned(h.With.ID(), err) }()
+	}
+	return h.With.HandleBuild(ctx, in, h.Next)
+}
+
 // BuildHandlerFunc provides a wrapper around a function to be used as a build middleware handler.
 type BuildHandlerFunc func(context.Context, BuildInput) (BuildOutput, Metadata, error)
 
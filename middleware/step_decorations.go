@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"context"
+	"time"
+)
+
+// decorationsKey is the context key of the decorations of stacks invoked with
+// the context, such that a stack, and each of its steps, looks up whether
+// timing, hooks, middleware overrides, or error attribution are enabled with
+// a single lookup.
+type decorationsKey struct{}
+
+// decorations are the decorations of the middleware of stacks enabled by a
+// context.
+type decorations struct {
+	// enabled by WithTiming
+	timing bool
+
+	// enabled by WithMiddlewareErrors
+	errors bool
+
+	// added by WithMiddlewareOverrides, for the stack next invoked
+	overrides middlewareOverrides
+
+	// the decorations of the invocation of the innermost stack being
+	// invoked, or nil if it decorates nothing
+	invocation *stackDecorations
+}
+
+// stackDecorations are the decorations of an invocation of a stack.
+type stackDecorations struct {
+	overrides middlewareOverrides
+	timing    *timingRecorder
+	hooks     *hookEmitter
+	errs      *errorAttributor
+}
+
+func getDecorations(ctx context.Context) *decorations {
+	d, _ := ctx.Value(decorationsKey{}).(*decorations)
+	return d
+}
+
+// withDecorations returns a context with a copy of the decorations of ctx
+// modified by fn.
+func withDecorations(ctx context.Context, fn func(*decorations)) context.Context {
+	var d decorations
+	if prev := getDecorations(ctx); prev != nil {
+		d = *prev
+	}
+	fn(&d)
+	return context.WithValue(ctx, decorationsKey{}, &d)
+}
+
+// getStackDecorations returns the decorations of the invocation of the stack
+// being invoked with ctx, or nil if it decorates nothing.
+func getStackDecorations(ctx context.Context) *stackDecorations {
+	if d := getDecorations(ctx); d != nil {
+		return d.invocation
+	}
+	return nil
+}
+
+// startDecorations returns a context for an invocation of the stack with the
+// decorations enabled for it, and the decorations, or nil if it decorates
+// nothing. The overrides of ctx are consumed by the invocation, such that
+// they are not applied to the stacks it invokes.
+func startDecorations(ctx context.Context, s *Stack) (context.Context, *stackDecorations) {
+	s.mu.RLock()
+	hooks := s.hooks
+	s.mu.RUnlock()
+
+	prev := getDecorations(ctx)
+	if prev == nil && len(hooks) == 0 {
+		return ctx, nil
+	}
+
+	var d decorations
+	if prev != nil {
+		d = *prev
+	}
+	overrides := d.overrides
+	d.overrides = nil
+	d.invocation = nil
+
+	if !d.timing && !d.errors && len(overrides) == 0 && len(hooks) == 0 {
+		if prev.invocation != nil {
+			// the decorations of an enclosing stack do not apply to this
+			// stack
+			ctx = context.WithValue(ctx, decorationsKey{}, &d)
+		}
+		return ctx, nil
+	}
+
+	sd := &stackDecorations{overrides: overrides}
+	if d.timing {
+		sd.timing = newTimingRecorder()
+	}
+	if len(hooks) != 0 {
+		sd.hooks = &hookEmitter{stackID: s.id, hooks: hooks}
+	}
+	if d.errors {
+		sd.errs = &errorAttributor{}
+	}
+	d.invocation = sd
+	return context.WithValue(ctx, decorationsKey{}, &d), sd
+}
+
+// decorateHandler returns the handler of the stack decorated to be timed,
+// call the hooks, and attribute errors, as enabled for the invocation.
+func (d *stackDecorations) decorateHandler(next Handler) Handler {
+	if errs := d.errs; errs != nil {
+		handler := next
+		next = HandlerFunc(func(ctx context.Context, input interface{}) (out interface{}, metadata Metadata, err error) {
+			errs.enter()
+			defer func() { err = errs.returned("", "", err) }()
+			return handler.Handle(ctx, input)
+		})
+	}
+	if hooks := d.hooks; hooks != nil {
+		handler := next
+		next = HandlerFunc(func(ctx context.Context, input interface{}) (out interface{}, metadata Metadata, err error) {
+			hooks.enter()
+			defer func() { hooks.returned(ctx, "", "", err) }()
+			return handler.Handle(ctx, input)
+		})
+	}
+	if rec := d.timing; rec != nil {
+		handler := next
+		next = HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			defer rec.handler.record(time.Now())
+			return handler.Handle(ctx, input)
+		})
+	}
+	return next
+}
+
+// stepDecorations are the decorations of the middleware of a step for an
+// invocation of its stack.
+type stepDecorations struct {
+	timers middlewareTimers
+	hooks  *stepHooks
+	errs   *stepErrors
+}
+
+// middleware returns the decorations of the i-th middleware of the step.
+func (d *stepDecorations) middleware(i int) middlewareDecorations {
+	return middlewareDecorations{
+		timing: d.timers.at(i),
+		hooks:  d.hooks,
+		errs:   d.errs,
+	}
+}
+
+// middlewareDecorations are the decorations of a middleware of a step.
+type middlewareDecorations struct {
+	// records the duration of the middleware, if timing is enabled
+	timing *middlewareTimer
+
+	// calls the hooks of the stack, if any
+	hooks *stepHooks
+
+	// wraps the errors of the middleware, if enabled
+	errs *stepErrors
+}
+
+// decorateStep returns the order of the middleware of the step for the
+// invocation with ctx, with the overrides of the step placed in it, and the
+// decorations of the middleware, or nil if the invocation decorates nothing.
+// done, if not nil, must be called with the error of the step when it
+// returns.
+//
+// When the invocation enables no decoration, the order is returned as is, so
+// that a stack invoked without them pays only for its middleware.
+func decorateStep(ctx context.Context, step, stepID string, order []interface{}) (
+	[]interface{}, *stepDecorations, func(error), error,
+) {
+	sd := getStackDecorations(ctx)
+	if sd == nil {
+		return order, nil, nil, nil
+	}
+	return sd.step(ctx, step, stepID, order)
+}
+
+func (sd *stackDecorations) step(ctx context.Context, step, stepID string, order []interface{}) (
+	_ []interface{}, d *stepDecorations, done func(error), err error,
+) {
+	if order, err = sd.overrides.apply(step, order); err != nil {
+		return nil, nil, nil, err
+	}
+
+	start := time.Now()
+	d = &stepDecorations{}
+	var stepTiming *stepTimer
+	stepTiming, d.timers = sd.timing.timers(stepID, order)
+	d.hooks = sd.hooks.step(stepID)
+	if d.hooks != nil {
+		d.hooks.enterStep(ctx)
+	}
+	d.errs = sd.errs.step(step)
+
+	done = func(err error) {
+		if d.hooks != nil {
+			d.hooks.exitStep(ctx, err)
+		}
+		if stepTiming != nil {
+			stepTiming.record(start)
+		}
+	}
+	return order, d, done, nil
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"time"
 )
 
 // DeserializeInput provides the input parameters for the DeserializeInput to
@@ -103,13 +104,27 @@ func (s *DeserializeStep) ID() string {
 func (s *DeserializeStep) HandleMiddleware(ctx context.Context, in interface{}, next Handler) (
 	out interface{}, metadata Metadata, err error,
 ) {
-	order := s.ids.GetOrder()
+	order, d, done, err := decorateStep(ctx, "Deserialize", s.ID(), s.ids.GetOrder())
+	if err != nil {
+		return nil, metadata, err
+	}
+	if done != nil {
+		defer func() { done(err) }()
+	}
 
 	var h DeserializeHandler = deserializeWrapHandler{Next: next}
 	for i := len(order) - 1; i >= 0; i-- {
-		h = decoratedDeserializeHandler{
-			Next: h,
-			With: order[i].(DeserializeMiddleware),
+		if d == nil {
+			h = decoratedDeserializeHandler{
+				Next: h,
+				With: order[i].(DeserializeMiddleware),
+			}
+			continue
+		}
+		h = instrumentedDeserializeHandler{
+			Next:        h,
+			With:        order[i].(DeserializeMiddleware),
+			decorations: d.middleware(i),
 		}
 	}
 
@@ -188,6 +203,7 @@ func (w deserializeWrapHandler) HandleDeserialize(ctx context.Context, in Deseri
 	out DeserializeOutput, metadata Metadata, err error,
 ) {
 	resp, metadata, err := w.Next.Handle(ctx, in.Request)
+	trackTimeoutReleases(ctx, resp)
 	return DeserializeOutput{
 		RawResponse: resp,
 	}, metadata, err
@@ -206,6 +222,34 @@ func (h decoratedDeserializeHandler) HandleDeserialize(ctx context.Context, in D
 	return h.With.HandleDeserialize(ctx, in, h.Next)
 }
 
+// instrumentedDeserializeHandler is a decoratedDeserializeHandler for an invocation that
+// enables decorations of its middleware, e.g. timing.
+type instrumentedDeserializeHandler struct {
+	Next        DeserializeHandler
+	With        DeserializeMiddleware
+	decorations middlewareDecorations
+}
+
+var _ DeserializeHandler = (*instrumentedDeserializeHandler)(nil)
+
+func (h instrumentedDeserializeHandler) HandleDeserialize(ctx context.Context, in DeserializeInput) (
+	out DeserializeOutput, metadata Metadata, err error,
+) {
+	d := h.decorations
+	if d.timing != nil {
+		defer d.timing.record(time.Now())
+	}
+	if d.hooks != nil {
+		d.hooks.enter()
+		defer func() { d.hooks.returned(ctx, h.With.ID(), err) }()
+	}
+	if d.errs != nil {
+		d.errs.enter()
+		defer func() { err = d.errs.returned(h.With.ID(), err) }()
+	}
+	return h.With.HandleDeserialize(ctx, in, h.Next)
+}
+
 // DeserializeHandlerFunc provides a wrapper around a function to be used as a deserialize middleware handler.
 type DeserializeHandlerFunc func(context.Context, DeserializeInput) (DeserializeOutput, Metadata, error)
 
@@ -1,6 +1,9 @@
 package middleware
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // FinalizeInput provides the input parameters for the FinalizeMiddleware to
 // consume. FinalizeMiddleware may modify the Request value before forwarding
@@ -97,13 +100,27 @@ func (s *FinalizeStep) ID() string {
 func (s *FinalizeStep) HandleMiddleware(ctx context.Context, in interface{}, next Handler) (
 	out interface{}, metadata Metadata, err error,
 ) {
-	order := s.ids.GetOrder()
+	order, d, done, err := decorateStep(ctx, "Finalize", s.ID(), s.ids.GetOrder())
+	if err != nil {
+		return nil, metadata, err
+	}
+	if done != nil {
+		defer func() { done(err) }()
+	}
 
 	var h FinalizeHandler = finalizeWrapHandler{Next: next}
 	for i := len(order) - 1; i >= 0; i-- {
-		h = decoratedFinalizeHandler{
-			Next: h,
-			With: order[i].(FinalizeMiddleware),
+		if d == nil {
+			h = decoratedFinalizeHandler{
+				Next: h,
+				With: order[i].(FinalizeMiddleware),
+			}
+			continue
+		}
+		h = instrumentedFinalizeHandler{
+			Next:        h,
+			With:        order[i].(FinalizeMiddleware),
+			decorations: d.middleware(i),
 		}
 	}
 
@@ -200,6 +217,34 @@ func (h decoratedFinalizeHandler) HandleFinalize(ctx context.Context, in Finaliz
 	return h.With.HandleFinalize(ctx, in, h.Next)
 }
 
+// instrumentedFinalizeHandler is a decoratedFinalizeHandler for an invocation that
+// enables decorations of its middleware, e.g. timing.
+type instrumentedFinalizeHandler struct {
+	Next        FinalizeHandler
+	With        FinalizeMiddleware
+	decorations middlewareDecorations
+}
+
+var _ FinalizeHandler = (*instrumentedFinalizeHandler)(nil)
+
+func (h instrumentedFinalizeHandler) HandleFinalize(ctx context.Context, in FinalizeInput) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	d := h.decorations
+	if d.timing != nil {
+		defer d.timing.record(time.Now())
+	}
+	if d.hooks != nil {
+		d.hooks.enter()
+		defer func() { d.hooks.returned(ctx, h.With.ID(), err) }()
+	}
+	if d.errs != nil {
+		d.errs.enter()
+		defer func() { err = d.errs.returned(h.With.ID(), err) }()
+	}
+	return h.With.HandleFinalize(ctx, in, h.Next)
+}
+
 // FinalizeHandlerFunc provides a wrapper around a function to be used as a finalize middleware handler.
 type FinalizeHandlerFunc func(context.Context, FinalizeInput) (FinalizeOutput, Metadata, error)
 
@@ -1,6 +1,9 @@
 package middleware
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // InitializeInput wraps the input parameters for the InitializeMiddlewares to
 // consume. InitializeMiddleware may modify the parameter value before
@@ -97,13 +100,27 @@ func (s *InitializeStep) ID() string {
 func (s *InitializeStep) HandleMiddleware(ctx context.Context, in interface{}, next Handler) (
 	out interface{}, metadata Metadata, err error,
 ) {
-	order := s.ids.GetOrder()
+	order, d, done, err := decorateStep(ctx, "Initialize", s.ID(), s.ids.GetOrder())
+	if err != nil {
+		return nil, metadata, err
+	}
+	if done != nil {
+		defer func() { done(err) }()
+	}
 
 	var h InitializeHandler = initializeWrapHandler{Next: next}
 	for i := len(order) - 1; i >= 0; i-- {
-		h = decoratedInitializeHandler{
-			Next: h,
-			With: order[i].(InitializeMiddleware),
+		if d == nil {
+			h = decoratedInitializeHandler{
+				Next: h,
+				With: order[i].(InitializeMiddleware),
+			}
+			continue
+		}
+		h = instrumentedInitializeHandler{
+			Next:        h,
+			With:        order[i].(InitializeMiddleware),
+			decorations: d.middleware(i),
 		}
 	}
 
@@ -200,6 +217,34 @@ func (h decoratedInitializeHandler) HandleInitialize(ctx context.Context, in Ini
 	return h.With.HandleInitialize(ctx, in, h.Next)
 }
 
+// instrumentedInitializeHandler is a decoratedInitializeHandler for an invocation that
+// enables decorations of its middleware, e.g. timing.
+type instrumentedInitializeHandler struct {
+	Next        InitializeHandler
+	With        InitializeMiddleware
+	decorations middlewareDecorations
+}
+
+var _ InitializeHandler = (*instrumentedInitializeHandler)(nil)
+
+func (h instrumentedInitializeHandler) HandleInitialize(ctx context.Context, in InitializeInput) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	d := h.decorations
+	if d.timing != nil {
+		defer d.timing.record(time.Now())
+	}
+	if d.hooks != nil {
+		d.hooks.enter()
+		defer func() { d.hooks.returned(ctx, h.With.ID(), err) }()
+	}
+	if d.errs != nil {
+		d.errs.enter()
+		defer func() { err = d.errs.returned(h.With.ID(), err) }()
+	}
+	return h.With.HandleInitialize(ctx, in, h.Next)
+}
+
 // InitializeHandlerFunc provides a wrapper around a function to be used as an initialize middleware handler.
 type InitializeHandlerFunc func(context.Context, InitializeInput) (InitializeOutput, Metadata, error)
 
@@ -1,6 +1,9 @@
 package middleware
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // SerializeInput provides the input parameters for the SerializeMiddleware to
 // consume. SerializeMiddleware may modify the Request value before forwarding
@@ -104,13 +107,27 @@ func (s *SerializeStep) ID() string {
 func (s *SerializeStep) HandleMiddleware(ctx context.Context, in interface{}, next Handler) (
 	out interface{}, metadata Metadata, err error,
 ) {
-	order := s.ids.GetOrder()
+	order, d, done, err := decorateStep(ctx, "Serialize", s.ID(), s.ids.GetOrder())
+	if err != nil {
+		return nil, metadata, err
+	}
+	if done != nil {
+		defer func() { done(err) }()
+	}
 
 	var h SerializeHandler = serializeWrapHandler{Next: next}
 	for i := len(order) - 1; i >= 0; i-- {
-		h = decoratedSerializeHandler{
-			Next: h,
-			With: order[i].(SerializeMiddleware),
+		if d == nil {
+			h = decoratedSerializeHandler{
+				Next: h,
+				With: order[i].(SerializeMiddleware),
+			}
+			continue
+		}
+		h = instrumentedSerializeHandler{
+			Next:        h,
+			With:        order[i].(SerializeMiddleware),
+			decorations: d.middleware(i),
 		}
 	}
 
@@ -208,6 +225,34 @@ func (h decoratedSerializeHandler) HandleSerialize(ctx context.Context, in Seria
 	return h.With.HandleSerialize(ctx, in, h.Next)
 }
 
+// instrumentedSerializeHandler is a decoratedSerializeHandler for an invocation that
+// enables decorations of its middleware, e.g. timing.
+type instrumentedSerializeHandler struct {
+	Next        SerializeHandler
+	With        SerializeMiddleware
+	decorations middlewareDecorations
+}
+
+var _ SerializeHandler = (*instrumentedSerializeHandler)(nil)
+
+func (h instrumentedSerializeHandler) HandleSerialize(ctx context.Context, in SerializeInput) (
+	out SerializeOutput, metadata Metadata, err error,
+) {
+	d := h.decorations
+	if d.timing != nil {
+		defer d.timing.record(time.Now())
+	}
+	if d.hooks != nil {
+		d.hooks.enter()
+		defer func() { d.hooks.returned(ctx, h.With.ID(), err) }()
+	}
+	if d.errs != nil {
+		d.errs.enter()
+		defer func() { err = d.errs.returned(h.With.ID(), err) }()
+	}
+	return h.With.HandleSerialize(ctx, in, h.Next)
+}
+
 // SerializeHandlerFunc provides a wrapper around a function to be used as a serialize middleware handler.
 type SerializeHandlerFunc func(context.Context, SerializeInput) (SerializeOutput, Metadata, error)
 
@@ -0,0 +1,270 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TimeoutError is the error of a step of a stack invocation that did not
+// complete within its timeout, see AddStepTimeout.
+type TimeoutError struct {
+	// The step that timed out, e.g. "Deserialize".
+	Step string
+
+	// The duration the step was given to complete.
+	Timeout time.Duration
+
+	// The error the step returned, e.g. one wrapping
+	// context.DeadlineExceeded.
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s step timed out after %v, %v", e.Step, e.Timeout, e.Err)
+}
+
+// Unwrap returns the error the step returned.
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// StepTimeoutOptions is the set of options that can be configured for the
+// timeout of a step.
+type StepTimeoutOptions struct {
+	// The duration the step is given to complete. A value of 0 (the default)
+	// does not bound the step, other than by Reserve.
+	Timeout time.Duration
+
+	// The duration of the deadline of the invocation's context that is
+	// reserved for the steps the step returns to, e.g. for a retry
+	// middleware to handle the error of an attempt. The step completes this
+	// long before the deadline. Has no effect if the context has no
+	// deadline.
+	Reserve time.Duration
+}
+
+// AddStepTimeout adds a middleware to the front of the step of the stack,
+// one of "Initialize", "Serialize", "Build", "Finalize", or "Deserialize",
+// that bounds the duration of the step, and the steps and handler it invokes.
+// A timeout of the Deserialize step is a timeout of each attempt of a request,
+// as it is invoked per attempt by a retry middleware of the Finalize step.
+//
+// If the step does not complete within its deadline, derived from the options
+// and the deadline of the invocation's context, its error is returned as a
+// *TimeoutError.
+//
+// The context of the step is canceled when the step returns an error. The
+// context of a step that completes is canceled once the last raw response
+// the step received is released, see ResponseReleaser, e.g. when a streaming
+// response body is closed, such that the response is read within the
+// deadline. It is canceled when the step returns if the response was
+// already released, or no response that implements ResponseReleaser was
+// received.
+func AddStepTimeout(stack *Stack, step string, optFns ...func(*StepTimeoutOptions)) error {
+	var o StepTimeoutOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	if o.Timeout < 0 || o.Reserve < 0 {
+		return fmt.Errorf("%s step timeout must not be negative", step)
+	}
+
+	ids, err := stepIDs(stack, step)
+	if err != nil {
+		return err
+	}
+	return ids.Add(&stepTimeout{step: step, options: o}, Before)
+}
+
+// ResponseReleaser is implemented by raw responses whose resources outlive
+// the invocation of the stack that received them, such as a
+// *smithyhttp.Response and its body. OnRelease must call fn once the
+// resources are released, e.g. when the body is closed, or immediately if
+// there are none.
+//
+// The deadline of a step timeout that completes is released with the last
+// raw response the step received, see AddStepTimeout.
+type ResponseReleaser interface {
+	OnRelease(fn func())
+}
+
+// timeoutReleaseKey is the context key of the timeoutRelease of the innermost
+// step timeout.
+type timeoutReleaseKey struct{}
+
+// timeoutRelease cancels the context of a step timeout when the step returns
+// successfully, or if the last raw response the step received is not yet
+// released, once it is.
+type timeoutRelease struct {
+	parent *timeoutRelease
+	cancel context.CancelFunc
+
+	mu sync.Mutex
+
+	// the generation of the last response received, and whether it is
+	// released
+	gen      int
+	tracked  bool
+	released bool
+
+	// whether the step returned its output
+	returned bool
+}
+
+// trackTimeoutReleases tracks the raw response received by the handler of a
+// stack with the step timeouts of the context, if it is a ResponseReleaser.
+func trackTimeoutReleases(ctx context.Context, resp interface{}) {
+	r, ok := resp.(ResponseReleaser)
+	if !ok {
+		return
+	}
+	for t, _ := ctx.Value(timeoutReleaseKey{}).(*timeoutRelease); t != nil; t = t.parent {
+		t.track(r)
+	}
+}
+
+func (t *timeoutRelease) track(r ResponseReleaser) {
+	t.mu.Lock()
+	t.gen++
+	gen := t.gen
+	t.tracked, t.released = true, false
+	t.mu.Unlock()
+
+	r.OnRelease(func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		// a response of an earlier attempt, or one already released
+		if gen != t.gen || t.released {
+			return
+		}
+		t.released = true
+		if t.returned {
+			t.cancel()
+		}
+	})
+}
+
+// complete is called when the step returns an output, and cancels the
+// context unless the last response received is not yet released.
+func (t *timeoutRelease) complete() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.returned = true
+	if !t.tracked || t.released {
+		t.cancel()
+	}
+}
+
+type stepTimeout struct {
+	step    string
+	options StepTimeoutOptions
+}
+
+// ID returns the identifier for the middleware.
+func (m *stepTimeout) ID() string {
+	return m.step + "Timeout"
+}
+
+// withDeadline returns the context of the step, and the duration it is
+// given to complete, or false if the step is not bounded.
+func (m *stepTimeout) withDeadline(ctx context.Context) (context.Context, context.CancelFunc, time.Duration, bool) {
+	now := time.Now()
+
+	var deadline time.Time
+	if m.options.Timeout > 0 {
+		deadline = now.Add(m.options.Timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && m.options.Reserve > 0 {
+		if d = d.Add(-m.options.Reserve); deadline.IsZero() || d.Before(deadline) {
+			deadline = d
+		}
+	}
+	if deadline.IsZero() {
+		return ctx, nil, 0, false
+	}
+
+	stepCtx, cancel := context.WithDeadline(ctx, deadline)
+	return stepCtx, cancel, deadline.Sub(now), true
+}
+
+// handle invokes the step with the context of its deadline, returning an
+// error of the step's deadline as a *TimeoutError.
+func (m *stepTimeout) handle(ctx context.Context, next func(context.Context) error) error {
+	stepCtx, cancel, timeout, ok := m.withDeadline(ctx)
+	if !ok {
+		return next(ctx)
+	}
+
+	release := &timeoutRelease{cancel: cancel}
+	release.parent, _ = ctx.Value(timeoutReleaseKey{}).(*timeoutRelease)
+	stepCtx = context.WithValue(stepCtx, timeoutReleaseKey{}, release)
+
+	err := next(stepCtx)
+	if err == nil {
+		release.complete()
+		return nil
+	}
+
+	// the step's deadline is exceeded, rather than the invocation's
+	timedOut := errors.Is(stepCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+	cancel()
+	if timedOut {
+		return &TimeoutError{Step: m.step, Timeout: timeout, Err: err}
+	}
+	return err
+}
+
+func (m *stepTimeout) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	err = m.handle(ctx, func(ctx context.Context) (err error) {
+		out, metadata, err = next.HandleInitialize(ctx, in)
+		return err
+	})
+	return out, metadata, err
+}
+
+func (m *stepTimeout) HandleSerialize(ctx context.Context, in SerializeInput, next SerializeHandler) (
+	out SerializeOutput, metadata Metadata, err error,
+) {
+	err = m.handle(ctx, func(ctx context.Context) (err error) {
+		out, metadata, err = next.HandleSerialize(ctx, in)
+		return err
+	})
+	return out, metadata, err
+}
+
+func (m *stepTimeout) HandleBuild(ctx context.Context, in BuildInput, next BuildHandler) (
+	out BuildOutput, metadata Metadata, err error,
+) {
+	err = m.handle(ctx, func(ctx context.Context) (err error) {
+		out, metadata, err = next.HandleBuild(ctx, in)
+		return err
+	})
+	return out, metadata, err
+}
+
+func (m *stepTimeout) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	err = m.handle(ctx, func(ctx context.Context) (err error) {
+		out, metadata, err = next.HandleFinalize(ctx, in)
+		return err
+	})
+	return out, metadata, err
+}
+
+func (m *stepTimeout) HandleDeserialize(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+	out DeserializeOutput, metadata Metadata, err error,
+) {
+	err = m.handle(ctx, func(ctx context.Context) (err error) {
+		out, metadata, err = next.HandleDeserialize(ctx, in)
+		return err
+	})
+	return out, metadata, err
+}
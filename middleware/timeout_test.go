@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAddStepTimeout(t *testing.T) {
+	cases := map[string]struct {
+		step          string
+		options       StepTimeoutOptions
+		ctxTimeout    time.Duration
+		handlerDelay  time.Duration
+		expectTimeout bool
+		expectCtxErr  bool
+		expectAddErr  bool
+		expectNoBound bool
+	}{
+		"completes": {
+			step:         "Deserialize",
+			options:      StepTimeoutOptions{Timeout: time.Second},
+			handlerDelay: time.Millisecond,
+		},
+		"times out": {
+			step:          "Deserialize",
+			options:       StepTimeoutOptions{Timeout: 10 * time.Millisecond},
+			handlerDelay:  time.Second,
+			expectTimeout: true,
+		},
+		"reserve of context deadline": {
+			step:          "Build",
+			options:       StepTimeoutOptions{Reserve: 900 * time.Millisecond},
+			ctxTimeout:    time.Second,
+			handlerDelay:  500 * time.Millisecond,
+			expectTimeout: true,
+		},
+		"context deadline exceeded": {
+			step:         "Finalize",
+			options:      StepTimeoutOptions{Timeout: time.Second},
+			ctxTimeout:   10 * time.Millisecond,
+			handlerDelay: time.Second,
+			expectCtxErr: true,
+		},
+		"reserve without context deadline": {
+			step:          "Initialize",
+			options:       StepTimeoutOptions{Reserve: time.Second},
+			handlerDelay:  time.Millisecond,
+			expectNoBound: true,
+		},
+		"unknown step": {
+			step:         "Send",
+			options:      StepTimeoutOptions{Timeout: time.Second},
+			expectAddErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			err := AddStepTimeout(s, c.step, func(o *StepTimeoutOptions) {
+				*o = c.options
+			})
+			if c.expectAddErr {
+				if err == nil {
+					t.Fatalf("expect error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			ctx := context.Background()
+			if c.ctxTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, c.ctxTimeout)
+				defer cancel()
+			}
+
+			_, _, err = s.HandleMiddleware(ctx, struct{}{}, HandlerFunc(
+				func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+					if _, ok := ctx.Deadline(); ok == c.expectNoBound {
+						t.Errorf("expect deadline %v, got %v", !c.expectNoBound, ok)
+					}
+					select {
+					case <-time.After(c.handlerDelay):
+						return nil, Metadata{}, nil
+					case <-ctx.Done():
+						return nil, Metadata{}, ctx.Err()
+					}
+				}))
+
+			var terr *TimeoutError
+			if e, a := c.expectTimeout, errors.As(err, &terr); e != a {
+				t.Fatalf("expect timeout error %v, got %v", e, err)
+			}
+			if c.expectTimeout {
+				if e, a := c.step, terr.Step; e != a {
+					t.Errorf("expect step %v, got %v", e, a)
+				}
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("expect %v, got %v", context.DeadlineExceeded, err)
+				}
+				return
+			}
+			if c.expectCtxErr {
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("expect %v, got %v", context.DeadlineExceeded, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("expect no error, got %v", err)
+			}
+		})
+	}
+}
+
+// releaserResponse is a raw response whose release is triggered by the test.
+type releaserResponse struct {
+	release func()
+}
+
+func (r *releaserResponse) OnRelease(fn func()) { r.release = fn }
+
+func TestStepTimeout_Release(t *testing.T) {
+	cases := map[string]struct {
+		step string
+
+		// the raw responses the handler returns, in turn
+		responses []interface{}
+
+		// releases responses[i] before the stack returns
+		releaseBefore []int
+
+		expectCanceled bool
+	}{
+		"no releaser": {
+			step:           "Deserialize",
+			responses:      []interface{}{struct{}{}},
+			expectCanceled: true,
+		},
+		"released before return": {
+			step:           "Deserialize",
+			responses:      []interface{}{&releaserResponse{}},
+			releaseBefore:  []int{0},
+			expectCanceled: true,
+		},
+		"not released": {
+			step:      "Initialize",
+			responses: []interface{}{&releaserResponse{}},
+		},
+		"earlier attempt released": {
+			step:          "Initialize",
+			responses:     []interface{}{&releaserResponse{}, &releaserResponse{}},
+			releaseBefore: []int{0},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			s := NewStack("fooStack", func() interface{} { return struct{}{} })
+			noError(t, AddStepTimeout(s, c.step, func(o *StepTimeoutOptions) {
+				o.Timeout = time.Minute
+			}))
+			// invokes the handler once per response, as a retry middleware
+			// would attempt the request
+			noError(t, s.Finalize.Add(FinalizeMiddlewareFunc("retry", func(
+				ctx context.Context, in FinalizeInput, next FinalizeHandler,
+			) (out FinalizeOutput, metadata Metadata, err error) {
+				for range c.responses {
+					if out, metadata, err = next.HandleFinalize(ctx, in); err != nil {
+						return out, metadata, err
+					}
+				}
+				for _, i := range c.releaseBefore {
+					c.responses[i].(*releaserResponse).release()
+				}
+				return out, metadata, err
+			}), After))
+
+			var stepCtx context.Context
+			var attempt int
+			_, _, err := s.HandleMiddleware(context.Background(), struct{}{}, HandlerFunc(
+				func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+					stepCtx = ctx
+					resp := c.responses[attempt]
+					attempt++
+					return resp, Metadata{}, nil
+				}))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.expectCanceled, stepCtx.Err() != nil; e != a {
+				t.Fatalf("expect canceled %v, got %v", e, a)
+			}
+			if c.expectCanceled {
+				return
+			}
+
+			// the context is canceled once the last response is released
+			last := c.responses[len(c.responses)-1].(*releaserResponse)
+			last.release()
+			if stepCtx.Err() == nil {
+				t.Errorf("expect canceled once the last response is released")
+			}
+		})
+	}
+}
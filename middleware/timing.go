@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StackTimings is the wall-clock duration of each step and middleware of an
+// invocation of a Stack, recorded if instrumentation is enabled with
+// WithTiming. Get the timings from the metadata of the invocation with
+// GetStackTimings.
+type StackTimings struct {
+	// The ID of the stack.
+	ID string
+
+	// The steps invoked, in the order they were first invoked.
+	Steps []StepTiming
+
+	// The duration of the handler decorated by the stack, e.g. the round trip
+	// of the HTTP request, summed over its calls.
+	Handler time.Duration
+}
+
+// StepTiming is the duration of a step of a stack, and of its middleware.
+type StepTiming struct {
+	// The ID of the step, e.g. "Build stack step".
+	ID string
+
+	// The duration of the step, including its middleware, the steps after
+	// it, and the handler, summed over its calls.
+	Duration time.Duration
+
+	// The duration of the step and its middleware, excluding the steps after
+	// it and the handler, e.g. the time spent serializing a request.
+	Self time.Duration
+
+	// The middleware invoked, in the order they were first invoked.
+	Middleware []MiddlewareTiming
+}
+
+// MiddlewareTiming is the duration of a middleware of a step.
+type MiddlewareTiming struct {
+	// The ID of the middleware.
+	ID string
+
+	// The number of times the middleware was called, e.g. once per attempt
+	// for a middleware after a retry middleware.
+	Calls int
+
+	// The duration of the middleware, including the middleware, steps, and
+	// handler it calls, summed over its calls.
+	Duration time.Duration
+
+	// The duration of the middleware, excluding the middleware, steps, and
+	// handler it calls, e.g. the time spent signing a request.
+	Self time.Duration
+}
+
+var stackTimingsKey = NewMetadataKey[*StackTimings]("StackTimings")
+
+// WithTiming returns a context that enables recording of the duration of
+// each step and middleware of stacks invoked with it. The timings of an
+// invocation are set in its metadata, see GetStackTimings.
+//
+// Recording adds the overhead of reading the clock twice per middleware.
+func WithTiming(ctx context.Context) context.Context {
+	return withDecorations(ctx, func(d *decorations) { d.timing = true })
+}
+
+// GetStackTimings returns the timings of a stack invocation from its
+// metadata, if instrumentation was enabled with WithTiming.
+func GetStackTimings(metadata MetadataReader) (*StackTimings, bool) {
+	return stackTimingsKey.Get(metadata)
+}
+
+func setStackTimings(metadata *Metadata, t *StackTimings) {
+	stackTimingsKey.Set(metadata, t)
+}
+
+// timingRecorder records the durations of the steps and middleware of a
+// stack invocation.
+type timingRecorder struct {
+	mu      sync.Mutex
+	steps   []*stepTimer
+	handler timer
+}
+
+// timer accumulates the durations of calls.
+type timer struct {
+	rec      *timingRecorder
+	calls    int
+	duration time.Duration
+}
+
+// record adds the duration since start, and is called deferred with the start
+// time of the call.
+func (t *timer) record(start time.Time) {
+	d := time.Since(start)
+
+	t.rec.mu.Lock()
+	t.calls++
+	t.duration += d
+	t.rec.mu.Unlock()
+}
+
+type stepTimer struct {
+	timer
+	id         string
+	middleware []*middlewareTimer
+}
+
+type middlewareTimer struct {
+	timer
+	id string
+}
+
+// middlewareTimers are the timers of the middleware of a step, in order. A nil
+// slice has a nil timer for each middleware.
+type middlewareTimers []*middlewareTimer
+
+func (t middlewareTimers) at(i int) *middlewareTimer {
+	if t == nil {
+		return nil
+	}
+	return t[i]
+}
+
+func newTimingRecorder() *timingRecorder {
+	rec := &timingRecorder{}
+	rec.handler.rec = rec
+	return rec
+}
+
+// timers returns the timers of the step and its middleware, which are added
+// to the recorder in order when the step is first invoked. Returns nil timers
+// if the recorder is nil.
+func (r *timingRecorder) timers(stepID string, order []interface{}) (*stepTimer, middlewareTimers) {
+	if r == nil {
+		return nil, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var step *stepTimer
+	for _, s := range r.steps {
+		if s.id == stepID {
+			step = s
+			break
+		}
+	}
+	if step == nil {
+		step = &stepTimer{timer: timer{rec: r}, id: stepID}
+		r.steps = append(r.steps, step)
+	}
+
+	timers := make(middlewareTimers, len(order))
+	for i, m := range order {
+		id := m.(ider).ID()
+		for _, t := range step.middleware {
+			if t.id == id {
+				timers[i] = t
+				break
+			}
+		}
+		if timers[i] == nil {
+			timers[i] = &middlewareTimer{timer: timer{rec: r}, id: id}
+			step.middleware = append(step.middleware, timers[i])
+		}
+	}
+	return step, timers
+}
+
+// timings returns the recorded timings. The self duration of each step and
+// middleware is its duration less that of the step, middleware, or handler
+// that follows it.
+func (r *timingRecorder) timings(stackID string) *StackTimings {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := &StackTimings{ID: stackID, Handler: r.handler.duration}
+	for i, s := range r.steps {
+		next := r.handler.duration
+		if i+1 < len(r.steps) {
+			next = r.steps[i+1].duration
+		}
+
+		step := StepTiming{
+			ID:       s.id,
+			Duration: s.duration,
+			Self:     nonNegative(s.duration - next),
+		}
+		for j, m := range s.middleware {
+			mnext := next
+			if j+1 < len(s.middleware) {
+				mnext = s.middleware[j+1].duration
+			}
+			step.Middleware = append(step.Middleware, MiddlewareTiming{
+				ID:       m.id,
+				Calls:    m.calls,
+				Duration: m.duration,
+				Self:     nonNegative(m.duration - mnext),
+			})
+		}
+		t.Steps = append(t.Steps, step)
+	}
+	return t
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
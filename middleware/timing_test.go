@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStackTimings(t *testing.T) {
+	const sleep = 10 * time.Millisecond
+
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+	s.Initialize.Add(mockInitializeMiddleware("initialize"), After)
+	s.Build.Add(BuildMiddlewareFunc("sign", func(
+		ctx context.Context, in BuildInput, next BuildHandler,
+	) (BuildOutput, Metadata, error) {
+		time.Sleep(sleep)
+		return next.HandleBuild(ctx, in)
+	}), After)
+	s.Finalize.Add(FinalizeMiddlewareFunc("retry", func(
+		ctx context.Context, in FinalizeInput, next FinalizeHandler,
+	) (FinalizeOutput, Metadata, error) {
+		next.HandleFinalize(ctx, in)
+		return next.HandleFinalize(ctx, in)
+	}), After)
+	s.Deserialize.Add(mockDeserializeMiddleware("deserialize"), After)
+
+	handler := HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+		time.Sleep(sleep)
+		return nil, Metadata{}, nil
+	})
+
+	_, metadata, err := s.HandleMiddleware(WithTiming(context.Background()), struct{}{}, handler)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	timings, ok := GetStackTimings(&metadata)
+	if !ok {
+		t.Fatalf("expect timings in metadata")
+	}
+	if e, a := "fooStack", timings.ID; e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+	if timings.Handler < 2*sleep {
+		t.Errorf("expect handler duration at least %v, got %v", 2*sleep, timings.Handler)
+	}
+
+	var steps []string
+	middleware := map[string]MiddlewareTiming{}
+	for _, step := range timings.Steps {
+		steps = append(steps, step.ID)
+		if step.Self > step.Duration {
+			t.Errorf("expect %v self duration at most %v, got %v", step.ID, step.Duration, step.Self)
+		}
+		for _, m := range step.Middleware {
+			middleware[m.ID] = m
+		}
+	}
+	expectSteps := []string{
+		(*InitializeStep)(nil).ID(),
+		(*SerializeStep)(nil).ID(),
+		(*BuildStep)(nil).ID(),
+		(*FinalizeStep)(nil).ID(),
+		(*DeserializeStep)(nil).ID(),
+	}
+	if e, a := len(expectSteps), len(steps); e != a {
+		t.Fatalf("expect %v steps, got %v", expectSteps, steps)
+	}
+	for i := range expectSteps {
+		if e, a := expectSteps[i], steps[i]; e != a {
+			t.Errorf("expect step %v, got %v", e, a)
+		}
+	}
+	if e, a := 2, len(timings.Steps[0].Middleware)+len(timings.Steps[2].Middleware); e != a {
+		t.Errorf("expect %v middleware, got %v", e, a)
+	}
+
+	sign := middleware["sign"]
+	if sign.Self < sleep || sign.Self > sign.Duration {
+		t.Errorf("expect sign self duration between %v and %v, got %v", sleep, sign.Duration, sign.Self)
+	}
+	if timings.Steps[2].Self < sleep {
+		t.Errorf("expect build step self duration at least %v, got %v", sleep, timings.Steps[2].Self)
+	}
+	if e, a := 1, middleware["retry"].Calls; e != a {
+		t.Errorf("expect %v calls, got %v", e, a)
+	}
+	if e, a := 2, middleware["deserialize"].Calls; e != a {
+		t.Errorf("expect %v calls, got %v", e, a)
+	}
+	if self := middleware["deserialize"].Self; self >= sleep {
+		t.Errorf("expect deserialize self duration less than %v, got %v", sleep, self)
+	}
+}
+
+func TestStackTimings_Disabled(t *testing.T) {
+	s := NewStack("fooStack", func() interface{} { return struct{}{} })
+	s.Build.Add(mockBuildMiddleware("build"), After)
+
+	_, metadata, err := s.HandleMiddleware(context.Background(), struct{}{},
+		HandlerFunc(func(ctx context.Context, input interface{}) (interface{}, Metadata, error) {
+			return nil, Metadata{}, nil
+		}))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if _, ok := GetStackTimings(&metadata); ok {
+		t.Errorf("expect no timings in metadata")
+	}
+}
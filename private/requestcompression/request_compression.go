@@ -13,6 +13,7 @@ import (
 	"github.com/aws/smithy-go/middleware"
 	"github.com/aws/smithy-go/transport/http"
 	"io"
+	"sync"
 )
 
 const MaxRequestMinCompressSizeBytes = 10485760
@@ -20,12 +21,60 @@ const MaxRequestMinCompressSizeBytes = 10485760
 // Enumeration values for supported compress Algorithms.
 const (
 	GZIP = "gzip"
+
+	// ZSTD is supported once its implementation in the optional
+	// github.com/aws/smithy-go/transport/http/zstd module is registered.
+	ZSTD = "zstd"
+)
+
+var (
+	allowedAlgorithmsMu sync.RWMutex
+	allowedAlgorithms   = map[string]http.ContentEncoding{
+		GZIP: http.GzipContentEncoding{},
+	}
 )
 
-type compressFunc func(io.Reader) ([]byte, error)
+// RegisterContentEncoding makes the content encoding available as a request
+// compression algorithm under its name, e.g. ZSTD, whose implementation is in
+// an optional module, such that its dependencies are not required by this
+// module. An encoding registered with the name of an existing algorithm
+// replaces it.
+func RegisterContentEncoding(e http.ContentEncoding) {
+	allowedAlgorithmsMu.Lock()
+	defer allowedAlgorithmsMu.Unlock()
+
+	allowedAlgorithms[e.Name()] = e
+}
+
+func getContentEncoding(algorithm string) http.ContentEncoding {
+	allowedAlgorithmsMu.RLock()
+	defer allowedAlgorithmsMu.RUnlock()
+	return allowedAlgorithms[algorithm]
+}
+
+func compress(e http.ContentEncoding, input io.Reader) ([]byte, error) {
+	var b bytes.Buffer
+	if err := compressTo(e, &b, input); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func compressTo(e http.ContentEncoding, dst io.Writer, input io.Reader) error {
+	w, err := e.NewWriter(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create %s writer, %v", e.Name(), err)
+	}
+
+	if _, err = io.Copy(w, input); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write payload to be compressed, %v", err)
+	}
+	if err = w.Close(); err != nil {
+		return fmt.Errorf("failed to flush payload being compressed, %v", err)
+	}
 
-var allowedAlgorithms = map[string]compressFunc{
-	GZIP: gzipCompress,
+	return nil
 }
 
 // AddRequestCompression add requestCompression middleware to op stack
@@ -48,7 +97,11 @@ func (m requestCompression) ID() string {
 	return "RequestCompression"
 }
 
-// HandleSerialize gzip compress the request's stream/body if enabled by config fields
+// HandleSerialize compresses the request's stream/body with the first
+// supported algorithm if enabled by config fields. A stream of known length is
+// compressed if it is at least the minimum size, and the request's
+// Content-Length is set to the length of the compressed stream. A stream of
+// unknown length is always compressed, and sent without a Content-Length.
 func (m requestCompression) HandleSerialize(
 	ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
 ) (
@@ -68,35 +121,57 @@ func (m requestCompression) HandleSerialize(
 	}
 
 	for _, algorithm := range m.compressAlgorithms {
-		compressFunc := allowedAlgorithms[algorithm]
-		if compressFunc != nil {
-			if stream := req.GetStream(); stream != nil {
-				size, found, err := req.StreamLength()
-				if err != nil {
-					return out, metadata, fmt.Errorf("error while finding request stream length, %v", err)
-				} else if !found || size < m.requestMinCompressSizeBytes {
-					return next.HandleSerialize(ctx, in)
-				}
-
-				compressedBytes, err := compressFunc(stream)
-				if err != nil {
-					return out, metadata, fmt.Errorf("failed to compress request stream, %v", err)
-				}
-
-				var newReq *http.Request
-				if newReq, err = req.SetStream(bytes.NewReader(compressedBytes)); err != nil {
-					return out, metadata, fmt.Errorf("failed to set request stream, %v", err)
-				}
-				*req = *newReq
-
-				if val := req.Header.Get("Content-Encoding"); val != "" {
-					req.Header.Set("Content-Encoding", fmt.Sprintf("%s, %s", val, algorithm))
-				} else {
-					req.Header.Set("Content-Encoding", algorithm)
-				}
-			}
+		encoding := getContentEncoding(algorithm)
+		if encoding == nil {
+			continue
+		}
+
+		stream := req.GetStream()
+		if stream == nil {
 			break
 		}
+		size, found, err := req.StreamLength()
+		if err != nil {
+			return out, metadata, fmt.Errorf("error while finding request stream length, %v", err)
+		} else if found && size < m.requestMinCompressSizeBytes {
+			return next.HandleSerialize(ctx, in)
+		}
+
+		var newReq *http.Request
+		if found {
+			compressedBytes, err := compress(encoding, stream)
+			if err != nil {
+				return out, metadata, fmt.Errorf("failed to compress request stream, %v", err)
+			}
+			if newReq, err = req.SetStream(bytes.NewReader(compressedBytes)); err != nil {
+				return out, metadata, fmt.Errorf("failed to set request stream, %v", err)
+			}
+			newReq.ContentLength = int64(len(compressedBytes))
+		} else {
+			// a stream of unknown length, e.g. of a streaming operation, is
+			// compressed regardless of its size as it is sent, with chunked
+			// transfer encoding
+			pr, pw := io.Pipe()
+			go func() {
+				pw.CloseWithError(compressTo(encoding, pw, stream))
+			}()
+			// unblock the compression of a stream that was not sent in full
+			defer pr.Close()
+
+			if newReq, err = req.SetStream(pr); err != nil {
+				return out, metadata, fmt.Errorf("failed to set request stream, %v", err)
+			}
+			newReq.ContentLength = -1
+		}
+		*req = *newReq
+		req.Header.Del("Content-Length")
+
+		if val := req.Header.Get("Content-Encoding"); val != "" {
+			req.Header.Set("Content-Encoding", fmt.Sprintf("%s, %s", val, algorithm))
+		} else {
+			req.Header.Set("Content-Encoding", algorithm)
+		}
+		break
 	}
 
 	return next.HandleSerialize(ctx, in)
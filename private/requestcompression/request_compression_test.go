@@ -123,3 +123,152 @@ func testUnzipContent(content io.Reader, expect []byte, disableRequestCompressio
 
 	return nil
 }
+
+// testReverseEncoding is a stand-in for a content encoding implemented
+// outside of this module, which reverses its content.
+type testReverseEncoding struct{}
+
+func (testReverseEncoding) Name() string { return "x-reverse" }
+
+func (testReverseEncoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(reverseBytes(b))), nil
+}
+
+func (testReverseEncoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return &reverseWriter{w: w}, nil
+}
+
+type reverseWriter struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+func (r *reverseWriter) Write(p []byte) (int, error) { return r.buf.Write(p) }
+
+func (r *reverseWriter) Close() error {
+	_, err := r.w.Write(reverseBytes(r.buf.Bytes()))
+	return err
+}
+
+func reverseBytes(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+func TestRequestCompression_RegisteredContentEncoding(t *testing.T) {
+	RegisterContentEncoding(testReverseEncoding{})
+
+	req := http.NewStackRequest().(*http.Request)
+	req, _ = req.SetStream(strings.NewReader("Hi, world!"))
+
+	m := requestCompression{
+		compressAlgorithms: []string{ZSTD, "x-reverse", GZIP},
+	}
+	var updatedRequest *http.Request
+	_, _, err := m.HandleSerialize(context.Background(),
+		middleware.SerializeInput{Request: req},
+		middleware.SerializeHandlerFunc(func(ctx context.Context, input middleware.SerializeInput) (
+			out middleware.SerializeOutput, metadata middleware.Metadata, err error) {
+			updatedRequest = input.Request.(*http.Request)
+			return out, metadata, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	// zstd is skipped, since no implementation is registered
+	if e, a := "x-reverse", updatedRequest.Header.Get("Content-Encoding"); e != a {
+		t.Errorf("expect content encoding %v, got %v", e, a)
+	}
+	b, err := io.ReadAll(updatedRequest.GetStream())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := "!dlrow ,iH", string(b); e != a {
+		t.Errorf("expect stream %v, got %v", e, a)
+	}
+}
+
+// unknownLengthReader hides the length of the reader it wraps.
+type unknownLengthReader struct {
+	io.Reader
+}
+
+func TestRequestCompression_ContentLength(t *testing.T) {
+	const payload = "Hi, world!"
+
+	cases := map[string]struct {
+		MinCompressSizeBytes int64
+		Stream               io.Reader
+		ExpectCompressed     bool
+		ExpectUnknownLength  bool
+	}{
+		"known length": {
+			Stream:           strings.NewReader(payload),
+			ExpectCompressed: true,
+		},
+		"known length below min size": {
+			MinCompressSizeBytes: 100,
+			Stream:               strings.NewReader(payload),
+		},
+		"unknown length above min size": {
+			MinCompressSizeBytes: 100,
+			Stream:               unknownLengthReader{strings.NewReader(payload)},
+			ExpectCompressed:     true,
+			ExpectUnknownLength:  true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := http.NewStackRequest().(*http.Request)
+			req, _ = req.SetStream(c.Stream)
+			req.ContentLength = int64(len(payload))
+			req.Header.Set("Content-Length", fmt.Sprint(len(payload)))
+
+			m := requestCompression{
+				requestMinCompressSizeBytes: c.MinCompressSizeBytes,
+				compressAlgorithms:          []string{GZIP},
+			}
+			var contentLength int64
+			var header string
+			var body []byte
+			_, _, err := m.HandleSerialize(context.Background(),
+				middleware.SerializeInput{Request: req},
+				middleware.SerializeHandlerFunc(func(ctx context.Context, input middleware.SerializeInput) (
+					out middleware.SerializeOutput, metadata middleware.Metadata, err error) {
+					r := input.Request.(*http.Request)
+					contentLength = r.ContentLength
+					header = r.Header.Get("Content-Length")
+					body, err = io.ReadAll(r.GetStream())
+					return out, metadata, err
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			expectLength := int64(len(body))
+			if c.ExpectUnknownLength {
+				expectLength = -1
+			}
+			if e, a := expectLength, contentLength; e != a {
+				t.Errorf("expect content length %v, got %v", e, a)
+			}
+			if c.ExpectCompressed && len(header) != 0 {
+				t.Errorf("expect no Content-Length header, got %v", header)
+			}
+			if err := testUnzipContent(bytes.NewReader(body), []byte(payload), !c.ExpectCompressed, 0); err != nil {
+				t.Errorf("error while checking request stream: %q", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Package tracing defines the tracing APIs used by Smithy clients, which are
+// implemented by a TracerProvider of the application, e.g. an adapter to
+// OpenTelemetry.
+package tracing
+
+import (
+	"context"
+
+	"github.com/aws/smithy-go"
+)
+
+// SpanStatus records the "success" state of an observed span.
+type SpanStatus int
+
+// Enumeration of SpanStatus.
+const (
+	SpanStatusUnset SpanStatus = iota
+	SpanStatusOK
+	SpanStatusError
+)
+
+// SpanKind indicates the nature of the work being performed.
+type SpanKind int
+
+// Enumeration of SpanKind.
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindClient
+)
+
+// TracerProvider is the entry point for creating client traces.
+type TracerProvider interface {
+	Tracer(scope string, opts ...TracerOption) Tracer
+}
+
+// TracerOption applies configuration to a tracer.
+type TracerOption func(o *TracerOptions)
+
+// TracerOptions represent configuration for tracers.
+type TracerOptions struct {
+	Properties smithy.Properties
+}
+
+// Tracer is the entry point for creating observed client Spans.
+//
+// Spans created by tracers propagate by existing on the Context. Consumers of
+// the API can use this to nest Spans.
+type Tracer interface {
+	StartSpan(ctx context.Context, name string, opts ...SpanOption) (context.Context, Span)
+}
+
+// SpanOption applies configuration to a span.
+type SpanOption func(o *SpanOptions)
+
+// SpanOptions represent configuration for span events.
+type SpanOptions struct {
+	Kind       SpanKind
+	Properties smithy.Properties
+}
+
+// WithSpanKind sets the kind of a span.
+func WithSpanKind(kind SpanKind) SpanOption {
+	return func(o *SpanOptions) {
+		o.Kind = kind
+	}
+}
+
+// WithSpanProperties sets the properties of a span, e.g. the attributes of
+// an OpenTelemetry span.
+func WithSpanProperties(props smithy.Properties) SpanOption {
+	return func(o *SpanOptions) {
+		o.Properties.SetAll(&props)
+	}
+}
+
+// Span is a single unit of application work.
+type Span interface {
+	Name() string
+	SetStatus(SpanStatus)
+	SetProperty(k, v any)
+	End()
+}
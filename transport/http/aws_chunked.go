@@ -0,0 +1,251 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// AWSChunkedContentEncoding is the name of the aws-chunked content encoding,
+// which frames a payload in chunks followed by its trailers, such that
+// trailers are sent in the payload itself rather than as HTTP trailers.
+const AWSChunkedContentEncoding = "aws-chunked"
+
+// DefaultAWSChunkSize is the default size in bytes of the chunks of a payload
+// encoded with aws-chunked.
+const DefaultAWSChunkSize = 64 * 1024
+
+// AWSChunkedOptions is the set of options that can be configured for the
+// aws-chunked encoding of a payload.
+type AWSChunkedOptions struct {
+	// The size in bytes of the chunks of the payload, except the last.
+	// Defaults to DefaultAWSChunkSize if zero.
+	ChunkSize int
+}
+
+// NewAWSChunkedReader returns a reader of the stream encoded with aws-chunked,
+// followed by the trailers, whose values are read once the stream is read to
+// EOF, like the Trailer of a http.Request. The trailers are sent in the order
+// of their names.
+//
+// The reader is an io.Seeker if the stream is, which only supports rewinding
+// the reader to its start, such that a request can be retried.
+func NewAWSChunkedReader(stream io.Reader, trailer http.Header, optFns ...func(*AWSChunkedOptions)) (io.Reader, error) {
+	var o AWSChunkedOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	if o.ChunkSize == 0 {
+		o.ChunkSize = DefaultAWSChunkSize
+	}
+	if o.ChunkSize < 0 {
+		return nil, fmt.Errorf("aws-chunked chunk size must not be negative, %v", o.ChunkSize)
+	}
+
+	r := &awsChunkedReader{
+		stream:  stream,
+		trailer: trailer,
+		chunk:   make([]byte, o.ChunkSize),
+	}
+
+	s, ok := stream.(io.Seeker)
+	if !ok {
+		return r, nil
+	}
+	start, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get position of aws-chunked stream, %w", err)
+	}
+	return &seekableAWSChunkedReader{awsChunkedReader: r, start: start}, nil
+}
+
+// awsChunkedReader encodes a stream with aws-chunked as it is read.
+type awsChunkedReader struct {
+	stream  io.Reader
+	trailer http.Header
+	chunk   []byte
+
+	// the encoded content not yet read, and whether it includes the end of
+	// the payload
+	buf  bytes.Buffer
+	done bool
+
+	// the position of the reader in the encoded content
+	pos int64
+}
+
+func (r *awsChunkedReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.encodeChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, _ := r.buf.Read(p)
+	r.pos += int64(n)
+	return n, nil
+}
+
+// encodeChunk encodes the next chunk of the stream, and the end of the
+// payload once the stream is read to EOF.
+func (r *awsChunkedReader) encodeChunk() error {
+	n, err := io.ReadFull(r.stream, r.chunk)
+	if n > 0 {
+		r.buf.WriteString(strconv.FormatInt(int64(n), 16))
+		r.buf.WriteString("\r\n")
+		r.buf.Write(r.chunk[:n])
+		r.buf.WriteString("\r\n")
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		r.encodeEnd()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read aws-chunked stream, %w", err)
+	}
+	return nil
+}
+
+// encodeEnd encodes the last, empty, chunk of the payload, and its trailers.
+func (r *awsChunkedReader) encodeEnd() {
+	r.buf.WriteString("0\r\n")
+	for _, name := range trailerNames(r.trailer) {
+		r.buf.WriteString(strings.ToLower(name))
+		r.buf.WriteString(":")
+		r.buf.WriteString(strings.Join(r.trailer.Values(name), ","))
+		r.buf.WriteString("\r\n")
+	}
+	r.buf.WriteString("\r\n")
+	r.done = true
+}
+
+// seekableAWSChunkedReader is an awsChunkedReader of a seekable stream.
+type seekableAWSChunkedReader struct {
+	*awsChunkedReader
+
+	// the position of the stream at the start of the payload
+	start int64
+}
+
+func (r *seekableAWSChunkedReader) Seek(offset int64, whence int) (int64, error) {
+	switch {
+	case offset == 0 && whence == io.SeekCurrent:
+		return r.pos, nil
+	case offset == 0 && whence == io.SeekStart:
+		if _, err := r.stream.(io.Seeker).Seek(r.start, io.SeekStart); err != nil {
+			return r.pos, fmt.Errorf("failed to rewind aws-chunked stream, %w", err)
+		}
+		r.buf.Reset()
+		r.done = false
+		r.pos = 0
+		return 0, nil
+	default:
+		return r.pos, fmt.Errorf("aws-chunked stream can only be rewound to its start, length is unknown")
+	}
+}
+
+func trailerNames(trailer http.Header) []string {
+	names := make([]string, 0, len(trailer))
+	for name := range trailer {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddAWSChunkedEncodingMiddleware adds middleware to the end of the Build step
+// that encodes request payloads that are sent with trailers, e.g. by
+// AddRequestChecksumMiddleware, with aws-chunked, sending the trailers in the
+// payload rather than as HTTP trailers. It must be added after the middleware
+// that sets the trailers, and after ComputeContentLength, as the length of the
+// encoded payload is not known.
+//
+// The aws-chunked encoding is appended to the Content-Encoding header of the
+// request, the names of the trailers are set in the X-Amz-Trailer header, and
+// the length of the payload, if known, in the X-Amz-Decoded-Content-Length
+// header. The encoded payload is sent with chunked transfer encoding. The
+// chunks are not signed.
+func AddAWSChunkedEncodingMiddleware(stack *middleware.Stack, optFns ...func(*AWSChunkedOptions)) error {
+	var o AWSChunkedOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	if o.ChunkSize < 0 {
+		return fmt.Errorf("aws-chunked chunk size must not be negative, %v", o.ChunkSize)
+	}
+
+	return stack.Build.Add(&awsChunkedEncodingMiddleware{options: o}, middleware.After)
+}
+
+type awsChunkedEncodingMiddleware struct {
+	options AWSChunkedOptions
+}
+
+// ID is the middleware identifier.
+func (*awsChunkedEncodingMiddleware) ID() string {
+	return "AWSChunkedEncoding"
+}
+
+func (m *awsChunkedEncodingMiddleware) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	ts, ok := req.GetStream().(trailerStream)
+	if !ok {
+		return next.HandleBuild(ctx, in)
+	}
+
+	size, known, err := req.StreamLength()
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to get length of request stream, %w", err)
+	}
+
+	trailer := http.Header{}
+	ts.bindTrailer(trailer)
+	names := trailerNames(trailer)
+	for i := range names {
+		names[i] = strings.ToLower(names[i])
+	}
+
+	body, err := NewAWSChunkedReader(req.GetStream(), trailer, func(o *AWSChunkedOptions) {
+		*o = m.options
+	})
+	if err != nil {
+		return out, metadata, err
+	}
+	encoded, err := req.SetStream(body)
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to set request stream, %w", err)
+	}
+	*req = *encoded
+	req.ContentLength = -1
+	req.Header.Del("Content-Length")
+
+	if v := req.Header.Get("Content-Encoding"); len(v) != 0 {
+		req.Header.Set("Content-Encoding", v+", "+AWSChunkedContentEncoding)
+	} else {
+		req.Header.Set("Content-Encoding", AWSChunkedContentEncoding)
+	}
+	req.Header.Set("X-Amz-Trailer", strings.Join(names, ","))
+	if known {
+		req.Header.Set("X-Amz-Decoded-Content-Length", strconv.FormatInt(size, 10))
+	}
+
+	return next.HandleBuild(ctx, in)
+}
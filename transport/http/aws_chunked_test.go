@@ -0,0 +1,204 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestAWSChunkedReader(t *testing.T) {
+	cases := map[string]struct {
+		body      string
+		chunkSize int
+		trailer   http.Header
+		expect    string
+	}{
+		"chunks": {
+			body:      "hello world",
+			chunkSize: 4,
+			trailer:   http.Header{"X-Amz-Checksum-Crc32": {"DUoRhQ=="}},
+			expect: "4\r\nhell\r\n4\r\no wo\r\n3\r\nrld\r\n0\r\n" +
+				"x-amz-checksum-crc32:DUoRhQ==\r\n\r\n",
+		},
+		"exact chunk": {
+			body:      "hello",
+			chunkSize: 5,
+			expect:    "5\r\nhello\r\n0\r\n\r\n",
+		},
+		"large chunk": {
+			body:   strings.Repeat("a", 20),
+			expect: "14\r\n" + strings.Repeat("a", 20) + "\r\n0\r\n\r\n",
+		},
+		"empty": {
+			trailer: http.Header{"B": {"2"}, "A": {"1"}},
+			expect:  "0\r\na:1\r\nb:2\r\n\r\n",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewAWSChunkedReader(unknownLengthReader{strings.NewReader(c.body)}, c.trailer,
+				func(o *AWSChunkedOptions) {
+					o.ChunkSize = c.chunkSize
+				})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if _, ok := r.(io.Seeker); ok {
+				t.Errorf("expect reader of unseekable stream not to be seekable")
+			}
+
+			b, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.expect, string(b); e != a {
+				t.Errorf("expect %q, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestAWSChunkedReader_Rewind(t *testing.T) {
+	stream := strings.NewReader("xxhello")
+	stream.Seek(2, io.SeekStart)
+
+	r, err := NewAWSChunkedReader(stream, nil)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	s := r.(io.Seeker)
+
+	const expect = "5\r\nhello\r\n0\r\n\r\n"
+	for i := 0; i < 2; i++ {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if e, a := expect, string(b); e != a {
+			t.Errorf("expect %q, got %q", e, a)
+		}
+
+		if n, _ := s.Seek(0, io.SeekCurrent); int64(len(expect)) != n {
+			t.Errorf("expect position %v, got %v", len(expect), n)
+		}
+		if _, err := s.Seek(0, io.SeekStart); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+
+	if _, err := s.Seek(0, io.SeekEnd); err == nil {
+		t.Errorf("expect error seeking to end")
+	}
+}
+
+func TestAWSChunkedEncodingMiddleware(t *testing.T) {
+	const payload = "hello world"
+
+	cases := map[string]struct {
+		body          func() io.Reader
+		checksum      bool
+		expectEncoded bool
+		expectLength  string
+	}{
+		"checksum trailer": {
+			body:          func() io.Reader { return strings.NewReader(payload) },
+			checksum:      true,
+			expectEncoded: true,
+			expectLength:  "11",
+		},
+		"checksum trailer unknown length": {
+			body:          func() io.Reader { return unknownLengthReader{strings.NewReader(payload)} },
+			checksum:      true,
+			expectEncoded: true,
+		},
+		"no trailer": {
+			body: func() io.Reader { return strings.NewReader(payload) },
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var body string
+			var header, trailer http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				body = string(b)
+				header = r.Header
+				trailer = r.Trailer
+			}))
+			defer server.Close()
+			endpoint, _ := url.Parse(server.URL)
+
+			stack := middleware.NewStack("test", NewStackRequest)
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize", func(
+				ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+			) (middleware.SerializeOutput, middleware.Metadata, error) {
+				req := in.Request.(*Request)
+				req.URL = endpoint
+				req.Method = http.MethodPut
+				r, err := req.SetStream(c.body())
+				if err != nil {
+					return middleware.SerializeOutput{}, middleware.Metadata{}, err
+				}
+				in.Request = r
+				return next.HandleSerialize(ctx, in)
+			}), middleware.After)
+			if err := AddComputeContentLengthMiddleware(stack); err != nil {
+				t.Fatal(err)
+			}
+			if c.checksum {
+				if err := AddRequestChecksumMiddleware(stack, func(o *RequestChecksumOptions) {
+					o.Algorithm = ChecksumCRC32
+					o.Trailer = true
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := AddAWSChunkedEncodingMiddleware(stack); err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, err := middleware.DecorateHandler(NewClientHandler(server.Client()), stack).
+				Handle(context.Background(), struct{}{})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if !c.expectEncoded {
+				if e, a := payload, body; e != a {
+					t.Errorf("expect body %q, got %q", e, a)
+				}
+				if v := header.Get("Content-Encoding"); len(v) != 0 {
+					t.Errorf("expect no Content-Encoding, got %q", v)
+				}
+				return
+			}
+
+			expect := "b\r\nhello world\r\n0\r\nx-amz-checksum-crc32:" +
+				testChecksum(t, ChecksumCRC32, payload) + "\r\n\r\n"
+			if e, a := expect, body; e != a {
+				t.Errorf("expect body %q, got %q", e, a)
+			}
+			if len(trailer) != 0 {
+				t.Errorf("expect no HTTP trailers, got %v", trailer)
+			}
+			if e, a := "aws-chunked", header.Get("Content-Encoding"); e != a {
+				t.Errorf("expect Content-Encoding %q, got %q", e, a)
+			}
+			if e, a := "x-amz-checksum-crc32", header.Get("X-Amz-Trailer"); e != a {
+				t.Errorf("expect X-Amz-Trailer %q, got %q", e, a)
+			}
+			if e, a := c.expectLength, header.Get("X-Amz-Decoded-Content-Length"); e != a {
+				t.Errorf("expect X-Amz-Decoded-Content-Length %q, got %q", e, a)
+			}
+		})
+	}
+}
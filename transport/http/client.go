@@ -27,6 +27,9 @@ func (fn ClientDoFunc) Do(r *http.Request) (*http.Response, error) {
 // implementation is http.Client.
 type ClientHandler struct {
 	client ClientDo
+
+	// records the telemetry of requests, if any
+	telemetry *requestTelemetry
 }
 
 // NewClientHandler returns an initialized middleware handler for the client.
@@ -36,6 +39,33 @@ func NewClientHandler(client ClientDo) ClientHandler {
 	}
 }
 
+// ClientHandlerOptions is the set of options that can be configured for a
+// ClientHandler.
+type ClientHandlerOptions struct {
+	// The telemetry of the requests sent by the handler. Each request is
+	// traced with a client span, and its duration recorded.
+	Telemetry TelemetryOptions
+}
+
+// NewClientHandlerWithOptions returns an initialized middleware handler for
+// the client, configured with the options. Returns an error if the
+// instruments of the telemetry cannot be created.
+func NewClientHandlerWithOptions(client ClientDo, optFns ...func(*ClientHandlerOptions)) (ClientHandler, error) {
+	var o ClientHandlerOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	telemetry, err := newRequestTelemetry(o.Telemetry)
+	if err != nil {
+		return ClientHandler{}, err
+	}
+	return ClientHandler{
+		client:    client,
+		telemetry: telemetry,
+	}, nil
+}
+
 // Handle implements the middleware Handler interface, that will invoke the
 // underlying HTTP client. Requires the input to be a Smithy *Request. Returns
 // a smithy *Response, or error if the request failed.
@@ -47,6 +77,12 @@ func (c ClientHandler) Handle(ctx context.Context, input interface{}) (
 		return nil, metadata, fmt.Errorf("expect Smithy http.Request value as input, got unsupported type %T", input)
 	}
 
+	if c.telemetry != nil {
+		var done func(out interface{}, err error)
+		ctx, done = c.telemetry.start(ctx, req)
+		defer func() { done(out, err) }()
+	}
+
 	builtRequest := req.Build(ctx)
 	if err := ValidateEndpointHost(builtRequest.Host); err != nil {
 		return nil, metadata, err
@@ -0,0 +1,197 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// ConnectionLimitsOptions is the set of options that can be configured for a
+// ConnectionLimitsTransport.
+type ConnectionLimitsOptions struct {
+	// The maximum amount of time a connection is used for after it is
+	// established. A value of 0 (the default) does not limit the lifetime.
+	MaxLifetime time.Duration
+
+	// The maximum number of requests sent on a connection. A value of 0 (the
+	// default) does not limit requests.
+	MaxRequests int
+
+	// The keep-alive period of connections dialed by the transport, if the
+	// http.Transport does not have a dialer. Defaults to 30 seconds, as does
+	// http.DefaultTransport. A negative value disables keep-alive probes.
+	KeepAlive time.Duration
+}
+
+// ConnectionLimitsTransport is an http.RoundTripper that recycles the
+// connections of an http.Transport once they reach a maximum lifetime or
+// number of requests, such that requests are periodically sent on new
+// connections. This allows load balancers that only rebalance new
+// connections to spread a long-lived client's requests across their targets.
+//
+// A connection that reaches a limit is closed once the response of each
+// request sent on it is closed, or read to EOF, rather than returned to the
+// transport's idle pool. The request that reaches a limit is completed on the
+// connection, so a connection that is idle when its lifetime elapses is
+// closed after its next request, or by the transport's IdleConnTimeout.
+//
+// Only connections dialed with the transport's DialContext, or Dial, are
+// limited, including those the transport upgrades to TLS. Connections
+// returned by DialTLSContext, or DialTLS, are not.
+type ConnectionLimitsTransport struct {
+	transport *http.Transport
+	options   ConnectionLimitsOptions
+
+	// now returns the current time, overridden by tests
+	now func() time.Time
+}
+
+// NewConnectionLimitsTransport returns a ConnectionLimitsTransport that sends
+// requests with a clone of t.
+func NewConnectionLimitsTransport(t *http.Transport, optFns ...func(*ConnectionLimitsOptions)) *ConnectionLimitsTransport {
+	var o ConnectionLimitsOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	if o.KeepAlive == 0 {
+		o.KeepAlive = 30 * time.Second
+	}
+
+	lt := &ConnectionLimitsTransport{
+		transport: t.Clone(),
+		options:   o,
+		now:       time.Now,
+	}
+
+	dial := lt.transport.DialContext
+	if dial == nil && lt.transport.Dial != nil {
+		legacyDial := lt.transport.Dial
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return legacyDial(network, addr)
+		}
+	}
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: o.KeepAlive}).DialContext
+	}
+	lt.transport.Dial = nil
+	lt.transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &limitedConn{Conn: conn, created: lt.now()}, nil
+	}
+
+	return lt
+}
+
+// Transport returns the http.Transport that sends requests, e.g. to close
+// its idle connections.
+func (t *ConnectionLimitsTransport) Transport() *http.Transport {
+	return t.transport
+}
+
+// RoundTrip sends the request with the transport, counting it against the
+// limits of the connection it is sent on.
+func (t *ConnectionLimitsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var conn *limitedConn
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			conn = asLimitedConn(info.Conn)
+			if conn != nil {
+				conn.acquire(t.options, t.now())
+			}
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := t.transport.RoundTrip(req)
+	if conn == nil {
+		return resp, err
+	}
+	if err != nil {
+		conn.release()
+		return resp, err
+	}
+
+	resp.Body = &limitedConnBody{ReadCloser: resp.Body, conn: conn}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the transport.
+func (t *ConnectionLimitsTransport) CloseIdleConnections() {
+	t.transport.CloseIdleConnections()
+}
+
+func asLimitedConn(c net.Conn) *limitedConn {
+	if tc, ok := c.(*tls.Conn); ok {
+		c = tc.NetConn()
+	}
+	lc, _ := c.(*limitedConn)
+	return lc
+}
+
+// limitedConn is a connection dialed by a ConnectionLimitsTransport.
+type limitedConn struct {
+	net.Conn
+	created time.Time
+
+	mu       sync.Mutex
+	requests int
+	active   int
+	retiring bool
+}
+
+// acquire counts a request sent on the connection, and marks the connection
+// to be closed if it reached a limit.
+func (c *limitedConn) acquire(o ConnectionLimitsOptions, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests++
+	c.active++
+	if o.MaxRequests > 0 && c.requests >= o.MaxRequests ||
+		o.MaxLifetime > 0 && now.Sub(c.created) >= o.MaxLifetime {
+		c.retiring = true
+	}
+}
+
+// release completes a request sent on the connection, closing the connection
+// if it reached a limit and has no other requests in flight.
+func (c *limitedConn) release() {
+	c.mu.Lock()
+	c.active--
+	closeConn := c.retiring && c.active == 0
+	c.mu.Unlock()
+
+	if closeConn {
+		c.Conn.Close()
+	}
+}
+
+// limitedConnBody is a response body that releases its request from the
+// connection it was sent on once closed or read to EOF.
+type limitedConnBody struct {
+	io.ReadCloser
+	conn *limitedConn
+	once sync.Once
+}
+
+func (b *limitedConnBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.once.Do(b.conn.release)
+	}
+	return n, err
+}
+
+func (b *limitedConnBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.conn.release)
+	return err
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newConnectionCountServer returns a test server that responds with the
+// number of distinct client connections it has seen.
+func newConnectionCountServer(t *testing.T) (*httptest.Server, func() int) {
+	var mu sync.Mutex
+	conns := map[string]struct{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conns[r.RemoteAddr] = struct{}{}
+		mu.Unlock()
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(conns)
+	}
+}
+
+func TestConnectionLimitsTransport(t *testing.T) {
+	cases := map[string]struct {
+		options     ConnectionLimitsOptions
+		advance     time.Duration
+		requests    int
+		expectConns int
+	}{
+		"no limits": {
+			requests:    5,
+			expectConns: 1,
+		},
+		"max requests": {
+			options:     ConnectionLimitsOptions{MaxRequests: 2},
+			requests:    5,
+			expectConns: 3,
+		},
+		"max lifetime": {
+			options:     ConnectionLimitsOptions{MaxLifetime: time.Minute},
+			advance:     30 * time.Second,
+			requests:    5,
+			expectConns: 2,
+		},
+		"lifetime not reached": {
+			options:     ConnectionLimitsOptions{MaxLifetime: time.Hour},
+			advance:     time.Second,
+			requests:    5,
+			expectConns: 1,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server, conns := newConnectionCountServer(t)
+
+			transport := NewConnectionLimitsTransport(&http.Transport{}, func(o *ConnectionLimitsOptions) {
+				*o = c.options
+			})
+			defer transport.CloseIdleConnections()
+
+			now := time.Now()
+			transport.now = func() time.Time { return now }
+			client := &http.Client{Transport: transport}
+
+			for i := 0; i < c.requests; i++ {
+				resp, err := client.Get(server.URL)
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				io.Copy(ioutil.Discard, resp.Body)
+				resp.Body.Close()
+				now = now.Add(c.advance)
+			}
+
+			if e, a := c.expectConns, conns(); e != a {
+				t.Errorf("expect %d connections, got %d", e, a)
+			}
+		})
+	}
+}
+
+func TestConnectionLimitsTransport_ClosedWithoutRead(t *testing.T) {
+	server, conns := newConnectionCountServer(t)
+
+	transport := NewConnectionLimitsTransport(&http.Transport{}, func(o *ConnectionLimitsOptions) {
+		o.MaxRequests = 1
+	})
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if e, a := 3, conns(); e != a {
+		t.Errorf("expect %d connections, got %d", e, a)
+	}
+}
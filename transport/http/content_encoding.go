@@ -0,0 +1,194 @@
+package http
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ContentEncoding is a content coding of HTTP message bodies, identified by
+// the name used in the Content-Encoding and Accept-Encoding headers, e.g.
+// "gzip".
+//
+// Only gzip is implemented by this module, see GzipContentEncoding. zstd is
+// implemented by the optional github.com/aws/smithy-go/transport/http/zstd
+// module, so that its dependencies are not required by every client.
+type ContentEncoding interface {
+	// The content coding name, e.g. "zstd".
+	Name() string
+
+	// NewReader returns a reader of the decoded content of r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+
+	// NewWriter returns a writer that encodes content to w. The encoding is
+	// complete once the writer is closed.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+// GzipContentEncoding is the gzip ContentEncoding.
+type GzipContentEncoding struct{}
+
+// Name returns "gzip".
+func (GzipContentEncoding) Name() string { return "gzip" }
+
+// NewReader returns a gzip reader of r.
+func (GzipContentEncoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// NewWriter returns a gzip writer to w.
+func (GzipContentEncoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+// AddResponseDecompressionMiddleware adds middleware to the stack that
+// negotiate and decode compressed responses with the content encodings.
+//
+// The Accept-Encoding header of the request is set to the names of the
+// encodings, in order of preference. A response with a Content-Encoding of
+// one or more of the encodings has its body decoded before it is
+// deserialized, and its Content-Encoding and Content-Length headers removed.
+// A response with any other Content-Encoding is passed through as is.
+//
+// Setting Accept-Encoding disables the transparent gzip decompression of
+// http.Transport, so GzipContentEncoding should be included to accept gzip.
+func AddResponseDecompressionMiddleware(stack *middleware.Stack, encodings ...ContentEncoding) error {
+	if len(encodings) == 0 {
+		return fmt.Errorf("at least one content encoding is required")
+	}
+
+	names := make([]string, len(encodings))
+	byName := make(map[string]ContentEncoding, len(encodings))
+	for i, e := range encodings {
+		names[i] = e.Name()
+		byName[strings.ToLower(e.Name())] = e
+	}
+
+	err := stack.Build.Add(&acceptEncodingMiddleware{value: strings.Join(names, ", ")}, middleware.After)
+	if err != nil {
+		return err
+	}
+	return stack.Deserialize.Insert(&decompressResponseMiddleware{encodings: byName},
+		"OperationDeserializer", middleware.After)
+}
+
+type acceptEncodingMiddleware struct {
+	value string
+}
+
+func (*acceptEncodingMiddleware) ID() string {
+	return "AcceptEncoding"
+}
+
+func (m *acceptEncodingMiddleware) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	req.Header.Set("Accept-Encoding", m.value)
+	return next.HandleBuild(ctx, in)
+}
+
+type decompressResponseMiddleware struct {
+	encodings map[string]ContentEncoding
+}
+
+func (*decompressResponseMiddleware) ID() string {
+	return "DecompressResponse"
+}
+
+func (m *decompressResponseMiddleware) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+	}
+
+	codings := m.responseCodings(resp)
+	if len(codings) == 0 {
+		return out, metadata, nil
+	}
+
+	// codings are listed in the order they were applied
+	body := resp.Body
+	closers := []io.Closer{resp.Body}
+	for i := len(codings) - 1; i >= 0; i-- {
+		r, err := codings[i].NewReader(body)
+		if err != nil {
+			resp.Body.Close()
+			return out, metadata, fmt.Errorf("failed to decode %s response body, %w", codings[i].Name(), err)
+		}
+		body = r
+		closers = append(closers, r)
+	}
+
+	resp.Body = &decodedBody{Reader: body, closers: closers}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	return out, metadata, nil
+}
+
+// responseCodings returns the encodings of the Content-Encoding of the
+// response, or nil if the response has no body, or any of its codings is not
+// one of the encodings.
+func (m *decompressResponseMiddleware) responseCodings(resp *Response) []ContentEncoding {
+	if resp.Body == nil || resp.Body == http.NoBody || resp.ContentLength == 0 ||
+		resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+
+	var codings []ContentEncoding
+	for _, v := range resp.Header.Values("Content-Encoding") {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if len(name) == 0 || name == "identity" {
+				continue
+			}
+
+			e, ok := m.encodings[name]
+			if !ok {
+				return nil
+			}
+			codings = append(codings, e)
+		}
+	}
+	return codings
+}
+
+// decodedBody is a response body read through its content decoders, which
+// closes the decoders and the underlying body.
+type decodedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *decodedBody) Close() error {
+	var err error
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		if cerr := b.closers[i].Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// testDeflateEncoding is a stand-in for a content encoding implemented
+// outside of this module.
+type testDeflateEncoding struct{}
+
+func (testDeflateEncoding) Name() string { return "deflate" }
+
+func (testDeflateEncoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+func (testDeflateEncoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zlib.NewWriter(w), nil
+}
+
+func encodeTestBody(t *testing.T, content string, encodings ...ContentEncoding) []byte {
+	t.Helper()
+
+	p := []byte(content)
+	for _, e := range encodings {
+		var b bytes.Buffer
+		w, err := e.NewWriter(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(p)
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		p = b.Bytes()
+	}
+	return p
+}
+
+func TestResponseDecompressionMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		contentEncoding string
+		body            []byte
+		expectBody      string
+		expectEncoding  string
+		err             string
+	}{
+		"gzip": {
+			contentEncoding: "gzip",
+			body:            encodeTestBody(t, "hello", GzipContentEncoding{}),
+			expectBody:      "hello",
+		},
+		"registered encoding": {
+			contentEncoding: "Deflate",
+			body:            encodeTestBody(t, "hello", testDeflateEncoding{}),
+			expectBody:      "hello",
+		},
+		"multiple encodings": {
+			contentEncoding: "deflate, identity, gzip",
+			body:            encodeTestBody(t, "hello", testDeflateEncoding{}, GzipContentEncoding{}),
+			expectBody:      "hello",
+		},
+		"no encoding": {
+			body:       []byte("hello"),
+			expectBody: "hello",
+		},
+		"unknown encoding": {
+			contentEncoding: "gzip, br",
+			body:            []byte("opaque"),
+			expectBody:      "opaque",
+			expectEncoding:  "gzip, br",
+		},
+		"invalid body": {
+			contentEncoding: "gzip",
+			body:            []byte("not gzip"),
+			err:             "failed to decode gzip response body",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("test", NewStackRequest)
+			stack.Deserialize.Add(&testOperationDeserializer{}, middleware.After)
+			if err := AddResponseDecompressionMiddleware(stack, testDeflateEncoding{}, GzipContentEncoding{}); err != nil {
+				t.Fatal(err)
+			}
+
+			var acceptEncoding string
+			var resp *http.Response
+			client := ClientDoFunc(func(r *http.Request) (*http.Response, error) {
+				acceptEncoding = r.Header.Get("Accept-Encoding")
+				resp = &http.Response{
+					StatusCode:    200,
+					Header:        http.Header{},
+					Body:          ioutil.NopCloser(bytes.NewReader(c.body)),
+					ContentLength: int64(len(c.body)),
+				}
+				if len(c.contentEncoding) != 0 {
+					resp.Header.Set("Content-Encoding", c.contentEncoding)
+				}
+				return resp, nil
+			})
+
+			result, _, err := middleware.DecorateHandler(NewClientHandler(client), stack).
+				Handle(context.Background(), struct{}{})
+
+			if e, a := "deflate, gzip", acceptEncoding; e != a {
+				t.Errorf("expect Accept-Encoding %q, got %q", e, a)
+			}
+			if len(c.err) != 0 {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expect error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.expectBody, result; e != a {
+				t.Errorf("expect body %v, got %v", e, a)
+			}
+			if e, a := c.expectEncoding, resp.Header.Get("Content-Encoding"); e != a {
+				t.Errorf("expect Content-Encoding %q, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestResponseDecompressionMiddleware_EmptyBody(t *testing.T) {
+	m := &decompressResponseMiddleware{
+		encodings: map[string]ContentEncoding{"gzip": GzipContentEncoding{}},
+	}
+
+	for _, status := range []int{200, 204, 304} {
+		_, _, err := m.HandleDeserialize(context.Background(), middleware.DeserializeInput{},
+			middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+				out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+			) {
+				out.RawResponse = &Response{Response: &http.Response{
+					StatusCode: status,
+					Header:     http.Header{"Content-Encoding": {"gzip"}},
+					Body:       http.NoBody,
+				}}
+				return out, metadata, nil
+			}),
+		)
+		if err != nil {
+			t.Errorf("%d: expect no error, got %v", status, err)
+		}
+	}
+}
+
+func TestGzipContentEncoding(t *testing.T) {
+	p := encodeTestBody(t, "hello", GzipContentEncoding{})
+
+	r, err := gzip.NewReader(bytes.NewReader(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := "hello", string(b); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DialerOptions is the set of options that can be configured for the dialer
+// of a transport, see NewDialerTransport.
+type DialerOptions struct {
+	// Dials the connections of the transport, e.g. through a sidecar proxy.
+	// Defaults to the dialer of the transport, or a net.Dialer with the
+	// timeouts of http.DefaultTransport if it has none.
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// The path of a unix domain socket that every connection of the
+	// transport is dialed to, regardless of the host of the request, e.g. a
+	// local test server. See ParseUnixEndpoint for the URL of requests sent
+	// over the socket.
+	UnixSocket string
+}
+
+// NewDialerTransport returns a clone of t that dials connections with the
+// options. t is not modified, and clones of the transport returned dial
+// connections the same way.
+//
+// Connections to https endpoints are dialed with the options, and upgraded to
+// TLS by the transport. A DialTLSContext, or DialTLS, of t is not used.
+func NewDialerTransport(t *http.Transport, optFns ...func(*DialerOptions)) *http.Transport {
+	var o DialerOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	t = t.Clone()
+
+	dial := o.DialContext
+	if dial == nil {
+		dial = t.DialContext
+	}
+	if dial == nil && t.Dial != nil {
+		legacyDial := t.Dial
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return legacyDial(network, addr)
+		}
+	}
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+
+	if socket := o.UnixSocket; len(socket) != 0 {
+		dialUnix := dial
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return dialUnix(ctx, "unix", socket)
+		}
+	}
+
+	t.Dial = nil
+	t.DialTLS = nil
+	t.DialTLSContext = nil
+	t.DialContext = dial
+
+	return t
+}
+
+// ParseUnixEndpoint parses a unix domain socket endpoint, such as
+// "unix:///var/run/service.sock", returning the path of the socket and the
+// URL of the endpoint to send requests over it to, "http://localhost".
+//
+// Set the socket with DialerOptions.UnixSocket, and the URL as the endpoint
+// of the client, e.g.
+//
+//	socket, endpoint, err := smithyhttp.ParseUnixEndpoint("unix:///var/run/service.sock")
+//	transport := smithyhttp.NewDialerTransport(http.DefaultTransport.(*http.Transport),
+//		func(o *smithyhttp.DialerOptions) {
+//			o.UnixSocket = socket
+//		})
+//	// send requests to endpoint with the transport
+func ParseUnixEndpoint(endpoint string) (socket string, u *url.URL, err error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse unix endpoint, %w", err)
+	}
+	if parsed.Scheme != "unix" {
+		return "", nil, fmt.Errorf("unix endpoint must have the unix scheme, got %q", parsed.Scheme)
+	}
+
+	socket = parsed.Path
+	if len(parsed.Host) != 0 {
+		// a relative socket path, e.g. "unix://service.sock"
+		socket = parsed.Host + parsed.Path
+	}
+	if len(socket) == 0 {
+		return "", nil, fmt.Errorf("unix endpoint must have a socket path, %q", endpoint)
+	}
+
+	return socket, &url.URL{Scheme: "http", Host: "localhost"}, nil
+}
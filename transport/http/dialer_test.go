@@ -0,0 +1,123 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewDialerTransport_UnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "service.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix domain sockets not supported, %v", err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello " + r.URL.Path))
+	})}
+	go server.Serve(l)
+	defer server.Close()
+
+	parsed, endpoint, err := ParseUnixEndpoint("unix://" + socket)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := socket, parsed; e != a {
+		t.Errorf("expect socket %v, got %v", e, a)
+	}
+
+	transport := NewDialerTransport(&http.Transport{}, func(o *DialerOptions) {
+		o.UnixSocket = parsed
+	})
+	// clones dial the socket as well
+	client := &http.Client{Transport: transport.Clone()}
+	defer transport.CloseIdleConnections()
+
+	resp, err := client.Get(endpoint.String() + "/foo")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if e, a := "hello /foo", string(body); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+}
+
+func TestNewDialerTransport_DialContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var dialed []string
+	original := &http.Transport{}
+	transport := NewDialerTransport(original, func(o *DialerOptions) {
+		o.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		}
+	})
+	defer transport.CloseIdleConnections()
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	resp.Body.Close()
+
+	if e, a := 1, len(dialed); e != a {
+		t.Errorf("expect %v dials, got %v", e, a)
+	}
+	if original.DialContext != nil {
+		t.Errorf("expect original transport not modified")
+	}
+}
+
+func TestParseUnixEndpoint(t *testing.T) {
+	cases := map[string]struct {
+		endpoint     string
+		expectSocket string
+		expectErr    bool
+	}{
+		"absolute": {
+			endpoint:     "unix:///var/run/service.sock",
+			expectSocket: "/var/run/service.sock",
+		},
+		"relative": {
+			endpoint:     "unix://service.sock",
+			expectSocket: "service.sock",
+		},
+		"not unix": {
+			endpoint:  "http://localhost",
+			expectErr: true,
+		},
+		"no socket": {
+			endpoint:  "unix://",
+			expectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			socket, u, err := ParseUnixEndpoint(c.endpoint)
+			if c.expectErr {
+				if err == nil {
+					t.Fatalf("expect error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.expectSocket, socket; e != a {
+				t.Errorf("expect socket %v, got %v", e, a)
+			}
+			if e, a := "http://localhost", u.String(); e != a {
+				t.Errorf("expect URL %v, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package http
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ChecksumAlgorithm is an algorithm of the checksums of HTTP message
+// payloads.
+type ChecksumAlgorithm string
+
+// Checksum algorithms of HTTP message payloads.
+const (
+	ChecksumCRC32     ChecksumAlgorithm = "CRC32"
+	ChecksumCRC32C    ChecksumAlgorithm = "CRC32C"
+	ChecksumSHA1      ChecksumAlgorithm = "SHA1"
+	ChecksumSHA256    ChecksumAlgorithm = "SHA256"
+	ChecksumCRC64NVME ChecksumAlgorithm = "CRC64NVME"
+)
+
+// DefaultChecksumHeaderPrefix is the default prefix of the names of checksum
+// headers and trailers, to which the lower case algorithm is appended, e.g.
+// "X-Amz-Checksum-crc32".
+const DefaultChecksumHeaderPrefix = "X-Amz-Checksum-"
+
+// defaultResponseChecksumAlgorithms are the algorithms of response checksums
+// validated by default, in order of preference.
+var defaultResponseChecksumAlgorithms = []ChecksumAlgorithm{
+	ChecksumCRC64NVME, ChecksumCRC32C, ChecksumCRC32, ChecksumSHA1, ChecksumSHA256,
+}
+
+var (
+	crc32cTable    = crc32.MakeTable(crc32.Castagnoli)
+	crc64NVMETable = crc64.MakeTable(0x9a6c9329ac4bc9b5)
+)
+
+// NewHash returns a new hash of the algorithm, or an error if the algorithm
+// is not supported.
+func (a ChecksumAlgorithm) NewHash() (hash.Hash, error) {
+	switch a {
+	case ChecksumCRC32:
+		return crc32.NewIEEE(), nil
+	case ChecksumCRC32C:
+		return crc32.New(crc32cTable), nil
+	case ChecksumSHA1:
+		return sha1.New(), nil
+	case ChecksumSHA256:
+		return sha256.New(), nil
+	case ChecksumCRC64NVME:
+		return crc64.New(crc64NVMETable), nil
+	default:
+		return nil, fmt.Errorf("unsupported checksum algorithm, %q", string(a))
+	}
+}
+
+func checksumHeader(prefix string, a ChecksumAlgorithm) string {
+	return http.CanonicalHeaderKey(prefix + strings.ToLower(string(a)))
+}
+
+func encodeChecksum(h hash.Hash) string {
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// RequestChecksumOptions is the set of options that can be configured for
+// the request checksum middleware.
+type RequestChecksumOptions struct {
+	// The algorithm of the checksum.
+	Algorithm ChecksumAlgorithm
+
+	// Send the checksum in a trailer of the request, computed as the payload
+	// is sent, rather than in a header. By default the checksum is sent in a
+	// trailer only if the payload is not seekable, or its length is unknown.
+	Trailer bool
+
+	// The prefix of the name of the checksum header or trailer. Defaults to
+	// DefaultChecksumHeaderPrefix.
+	HeaderPrefix string
+}
+
+// AddRequestChecksumMiddleware adds middleware to the end of the Build step
+// that sends a checksum of the request payload with the algorithm.
+//
+// The checksum of a seekable payload of known length is computed before the
+// request is sent, and set in a header. The checksum of any other payload is
+// computed as it is sent, and set in a trailer of the request, which is sent
+// with chunked transfer encoding. A request that already has the checksum
+// header is not modified.
+func AddRequestChecksumMiddleware(stack *middleware.Stack, optFns ...func(*RequestChecksumOptions)) error {
+	var o RequestChecksumOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	if len(o.HeaderPrefix) == 0 {
+		o.HeaderPrefix = DefaultChecksumHeaderPrefix
+	}
+	if _, err := o.Algorithm.NewHash(); err != nil {
+		return err
+	}
+
+	return stack.Build.Add(&requestChecksumMiddleware{options: o}, middleware.After)
+}
+
+type requestChecksumMiddleware struct {
+	options RequestChecksumOptions
+}
+
+func (*requestChecksumMiddleware) ID() string {
+	return "RequestChecksum"
+}
+
+func (m *requestChecksumMiddleware) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	name := checksumHeader(m.options.HeaderPrefix, m.options.Algorithm)
+	if len(req.Header.Get(name)) != 0 {
+		return next.HandleBuild(ctx, in)
+	}
+
+	h, err := m.options.Algorithm.NewHash()
+	if err != nil {
+		return out, metadata, err
+	}
+
+	stream := req.GetStream()
+	if stream == nil {
+		req.Header.Set(name, encodeChecksum(h))
+		return next.HandleBuild(ctx, in)
+	}
+
+	_, known, err := req.StreamLength()
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to get length of request stream, %w", err)
+	}
+
+	if !m.options.Trailer && known && req.IsStreamSeekable() {
+		if _, err := io.Copy(h, stream); err != nil {
+			return out, metadata, fmt.Errorf("failed to compute %s checksum of request payload, %w",
+				m.options.Algorithm, err)
+		}
+		if err := req.RewindStream(); err != nil {
+			return out, metadata, fmt.Errorf("failed to rewind request stream after computing checksum, %w", err)
+		}
+		req.Header.Set(name, encodeChecksum(h))
+		return next.HandleBuild(ctx, in)
+	}
+
+	r := &checksumTrailerReader{Reader: stream, hash: h, name: name}
+	var body io.Reader = r
+	if req.IsStreamSeekable() {
+		body = seekableChecksumTrailerReader{r}
+	}
+	trailing, err := req.SetStream(body)
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to set request stream, %w", err)
+	}
+	*req = *trailing
+	req.ContentLength = -1
+	req.Header.Del("Content-Length")
+
+	return next.HandleBuild(ctx, in)
+}
+
+// trailerStream is a request stream that sets trailers of the request it is
+// sent with once read, see Request.Build.
+type trailerStream interface {
+	bindTrailer(http.Header)
+}
+
+// checksumTrailerReader is a request stream that sets the checksum of its
+// content in a trailer once read to EOF.
+type checksumTrailerReader struct {
+	io.Reader
+	hash hash.Hash
+	name string
+
+	mu      sync.Mutex
+	trailer http.Header
+}
+
+func (r *checksumTrailerReader) bindTrailer(trailer http.Header) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the trailer must be declared before the request is sent
+	trailer[r.name] = nil
+	r.trailer = trailer
+}
+
+func (r *checksumTrailerReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		r.mu.Lock()
+		if r.trailer != nil {
+			r.trailer.Set(r.name, encodeChecksum(r.hash))
+		}
+		r.mu.Unlock()
+	}
+	return n, err
+}
+
+// seekableChecksumTrailerReader is a checksumTrailerReader of a seekable
+// stream, such that the request can be retried. The checksum is reset when
+// the stream is seeked, e.g. rewound to its start.
+type seekableChecksumTrailerReader struct {
+	*checksumTrailerReader
+}
+
+func (r seekableChecksumTrailerReader) Seek(offset int64, whence int) (int64, error) {
+	n, err := r.Reader.(io.Seeker).Seek(offset, whence)
+	r.hash.Reset()
+	return n, err
+}
+
+// ResponseChecksumOptions is the set of options that can be configured for
+// the response checksum validation middleware.
+type ResponseChecksumOptions struct {
+	// The algorithms of the checksums validated, in order of preference.
+	// Only the checksum of the first algorithm the response has a checksum
+	// header of is validated. Defaults to CRC64NVME, CRC32C, CRC32, SHA1,
+	// and SHA256.
+	Algorithms []ChecksumAlgorithm
+
+	// The prefix of the names of checksum headers. Defaults to
+	// DefaultChecksumHeaderPrefix.
+	HeaderPrefix string
+}
+
+// ChecksumValidationError is returned when the response payload is read to
+// EOF if its checksum does not match the checksum header of the response.
+type ChecksumValidationError struct {
+	Algorithm ChecksumAlgorithm
+	Expect    string
+	Actual    string
+}
+
+func (e *ChecksumValidationError) Error() string {
+	return fmt.Sprintf("response %s checksum mismatch, expect %s, got %s", e.Algorithm, e.Expect, e.Actual)
+}
+
+// AddResponseChecksumValidationMiddleware adds middleware to the end of the
+// Deserialize step that validates the checksum of response payloads, as they
+// are read, against the checksum header of the response. Reading the payload
+// to EOF returns a *ChecksumValidationError if the checksums do not match.
+//
+// Responses without a checksum header of the algorithms, and checksums of
+// multipart payloads, with a "-N" part count suffix, are not validated.
+func AddResponseChecksumValidationMiddleware(stack *middleware.Stack, optFns ...func(*ResponseChecksumOptions)) error {
+	var o ResponseChecksumOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	if len(o.Algorithms) == 0 {
+		o.Algorithms = defaultResponseChecksumAlgorithms
+	}
+	if len(o.HeaderPrefix) == 0 {
+		o.HeaderPrefix = DefaultChecksumHeaderPrefix
+	}
+	for _, a := range o.Algorithms {
+		if _, err := a.NewHash(); err != nil {
+			return err
+		}
+	}
+
+	return stack.Deserialize.Add(&responseChecksumMiddleware{options: o}, middleware.After)
+}
+
+type responseChecksumMiddleware struct {
+	options ResponseChecksumOptions
+}
+
+func (*responseChecksumMiddleware) ID() string {
+	return "ResponseChecksumValidation"
+}
+
+func (m *responseChecksumMiddleware) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+	}
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return out, metadata, nil
+	}
+
+	for _, a := range m.options.Algorithms {
+		expect := resp.Header.Get(checksumHeader(m.options.HeaderPrefix, a))
+		if len(expect) == 0 {
+			continue
+		}
+		if strings.Contains(expect, "-") {
+			break
+		}
+
+		h, err := a.NewHash()
+		if err != nil {
+			return out, metadata, err
+		}
+		resp.Body = &checksumValidatingBody{ReadCloser: resp.Body, algorithm: a, hash: h, expect: expect}
+		break
+	}
+
+	return out, metadata, nil
+}
+
+// checksumValidatingBody is a response body that validates the checksum of
+// its content once read to EOF.
+type checksumValidatingBody struct {
+	io.ReadCloser
+	algorithm ChecksumAlgorithm
+	hash      hash.Hash
+	expect    string
+}
+
+func (b *checksumValidatingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.hash.Write(p[:n])
+	if err == io.EOF {
+		if actual := encodeChecksum(b.hash); actual != b.expect {
+			return n, &ChecksumValidationError{Algorithm: b.algorithm, Expect: b.expect, Actual: actual}
+		}
+	}
+	return n, err
+}
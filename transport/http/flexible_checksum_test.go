@@ -0,0 +1,248 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestChecksumAlgorithm(t *testing.T) {
+	cases := map[ChecksumAlgorithm]uint64{
+		ChecksumCRC32:     0xcbf43926,
+		ChecksumCRC32C:    0xe3069283,
+		ChecksumCRC64NVME: 0xae8b14860a799888,
+	}
+
+	for a, expect := range cases {
+		t.Run(string(a), func(t *testing.T) {
+			h, err := a.NewHash()
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			h.Write([]byte("123456789"))
+
+			var actual uint64
+			switch h := h.(type) {
+			case hash.Hash32:
+				actual = uint64(h.Sum32())
+			case hash.Hash64:
+				actual = h.Sum64()
+			}
+			if expect != actual {
+				t.Errorf("expect %x, got %x", expect, actual)
+			}
+		})
+	}
+
+	if _, err := ChecksumAlgorithm("MD5").NewHash(); err == nil {
+		t.Errorf("expect error for unsupported algorithm")
+	}
+}
+
+func testChecksum(t *testing.T, a ChecksumAlgorithm, content string) string {
+	t.Helper()
+
+	h, err := a.NewHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Write([]byte(content))
+	return encodeChecksum(h)
+}
+
+func TestRequestChecksumMiddleware(t *testing.T) {
+	const payload = "hello world"
+
+	cases := map[string]struct {
+		body          func() io.Reader
+		trailer       bool
+		expectHeader  bool
+		expectTrailer bool
+	}{
+		"seekable": {
+			body:         func() io.Reader { return strings.NewReader(payload) },
+			expectHeader: true,
+		},
+		"seekable trailer": {
+			body:          func() io.Reader { return strings.NewReader(payload) },
+			trailer:       true,
+			expectTrailer: true,
+		},
+		"unknown length": {
+			body:          func() io.Reader { return unknownLengthReader{strings.NewReader(payload)} },
+			expectTrailer: true,
+		},
+		"no body": {
+			expectHeader: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var body, header, trailer string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				body = string(b)
+				header = r.Header.Get("X-Amz-Checksum-Crc32c")
+				trailer = r.Trailer.Get("X-Amz-Checksum-Crc32c")
+			}))
+			defer server.Close()
+			endpoint, _ := url.Parse(server.URL)
+
+			stack := middleware.NewStack("test", NewStackRequest)
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize", func(
+				ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+			) (middleware.SerializeOutput, middleware.Metadata, error) {
+				req := in.Request.(*Request)
+				req.URL = endpoint
+				req.Method = http.MethodPut
+				if c.body != nil {
+					r, err := req.SetStream(c.body())
+					if err != nil {
+						return middleware.SerializeOutput{}, middleware.Metadata{}, err
+					}
+					in.Request = r
+				}
+				return next.HandleSerialize(ctx, in)
+			}), middleware.After)
+			if err := AddComputeContentLengthMiddleware(stack); err != nil {
+				t.Fatal(err)
+			}
+			if err := AddRequestChecksumMiddleware(stack, func(o *RequestChecksumOptions) {
+				o.Algorithm = ChecksumCRC32C
+				o.Trailer = c.trailer
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, err := middleware.DecorateHandler(NewClientHandler(server.Client()), stack).
+				Handle(context.Background(), struct{}{})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			expectBody := payload
+			if c.body == nil {
+				expectBody = ""
+			}
+			if e, a := expectBody, body; e != a {
+				t.Errorf("expect body %q, got %q", e, a)
+			}
+
+			expect := testChecksum(t, ChecksumCRC32C, expectBody)
+			var expectHeader, expectTrailer string
+			if c.expectHeader {
+				expectHeader = expect
+			}
+			if c.expectTrailer {
+				expectTrailer = expect
+			}
+			if e, a := expectHeader, header; e != a {
+				t.Errorf("expect checksum header %q, got %q", e, a)
+			}
+			if e, a := expectTrailer, trailer; e != a {
+				t.Errorf("expect checksum trailer %q, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestAddRequestChecksumMiddleware_Unsupported(t *testing.T) {
+	stack := middleware.NewStack("test", NewStackRequest)
+	err := AddRequestChecksumMiddleware(stack, func(o *RequestChecksumOptions) {
+		o.Algorithm = "MD5"
+	})
+	if err == nil {
+		t.Errorf("expect error")
+	}
+}
+
+func TestResponseChecksumValidationMiddleware(t *testing.T) {
+	const payload = "hello world"
+
+	cases := map[string]struct {
+		header    map[string]string
+		expectErr bool
+	}{
+		"match": {
+			header: map[string]string{
+				"X-Amz-Checksum-Sha256": testChecksum(t, ChecksumSHA256, payload),
+			},
+		},
+		"mismatch": {
+			header: map[string]string{
+				"X-Amz-Checksum-Crc32": testChecksum(t, ChecksumCRC32, "goodbye"),
+			},
+			expectErr: true,
+		},
+		"preferred algorithm": {
+			header: map[string]string{
+				"X-Amz-Checksum-Crc32c": testChecksum(t, ChecksumCRC32C, payload),
+				"X-Amz-Checksum-Sha1":   testChecksum(t, ChecksumSHA1, "goodbye"),
+			},
+		},
+		"multipart": {
+			header: map[string]string{
+				"X-Amz-Checksum-Crc32": testChecksum(t, ChecksumCRC32, "goodbye") + "-3",
+			},
+		},
+		"no checksum": {},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("test", NewStackRequest)
+			var body []byte
+			var readErr error
+			stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("OperationDeserializer", func(
+				ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+			) (middleware.DeserializeOutput, middleware.Metadata, error) {
+				out, metadata, err := next.HandleDeserialize(ctx, in)
+				if err != nil {
+					return out, metadata, err
+				}
+				body, readErr = ioutil.ReadAll(out.RawResponse.(*Response).Body)
+				return out, metadata, err
+			}), middleware.After)
+			if err := AddResponseChecksumValidationMiddleware(stack); err != nil {
+				t.Fatal(err)
+			}
+
+			client := ClientDoFunc(func(r *http.Request) (*http.Response, error) {
+				resp := &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(bytes.NewReader([]byte(payload))),
+				}
+				for k, v := range c.header {
+					resp.Header.Set(k, v)
+				}
+				return resp, nil
+			})
+
+			_, _, err := middleware.DecorateHandler(NewClientHandler(client), stack).
+				Handle(context.Background(), struct{}{})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var verr *ChecksumValidationError
+			if e, a := c.expectErr, errors.As(readErr, &verr); e != a {
+				t.Fatalf("expect checksum validation error %v, got %v", e, readErr)
+			}
+			if e, a := payload, string(body); e != a {
+				t.Errorf("expect body %q, got %q", e, a)
+			}
+		})
+	}
+}
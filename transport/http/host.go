@@ -15,7 +15,10 @@ func ValidateEndpointHost(host string) error {
 	var port string
 	var err error
 
-	if strings.Contains(host, ":") {
+	if isBracketedIPv6(host) {
+		// IPv6 literal without a port, e.g. "[::1]", SplitHostPort requires one
+		hostname = host[1 : len(host)-1]
+	} else if strings.Contains(host, ":") {
 		hostname, port, err = net.SplitHostPort(host)
 		if err != nil {
 			errors.WriteString(fmt.Sprintf("\n endpoint %v, failed to parse, got ", host))
@@ -29,16 +32,24 @@ func ValidateEndpointHost(host string) error {
 		hostname = host
 	}
 
-	labels := strings.Split(hostname, ".")
-	for i, label := range labels {
-		if i == len(labels)-1 && len(label) == 0 {
-			// Allow trailing dot for FQDN hosts.
-			continue
+	if strings.Contains(hostname, ":") {
+		// only IPv6 literals may contain colons, and must be bracketed in
+		// the host
+		if !strings.HasPrefix(host, "[") || net.ParseIP(stripZone(hostname)) == nil {
+			errors.WriteString(fmt.Sprintf("\nendpoint host is not a valid IPv6 address, got %v", hostname))
 		}
+	} else {
+		labels := strings.Split(hostname, ".")
+		for i, label := range labels {
+			if i == len(labels)-1 && len(label) == 0 {
+				// Allow trailing dot for FQDN hosts.
+				continue
+			}
 
-		if !ValidHostLabel(label) {
-			errors.WriteString("\nendpoint host domain labels must match \"[a-zA-Z0-9-]{1,63}\", but found: ")
-			errors.WriteString(label)
+			if !ValidHostLabel(label) {
+				errors.WriteString("\nendpoint host domain labels must match \"[a-zA-Z0-9-]{1,63}\", but found: ")
+				errors.WriteString(label)
+			}
 		}
 	}
 
@@ -56,6 +67,61 @@ func ValidateEndpointHost(host string) error {
 	return nil
 }
 
+// HostForHeader returns the value of the Host header that will be sent for a
+// request to the given URL host (host or host:port, as in url.URL.Host). An
+// IPv6 zone identifier is removed, since it is only meaningful to the sender
+// and is not permitted in the header (RFC 6874 section 4), and the port is
+// removed if it is the default for the scheme.
+//
+// Request.Build sets the Host of the request it builds to this value, unless
+// the Host is already set. Signers that include the Host header in a
+// canonical request must use this value rather than url.URL.Host for their
+// signature to match what the service receives.
+func HostForHeader(scheme, host string) string {
+	hostname, port := host, ""
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		hostname, port = h, p
+	} else if isBracketedIPv6(host) {
+		hostname = host[1 : len(host)-1]
+	}
+
+	hostname = stripZone(hostname)
+	if isDefaultPort(scheme, port) {
+		port = ""
+	}
+
+	if strings.Contains(hostname, ":") {
+		hostname = "[" + hostname + "]"
+	}
+	if len(port) != 0 {
+		return hostname + ":" + port
+	}
+	return hostname
+}
+
+func isBracketedIPv6(host string) bool {
+	return strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]")
+}
+
+// stripZone removes the zone identifier from an IPv6 address, if present.
+// The separator may be percent-encoded ("%25") as it is in a URI.
+func stripZone(hostname string) string {
+	if i := strings.Index(hostname, "%"); i > -1 {
+		return hostname[:i]
+	}
+	return hostname
+}
+
+func isDefaultPort(scheme, port string) bool {
+	switch strings.ToLower(scheme) {
+	case "http":
+		return port == "80"
+	case "https":
+		return port == "443"
+	}
+	return false
+}
+
 // ValidPortNumber returns whether the port is valid RFC 3986 port.
 func ValidPortNumber(port string) bool {
 	i, err := strconv.Atoi(port)
@@ -74,6 +74,13 @@ func TestValidateEndpointHostHandler(t *testing.T) {
 		"valid host with invalid port number": {Input: "abc.123:99999", Valid: false},
 		"empty host with port number":         {Input: ":1234", Valid: false},
 		"valid host with empty port number":   {Input: "abc.123:", Valid: false},
+		"ipv6":                                {Input: "[::1]", Valid: true},
+		"ipv6 with port":                      {Input: "[::1]:8443", Valid: true},
+		"ipv6 with zone":                      {Input: "[fe80::1%eth0]", Valid: true},
+		"ipv6 with escaped zone and port":     {Input: "[fe80::1%25eth0]:8443", Valid: true},
+		"ipv6 unbracketed":                    {Input: "fe80::1", Valid: false},
+		"ipv6 invalid":                        {Input: "[fe80::zz]", Valid: false},
+		"ipv6 invalid port":                   {Input: "[::1]:99999", Valid: false},
 	}
 
 	for name, c := range cases {
@@ -85,3 +92,30 @@ func TestValidateEndpointHostHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestHostForHeader(t *testing.T) {
+	cases := map[string]struct {
+		Scheme, Host string
+		Expect       string
+	}{
+		"hostname":              {"https", "example.com", "example.com"},
+		"default port":          {"https", "example.com:443", "example.com"},
+		"default http port":     {"http", "example.com:80", "example.com"},
+		"non-default port":      {"https", "example.com:8443", "example.com:8443"},
+		"mismatched default":    {"http", "example.com:443", "example.com:443"},
+		"ipv6":                  {"https", "[::1]", "[::1]"},
+		"ipv6 port":             {"https", "[::1]:8443", "[::1]:8443"},
+		"ipv6 default port":     {"https", "[::1]:443", "[::1]"},
+		"ipv6 zone":             {"https", "[fe80::1%eth0]", "[fe80::1]"},
+		"ipv6 escaped zone":     {"https", "[fe80::1%25eth0]:8443", "[fe80::1]:8443"},
+		"ipv4 non-default port": {"http", "127.0.0.1:8080", "127.0.0.1:8080"},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if e, a := c.Expect, HostForHeader(c.Scheme, c.Host); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
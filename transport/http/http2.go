@@ -0,0 +1,55 @@
+package http
+
+import (
+	"net/http"
+	"time"
+)
+
+// HTTP2Options is the set of options that can be configured for the HTTP/2
+// support of a transport, see NewHTTP2Transport.
+type HTTP2Options struct {
+	// Only send requests to https endpoints with HTTP/2, rather than falling
+	// back to HTTP/1.1 with servers that do not negotiate HTTP/2. Requests
+	// to http endpoints are sent with HTTP/1.1, unless Cleartext is set.
+	ForceHTTP2 bool
+
+	// Send requests with unencrypted HTTP/2 with prior knowledge (h2c) to
+	// http endpoints, e.g. for internal services, rather than HTTP/1.1.
+	// Implies ForceHTTP2.
+	Cleartext bool
+
+	// The timeout after which a health check ping is sent on a connection
+	// that has not received a frame. A value of 0 (the default) does not
+	// send health checks.
+	SendPingTimeout time.Duration
+
+	// The timeout after which a connection is closed if a health check ping
+	// is not responded to. Defaults to 15 seconds if zero.
+	PingTimeout time.Duration
+
+	// Wait for a stream of a connection when the server's limit of
+	// concurrent streams is reached by requests in flight, rather than
+	// opening another connection.
+	StrictMaxConcurrentStreams bool
+}
+
+// NewHTTP2Transport returns a clone of t with HTTP/2 configured by the
+// options, such that HTTP/2 is attempted even if t has a custom dialer or
+// TLS config. t is not modified.
+//
+// Returns an error if the options are not supported by the Go version the
+// module is built with. Each option other than the default of attempting
+// HTTP/2 over TLS requires go1.24 or later.
+func NewHTTP2Transport(t *http.Transport, optFns ...func(*HTTP2Options)) (*http.Transport, error) {
+	var o HTTP2Options
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	t = t.Clone()
+	t.ForceAttemptHTTP2 = true
+	if err := configureHTTP2(t, o); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
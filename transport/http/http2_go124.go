@@ -0,0 +1,30 @@
+//go:build go1.24
+
+package http
+
+import "net/http"
+
+func configureHTTP2(t *http.Transport, o HTTP2Options) error {
+	var protocols http.Protocols
+	protocols.SetHTTP1(!o.ForceHTTP2 && !o.Cleartext)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(o.Cleartext)
+	t.Protocols = &protocols
+
+	var config http.HTTP2Config
+	if t.HTTP2 != nil {
+		config = *t.HTTP2
+	}
+	if o.SendPingTimeout != 0 {
+		config.SendPingTimeout = o.SendPingTimeout
+	}
+	if o.PingTimeout != 0 {
+		config.PingTimeout = o.PingTimeout
+	}
+	if o.StrictMaxConcurrentStreams {
+		config.StrictMaxConcurrentRequests = true
+	}
+	t.HTTP2 = &config
+
+	return nil
+}
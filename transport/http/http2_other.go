@@ -0,0 +1,15 @@
+//go:build !go1.24
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func configureHTTP2(t *http.Transport, o HTTP2Options) error {
+	if o != (HTTP2Options{}) {
+		return fmt.Errorf("HTTP/2 options other than the default require go1.24 or later")
+	}
+	return nil
+}
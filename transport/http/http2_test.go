@@ -0,0 +1,107 @@
+//go:build go1.24
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewHTTP2Transport(t *testing.T) {
+	cases := map[string]struct {
+		options     HTTP2Options
+		tls         bool
+		expectProto int
+	}{
+		"default": {
+			expectProto: 1,
+		},
+		"default tls": {
+			tls:         true,
+			expectProto: 1,
+		},
+		"force tls": {
+			options: HTTP2Options{ForceHTTP2: true},
+			tls:     true,
+		},
+		"cleartext": {
+			options:     HTTP2Options{Cleartext: true},
+			expectProto: 2,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("ok"))
+			}))
+			server.Config.Protocols = &http.Protocols{}
+			server.Config.Protocols.SetHTTP1(true)
+			server.Config.Protocols.SetUnencryptedHTTP2(true)
+			base := &http.Transport{}
+			if c.tls {
+				// the server only negotiates HTTP/1.1
+				server.StartTLS()
+				base = server.Client().Transport.(*http.Transport)
+			} else {
+				server.Start()
+			}
+			defer server.Close()
+
+			transport, err := NewHTTP2Transport(base, func(o *HTTP2Options) {
+				*o = c.options
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			defer transport.CloseIdleConnections()
+
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if c.expectProto == 0 {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatalf("expect error, server does not support HTTP/2")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			resp.Body.Close()
+			if e, a := c.expectProto, resp.ProtoMajor; e != a {
+				t.Errorf("expect HTTP/%v, got HTTP/%v", e, a)
+			}
+		})
+	}
+}
+
+func TestNewHTTP2Transport_Config(t *testing.T) {
+	original := &http.Transport{HTTP2: &http.HTTP2Config{MaxReadFrameSize: 1 << 20}}
+	transport, err := NewHTTP2Transport(original, func(o *HTTP2Options) {
+		o.SendPingTimeout = 10 * time.Second
+		o.PingTimeout = 5 * time.Second
+		o.StrictMaxConcurrentStreams = true
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	expect := http.HTTP2Config{
+		MaxReadFrameSize:            1 << 20,
+		SendPingTimeout:             10 * time.Second,
+		PingTimeout:                 5 * time.Second,
+		StrictMaxConcurrentRequests: true,
+	}
+	if e, a := expect, *transport.HTTP2; !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %+v config, got %+v", e, a)
+	}
+	if !transport.ForceAttemptHTTP2 {
+		t.Errorf("expect HTTP/2 attempted")
+	}
+	if original.ForceAttemptHTTP2 || original.Protocols != nil || original.HTTP2.PingTimeout != 0 {
+		t.Errorf("expect original transport not modified")
+	}
+}
@@ -8,14 +8,60 @@ import (
 	"io/ioutil"
 )
 
+// CloseResponseBodyOptions is the set of options that can be configured for
+// the middleware that close response bodies.
+type CloseResponseBodyOptions struct {
+	// The maximum number of bytes of the unread remainder of a response body
+	// that are read before it is closed, such that its connection can be
+	// reused. The connection of a body with more unread bytes is closed
+	// rather than reading the body in full, see DrainResponseBody. A value of
+	// 0 (the default) reads the remainder of the body in full.
+	DrainLimit int64
+}
+
+// DrainResponseBody reads at most limit bytes of the remainder of the body,
+// and closes it. A body with no more than limit bytes remaining is read to
+// EOF, such that its connection is reused. The connection of any other body
+// is closed once the body is closed, which is cheaper than reading a large
+// body in full. Returns whether the body was read to EOF, and the error
+// reading the body, if any.
+//
+// A limit of 0 or less reads the remainder of the body in full.
+func DrainResponseBody(body io.ReadCloser, limit int64) (drained bool, err error) {
+	defer body.Close()
+	return drainBody(body, limit)
+}
+
+func drainBody(body io.Reader, limit int64) (drained bool, err error) {
+	if limit <= 0 {
+		_, err = io.Copy(ioutil.Discard, body)
+		return err == nil, err
+	}
+
+	n, err := io.CopyN(ioutil.Discard, body, limit+1)
+	if err == io.EOF {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return n <= limit, nil
+}
+
 // AddErrorCloseResponseBodyMiddleware adds the middleware to automatically
 // close the response body of an operation request if the request response
 // failed.
-func AddErrorCloseResponseBodyMiddleware(stack *middleware.Stack) error {
-	return stack.Deserialize.Insert(&errorCloseResponseBodyMiddleware{}, "OperationDeserializer", middleware.Before)
+func AddErrorCloseResponseBodyMiddleware(stack *middleware.Stack, optFns ...func(*CloseResponseBodyOptions)) error {
+	var o CloseResponseBodyOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	return stack.Deserialize.Insert(&errorCloseResponseBodyMiddleware{options: o}, "OperationDeserializer", middleware.Before)
 }
 
-type errorCloseResponseBodyMiddleware struct{}
+type errorCloseResponseBodyMiddleware struct {
+	options CloseResponseBodyOptions
+}
 
 func (*errorCloseResponseBodyMiddleware) ID() string {
 	return "ErrorCloseResponseBody"
@@ -29,10 +75,10 @@ func (m *errorCloseResponseBodyMiddleware) HandleDeserialize(
 	out, metadata, err := next.HandleDeserialize(ctx, input)
 	if err != nil {
 		if resp, ok := out.RawResponse.(*Response); ok && resp != nil && resp.Body != nil {
-			// Consume the full body to prevent TCP connection resets on some platforms
-			_, _ = io.Copy(ioutil.Discard, resp.Body)
-			// Do not validate that the response closes successfully.
-			resp.Body.Close()
+			// Consume the body to prevent TCP connection resets on some
+			// platforms. Do not validate that the response closes
+			// successfully.
+			_, _ = DrainResponseBody(resp.Body, m.options.DrainLimit)
 		}
 	}
 
@@ -42,11 +88,17 @@ func (m *errorCloseResponseBodyMiddleware) HandleDeserialize(
 // AddCloseResponseBodyMiddleware adds the middleware to automatically close
 // the response body of an operation request, after the response had been
 // deserialized.
-func AddCloseResponseBodyMiddleware(stack *middleware.Stack) error {
-	return stack.Deserialize.Insert(&closeResponseBody{}, "OperationDeserializer", middleware.Before)
+func AddCloseResponseBodyMiddleware(stack *middleware.Stack, optFns ...func(*CloseResponseBodyOptions)) error {
+	var o CloseResponseBodyOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	return stack.Deserialize.Insert(&closeResponseBody{options: o}, "OperationDeserializer", middleware.Before)
 }
 
-type closeResponseBody struct{}
+type closeResponseBody struct {
+	options CloseResponseBodyOptions
+}
 
 func (*closeResponseBody) ID() string {
 	return "CloseResponseBody"
@@ -63,8 +115,8 @@ func (m *closeResponseBody) HandleDeserialize(
 	}
 
 	if resp, ok := out.RawResponse.(*Response); ok {
-		// Consume the full body to prevent TCP connection resets on some platforms
-		_, copyErr := io.Copy(ioutil.Discard, resp.Body)
+		// Consume the body to prevent TCP connection resets on some platforms
+		_, copyErr := drainBody(resp.Body, m.options.DrainLimit)
 		if copyErr != nil {
 			middleware.GetLogger(ctx).Logf(logging.Warn, "failed to discard remaining HTTP response body, this may affect connection reuse")
 		}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestDrainResponseBody(t *testing.T) {
+	cases := map[string]struct {
+		bodySize      int
+		limit         int64
+		expectDrained bool
+	}{
+		"within limit": {
+			bodySize:      100,
+			limit:         100,
+			expectDrained: true,
+		},
+		"exceeds limit": {
+			bodySize: 1 << 20,
+			limit:    100,
+		},
+		"no limit": {
+			bodySize:      1 << 20,
+			expectDrained: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(strings.Repeat("a", c.bodySize)))
+			}))
+			defer server.Close()
+			client := server.Client()
+
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			drained, err := DrainResponseBody(resp.Body, c.limit)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.expectDrained, drained; e != a {
+				t.Errorf("expect drained %v, got %v", e, a)
+			}
+
+			var reused bool
+			ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+				GotConn: func(info httptrace.GotConnInfo) { reused = info.Reused },
+			})
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			resp, err = client.Do(req)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			resp.Body.Close()
+			if e, a := c.expectDrained, reused; e != a {
+				t.Errorf("expect connection reused %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+// countingBody is a response body that counts the bytes read from it.
+type countingBody struct {
+	*strings.Reader
+	read   int
+	closed bool
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	b.read += n
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestCloseResponseBodyMiddleware_DrainLimit(t *testing.T) {
+	body := &countingBody{Reader: strings.NewReader(strings.Repeat("a", 1<<20))}
+
+	stack := middleware.NewStack("test", NewStackRequest)
+	stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("OperationDeserializer", func(
+		ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+	) (middleware.DeserializeOutput, middleware.Metadata, error) {
+		return next.HandleDeserialize(ctx, in)
+	}), middleware.After)
+	if err := AddCloseResponseBodyMiddleware(stack, func(o *CloseResponseBodyOptions) {
+		o.DrainLimit = 1024
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	client := ClientDoFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: body}, nil
+	})
+	_, _, err := middleware.DecorateHandler(NewClientHandler(client), stack).
+		Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if body.read > 1025 {
+		t.Errorf("expect at most 1025 bytes drained, got %v", body.read)
+	}
+	if !body.closed {
+		t.Errorf("expect body closed")
+	}
+}
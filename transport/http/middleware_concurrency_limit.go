@@ -0,0 +1,201 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ConcurrencyLimiterOptions is the set of options that can be configured for
+// a ConcurrencyLimiter.
+type ConcurrencyLimiterOptions struct {
+	// The maximum number of in-flight requests to any single host. A value of
+	// 0 (the default) does not limit requests.
+	MaxConcurrency int
+
+	// Overrides MaxConcurrency for specific hosts, keyed by the request URL
+	// host (including the port, if any).
+	HostMaxConcurrency map[string]int
+
+	// The maximum amount of time a request will wait for a slot to become
+	// available before failing with a ConcurrencyLimitError. A value of 0 (the
+	// default) waits until the request context is done.
+	QueueTimeout time.Duration
+
+	// Invoked with the amount of time each request spent waiting for a slot.
+	// This is intended as a hook for metrics. May be called concurrently.
+	OnWait func(host string, wait time.Duration)
+}
+
+// ConcurrencyLimiter bounds the number of concurrent in-flight requests to
+// each host, such that a single slow dependency cannot consume the entirety
+// of a process's connection and goroutine budget.
+//
+// A ConcurrencyLimiter is safe for concurrent use. To be effective it must be
+// shared between every stack that sends requests to the same hosts, e.g. by
+// constructing one per client.
+type ConcurrencyLimiter struct {
+	options ConcurrencyLimiterOptions
+
+	// semaphores of the hosts with requests in flight or waiting for a slot,
+	// such that the hosts of idle semaphores are not retained
+	mu   sync.Mutex
+	sems map[string]*hostSemaphore
+}
+
+type hostSemaphore struct {
+	slots chan struct{}
+
+	// number of requests holding or waiting for a slot, guarded by the
+	// limiter's mu
+	refs int
+}
+
+// NewConcurrencyLimiter returns an initialized ConcurrencyLimiter.
+func NewConcurrencyLimiter(optFns ...func(*ConcurrencyLimiterOptions)) *ConcurrencyLimiter {
+	var o ConcurrencyLimiterOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	return &ConcurrencyLimiter{
+		options: o,
+		sems:    map[string]*hostSemaphore{},
+	}
+}
+
+// ConcurrencyLimitError is returned when a request could not acquire a slot
+// for its host before the configured queue timeout elapsed.
+type ConcurrencyLimitError struct {
+	Host    string
+	Timeout time.Duration
+}
+
+func (e *ConcurrencyLimitError) Error() string {
+	return fmt.Sprintf("concurrency limit for host %s not acquired within %v", e.Host, e.Timeout)
+}
+
+// AddConcurrencyLimiterMiddleware adds a finalize middleware to the stack that
+// acquires a slot from the limiter for the request's host before sending it.
+//
+// The middleware is added at the end of the finalize step, such that the slot
+// is held for each attempt of an operation only while the request is in
+// flight and its response is deserialized.
+func AddConcurrencyLimiterMiddleware(stack *middleware.Stack, limiter *ConcurrencyLimiter) error {
+	return stack.Finalize.Add(&concurrencyLimiterMiddleware{limiter: limiter}, middleware.After)
+}
+
+type concurrencyLimiterMiddleware struct {
+	limiter *ConcurrencyLimiter
+}
+
+// ID is the middleware identifier.
+func (*concurrencyLimiterMiddleware) ID() string {
+	return "ConcurrencyLimiter"
+}
+
+// DescribeConfig describes the limiter's configuration for a stack snapshot.
+func (m *concurrencyLimiterMiddleware) DescribeConfig() map[string]interface{} {
+	hosts := map[string]interface{}{}
+	for host, n := range m.limiter.options.HostMaxConcurrency {
+		hosts[host] = n
+	}
+
+	return map[string]interface{}{
+		"MaxConcurrency":     m.limiter.options.MaxConcurrency,
+		"HostMaxConcurrency": hosts,
+		"QueueTimeout":       m.limiter.options.QueueTimeout.String(),
+	}
+}
+
+func (m *concurrencyLimiterMiddleware) HandleFinalize(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	release, err := m.limiter.acquire(ctx, req.URL.Host)
+	if err != nil {
+		return out, metadata, err
+	}
+	defer release()
+
+	return next.HandleFinalize(ctx, in)
+}
+
+func (l *ConcurrencyLimiter) limit(host string) int {
+	if n, ok := l.options.HostMaxConcurrency[host]; ok {
+		return n
+	}
+	return l.options.MaxConcurrency
+}
+
+// retain returns the semaphore of host, creating it if it has none. The
+// semaphore must be released with release once the slot is released or the
+// request stops waiting for one.
+func (l *ConcurrencyLimiter) retain(host string) *hostSemaphore {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sem, ok := l.sems[host]
+	if !ok {
+		sem = &hostSemaphore{slots: make(chan struct{}, l.limit(host))}
+		l.sems[host] = sem
+	}
+	sem.refs++
+	return sem
+}
+
+// release removes the semaphore of host once no request holds or waits for
+// one of its slots.
+func (l *ConcurrencyLimiter) release(host string, sem *hostSemaphore) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if sem.refs--; sem.refs == 0 {
+		delete(l.sems, host)
+	}
+}
+
+// acquire blocks until a slot is available for host, returning a func to
+// release it.
+func (l *ConcurrencyLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	if l.limit(host) <= 0 {
+		return func() {}, nil
+	}
+
+	start := time.Now()
+	defer func() {
+		if l.options.OnWait != nil {
+			l.options.OnWait(host, time.Since(start))
+		}
+	}()
+
+	var timeout <-chan time.Time
+	if l.options.QueueTimeout > 0 {
+		timer := time.NewTimer(l.options.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	sem := l.retain(host)
+	select {
+	case sem.slots <- struct{}{}:
+		return func() {
+			<-sem.slots
+			l.release(host, sem)
+		}, nil
+	case <-timeout:
+		l.release(host, sem)
+		return nil, &ConcurrencyLimitError{Host: host, Timeout: l.options.QueueTimeout}
+	case <-ctx.Done():
+		l.release(host, sem)
+		return nil, &smithy.CanceledError{Err: ctx.Err()}
+	}
+}
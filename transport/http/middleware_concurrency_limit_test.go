@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+func newLimiterTestRequest(host string) middleware.FinalizeInput {
+	req := NewStackRequest().(*Request)
+	req.URL = &url.URL{Scheme: "https", Host: host}
+	return middleware.FinalizeInput{Request: req}
+}
+
+func TestConcurrencyLimiter_LimitsPerHost(t *testing.T) {
+	limiter := NewConcurrencyLimiter(func(o *ConcurrencyLimiterOptions) {
+		o.MaxConcurrency = 2
+		o.HostMaxConcurrency = map[string]int{"unlimited.example.com": 0}
+	})
+	m := &concurrencyLimiterMiddleware{limiter: limiter}
+
+	var inflight, maxInflight int32
+	next := middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+		out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+	) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInflight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInflight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return out, metadata, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := m.HandleFinalize(context.Background(), newLimiterTestRequest("limited.example.com"), next); err != nil {
+				t.Errorf("expect no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if e, a := int32(2), maxInflight; e != a {
+		t.Errorf("expect max %d in-flight requests, got %d", e, a)
+	}
+	if n := len(limiter.sems); n != 0 {
+		t.Errorf("expect idle semaphores to be removed, got %d", n)
+	}
+}
+
+func TestConcurrencyLimiter_QueueTimeout(t *testing.T) {
+	var waits int32
+	limiter := NewConcurrencyLimiter(func(o *ConcurrencyLimiterOptions) {
+		o.MaxConcurrency = 1
+		o.QueueTimeout = 10 * time.Millisecond
+		o.OnWait = func(host string, wait time.Duration) {
+			if e, a := "example.com", host; e != a {
+				t.Errorf("expect host %v, got %v", e, a)
+			}
+			atomic.AddInt32(&waits, 1)
+		}
+	})
+
+	release, err := limiter.acquire(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	_, err = limiter.acquire(context.Background(), "example.com")
+	var limitErr *ConcurrencyLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expect %T, got %v", limitErr, err)
+	}
+
+	release()
+	if _, err := limiter.acquire(context.Background(), "example.com"); err != nil {
+		t.Errorf("expect no error after release, got %v", err)
+	}
+
+	if e, a := int32(3), atomic.LoadInt32(&waits); e != a {
+		t.Errorf("expect %d wait observations, got %d", e, a)
+	}
+}
+
+func TestConcurrencyLimiter_Canceled(t *testing.T) {
+	limiter := NewConcurrencyLimiter(func(o *ConcurrencyLimiterOptions) {
+		o.MaxConcurrency = 1
+	})
+
+	if _, err := limiter.acquire(context.Background(), "example.com"); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := limiter.acquire(ctx, "example.com")
+	var cancelErr *smithy.CanceledError
+	if !errors.As(err, &cancelErr) {
+		t.Errorf("expect %T, got %v", cancelErr, err)
+	}
+	if e, a := 1, limiter.sems["example.com"].refs; e != a {
+		t.Errorf("expect %d semaphore references, got %d", e, a)
+	}
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// CookieJarOptions is the set of options that can be configured for a
+// CookieJar.
+type CookieJarOptions struct {
+	// The public suffix list used to determine whether a response may set a
+	// cookie for a parent domain with the Domain attribute, see
+	// net/http/cookiejar.Options.
+	//
+	// If nil (the default), the Domain attribute is ignored and every cookie
+	// is host-only. This isolates cookies to the exact host that set them,
+	// since without a public suffix list the jar could not otherwise prevent
+	// a host from setting cookies for an unrelated registrable domain.
+	PublicSuffixList cookiejar.PublicSuffixList
+}
+
+// CookieJar is an in-memory http.CookieJar, isolated per host and honoring
+// cookie expiry, for use with AddCookieJarMiddleware.
+//
+// A CookieJar is safe for concurrent use. It should be shared between the
+// stacks of a single client so that a session established by one operation
+// is used by the next.
+type CookieJar struct {
+	jar      *cookiejar.Jar
+	hostOnly bool
+}
+
+var _ http.CookieJar = (*CookieJar)(nil)
+
+// NewCookieJar returns an empty CookieJar.
+func NewCookieJar(optFns ...func(*CookieJarOptions)) *CookieJar {
+	var o CookieJarOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	// cookiejar.New never returns an error
+	jar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: o.PublicSuffixList})
+	return &CookieJar{
+		jar:      jar,
+		hostOnly: o.PublicSuffixList == nil,
+	}
+}
+
+// Cookies returns the unexpired cookies to send in a request for u.
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+// SetCookies stores the cookies received in a response for u.
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if j.hostOnly {
+		hostOnly := make([]*http.Cookie, len(cookies))
+		for i, c := range cookies {
+			cc := *c
+			cc.Domain = ""
+			hostOnly[i] = &cc
+		}
+		cookies = hostOnly
+	}
+	j.jar.SetCookies(u, cookies)
+}
+
+// AddCookieJarMiddleware adds a deserialize middleware to the stack that
+// sends the cookies in jar with each request attempt, and stores the cookies
+// set by each response in it.
+//
+// This is intended for services that maintain session affinity with cookies.
+// The HTTP client used with the stack should not also be configured with a
+// jar, otherwise cookies will be sent twice.
+//
+// The middleware is added at the end of the deserialize step, after requests
+// are signed, such that the cookies of each attempt are current.
+func AddCookieJarMiddleware(stack *middleware.Stack, jar http.CookieJar) error {
+	return stack.Deserialize.Add(&cookieJarMiddleware{jar: jar}, middleware.After)
+}
+
+type cookieJarMiddleware struct {
+	jar http.CookieJar
+}
+
+// ID is the middleware identifier.
+func (*cookieJarMiddleware) ID() string {
+	return "CookieJar"
+}
+
+func (m *cookieJarMiddleware) HandleDeserialize(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	u := cookieURL(req)
+	for _, c := range m.jar.Cookies(u) {
+		req.AddCookie(c)
+	}
+
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if resp, ok := out.RawResponse.(*Response); ok && resp != nil && resp.Response != nil {
+		if cookies := resp.Cookies(); len(cookies) != 0 {
+			m.jar.SetCookies(u, cookies)
+		}
+	}
+
+	return out, metadata, err
+}
+
+// cookieURL returns the URL the request is sent to, for the purposes of
+// cookie matching, accounting for an override of its Host.
+func cookieURL(req *Request) *url.URL {
+	u := *req.URL
+	if len(req.Host) != 0 {
+		u.Host = req.Host
+	}
+	return &u
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestCookieJarMiddleware(t *testing.T) {
+	jar := NewCookieJar()
+	m := &cookieJarMiddleware{jar: jar}
+
+	send := func(rawURL, host string, setCookies ...string) string {
+		t.Helper()
+
+		req := NewStackRequest().(*Request)
+		req.URL, _ = url.Parse(rawURL)
+		req.Host = host
+
+		var sent string
+		_, _, err := m.HandleDeserialize(context.Background(),
+			middleware.DeserializeInput{Request: req},
+			middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+				out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+			) {
+				sent = in.Request.(*Request).Header.Get("Cookie")
+				out.RawResponse = &Response{Response: &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{"Set-Cookie": setCookies},
+				}}
+				return out, metadata, nil
+			}),
+		)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		return sent
+	}
+
+	if e, a := "", send("https://a.example.com/", "", "session=1", "expired=1; Max-Age=0"); e != a {
+		t.Errorf("expect cookie %q, got %q", e, a)
+	}
+	if e, a := "session=1", send("https://a.example.com/other", ""); e != a {
+		t.Errorf("expect cookie %q, got %q", e, a)
+	}
+
+	// hosts are isolated, including from Domain cookies set by a sibling
+	send("https://b.example.com/", "", "b=1; Domain=example.com")
+	if e, a := "session=1", send("https://a.example.com/", ""); e != a {
+		t.Errorf("expect cookie %q, got %q", e, a)
+	}
+	if e, a := "b=1", send("https://b.example.com/", ""); e != a {
+		t.Errorf("expect cookie %q, got %q", e, a)
+	}
+
+	// cookies are matched against the Host override
+	if e, a := "b=1", send("https://10.0.0.1/", "b.example.com"); e != a {
+		t.Errorf("expect cookie %q, got %q", e, a)
+	}
+}
+
+func TestCookieJar_Expiry(t *testing.T) {
+	jar := NewCookieJar()
+	u, _ := url.Parse("https://service.example.com")
+
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "live", Value: "1", Expires: time.Now().Add(time.Hour)},
+		{Name: "dead", Value: "1", Expires: time.Now().Add(-time.Hour)},
+	})
+
+	cookies := jar.Cookies(u)
+	if len(cookies) != 1 || cookies[0].Name != "live" {
+		t.Errorf("expect only live cookie, got %v", cookies)
+	}
+}
+
+func TestAddCookieJarMiddleware(t *testing.T) {
+	stack := middleware.NewStack("test", NewStackRequest)
+	if err := AddCookieJarMiddleware(stack, NewCookieJar()); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if _, ok := stack.Deserialize.Get("CookieJar"); !ok {
+		t.Errorf("expect CookieJar middleware in deserialize step")
+	}
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultExpectContinueMinSize is the default minimum size of request
+// payloads sent with "Expect: 100-continue".
+const DefaultExpectContinueMinSize int64 = 2 * 1024 * 1024
+
+// DefaultExpectContinueTimeout is the default time a transport waits for a
+// server's first response headers after writing the headers of a request
+// with "Expect: 100-continue", see NewExpectContinueTransport.
+const DefaultExpectContinueTimeout = time.Second
+
+// ExpectContinueOptions is the set of options that can be configured for the
+// expect continue middleware.
+type ExpectContinueOptions struct {
+	// The minimum size in bytes of payloads sent with "Expect: 100-continue".
+	// Payloads of unknown length are always sent with it. Defaults to
+	// DefaultExpectContinueMinSize if zero.
+	MinSize int64
+}
+
+// AddExpectContinueMiddleware adds a build middleware to the stack that sets
+// the "Expect: 100-continue" header of requests with large payloads, such
+// that the payload is not sent if the server rejects the request from its
+// headers, e.g. as it is not authorized.
+//
+// The payload is only held back if the transport the request is sent with
+// has an ExpectContinueTimeout, see NewExpectContinueTransport. A request
+// that already has an Expect header is not modified.
+func AddExpectContinueMiddleware(stack *middleware.Stack, optFns ...func(*ExpectContinueOptions)) error {
+	var o ExpectContinueOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	if o.MinSize == 0 {
+		o.MinSize = DefaultExpectContinueMinSize
+	}
+
+	return stack.Build.Add(&expectContinueMiddleware{options: o}, middleware.After)
+}
+
+type expectContinueMiddleware struct {
+	options ExpectContinueOptions
+}
+
+// ID is the middleware identifier.
+func (*expectContinueMiddleware) ID() string {
+	return "ExpectContinue"
+}
+
+// DescribeConfig describes the middleware's configuration for a stack
+// snapshot.
+func (m *expectContinueMiddleware) DescribeConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"MinSize": m.options.MinSize,
+	}
+}
+
+func (m *expectContinueMiddleware) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	if len(req.Header.Get("Expect")) != 0 || req.GetStream() == nil {
+		return next.HandleBuild(ctx, in)
+	}
+
+	size, known, err := req.StreamLength()
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to get length of request stream, %w", err)
+	}
+	if !known || size >= m.options.MinSize {
+		req.Header.Set("Expect", "100-continue")
+
+		// net/http only waits for a 100 Continue before sending the payload of
+		// HTTP/1.1 requests, which stack requests are sent as without setting
+		// their protocol.
+		if !req.ProtoAtLeast(1, 1) {
+			req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/1.1", 1, 1
+		}
+	}
+
+	return next.HandleBuild(ctx, in)
+}
+
+// NewExpectContinueTransport returns a clone of t that waits up to timeout
+// for a server's response to the headers of a request with
+// "Expect: 100-continue" before sending its payload. A timeout of 0 defaults
+// to DefaultExpectContinueTimeout. t is not modified.
+func NewExpectContinueTransport(t *http.Transport, timeout time.Duration) *http.Transport {
+	if timeout == 0 {
+		timeout = DefaultExpectContinueTimeout
+	}
+
+	t = t.Clone()
+	t.ExpectContinueTimeout = timeout
+	return t
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestExpectContinueMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		body         func() io.Reader
+		header       string
+		expectHeader string
+	}{
+		"large payload": {
+			body:         func() io.Reader { return strings.NewReader(strings.Repeat("a", 100)) },
+			expectHeader: "100-continue",
+		},
+		"small payload": {
+			body: func() io.Reader { return strings.NewReader("a") },
+		},
+		"unknown length": {
+			body:         func() io.Reader { return unknownLengthReader{strings.NewReader("a")} },
+			expectHeader: "100-continue",
+		},
+		"no payload": {},
+		"existing header": {
+			body:         func() io.Reader { return strings.NewReader(strings.Repeat("a", 100)) },
+			header:       "custom",
+			expectHeader: "custom",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := &expectContinueMiddleware{options: ExpectContinueOptions{MinSize: 10}}
+			req := NewStackRequest().(*Request)
+			if len(c.header) != 0 {
+				req.Header.Set("Expect", c.header)
+			}
+			if c.body != nil {
+				var err error
+				if req, err = req.SetStream(c.body()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var expect string
+			_, _, err := m.HandleBuild(context.Background(), middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					expect = in.Request.(*Request).Header.Get("Expect")
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.expectHeader, expect; e != a {
+				t.Errorf("expect Expect header %q, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestExpectContinue_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// rejected without reading the payload, closing the connection such
+		// that the transport does not send the payload to reuse it
+		w.Header().Set("Connection", "close")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	endpoint, _ := url.Parse(server.URL)
+
+	body := &countingBody{Reader: strings.NewReader(strings.Repeat("a", 1<<20))}
+	stack := middleware.NewStack("test", NewStackRequest)
+	stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize", func(
+		ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+	) (middleware.SerializeOutput, middleware.Metadata, error) {
+		req := in.Request.(*Request)
+		req.URL = endpoint
+		req.Method = http.MethodPut
+		r, err := req.SetStream(body)
+		if err != nil {
+			return middleware.SerializeOutput{}, middleware.Metadata{}, err
+		}
+		in.Request = r
+		return next.HandleSerialize(ctx, in)
+	}), middleware.After)
+	stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("deserialize", func(
+		ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+	) (middleware.DeserializeOutput, middleware.Metadata, error) {
+		out, metadata, err := next.HandleDeserialize(ctx, in)
+		out.Result = out.RawResponse
+		return out, metadata, err
+	}), middleware.After)
+	if err := AddComputeContentLengthMiddleware(stack); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddExpectContinueMiddleware(stack, func(o *ExpectContinueOptions) {
+		o.MinSize = 1024
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	transport := NewExpectContinueTransport(&http.Transport{}, 10*time.Second)
+	defer transport.CloseIdleConnections()
+
+	out, _, err := middleware.DecorateHandler(NewClientHandler(&http.Client{Transport: transport}), stack).
+		Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	resp := out.(*Response)
+	resp.Body.Close()
+
+	if e, a := http.StatusForbidden, resp.StatusCode; e != a {
+		t.Errorf("expect status %v, got %v", e, a)
+	}
+	if body.read != 0 {
+		t.Errorf("expect payload not sent, got %v bytes sent", body.read)
+	}
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// HostPrefixLabelsFunc returns the values of the labels of the host prefix of
+// an operation, by the names of the labels, e.g. from the members of the
+// operation input bound to them.
+type HostPrefixLabelsFunc func(ctx context.Context) (map[string]*string, error)
+
+// AddHostPrefixMiddleware adds middleware to the Finalize step, after the
+// endpoint is resolved, that prefixes the host of the request endpoint with
+// the host prefix of an operation, e.g. "{AccountId}.data.", with labels in
+// braces replaced by their values returned by labels, which may be nil if the
+// pattern has no labels.
+//
+// Returns an error if the pattern is malformed. Each label value must be a
+// valid host label, and the host prefixed must be a valid endpoint host, see
+// ValidateEndpointHost, or the request fails with a
+// *smithy.SerializationError. A request to an endpoint whose host is an IP
+// address fails, as it cannot be prefixed.
+//
+// The host is not prefixed if prefixing is disabled, see
+// DisableEndpointHostPrefix, or the hostname is immutable, see
+// SetHostnameImmutable.
+//
+// Generated clients add the middleware, with ID "EndpointHostPrefix", to
+// each operation with a host prefix.
+func AddHostPrefixMiddleware(stack *middleware.Stack, pattern string, labels HostPrefixLabelsFunc) error {
+	segments, err := parseHostPrefix(pattern)
+	if err != nil {
+		return err
+	}
+	for _, s := range segments {
+		if s.label && labels == nil {
+			return fmt.Errorf("host prefix %q has labels, but no label values", pattern)
+		}
+	}
+
+	m := &hostPrefixMiddleware{pattern: pattern, segments: segments, labels: labels}
+	if _, ok := stack.Finalize.Get("ResolveEndpointV2"); ok {
+		return stack.Finalize.Insert(m, "ResolveEndpointV2", middleware.After)
+	}
+	return stack.Finalize.Add(m, middleware.Before)
+}
+
+// hostPrefixSegment is a literal segment, or label, of a host prefix.
+type hostPrefixSegment struct {
+	value string
+	label bool
+}
+
+func parseHostPrefix(pattern string) ([]hostPrefixSegment, error) {
+	var segments []hostPrefixSegment
+	for p := pattern; len(p) != 0; {
+		start := strings.IndexAny(p, "{}")
+		if start == -1 {
+			segments = append(segments, hostPrefixSegment{value: p})
+			break
+		}
+		if p[start] == '}' {
+			return nil, fmt.Errorf("host prefix %q has unopened label", pattern)
+		}
+		if start > 0 {
+			segments = append(segments, hostPrefixSegment{value: p[:start]})
+		}
+
+		end := strings.IndexAny(p[start+1:], "{}")
+		if end == -1 || p[start+1+end] != '}' {
+			return nil, fmt.Errorf("host prefix %q has unclosed label", pattern)
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("host prefix %q has empty label", pattern)
+		}
+		segments = append(segments, hostPrefixSegment{value: p[start+1 : start+1+end], label: true})
+		p = p[start+1+end+1:]
+	}
+	return segments, nil
+}
+
+type hostPrefixMiddleware struct {
+	pattern  string
+	segments []hostPrefixSegment
+	labels   HostPrefixLabelsFunc
+}
+
+// ID is the middleware identifier.
+func (*hostPrefixMiddleware) ID() string {
+	return "EndpointHostPrefix"
+}
+
+// DescribeConfig describes the middleware's configuration for a stack
+// snapshot.
+func (m *hostPrefixMiddleware) DescribeConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"Pattern": m.pattern,
+	}
+}
+
+func (m *hostPrefixMiddleware) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	if GetHostnameImmutable(ctx) || IsEndpointHostPrefixDisabled(ctx) {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	prefix, err := m.prefix(ctx)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	if hostname := req.URL.Hostname(); net.ParseIP(stripZone(hostname)) != nil {
+		return out, metadata, &smithy.SerializationError{Err: fmt.Errorf(
+			"endpoint host %s is an IP address, which cannot be prefixed with the host prefix %q "+
+				"of the operation, disable host prefixing with DisableEndpointHostPrefix", hostname, prefix)}
+	}
+
+	host := prefix + req.URL.Host
+	if err := ValidateEndpointHost(host); err != nil {
+		return out, metadata, &smithy.SerializationError{Err: fmt.Errorf(
+			"host prefix %q forms an invalid endpoint host, %w", prefix, err)}
+	}
+	req.URL.Host = host
+
+	return next.HandleFinalize(ctx, in)
+}
+
+// prefix returns the host prefix with the values of its labels.
+func (m *hostPrefixMiddleware) prefix(ctx context.Context) (string, error) {
+	var values map[string]*string
+	if m.labels != nil {
+		var err error
+		if values, err = m.labels(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	var prefix strings.Builder
+	for _, s := range m.segments {
+		if !s.label {
+			prefix.WriteString(s.value)
+			continue
+		}
+
+		v := values[s.value]
+		if v == nil {
+			return "", &smithy.SerializationError{Err: fmt.Errorf(
+				"%s forms part of the endpoint host and so may not be nil", s.value)}
+		}
+		if !ValidHostLabel(*v) {
+			return "", &smithy.SerializationError{Err: fmt.Errorf(
+				"%s forms part of the endpoint host and so must match \"[a-zA-Z0-9-]{1,63}\", but was \"%s\"",
+				s.value, *v)}
+		}
+		prefix.WriteString(*v)
+	}
+	return prefix.String(), nil
+}
@@ -0,0 +1,209 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestHostPrefixMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		ctx        func(context.Context) context.Context
+		pattern    string
+		labels     map[string]*string
+		host       string
+		expectHost string
+		expectErr  string
+	}{
+		"literal": {
+			pattern:    "data.",
+			host:       "service.amazonaws.com",
+			expectHost: "data.service.amazonaws.com",
+		},
+		"label": {
+			pattern:    "{AccountId}.data.",
+			labels:     map[string]*string{"AccountId": ptr.String("123456789012")},
+			host:       "service.amazonaws.com",
+			expectHost: "123456789012.data.service.amazonaws.com",
+		},
+		"port": {
+			pattern:    "{Bucket}-data.",
+			labels:     map[string]*string{"Bucket": ptr.String("bucket")},
+			host:       "localhost:8080",
+			expectHost: "bucket-data.localhost:8080",
+		},
+		"nil label": {
+			pattern:   "{AccountId}.",
+			labels:    map[string]*string{},
+			host:      "service.amazonaws.com",
+			expectErr: "AccountId forms part of the endpoint host and so may not be nil",
+		},
+		"invalid label": {
+			pattern:   "{AccountId}.",
+			labels:    map[string]*string{"AccountId": ptr.String("a.b")},
+			host:      "service.amazonaws.com",
+			expectErr: `AccountId forms part of the endpoint host and so must match "[a-zA-Z0-9-]{1,63}", but was "a.b"`,
+		},
+		"invalid host": {
+			pattern:   strings.Repeat("a.", 130),
+			host:      "service.amazonaws.com",
+			expectErr: "forms an invalid endpoint host",
+		},
+		"IPv4 host": {
+			pattern:   "data.",
+			host:      "127.0.0.1:8080",
+			expectErr: "endpoint host 127.0.0.1 is an IP address",
+		},
+		"IPv6 host": {
+			pattern:   "data.",
+			host:      "[::1]",
+			expectErr: "endpoint host ::1 is an IP address",
+		},
+		"disabled": {
+			ctx: func(ctx context.Context) context.Context {
+				return DisableEndpointHostPrefix(ctx, true)
+			},
+			pattern:    "data.",
+			host:       "127.0.0.1",
+			expectHost: "127.0.0.1",
+		},
+		"hostname immutable": {
+			ctx: func(ctx context.Context) context.Context {
+				return SetHostnameImmutable(ctx, true)
+			},
+			pattern:    "data.",
+			host:       "service.amazonaws.com",
+			expectHost: "service.amazonaws.com",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			segments, err := parseHostPrefix(c.pattern)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			m := &hostPrefixMiddleware{
+				pattern:  c.pattern,
+				segments: segments,
+				labels: func(context.Context) (map[string]*string, error) {
+					return c.labels, nil
+				},
+			}
+
+			ctx := context.Background()
+			if c.ctx != nil {
+				ctx = c.ctx(middleware.ClearStackValues(ctx))
+			}
+			req := &Request{Request: NewStackRequest().(*Request).Request}
+			req.URL = &url.URL{Scheme: "https", Host: c.host}
+
+			var host string
+			_, _, err = m.HandleFinalize(ctx, middleware.FinalizeInput{Request: req},
+				middleware.FinalizeHandlerFunc(func(
+					ctx context.Context, in middleware.FinalizeInput,
+				) (middleware.FinalizeOutput, middleware.Metadata, error) {
+					host = in.Request.(*Request).URL.Host
+					return middleware.FinalizeOutput{}, middleware.Metadata{}, nil
+				}),
+			)
+
+			if len(c.expectErr) != 0 {
+				var serErr *smithy.SerializationError
+				if !errors.As(err, &serErr) {
+					t.Fatalf("expect serialization error, got %v", err)
+				}
+				if !strings.Contains(err.Error(), c.expectErr) {
+					t.Errorf("expect error to contain %q, got %v", c.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.expectHost, host; e != a {
+				t.Errorf("expect host %q, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestParseHostPrefix(t *testing.T) {
+	cases := map[string]struct {
+		pattern   string
+		expect    []hostPrefixSegment
+		expectErr bool
+	}{
+		"literal": {
+			pattern: "data.",
+			expect:  []hostPrefixSegment{{value: "data."}},
+		},
+		"labels": {
+			pattern: "{A}-{B}.data.",
+			expect: []hostPrefixSegment{
+				{value: "A", label: true},
+				{value: "-"},
+				{value: "B", label: true},
+				{value: ".data."},
+			},
+		},
+		"unclosed": {
+			pattern:   "{A.",
+			expectErr: true,
+		},
+		"nested": {
+			pattern:   "{A{B}}.",
+			expectErr: true,
+		},
+		"unopened": {
+			pattern:   "A}.",
+			expectErr: true,
+		},
+		"empty label": {
+			pattern:   "{}.",
+			expectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseHostPrefix(c.pattern)
+			if c.expectErr {
+				if err == nil {
+					t.Fatalf("expect error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !reflect.DeepEqual(c.expect, actual) {
+				t.Errorf("expect %v, got %v", c.expect, actual)
+			}
+		})
+	}
+}
+
+func TestAddHostPrefixMiddleware(t *testing.T) {
+	stack := middleware.NewStack("test", NewStackRequest)
+	stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("Retry", nil), middleware.After)
+	stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("ResolveEndpointV2", nil), middleware.Before)
+
+	if err := AddHostPrefixMiddleware(stack, "data.", nil); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := []string{"ResolveEndpointV2", "EndpointHostPrefix", "Retry"}, stack.Finalize.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	if err := AddHostPrefixMiddleware(stack, "{AccountId}.", nil); err == nil {
+		t.Errorf("expect error for labels without values")
+	}
+}
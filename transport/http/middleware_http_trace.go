@@ -0,0 +1,243 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go/metrics"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ConnectionTimings are the durations of the phases of sending a request,
+// recorded with httptrace, such that the latency of the network can be
+// separated from the latency of the service. A phase that did not occur, e.g.
+// the DNS lookup of a request sent on a reused connection, has a duration of
+// 0.
+type ConnectionTimings struct {
+	// The time to obtain a connection, including the DNS lookup, connect,
+	// and TLS handshake if a new connection was dialed.
+	GetConn time.Duration
+
+	// The time of the DNS lookup of the host.
+	DNSLookup time.Duration
+
+	// The time to connect to the host.
+	Connect time.Duration
+
+	// The time of the TLS handshake.
+	TLSHandshake time.Duration
+
+	// The time from when the request was written to when the first byte of
+	// the response was received, i.e. the latency of the service and the
+	// round trip of the network.
+	WaitForResponse time.Duration
+
+	// The time from when a connection was requested to when the first byte
+	// of the response was received.
+	TimeToFirstByte time.Duration
+
+	// Whether the request was sent on a connection that was used for a
+	// previous request.
+	ConnReused bool
+}
+
+var connectionTimingsKey = middleware.NewMetadataKey[ConnectionTimings]("ConnectionTimings")
+
+// GetConnectionTimings returns the connection timings of the last attempt
+// of an operation, recorded by the middleware added with
+// AddHTTPTraceMiddleware.
+func GetConnectionTimings(metadata middleware.MetadataReader) (ConnectionTimings, bool) {
+	return connectionTimingsKey.Get(metadata)
+}
+
+// HTTPTraceOptions is the set of options that can be configured for the
+// httptrace middleware.
+type HTTPTraceOptions struct {
+	// Invoked with the connection timings of each attempt of an operation
+	// once its response headers are received, or it fails. This is intended
+	// as a hook for metrics. May be called concurrently.
+	OnTimings func(ctx context.Context, timings ConnectionTimings)
+
+	// The telemetry the connection timings are recorded with, as
+	// histograms of the meter of its MeterProvider, if any.
+	Telemetry TelemetryOptions
+}
+
+// AddHTTPTraceMiddleware adds a finalize middleware to the end of the stack's
+// Finalize step that traces each attempt of an operation with httptrace, and
+// records its ConnectionTimings in the metadata of the operation, see
+// GetConnectionTimings. Client traces already in the request context are
+// still invoked.
+func AddHTTPTraceMiddleware(stack *middleware.Stack, optFns ...func(*HTTPTraceOptions)) error {
+	var o HTTPTraceOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	m := &httpTraceMiddleware{options: o}
+	if o.Telemetry.MeterProvider != nil {
+		var err error
+		if m.metrics, err = newConnectionMetrics(o.Telemetry); err != nil {
+			return err
+		}
+	}
+	return stack.Finalize.Add(m, middleware.After)
+}
+
+type httpTraceMiddleware struct {
+	options HTTPTraceOptions
+
+	// records the connection timings as metrics, if enabled
+	metrics *connectionMetrics
+}
+
+func (*httpTraceMiddleware) ID() string {
+	return "HTTPTrace"
+}
+
+func (m *httpTraceMiddleware) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	rec := &connectionTimingsRecorder{}
+	out, metadata, err = next.HandleFinalize(httptrace.WithClientTrace(ctx, rec.trace()), in)
+
+	timings := rec.timings()
+	connectionTimingsKey.Set(&metadata, timings)
+	if m.options.OnTimings != nil {
+		m.options.OnTimings(ctx, timings)
+	}
+	if m.metrics != nil {
+		m.metrics.record(ctx, timings)
+	}
+	return out, metadata, err
+}
+
+// connectionMetrics records connection timings as histograms of the meter of
+// the telemetry.
+type connectionMetrics struct {
+	options TelemetryOptions
+
+	getConn, dnsLookup, connect, tlsHandshake, timeToFirstByte metrics.Float64Histogram
+}
+
+func newConnectionMetrics(o TelemetryOptions) (*connectionMetrics, error) {
+	m := &connectionMetrics{options: o}
+	meter := o.meter()
+	for _, h := range []struct {
+		name, description string
+		histogram         *metrics.Float64Histogram
+	}{
+		{"client.http.connections.acquire_duration", "The time to obtain a connection", &m.getConn},
+		{"client.http.connections.dns_lookup_duration", "The time of the DNS lookup of the host", &m.dnsLookup},
+		{"client.http.connections.connect_duration", "The time to connect to the host", &m.connect},
+		{"client.http.connections.tls_handshake_duration", "The time of the TLS handshake", &m.tlsHandshake},
+		{"client.http.time_to_first_byte", "The time from when a connection was requested to the first byte of the response", &m.timeToFirstByte},
+	} {
+		var err error
+		*h.histogram, err = meter.Float64Histogram(h.name, metrics.WithUnit("s"), metrics.WithDescription(h.description))
+		if err != nil {
+			return nil, fmt.Errorf("create %s histogram, %w", h.name, err)
+		}
+	}
+	return m, nil
+}
+
+// record records the durations of the phases that occurred.
+func (m *connectionMetrics) record(ctx context.Context, t ConnectionTimings) {
+	props := m.options.properties("http.conn_reused", t.ConnReused)
+	for _, d := range []struct {
+		histogram metrics.Float64Histogram
+		duration  time.Duration
+	}{
+		{m.getConn, t.GetConn},
+		{m.dnsLookup, t.DNSLookup},
+		{m.connect, t.Connect},
+		{m.tlsHandshake, t.TLSHandshake},
+		{m.timeToFirstByte, t.TimeToFirstByte},
+	} {
+		if d.duration != 0 {
+			d.histogram.Record(ctx, d.duration.Seconds(), metrics.WithProperties(props))
+		}
+	}
+}
+
+// connectionTimingsRecorder records the connection timings of a request from
+// the events of its client trace, which may be invoked concurrently, e.g. as
+// the addresses of a host are dialed in parallel.
+type connectionTimingsRecorder struct {
+	mu sync.Mutex
+	t  ConnectionTimings
+
+	// the start of each phase
+	getConn, dnsStart, connectStart, tlsStart, wroteRequest time.Time
+}
+
+func (r *connectionTimingsRecorder) record(fn func(now time.Time)) {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(now)
+}
+
+func (r *connectionTimingsRecorder) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			r.record(func(now time.Time) { r.getConn = now })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.record(func(now time.Time) {
+				r.t.GetConn = now.Sub(r.getConn)
+				r.t.ConnReused = info.Reused
+			})
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			r.record(func(now time.Time) { r.dnsStart = now })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			r.record(func(now time.Time) { r.t.DNSLookup = now.Sub(r.dnsStart) })
+		},
+		ConnectStart: func(network, addr string) {
+			r.record(func(now time.Time) {
+				if r.connectStart.IsZero() {
+					r.connectStart = now
+				}
+			})
+		},
+		ConnectDone: func(network, addr string, err error) {
+			r.record(func(now time.Time) {
+				if err == nil && r.t.Connect == 0 {
+					r.t.Connect = now.Sub(r.connectStart)
+				}
+			})
+		},
+		TLSHandshakeStart: func() {
+			r.record(func(now time.Time) { r.tlsStart = now })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			r.record(func(now time.Time) { r.t.TLSHandshake = now.Sub(r.tlsStart) })
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			r.record(func(now time.Time) { r.wroteRequest = now })
+		},
+		GotFirstResponseByte: func() {
+			r.record(func(now time.Time) {
+				if !r.wroteRequest.IsZero() {
+					r.t.WaitForResponse = now.Sub(r.wroteRequest)
+				}
+				r.t.TimeToFirstByte = now.Sub(r.getConn)
+			})
+		},
+	}
+}
+
+func (r *connectionTimingsRecorder) timings() ConnectionTimings {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.t
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestHTTPTraceMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		tls bool
+	}{
+		"http":  {},
+		"https": {tls: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(10 * time.Millisecond)
+			})
+			newServer := httptest.NewServer
+			if c.tls {
+				newServer = httptest.NewTLSServer
+			}
+			server := newServer(handler)
+			defer server.Close()
+			endpoint, _ := url.Parse(server.URL)
+
+			stack := middleware.NewStack("test", NewStackRequest)
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize", func(
+				ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+			) (middleware.SerializeOutput, middleware.Metadata, error) {
+				in.Request.(*Request).URL = endpoint
+				return next.HandleSerialize(ctx, in)
+			}), middleware.After)
+
+			var reported []ConnectionTimings
+			if err := AddHTTPTraceMiddleware(stack, func(o *HTTPTraceOptions) {
+				o.OnTimings = func(ctx context.Context, timings ConnectionTimings) {
+					reported = append(reported, timings)
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			h := middleware.DecorateHandler(NewClientHandler(server.Client()), stack)
+			for i := 0; i < 2; i++ {
+				_, metadata, err := h.Handle(context.Background(), struct{}{})
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+
+				timings, ok := GetConnectionTimings(metadata)
+				if !ok {
+					t.Fatalf("expect connection timings")
+				}
+				if e, a := i != 0, timings.ConnReused; e != a {
+					t.Errorf("%d: expect reused connection %v, got %v", i, e, a)
+				}
+				if e, a := !timings.ConnReused, timings.Connect > 0; e != a {
+					t.Errorf("%d: expect connect timing %v, got %v", i, e, timings.Connect)
+				}
+				if e, a := c.tls && !timings.ConnReused, timings.TLSHandshake > 0; e != a {
+					t.Errorf("%d: expect TLS handshake timing %v, got %v", i, e, timings.TLSHandshake)
+				}
+				if timings.WaitForResponse < 10*time.Millisecond {
+					t.Errorf("%d: expect wait for response of at least 10ms, got %v", i, timings.WaitForResponse)
+				}
+				if timings.TimeToFirstByte < timings.WaitForResponse+timings.GetConn {
+					t.Errorf("%d: expect time to first byte to include wait and connection, got %+v", i, timings)
+				}
+			}
+			if e, a := 2, len(reported); e != a {
+				t.Errorf("expect %v reported timings, got %v", e, a)
+			}
+		})
+	}
+}
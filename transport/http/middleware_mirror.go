@@ -0,0 +1,251 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/url"
+
+	smithycontext "github.com/aws/smithy-go/context"
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// MirrorOptions is the set of options that can be configured for the request
+// mirror middleware.
+type MirrorOptions struct {
+	// The endpoint to which mirrored requests are sent. The scheme and host of
+	// each mirrored request are replaced with those of Endpoint, the path is
+	// prefixed with its path. Required.
+	Endpoint *url.URL
+
+	// The HTTP client used to send mirrored requests. Required.
+	Client ClientDo
+
+	// The fraction of requests to mirror, in the range [0, 1]. A value of 0
+	// (the default) mirrors nothing.
+	SampleRate float64
+
+	// The maximum number of mirrored requests in flight at once. Sampled
+	// requests beyond it are not mirrored, and reported as an error. Defaults
+	// to DefaultMirrorMaxConcurrency.
+	MaxConcurrency int
+
+	// The headers removed from mirrored requests, as they carry the
+	// credentials the request was signed with for its primary endpoint.
+	// Defaults to DefaultRedactedHeaders. Set to an empty, non-nil slice to
+	// mirror all headers as they are sent.
+	StrippedHeaders []string
+
+	// The query parameters removed from mirrored requests, as they may carry
+	// credentials, e.g. of a presigned request. Defaults to
+	// DefaultRedactedQueryParams. Set to an empty, non-nil slice to mirror
+	// all query parameters as they are sent.
+	StrippedQueryParams []string
+
+	// If set, invoked with each mirrored request before it is sent, after its
+	// headers and query parameters have been stripped, e.g. to sign it with
+	// credentials for the mirror endpoint. An error fails the mirrored
+	// request. May be called concurrently.
+	Sign func(context.Context, *Request) error
+
+	// Invoked with any error encountered while sending a mirrored request, in
+	// addition to it being logged at logging.Debug. This is intended as a hook
+	// for metrics. May be called concurrently.
+	OnError func(error)
+}
+
+// DefaultMirrorMaxConcurrency is the default maximum number of mirrored
+// requests in flight at once, see MirrorOptions.MaxConcurrency.
+const DefaultMirrorMaxConcurrency = 16
+
+// AddMirrorMiddleware adds a finalize middleware that asynchronously sends a
+// copy of a sampled fraction of requests to a secondary endpoint, e.g. to
+// compare the behavior of a service backend being migrated to.
+//
+// Mirrored requests never affect the operation: they are sent outside of the
+// operation's context cancellation, their responses are discarded, and any
+// error is only logged. Because the middleware is added at the end of the
+// finalize step, every attempt of an operation is a candidate for mirroring.
+//
+// Because the middleware runs after the request is signed, the credentials
+// of the signature must not reach the mirror endpoint: the headers and query
+// parameters of MirrorOptions.StrippedHeaders and StrippedQueryParams are
+// removed from each mirrored request. Mirrored requests are therefore
+// unsigned, unless MirrorOptions.Sign signs them for the mirror endpoint.
+//
+// Request bodies are buffered in memory in order to be sent twice. Requests
+// whose body stream is not seekable are never mirrored, nor are requests
+// sampled while MirrorOptions.MaxConcurrency mirrored requests are in flight.
+func AddMirrorMiddleware(stack *middleware.Stack, optFns ...func(*MirrorOptions)) error {
+	var o MirrorOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	if o.Endpoint == nil {
+		return fmt.Errorf("mirror endpoint is required")
+	}
+	if o.Client == nil {
+		return fmt.Errorf("mirror client is required")
+	}
+
+	return stack.Finalize.Add(newMirrorMiddleware(o), middleware.After)
+}
+
+type mirrorMiddleware struct {
+	options MirrorOptions
+
+	// holds a token per mirrored request in flight
+	sem chan struct{}
+}
+
+func newMirrorMiddleware(o MirrorOptions) *mirrorMiddleware {
+	if o.MaxConcurrency <= 0 {
+		o.MaxConcurrency = DefaultMirrorMaxConcurrency
+	}
+	if o.StrippedHeaders == nil {
+		o.StrippedHeaders = DefaultRedactedHeaders
+	}
+	if o.StrippedQueryParams == nil {
+		o.StrippedQueryParams = DefaultRedactedQueryParams
+	}
+	return &mirrorMiddleware{
+		options: o,
+		sem:     make(chan struct{}, o.MaxConcurrency),
+	}
+}
+
+// ID is the middleware identifier.
+func (*mirrorMiddleware) ID() string {
+	return "RequestMirror"
+}
+
+// DescribeConfig describes the mirror's configuration for a stack snapshot.
+// Any password in the endpoint is redacted.
+func (m *mirrorMiddleware) DescribeConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"Endpoint":       m.options.Endpoint.Redacted(),
+		"SampleRate":     m.options.SampleRate,
+		"MaxConcurrency": m.options.MaxConcurrency,
+	}
+}
+
+func (m *mirrorMiddleware) HandleFinalize(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	if m.sample() {
+		m.mirror(ctx, req)
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
+
+func (m *mirrorMiddleware) sample() bool {
+	return m.options.SampleRate > 0 && rand.Float64() < m.options.SampleRate
+}
+
+func (m *mirrorMiddleware) mirror(ctx context.Context, req *Request) {
+	logger := middleware.GetLogger(ctx)
+
+	select {
+	case m.sem <- struct{}{}:
+	default:
+		m.onError(logger, fmt.Errorf("%d mirrored requests in flight", m.options.MaxConcurrency))
+		return
+	}
+
+	mirror, err := m.newMirrorRequest(req)
+	if err != nil || mirror == nil {
+		<-m.sem
+		if err != nil {
+			m.onError(logger, fmt.Errorf("copy request: %w", err))
+		}
+		return
+	}
+
+	go m.send(smithycontext.WithSuppressCancel(ctx), logger, mirror)
+}
+
+// newMirrorRequest returns a copy of req targeted at the mirror endpoint, or
+// nil if the request cannot be mirrored.
+func (m *mirrorMiddleware) newMirrorRequest(req *Request) (*Request, error) {
+	stream := req.GetStream()
+	if stream != nil && !req.IsStreamSeekable() {
+		return nil, nil
+	}
+
+	mirror := req.Clone()
+	if stream != nil {
+		body, err := io.ReadAll(stream)
+		if err != nil {
+			return nil, fmt.Errorf("read stream: %w", err)
+		}
+		if err := req.RewindStream(); err != nil {
+			return nil, fmt.Errorf("rewind stream: %w", err)
+		}
+
+		if mirror, err = mirror.SetStream(bytes.NewReader(body)); err != nil {
+			return nil, fmt.Errorf("set stream: %w", err)
+		}
+	}
+
+	mirror.URL.Scheme = m.options.Endpoint.Scheme
+	mirror.URL.Host = m.options.Endpoint.Host
+	mirror.URL.RawPath = JoinPath(m.options.Endpoint.EscapedPath(), mirror.URL.EscapedPath())
+	mirror.URL.Path = JoinPath(m.options.Endpoint.Path, mirror.URL.Path)
+	mirror.Host = ""
+
+	for _, name := range m.options.StrippedHeaders {
+		mirror.Header.Del(name)
+	}
+	query := mirror.URL.Query()
+	stripped := false
+	for _, name := range m.options.StrippedQueryParams {
+		if query.Has(name) {
+			query.Del(name)
+			stripped = true
+		}
+	}
+	if stripped {
+		mirror.URL.RawQuery = query.Encode()
+	}
+
+	return mirror, nil
+}
+
+func (m *mirrorMiddleware) send(ctx context.Context, logger logging.Logger, req *Request) {
+	defer func() { <-m.sem }()
+
+	if m.options.Sign != nil {
+		if err := m.options.Sign(ctx, req); err != nil {
+			m.onError(logger, fmt.Errorf("sign request: %w", err))
+			return
+		}
+	}
+
+	resp, err := m.options.Client.Do(req.Build(ctx))
+	if err != nil {
+		m.onError(logger, &RequestSendError{Err: err})
+		return
+	}
+
+	if resp.Body != nil {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+func (m *mirrorMiddleware) onError(logger logging.Logger, err error) {
+	logger.Logf(logging.Debug, "request mirror failed, %v", err)
+	if m.options.OnError != nil {
+		m.options.OnError(err)
+	}
+}
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+	smithytime "github.com/aws/smithy-go/time"
+)
+
+// ResponseValidators are the cache validators of an HTTP response, which can
+// be sent in a later conditional request with WithIfNoneMatch or
+// WithIfModifiedSince.
+type ResponseValidators struct {
+	// The value of the ETag header, including quotes and any weak prefix,
+	// e.g. `W/"abc"`. Empty if the header was not present.
+	ETag string
+
+	// The value of the Last-Modified header. The zero time if the header was
+	// not present or invalid.
+	LastModified time.Time
+}
+
+var responseValidatorsKey = middleware.NewMetadataKey[ResponseValidators]("ResponseValidators")
+
+// GetResponseValidators retrieves the cache validators of the HTTP response
+// from the operation metadata, as recorded by the NotModified middleware.
+// Returns false if the response had no validators.
+func GetResponseValidators(metadata middleware.Metadata) (v ResponseValidators, ok bool) {
+	return responseValidatorsKey.Get(metadata)
+}
+
+func setResponseValidators(metadata *middleware.Metadata, v ResponseValidators) {
+	responseValidatorsKey.Set(metadata, v)
+}
+
+// NotModifiedError is returned by an operation whose conditional request was
+// answered with 304 Not Modified. The response has no body, the caller
+// should use its cached representation of the resource, which is still
+// current.
+type NotModifiedError struct {
+	Response *Response
+
+	// The validators of the response, which identify the current
+	// representation of the resource.
+	Validators ResponseValidators
+}
+
+// HTTPStatusCode returns the HTTP response status code received from the service.
+func (e *NotModifiedError) HTTPStatusCode() int { return e.Response.StatusCode }
+
+// HTTPResponse returns the HTTP response received from the service.
+func (e *NotModifiedError) HTTPResponse() *Response { return e.Response }
+
+func (e *NotModifiedError) Error() string {
+	return fmt.Sprintf("http response not modified, StatusCode: %d", e.Response.StatusCode)
+}
+
+// AddNotModifiedMiddleware adds the NotModified middleware to the stack,
+// between the OperationDeserializer and the transport.
+//
+// The middleware records the validators of every response in the operation
+// metadata, see GetResponseValidators. A 304 Not Modified response is
+// returned as a NotModifiedError, such that the operation deserializer does
+// not attempt to deserialize its absent payload.
+//
+// Other 3xx responses are passed to the operation deserializer. Redirects are
+// followed by the HTTP client, e.g. http.Client, if it is configured to.
+func AddNotModifiedMiddleware(stack *middleware.Stack) error {
+	return stack.Deserialize.Insert(&notModifiedMiddleware{}, "OperationDeserializer", middleware.After)
+}
+
+type notModifiedMiddleware struct{}
+
+func (*notModifiedMiddleware) ID() string {
+	return "NotModified"
+}
+
+func (m *notModifiedMiddleware) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type: %T", out.RawResponse)
+	}
+
+	validators, ok := getResponseValidators(resp.Header)
+	if ok {
+		setResponseValidators(&metadata, validators)
+	}
+
+	if resp.StatusCode != http.StatusNotModified {
+		return out, metadata, nil
+	}
+
+	// A 304 response cannot have a body, but consume any that was sent to
+	// allow the connection to be reused.
+	if resp.Body != nil {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	return out, metadata, &NotModifiedError{
+		Response:   resp,
+		Validators: validators,
+	}
+}
+
+func getResponseValidators(header http.Header) (v ResponseValidators, ok bool) {
+	v.ETag = header.Get("ETag")
+	if lm := header.Get("Last-Modified"); len(lm) != 0 {
+		v.LastModified, _ = ParseTime(lm)
+	}
+	return v, len(v.ETag) != 0 || !v.LastModified.IsZero()
+}
+
+// WithIfNoneMatch returns a stack mutator that sets the If-None-Match header
+// of the request to etag, as previously received in the ETag header of a
+// response. It is typically used with AddNotModifiedMiddleware.
+func WithIfNoneMatch(etag string) func(*middleware.Stack) error {
+	return SetHeaderValue("If-None-Match", etag)
+}
+
+// WithIfModifiedSince returns a stack mutator that sets the If-Modified-Since
+// header of the request to t, as previously received in the Last-Modified
+// header of a response. It is typically used with AddNotModifiedMiddleware.
+func WithIfModifiedSince(t time.Time) func(*middleware.Stack) error {
+	return SetHeaderValue("If-Modified-Since", smithytime.FormatHTTPDate(t))
+}
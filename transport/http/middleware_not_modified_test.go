@@ -0,0 +1,244 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// testOperationDeserializer mimics a generated operation deserializer, which
+// expects a payload for any response that is not an error.
+type testOperationDeserializer struct {
+	invoked bool
+}
+
+func (*testOperationDeserializer) ID() string { return "OperationDeserializer" }
+
+func (m *testOperationDeserializer) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	m.invoked = true
+	resp := out.RawResponse.(*Response)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return out, metadata, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return out, metadata, err
+	}
+	if len(body) == 0 {
+		return out, metadata, fmt.Errorf("expect payload")
+	}
+	out.Result = string(body)
+	return out, metadata, nil
+}
+
+func newNotModifiedTestServer() *httptest.Server {
+	const etag = `"v1"`
+	lastModified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/resource", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if t, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !lastModified.After(t) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("payload"))
+	})
+	for _, status := range []int{301, 302, 303, 307, 308} {
+		status := status
+		mux.HandleFunc(fmt.Sprintf("/redirect/%d", status), func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/resource", status)
+		})
+	}
+	mux.HandleFunc("/multiple-choices", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMultipleChoices)
+	})
+	return httptest.NewServer(mux)
+}
+
+func invokeNotModifiedTestStack(serverURL, path string, optFns ...func(*middleware.Stack) error) (
+	interface{}, middleware.Metadata, *testOperationDeserializer, error,
+) {
+	stack := middleware.NewStack("test", NewStackRequest)
+	stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize", func(
+		ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+	) (
+		out middleware.SerializeOutput, metadata middleware.Metadata, err error,
+	) {
+		req := in.Request.(*Request)
+		req.URL, _ = url.Parse(serverURL)
+		req.URL.Path = path
+		return next.HandleSerialize(ctx, in)
+	}), middleware.After)
+
+	deserializer := &testOperationDeserializer{}
+	stack.Deserialize.Add(deserializer, middleware.After)
+	if err := AddNotModifiedMiddleware(stack); err != nil {
+		return nil, middleware.Metadata{}, nil, err
+	}
+	for _, fn := range optFns {
+		if err := fn(stack); err != nil {
+			return nil, middleware.Metadata{}, nil, err
+		}
+	}
+
+	handler := middleware.DecorateHandler(NewClientHandler(http.DefaultClient), stack)
+	result, metadata, err := handler.Handle(context.Background(), struct{}{})
+	return result, metadata, deserializer, err
+}
+
+func TestNotModifiedMiddleware(t *testing.T) {
+	server := newNotModifiedTestServer()
+	defer server.Close()
+
+	lastModified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := map[string]struct {
+		path         string
+		optFns       []func(*middleware.Stack) error
+		expectResult interface{}
+		notModified  bool
+		err          string
+	}{
+		"unconditional": {
+			path:         "/resource",
+			expectResult: "payload",
+		},
+		"if none match, modified": {
+			path:         "/resource",
+			optFns:       []func(*middleware.Stack) error{WithIfNoneMatch(`"v0"`)},
+			expectResult: "payload",
+		},
+		"if none match, not modified": {
+			path:        "/resource",
+			optFns:      []func(*middleware.Stack) error{WithIfNoneMatch(`"v1"`)},
+			notModified: true,
+		},
+		"if modified since, modified": {
+			path:         "/resource",
+			optFns:       []func(*middleware.Stack) error{WithIfModifiedSince(lastModified.Add(-time.Second))},
+			expectResult: "payload",
+		},
+		"if modified since, not modified": {
+			path:        "/resource",
+			optFns:      []func(*middleware.Stack) error{WithIfModifiedSince(lastModified)},
+			notModified: true,
+		},
+		"301 followed": {
+			path:         "/redirect/301",
+			expectResult: "payload",
+		},
+		"302 followed": {
+			path:         "/redirect/302",
+			expectResult: "payload",
+		},
+		"303 followed": {
+			path:         "/redirect/303",
+			expectResult: "payload",
+		},
+		"307 followed": {
+			path:         "/redirect/307",
+			expectResult: "payload",
+		},
+		"308 followed": {
+			path:         "/redirect/308",
+			expectResult: "payload",
+		},
+		"redirect, not modified": {
+			path:        "/redirect/307",
+			optFns:      []func(*middleware.Stack) error{WithIfNoneMatch(`"v1"`)},
+			notModified: true,
+		},
+		"300 passed to deserializer": {
+			path: "/multiple-choices",
+			err:  "unexpected status 300",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			result, metadata, deserializer, err := invokeNotModifiedTestStack(server.URL, c.path, c.optFns...)
+
+			if c.notModified {
+				var nmErr *NotModifiedError
+				if !errors.As(err, &nmErr) {
+					t.Fatalf("expect NotModifiedError, got %v", err)
+				}
+				if e, a := http.StatusNotModified, nmErr.HTTPStatusCode(); e != a {
+					t.Errorf("expect status %v, got %v", e, a)
+				}
+				if e, a := `"v1"`, nmErr.Validators.ETag; e != a {
+					t.Errorf("expect ETag %v, got %v", e, a)
+				}
+				if deserializer.invoked {
+					t.Errorf("expect operation deserializer not to be invoked")
+				}
+			} else if len(c.err) != 0 {
+				if err == nil || err.Error() != c.err {
+					t.Fatalf("expect error %q, got %v", c.err, err)
+				}
+				return
+			} else {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if e, a := c.expectResult, result; e != a {
+					t.Errorf("expect result %v, got %v", e, a)
+				}
+			}
+
+			validators, ok := GetResponseValidators(metadata)
+			if !ok {
+				t.Fatalf("expect response validators")
+			}
+			expect := ResponseValidators{ETag: `"v1"`, LastModified: lastModified}
+			if e, a := expect, validators; e != a {
+				t.Errorf("expect validators %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestNotModifiedMiddleware_NoValidators(t *testing.T) {
+	var m notModifiedMiddleware
+	_, metadata, err := m.HandleDeserialize(context.Background(), middleware.DeserializeInput{},
+		middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+			out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+		) {
+			out.RawResponse = &Response{Response: &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       http.NoBody,
+			}}
+			return out, metadata, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if _, ok := GetResponseValidators(metadata); ok {
+		t.Errorf("expect no response validators")
+	}
+}
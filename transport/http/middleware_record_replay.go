@@ -0,0 +1,401 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Exchange is a request and the raw response it received, as recorded by the
+// record middleware, see AddRecordMiddleware.
+type Exchange struct {
+	// The key of the request, see ExchangeKey.
+	Key string
+
+	// The method, URL, header, and body of the request as it was sent.
+	Method        string
+	URL           string
+	RequestHeader http.Header
+	RequestBody   []byte
+
+	// The status code, header, and body of the response.
+	StatusCode     int
+	ResponseHeader http.Header
+	ResponseBody   []byte
+}
+
+// ExchangeStore stores recorded exchanges by the key of their request. Must
+// be safe for concurrent use.
+type ExchangeStore interface {
+	// Put stores the exchange by its key, replacing any exchange of the key.
+	Put(ctx context.Context, exchange *Exchange) error
+
+	// Get returns the exchange of the key, or false if there is none.
+	Get(ctx context.Context, key string) (*Exchange, bool, error)
+}
+
+// ExchangeKey returns the key of a request with the body, by which its
+// exchange is recorded and replayed. The key is the method, the URL with its
+// query keys sorted, and the SHA-256 digest of the body. Headers are not part
+// of the key, as they commonly vary between requests, e.g. by signature.
+func ExchangeKey(req *Request, body []byte) string {
+	u := *req.URL
+	u.RawQuery = u.Query().Encode()
+	sum := sha256.Sum256(body)
+	return req.Method + " " + u.String() + " " + hex.EncodeToString(sum[:])
+}
+
+// DefaultRedactedHeaders are the request and response headers whose values
+// are redacted from recorded exchanges by default, as they carry
+// credentials, see RecordReplayOptions.RedactedHeaders.
+var DefaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"X-Amz-Security-Token",
+	"X-Api-Key",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// DefaultRedactedQueryParams are the request query parameters whose values
+// are redacted from the URL and key of recorded exchanges by default, as they
+// carry credentials, e.g. of a presigned request, see
+// RecordReplayOptions.RedactedQueryParams.
+var DefaultRedactedQueryParams = []string{
+	"X-Amz-Signature",
+	"X-Amz-Credential",
+	"X-Amz-Security-Token",
+}
+
+// RedactedHeaderValue replaces the values of redacted headers and query
+// parameters of recorded exchanges.
+const RedactedHeaderValue = "REDACTED"
+
+// RecordReplayOptions is the set of options that can be configured for the
+// record and replay middleware.
+type RecordReplayOptions struct {
+	// Returns the key of a request with the body. Defaults to ExchangeKey.
+	Key func(req *Request, body []byte) string
+
+	// For the replay middleware, sends requests without a recorded exchange
+	// with the transport, rather than failing them with a
+	// *ExchangeNotFoundError.
+	Passthrough bool
+
+	// For the record middleware, the headers of requests and responses whose
+	// values are recorded as RedactedHeaderValue, as they may carry
+	// credentials, e.g. a signature or bearer token. Defaults to
+	// DefaultRedactedHeaders. Set to an empty, non-nil slice to record all
+	// headers as they were sent and received.
+	RedactedHeaders []string
+
+	// For the record and replay middleware, the query parameters of requests
+	// whose values are recorded as RedactedHeaderValue, in both the URL and
+	// the key of the exchange, as they may carry credentials. Defaults to
+	// DefaultRedactedQueryParams. Set to an empty, non-nil slice to record
+	// and key all query parameters as they were sent.
+	RedactedQueryParams []string
+}
+
+// ExchangeNotFoundError is the error of a request replayed without a
+// recorded exchange.
+type ExchangeNotFoundError struct {
+	Key string
+}
+
+func (e *ExchangeNotFoundError) Error() string {
+	return fmt.Sprintf("no recorded exchange of request %s", e.Key)
+}
+
+// AddRecordMiddleware adds a deserialize middleware that records each request
+// sent and the raw response it received to the store, e.g. to replay them in
+// tests without access to the service, see AddReplayMiddleware.
+//
+// The middleware is added at the end of the deserialize step, such that the
+// request is recorded as sent, and the response as received. Request and
+// response bodies are buffered in memory. The body of a request whose stream
+// is not seekable is not recorded. The values of headers and query
+// parameters that carry credentials are redacted, see
+// RecordReplayOptions.RedactedHeaders and RedactedQueryParams.
+func AddRecordMiddleware(stack *middleware.Stack, store ExchangeStore, optFns ...func(*RecordReplayOptions)) error {
+	return stack.Deserialize.Add(&recordMiddleware{
+		store:   store,
+		options: resolveRecordReplayOptions(optFns),
+	}, middleware.After)
+}
+
+// AddReplayMiddleware adds a deserialize middleware that returns the response
+// of the recorded exchange of each request from the store, rather than
+// sending the request, see AddRecordMiddleware. A request without a recorded
+// exchange fails with a *ExchangeNotFoundError, unless Passthrough is set.
+//
+// The middleware is added at the end of the deserialize step, such that the
+// middleware of the stack handle replayed responses as they would responses
+// received.
+func AddReplayMiddleware(stack *middleware.Stack, store ExchangeStore, optFns ...func(*RecordReplayOptions)) error {
+	return stack.Deserialize.Add(&replayMiddleware{
+		store:   store,
+		options: resolveRecordReplayOptions(optFns),
+	}, middleware.After)
+}
+
+func resolveRecordReplayOptions(optFns []func(*RecordReplayOptions)) RecordReplayOptions {
+	var o RecordReplayOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	if o.Key == nil {
+		o.Key = ExchangeKey
+	}
+	if o.RedactedHeaders == nil {
+		o.RedactedHeaders = DefaultRedactedHeaders
+	}
+	if o.RedactedQueryParams == nil {
+		o.RedactedQueryParams = DefaultRedactedQueryParams
+	}
+	return o
+}
+
+// redactQuery returns the request with the values of the redacted query
+// parameters of its URL replaced, as a copy if any are present, such that
+// the request sent is not modified.
+func (o RecordReplayOptions) redactQuery(req *Request) *Request {
+	query := req.URL.Query()
+	redacted := false
+	for _, name := range o.RedactedQueryParams {
+		if query.Has(name) {
+			query.Set(name, RedactedHeaderValue)
+			redacted = true
+		}
+	}
+	if !redacted {
+		return req
+	}
+
+	req = req.Clone()
+	u := *req.URL
+	u.RawQuery = query.Encode()
+	req.URL = &u
+	return req
+}
+
+type recordMiddleware struct {
+	store   ExchangeStore
+	options RecordReplayOptions
+}
+
+// ID is the middleware identifier.
+func (*recordMiddleware) ID() string {
+	return "RecordExchange"
+}
+
+func (m *recordMiddleware) HandleDeserialize(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	body, err := readRequestBody(req)
+	if err != nil {
+		return out, metadata, err
+	}
+	recorded := m.options.redactQuery(req)
+	exchange := &Exchange{
+		Key:           m.options.Key(recorded, body),
+		Method:        req.Method,
+		URL:           recorded.URL.String(),
+		RequestHeader: m.redact(req.Header),
+		RequestBody:   body,
+	}
+
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown response type %T", out.RawResponse)
+	}
+
+	if resp.Body != nil {
+		exchange.ResponseBody, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return out, metadata, fmt.Errorf("read response body: %w", err)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(exchange.ResponseBody))
+	}
+	exchange.StatusCode = resp.StatusCode
+	exchange.ResponseHeader = m.redact(resp.Header)
+
+	if err := m.store.Put(ctx, exchange); err != nil {
+		return out, metadata, fmt.Errorf("record exchange: %w", err)
+	}
+	return out, metadata, nil
+}
+
+// redact returns a copy of the header with the values of the redacted headers
+// replaced.
+func (m *recordMiddleware) redact(header http.Header) http.Header {
+	header = header.Clone()
+	for _, name := range m.options.RedactedHeaders {
+		if len(header.Values(name)) != 0 {
+			header.Set(name, RedactedHeaderValue)
+		}
+	}
+	return header
+}
+
+type replayMiddleware struct {
+	store   ExchangeStore
+	options RecordReplayOptions
+}
+
+// ID is the middleware identifier.
+func (*replayMiddleware) ID() string {
+	return "ReplayExchange"
+}
+
+func (m *replayMiddleware) HandleDeserialize(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", in.Request)
+	}
+
+	body, err := readRequestBody(req)
+	if err != nil {
+		return out, metadata, err
+	}
+	key := m.options.Key(m.options.redactQuery(req), body)
+
+	exchange, ok, err := m.store.Get(ctx, key)
+	if err != nil {
+		return out, metadata, fmt.Errorf("get recorded exchange: %w", err)
+	}
+	if !ok {
+		if m.options.Passthrough {
+			return next.HandleDeserialize(ctx, in)
+		}
+		return out, metadata, &ExchangeNotFoundError{Key: key}
+	}
+
+	header := exchange.ResponseHeader.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	out.RawResponse = &Response{Response: &http.Response{
+		Status:        strconv.Itoa(exchange.StatusCode) + " " + http.StatusText(exchange.StatusCode),
+		StatusCode:    exchange.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(exchange.ResponseBody)),
+		ContentLength: int64(len(exchange.ResponseBody)),
+		Request:       req.Build(ctx),
+	}}
+	return out, metadata, nil
+}
+
+// readRequestBody returns the body of the request, rewinding its stream, or
+// nil if the request has no body or its stream is not seekable.
+func readRequestBody(req *Request) ([]byte, error) {
+	stream := req.GetStream()
+	if stream == nil || !req.IsStreamSeekable() {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+	if err := req.RewindStream(); err != nil {
+		return nil, fmt.Errorf("rewind request body: %w", err)
+	}
+	return body, nil
+}
+
+// MemoryExchangeStore is an ExchangeStore of exchanges in memory.
+type MemoryExchangeStore struct {
+	mu        sync.Mutex
+	exchanges map[string]*Exchange
+}
+
+// NewMemoryExchangeStore returns an empty MemoryExchangeStore.
+func NewMemoryExchangeStore() *MemoryExchangeStore {
+	return &MemoryExchangeStore{exchanges: map[string]*Exchange{}}
+}
+
+// Put stores the exchange by its key.
+func (s *MemoryExchangeStore) Put(ctx context.Context, exchange *Exchange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exchanges[exchange.Key] = exchange
+	return nil
+}
+
+// Get returns the exchange of the key, or false if there is none.
+func (s *MemoryExchangeStore) Get(ctx context.Context, key string) (*Exchange, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exchange, ok := s.exchanges[key]
+	return exchange, ok, nil
+}
+
+// DirExchangeStore is an ExchangeStore of exchanges as JSON files in a
+// directory, e.g. the testdata of a package, named by the SHA-256 digest of
+// their key.
+type DirExchangeStore struct {
+	Dir string
+}
+
+// Put writes the exchange to the file of its key.
+func (s DirExchangeStore) Put(ctx context.Context, exchange *Exchange) error {
+	b, err := json.MarshalIndent(exchange, "", "\t")
+	if err != nil {
+		return fmt.Errorf("marshal exchange: %w", err)
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path(exchange.Key), b, 0o644)
+}
+
+// Get reads the exchange of the key from its file, or returns false if the
+// file does not exist.
+func (s DirExchangeStore) Get(ctx context.Context, key string) (*Exchange, bool, error) {
+	b, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	var exchange Exchange
+	if err := json.Unmarshal(b, &exchange); err != nil {
+		return nil, false, fmt.Errorf("unmarshal exchange %s: %w", key, err)
+	}
+	return &exchange, true, nil
+}
+
+func (s DirExchangeStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.Dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,260 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRecordReplayMiddleware(t *testing.T) {
+	stores := map[string]func(t *testing.T) ExchangeStore{
+		"memory": func(t *testing.T) ExchangeStore { return NewMemoryExchangeStore() },
+		"dir":    func(t *testing.T) ExchangeStore { return DirExchangeStore{Dir: t.TempDir()} },
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+
+			newRequest := func(body string) *Request {
+				req := NewStackRequest().(*Request)
+				req.Method = http.MethodPut
+				req.URL.Scheme, req.URL.Host, req.URL.Path = "https", "example.com", "/foo"
+				req.URL.RawQuery = "b=2&a=1"
+				req.Header.Set("X-Amz-Date", "20250101T000000Z")
+				req, _ = req.SetStream(strings.NewReader(body))
+				return req
+			}
+
+			sent := 0
+			handler := middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+				middleware.DeserializeOutput, middleware.Metadata, error,
+			) {
+				sent++
+				b, _ := io.ReadAll(in.Request.(*Request).GetStream())
+				return middleware.DeserializeOutput{RawResponse: &Response{Response: &http.Response{
+					StatusCode: 201,
+					Header:     http.Header{"X-Amz-Request-Id": []string{"abc"}},
+					Body:       io.NopCloser(strings.NewReader("echo " + string(b))),
+				}}}, middleware.Metadata{}, nil
+			})
+
+			record := &recordMiddleware{store: store, options: resolveRecordReplayOptions(nil)}
+			out, _, err := record.HandleDeserialize(context.Background(),
+				middleware.DeserializeInput{Request: newRequest("hello")}, handler)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			// the recorded response body is still readable
+			if b, _ := io.ReadAll(out.RawResponse.(*Response).Body); string(b) != "echo hello" {
+				t.Errorf("expect recorded response body, got %q", b)
+			}
+
+			replay := &replayMiddleware{store: store, options: resolveRecordReplayOptions(nil)}
+			req := newRequest("hello")
+			req.URL.RawQuery = "a=1&b=2"
+			req.Header.Set("X-Amz-Date", "20250102T000000Z")
+			out, _, err = replay.HandleDeserialize(context.Background(), middleware.DeserializeInput{Request: req}, handler)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := 1, sent; e != a {
+				t.Errorf("expect %v requests sent, got %v", e, a)
+			}
+			resp := out.RawResponse.(*Response)
+			if e, a := 201, resp.StatusCode; e != a {
+				t.Errorf("expect status %v, got %v", e, a)
+			}
+			if e, a := "abc", resp.Header.Get("X-Amz-Request-Id"); e != a {
+				t.Errorf("expect header %v, got %v", e, a)
+			}
+			if b, _ := io.ReadAll(resp.Body); string(b) != "echo hello" {
+				t.Errorf("expect replayed response body, got %q", b)
+			}
+
+			// a request with a different body is not recorded
+			_, _, err = replay.HandleDeserialize(context.Background(),
+				middleware.DeserializeInput{Request: newRequest("other")}, handler)
+			var notFound *ExchangeNotFoundError
+			if !errors.As(err, &notFound) {
+				t.Fatalf("expect ExchangeNotFoundError, got %v", err)
+			}
+
+			replay.options.Passthrough = true
+			if _, _, err = replay.HandleDeserialize(context.Background(),
+				middleware.DeserializeInput{Request: newRequest("other")}, handler); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := 2, sent; e != a {
+				t.Errorf("expect %v requests sent, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestRecordMiddleware_RedactedHeaders(t *testing.T) {
+	cases := map[string]struct {
+		redacted       []string
+		expectRequest  http.Header
+		expectResponse http.Header
+	}{
+		"default": {
+			expectRequest: http.Header{
+				"Authorization":        {RedactedHeaderValue},
+				"X-Amz-Security-Token": {RedactedHeaderValue},
+				"X-Amz-Date":           {"20250101T000000Z"},
+			},
+			expectResponse: http.Header{
+				"Set-Cookie": {RedactedHeaderValue},
+			},
+		},
+		"custom": {
+			redacted: []string{"x-amz-date", "Set-Cookie"},
+			expectRequest: http.Header{
+				"Authorization":        {"Bearer token"},
+				"X-Amz-Security-Token": {"token"},
+				"X-Amz-Date":           {RedactedHeaderValue},
+			},
+			expectResponse: http.Header{
+				"Set-Cookie": {RedactedHeaderValue},
+			},
+		},
+		"none": {
+			redacted: []string{},
+			expectRequest: http.Header{
+				"Authorization":        {"Bearer token"},
+				"X-Amz-Security-Token": {"token"},
+				"X-Amz-Date":           {"20250101T000000Z"},
+			},
+			expectResponse: http.Header{
+				"Set-Cookie": {"session=abc"},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			store := NewMemoryExchangeStore()
+			req := NewStackRequest().(*Request)
+			req.URL.Scheme, req.URL.Host = "https", "example.com"
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("X-Amz-Security-Token", "token")
+			req.Header.Set("X-Amz-Date", "20250101T000000Z")
+
+			var sent http.Header
+			handler := middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+				middleware.DeserializeOutput, middleware.Metadata, error,
+			) {
+				sent = in.Request.(*Request).Header
+				return middleware.DeserializeOutput{RawResponse: &Response{Response: &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{"Set-Cookie": {"session=abc"}},
+				}}}, middleware.Metadata{}, nil
+			})
+
+			record := &recordMiddleware{store: store, options: resolveRecordReplayOptions(
+				[]func(*RecordReplayOptions){func(o *RecordReplayOptions) { o.RedactedHeaders = c.redacted }})}
+			out, _, err := record.HandleDeserialize(context.Background(), middleware.DeserializeInput{Request: req}, handler)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			exchange, ok, err := store.Get(context.Background(), ExchangeKey(req, nil))
+			if err != nil || !ok {
+				t.Fatalf("expect recorded exchange, got %v, %v", ok, err)
+			}
+			if e, a := c.expectRequest, exchange.RequestHeader; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect request header %v, got %v", e, a)
+			}
+			if e, a := c.expectResponse, exchange.ResponseHeader; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect response header %v, got %v", e, a)
+			}
+
+			// the request sent and the response returned are not redacted
+			if e, a := "Bearer token", sent.Get("Authorization"); e != a {
+				t.Errorf("expect sent header %v, got %v", e, a)
+			}
+			if e, a := "session=abc", out.RawResponse.(*Response).Header.Get("Set-Cookie"); e != a {
+				t.Errorf("expect returned header %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestRecordReplayMiddleware_RedactedQueryParams(t *testing.T) {
+	cases := map[string]struct {
+		redacted  []string
+		expectURL string
+	}{
+		"default": {
+			expectURL: "https://example.com/foo?X-Amz-Credential=REDACTED&X-Amz-Signature=REDACTED&a=1",
+		},
+		"none": {
+			redacted:  []string{},
+			expectURL: "https://example.com/foo?X-Amz-Signature=sig1&X-Amz-Credential=cred&a=1",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			store := NewMemoryExchangeStore()
+			newRequest := func(signature string) *Request {
+				req := NewStackRequest().(*Request)
+				req.URL.Scheme, req.URL.Host, req.URL.Path = "https", "example.com", "/foo"
+				req.URL.RawQuery = "X-Amz-Signature=" + signature + "&X-Amz-Credential=cred&a=1"
+				return req
+			}
+
+			var sent string
+			handler := middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+				middleware.DeserializeOutput, middleware.Metadata, error,
+			) {
+				sent = in.Request.(*Request).URL.RawQuery
+				return middleware.DeserializeOutput{RawResponse: &Response{Response: &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{},
+				}}}, middleware.Metadata{}, nil
+			})
+
+			options := resolveRecordReplayOptions(
+				[]func(*RecordReplayOptions){func(o *RecordReplayOptions) { o.RedactedQueryParams = c.redacted }})
+			record := &recordMiddleware{store: store, options: options}
+			if _, _, err := record.HandleDeserialize(context.Background(),
+				middleware.DeserializeInput{Request: newRequest("sig1")}, handler); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			// the request sent is not redacted
+			if e, a := "X-Amz-Signature=sig1&X-Amz-Credential=cred&a=1", sent; e != a {
+				t.Errorf("expect sent query %v, got %v", e, a)
+			}
+
+			var exchange *Exchange
+			for _, v := range store.exchanges {
+				exchange = v
+			}
+			if e, a := c.expectURL, exchange.URL; e != a {
+				t.Errorf("expect recorded URL %v, got %v", e, a)
+			}
+			if c.redacted == nil && strings.Contains(exchange.Key, "sig1") {
+				t.Errorf("expect signature redacted from key, got %v", exchange.Key)
+			}
+
+			// a request of another signature replays the exchange only if the
+			// signature is redacted from its key
+			replay := &replayMiddleware{store: store, options: options}
+			_, _, err := replay.HandleDeserialize(context.Background(),
+				middleware.DeserializeInput{Request: newRequest("sig2")}, handler)
+			var notFound *ExchangeNotFoundError
+			if e, a := c.redacted != nil, errors.As(err, &notFound); e != a {
+				t.Errorf("expect not found %v, got %v", e, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ResponseBodyTooLargeError is returned when the body of a response is larger
+// than the maximum size allowed by the response body size limit middleware.
+type ResponseBodyTooLargeError struct {
+	// The maximum size of response bodies, in bytes.
+	MaxSize int64
+
+	// The Content-Length of the response, or -1 if the limit was exceeded
+	// as the body was read.
+	ContentLength int64
+}
+
+func (e *ResponseBodyTooLargeError) Error() string {
+	if e.ContentLength >= 0 {
+		return fmt.Sprintf("response body of %d bytes exceeds maximum size of %d bytes", e.ContentLength, e.MaxSize)
+	}
+	return fmt.Sprintf("response body exceeds maximum size of %d bytes", e.MaxSize)
+}
+
+// AddResponseBodySizeLimitMiddleware adds a deserialize middleware to the end
+// of the stack's Deserialize step that limits the size of response bodies to
+// maxSize bytes, such that an untrusted response cannot exhaust memory as it
+// is deserialized.
+//
+// A response whose Content-Length is larger than maxSize fails with a
+// *ResponseBodyTooLargeError without its body being read. Reading more than
+// maxSize bytes of any other body fails with a *ResponseBodyTooLargeError, and
+// closes the body. The limit applies to the body as received, before it is
+// decompressed by middleware such as that of
+// AddResponseDecompressionMiddleware.
+func AddResponseBodySizeLimitMiddleware(stack *middleware.Stack, maxSize int64) error {
+	if maxSize < 0 {
+		return fmt.Errorf("invalid maximum response body size %d, must be at least 0", maxSize)
+	}
+	return stack.Deserialize.Add(&responseBodySizeLimitMiddleware{maxSize: maxSize}, middleware.After)
+}
+
+type responseBodySizeLimitMiddleware struct {
+	maxSize int64
+}
+
+// ID is the middleware identifier.
+func (*responseBodySizeLimitMiddleware) ID() string {
+	return "ResponseBodySizeLimit"
+}
+
+// DescribeConfig describes the limit for a stack snapshot.
+func (m *responseBodySizeLimitMiddleware) DescribeConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"MaxSize": m.maxSize,
+	}
+}
+
+func (m *responseBodySizeLimitMiddleware) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+	}
+	if resp.Body == nil {
+		return out, metadata, nil
+	}
+
+	if resp.ContentLength > m.maxSize {
+		resp.Body.Close()
+		return out, metadata, &ResponseBodyTooLargeError{MaxSize: m.maxSize, ContentLength: resp.ContentLength}
+	}
+
+	resp.Body = &limitedResponseBody{ReadCloser: resp.Body, remaining: m.maxSize, maxSize: m.maxSize}
+	return out, metadata, nil
+}
+
+// limitedResponseBody is a response body that fails once more than its
+// maximum size is read.
+type limitedResponseBody struct {
+	io.ReadCloser
+	remaining int64
+	maxSize   int64
+	err       error
+}
+
+func (b *limitedResponseBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	// read one byte past the limit, to tell a body of exactly the maximum
+	// size from one larger than it
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		b.err = &ResponseBodyTooLargeError{MaxSize: b.maxSize, ContentLength: -1}
+		b.ReadCloser.Close()
+		return n, b.err
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
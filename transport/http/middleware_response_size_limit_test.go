@@ -0,0 +1,104 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestResponseBodySizeLimitMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		body          string
+		contentLength int64
+		expectErr     bool
+		expectReadErr bool
+	}{
+		"within limit": {
+			body:          "hello",
+			contentLength: -1,
+		},
+		"exactly limit": {
+			body:          "hello worl",
+			contentLength: 10,
+		},
+		"content length exceeds limit": {
+			body:          "hello world",
+			contentLength: 11,
+			expectErr:     true,
+		},
+		"body exceeds limit": {
+			body:          strings.Repeat("hello world", 1000),
+			contentLength: -1,
+			expectReadErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("test", NewStackRequest)
+			var body []byte
+			var readErr error
+			stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("OperationDeserializer", func(
+				ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+			) (middleware.DeserializeOutput, middleware.Metadata, error) {
+				out, metadata, err := next.HandleDeserialize(ctx, in)
+				if err != nil {
+					return out, metadata, err
+				}
+				body, readErr = ioutil.ReadAll(out.RawResponse.(*Response).Body)
+				return out, metadata, err
+			}), middleware.After)
+			if err := AddResponseBodySizeLimitMiddleware(stack, 10); err != nil {
+				t.Fatal(err)
+			}
+
+			client := ClientDoFunc(func(r *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode:    200,
+					Header:        http.Header{},
+					Body:          ioutil.NopCloser(bytes.NewReader([]byte(c.body))),
+					ContentLength: c.contentLength,
+				}, nil
+			})
+
+			_, _, err := middleware.DecorateHandler(NewClientHandler(client), stack).
+				Handle(context.Background(), struct{}{})
+
+			var terr *ResponseBodyTooLargeError
+			if c.expectErr {
+				if !errors.As(err, &terr) {
+					t.Fatalf("expect response body too large error, got %v", err)
+				}
+				if e, a := c.contentLength, terr.ContentLength; e != a {
+					t.Errorf("expect content length %v, got %v", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if c.expectReadErr {
+				if !errors.As(readErr, &terr) {
+					t.Fatalf("expect response body too large error, got %v", readErr)
+				}
+				if e, a := 10, len(body); e != a {
+					t.Errorf("expect %v bytes read, got %v", e, a)
+				}
+				return
+			}
+			if readErr != nil {
+				t.Fatalf("expect no read error, got %v", readErr)
+			}
+			if e, a := c.body, string(body); e != a {
+				t.Errorf("expect body %q, got %q", e, a)
+			}
+		})
+	}
+}
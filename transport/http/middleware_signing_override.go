@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+type signingOverridesKey struct{}
+
+// WithSigningName instruments a middleware stack to sign the request with the
+// given signing name rather than the one resolved for the operation, e.g. for
+// an operation routed through a different service.
+//
+// The override is applied to the signer properties of the selected auth
+// option by the generated signing middleware, see ApplySigningOverrides.
+func WithSigningName(name string) func(*middleware.Stack) error {
+	return func(s *middleware.Stack) error {
+		m, err := getOrAddSigningOverride(s)
+		if err != nil {
+			return fmt.Errorf("get or add signing override: %v", err)
+		}
+
+		SetSigningNameOverride(&m.overrides, name)
+		return nil
+	}
+}
+
+// WithSigningRegion instruments a middleware stack to sign the request for
+// the given region rather than the one resolved for the operation, e.g. for a
+// cross-region copy that must be signed for the source region.
+//
+// For SigV4A the override replaces the signing region set with the single
+// region given. The override is applied to the signer properties of the
+// selected auth option by the generated signing middleware, see
+// ApplySigningOverrides.
+func WithSigningRegion(region string) func(*middleware.Stack) error {
+	return func(s *middleware.Stack) error {
+		m, err := getOrAddSigningOverride(s)
+		if err != nil {
+			return fmt.Errorf("get or add signing override: %v", err)
+		}
+
+		SetSigningRegionOverride(&m.overrides, region)
+		return nil
+	}
+}
+
+// ApplySigningOverrides applies any signing name and region overrides set on
+// the stack with WithSigningName and WithSigningRegion to the given signer
+// properties, replacing the SigV4 and SigV4A signing name and region.
+//
+// The generated signing middleware calls this with a copy of the signer
+// properties of the auth option selected for the operation, immediately
+// before invoking the Signer, such that the resolved auth scheme is not
+// modified.
+func ApplySigningOverrides(ctx context.Context, signerProps *smithy.Properties) {
+	overrides, ok := middleware.GetStackValue(ctx, signingOverridesKey{}).(*smithy.Properties)
+	if !ok {
+		return
+	}
+
+	if name, ok := GetSigningNameOverride(overrides); ok {
+		SetSigV4SigningName(signerProps, name)
+		SetSigV4ASigningName(signerProps, name)
+	}
+	if region, ok := GetSigningRegionOverride(overrides); ok {
+		SetSigV4SigningRegion(signerProps, region)
+		SetSigV4ASigningRegions(signerProps, []string{region})
+	}
+}
+
+type signingOverrideMiddleware struct {
+	overrides smithy.Properties
+}
+
+func (*signingOverrideMiddleware) ID() string {
+	return "SigningOverride"
+}
+
+func (m *signingOverrideMiddleware) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	ctx = middleware.WithStackValue(ctx, signingOverridesKey{}, &m.overrides)
+	return next.HandleInitialize(ctx, in)
+}
+
+func getOrAddSigningOverride(s *middleware.Stack) (*signingOverrideMiddleware, error) {
+	id := (*signingOverrideMiddleware)(nil).ID()
+	m, ok := s.Initialize.Get(id)
+	if !ok {
+		m := &signingOverrideMiddleware{}
+		if err := s.Initialize.Add(m, middleware.Before); err != nil {
+			return nil, fmt.Errorf("add initialize: %v", err)
+		}
+
+		return m, nil
+	}
+
+	so, ok := m.(*signingOverrideMiddleware)
+	if !ok {
+		return nil, fmt.Errorf("existing middleware w/ id %s is not *signingOverrideMiddleware", id)
+	}
+
+	return so, nil
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// injectSigner adds a finalize middleware that applies signing overrides to
+// props as signer middleware would.
+func injectSigner(s *middleware.Stack, props *smithy.Properties) {
+	s.Finalize.Add(
+		middleware.FinalizeMiddlewareFunc(
+			"injectSigner",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+				middleware.FinalizeOutput, middleware.Metadata, error,
+			) {
+				ApplySigningOverrides(ctx, props)
+				return next.HandleFinalize(ctx, in)
+			},
+		),
+		middleware.After,
+	)
+}
+
+func TestApplySigningOverrides(t *testing.T) {
+	cases := map[string]struct {
+		Fns           []func(*middleware.Stack) error
+		ExpectName    string
+		ExpectRegion  string
+		ExpectRegions []string
+	}{
+		"no overrides": {
+			ExpectName:    "s3",
+			ExpectRegion:  "us-west-2",
+			ExpectRegions: []string{"*"},
+		},
+		"name": {
+			Fns:           []func(*middleware.Stack) error{WithSigningName("s3-outposts")},
+			ExpectName:    "s3-outposts",
+			ExpectRegion:  "us-west-2",
+			ExpectRegions: []string{"*"},
+		},
+		"region": {
+			Fns:           []func(*middleware.Stack) error{WithSigningRegion("eu-central-1")},
+			ExpectName:    "s3",
+			ExpectRegion:  "eu-central-1",
+			ExpectRegions: []string{"eu-central-1"},
+		},
+		"both, last wins": {
+			Fns: []func(*middleware.Stack) error{
+				WithSigningRegion("eu-central-1"),
+				WithSigningName("s3-outposts"),
+				WithSigningRegion("ap-south-1"),
+			},
+			ExpectName:    "s3-outposts",
+			ExpectRegion:  "ap-south-1",
+			ExpectRegions: []string{"ap-south-1"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack, err := newTestStack(c.Fns...)
+			if err != nil {
+				t.Fatalf("expected no error on new stack, got %v", err)
+			}
+
+			var props smithy.Properties
+			SetSigV4SigningName(&props, "s3")
+			SetSigV4ASigningName(&props, "s3")
+			SetSigV4SigningRegion(&props, "us-west-2")
+			SetSigV4ASigningRegions(&props, []string{"*"})
+			injectSigner(stack, &props)
+
+			if err := handle(stack); err != nil {
+				t.Fatalf("expected no error on handle, got %v", err)
+			}
+
+			if v, _ := GetSigV4SigningName(&props); c.ExpectName != v {
+				t.Errorf("expect signing name %v, got %v", c.ExpectName, v)
+			}
+			if v, _ := GetSigV4ASigningName(&props); c.ExpectName != v {
+				t.Errorf("expect v4a signing name %v, got %v", c.ExpectName, v)
+			}
+			if v, _ := GetSigV4SigningRegion(&props); c.ExpectRegion != v {
+				t.Errorf("expect signing region %v, got %v", c.ExpectRegion, v)
+			}
+			if v, _ := GetSigV4ASigningRegions(&props); !reflect.DeepEqual(c.ExpectRegions, v) {
+				t.Errorf("expect v4a signing regions %v, got %v", c.ExpectRegions, v)
+			}
+		})
+	}
+}
@@ -11,6 +11,9 @@ type (
 
 	isUnsignedPayloadKey     struct{}
 	disableDoubleEncodingKey struct{}
+
+	signingNameOverrideKey   struct{}
+	signingRegionOverrideKey struct{}
 )
 
 // GetSigV4SigningName gets the signing name from Properties.
@@ -78,3 +81,29 @@ func GetDisableDoubleEncoding(p *smithy.Properties) (bool, bool) {
 func SetDisableDoubleEncoding(p *smithy.Properties, disableDoubleEncoding bool) {
 	p.Set(disableDoubleEncodingKey{}, disableDoubleEncoding)
 }
+
+// GetSigningNameOverride gets the per-operation signing name override from
+// Properties.
+func GetSigningNameOverride(p *smithy.Properties) (string, bool) {
+	v, ok := p.Get(signingNameOverrideKey{}).(string)
+	return v, ok
+}
+
+// SetSigningNameOverride sets the per-operation signing name override on
+// Properties.
+func SetSigningNameOverride(p *smithy.Properties, name string) {
+	p.Set(signingNameOverrideKey{}, name)
+}
+
+// GetSigningRegionOverride gets the per-operation signing region override
+// from Properties.
+func GetSigningRegionOverride(p *smithy.Properties) (string, bool) {
+	v, ok := p.Get(signingRegionOverrideKey{}).(string)
+	return v, ok
+}
+
+// SetSigningRegionOverride sets the per-operation signing region override on
+// Properties.
+func SetSigningRegionOverride(p *smithy.Properties, region string) {
+	p.Set(signingRegionOverrideKey{}, region)
+}
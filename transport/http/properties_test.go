@@ -71,4 +71,26 @@ func TestDisableDoubleEncoding(t *testing.T) {
 	if expected != actual {
 		t.Errorf("Expect DisableDoubleEncoding to be equivalent %v != %v", expected, actual)
 	}
-}
\ No newline at end of file
+}
+
+func TestSigningNameOverride(t *testing.T) {
+	expected := "foo"
+	var m smithy.Properties
+	SetSigningNameOverride(&m, expected)
+	actual, _ := GetSigningNameOverride(&m)
+
+	if expected != actual {
+		t.Errorf("Expect SigningNameOverride to be equivalent %s != %s", expected, actual)
+	}
+}
+
+func TestSigningRegionOverride(t *testing.T) {
+	expected := "foo"
+	var m smithy.Properties
+	SetSigningRegionOverride(&m, expected)
+	actual, _ := GetSigningRegionOverride(&m)
+
+	if expected != actual {
+		t.Errorf("Expect SigningRegionOverride to be equivalent %s != %s", expected, actual)
+	}
+}
@@ -165,6 +165,12 @@ func (r *Request) Build(ctx context.Context) *http.Request {
 		req.ContentLength = 0
 	}
 
+	// The Host header is sent as signers expect it, see HostForHeader, rather
+	// than as net/http would derive it from the URL.
+	if len(req.Host) == 0 && req.URL != nil {
+		req.Host = HostForHeader(req.URL.Scheme, req.URL.Host)
+	}
+
 	switch stream := r.stream.(type) {
 	case *io.PipeReader:
 		req.Body = ioutil.NopCloser(stream)
@@ -179,6 +185,17 @@ func (r *Request) Build(ctx context.Context) *http.Request {
 		}
 	}
 
+	// A stream that sets trailers once read, e.g. to send a checksum of its
+	// content, sets them on the request that is sent. Trailers are only sent
+	// with a chunked body, so the length of the stream, if known, is not.
+	if ts, ok := r.stream.(trailerStream); ok && req.Body != nil {
+		if req.Trailer == nil {
+			req.Trailer = http.Header{}
+		}
+		ts.bindTrailer(req.Trailer)
+		req.ContentLength = -1
+	}
+
 	return req
 }
 
@@ -5,7 +5,10 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -109,6 +112,64 @@ func TestRequestBuild_contentLength(t *testing.T) {
 	}
 }
 
+func TestRequestBuild_host(t *testing.T) {
+	cases := map[string]struct {
+		url        string
+		host       string
+		expectHost string
+	}{
+		"default port": {
+			url:        "http://example.com:80/",
+			expectHost: "example.com",
+		},
+		"non-default port": {
+			url:        "http://example.com:8080/",
+			expectHost: "example.com:8080",
+		},
+		"ipv6 zone": {
+			url:        "http://[fe80::1%25eth0]:8080/",
+			expectHost: "[fe80::1]:8080",
+		},
+		"host set": {
+			url:        "http://example.com:80/",
+			host:       "other.example.com",
+			expectHost: "other.example.com",
+		},
+	}
+
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Host
+	}))
+	defer server.Close()
+
+	// every request is sent to the server, whatever the host of its URL
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req.URL, _ = url.Parse(c.url)
+			req.Host = c.host
+
+			resp, err := client.Do(req.Build(context.Background()))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			resp.Body.Close()
+
+			if e, a := c.expectHost, received; e != a {
+				t.Errorf("expect host %q, got %q", e, a)
+			}
+		})
+	}
+}
+
 func TestRequestSetStream(t *testing.T) {
 	cases := map[string]struct {
 		reader                 io.Reader
@@ -219,3 +280,8 @@ func TestRequestSetStream(t *testing.T) {
 		})
 	}
 }
+
+// unknownLengthReader hides the length of the reader it wraps.
+type unknownLengthReader struct {
+	io.Reader
+}
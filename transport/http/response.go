@@ -2,7 +2,12 @@ package http
 
 import (
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Response provides the HTTP specific response structure for HTTP specific
@@ -11,6 +16,31 @@ type Response struct {
 	*http.Response
 }
 
+// OnRelease calls fn once the body of the response is closed, or immediately
+// if the response has no body. Implements middleware.ResponseReleaser, for
+// the deadline of a step timeout to be released with the response.
+func (r *Response) OnRelease(fn func()) {
+	if r.Response == nil || r.Body == nil || r.Body == http.NoBody {
+		fn()
+		return
+	}
+	r.Body = &releaseOnClose{ReadCloser: r.Body, release: fn}
+}
+
+// releaseOnClose is a response body that calls release the first time it is
+// closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
 // ResponseError provides the HTTP centric error type wrapping the underlying
 // error with the HTTP response value.
 type ResponseError struct {
@@ -27,6 +57,46 @@ func (e *ResponseError) HTTPResponse() *Response { return e.Response }
 // Unwrap returns the nested error if any, or nil.
 func (e *ResponseError) Unwrap() error { return e.Err }
 
+// RetryAfterHint returns the duration from the response's Retry-After header,
+// if it is present and valid.
+func (e *ResponseError) RetryAfterHint() (time.Duration, bool) {
+	if e.Response == nil || e.Response.Response == nil {
+		return 0, false
+	}
+	return ParseRetryAfter(e.Response.Header, time.Now())
+}
+
+// ParseRetryAfter parses the Retry-After header of a response, in either the
+// delay-seconds or HTTP-date form, returning false if it is absent or
+// invalid.
+//
+// An HTTP-date is resolved relative to the response's Date header if it is
+// present, or now otherwise, so that clock skew between the client and the
+// service does not affect the delay. A date in the past is a hint of 0.
+func ParseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	v := strings.TrimSpace(header.Get("Retry-After"))
+	if len(v) == 0 {
+		return 0, false
+	}
+
+	if secs, err := strconv.ParseUint(v, 10, 32); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	t, err := ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if date, err := ParseTime(header.Get("Date")); err == nil {
+		now = date
+	}
+
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
 func (e *ResponseError) Error() string {
 	return fmt.Sprintf(
 		"http response error StatusCode: %d, %v",
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		header     http.Header
+		expectOK   bool
+		expectWait time.Duration
+	}{
+		"absent": {
+			header: http.Header{},
+		},
+		"seconds": {
+			header:     http.Header{"Retry-After": []string{"120"}},
+			expectOK:   true,
+			expectWait: 2 * time.Minute,
+		},
+		"zero seconds": {
+			header:   http.Header{"Retry-After": []string{"0"}},
+			expectOK: true,
+		},
+		"negative seconds": {
+			header: http.Header{"Retry-After": []string{"-1"}},
+		},
+		"invalid": {
+			header: http.Header{"Retry-After": []string{"soon"}},
+		},
+		"date": {
+			header:     http.Header{"Retry-After": []string{"Wed, 21 Oct 2015 07:28:30 GMT"}},
+			expectOK:   true,
+			expectWait: 30 * time.Second,
+		},
+		"date relative to response date": {
+			header: http.Header{
+				"Retry-After": []string{"Wed, 21 Oct 2015 07:28:30 GMT"},
+				"Date":        []string{"Wed, 21 Oct 2015 07:28:20 GMT"},
+			},
+			expectOK:   true,
+			expectWait: 10 * time.Second,
+		},
+		"date in the past": {
+			header:   http.Header{"Retry-After": []string{"Wed, 21 Oct 2015 07:00:00 GMT"}},
+			expectOK: true,
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			wait, ok := ParseRetryAfter(tt.header, now)
+			if e, a := tt.expectOK, ok; e != a {
+				t.Fatalf("expect ok %v, got %v", e, a)
+			}
+			if e, a := tt.expectWait, wait; e != a {
+				t.Errorf("expect wait %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestResponseError_RetryAfterHint(t *testing.T) {
+	newErr := func(header http.Header, err error) error {
+		return &smithy.OperationError{
+			ServiceID:     "service",
+			OperationName: "operation",
+			Err: &ResponseError{
+				Response: &Response{Response: &http.Response{StatusCode: 503, Header: header}},
+				Err:      err,
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		err        error
+		expectOK   bool
+		expectWait time.Duration
+	}{
+		"no hint": {
+			err: newErr(http.Header{}, fmt.Errorf("unmodeled")),
+		},
+		"header": {
+			err:        newErr(http.Header{"Retry-After": []string{"3"}}, &smithy.GenericAPIError{RetryAfter: ptr.Duration(time.Second)}),
+			expectOK:   true,
+			expectWait: 3 * time.Second,
+		},
+		"modeled error field": {
+			err:        newErr(http.Header{}, &smithy.GenericAPIError{RetryAfter: ptr.Duration(time.Second)}),
+			expectOK:   true,
+			expectWait: time.Second,
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			wait, ok := smithy.GetRetryAfterHint(tt.err)
+			if e, a := tt.expectOK, ok; e != a {
+				t.Fatalf("expect ok %v, got %v", e, a)
+			}
+			if e, a := tt.expectWait, wait; e != a {
+				t.Errorf("expect wait %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+var _ middleware.ResponseReleaser = (*Response)(nil)
+
+func TestResponse_OnRelease(t *testing.T) {
+	cases := map[string]struct {
+		body          io.ReadCloser
+		expectOnClose bool
+	}{
+		"body": {
+			body:          io.NopCloser(strings.NewReader("hello")),
+			expectOnClose: true,
+		},
+		"no body": {
+			body: http.NoBody,
+		},
+		"nil body": {},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			resp := &Response{Response: &http.Response{Body: c.body}}
+
+			var released int
+			resp.OnRelease(func() { released++ })
+			if c.expectOnClose {
+				if e, a := 0, released; e != a {
+					t.Fatalf("expect released %v times before close, got %v", e, a)
+				}
+				if b, _ := io.ReadAll(resp.Body); string(b) != "hello" {
+					t.Errorf("expect body readable, got %q", b)
+				}
+				resp.Body.Close()
+				resp.Body.Close()
+			}
+			if e, a := 1, released; e != a {
+				t.Errorf("expect released %v times, got %v", e, a)
+			}
+		})
+	}
+}
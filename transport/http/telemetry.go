@@ -0,0 +1,191 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/metrics"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/aws/smithy-go/tracing"
+)
+
+// telemetryScope is the scope of the meters and tracers of this package.
+const telemetryScope = "github.com/aws/smithy-go/transport/http"
+
+// TelemetryOptions is the set of telemetry providers of a client, such that
+// its observability is enabled with a single option of its handler, see
+// NewClientHandlerWithOptions, and its stack, see AddTelemetryMiddleware and
+// AddHTTPTraceMiddleware. A nil provider records nothing of its kind.
+type TelemetryOptions struct {
+	// Provides the meter of the metrics recorded.
+	MeterProvider metrics.MeterProvider
+
+	// Provides the tracer of the spans started.
+	TracerProvider tracing.TracerProvider
+
+	// Provides the logger of each operation, scoped to the ID of its stack.
+	LoggerProvider logging.LoggerProvider
+
+	// Properties set on every span started and metric recorded, e.g. the
+	// name of the service.
+	Properties smithy.Properties
+}
+
+func (o *TelemetryOptions) meter() metrics.Meter {
+	if o.MeterProvider == nil {
+		return metrics.NopMeterProvider{}.Meter(telemetryScope)
+	}
+	return o.MeterProvider.Meter(telemetryScope)
+}
+
+func (o *TelemetryOptions) tracer() tracing.Tracer {
+	if o.TracerProvider == nil {
+		return tracing.NopTracerProvider{}.Tracer(telemetryScope)
+	}
+	return o.TracerProvider.Tracer(telemetryScope)
+}
+
+// properties returns the properties of the options with the key value pairs
+// set on them.
+func (o *TelemetryOptions) properties(kv ...interface{}) smithy.Properties {
+	var props smithy.Properties
+	props.SetAll(&o.Properties)
+	for i := 0; i+1 < len(kv); i += 2 {
+		props.Set(kv[i], kv[i+1])
+	}
+	return props
+}
+
+// requestTelemetry records the telemetry of the requests sent by a
+// ClientHandler.
+type requestTelemetry struct {
+	options  TelemetryOptions
+	tracer   tracing.Tracer
+	duration metrics.Float64Histogram
+	errors   metrics.Int64Counter
+}
+
+func newRequestTelemetry(o TelemetryOptions) (*requestTelemetry, error) {
+	meter := o.meter()
+	duration, err := meter.Float64Histogram("client.http.request_duration",
+		metrics.WithUnit("s"),
+		metrics.WithDescription("The time to send a request and receive the headers of its response"))
+	if err != nil {
+		return nil, fmt.Errorf("create request duration histogram, %w", err)
+	}
+	errors, err := meter.Int64Counter("client.http.request_errors",
+		metrics.WithDescription("The number of requests that failed to be sent"))
+	if err != nil {
+		return nil, fmt.Errorf("create request errors counter, %w", err)
+	}
+
+	return &requestTelemetry{
+		options:  o,
+		tracer:   o.tracer(),
+		duration: duration,
+		errors:   errors,
+	}, nil
+}
+
+// start starts the span of the request, and returns the context of the span,
+// and a func to be called with the result of the request.
+func (t *requestTelemetry) start(ctx context.Context, req *Request) (
+	context.Context, func(out interface{}, err error),
+) {
+	props := t.options.properties("http.method", req.Method)
+	ctx, span := t.tracer.StartSpan(ctx, "HTTP "+req.Method,
+		tracing.WithSpanKind(tracing.SpanKindClient),
+		tracing.WithSpanProperties(props))
+
+	start := time.Now()
+	return ctx, func(out interface{}, err error) {
+		defer span.End()
+
+		if resp, ok := out.(*Response); ok && resp.Response != nil && resp.StatusCode != 0 {
+			props.Set("http.status_code", resp.StatusCode)
+			span.SetProperty("http.status_code", resp.StatusCode)
+		}
+		t.duration.Record(ctx, time.Since(start).Seconds(), metrics.WithProperties(props))
+		if err != nil {
+			t.errors.Add(ctx, 1, metrics.WithProperties(props))
+			span.SetStatus(tracing.SpanStatusError)
+		} else {
+			span.SetStatus(tracing.SpanStatusOK)
+		}
+	}
+}
+
+// AddTelemetryMiddleware adds a middleware to the end of the Initialize step
+// of the stack that, for each operation invocation:
+//
+//   - sets the logger of the LoggerProvider, scoped to the ID of the stack,
+//     see middleware.GetLogger
+//   - starts a span of the TracerProvider named with the ID of the stack
+//   - records the duration, and errors, of the invocation with the meter of
+//     the MeterProvider
+//
+// The logger replaces any set by middleware before it, e.g. one added with
+// middleware.AddSetLoggerMiddleware.
+func AddTelemetryMiddleware(stack *middleware.Stack, o TelemetryOptions) error {
+	meter := o.meter()
+	duration, err := meter.Float64Histogram("client.call.duration",
+		metrics.WithUnit("s"),
+		metrics.WithDescription("The time of an operation invocation, including all of its attempts"))
+	if err != nil {
+		return fmt.Errorf("create call duration histogram, %w", err)
+	}
+	errors, err := meter.Int64Counter("client.call.errors",
+		metrics.WithDescription("The number of operation invocations that failed"))
+	if err != nil {
+		return fmt.Errorf("create call errors counter, %w", err)
+	}
+
+	return stack.Initialize.Add(&telemetryMiddleware{
+		operation: stack.ID(),
+		options:   o,
+		tracer:    o.tracer(),
+		duration:  duration,
+		errors:    errors,
+	}, middleware.After)
+}
+
+type telemetryMiddleware struct {
+	operation string
+	options   TelemetryOptions
+	tracer    tracing.Tracer
+	duration  metrics.Float64Histogram
+	errors    metrics.Int64Counter
+}
+
+func (*telemetryMiddleware) ID() string {
+	return "Telemetry"
+}
+
+func (m *telemetryMiddleware) HandleInitialize(
+	ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+) (
+	out middleware.InitializeOutput, metadata middleware.Metadata, err error,
+) {
+	if m.options.LoggerProvider != nil {
+		ctx = middleware.SetLogger(ctx, m.options.LoggerProvider.Logger(m.operation))
+	}
+
+	props := m.options.properties("rpc.method", m.operation)
+	ctx, span := m.tracer.StartSpan(ctx, m.operation, tracing.WithSpanProperties(props))
+	defer span.End()
+
+	start := time.Now()
+	out, metadata, err = next.HandleInitialize(ctx, in)
+
+	m.duration.Record(ctx, time.Since(start).Seconds(), metrics.WithProperties(props))
+	if err != nil {
+		m.errors.Add(ctx, 1, metrics.WithProperties(props))
+		span.SetStatus(tracing.SpanStatusError)
+	} else {
+		span.SetStatus(tracing.SpanStatusOK)
+	}
+	return out, metadata, err
+}
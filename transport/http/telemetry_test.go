@@ -0,0 +1,253 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/metrics"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/aws/smithy-go/tracing"
+)
+
+type recordedMetric struct {
+	name  string
+	value float64
+	props smithy.Properties
+}
+
+// testTelemetry records the spans and metrics of its providers.
+type testTelemetry struct {
+	mu      sync.Mutex
+	spans   []*testSpan
+	metrics []recordedMetric
+}
+
+func (t *testTelemetry) Meter(string, ...metrics.MeterOption) metrics.Meter { return t }
+
+func (t *testTelemetry) Int64Counter(name string, _ ...metrics.InstrumentOption) (metrics.Int64Counter, error) {
+	return &testInstrument{t: t, name: name}, nil
+}
+
+func (t *testTelemetry) Float64Histogram(name string, _ ...metrics.InstrumentOption) (metrics.Float64Histogram, error) {
+	return &testInstrument{t: t, name: name}, nil
+}
+
+func (t *testTelemetry) Tracer(string, ...tracing.TracerOption) tracing.Tracer { return t }
+
+func (t *testTelemetry) StartSpan(ctx context.Context, name string, opts ...tracing.SpanOption) (context.Context, tracing.Span) {
+	var o tracing.SpanOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	s := &testSpan{name: name, options: o}
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return ctx, s
+}
+
+func (t *testTelemetry) metric(name string) (recordedMetric, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, m := range t.metrics {
+		if m.name == name {
+			return m, true
+		}
+	}
+	return recordedMetric{}, false
+}
+
+type testInstrument struct {
+	t    *testTelemetry
+	name string
+}
+
+func (i *testInstrument) record(v float64, opts []metrics.RecordMetricOption) {
+	var o metrics.RecordMetricOptions
+	for _, fn := range opts {
+		fn(&o)
+	}
+	i.t.mu.Lock()
+	i.t.metrics = append(i.t.metrics, recordedMetric{name: i.name, value: v, props: o.Properties})
+	i.t.mu.Unlock()
+}
+
+func (i *testInstrument) Add(_ context.Context, v int64, opts ...metrics.RecordMetricOption) {
+	i.record(float64(v), opts)
+}
+
+func (i *testInstrument) Record(_ context.Context, v float64, opts ...metrics.RecordMetricOption) {
+	i.record(v, opts)
+}
+
+type testSpan struct {
+	name    string
+	options tracing.SpanOptions
+	status  tracing.SpanStatus
+	props   smithy.Properties
+	ended   bool
+}
+
+func (s *testSpan) Name() string                    { return s.name }
+func (s *testSpan) SetStatus(st tracing.SpanStatus) { s.status = st }
+func (s *testSpan) SetProperty(k, v any)            { s.props.Set(k, v) }
+func (s *testSpan) End()                            { s.ended = true }
+
+func TestClientHandler_Telemetry(t *testing.T) {
+	cases := map[string]struct {
+		err          error
+		expectStatus tracing.SpanStatus
+		expectErrors bool
+	}{
+		"success": {
+			expectStatus: tracing.SpanStatusOK,
+		},
+		"send error": {
+			err:          errors.New("connection reset"),
+			expectStatus: tracing.SpanStatusError,
+			expectErrors: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			telemetry := &testTelemetry{}
+			var props smithy.Properties
+			props.Set("service", "test")
+
+			handler, err := NewClientHandlerWithOptions(ClientDoFunc(func(r *http.Request) (*http.Response, error) {
+				if c.err != nil {
+					return nil, c.err
+				}
+				return &http.Response{StatusCode: 200, Header: http.Header{}, Body: http.NoBody}, nil
+			}), func(o *ClientHandlerOptions) {
+				o.Telemetry = TelemetryOptions{
+					MeterProvider:  telemetry,
+					TracerProvider: telemetry,
+					Properties:     props,
+				}
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := NewStackRequest().(*Request)
+			req.Method = "GET"
+			req.URL.Host = "example.com"
+			req.URL.Scheme = "https"
+			handler.Handle(context.Background(), req)
+
+			if e, a := 1, len(telemetry.spans); e != a {
+				t.Fatalf("expect %v spans, got %v", e, a)
+			}
+			span := telemetry.spans[0]
+			if e, a := "HTTP GET", span.name; e != a {
+				t.Errorf("expect span %q, got %q", e, a)
+			}
+			if e, a := tracing.SpanKindClient, span.options.Kind; e != a {
+				t.Errorf("expect span kind %v, got %v", e, a)
+			}
+			if e, a := "test", span.options.Properties.Get("service"); e != a {
+				t.Errorf("expect service property %v, got %v", e, a)
+			}
+			if e, a := c.expectStatus, span.status; e != a {
+				t.Errorf("expect span status %v, got %v", e, a)
+			}
+			if !span.ended {
+				t.Errorf("expect span to be ended")
+			}
+
+			if _, ok := telemetry.metric("client.http.request_duration"); !ok {
+				t.Errorf("expect request duration to be recorded")
+			}
+			if _, ok := telemetry.metric("client.http.request_errors"); ok != c.expectErrors {
+				t.Errorf("expect request errors recorded %v, got %v", c.expectErrors, ok)
+			}
+		})
+	}
+}
+
+func TestAddTelemetryMiddleware(t *testing.T) {
+	telemetry := &testTelemetry{}
+	var scope string
+	var logger logging.Logger
+
+	stack := middleware.NewStack("GetItem", NewStackRequest)
+	if err := AddTelemetryMiddleware(stack, TelemetryOptions{
+		MeterProvider:  telemetry,
+		TracerProvider: telemetry,
+		LoggerProvider: logging.LoggerProviderFunc(func(s string) logging.Logger {
+			scope = s
+			return logging.Nop{}
+		}),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	stack.Initialize.Add(middleware.InitializeMiddlewareFunc("captureLogger", func(
+		ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+	) (middleware.InitializeOutput, middleware.Metadata, error) {
+		logger = middleware.GetLogger(ctx)
+		return next.HandleInitialize(ctx, in)
+	}), middleware.After)
+
+	expectErr := errors.New("operation failed")
+	_, _, err := middleware.DecorateHandler(middleware.HandlerFunc(func(context.Context, interface{}) (
+		interface{}, middleware.Metadata, error,
+	) {
+		return nil, middleware.Metadata{}, expectErr
+	}), stack).Handle(context.Background(), struct{}{})
+	if !errors.Is(err, expectErr) {
+		t.Fatalf("expect error %v, got %v", expectErr, err)
+	}
+
+	if e, a := "GetItem", scope; e != a {
+		t.Errorf("expect logger scope %q, got %q", e, a)
+	}
+	if _, ok := logger.(logging.Nop); !ok {
+		t.Errorf("expect logger of the provider, got %T", logger)
+	}
+	if e, a := 1, len(telemetry.spans); e != a {
+		t.Fatalf("expect %v spans, got %v", e, a)
+	}
+	if e, a := "GetItem", telemetry.spans[0].name; e != a {
+		t.Errorf("expect span %q, got %q", e, a)
+	}
+	if e, a := tracing.SpanStatusError, telemetry.spans[0].status; e != a {
+		t.Errorf("expect span status %v, got %v", e, a)
+	}
+	if _, ok := telemetry.metric("client.call.duration"); !ok {
+		t.Errorf("expect call duration to be recorded")
+	}
+	if m, ok := telemetry.metric("client.call.errors"); !ok || m.value != 1 {
+		t.Errorf("expect one call error to be recorded, got %v", m.value)
+	}
+}
+
+func TestHTTPTraceMiddleware_Telemetry(t *testing.T) {
+	telemetry := &testTelemetry{}
+	m, err := newConnectionMetrics(TelemetryOptions{MeterProvider: telemetry})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.record(context.Background(), ConnectionTimings{Connect: 1, TimeToFirstByte: 2, ConnReused: true})
+
+	if _, ok := telemetry.metric("client.http.connections.connect_duration"); !ok {
+		t.Errorf("expect connect duration to be recorded")
+	}
+	if _, ok := telemetry.metric("client.http.connections.dns_lookup_duration"); ok {
+		t.Errorf("expect phase that did not occur not to be recorded")
+	}
+	r, ok := telemetry.metric("client.http.time_to_first_byte")
+	if !ok {
+		t.Fatalf("expect time to first byte to be recorded")
+	}
+	if e, a := true, r.props.Get("http.conn_reused"); e != a {
+		t.Errorf("expect conn reused property %v, got %v", e, a)
+	}
+}
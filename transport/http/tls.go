@@ -0,0 +1,138 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSOptions is the set of options that can be configured for the TLS
+// config of a transport, see NewTLSTransport.
+type TLSOptions struct {
+	// PEM encoded certificates of CAs trusted in addition to the system
+	// roots, e.g. of a private CA.
+	CABundle []byte
+
+	// The path of a file of PEM encoded certificates of CAs trusted in
+	// addition to the system roots, and CABundle.
+	CABundleFile string
+
+	// The paths of the PEM encoded certificate, and its private key, of a
+	// client certificate presented to servers that request one, e.g. for
+	// mutual TLS. The files are loaded again once the certificate expires,
+	// such that a rotated certificate is used without recreating the
+	// transport.
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// The minimum TLS version, e.g. tls.VersionTLS13. Defaults to the
+	// minimum version of the TLS config of the transport, if any, or of the
+	// crypto/tls package.
+	MinVersion uint16
+}
+
+// NewTLSTransport returns a clone of t with its TLS config configured by the
+// options. t is not modified.
+//
+// Returns an error if the CA bundle or client certificate cannot be loaded.
+func NewTLSTransport(t *http.Transport, optFns ...func(*TLSOptions)) (*http.Transport, error) {
+	var o TLSOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+
+	t = t.Clone()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	config := t.TLSClientConfig
+
+	if len(o.CABundle) != 0 || len(o.CABundleFile) != 0 {
+		pool, err := loadCABundle(config.RootCAs, o.CABundle, o.CABundleFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+
+	if len(o.ClientCertFile) != 0 || len(o.ClientKeyFile) != 0 {
+		r := &clientCertReloader{certFile: o.ClientCertFile, keyFile: o.ClientKeyFile, now: time.Now}
+		if _, err := r.certificate(); err != nil {
+			return nil, err
+		}
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.certificate()
+		}
+	}
+
+	if o.MinVersion != 0 {
+		config.MinVersion = o.MinVersion
+	}
+
+	return t, nil
+}
+
+// loadCABundle returns a pool of the certificates of the pool, or the system
+// roots if nil, and of the bundle and bundle file.
+func loadCABundle(pool *x509.CertPool, bundle []byte, bundleFile string) (*x509.CertPool, error) {
+	if pool != nil {
+		pool = pool.Clone()
+	} else if sys, err := x509.SystemCertPool(); err == nil {
+		pool = sys
+	} else {
+		pool = x509.NewCertPool()
+	}
+
+	if len(bundle) != 0 && !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("failed to load CA bundle, no PEM encoded certificates")
+	}
+	if len(bundleFile) != 0 {
+		b, err := os.ReadFile(bundleFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle file, %w", err)
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("failed to load CA bundle file %s, no PEM encoded certificates", bundleFile)
+		}
+	}
+	return pool, nil
+}
+
+// clientCertReloader loads a client certificate from its files, and again
+// once the certificate loaded expires.
+type clientCertReloader struct {
+	certFile, keyFile string
+
+	// now returns the current time, overridden by tests
+	now func() time.Time
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	notAfter time.Time
+}
+
+func (r *clientCertReloader) certificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cert != nil && r.now().Before(r.notAfter) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate, %w", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client certificate, %w", err)
+	}
+
+	r.cert = &cert
+	r.notAfter = leaf.NotAfter
+	return r.cert, nil
+}
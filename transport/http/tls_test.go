@@ -0,0 +1,177 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestClientCert writes a self-signed client certificate with the
+// common name and expiry, and its key, to the files.
+func writeTestClientCert(t *testing.T, certFile, keyFile, name string, notAfter time.Time) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certPEM
+}
+
+func TestNewTLSTransport(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	clientCA := writeTestClientCert(t, certFile, keyFile, "client", time.Now().Add(time.Hour))
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(clientCA)
+
+	var clientName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientName = r.TLS.PeerCertificates[0].Subject.CommonName
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, serverCA, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		options TLSOptions
+	}{
+		"CA bundle": {
+			options: TLSOptions{CABundle: serverCA},
+		},
+		"CA bundle file": {
+			options: TLSOptions{CABundleFile: caFile},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			clientName = ""
+			original := &http.Transport{}
+			transport, err := NewTLSTransport(original, func(o *TLSOptions) {
+				*o = c.options
+				o.ClientCertFile, o.ClientKeyFile = certFile, keyFile
+				o.MinVersion = tls.VersionTLS13
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			defer transport.CloseIdleConnections()
+
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			resp.Body.Close()
+
+			if e, a := "client", clientName; e != a {
+				t.Errorf("expect client certificate %q, got %q", e, a)
+			}
+			if e, a := uint16(tls.VersionTLS13), resp.TLS.Version; e != a {
+				t.Errorf("expect TLS version %x, got %x", e, a)
+			}
+			if c := original.TLSClientConfig; c != nil && (c.RootCAs != nil || c.GetClientCertificate != nil) {
+				t.Errorf("expect original transport not modified")
+			}
+		})
+	}
+}
+
+func TestNewTLSTransport_Errors(t *testing.T) {
+	cases := map[string]TLSOptions{
+		"invalid CA bundle":  {CABundle: []byte("not PEM")},
+		"missing CA file":    {CABundleFile: filepath.Join(t.TempDir(), "missing.pem")},
+		"missing cert files": {ClientCertFile: "missing.pem", ClientKeyFile: "missing.key"},
+	}
+
+	for name, options := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewTLSTransport(&http.Transport{}, func(o *TLSOptions) {
+				*o = options
+			})
+			if err == nil {
+				t.Errorf("expect error")
+			}
+		})
+	}
+}
+
+func TestClientCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	expiry := time.Now().Add(time.Hour)
+	writeTestClientCert(t, certFile, keyFile, "first", expiry)
+
+	now := time.Now()
+	r := &clientCertReloader{certFile: certFile, keyFile: keyFile, now: func() time.Time { return now }}
+
+	name := func() string {
+		cert, err := r.certificate()
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.Subject.CommonName
+	}
+
+	if e, a := "first", name(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	// rotated, but not loaded until the certificate expires
+	writeTestClientCert(t, certFile, keyFile, "second", expiry.Add(time.Hour))
+	if e, a := "first", name(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	now = expiry
+	if e, a := "second", name(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// NewTrailerReader returns a request stream of the stream that sends the
+// trailers with the names, set to the values returned by fn once the stream
+// is read to EOF, with the request it is set on, see Request.SetStream. fn is
+// called again if the stream is read to EOF again, e.g. when the request is
+// retried.
+//
+// The trailers are sent as HTTP trailers, with chunked transfer encoding, or
+// in the payload if it is encoded with aws-chunked, see
+// AddAWSChunkedEncodingMiddleware.
+//
+// The stream returned is an io.Seeker if the stream is.
+func NewTrailerReader(stream io.Reader, names []string, fn func() http.Header) io.Reader {
+	r := &trailerReader{Reader: stream, names: names, fn: fn}
+	if _, ok := stream.(io.Seeker); ok {
+		return seekableTrailerReader{r}
+	}
+	return r
+}
+
+// trailerReader is a request stream that sets trailers with the values of a
+// function once read to EOF.
+type trailerReader struct {
+	io.Reader
+	names []string
+	fn    func() http.Header
+
+	mu      sync.Mutex
+	trailer http.Header
+}
+
+func (r *trailerReader) bindTrailer(trailer http.Header) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the trailers must be declared before the request is sent
+	for _, name := range r.names {
+		trailer[http.CanonicalHeaderKey(name)] = nil
+	}
+	r.trailer = trailer
+}
+
+func (r *trailerReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		r.mu.Lock()
+		if r.trailer != nil {
+			values := r.fn()
+			for _, name := range r.names {
+				if v := values.Values(name); len(v) != 0 {
+					r.trailer[http.CanonicalHeaderKey(name)] = v
+				}
+			}
+		}
+		r.mu.Unlock()
+	}
+	return n, err
+}
+
+type seekableTrailerReader struct {
+	*trailerReader
+}
+
+func (r seekableTrailerReader) Seek(offset int64, whence int) (int64, error) {
+	return r.Reader.(io.Seeker).Seek(offset, whence)
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestTrailerReader(t *testing.T) {
+	const payload = "hello world"
+
+	cases := map[string]struct {
+		body          func() io.Reader
+		seekable      bool
+		computeLength bool
+	}{
+		"seekable": {
+			body:     func() io.Reader { return strings.NewReader(payload) },
+			seekable: true,
+		},
+		"seekable with computed length": {
+			body:          func() io.Reader { return strings.NewReader(payload) },
+			seekable:      true,
+			computeLength: true,
+		},
+		"unseekable": {
+			body: func() io.Reader { return unknownLengthReader{strings.NewReader(payload)} },
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var body, trailer string
+			var transferEncoding []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				body = string(b)
+				trailer = r.Trailer.Get("X-Test-Trailer")
+				transferEncoding = r.TransferEncoding
+			}))
+			defer server.Close()
+			endpoint, _ := url.Parse(server.URL)
+
+			var read int
+			stream := NewTrailerReader(c.body(), []string{"x-test-trailer"}, func() http.Header {
+				read++
+				return http.Header{"X-Test-Trailer": {"done"}}
+			})
+			if _, ok := stream.(io.Seeker); ok != c.seekable {
+				t.Errorf("expect seekable %v, got %v", c.seekable, ok)
+			}
+
+			stack := middleware.NewStack("test", NewStackRequest)
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize", func(
+				ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+			) (middleware.SerializeOutput, middleware.Metadata, error) {
+				req := in.Request.(*Request)
+				req.URL = endpoint
+				req.Method = http.MethodPut
+				r, err := req.SetStream(stream)
+				if err != nil {
+					return middleware.SerializeOutput{}, middleware.Metadata{}, err
+				}
+				in.Request = r
+				return next.HandleSerialize(ctx, in)
+			}), middleware.After)
+			if c.computeLength {
+				stack.Build.Add(&ComputeContentLength{}, middleware.After)
+			}
+
+			_, _, err := middleware.DecorateHandler(NewClientHandler(server.Client()), stack).
+				Handle(context.Background(), struct{}{})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := payload, body; e != a {
+				t.Errorf("expect body %q, got %q", e, a)
+			}
+			if e, a := "done", trailer; e != a {
+				t.Errorf("expect trailer %q, got %q", e, a)
+			}
+			if e, a := []string{"chunked"}, transferEncoding; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect transfer encoding %v, got %v", e, a)
+			}
+			if e, a := 1, read; e != a {
+				t.Errorf("expect trailer values read %v times, got %v", e, a)
+			}
+		})
+	}
+}
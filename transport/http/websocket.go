@@ -0,0 +1,426 @@
+package http
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aws/smithy-go/rand"
+)
+
+// DefaultWebSocketMaxMessageSize is the default maximum size in bytes of a
+// message received over a WebSocket.
+const DefaultWebSocketMaxMessageSize = 16 * 1024 * 1024
+
+// websocketAcceptGUID is appended to the key of a WebSocket handshake to
+// compute the accept of the server, see RFC 6455 section 4.2.2.
+const websocketAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocketMessageType is the type of a WebSocket message.
+type WebSocketMessageType int
+
+// Types of WebSocket messages.
+const (
+	WebSocketTextMessage   WebSocketMessageType = 1
+	WebSocketBinaryMessage WebSocketMessageType = 2
+)
+
+// frame opcodes, see RFC 6455 section 5.2
+const (
+	wsContinuation byte = 0x0
+	wsText         byte = 0x1
+	wsBinary       byte = 0x2
+	wsClose        byte = 0x8
+	wsPing         byte = 0x9
+	wsPong         byte = 0xa
+)
+
+// WebSocketOptions is the set of options that can be configured for a
+// WebSocket client, see NewWebSocketClient.
+type WebSocketOptions struct {
+	// The subprotocols requested in the handshake, in order of preference.
+	// The subprotocol selected by the server is returned by
+	// WebSocketConn.Subprotocol.
+	Subprotocols []string
+
+	// The maximum size in bytes of a message received. Defaults to
+	// DefaultWebSocketMaxMessageSize if zero.
+	MaxMessageSize int64
+}
+
+// WebSocketClient is a ClientDo that upgrades the connection of each request
+// to a WebSocket, such that the frame-based protocol of a service is sent
+// over the same client handler and middleware stack as HTTP requests, see
+// NewClientHandler.
+type WebSocketClient struct {
+	client  ClientDo
+	options WebSocketOptions
+}
+
+// NewWebSocketClient returns a WebSocketClient that sends the handshake of
+// each request with the client. The client must send requests with HTTP/1.1,
+// as WebSocket connections cannot be upgraded from HTTP/2.
+func NewWebSocketClient(client ClientDo, optFns ...func(*WebSocketOptions)) *WebSocketClient {
+	var o WebSocketOptions
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	if o.MaxMessageSize == 0 {
+		o.MaxMessageSize = DefaultWebSocketMaxMessageSize
+	}
+
+	return &WebSocketClient{client: client, options: o}
+}
+
+// Do sends the WebSocket handshake of the request, which must not have a
+// body. The ws and wss schemes of the request URL are sent as http and https.
+//
+// If the server accepts the handshake, the body of the response returned is
+// a *WebSocketConn of the connection, to send and receive messages with. The
+// response of a server that does not upgrade the connection, e.g. as the
+// request is not authorized, is returned without a connection, such that it
+// is deserialized as any other error response.
+func (c *WebSocketClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		return nil, fmt.Errorf("websocket handshake request must not have a body")
+	}
+
+	var key [16]byte
+	if _, err := io.ReadFull(rand.Reader, key[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate websocket key, %w", err)
+	}
+	encodedKey := base64.StdEncoding.EncodeToString(key[:])
+
+	req = req.Clone(req.Context())
+	switch u := req.URL; strings.ToLower(u.Scheme) {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", encodedKey)
+	if len(c.options.Subprotocols) != 0 {
+		req.Header.Set("Sec-WebSocket-Protocol", strings.Join(c.options.Subprotocols, ", "))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return resp, nil
+	}
+
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, fmt.Errorf("websocket connection is not writable, %T", resp.Body)
+	}
+	if err := validateWebSocketUpgrade(resp, encodedKey); err != nil {
+		rwc.Close()
+		return nil, err
+	}
+
+	resp.Body = &WebSocketConn{
+		rwc:            rwc,
+		br:             bufio.NewReader(rwc),
+		subprotocol:    resp.Header.Get("Sec-WebSocket-Protocol"),
+		maxMessageSize: c.options.MaxMessageSize,
+	}
+	return resp, nil
+}
+
+func validateWebSocketUpgrade(resp *http.Response, key string) error {
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		return fmt.Errorf("websocket handshake response upgraded to %q", resp.Header.Get("Upgrade"))
+	}
+
+	h := sha1.New()
+	h.Write([]byte(key + websocketAcceptGUID))
+	if e, a := base64.StdEncoding.EncodeToString(h.Sum(nil)), resp.Header.Get("Sec-WebSocket-Accept"); e != a {
+		return fmt.Errorf("websocket handshake response accept mismatch, expect %s, got %s", e, a)
+	}
+	return nil
+}
+
+// WebSocketCloseError is returned when reading from a WebSocket connection
+// the server closed.
+type WebSocketCloseError struct {
+	// The status code of the close, or 1005 if the server sent none.
+	Code   int
+	Reason string
+}
+
+func (e *WebSocketCloseError) Error() string {
+	return fmt.Sprintf("websocket closed, %d %s", e.Code, e.Reason)
+}
+
+// WebSocketConn is a client WebSocket connection, the body of the response
+// to a handshake accepted by the server, see WebSocketClient.
+//
+// Messages may be written concurrently with reads, but only one goroutine
+// may read at a time. Ping frames of the server are answered while reading.
+type WebSocketConn struct {
+	rwc            io.ReadWriteCloser
+	br             *bufio.Reader
+	subprotocol    string
+	maxMessageSize int64
+
+	writeMu sync.Mutex
+	closed  bool
+
+	// the remaining payload of the message being read by Read
+	pending []byte
+}
+
+// Subprotocol returns the subprotocol selected by the server, if any.
+func (c *WebSocketConn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// ReadMessage reads the next text or binary message of the connection,
+// reassembling its fragments.
+//
+// Returns a *WebSocketCloseError once the server closes the connection.
+func (c *WebSocketConn) ReadMessage() (WebSocketMessageType, []byte, error) {
+	var typ WebSocketMessageType
+	var payload []byte
+	for {
+		f, err := readWebSocketFrame(c.br, c.maxMessageSize-int64(len(payload)))
+		if err != nil {
+			return 0, nil, err
+		}
+		if f.masked {
+			return 0, nil, fmt.Errorf("websocket frame of server must not be masked")
+		}
+
+		switch f.opcode {
+		case wsPing:
+			if err := c.writeFrame(wsPong, f.payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			return 0, nil, c.handleClose(f.payload)
+		case wsText, wsBinary:
+			if typ != 0 {
+				return 0, nil, fmt.Errorf("websocket message fragment expected, got opcode %d", f.opcode)
+			}
+			typ = WebSocketMessageType(f.opcode)
+		case wsContinuation:
+			if typ == 0 {
+				return 0, nil, fmt.Errorf("websocket continuation frame without a message")
+			}
+		default:
+			return 0, nil, fmt.Errorf("unknown websocket opcode %d", f.opcode)
+		}
+
+		payload = append(payload, f.payload...)
+		if f.fin {
+			return typ, payload, nil
+		}
+	}
+}
+
+// handleClose answers the close frame of the server, returning the
+// WebSocketCloseError of it.
+func (c *WebSocketConn) handleClose(payload []byte) error {
+	closeErr := &WebSocketCloseError{Code: 1005}
+	if len(payload) >= 2 {
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+	}
+
+	// echo the status code of the close
+	if len(payload) > 2 {
+		payload = payload[:2]
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if !c.closed {
+		c.closed = true
+		writeWebSocketFrame(c.rwc, true, wsClose, payload, true)
+	}
+	return closeErr
+}
+
+// Read reads the payloads of the messages of the connection, in order, such
+// that the connection can be read as a stream, e.g. of event stream messages
+// sent in binary messages.
+func (c *WebSocketConn) Read(p []byte) (int, error) {
+	for len(c.pending) == 0 {
+		_, payload, err := c.ReadMessage()
+		var closeErr *WebSocketCloseError
+		if errors.As(err, &closeErr) && closeErr.Code == 1000 {
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, err
+		}
+		c.pending = payload
+	}
+
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
+
+// WriteMessage writes a text or binary message to the connection.
+func (c *WebSocketConn) WriteMessage(typ WebSocketMessageType, payload []byte) error {
+	if typ != WebSocketTextMessage && typ != WebSocketBinaryMessage {
+		return fmt.Errorf("unknown websocket message type %d", typ)
+	}
+	return c.writeFrame(byte(typ), payload)
+}
+
+func (c *WebSocketConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closed {
+		return fmt.Errorf("websocket connection closed")
+	}
+	if err := writeWebSocketFrame(c.rwc, true, opcode, payload, true); err != nil {
+		return fmt.Errorf("failed to write websocket frame, %w", err)
+	}
+	return nil
+}
+
+// Close sends a normal close frame to the server, if the connection was not
+// already closed, and closes the connection.
+func (c *WebSocketConn) Close() error {
+	c.writeMu.Lock()
+	if !c.closed {
+		c.closed = true
+		writeWebSocketFrame(c.rwc, true, wsClose, []byte{0x03, 0xe8}, true)
+	}
+	c.writeMu.Unlock()
+
+	return c.rwc.Close()
+}
+
+// webSocketFrame is a frame of a WebSocket connection.
+type webSocketFrame struct {
+	fin     bool
+	opcode  byte
+	masked  bool
+	payload []byte
+}
+
+// readWebSocketFrame reads a frame, unmasking its payload if masked. Returns an
+// error if the payload of a data frame exceeds maxSize.
+func readWebSocketFrame(r io.Reader, maxSize int64) (webSocketFrame, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return webSocketFrame{}, err
+	}
+	f := webSocketFrame{
+		fin:    head[0]&0x80 != 0,
+		opcode: head[0] & 0x0f,
+		masked: head[1]&0x80 != 0,
+	}
+	if head[0]&0x70 != 0 {
+		return webSocketFrame{}, fmt.Errorf("websocket frame has reserved bits set")
+	}
+
+	size := uint64(head[1] & 0x7f)
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return webSocketFrame{}, err
+		}
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return webSocketFrame{}, err
+		}
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+	if f.opcode >= wsClose && (size > 125 || !f.fin) {
+		return webSocketFrame{}, fmt.Errorf("websocket control frame is fragmented or too large")
+	}
+	if f.opcode < wsClose && (maxSize < 0 || size > uint64(maxSize)) {
+		return webSocketFrame{}, fmt.Errorf("websocket message exceeds maximum size")
+	}
+
+	var mask [4]byte
+	if f.masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return webSocketFrame{}, err
+		}
+	}
+
+	f.payload = make([]byte, size)
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return webSocketFrame{}, err
+	}
+	if f.masked {
+		maskWebSocketPayload(mask, f.payload)
+	}
+	return f, nil
+}
+
+// writeWebSocketFrame writes a frame of the payload, masked with a random key
+// if mask is set, as every frame of a client must be.
+func writeWebSocketFrame(w io.Writer, fin bool, opcode byte, payload []byte, mask bool) error {
+	buf := make([]byte, 0, 14+len(payload))
+
+	b0 := opcode
+	if fin {
+		b0 |= 0x80
+	}
+	var b1 byte
+	if mask {
+		b1 = 0x80
+	}
+
+	switch n := len(payload); {
+	case n <= 125:
+		buf = append(buf, b0, b1|byte(n))
+	case n <= 0xffff:
+		buf = append(buf, b0, b1|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, b0, b1|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+
+	if !mask {
+		buf = append(buf, payload...)
+		_, err := w.Write(buf)
+		return err
+	}
+
+	var key [4]byte
+	if _, err := io.ReadFull(rand.Reader, key[:]); err != nil {
+		return fmt.Errorf("failed to generate websocket mask, %w", err)
+	}
+	buf = append(buf, key[:]...)
+	start := len(buf)
+	buf = append(buf, payload...)
+	maskWebSocketPayload(key, buf[start:])
+
+	_, err := w.Write(buf)
+	return err
+}
+
+func maskWebSocketPayload(key [4]byte, p []byte) {
+	for i := range p {
+		p[i] ^= key[i%4]
+	}
+}
@@ -0,0 +1,247 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// newWebSocketEchoServer returns a server that upgrades requests to a
+// WebSocket, pings the client, and echoes each message it receives in two
+// fragments until the client closes the connection.
+func newWebSocketEchoServer(t *testing.T, accept func(key string) string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		conn, brw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("failed to hijack connection, %v", err)
+			return
+		}
+		defer conn.Close()
+
+		fmt.Fprintf(brw, "HTTP/1.1 101 Switching Protocols\r\n"+
+			"Upgrade: websocket\r\nConnection: Upgrade\r\n"+
+			"Sec-WebSocket-Accept: %s\r\nSec-WebSocket-Protocol: echo\r\n\r\n",
+			accept(r.Header.Get("Sec-WebSocket-Key")))
+		writeWebSocketFrame(brw, true, wsPing, []byte("ping"), false)
+		brw.Flush()
+
+		for {
+			f, err := readWebSocketFrame(brw, 1<<20)
+			if err != nil {
+				return
+			}
+			if !f.masked {
+				t.Errorf("expect client frame to be masked")
+			}
+			switch f.opcode {
+			case wsPong:
+				if e, a := "ping", string(f.payload); e != a {
+					t.Errorf("expect pong %q, got %q", e, a)
+				}
+			case wsClose:
+				writeWebSocketFrame(brw, true, wsClose, f.payload, false)
+				brw.Flush()
+				return
+			default:
+				half := len(f.payload) / 2
+				writeWebSocketFrame(brw, false, f.opcode, f.payload[:half], false)
+				writeWebSocketFrame(brw, true, wsContinuation, f.payload[half:], false)
+				brw.Flush()
+			}
+		}
+	}))
+}
+
+func testWebSocketAccept(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + websocketAcceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func testWebSocketHandshake(t *testing.T, server *httptest.Server, optFns ...func(*WebSocketOptions)) (*Response, error) {
+	t.Helper()
+
+	endpoint, _ := url.Parse(server.URL)
+	endpoint.Scheme = "ws"
+
+	stack := middleware.NewStack("test", NewStackRequest)
+	stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize", func(
+		ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+	) (middleware.SerializeOutput, middleware.Metadata, error) {
+		req := in.Request.(*Request)
+		req.URL = endpoint
+		req.Method = http.MethodGet
+		return next.HandleSerialize(ctx, in)
+	}), middleware.After)
+	var resp *Response
+	stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("deserialize", func(
+		ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+	) (middleware.DeserializeOutput, middleware.Metadata, error) {
+		out, metadata, err := next.HandleDeserialize(ctx, in)
+		resp, _ = out.RawResponse.(*Response)
+		return out, metadata, err
+	}), middleware.After)
+
+	client := NewWebSocketClient(server.Client(), optFns...)
+	_, _, err := middleware.DecorateHandler(NewClientHandler(client), stack).
+		Handle(context.Background(), struct{}{})
+	return resp, err
+}
+
+func TestWebSocketClient(t *testing.T) {
+	server := newWebSocketEchoServer(t, testWebSocketAccept)
+	defer server.Close()
+
+	resp, err := testWebSocketHandshake(t, server, func(o *WebSocketOptions) {
+		o.Subprotocols = []string{"echo", "other"}
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	conn, ok := resp.Body.(*WebSocketConn)
+	if !ok {
+		t.Fatalf("expect websocket connection, got %T", resp.Body)
+	}
+	defer conn.Close()
+
+	if e, a := "echo", conn.Subprotocol(); e != a {
+		t.Errorf("expect subprotocol %q, got %q", e, a)
+	}
+
+	messages := []struct {
+		typ     WebSocketMessageType
+		payload string
+	}{
+		{WebSocketTextMessage, "hello"},
+		{WebSocketBinaryMessage, strings.Repeat("a", 70000)},
+	}
+	for _, m := range messages {
+		if err := conn.WriteMessage(m.typ, []byte(m.payload)); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		typ, payload, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if e, a := m.typ, typ; e != a {
+			t.Errorf("expect message type %v, got %v", e, a)
+		}
+		if e, a := m.payload, string(payload); e != a {
+			t.Errorf("expect payload of %d bytes, got %d", len(e), len(a))
+		}
+	}
+}
+
+func TestWebSocketConn_Read(t *testing.T) {
+	server := newWebSocketEchoServer(t, testWebSocketAccept)
+	defer server.Close()
+
+	resp, err := testWebSocketHandshake(t, server)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	conn := resp.Body.(*WebSocketConn)
+	defer conn.Close()
+
+	conn.WriteMessage(WebSocketBinaryMessage, []byte("hello "))
+	conn.WriteMessage(WebSocketBinaryMessage, []byte("world"))
+
+	b := make([]byte, len("hello world"))
+	if _, err := io.ReadFull(conn, b); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "hello world", string(b); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+
+	// the server echoes the close of the client
+	conn.writeMu.Lock()
+	writeWebSocketFrame(conn.rwc, true, wsClose, []byte{0x03, 0xe8}, true)
+	conn.writeMu.Unlock()
+	if _, err := conn.Read(b); err != io.EOF {
+		t.Errorf("expect EOF, got %v", err)
+	}
+}
+
+func TestWebSocketConn_MaxMessageSize(t *testing.T) {
+	server := newWebSocketEchoServer(t, testWebSocketAccept)
+	defer server.Close()
+
+	resp, err := testWebSocketHandshake(t, server, func(o *WebSocketOptions) {
+		o.MaxMessageSize = 10
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	conn := resp.Body.(*WebSocketConn)
+	defer conn.Close()
+
+	conn.WriteMessage(WebSocketTextMessage, []byte("0123456789a"))
+	if _, _, err := conn.ReadMessage(); err == nil || !strings.Contains(err.Error(), "exceeds maximum size") {
+		t.Errorf("expect maximum size error, got %v", err)
+	}
+}
+
+func TestWebSocketClient_Handshake(t *testing.T) {
+	t.Run("rejected", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, "not authorized")
+		}))
+		defer server.Close()
+
+		resp, err := testWebSocketHandshake(t, server)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		defer resp.Body.Close()
+
+		if e, a := http.StatusForbidden, resp.StatusCode; e != a {
+			t.Errorf("expect status %v, got %v", e, a)
+		}
+		if b, _ := ioutil.ReadAll(resp.Body); string(b) != "not authorized" {
+			t.Errorf("expect error response body, got %q", b)
+		}
+	})
+
+	t.Run("accept mismatch", func(t *testing.T) {
+		server := newWebSocketEchoServer(t, func(string) string { return "invalid" })
+		defer server.Close()
+
+		_, err := testWebSocketHandshake(t, server)
+		var sendErr *RequestSendError
+		if !errors.As(err, &sendErr) {
+			t.Fatalf("expect request send error, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "accept mismatch") {
+			t.Errorf("expect accept mismatch error, got %v", err)
+		}
+	})
+
+	t.Run("request body", func(t *testing.T) {
+		client := NewWebSocketClient(ClientDoFunc(func(*http.Request) (*http.Response, error) {
+			t.Fatalf("expect request not sent")
+			return nil, nil
+		}))
+		req, _ := http.NewRequest(http.MethodGet, "ws://localhost", bufio.NewReader(strings.NewReader("body")))
+		if _, err := client.Do(req); err == nil {
+			t.Errorf("expect error")
+		}
+	})
+}
@@ -0,0 +1,10 @@
+module github.com/aws/smithy-go/transport/http/zstd
+
+go 1.20
+
+require (
+	github.com/aws/smithy-go v1.20.2
+	github.com/klauspost/compress v1.17.9
+)
+
+replace github.com/aws/smithy-go => ../../../
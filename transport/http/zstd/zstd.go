@@ -0,0 +1,51 @@
+// Package zstd provides the zstd content encoding for smithy-go HTTP clients.
+//
+// The encoding is in a module of its own, such that its dependency on a zstd
+// implementation is only required by clients that use it. Responses are
+// negotiated and decoded with zstd by adding the encoding to the response
+// decompression middleware of a stack:
+//
+//	smithyhttp.AddResponseDecompressionMiddleware(stack,
+//		zstd.ContentEncoding{}, smithyhttp.GzipContentEncoding{})
+//
+// Request payloads of operations with the requestCompression trait are
+// compressed with zstd, where the operation prefers it, once
+// RegisterRequestCompression has been called.
+package zstd
+
+import (
+	"io"
+
+	"github.com/aws/smithy-go/private/requestcompression"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/klauspost/compress/zstd"
+)
+
+// ContentEncoding is the zstd smithyhttp.ContentEncoding.
+type ContentEncoding struct{}
+
+var _ smithyhttp.ContentEncoding = ContentEncoding{}
+
+// Name returns "zstd".
+func (ContentEncoding) Name() string { return requestcompression.ZSTD }
+
+// NewReader returns a zstd reader of r. The reader must be closed to release
+// its resources.
+func (ContentEncoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
+
+// NewWriter returns a zstd writer to w.
+func (ContentEncoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+}
+
+// RegisterRequestCompression makes zstd available to the request compression
+// of operations with the requestCompression trait, see ContentEncoding.
+func RegisterRequestCompression() {
+	requestcompression.RegisterContentEncoding(ContentEncoding{})
+}
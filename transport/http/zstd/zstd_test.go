@@ -0,0 +1,164 @@
+package zstd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/aws/smithy-go/private/requestcompression"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestContentEncoding(t *testing.T) {
+	payload := strings.Repeat("hello ", 100)
+
+	var b bytes.Buffer
+	w, err := ContentEncoding{}.NewWriter(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, payload); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the encoded content is readable by any zstd decoder
+	d, err := zstd.NewReader(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	p, err := d.DecodeAll(b.Bytes(), nil)
+	if err != nil {
+		t.Fatalf("expect zstd content, got %v", err)
+	}
+	if e, a := payload, string(p); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+
+	r, err := ContentEncoding{}.NewReader(bytes.NewReader(b.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if p, err = io.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+	if e, a := payload, string(p); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+}
+
+type testDeserializer struct{}
+
+func (*testDeserializer) ID() string { return "OperationDeserializer" }
+
+func (*testDeserializer) HandleDeserialize(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+	resp := out.RawResponse.(*smithyhttp.Response)
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	out.Result = string(b)
+	return out, metadata, err
+}
+
+func TestResponseDecompression(t *testing.T) {
+	d, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := d.EncodeAll([]byte("hello"), nil)
+
+	stack := middleware.NewStack("test", smithyhttp.NewStackRequest)
+	stack.Deserialize.Add(&testDeserializer{}, middleware.After)
+	if err := smithyhttp.AddResponseDecompressionMiddleware(stack,
+		ContentEncoding{}, smithyhttp.GzipContentEncoding{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var acceptEncoding string
+	client := smithyhttp.ClientDoFunc(func(r *http.Request) (*http.Response, error) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		return &http.Response{
+			StatusCode:    200,
+			Header:        http.Header{"Content-Encoding": {"zstd"}},
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+		}, nil
+	})
+
+	result, _, err := middleware.DecorateHandler(smithyhttp.NewClientHandler(client), stack).
+		Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "zstd, gzip", acceptEncoding; e != a {
+		t.Errorf("expect Accept-Encoding %q, got %q", e, a)
+	}
+	if e, a := "hello", result; e != a {
+		t.Errorf("expect body %v, got %v", e, a)
+	}
+}
+
+func TestRegisterRequestCompression(t *testing.T) {
+	RegisterRequestCompression()
+
+	stack := middleware.NewStack("test", smithyhttp.NewStackRequest)
+	stack.Serialize.Add(middleware.SerializeMiddlewareFunc("serialize", func(
+		ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler,
+	) (middleware.SerializeOutput, middleware.Metadata, error) {
+		req, err := in.Request.(*smithyhttp.Request).SetStream(strings.NewReader("hello"))
+		if err != nil {
+			return middleware.SerializeOutput{}, middleware.Metadata{}, err
+		}
+		in.Request = req
+		return next.HandleSerialize(ctx, in)
+	}), middleware.After)
+	if err := requestcompression.AddRequestCompression(stack, false, 0,
+		[]string{requestcompression.ZSTD, requestcompression.GZIP}); err != nil {
+		t.Fatal(err)
+	}
+
+	var contentEncoding string
+	var body []byte
+	client := smithyhttp.ClientDoFunc(func(r *http.Request) (*http.Response, error) {
+		contentEncoding = r.Header.Get("Content-Encoding")
+		body, _ = io.ReadAll(r.Body)
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: http.NoBody}, nil
+	})
+
+	_, _, err := middleware.DecorateHandler(smithyhttp.NewClientHandler(client), stack).
+		Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "zstd", contentEncoding; e != a {
+		t.Errorf("expect Content-Encoding %q, got %q", e, a)
+	}
+
+	d, err := zstd.NewReader(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	p, err := d.DecodeAll(body, nil)
+	if err != nil {
+		t.Fatalf("expect zstd body, got %v", err)
+	}
+	if e, a := "hello", string(p); e != a {
+		t.Errorf("expect body %q, got %q", e, a)
+	}
+}
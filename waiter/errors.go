@@ -0,0 +1,78 @@
+package waiter
+
+import (
+	"fmt"
+	"time"
+)
+
+// Attempt describes the outcome of a single waiter attempt.
+type Attempt struct {
+	// The attempt number, starting at 1.
+	Number int64
+
+	// The delay before the attempt was made.
+	Delay time.Duration
+
+	// The name of the waiter state matched by the attempt, e.g. "retry", or
+	// empty if no acceptor matched.
+	Match string
+
+	// The error returned by the operation, if any.
+	Err error
+}
+
+// FailureStateError is returned when an acceptor of the waiter matched its
+// failure state. The resource has reached a state from which it will not
+// transition to success, so invoking the waiter again will not succeed.
+type FailureStateError struct {
+	WaiterName string
+
+	// The attempts made, the last of which matched the failure state.
+	Attempts []Attempt
+}
+
+func (e *FailureStateError) Error() string {
+	return fmt.Sprintf("%s waiter state transitioned to failure after %d attempts",
+		e.WaiterName, len(e.Attempts))
+}
+
+// RetryBudgetExhaustedError is returned when the waiter's maximum wait time
+// elapsed without an acceptor matching its success or failure state. The
+// resource may yet reach the success state, so invoking the waiter again may
+// succeed.
+type RetryBudgetExhaustedError struct {
+	WaiterName  string
+	MaxWaitTime time.Duration
+
+	// The attempts made, each of which matched the retry state.
+	Attempts []Attempt
+}
+
+func (e *RetryBudgetExhaustedError) Error() string {
+	return fmt.Sprintf("exceeded max wait time %v for %s waiter after %d attempts",
+		e.MaxWaitTime, e.WaiterName, len(e.Attempts))
+}
+
+// NonRetryableError is returned when the operation returned an error that no
+// acceptor of the waiter matched, which halts the wait. Whether invoking the
+// waiter again makes sense depends on the wrapped operation error, e.g. it may
+// follow a transient network failure, but not an access denial.
+type NonRetryableError struct {
+	WaiterName string
+
+	// The operation error.
+	Err error
+
+	// The attempts made, the last of which returned Err.
+	Attempts []Attempt
+}
+
+func (e *NonRetryableError) Error() string {
+	return fmt.Sprintf("%s waiter halted by non-retryable error on attempt %d, %v",
+		e.WaiterName, len(e.Attempts), e.Err)
+}
+
+// Unwrap returns the underlying operation error.
+func (e *NonRetryableError) Unwrap() error {
+	return e.Err
+}
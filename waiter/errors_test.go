@@ -0,0 +1,71 @@
+package waiter
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestErrors(t *testing.T) {
+	opErr := fmt.Errorf("access denied")
+	attempts := []Attempt{
+		{Number: 1, Match: "retry"},
+		{Number: 2, Delay: 2 * time.Second, Match: "retry"},
+	}
+
+	cases := map[string]struct {
+		Err       error
+		ExpectMsg string
+		ExpectErr error
+	}{
+		"failure state": {
+			Err:       &FailureStateError{WaiterName: "BucketExists", Attempts: attempts},
+			ExpectMsg: "BucketExists waiter state transitioned to failure after 2 attempts",
+		},
+		"budget exhausted": {
+			Err: &RetryBudgetExhaustedError{
+				WaiterName: "BucketExists", MaxWaitTime: time.Minute, Attempts: attempts,
+			},
+			ExpectMsg: "exceeded max wait time 1m0s for BucketExists waiter after 2 attempts",
+		},
+		"non-retryable": {
+			Err: &NonRetryableError{
+				WaiterName: "BucketExists", Err: opErr,
+				Attempts: append(attempts, Attempt{Number: 3, Err: opErr}),
+			},
+			ExpectMsg: "BucketExists waiter halted by non-retryable error on attempt 3, access denied",
+			ExpectErr: opErr,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", c.Err)
+
+			if e, a := c.ExpectMsg, c.Err.Error(); e != a {
+				t.Errorf("expect %q, got %q", e, a)
+			}
+			if c.ExpectErr != nil && !errors.Is(err, c.ExpectErr) {
+				t.Errorf("expect %v to wrap %v", err, c.ExpectErr)
+			}
+
+			var failure *FailureStateError
+			var exhausted *RetryBudgetExhaustedError
+			var nonRetryable *NonRetryableError
+			var matched int
+			for _, ok := range []bool{
+				errors.As(err, &failure),
+				errors.As(err, &exhausted),
+				errors.As(err, &nonRetryable),
+			} {
+				if ok {
+					matched++
+				}
+			}
+			if matched != 1 {
+				t.Errorf("expect error to match exactly one type, matched %d", matched)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package waiter
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const stateVersion = 1
+
+// State captures the progress of a waiter between attempts, such that a wait
+// may be checkpointed and later resumed, potentially in another process,
+// instead of holding a goroutine for the full duration of the wait.
+//
+// State is serialized with MarshalJSON. Durations are tracked as the time
+// spent waiting rather than wall-clock deadlines, so a resumed wait does not
+// count the time the checkpoint was at rest against its budget.
+type State struct {
+	// The number of attempts made so far.
+	Attempt int64
+
+	// The total amount of time spent waiting so far.
+	Elapsed time.Duration
+
+	// The maximum amount of time the waiter may spend waiting.
+	MaxWaitTime time.Duration
+
+	// The name of the waiter state matched by the last attempt, e.g. "retry".
+	LastMatch string
+}
+
+type serializedState struct {
+	Version   int    `json:"version"`
+	Attempt   int64  `json:"attempt"`
+	ElapsedNS int64  `json:"elapsedNanos"`
+	MaxWaitNS int64  `json:"maxWaitTimeNanos"`
+	LastMatch string `json:"lastMatch,omitempty"`
+}
+
+// Remaining returns the amount of time left in the wait budget.
+func (s State) Remaining() time.Duration {
+	if s.Elapsed >= s.MaxWaitTime {
+		return 0
+	}
+	return s.MaxWaitTime - s.Elapsed
+}
+
+// NextDelay computes the delay before the next attempt with ComputeDelay,
+// using the attempt count and remaining budget of the state.
+func (s State) NextDelay(minDelay, maxDelay time.Duration) (time.Duration, error) {
+	return ComputeDelay(s.Attempt, minDelay, maxDelay, s.Remaining())
+}
+
+// Record returns the state advanced by a single attempt that took elapsed
+// time (including any delay before it) and matched the named waiter state.
+func (s State) Record(elapsed time.Duration, match string) State {
+	s.Attempt++
+	s.Elapsed += elapsed
+	s.LastMatch = match
+	return s
+}
+
+// MarshalJSON returns the serialized form of the state.
+func (s State) MarshalJSON() ([]byte, error) {
+	return json.Marshal(serializedState{
+		Version:   stateVersion,
+		Attempt:   s.Attempt,
+		ElapsedNS: int64(s.Elapsed),
+		MaxWaitNS: int64(s.MaxWaitTime),
+		LastMatch: s.LastMatch,
+	})
+}
+
+// UnmarshalJSON restores state from its serialized form.
+func (s *State) UnmarshalJSON(p []byte) error {
+	var ss serializedState
+	if err := json.Unmarshal(p, &ss); err != nil {
+		return fmt.Errorf("unmarshal waiter state, %w", err)
+	}
+	if ss.Version != stateVersion {
+		return fmt.Errorf("unsupported waiter state version %d", ss.Version)
+	}
+	if ss.Attempt < 0 || ss.ElapsedNS < 0 || ss.MaxWaitNS < 0 {
+		return fmt.Errorf("invalid waiter state, negative values")
+	}
+
+	*s = State{
+		Attempt:     ss.Attempt,
+		Elapsed:     time.Duration(ss.ElapsedNS),
+		MaxWaitTime: time.Duration(ss.MaxWaitNS),
+		LastMatch:   ss.LastMatch,
+	}
+	return nil
+}
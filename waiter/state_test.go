@@ -0,0 +1,84 @@
+package waiter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestState_RoundTrip(t *testing.T) {
+	s := State{MaxWaitTime: 5 * time.Minute}
+	s = s.Record(0, "retry")
+	s = s.Record(2*time.Second, "retry")
+
+	p, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var actual State
+	if err := json.Unmarshal(p, &actual); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := s, actual; e != a {
+		t.Errorf("expect %+v, got %+v", e, a)
+	}
+	if e, a := int64(2), actual.Attempt; e != a {
+		t.Errorf("expect attempt %v, got %v", e, a)
+	}
+	if e, a := 5*time.Minute-2*time.Second, actual.Remaining(); e != a {
+		t.Errorf("expect remaining %v, got %v", e, a)
+	}
+}
+
+func TestState_NextDelay(t *testing.T) {
+	s := State{Attempt: 3, Elapsed: 10 * time.Second, MaxWaitTime: 100 * time.Second}
+
+	delay, err := s.NextDelay(2*time.Second, 120*time.Second)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if delay < 2*time.Second || delay > 8*time.Second {
+		t.Errorf("expect delay in [2s, 8s], got %v", delay)
+	}
+
+	s.Elapsed = s.MaxWaitTime
+	if e, a := time.Duration(0), s.Remaining(); e != a {
+		t.Errorf("expect remaining %v, got %v", e, a)
+	}
+}
+
+func TestState_UnmarshalInvalid(t *testing.T) {
+	cases := map[string]struct {
+		input     string
+		expectErr string
+	}{
+		"unknown version": {
+			input:     `{"version":2,"attempt":1}`,
+			expectErr: "unsupported waiter state version 2",
+		},
+		"negative": {
+			input:     `{"version":1,"attempt":-1}`,
+			expectErr: "negative values",
+		},
+		"malformed": {
+			input:     `{`,
+			expectErr: "unmarshal waiter state",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var s State
+			err := s.UnmarshalJSON([]byte(c.input))
+			if err == nil {
+				t.Fatalf("expect error")
+			}
+			if !strings.Contains(err.Error(), c.expectErr) {
+				t.Errorf("expect error %q, got %v", c.expectErr, err)
+			}
+		})
+	}
+}